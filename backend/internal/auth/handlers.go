@@ -107,9 +107,15 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If TOTP is enabled, require a second step.
+	// If TOTP or a passkey is set up, require a second step.
+	methods, err := h.svc.MFAMethods(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("user.Handler.login: mfa methods: %v", err)
+		utils.WriteError(w, http.StatusInternalServerError, "login failed")
+		return
+	}
 	totpSecret, enabled, _ := h.svc.GetTOTPEnabled(r.Context(), user.ID)
-	if enabled {
+	if len(methods) > 0 {
 		// Issue a short-lived TOTP challenge token (5 min).
 		totpToken, err := ijwt.GenerateTokenWithExpiration(
 			user.ID, user.Username, user.Email, user.DisplayName,
@@ -124,6 +130,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 				utils.WriteJSON(w, http.StatusOK, models.AuthResponse{
 					RequiresTOTP: true,
 					TOTPToken:    "", // No token, but allow backup code
+					MFAMethods:   methods,
 				})
 				return
 			}
@@ -133,6 +140,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		utils.WriteJSON(w, http.StatusOK, models.AuthResponse{
 			RequiresTOTP: true,
 			TOTPToken:    totpToken,
+			MFAMethods:   methods,
 		})
 		return
 	}
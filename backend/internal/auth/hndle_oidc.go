@@ -0,0 +1,369 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	log "github.com/skaia/backend/internal/syslog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	ievents "github.com/skaia/backend/internal/events"
+	ijwt "github.com/skaia/backend/internal/jwt"
+	"github.com/skaia/backend/internal/seo"
+	"github.com/skaia/backend/internal/utils"
+	"github.com/skaia/backend/models"
+)
+
+// oidcRedirectURI is the callback URL registered with providers. It is
+// built from the tenant's public origin; the request host is only used in
+// development, where no origin is configured.
+func oidcRedirectURI(r *http.Request, providerID string) string {
+	base := seo.ConfiguredPublicBaseURL()
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + r.Host
+	}
+	return base + "/api/auth/oidc/" + url.PathEscape(providerID) + "/callback"
+}
+
+// safeReturnTo keeps post-login redirects on this site.
+func safeReturnTo(raw string) string {
+	if !strings.HasPrefix(raw, "/") || strings.HasPrefix(raw, "//") || strings.HasPrefix(raw, "/\\") {
+		return "/"
+	}
+	return raw
+}
+
+func withQuery(path string, key, value string) string {
+	u, err := url.Parse(path)
+	if err != nil {
+		return "/"
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// oidcStateCookie binds a pending sign-in to the browser that started it. It
+// holds a hash of the state key, so a callback URL carrying someone else's
+// code and state is refused (login CSRF).
+const oidcStateCookie = "skaia_oidc_state"
+
+func oidcStateHash(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+func setOIDCStateCookie(w http.ResponseWriter, r *http.Request, providerID, state string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    oidcStateHash(state),
+		Path:     "/api/auth/oidc/",
+		MaxAge:   int(oidcStateTTL / time.Second),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(oidcRedirectURI(r, providerID), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearOIDCStateCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/api/auth/oidc/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// oidcStateFromBrowser reports whether the callback's state was issued to
+// this browser.
+func oidcStateFromBrowser(r *http.Request, state string) bool {
+	c, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.Value), []byte(oidcStateHash(state))) == 1
+}
+
+// OIDCProviders lists the enabled sign-in providers for the login page.
+func (h *Handler) OIDCProviders(w http.ResponseWriter, r *http.Request) {
+	providers, err := h.svc.OIDCProviders(r.Context())
+	if err != nil {
+		log.Printf("auth.Handler.OIDCProviders: %v", err)
+		utils.WriteError(w, http.StatusInternalServerError, "failed to load sign-in providers")
+		return
+	}
+	out := []map[string]string{}
+	for _, p := range providers {
+		if !p.Disabled {
+			out = append(out, map[string]string{"id": p.ID, "name": p.Name})
+		}
+	}
+	utils.WriteJSON(w, http.StatusOK, out)
+}
+
+// OIDCStart redirects the browser to the provider's sign-in page.
+func (h *Handler) OIDCStart(w http.ResponseWriter, r *http.Request) {
+	providerID := chi.URLParam(r, "provider")
+	returnTo := safeReturnTo(r.URL.Query().Get("return_to"))
+	authURL, state, err := h.svc.BeginOIDC(r.Context(), providerID, oidcRedirectURI(r, providerID), returnTo, 0)
+	if err != nil {
+		log.Printf("auth.Handler.OIDCStart: %s: %v", providerID, err)
+		http.Redirect(w, r, withQuery(returnTo, "oidc_error", "provider unavailable"), http.StatusFound)
+		return
+	}
+	setOIDCStateCookie(w, r, providerID, state)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCLink returns the URL that links a provider account to the signed-in
+// user.
+func (h *Handler) OIDCLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	providerID := chi.URLParam(r, "provider")
+	returnTo := safeReturnTo(r.URL.Query().Get("return_to"))
+	authURL, state, err := h.svc.BeginOIDC(r.Context(), providerID, oidcRedirectURI(r, providerID), returnTo, userID)
+	if errors.Is(err, ErrOIDCProviderNotFound) {
+		utils.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("auth.Handler.OIDCLink: %s: %v", providerID, err)
+		utils.WriteError(w, http.StatusBadGateway, "provider unavailable")
+		return
+	}
+	setOIDCStateCookie(w, r, providerID, state)
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"url": authURL})
+}
+
+// OIDCCallback completes a sign-in and sends the browser back to the site
+// with a one-time login code, or with oidc_error. The state must match the
+// cookie OIDCStart or OIDCLink set in this browser.
+func (h *Handler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	fromBrowser := oidcStateFromBrowser(r, q.Get("state"))
+	clearOIDCStateCookie(w)
+	if !fromBrowser {
+		log.Printf("auth.Handler.OIDCCallback: %s: state not issued to this browser", chi.URLParam(r, "provider"))
+		http.Redirect(w, r, withQuery("/", "oidc_error", ErrOIDCInvalidState.Error()), http.StatusFound)
+		return
+	}
+	if e := q.Get("error"); e != "" {
+		// The user cancelled or the provider refused; forget the pending sign-in.
+		h.svc.takeOIDCState(q.Get("state"))
+		http.Redirect(w, r, withQuery("/", "oidc_error", e), http.StatusFound)
+		return
+	}
+	res, err := h.svc.CompleteOIDC(r.Context(), q.Get("state"), q.Get("code"))
+	if err != nil {
+		log.Printf("auth.Handler.OIDCCallback: %s: %v", chi.URLParam(r, "provider"), err)
+		msg := "sign-in failed"
+		switch {
+		case errors.Is(err, ErrOIDCInvalidState), errors.Is(err, ErrOIDCNoAccount),
+			errors.Is(err, ErrOIDCDomainNotAllowed), errors.Is(err, ErrOIDCAlreadyLinked),
+			errors.Is(err, ErrOIDCAccountSuspended):
+			msg = err.Error()
+		}
+		http.Redirect(w, r, withQuery("/", "oidc_error", msg), http.StatusFound)
+		return
+	}
+	if res.LinkOnly {
+		http.Redirect(w, r, withQuery(res.ReturnTo, "oidc_linked", chi.URLParam(r, "provider")), http.StatusFound)
+		return
+	}
+	if res.Created {
+		log.Printf("auth: registered %q (@%s, id=%d) via %s", res.User.DisplayName, res.User.Username, res.User.ID, chi.URLParam(r, "provider"))
+		h.dispatcher.Dispatch(ievents.Job{
+			UserID:     res.User.ID,
+			Activity:   ievents.ActUserRegistered,
+			Resource:   ievents.ResUser,
+			ResourceID: res.User.ID,
+			IP:         ievents.ClientIP(r),
+			Meta:       map[string]interface{}{"username": res.User.Username, "oidc": chi.URLParam(r, "provider")},
+		})
+	}
+	http.Redirect(w, r, withQuery(res.ReturnTo, "oidc_code", h.svc.IssueOIDCLoginCode(res.User.ID)), http.StatusFound)
+}
+
+// OIDCExchange trades a one-time login code for tokens. Accounts with TOTP
+// or a passkey set up still complete the second step through
+// /auth/login/totp.
+func (h *Handler) OIDCExchange(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		utils.WriteError(w, http.StatusBadRequest, "code required")
+		return
+	}
+	user, err := h.svc.RedeemOIDCLoginCode(r.Context(), req.Code)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "login code expired or invalid")
+		return
+	}
+
+	methods, err := h.svc.MFAMethods(r.Context(), user.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "login failed")
+		return
+	}
+	if len(methods) > 0 {
+		totpToken, err := ijwt.GenerateTokenWithExpiration(
+			user.ID, user.Username, user.Email, user.DisplayName,
+			user.Roles, user.Permissions, 5*time.Minute,
+		)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "login failed")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, models.AuthResponse{RequiresTOTP: true, TOTPToken: totpToken, MFAMethods: methods})
+		return
+	}
+
+	accessToken, refreshToken, err := h.svc.IssueTokens(r.Context(), user)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "token generation failed")
+		return
+	}
+	h.propagateAuthUser(r.Context(), user.ID, map[string]interface{}{"new_token": accessToken})
+
+	log.Printf("auth: login %q (@%s, id=%d) via oidc", user.DisplayName, user.Username, user.ID)
+	h.dispatcher.Dispatch(ievents.Job{
+		UserID:     user.ID,
+		Activity:   ievents.ActUserLoggedIn,
+		Resource:   ievents.ResUser,
+		ResourceID: user.ID,
+		IP:         ievents.ClientIP(r),
+		Meta:       map[string]interface{}{"oidc": true},
+	})
+	utils.WriteJSON(w, http.StatusOK, models.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         h.newAuthUser(r.Context(), user),
+	})
+}
+
+// OIDCIdentities lists the signed-in user's linked provider accounts.
+func (h *Handler) OIDCIdentities(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	idents, err := h.svc.ListOIDCIdentities(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "failed to load linked accounts")
+		return
+	}
+	if idents == nil {
+		idents = []*models.OIDCIdentity{}
+	}
+	utils.WriteJSON(w, http.StatusOK, idents)
+}
+
+// OIDCUnlink removes one of the signed-in user's provider links.
+func (h *Handler) OIDCUnlink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	err = h.svc.UnlinkOIDCIdentity(r.Context(), userID, id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		utils.WriteError(w, http.StatusNotFound, "linked account not found")
+	case err != nil:
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "success"})
+	}
+}
+
+func (h *Handler) requireAdminGeneral(w http.ResponseWriter, r *http.Request) bool {
+	actorID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return false
+	}
+	if ok, _ := h.svc.HasPermission(r.Context(), actorID, "admin.general"); !ok {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return false
+	}
+	return true
+}
+
+// AdminOIDCProviders lists the tenant's providers with secrets withheld.
+func (h *Handler) AdminOIDCProviders(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminGeneral(w, r) {
+		return
+	}
+	providers, err := h.svc.OIDCProviders(r.Context())
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "failed to load sign-in providers")
+		return
+	}
+	type adminProvider struct {
+		*OIDCProvider
+		ClientSecretSet bool `json:"client_secret_set"`
+	}
+	out := []adminProvider{}
+	for _, p := range providers {
+		set := p.ClientSecret != ""
+		p.ClientSecret = ""
+		out = append(out, adminProvider{OIDCProvider: p, ClientSecretSet: set})
+	}
+	utils.WriteJSON(w, http.StatusOK, out)
+}
+
+// AdminSaveOIDCProvider adds or replaces a provider.
+func (h *Handler) AdminSaveOIDCProvider(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminGeneral(w, r) {
+		return
+	}
+	var p OIDCProvider
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	p.ID = chi.URLParam(r, "provider")
+	if err := h.svc.SaveOIDCProvider(r.Context(), &p); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "success", "redirect_uri": oidcRedirectURI(r, p.ID)})
+}
+
+// AdminRemoveOIDCProvider removes a provider.
+func (h *Handler) AdminRemoveOIDCProvider(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminGeneral(w, r) {
+		return
+	}
+	if err := h.svc.RemoveOIDCProvider(r.Context(), chi.URLParam(r, "provider")); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "failed to remove provider")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	log "github.com/skaia/backend/internal/syslog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	ievents "github.com/skaia/backend/internal/events"
+	ijwt "github.com/skaia/backend/internal/jwt"
+	"github.com/skaia/backend/internal/utils"
+	"github.com/skaia/backend/models"
+)
+
+// PasskeyRegisterOptions starts adding a passkey to the signed-in account.
+func (h *Handler) PasskeyRegisterOptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	opts, err := h.svc.BeginPasskeyRegistration(r.Context(), RelyingPartyFor(r), userID)
+	if err != nil {
+		log.Printf("auth.Handler.PasskeyRegisterOptions: %v", err)
+		utils.WriteError(w, http.StatusInternalServerError, "failed to start passkey registration")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, opts)
+}
+
+// PasskeyRegister stores the passkey created from PasskeyRegisterOptions.
+func (h *Handler) PasskeyRegister(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req struct {
+		Name       string             `json:"name"`
+		Credential *PasskeyCredential `json:"credential"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Credential == nil {
+		utils.WriteError(w, http.StatusBadRequest, "credential required")
+		return
+	}
+	passkey, err := h.svc.FinishPasskeyRegistration(r.Context(), RelyingPartyFor(r), userID, req.Name, req.Credential)
+	switch {
+	case errors.Is(err, ErrPasskeyInvalid), errors.Is(err, ErrPasskeyExists):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		log.Printf("auth.Handler.PasskeyRegister: %v", err)
+		utils.WriteError(w, http.StatusInternalServerError, "failed to register passkey")
+		return
+	}
+
+	// Registering needed a user gesture on a device the user holds, which
+	// answers any pending MFA challenge just as enabling TOTP does.
+	_ = h.svc.SetMFARequired(r.Context(), userID, false)
+
+	h.propagateAuthUser(r.Context(), userID, nil)
+	utils.WriteJSON(w, http.StatusCreated, passkey)
+}
+
+// Passkeys lists the signed-in user's passkeys.
+func (h *Handler) Passkeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	passkeys, err := h.svc.ListPasskeys(r.Context(), userID)
+	if err != nil {
+		log.Printf("auth.Handler.Passkeys: %v", err)
+		utils.WriteError(w, http.StatusInternalServerError, "failed to load passkeys")
+		return
+	}
+	if passkeys == nil {
+		passkeys = []*models.Passkey{}
+	}
+	utils.WriteJSON(w, http.StatusOK, passkeys)
+}
+
+// RenamePasskey changes the label of one of the signed-in user's passkeys.
+func (h *Handler) RenamePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	err = h.svc.RenamePasskey(r.Context(), userID, id, req.Name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		utils.WriteError(w, http.StatusNotFound, "passkey not found")
+	case err != nil:
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "success"})
+	}
+}
+
+// DeletePasskey removes one of the signed-in user's passkeys.
+func (h *Handler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	err = h.svc.DeletePasskey(r.Context(), userID, id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		utils.WriteError(w, http.StatusNotFound, "passkey not found")
+	case err != nil:
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.propagateAuthUser(r.Context(), userID, nil)
+		utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "success"})
+	}
+}
+
+// PasskeyLoginOptions starts a passwordless sign-in.
+func (h *Handler) PasskeyLoginOptions(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, h.svc.BeginPasskeyLogin(r.Context(), RelyingPartyFor(r)))
+}
+
+// PasskeyLogin signs a user in with a passkey alone. The authenticator has
+// verified the user, so no TOTP step follows and any pending MFA challenge
+// is cleared.
+func (h *Handler) PasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Credential *PasskeyCredential `json:"credential"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Credential == nil {
+		utils.WriteError(w, http.StatusBadRequest, "credential required")
+		return
+	}
+	user, err := h.svc.FinishPasskeyLogin(r.Context(), RelyingPartyFor(r), req.Credential)
+	if err != nil {
+		var susp *SuspendedError
+		switch {
+		case errors.As(err, &susp):
+			utils.WriteJSON(w, http.StatusForbidden, map[string]string{
+				"error":  "user account is suspended",
+				"reason": susp.Reason,
+			})
+		case errors.Is(err, ErrPasskeyInvalid):
+			utils.WriteError(w, http.StatusUnauthorized, "passkey not recognised")
+		default:
+			log.Printf("auth.Handler.PasskeyLogin: %v", err)
+			utils.WriteError(w, http.StatusInternalServerError, "login failed")
+		}
+		return
+	}
+
+	if err := h.svc.SetMFARequired(r.Context(), user.ID, false); err != nil {
+		log.Printf("auth: failed to reset MFA challenge status: %v", err)
+	}
+	accessToken, refreshToken, err := h.svc.IssueTokens(r.Context(), user)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "token generation failed")
+		return
+	}
+	h.propagateAuthUser(r.Context(), user.ID, map[string]interface{}{"new_token": accessToken})
+
+	log.Printf("auth: login %q (@%s, id=%d) via passkey", user.DisplayName, user.Username, user.ID)
+	h.dispatcher.Dispatch(ievents.Job{
+		UserID:     user.ID,
+		Activity:   ievents.ActUserLoggedIn,
+		Resource:   ievents.ResUser,
+		ResourceID: user.ID,
+		IP:         ievents.ClientIP(r),
+		Meta:       map[string]interface{}{"passkey": true},
+	})
+	utils.WriteJSON(w, http.StatusOK, models.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         h.newAuthUser(r.Context(), user),
+	})
+}
+
+// LoginPasskeyOptions starts the passkey alternative to the TOTP step of a
+// password login. The answer is posted to /auth/login/totp as "passkey".
+func (h *Handler) LoginPasskeyOptions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TOTPToken string `json:"totp_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TOTPToken == "" {
+		utils.WriteError(w, http.StatusBadRequest, "totp_token required")
+		return
+	}
+	claims, err := ijwt.ValidateToken(req.TOTPToken)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "invalid or expired TOTP token")
+		return
+	}
+	h.writePasskeyMFAOptions(w, r, claims.UserID)
+}
+
+// MFAChallengePasskeyOptions starts the passkey alternative to a TOTP code
+// for /auth/mfa-challenge. MFARequiredMiddleware lets it through while a
+// challenge is pending.
+func (h *Handler) MFAChallengePasskeyOptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.UserIDFromCtx(r)
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	h.writePasskeyMFAOptions(w, r, userID)
+}
+
+func (h *Handler) writePasskeyMFAOptions(w http.ResponseWriter, r *http.Request, userID int64) {
+	opts, err := h.svc.BeginPasskeyMFA(r.Context(), RelyingPartyFor(r), userID)
+	switch {
+	case errors.Is(err, ErrNoPasskeys):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		log.Printf("auth.Handler.writePasskeyMFAOptions: %v", err)
+		utils.WriteError(w, http.StatusInternalServerError, "failed to start passkey verification")
+	default:
+		utils.WriteJSON(w, http.StatusOK, opts)
+	}
+}
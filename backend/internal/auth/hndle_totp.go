@@ -15,9 +15,10 @@ import (
 // TOTP Handlers
 func (h *Handler) LoginTOTP(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		TOTPToken  string `json:"totp_token"`
-		TOTPCode   string `json:"totp_code"`
-		BackupCode string `json:"backup_code"`
+		TOTPToken  string             `json:"totp_token"`
+		TOTPCode   string             `json:"totp_code"`
+		BackupCode string             `json:"backup_code"`
+		Passkey    *PasskeyCredential `json:"passkey"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
@@ -40,14 +41,24 @@ func (h *Handler) LoginTOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, enabled, err := h.svc.GetTOTPEnabled(r.Context(), user.ID)
-	if !enabled || err != nil {
+	methods, err := h.svc.MFAMethods(r.Context(), user.ID)
+	if len(methods) == 0 || err != nil {
 		utils.WriteError(w, http.StatusBadRequest, "2FA is not enabled for this account. Please contact support to regain access.")
 		return
 	}
+	_, enabled, _ := h.svc.GetTOTPEnabled(r.Context(), user.ID)
 
 	var valid bool
-	if req.BackupCode != "" {
+	method := MFAMethodTOTP
+	if req.Passkey != nil {
+		method = MFAMethodPasskey
+		valid, err = h.svc.VerifyPasskeyMFA(r.Context(), RelyingPartyFor(r), user.ID, req.Passkey)
+		if err != nil {
+			log.Printf("user.Handler.loginTOTP: passkey verification: %v", err)
+			utils.WriteError(w, http.StatusInternalServerError, "verification failed")
+			return
+		}
+	} else if req.BackupCode != "" {
 		// Try backup code.
 		valid, err = h.svc.ValidateTOTPBackupCode(r.Context(), user.ID, req.BackupCode)
 		if err != nil {
@@ -63,7 +74,7 @@ func (h *Handler) LoginTOTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	} else {
-		utils.WriteError(w, http.StatusBadRequest, "totp_code or backup_code required")
+		utils.WriteError(w, http.StatusBadRequest, "totp_code, backup_code or passkey required")
 		return
 	}
 
@@ -95,7 +106,7 @@ func (h *Handler) LoginTOTP(w http.ResponseWriter, r *http.Request) {
 		Resource:   ievents.ResUser,
 		ResourceID: user.ID,
 		IP:         ievents.ClientIP(r),
-		Meta:       map[string]interface{}{"2fa": true},
+		Meta:       map[string]interface{}{"2fa": true, "method": method},
 	})
 	utils.WriteJSON(w, http.StatusOK, models.AuthResponse{
 		AccessToken: accessToken,
@@ -233,9 +244,9 @@ func (h *Handler) AdminTriggerMFAChallenge(w http.ResponseWriter, r *http.Reques
 	if !h.userSvc.CheckManagePowerLevel(w, actorID, targetID) {
 		return
 	}
-	// Verify target user actually has TOTP enabled
-	_, enabled, err := h.svc.GetTOTPEnabled(r.Context(), targetID)
-	if err != nil || !enabled {
+	// Verify target user actually has TOTP or a passkey set up
+	methods, err := h.svc.MFAMethods(r.Context(), targetID)
+	if err != nil || len(methods) == 0 {
 		utils.WriteError(w, http.StatusBadRequest, "user does not have a method like TOTP enabled")
 		return
 	}
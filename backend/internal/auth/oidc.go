@@ -0,0 +1,724 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/skaia/backend/models"
+)
+
+// OIDCConfigKey is the site_config key holding the tenant's OIDC providers,
+// a JSON object keyed by provider ID. Upserts merge per provider; setting a
+// provider to null removes it.
+const OIDCConfigKey = "oidc_providers"
+
+const (
+	oidcStateTTL     = 10 * time.Minute
+	oidcLoginCodeTTL = time.Minute
+	oidcMetadataTTL  = time.Hour
+	oidcClockSkew    = time.Minute
+)
+
+var (
+	ErrOIDCProviderNotFound = errors.New("sign-in provider not found")
+	ErrOIDCInvalidState     = errors.New("sign-in request expired or invalid")
+	ErrOIDCNoAccount        = errors.New("no account is linked to this sign-in")
+	ErrOIDCDomainNotAllowed = errors.New("email domain is not allowed for this sign-in")
+	ErrOIDCAlreadyLinked    = errors.New("this sign-in is already linked to another account")
+	ErrOIDCAccountSuspended = errors.New("account suspended")
+)
+
+var oidcProviderID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// OIDCProvider is one "Sign in with" provider configured for the tenant.
+type OIDCProvider struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Issuer         string   `json:"issuer"`
+	ClientID       string   `json:"client_id"`
+	ClientSecret   string   `json:"client_secret,omitempty"`
+	Scopes         []string `json:"scopes,omitempty"`
+	AllowSignup    bool     `json:"allow_signup"`
+	DefaultRole    string   `json:"default_role,omitempty"`
+	LinkByEmail    bool     `json:"link_by_email"`
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	Disabled       bool     `json:"disabled,omitempty"`
+}
+
+// Validate checks the fields a provider cannot work without.
+func (p *OIDCProvider) Validate() error {
+	if !oidcProviderID.MatchString(p.ID) {
+		return fmt.Errorf("provider id must be lowercase letters, digits, - or _")
+	}
+	u, err := url.Parse(p.Issuer)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && !(u.Scheme == "http" && isLoopbackHost(u.Hostname()))) {
+		return fmt.Errorf("issuer must be an https URL")
+	}
+	if p.ClientID == "" {
+		return fmt.Errorf("client_id required")
+	}
+	return nil
+}
+
+func (p *OIDCProvider) scopes() string {
+	scopes := []string{"openid"}
+	for _, s := range p.Scopes {
+		if s != "openid" {
+			scopes = append(scopes, s)
+		}
+	}
+	if len(p.Scopes) == 0 {
+		scopes = append(scopes, "email", "profile")
+	}
+	return strings.Join(scopes, " ")
+}
+
+func (p *OIDCProvider) domainAllowed(email string) bool {
+	if len(p.AllowedDomains) == 0 {
+		return true
+	}
+	_, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok {
+		return false
+	}
+	for _, d := range p.AllowedDomains {
+		if strings.EqualFold(strings.TrimPrefix(d, "@"), domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoopbackHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// SiteConfigStore reads and merges site_config values; config.Service
+// satisfies it.
+type SiteConfigStore interface {
+	GetConfig(key string) (*models.SiteConfig, error)
+	UpsertConfig(key, valueJSON string) error
+}
+
+// UseSiteConfig gives the service access to the tenant's site_config, where
+// OIDC providers are configured.
+func (s *Service) UseSiteConfig(cfg SiteConfigStore) {
+	s.siteConfig = cfg
+}
+
+// OIDCProviders returns the tenant's configured providers, sorted by name.
+func (s *Service) OIDCProviders(ctx context.Context) ([]*OIDCProvider, error) {
+	if s.siteConfig == nil {
+		return nil, nil
+	}
+	sc, err := s.siteConfig.GetConfig(OIDCConfigKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var byID map[string]*OIDCProvider
+	if err := json.Unmarshal([]byte(sc.Value), &byID); err != nil {
+		return nil, fmt.Errorf("site_config %s: %w", OIDCConfigKey, err)
+	}
+	providers := make([]*OIDCProvider, 0, len(byID))
+	for id, p := range byID {
+		if p == nil {
+			continue
+		}
+		p.ID = id
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name < providers[j].Name })
+	return providers, nil
+}
+
+// storedOIDCProvider returns a provider as configured, disabled or not.
+func (s *Service) storedOIDCProvider(ctx context.Context, id string) (*OIDCProvider, error) {
+	providers, err := s.OIDCProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range providers {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, ErrOIDCProviderNotFound
+}
+
+// oidcProvider returns a provider users may sign in with.
+func (s *Service) oidcProvider(ctx context.Context, id string) (*OIDCProvider, error) {
+	p, err := s.storedOIDCProvider(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Disabled {
+		return nil, ErrOIDCProviderNotFound
+	}
+	return p, nil
+}
+
+// SaveOIDCProvider adds or replaces a provider. An empty client secret keeps
+// the stored one.
+func (s *Service) SaveOIDCProvider(ctx context.Context, p *OIDCProvider) error {
+	if s.siteConfig == nil {
+		return errors.New("site config unavailable")
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.ClientSecret == "" {
+		if old, err := s.storedOIDCProvider(ctx, p.ID); err == nil {
+			p.ClientSecret = old.ClientSecret
+		}
+	}
+	if p.Name == "" {
+		p.Name = p.ID
+	}
+	p.Issuer = strings.TrimRight(p.Issuer, "/")
+	value, err := json.Marshal(map[string]*OIDCProvider{p.ID: p})
+	if err != nil {
+		return err
+	}
+	return s.siteConfig.UpsertConfig(OIDCConfigKey, string(value))
+}
+
+// RemoveOIDCProvider removes a provider. Identities linked through it stay
+// recorded so re-adding the provider restores them.
+func (s *Service) RemoveOIDCProvider(ctx context.Context, id string) error {
+	if s.siteConfig == nil {
+		return errors.New("site config unavailable")
+	}
+	value, _ := json.Marshal(map[string]any{id: nil})
+	return s.siteConfig.UpsertConfig(OIDCConfigKey, string(value))
+}
+
+// oidcMetadata is the part of an issuer's discovery document and key set
+// the login flow uses.
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	keys    map[string]any
+	fetched time.Time
+}
+
+// oidcState is a sign-in started by BeginOIDC and not yet completed.
+type oidcState struct {
+	Provider    string
+	Nonce       string
+	Verifier    string
+	RedirectURI string
+	ReturnTo    string
+	LinkUserID  int64
+	ExpiresAt   time.Time
+}
+
+type oidcLoginCode struct {
+	UserID    int64
+	ExpiresAt time.Time
+}
+
+// OIDCResult is the outcome of a completed sign-in.
+type OIDCResult struct {
+	User     *models.User
+	Created  bool
+	Linked   bool
+	ReturnTo string
+	LinkOnly bool
+}
+
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s *Service) oidcHTTP() *http.Client {
+	if s.httpClient != nil {
+		return s.httpClient
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+func (s *Service) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.oidcHTTP().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// discover fetches and caches an issuer's metadata and signing keys. A
+// refresh is forced when a token names a key that is not cached, so key
+// rotation at the provider does not lock users out.
+func (s *Service) discover(ctx context.Context, issuer string, refresh bool) (*oidcMetadata, error) {
+	s.oidcMu.Lock()
+	cached := s.oidcMetadata[issuer]
+	s.oidcMu.Unlock()
+	if cached != nil && !refresh && time.Since(cached.fetched) < oidcMetadataTTL {
+		return cached, nil
+	}
+
+	md := &oidcMetadata{}
+	if err := s.getJSON(ctx, issuer+"/.well-known/openid-configuration", md); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimRight(md.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", md.Issuer, issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: incomplete provider metadata")
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.getJSON(ctx, md.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc keys: %w", err)
+	}
+	md.keys = map[string]any{}
+	for _, k := range set.Keys {
+		if pub, err := k.publicKey(); err == nil && (k.Use == "" || k.Use == "sig") {
+			md.keys[k.Kid] = pub
+		}
+	}
+	md.fetched = time.Now()
+
+	s.oidcMu.Lock()
+	s.oidcMetadata[issuer] = md
+	s.oidcMu.Unlock()
+	return md, nil
+}
+
+// jsonWebKey is an RSA or EC public key from a provider's JWKS.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	num := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	switch k.Kty {
+	case "RSA":
+		n, err := num(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := num(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := num(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := num(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// BeginOIDC starts a sign-in with a provider and returns the URL to send the
+// browser to, along with the state key the callback must present. linkUserID,
+// when set, links the provider account to that user instead of signing in.
+func (s *Service) BeginOIDC(ctx context.Context, providerID, redirectURI, returnTo string, linkUserID int64) (string, string, error) {
+	p, err := s.oidcProvider(ctx, providerID)
+	if err != nil {
+		return "", "", err
+	}
+	md, err := s.discover(ctx, p.Issuer, false)
+	if err != nil {
+		return "", "", err
+	}
+
+	state := oidcState{
+		Provider:    p.ID,
+		Nonce:       randomToken(16),
+		Verifier:    randomToken(32),
+		RedirectURI: redirectURI,
+		ReturnTo:    returnTo,
+		LinkUserID:  linkUserID,
+		ExpiresAt:   time.Now().Add(oidcStateTTL),
+	}
+	key := randomToken(24)
+	s.oidcMu.Lock()
+	s.cleanupOIDCLocked(time.Now())
+	s.oidcStates[key] = state
+	s.oidcMu.Unlock()
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {p.scopes()},
+		"state":                 {key},
+		"nonce":                 {state.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(md.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return md.AuthorizationEndpoint + sep + q.Encode(), key, nil
+}
+
+func (s *Service) cleanupOIDCLocked(now time.Time) {
+	for k, st := range s.oidcStates {
+		if !now.Before(st.ExpiresAt) {
+			delete(s.oidcStates, k)
+		}
+	}
+	for k, c := range s.oidcLoginCodes {
+		if !now.Before(c.ExpiresAt) {
+			delete(s.oidcLoginCodes, k)
+		}
+	}
+}
+
+// takeOIDCState returns and forgets a pending sign-in, so a state value is
+// only ever used once.
+func (s *Service) takeOIDCState(key string) (oidcState, error) {
+	s.oidcMu.Lock()
+	defer s.oidcMu.Unlock()
+	st, ok := s.oidcStates[key]
+	delete(s.oidcStates, key)
+	if !ok || !time.Now().Before(st.ExpiresAt) {
+		return oidcState{}, ErrOIDCInvalidState
+	}
+	return st, nil
+}
+
+// oidcClaims are the ID token claims Skaia reads.
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// verified reports email_verified, which some providers send as a string.
+func (c *oidcClaims) verified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// exchangeCode redeems an authorization code and verifies the ID token.
+func (s *Service) exchangeCode(ctx context.Context, p *OIDCProvider, st oidcState, code string) (*oidcClaims, error) {
+	md, err := s.discover(ctx, p.Issuer, false)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {st.RedirectURI},
+		"client_id":     {p.ClientID},
+		"code_verifier": {st.Verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+	resp, err := s.oidcHTTP().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %w", err)
+	}
+	defer resp.Body.Close()
+	var tok struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tok); err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK || tok.IDToken == "" {
+		return nil, fmt.Errorf("oidc token exchange: %s %s", tok.Error, tok.ErrorDescription)
+	}
+	return s.verifyIDToken(ctx, p, md, tok.IDToken, st.Nonce)
+}
+
+func (s *Service) verifyIDToken(ctx context.Context, p *OIDCProvider, md *oidcMetadata, raw, nonce string) (*oidcClaims, error) {
+	claims := &oidcClaims{}
+	keyFunc := func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := md.keys[kid]; ok {
+			return key, nil
+		}
+		fresh, err := s.discover(ctx, p.Issuer, true)
+		if err != nil {
+			return nil, err
+		}
+		if key, ok := fresh.keys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	_, err := jwt.ParseWithClaims(raw, claims, keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	if claims.Subject == "" || claims.Nonce != nonce {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+// CompleteOIDC finishes a sign-in: it redeems the code, then finds the
+// account by an existing link, links the signed-in user or an account with
+// the same verified email, or creates a user when the provider allows it.
+// Allowed domains and new accounts only ever trust a verified email.
+func (s *Service) CompleteOIDC(ctx context.Context, stateKey, code string) (*OIDCResult, error) {
+	st, err := s.takeOIDCState(stateKey)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.oidcProvider(ctx, st.Provider)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := s.exchangeCode(ctx, p, st, code)
+	if err != nil {
+		return nil, err
+	}
+	res := &OIDCResult{ReturnTo: st.ReturnTo, LinkOnly: st.LinkUserID != 0}
+
+	ident, err := s.repo.GetOIDCIdentity(ctx, p.ID, claims.Subject)
+	switch {
+	case err == nil:
+		if st.LinkUserID != 0 && ident.UserID != st.LinkUserID {
+			return nil, ErrOIDCAlreadyLinked
+		}
+		res.User, err = s.userService.GetByID(ident.UserID)
+		if err != nil {
+			return nil, ErrOIDCNoAccount
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	case st.LinkUserID != 0:
+		if res.User, err = s.userService.GetByID(st.LinkUserID); err != nil {
+			return nil, err
+		}
+		res.Linked = true
+	case len(p.AllowedDomains) > 0 && (!claims.verified() || !p.domainAllowed(claims.Email)):
+		return nil, ErrOIDCDomainNotAllowed
+	default:
+		if p.LinkByEmail && claims.Email != "" && claims.verified() {
+			if u, err := s.userService.GetByEmail(claims.Email); err == nil {
+				res.User, res.Linked = u, true
+			}
+		}
+		if res.User == nil {
+			if !p.AllowSignup || claims.Email == "" || !claims.verified() {
+				return nil, ErrOIDCNoAccount
+			}
+			if res.User, err = s.createOIDCUser(p, claims); err != nil {
+				return nil, err
+			}
+			res.Created, res.Linked = true, true
+		}
+	}
+	if res.User.IsSuspended {
+		return nil, ErrOIDCAccountSuspended
+	}
+
+	if res.Linked {
+		ident, err = s.repo.LinkOIDCIdentity(ctx, res.User.ID, p.ID, claims.Subject, claims.Email)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.TouchOIDCIdentity(ctx, ident.ID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// createOIDCUser makes a just-in-time account for a first sign-in. The
+// username comes from the provider and is suffixed until it is free.
+func (s *Service) createOIDCUser(p *OIDCProvider, c *oidcClaims) (*models.User, error) {
+	base := c.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(c.Email, "@")
+	}
+	base = strings.Trim(usernameUnsafe.ReplaceAllString(strings.ToLower(base), "_"), "_")
+	if len(base) < 3 {
+		base = "user_" + base
+	}
+	if len(base) > 24 {
+		base = base[:24]
+	}
+	username := base
+	for i := 2; ; i++ {
+		if _, err := s.userService.GetByUsername(username); err != nil {
+			break
+		}
+		if i > 50 {
+			return nil, errors.New("could not choose a free username")
+		}
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+	name := c.Name
+	if name == "" {
+		name = username
+	}
+	user, err := s.userService.CreateUserFromRegisterRequest(&models.RegisterRequest{
+		Username:    username,
+		Email:       c.Email,
+		DisplayName: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p.DefaultRole != "" && p.DefaultRole != "member" {
+		if err := s.userService.AddRoleByName(user.ID, p.DefaultRole); err != nil {
+			return nil, fmt.Errorf("assign role %s: %w", p.DefaultRole, err)
+		}
+		if user, err = s.userService.GetByID(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// IssueOIDCLoginCode returns a one-time code the browser trades for tokens,
+// so tokens never appear in a redirect URL.
+func (s *Service) IssueOIDCLoginCode(userID int64) string {
+	code := randomToken(24)
+	s.oidcMu.Lock()
+	s.oidcLoginCodes[code] = oidcLoginCode{UserID: userID, ExpiresAt: time.Now().Add(oidcLoginCodeTTL)}
+	s.oidcMu.Unlock()
+	return code
+}
+
+// RedeemOIDCLoginCode trades a login code for the user it was issued to.
+func (s *Service) RedeemOIDCLoginCode(ctx context.Context, code string) (*models.User, error) {
+	s.oidcMu.Lock()
+	c, ok := s.oidcLoginCodes[code]
+	delete(s.oidcLoginCodes, code)
+	s.oidcMu.Unlock()
+	if !ok || !time.Now().Before(c.ExpiresAt) {
+		return nil, ErrOIDCInvalidState
+	}
+	return s.userService.GetByID(c.UserID)
+}
+
+// IssueTokens returns an access and refresh token pair for a user signed in
+// by other means than a password.
+func (s *Service) IssueTokens(ctx context.Context, user *models.User) (string, string, error) {
+	access, err := s.generateAccessToken(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+	refresh, err := s.generateRefreshToken(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+	return access, refresh, nil
+}
+
+// ListOIDCIdentities returns the provider accounts linked to a user.
+func (s *Service) ListOIDCIdentities(ctx context.Context, userID int64) ([]*models.OIDCIdentity, error) {
+	return s.repo.ListOIDCIdentities(ctx, userID)
+}
+
+// UnlinkOIDCIdentity removes one of a user's provider links. The last link
+// of an account without a password or passkey cannot be removed.
+func (s *Service) UnlinkOIDCIdentity(ctx context.Context, userID, identityID int64) error {
+	idents, err := s.repo.ListOIDCIdentities(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(idents) <= 1 {
+		passkeys, err := s.repo.ListPasskeys(ctx, userID)
+		if err != nil {
+			return err
+		}
+		cred, err := s.repo.GetCredentialByUserID(ctx, userID)
+		if len(passkeys) == 0 && (err != nil || cred.PasswordHash == "") {
+			return errors.New("set a password before removing your last sign-in provider")
+		}
+	}
+	return s.repo.UnlinkOIDCIdentity(ctx, userID, identityID)
+}
@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/skaia/backend/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIssuer is a minimal OIDC provider: discovery, JWKS and a token
+// endpoint that answers one authorization code with a signed ID token.
+type mockIssuer struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	clientID  string
+	claims    jwt.MapClaims // extra claims for the next ID token
+	nonce     string
+	challenge string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	m := &mockIssuer{key: key, clientID: "skaia-test"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		id, secret, _ := r.BasicAuth()
+		if r.PostForm.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge ||
+			id != m.clientID || secret != "s3cret" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss": m.URL, "aud": m.clientID, "nonce": m.nonce,
+			"iat": time.Now().Unix(), "exp": time.Now().Add(5 * time.Minute).Unix(),
+		}
+		for k, v := range m.claims {
+			claims[k] = v
+		}
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		tok.Header["kid"] = "k1"
+		signed, _ := tok.SignedString(m.key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// signIn runs the browser's half of the flow: it starts a sign-in, plays the
+// provider's authorize step and completes the callback.
+func (m *mockIssuer) signIn(t *testing.T, svc *Service, provider string, linkUserID int64, claims jwt.MapClaims) (*OIDCResult, error) {
+	t.Helper()
+	authURL, state, err := svc.BeginOIDC(context.Background(), provider, "https://tenant.example/api/auth/oidc/"+provider+"/callback", "/forum", linkUserID)
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+	require.Equal(t, m.clientID, q.Get("client_id"))
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+	require.Equal(t, state, q.Get("state"))
+	m.nonce, m.challenge, m.claims = q.Get("nonce"), q.Get("code_challenge"), claims
+	return svc.CompleteOIDC(context.Background(), q.Get("state"), "good-code")
+}
+
+// fakeSiteConfig merges top-level keys on upsert like site_config does.
+type fakeSiteConfig map[string]string
+
+func (c fakeSiteConfig) GetConfig(key string) (*models.SiteConfig, error) {
+	v, ok := c[key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &models.SiteConfig{Key: key, Value: v}, nil
+}
+
+func (c fakeSiteConfig) UpsertConfig(key, valueJSON string) error {
+	merged := map[string]json.RawMessage{}
+	if v, ok := c[key]; ok {
+		json.Unmarshal([]byte(v), &merged)
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(valueJSON), &patch); err != nil {
+		return err
+	}
+	for k, v := range patch {
+		merged[k] = v
+	}
+	out, _ := json.Marshal(merged)
+	c[key] = string(out)
+	return nil
+}
+
+func newOIDCTestService(t *testing.T, p OIDCProvider) (*Service, *fakeAuthRepository, *fakeUserService, *mockIssuer) {
+	t.Helper()
+	issuer := newMockIssuer(t)
+	svc, repo, users := newTestService()
+	svc.UseSiteConfig(fakeSiteConfig{})
+	p.Issuer, p.ClientID, p.ClientSecret = issuer.URL, issuer.clientID, "s3cret"
+	require.NoError(t, svc.SaveOIDCProvider(context.Background(), &p))
+	return svc, repo, users, issuer
+}
+
+func TestOIDCJustInTimeSignupUsesRefreshFlow(t *testing.T) {
+	svc, repo, _, issuer := newOIDCTestService(t, OIDCProvider{
+		ID: "corp", Name: "Corp SSO", AllowSignup: true, DefaultRole: "editor", AllowedDomains: []string{"example.com"},
+	})
+	claims := jwt.MapClaims{"sub": "u-100", "email": "ada@example.com", "email_verified": true, "preferred_username": "Ada.L", "name": "Ada"}
+
+	res, err := issuer.signIn(t, svc, "corp", 0, claims)
+	require.NoError(t, err)
+	assert.True(t, res.Created)
+	assert.Equal(t, "/forum", res.ReturnTo)
+	assert.Equal(t, "ada_l", res.User.Username)
+	assert.Contains(t, res.User.Roles, "editor")
+	require.Len(t, repo.identities, 1)
+	assert.NotNil(t, repo.identities[0].LastLoginAt)
+
+	again, err := issuer.signIn(t, svc, "corp", 0, claims)
+	require.NoError(t, err)
+	assert.False(t, again.Created)
+	assert.Equal(t, res.User.ID, again.User.ID)
+
+	code := svc.IssueOIDCLoginCode(res.User.ID)
+	user, err := svc.RedeemOIDCLoginCode(context.Background(), code)
+	require.NoError(t, err)
+	_, err = svc.RedeemOIDCLoginCode(context.Background(), code)
+	assert.ErrorIs(t, err, ErrOIDCInvalidState, "login codes are one-time")
+
+	_, refresh, err := svc.IssueTokens(context.Background(), user)
+	require.NoError(t, err)
+	access, err := svc.RefreshToken(context.Background(), refresh)
+	require.NoError(t, err)
+	assert.NotEmpty(t, access)
+
+	_, err = issuer.signIn(t, svc, "corp", 0, jwt.MapClaims{"sub": "u-101", "email": "eve@evil.test", "email_verified": true})
+	assert.ErrorIs(t, err, ErrOIDCDomainNotAllowed)
+	_, err = issuer.signIn(t, svc, "corp", 0, jwt.MapClaims{"sub": "u-102", "email": "mallory@example.com", "email_verified": false})
+	assert.ErrorIs(t, err, ErrOIDCDomainNotAllowed, "an unverified email must not pass the domain gate")
+	_, err = issuer.signIn(t, svc, "corp", 0, jwt.MapClaims{"sub": "u-103"})
+	assert.ErrorIs(t, err, ErrOIDCDomainNotAllowed, "a sign-in without an email must not pass the domain gate")
+}
+
+func TestOIDCSignupRequiresVerifiedEmail(t *testing.T) {
+	svc, repo, _, issuer := newOIDCTestService(t, OIDCProvider{ID: "open", AllowSignup: true})
+	_, err := issuer.signIn(t, svc, "open", 0, jwt.MapClaims{"sub": "u-200", "email": "someone@example.com"})
+	assert.ErrorIs(t, err, ErrOIDCNoAccount)
+	assert.Empty(t, repo.identities)
+}
+
+func TestOIDCLinksExistingAccounts(t *testing.T) {
+	svc, repo, users, issuer := newOIDCTestService(t, OIDCProvider{ID: "corp", LinkByEmail: true})
+	grace := users.mustCreate(t, "grace@example.com")
+	other := users.mustCreate(t, "other@example.com")
+
+	_, err := issuer.signIn(t, svc, "corp", 0, jwt.MapClaims{"sub": "g-1", "email": "grace@example.com", "email_verified": false})
+	assert.ErrorIs(t, err, ErrOIDCNoAccount, "unverified email must not link")
+
+	res, err := issuer.signIn(t, svc, "corp", 0, jwt.MapClaims{"sub": "g-1", "email": "grace@example.com", "email_verified": "true"})
+	require.NoError(t, err)
+	assert.True(t, res.Linked)
+	assert.Equal(t, grace.ID, res.User.ID)
+
+	res, err = issuer.signIn(t, svc, "corp", other.ID, jwt.MapClaims{"sub": "o-1"})
+	require.NoError(t, err)
+	assert.True(t, res.LinkOnly)
+	assert.Equal(t, other.ID, res.User.ID)
+
+	_, err = issuer.signIn(t, svc, "corp", other.ID, jwt.MapClaims{"sub": "g-1"})
+	assert.ErrorIs(t, err, ErrOIDCAlreadyLinked)
+	assert.Len(t, repo.identities, 2)
+
+	require.NoError(t, repo.CreateCredentialHash(other.ID, "CorrectHorse1!"))
+	idents, _ := svc.ListOIDCIdentities(context.Background(), grace.ID)
+	require.Len(t, idents, 1)
+	assert.Error(t, svc.UnlinkOIDCIdentity(context.Background(), grace.ID, idents[0].ID), "last sign-in of a passwordless account")
+	idents, _ = svc.ListOIDCIdentities(context.Background(), other.ID)
+	assert.NoError(t, svc.UnlinkOIDCIdentity(context.Background(), other.ID, idents[0].ID))
+}
+
+func TestOIDCRejectsForgedSignIns(t *testing.T) {
+	svc, _, _, issuer := newOIDCTestService(t, OIDCProvider{ID: "corp", AllowSignup: true})
+
+	_, err := issuer.signIn(t, svc, "corp", 0, jwt.MapClaims{"sub": "x", "email": "x@example.com", "nonce": "replayed"})
+	assert.ErrorContains(t, err, "nonce")
+
+	_, err = issuer.signIn(t, svc, "corp", 0, jwt.MapClaims{"sub": "x", "email": "x@example.com", "aud": "someone-else"})
+	assert.ErrorContains(t, err, "invalid id token")
+
+	_, err = issuer.signIn(t, svc, "corp", 0, jwt.MapClaims{"sub": "x", "email": "x@example.com", "iss": "https://evil.test"})
+	assert.ErrorContains(t, err, "invalid id token")
+
+	_, err = svc.CompleteOIDC(context.Background(), "never-issued", "good-code")
+	assert.ErrorIs(t, err, ErrOIDCInvalidState)
+}
+
+func TestOIDCProviderConfig(t *testing.T) {
+	svc, _, _, issuer := newOIDCTestService(t, OIDCProvider{ID: "corp", Name: "Corp"})
+	require.NoError(t, svc.SaveOIDCProvider(context.Background(), &OIDCProvider{ID: "corp", Name: "Corp SSO", Issuer: issuer.URL + "/", ClientID: issuer.clientID}))
+	require.NoError(t, svc.SaveOIDCProvider(context.Background(), &OIDCProvider{ID: "gh", Issuer: "https://github.example", ClientID: "x"}))
+
+	providers, err := svc.OIDCProviders(context.Background())
+	require.NoError(t, err)
+	require.Len(t, providers, 2)
+	assert.Equal(t, "Corp SSO", providers[0].Name)
+	assert.Equal(t, "s3cret", providers[0].ClientSecret, "an empty secret keeps the stored one")
+	assert.Equal(t, issuer.URL, providers[0].Issuer)
+
+	require.NoError(t, svc.SaveOIDCProvider(context.Background(), &OIDCProvider{ID: "corp", Name: "Corp SSO", Issuer: issuer.URL, ClientID: issuer.clientID, Disabled: true}))
+	require.NoError(t, svc.SaveOIDCProvider(context.Background(), &OIDCProvider{ID: "corp", Name: "Corp SSO", Issuer: issuer.URL, ClientID: issuer.clientID, Disabled: true}))
+	providers, _ = svc.OIDCProviders(context.Background())
+	assert.Equal(t, "s3cret", providers[0].ClientSecret, "saving a disabled provider keeps its secret")
+
+	assert.Error(t, svc.SaveOIDCProvider(context.Background(), &OIDCProvider{ID: "Bad ID", Issuer: "https://x.example", ClientID: "x"}))
+	assert.Error(t, svc.SaveOIDCProvider(context.Background(), &OIDCProvider{ID: "plain", Issuer: "http://idp.example", ClientID: "x"}))
+
+	require.NoError(t, svc.RemoveOIDCProvider(context.Background(), "gh"))
+	providers, _ = svc.OIDCProviders(context.Background())
+	assert.Len(t, providers, 1)
+	_, _, err = svc.BeginOIDC(context.Background(), "gh", "https://tenant.example/cb", "/", 0)
+	assert.ErrorIs(t, err, ErrOIDCProviderNotFound)
+}
+
+func TestOIDCStateCookieBindsBrowser(t *testing.T) {
+	start := httptest.NewRecorder()
+	setOIDCStateCookie(start, httptest.NewRequest(http.MethodGet, "https://tenant.example/api/auth/oidc/corp/start", nil), "corp", "victim-state")
+	cookies := start.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	assert.NotContains(t, cookies[0].Value, "victim-state", "the cookie holds a hash, not the state")
+
+	callback := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/corp/callback", nil)
+	callback.AddCookie(cookies[0])
+	assert.True(t, oidcStateFromBrowser(callback, "victim-state"))
+	assert.False(t, oidcStateFromBrowser(callback, "attacker-state"), "a state from another browser")
+	assert.False(t, oidcStateFromBrowser(httptest.NewRequest(http.MethodGet, "/api/auth/oidc/corp/callback", nil), "victim-state"), "no cookie")
+}
+
+func TestSafeReturnTo(t *testing.T) {
+	for in, want := range map[string]string{
+		"/forum?x=1":          "/forum?x=1",
+		"//evil.test":         "/",
+		"/\\evil.test":        "/",
+		"https://evil.test/x": "/",
+		"":                    "/",
+	} {
+		assert.Equal(t, want, safeReturnTo(in), in)
+	}
+}
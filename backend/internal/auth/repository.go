@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/lib/pq"
+
 	"github.com/skaia/backend/database"
 	"github.com/skaia/backend/models"
 )
@@ -26,6 +28,19 @@ type Repository interface {
 	SetMFARequired(ctx context.Context, userID int64, required bool) error
 	SetMFAChallenge(ctx context.Context, userID int64, required bool, reason, action string) error
 	GetMFARequired(ctx context.Context, userID int64) (models.MFAChallengeStatus, error)
+
+	GetOIDCIdentity(ctx context.Context, provider, subject string) (*models.OIDCIdentity, error)
+	LinkOIDCIdentity(ctx context.Context, userID int64, provider, subject, email string) (*models.OIDCIdentity, error)
+	TouchOIDCIdentity(ctx context.Context, id int64) error
+	ListOIDCIdentities(ctx context.Context, userID int64) ([]*models.OIDCIdentity, error)
+	UnlinkOIDCIdentity(ctx context.Context, userID, id int64) error
+
+	CreatePasskey(ctx context.Context, p *models.Passkey) error
+	GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*models.Passkey, error)
+	ListPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error)
+	TouchPasskey(ctx context.Context, id int64, signCount uint32) error
+	RenamePasskey(ctx context.Context, userID, id int64, name string) error
+	DeletePasskey(ctx context.Context, userID, id int64) error
 }
 
 // SQLRepository implements Repository using a SQL database.
@@ -197,3 +212,153 @@ func (r *SQLRepository) GetMFARequired(ctx context.Context, userID int64) (model
 	}
 	return status, nil
 }
+
+// OIDC identity methods
+const oidcIdentityCols = `i.id,i.user_id,i.provider,i.subject,i.email,i.created_at,i.last_login_at`
+
+func scanOIDCIdentity(row interface{ Scan(...any) error }) (*models.OIDCIdentity, error) {
+	i := &models.OIDCIdentity{}
+	if err := row.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt, &i.LastLoginAt); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func (r *SQLRepository) GetOIDCIdentity(ctx context.Context, provider, subject string) (*models.OIDCIdentity, error) {
+	return scanOIDCIdentity(r.executor(ctx).QueryRowContext(ctx, `
+		SELECT `+oidcIdentityCols+`
+		FROM auth_identities i JOIN users u ON u.id=i.user_id
+		WHERE i.provider=$1 AND i.subject=$2 AND i.unlinked_at IS NULL AND u.deleted_at IS NULL`, provider, subject))
+}
+
+// LinkOIDCIdentity records a link, or refreshes the email of an existing one.
+// A subject already linked to another user is refused.
+func (r *SQLRepository) LinkOIDCIdentity(ctx context.Context, userID int64, provider, subject, email string) (*models.OIDCIdentity, error) {
+	ident, err := scanOIDCIdentity(r.executor(ctx).QueryRowContext(ctx, `
+		INSERT INTO auth_identities AS i (user_id,provider,subject,email) VALUES ($1,$2,$3,$4)
+		ON CONFLICT (provider,subject) WHERE unlinked_at IS NULL
+		DO UPDATE SET email=EXCLUDED.email WHERE i.user_id=EXCLUDED.user_id
+		RETURNING `+oidcIdentityCols, userID, provider, subject, email))
+	if err == sql.ErrNoRows {
+		return nil, ErrOIDCAlreadyLinked
+	}
+	return ident, err
+}
+
+func (r *SQLRepository) TouchOIDCIdentity(ctx context.Context, id int64) error {
+	_, err := r.executor(ctx).ExecContext(ctx, `UPDATE auth_identities SET last_login_at=NOW() WHERE id=$1`, id)
+	return err
+}
+
+func (r *SQLRepository) ListOIDCIdentities(ctx context.Context, userID int64) ([]*models.OIDCIdentity, error) {
+	rows, err := r.executor(ctx).QueryContext(ctx, `
+		SELECT `+oidcIdentityCols+` FROM auth_identities i
+		WHERE i.user_id=$1 AND i.unlinked_at IS NULL ORDER BY i.provider, i.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var idents []*models.OIDCIdentity
+	for rows.Next() {
+		i, err := scanOIDCIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		idents = append(idents, i)
+	}
+	return idents, rows.Err()
+}
+
+func (r *SQLRepository) UnlinkOIDCIdentity(ctx context.Context, userID, id int64) error {
+	res, err := r.executor(ctx).ExecContext(ctx, `
+		UPDATE auth_identities SET unlinked_at=NOW()
+		WHERE id=$1 AND user_id=$2 AND unlinked_at IS NULL`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const passkeyCols = `id,user_id,credential_id,public_key,sign_count,name,aaguid,transports,created_at,last_used_at`
+
+func scanPasskey(row interface{ Scan(...any) error }) (*models.Passkey, error) {
+	p := &models.Passkey{}
+	var signCount int64
+	if err := row.Scan(&p.ID, &p.UserID, &p.CredentialID, &p.PublicKey, &signCount, &p.Name, &p.AAGUID,
+		pq.Array(&p.Transports), &p.CreatedAt, &p.LastUsedAt); err != nil {
+		return nil, err
+	}
+	p.SignCount = uint32(signCount)
+	return p, nil
+}
+
+func (r *SQLRepository) CreatePasskey(ctx context.Context, p *models.Passkey) error {
+	if p.Transports == nil {
+		p.Transports = []string{}
+	}
+	return r.executor(ctx).QueryRowContext(ctx, `
+		INSERT INTO auth_webauthn_credentials (user_id,credential_id,public_key,sign_count,name,aaguid,transports)
+		VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id,created_at`,
+		p.UserID, p.CredentialID, p.PublicKey, int64(p.SignCount), p.Name, p.AAGUID, pq.Array(p.Transports),
+	).Scan(&p.ID, &p.CreatedAt)
+}
+
+func (r *SQLRepository) GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*models.Passkey, error) {
+	return scanPasskey(r.executor(ctx).QueryRowContext(ctx, `
+		SELECT `+passkeyCols+` FROM auth_webauthn_credentials
+		WHERE credential_id=$1 AND cleared_at IS NULL`, credentialID))
+}
+
+func (r *SQLRepository) ListPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error) {
+	rows, err := r.executor(ctx).QueryContext(ctx, `
+		SELECT `+passkeyCols+` FROM auth_webauthn_credentials
+		WHERE user_id=$1 AND cleared_at IS NULL ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var passkeys []*models.Passkey
+	for rows.Next() {
+		p, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, p)
+	}
+	return passkeys, rows.Err()
+}
+
+func (r *SQLRepository) TouchPasskey(ctx context.Context, id int64, signCount uint32) error {
+	_, err := r.executor(ctx).ExecContext(ctx, `
+		UPDATE auth_webauthn_credentials SET sign_count=$2,last_used_at=NOW() WHERE id=$1`, id, int64(signCount))
+	return err
+}
+
+func (r *SQLRepository) RenamePasskey(ctx context.Context, userID, id int64, name string) error {
+	res, err := r.executor(ctx).ExecContext(ctx, `
+		UPDATE auth_webauthn_credentials SET name=$3
+		WHERE id=$1 AND user_id=$2 AND cleared_at IS NULL`, id, userID, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *SQLRepository) DeletePasskey(ctx context.Context, userID, id int64) error {
+	res, err := r.executor(ctx).ExecContext(ctx, `
+		UPDATE auth_webauthn_credentials SET cleared_at=NOW()
+		WHERE id=$1 AND user_id=$2 AND cleared_at IS NULL`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
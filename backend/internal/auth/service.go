@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
 	"sort"
 	"strings"
 	"sync"
@@ -301,7 +302,9 @@ type recoveryChallengeJob struct {
 type UserService interface {
 	GetByID(id int64) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
+	GetByUsername(username string) (*models.User, error)
 	CreateUserFromRegisterRequest(req *models.RegisterRequest) (*models.User, error)
+	AddRoleByName(userID int64, roleName string) error
 	HasPermission(userID int64, permission string) (bool, error)
 }
 
@@ -312,6 +315,16 @@ type Service struct {
 	recoveryRequests   map[string]*models.RecoveryRequest
 	recoveryLastSeen   map[string]time.Time
 	recoveryChallenges map[int64]recoveryChallengeJob
+
+	siteConfig     SiteConfigStore
+	httpClient     *http.Client // nil uses a client with a 10s timeout
+	oidcMu         sync.Mutex
+	oidcMetadata   map[string]*oidcMetadata
+	oidcStates     map[string]oidcState
+	oidcLoginCodes map[string]oidcLoginCode
+
+	passkeyMu         sync.Mutex
+	passkeyChallenges map[string]passkeyChallenge
 }
 
 func NewService(r Repository, userService UserService) *Service {
@@ -321,6 +334,10 @@ func NewService(r Repository, userService UserService) *Service {
 		recoveryRequests:   make(map[string]*models.RecoveryRequest),
 		recoveryLastSeen:   make(map[string]time.Time),
 		recoveryChallenges: make(map[int64]recoveryChallengeJob),
+		oidcMetadata:       make(map[string]*oidcMetadata),
+		oidcStates:         make(map[string]oidcState),
+		oidcLoginCodes:     make(map[string]oidcLoginCode),
+		passkeyChallenges:  make(map[string]passkeyChallenge),
 	}
 }
 
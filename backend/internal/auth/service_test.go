@@ -137,6 +137,25 @@ func (s *fakeUserService) GetByEmail(email string) (*models.User, error) {
 	}
 	return user, nil
 }
+
+func (s *fakeUserService) GetByUsername(username string) (*models.User, error) {
+	for _, user := range s.byID {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (s *fakeUserService) AddRoleByName(userID int64, roleName string) error {
+	user, ok := s.byID[userID]
+	if !ok {
+		return errors.New("user not found")
+	}
+	user.Roles = append(user.Roles, roleName)
+	return nil
+}
+
 func (s *fakeUserService) HasPermission(userID int64, permission string) (bool, error) {
 	return true, nil // For tests, just return true
 }
@@ -170,6 +189,8 @@ type fakeAuthRepository struct {
 	totpSecrets map[int64]*models.TOTPSecret
 	backupCodes map[int64][]*models.BackupCode
 	mfaStatuses map[int64]models.MFAChallengeStatus
+	identities  []*models.OIDCIdentity
+	passkeys    []*models.Passkey
 }
 
 func newFakeAuthRepository() *fakeAuthRepository {
@@ -334,3 +355,113 @@ func (r *fakeAuthRepository) GetMFARequired(ctx context.Context, userID int64) (
 	}
 	return status, nil
 }
+
+func (r *fakeAuthRepository) GetOIDCIdentity(ctx context.Context, provider, subject string) (*models.OIDCIdentity, error) {
+	for _, ident := range r.identities {
+		if ident.Provider == provider && ident.Subject == subject {
+			return ident, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeAuthRepository) LinkOIDCIdentity(ctx context.Context, userID int64, provider, subject, email string) (*models.OIDCIdentity, error) {
+	if ident, err := r.GetOIDCIdentity(ctx, provider, subject); err == nil {
+		if ident.UserID != userID {
+			return nil, ErrOIDCAlreadyLinked
+		}
+		ident.Email = email
+		return ident, nil
+	}
+	ident := &models.OIDCIdentity{ID: r.next(), UserID: userID, Provider: provider, Subject: subject, Email: email, CreatedAt: time.Now()}
+	r.identities = append(r.identities, ident)
+	return ident, nil
+}
+
+func (r *fakeAuthRepository) TouchOIDCIdentity(ctx context.Context, id int64) error {
+	for _, ident := range r.identities {
+		if ident.ID == id {
+			now := time.Now()
+			ident.LastLoginAt = &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *fakeAuthRepository) ListOIDCIdentities(ctx context.Context, userID int64) ([]*models.OIDCIdentity, error) {
+	var idents []*models.OIDCIdentity
+	for _, ident := range r.identities {
+		if ident.UserID == userID {
+			idents = append(idents, ident)
+		}
+	}
+	return idents, nil
+}
+
+func (r *fakeAuthRepository) UnlinkOIDCIdentity(ctx context.Context, userID, id int64) error {
+	for i, ident := range r.identities {
+		if ident.ID == id && ident.UserID == userID {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *fakeAuthRepository) CreatePasskey(ctx context.Context, p *models.Passkey) error {
+	p.ID = r.next()
+	p.CreatedAt = time.Now()
+	r.passkeys = append(r.passkeys, p)
+	return nil
+}
+
+func (r *fakeAuthRepository) GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*models.Passkey, error) {
+	for _, p := range r.passkeys {
+		if string(p.CredentialID) == string(credentialID) {
+			return p, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeAuthRepository) ListPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error) {
+	var passkeys []*models.Passkey
+	for _, p := range r.passkeys {
+		if p.UserID == userID {
+			passkeys = append(passkeys, p)
+		}
+	}
+	return passkeys, nil
+}
+
+func (r *fakeAuthRepository) TouchPasskey(ctx context.Context, id int64, signCount uint32) error {
+	for _, p := range r.passkeys {
+		if p.ID == id {
+			now := time.Now()
+			p.SignCount, p.LastUsedAt = signCount, &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *fakeAuthRepository) RenamePasskey(ctx context.Context, userID, id int64, name string) error {
+	for _, p := range r.passkeys {
+		if p.ID == id && p.UserID == userID {
+			p.Name = name
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *fakeAuthRepository) DeletePasskey(ctx context.Context, userID, id int64) error {
+	for i, p := range r.passkeys {
+		if p.ID == id && p.UserID == userID {
+			r.passkeys = append(r.passkeys[:i], r.passkeys[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
@@ -0,0 +1,765 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skaia/backend/internal/seo"
+	"github.com/skaia/backend/models"
+)
+
+const (
+	passkeyChallengeTTL = 5 * time.Minute
+	passkeyNameMax      = 64
+
+	passkeyPurposeRegister = "register"
+	passkeyPurposeLogin    = "login"
+	passkeyPurposeMFA      = "mfa"
+)
+
+// MFA methods reported by MFAMethods.
+const (
+	MFAMethodTOTP    = "totp"
+	MFAMethodPasskey = "passkey"
+)
+
+var (
+	ErrPasskeyInvalid = errors.New("passkey verification failed")
+	ErrPasskeyExists  = errors.New("this passkey is already registered")
+	ErrNoPasskeys     = errors.New("no passkeys registered")
+)
+
+// COSE algorithm identifiers accepted for passkeys, in preference order.
+const (
+	coseES256 = -7
+	coseEdDSA = -8
+	coseRS256 = -257
+)
+
+// Authenticator data flags.
+const (
+	authDataUserPresent  = 0x01
+	authDataUserVerified = 0x04
+	authDataAttested     = 0x40
+	authDataExtensions   = 0x80
+)
+
+// RelyingParty identifies this site to authenticators. ID is the host a
+// passkey is bound to and Origin the exact origin the browser must report.
+type RelyingParty struct {
+	ID     string
+	Origin string
+	Name   string
+}
+
+// RelyingPartyFor returns the relying party for a request. It is built from
+// the tenant's public origin; in development, where none is configured, the
+// browser's Origin header or the request host stands in.
+func RelyingPartyFor(r *http.Request) RelyingParty {
+	origin := seo.ConfiguredPublicBaseURL()
+	if origin == "" {
+		origin = r.Header.Get("Origin")
+	}
+	if origin == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		origin = scheme + "://" + r.Host
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return RelyingParty{ID: r.Host, Origin: origin, Name: "Skaia"}
+	}
+	return RelyingParty{ID: u.Hostname(), Origin: u.Scheme + "://" + u.Host, Name: "Skaia"}
+}
+
+// PasskeyCredential is a PublicKeyCredential as serialized by the browser's
+// toJSON(), with binary fields base64url encoded. Registrations fill
+// AttestationObject; assertions fill AuthenticatorData and Signature.
+type PasskeyCredential struct {
+	ID       string          `json:"id"`
+	RawID    string          `json:"rawId"`
+	Type     string          `json:"type"`
+	Response PasskeyResponse `json:"response"`
+}
+
+// PasskeyResponse is the response member of a PasskeyCredential.
+type PasskeyResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject,omitempty"`
+	Transports        []string `json:"transports,omitempty"`
+	AuthenticatorData string   `json:"authenticatorData,omitempty"`
+	Signature         string   `json:"signature,omitempty"`
+	UserHandle        string   `json:"userHandle,omitempty"`
+}
+
+type passkeyDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type passkeyRP struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type passkeyUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type passkeyParam struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type passkeySelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// PasskeyCreationOptions is a PublicKeyCredentialCreationOptionsJSON, ready
+// for PublicKeyCredential.parseCreationOptionsFromJSON.
+type PasskeyCreationOptions struct {
+	Challenge              string              `json:"challenge"`
+	RP                     passkeyRP           `json:"rp"`
+	User                   passkeyUser         `json:"user"`
+	PubKeyCredParams       []passkeyParam      `json:"pubKeyCredParams"`
+	Timeout                int64               `json:"timeout"`
+	ExcludeCredentials     []passkeyDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection passkeySelection    `json:"authenticatorSelection"`
+	Attestation            string              `json:"attestation"`
+}
+
+// PasskeyRequestOptions is a PublicKeyCredentialRequestOptionsJSON, ready
+// for PublicKeyCredential.parseRequestOptionsFromJSON.
+type PasskeyRequestOptions struct {
+	Challenge        string              `json:"challenge"`
+	Timeout          int64               `json:"timeout"`
+	RPID             string              `json:"rpId"`
+	AllowCredentials []passkeyDescriptor `json:"allowCredentials"`
+	UserVerification string              `json:"userVerification"`
+}
+
+// passkeyChallenge is a ceremony started by one of the Begin methods and not
+// yet finished. UserID is zero for a passwordless login.
+type passkeyChallenge struct {
+	UserID    int64
+	Purpose   string
+	ExpiresAt time.Time
+}
+
+func (s *Service) newPasskeyChallenge(userID int64, purpose string) string {
+	challenge := randomToken(32)
+	now := time.Now()
+	s.passkeyMu.Lock()
+	defer s.passkeyMu.Unlock()
+	for k, c := range s.passkeyChallenges {
+		if !now.Before(c.ExpiresAt) {
+			delete(s.passkeyChallenges, k)
+		}
+	}
+	s.passkeyChallenges[challenge] = passkeyChallenge{UserID: userID, Purpose: purpose, ExpiresAt: now.Add(passkeyChallengeTTL)}
+	return challenge
+}
+
+// takePasskeyChallenge returns and forgets a pending ceremony, so every
+// challenge is answered at most once.
+func (s *Service) takePasskeyChallenge(challenge, purpose string) (passkeyChallenge, error) {
+	s.passkeyMu.Lock()
+	defer s.passkeyMu.Unlock()
+	c, ok := s.passkeyChallenges[challenge]
+	delete(s.passkeyChallenges, challenge)
+	if !ok || c.Purpose != purpose || !time.Now().Before(c.ExpiresAt) {
+		return passkeyChallenge{}, ErrPasskeyInvalid
+	}
+	return c, nil
+}
+
+// passkeyUserHandle is the opaque user.id given to authenticators.
+func passkeyUserHandle(userID int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(userID))
+	return b
+}
+
+func b64url(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+// decodeB64 accepts base64url as browsers send it, padded or not, and plain
+// base64 from older client libraries.
+func decodeB64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+func descriptors(passkeys []*models.Passkey) []passkeyDescriptor {
+	out := make([]passkeyDescriptor, 0, len(passkeys))
+	for _, p := range passkeys {
+		out = append(out, passkeyDescriptor{Type: "public-key", ID: b64url(p.CredentialID), Transports: p.Transports})
+	}
+	return out
+}
+
+// BeginPasskeyRegistration returns the options for adding a passkey to the
+// user's account. Passkeys the user already has are excluded.
+func (s *Service) BeginPasskeyRegistration(ctx context.Context, rp RelyingParty, userID int64) (*PasskeyCreationOptions, error) {
+	user, err := s.userService.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListPasskeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyCreationOptions{
+		Challenge: s.newPasskeyChallenge(userID, passkeyPurposeRegister),
+		RP:        passkeyRP{ID: rp.ID, Name: rp.Name},
+		User:      passkeyUser{ID: b64url(passkeyUserHandle(userID)), Name: user.Username, DisplayName: user.DisplayName},
+		PubKeyCredParams: []passkeyParam{
+			{Type: "public-key", Alg: coseES256},
+			{Type: "public-key", Alg: coseEdDSA},
+			{Type: "public-key", Alg: coseRS256},
+		},
+		Timeout:                passkeyChallengeTTL.Milliseconds(),
+		ExcludeCredentials:     descriptors(existing),
+		AuthenticatorSelection: passkeySelection{ResidentKey: "required", UserVerification: "preferred"},
+		Attestation:            "none",
+	}, nil
+}
+
+// FinishPasskeyRegistration verifies an authenticator's answer to
+// BeginPasskeyRegistration and stores the new passkey. Attestation is not
+// requested, so only the credential itself is checked.
+func (s *Service) FinishPasskeyRegistration(ctx context.Context, rp RelyingParty, userID int64, name string, cred *PasskeyCredential) (*models.Passkey, error) {
+	c, _, err := s.checkClientData(rp, cred, "webauthn.create", passkeyPurposeRegister)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserID != userID {
+		return nil, ErrPasskeyInvalid
+	}
+	raw, err := decodeB64(cred.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	obj, _, err := cborDecode(raw, 0)
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	attestation, _ := obj.(map[any]any)
+	authDataRaw, _ := attestation["authData"].([]byte)
+	ad, err := parseAuthenticatorData(authDataRaw)
+	if err != nil {
+		return nil, err
+	}
+	if err := ad.check(rp, false); err != nil {
+		return nil, err
+	}
+	if ad.CredentialID == nil {
+		return nil, ErrPasskeyInvalid
+	}
+	if _, err := parseCOSEKey(ad.PublicKey); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetPasskeyByCredentialID(ctx, ad.CredentialID); err == nil {
+		return nil, ErrPasskeyExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Passkey"
+	}
+	if len(name) > passkeyNameMax {
+		name = name[:passkeyNameMax]
+	}
+	p := &models.Passkey{
+		UserID:       userID,
+		CredentialID: ad.CredentialID,
+		PublicKey:    ad.PublicKey,
+		SignCount:    ad.SignCount,
+		Name:         name,
+		AAGUID:       formatAAGUID(ad.AAGUID),
+		Transports:   knownTransports(cred.Response.Transports),
+	}
+	if err := s.repo.CreatePasskey(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// BeginPasskeyLogin returns the options for a passwordless sign-in. No
+// credentials are listed, so the authenticator offers its discoverable
+// passkeys for this site.
+func (s *Service) BeginPasskeyLogin(ctx context.Context, rp RelyingParty) *PasskeyRequestOptions {
+	return &PasskeyRequestOptions{
+		Challenge:        s.newPasskeyChallenge(0, passkeyPurposeLogin),
+		Timeout:          passkeyChallengeTTL.Milliseconds(),
+		RPID:             rp.ID,
+		AllowCredentials: []passkeyDescriptor{},
+		UserVerification: "required",
+	}
+}
+
+// FinishPasskeyLogin verifies a passwordless sign-in and returns the user.
+// The authenticator must have verified the user, which makes the passkey a
+// second factor in itself.
+func (s *Service) FinishPasskeyLogin(ctx context.Context, rp RelyingParty, cred *PasskeyCredential) (*models.User, error) {
+	p, err := s.verifyPasskeyAssertion(ctx, rp, cred, passkeyPurposeLogin, 0)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userService.GetByID(p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsSuspended {
+		reason := ""
+		if user.SuspendedReason != nil {
+			reason = *user.SuspendedReason
+		}
+		return nil, &SuspendedError{Reason: reason}
+	}
+	return user, nil
+}
+
+// BeginPasskeyMFA returns the options for confirming a signed-in (or
+// half-signed-in) user with one of their passkeys.
+func (s *Service) BeginPasskeyMFA(ctx context.Context, rp RelyingParty, userID int64) (*PasskeyRequestOptions, error) {
+	passkeys, err := s.repo.ListPasskeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(passkeys) == 0 {
+		return nil, ErrNoPasskeys
+	}
+	return &PasskeyRequestOptions{
+		Challenge:        s.newPasskeyChallenge(userID, passkeyPurposeMFA),
+		Timeout:          passkeyChallengeTTL.Milliseconds(),
+		RPID:             rp.ID,
+		AllowCredentials: descriptors(passkeys),
+		UserVerification: "preferred",
+	}, nil
+}
+
+// VerifyPasskeyMFA checks a passkey assertion in place of a TOTP code. Like
+// VerifyTOTP it reports a wrong answer as false rather than an error.
+func (s *Service) VerifyPasskeyMFA(ctx context.Context, rp RelyingParty, userID int64, cred *PasskeyCredential) (bool, error) {
+	_, err := s.verifyPasskeyAssertion(ctx, rp, cred, passkeyPurposeMFA, userID)
+	if errors.Is(err, ErrPasskeyInvalid) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ListPasskeys returns the user's registered passkeys.
+func (s *Service) ListPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error) {
+	return s.repo.ListPasskeys(ctx, userID)
+}
+
+// RenamePasskey changes the label a user sees for one of their passkeys.
+func (s *Service) RenamePasskey(ctx context.Context, userID, id int64, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > passkeyNameMax {
+		return fmt.Errorf("name must be 1-%d characters", passkeyNameMax)
+	}
+	return s.repo.RenamePasskey(ctx, userID, id, name)
+}
+
+// DeletePasskey removes one of the user's passkeys. The last passkey of an
+// account with no password and no linked provider cannot be removed.
+func (s *Service) DeletePasskey(ctx context.Context, userID, id int64) error {
+	passkeys, err := s.repo.ListPasskeys(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(passkeys) <= 1 {
+		idents, err := s.repo.ListOIDCIdentities(ctx, userID)
+		if err != nil {
+			return err
+		}
+		cred, err := s.repo.GetCredentialByUserID(ctx, userID)
+		if len(idents) == 0 && (err != nil || cred.PasswordHash == "") {
+			return errors.New("set a password before removing your last passkey")
+		}
+	}
+	return s.repo.DeletePasskey(ctx, userID, id)
+}
+
+// MFAMethods lists the second factors the user has set up. An empty list
+// means the account is not protected by MFA.
+func (s *Service) MFAMethods(ctx context.Context, userID int64) ([]string, error) {
+	var methods []string
+	_, totpEnabled, err := s.GetTOTPEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if totpEnabled {
+		methods = append(methods, MFAMethodTOTP)
+	}
+	passkeys, err := s.repo.ListPasskeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(passkeys) > 0 {
+		methods = append(methods, MFAMethodPasskey)
+	}
+	return methods, nil
+}
+
+type collectedClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// checkClientData verifies the browser's client data against a pending
+// challenge and returns that challenge with the raw client data bytes.
+func (s *Service) checkClientData(rp RelyingParty, cred *PasskeyCredential, ceremony, purpose string) (passkeyChallenge, []byte, error) {
+	if cred == nil || cred.Type != "public-key" {
+		return passkeyChallenge{}, nil, ErrPasskeyInvalid
+	}
+	raw, err := decodeB64(cred.Response.ClientDataJSON)
+	if err != nil {
+		return passkeyChallenge{}, nil, ErrPasskeyInvalid
+	}
+	var cd collectedClientData
+	if err := json.Unmarshal(raw, &cd); err != nil || cd.Type != ceremony || cd.Origin != rp.Origin {
+		return passkeyChallenge{}, nil, ErrPasskeyInvalid
+	}
+	c, err := s.takePasskeyChallenge(strings.TrimRight(cd.Challenge, "="), purpose)
+	if err != nil {
+		return passkeyChallenge{}, nil, err
+	}
+	return c, raw, nil
+}
+
+// verifyPasskeyAssertion checks a login or MFA assertion and advances the
+// passkey's signature counter. A counter that fails to increase means the
+// authenticator may have been cloned, and the assertion is refused.
+func (s *Service) verifyPasskeyAssertion(ctx context.Context, rp RelyingParty, cred *PasskeyCredential, purpose string, userID int64) (*models.Passkey, error) {
+	c, clientDataRaw, err := s.checkClientData(rp, cred, "webauthn.get", purpose)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserID != userID {
+		return nil, ErrPasskeyInvalid
+	}
+	rawID, err := decodeB64(cred.RawID)
+	if err != nil || len(rawID) == 0 {
+		return nil, ErrPasskeyInvalid
+	}
+	p, err := s.repo.GetPasskeyByCredentialID(ctx, rawID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPasskeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 && p.UserID != userID {
+		return nil, ErrPasskeyInvalid
+	}
+	if cred.Response.UserHandle != "" {
+		handle, err := decodeB64(cred.Response.UserHandle)
+		if err != nil || !bytes.Equal(handle, passkeyUserHandle(p.UserID)) {
+			return nil, ErrPasskeyInvalid
+		}
+	}
+
+	authDataRaw, err := decodeB64(cred.Response.AuthenticatorData)
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	ad, err := parseAuthenticatorData(authDataRaw)
+	if err != nil {
+		return nil, err
+	}
+	if err := ad.check(rp, purpose == passkeyPurposeLogin); err != nil {
+		return nil, err
+	}
+	sig, err := decodeB64(cred.Response.Signature)
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	key, err := parseCOSEKey(p.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataRaw)
+	if err := key.verify(append(authDataRaw[:len(authDataRaw):len(authDataRaw)], clientDataHash[:]...), sig); err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	if (ad.SignCount != 0 || p.SignCount != 0) && ad.SignCount <= p.SignCount {
+		return nil, ErrPasskeyInvalid
+	}
+	if err := s.repo.TouchPasskey(ctx, p.ID, ad.SignCount); err != nil {
+		return nil, err
+	}
+	p.SignCount = ad.SignCount
+	return p, nil
+}
+
+// authenticatorData is the parsed authenticator data structure. The
+// attested credential fields are only present on registration.
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte // COSE_Key bytes
+}
+
+func parseAuthenticatorData(b []byte) (*authenticatorData, error) {
+	if len(b) < 37 {
+		return nil, ErrPasskeyInvalid
+	}
+	ad := &authenticatorData{RPIDHash: b[:32], Flags: b[32], SignCount: binary.BigEndian.Uint32(b[33:37])}
+	if ad.Flags&authDataAttested == 0 {
+		return ad, nil
+	}
+	rest := b[37:]
+	if len(rest) < 18 {
+		return nil, ErrPasskeyInvalid
+	}
+	ad.AAGUID = rest[:16]
+	n := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if n == 0 || n > 1023 || len(rest) < n {
+		return nil, ErrPasskeyInvalid
+	}
+	ad.CredentialID = rest[:n]
+	rest = rest[n:]
+	_, after, err := cborDecode(rest, 0)
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	ad.PublicKey = rest[:len(rest)-len(after)]
+	if len(after) > 0 && ad.Flags&authDataExtensions == 0 {
+		return nil, ErrPasskeyInvalid
+	}
+	return ad, nil
+}
+
+// check verifies the relying party hash and the user presence, and user
+// verification when requireUV is set.
+func (ad *authenticatorData) check(rp RelyingParty, requireUV bool) error {
+	want := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(ad.RPIDHash, want[:]) || ad.Flags&authDataUserPresent == 0 {
+		return ErrPasskeyInvalid
+	}
+	if requireUV && ad.Flags&authDataUserVerified == 0 {
+		return ErrPasskeyInvalid
+	}
+	return nil
+}
+
+func formatAAGUID(b []byte) string {
+	if len(b) != 16 || bytes.Equal(b, make([]byte, 16)) {
+		return ""
+	}
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+func knownTransports(in []string) []string {
+	var out []string
+	for _, t := range in {
+		switch t {
+		case "usb", "nfc", "ble", "internal", "hybrid", "smart-card":
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// coseKey is a passkey public key with the algorithm it signs with.
+type coseKey struct {
+	alg int64
+	pub crypto.PublicKey
+}
+
+func parseCOSEKey(b []byte) (*coseKey, error) {
+	v, _, err := cborDecode(b, 0)
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, ErrPasskeyInvalid
+	}
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+	crv, _ := m[int64(-1)].(int64)
+	x, _ := m[int64(-2)].([]byte)
+	y, _ := m[int64(-3)].([]byte)
+	switch {
+	case kty == 2 && alg == coseES256 && crv == 1 && len(x) == 32 && len(y) == 32:
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, ErrPasskeyInvalid
+		}
+		return &coseKey{alg: alg, pub: pub}, nil
+	case kty == 1 && alg == coseEdDSA && crv == 6 && len(x) == ed25519.PublicKeySize:
+		return &coseKey{alg: alg, pub: ed25519.PublicKey(x)}, nil
+	case kty == 3 && alg == coseRS256:
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrPasskeyInvalid
+		}
+		return &coseKey{alg: alg, pub: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported key algorithm %d", ErrPasskeyInvalid, alg)
+}
+
+func (k *coseKey) verify(data, sig []byte) error {
+	switch pub := k.pub.(type) {
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(pub, sum[:], sig) {
+			return ErrPasskeyInvalid
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, data, sig) {
+			return ErrPasskeyInvalid
+		}
+		return nil
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig)
+	}
+	return ErrPasskeyInvalid
+}
+
+const cborMaxDepth = 16
+
+// cborDecode decodes one CBOR data item and returns it with the bytes that
+// follow. It covers what WebAuthn sends: integers, byte and text strings,
+// arrays, maps, tags and simple values, all of definite length. Integers
+// decode as int64, maps as map[any]any keyed by int64 or string.
+func cborDecode(b []byte, depth int) (any, []byte, error) {
+	if depth > cborMaxDepth {
+		return nil, nil, errors.New("cbor: nested too deeply")
+	}
+	if len(b) == 0 {
+		return nil, nil, errors.New("cbor: unexpected end of data")
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+	b = b[1:]
+	var arg uint64
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(b) < size {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		for _, c := range b[:size] {
+			arg = arg<<8 | uint64(c)
+		}
+		b = b[size:]
+	default:
+		return nil, nil, errors.New("cbor: indefinite lengths are not supported")
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return int64(arg), b, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(arg), b, nil
+	case 2, 3:
+		if arg > uint64(len(b)) {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		if major == 3 {
+			return string(b[:arg]), b[arg:], nil
+		}
+		return b[:arg], b[arg:], nil
+	case 4:
+		if arg > uint64(len(b)) {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		items := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item any
+			var err error
+			if item, b, err = cborDecode(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, b, nil
+	case 5:
+		if arg > uint64(len(b)) {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		m := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			var k, v any
+			var err error
+			if k, b, err = cborDecode(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errors.New("cbor: unsupported map key")
+			}
+			if v, b, err = cborDecode(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[k] = v
+		}
+		return m, b, nil
+	case 6:
+		return cborDecode(b, depth+1)
+	}
+	switch {
+	case info == 20:
+		return false, b, nil
+	case info == 21:
+		return true, b, nil
+	case info == 22 || info == 23:
+		return nil, b, nil
+	case info == 26:
+		return float64(math.Float32frombits(uint32(arg))), b, nil
+	case info == 27:
+		return math.Float64frombits(arg), b, nil
+	}
+	return nil, nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+}
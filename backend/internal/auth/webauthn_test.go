@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRP = RelyingParty{ID: "tenant.example", Origin: "https://tenant.example", Name: "Skaia"}
+
+// cborEncode writes the subset of CBOR a test authenticator needs. Map keys
+// are sorted so output is stable.
+func cborEncode(v any) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 1<<8:
+			return []byte{major<<5 | 24, byte(n)}
+		case n < 1<<16:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+		}
+	}
+	switch x := v.(type) {
+	case int:
+		if x < 0 {
+			return head(1, uint64(-1-x))
+		}
+		return head(0, uint64(x))
+	case []byte:
+		return append(head(2, uint64(len(x))), x...)
+	case string:
+		return append(head(3, uint64(len(x))), x...)
+	case map[any]any:
+		keys := make([]any, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return string(cborEncode(keys[i])) < string(cborEncode(keys[j])) })
+		out := head(5, uint64(len(x)))
+		for _, k := range keys {
+			out = append(out, cborEncode(k)...)
+			out = append(out, cborEncode(x[k])...)
+		}
+		return out
+	}
+	panic("cborEncode: unsupported type")
+}
+
+// softAuthenticator is an in-memory platform authenticator.
+type softAuthenticator struct {
+	id        []byte
+	ec        *ecdsa.PrivateKey
+	ed        ed25519.PrivateKey
+	count     uint32
+	userID    int64
+	skipCount bool
+	noUV      bool
+}
+
+func newSoftAuthenticator(t *testing.T, ed bool) *softAuthenticator {
+	t.Helper()
+	a := &softAuthenticator{id: []byte(randomToken(16))}
+	if ed {
+		_, a.ed, _ = ed25519.GenerateKey(rand.Reader)
+	} else {
+		a.ec, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	return a
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	if a.ed != nil {
+		return cborEncode(map[any]any{1: 1, 3: coseEdDSA, -1: 6, -2: []byte(a.ed.Public().(ed25519.PublicKey))})
+	}
+	x, y := make([]byte, 32), make([]byte, 32)
+	a.ec.X.FillBytes(x)
+	a.ec.Y.FillBytes(y)
+	return cborEncode(map[any]any{1: 2, 3: coseES256, -1: 1, -2: x, -3: y})
+}
+
+func (a *softAuthenticator) authData(rp RelyingParty, attested bool) []byte {
+	if !a.skipCount {
+		a.count++
+	}
+	hash := sha256.Sum256([]byte(rp.ID))
+	flags := byte(authDataUserPresent)
+	if !a.noUV {
+		flags |= authDataUserVerified
+	}
+	if attested {
+		flags |= authDataAttested
+	}
+	out := append(hash[:], flags)
+	out = binary.BigEndian.AppendUint32(out, a.count)
+	if attested {
+		out = append(out, make([]byte, 16)...)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(a.id)))
+		out = append(out, a.id...)
+		out = append(out, a.coseKey()...)
+	}
+	return out
+}
+
+func clientDataJSON(ceremony, challenge, origin string) []byte {
+	b, _ := json.Marshal(map[string]any{"type": ceremony, "challenge": challenge, "origin": origin})
+	return b
+}
+
+func (a *softAuthenticator) create(rp RelyingParty, opts *PasskeyCreationOptions) *PasskeyCredential {
+	handle, _ := decodeB64(opts.User.ID)
+	a.userID = int64(binary.BigEndian.Uint64(handle))
+	att := cborEncode(map[any]any{"fmt": "none", "attStmt": map[any]any{}, "authData": a.authData(rp, true)})
+	return &PasskeyCredential{
+		ID: b64url(a.id), RawID: b64url(a.id), Type: "public-key",
+		Response: PasskeyResponse{
+			ClientDataJSON:    b64url(clientDataJSON("webauthn.create", opts.Challenge, rp.Origin)),
+			AttestationObject: b64url(att),
+			Transports:        []string{"internal", "bogus"},
+		},
+	}
+}
+
+func (a *softAuthenticator) get(rp RelyingParty, challenge string) *PasskeyCredential {
+	ad := a.authData(rp, false)
+	cd := clientDataJSON("webauthn.get", challenge, rp.Origin)
+	cdHash := sha256.Sum256(cd)
+	signed := append(append([]byte{}, ad...), cdHash[:]...)
+	var sig []byte
+	if a.ed != nil {
+		sig = ed25519.Sign(a.ed, signed)
+	} else {
+		sum := sha256.Sum256(signed)
+		sig, _ = ecdsa.SignASN1(rand.Reader, a.ec, sum[:])
+	}
+	return &PasskeyCredential{
+		ID: b64url(a.id), RawID: b64url(a.id), Type: "public-key",
+		Response: PasskeyResponse{
+			ClientDataJSON:    b64url(cd),
+			AuthenticatorData: b64url(ad),
+			Signature:         b64url(sig),
+			UserHandle:        b64url(passkeyUserHandle(a.userID)),
+		},
+	}
+}
+
+func registerPasskey(t *testing.T, svc *Service, userID int64, a *softAuthenticator) {
+	t.Helper()
+	opts, err := svc.BeginPasskeyRegistration(context.Background(), testRP, userID)
+	require.NoError(t, err)
+	_, err = svc.FinishPasskeyRegistration(context.Background(), testRP, userID, "Laptop", a.create(testRP, opts))
+	require.NoError(t, err)
+}
+
+func TestPasskeyRegistrationAndPasswordlessLogin(t *testing.T) {
+	svc, repo, users := newTestService()
+	user := users.mustCreate(t, "pk@example.com")
+	ctx := context.Background()
+
+	methods, _ := svc.MFAMethods(ctx, user.ID)
+	assert.Empty(t, methods)
+
+	a := newSoftAuthenticator(t, false)
+	registerPasskey(t, svc, user.ID, a)
+	require.Len(t, repo.passkeys, 1)
+	assert.Equal(t, []string{"internal"}, repo.passkeys[0].Transports)
+	methods, _ = svc.MFAMethods(ctx, user.ID)
+	assert.Equal(t, []string{MFAMethodPasskey}, methods)
+
+	opts, err := svc.BeginPasskeyRegistration(ctx, testRP, user.ID)
+	require.NoError(t, err)
+	require.Len(t, opts.ExcludeCredentials, 1)
+	_, err = svc.FinishPasskeyRegistration(ctx, testRP, user.ID, "", a.create(testRP, opts))
+	assert.ErrorIs(t, err, ErrPasskeyExists)
+
+	login := svc.BeginPasskeyLogin(ctx, testRP)
+	assertion := a.get(testRP, login.Challenge)
+	got, err := svc.FinishPasskeyLogin(ctx, testRP, assertion)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	assert.Equal(t, a.count, repo.passkeys[0].SignCount)
+
+	_, err = svc.FinishPasskeyLogin(ctx, testRP, assertion)
+	assert.ErrorIs(t, err, ErrPasskeyInvalid, "a challenge is answered once")
+
+	a.noUV = true
+	_, err = svc.FinishPasskeyLogin(ctx, testRP, a.get(testRP, svc.BeginPasskeyLogin(ctx, testRP).Challenge))
+	assert.ErrorIs(t, err, ErrPasskeyInvalid, "passwordless login needs user verification")
+	a.noUV = false
+
+	other := RelyingParty{ID: "evil.example", Origin: "https://evil.example"}
+	_, err = svc.FinishPasskeyLogin(ctx, testRP, a.get(other, svc.BeginPasskeyLogin(ctx, testRP).Challenge))
+	assert.ErrorIs(t, err, ErrPasskeyInvalid, "assertion for another origin")
+
+	a.count, a.skipCount = repo.passkeys[0].SignCount, true
+	_, err = svc.FinishPasskeyLogin(ctx, testRP, a.get(testRP, svc.BeginPasskeyLogin(ctx, testRP).Challenge))
+	assert.ErrorIs(t, err, ErrPasskeyInvalid, "a counter that does not advance suggests a cloned key")
+}
+
+func TestPasskeyAsMFAMethod(t *testing.T) {
+	svc, repo, users := newTestService()
+	user := users.mustCreate(t, "mfa-pk@example.com")
+	intruder := users.mustCreate(t, "x@example.com")
+	ctx := context.Background()
+
+	_, err := svc.BeginPasskeyMFA(ctx, testRP, user.ID)
+	assert.ErrorIs(t, err, ErrNoPasskeys)
+
+	a := newSoftAuthenticator(t, true)
+	registerPasskey(t, svc, user.ID, a)
+
+	opts, err := svc.BeginPasskeyMFA(ctx, testRP, user.ID)
+	require.NoError(t, err)
+	require.Len(t, opts.AllowCredentials, 1)
+	a.noUV = true
+	ok, err := svc.VerifyPasskeyMFA(ctx, testRP, user.ID, a.get(testRP, opts.Challenge))
+	require.NoError(t, err)
+	assert.True(t, ok, "a second factor only needs user presence")
+
+	opts, _ = svc.BeginPasskeyMFA(ctx, testRP, user.ID)
+	ok, err = svc.VerifyPasskeyMFA(ctx, testRP, intruder.ID, a.get(testRP, opts.Challenge))
+	require.NoError(t, err)
+	assert.False(t, ok, "a challenge issued to one user does not verify another")
+
+	opts, _ = svc.BeginPasskeyMFA(ctx, testRP, user.ID)
+	_, err = svc.FinishPasskeyLogin(ctx, testRP, a.get(testRP, opts.Challenge))
+	assert.ErrorIs(t, err, ErrPasskeyInvalid, "an MFA challenge is not a login challenge")
+
+	assert.Error(t, svc.DeletePasskey(ctx, user.ID, repo.passkeys[0].ID), "last sign-in of a passwordless account")
+	require.NoError(t, repo.CreateCredentialHash(user.ID, "CorrectHorse1!"))
+	require.NoError(t, svc.RenamePasskey(ctx, user.ID, repo.passkeys[0].ID, "Phone"))
+	assert.Equal(t, "Phone", repo.passkeys[0].Name)
+	require.NoError(t, svc.DeletePasskey(ctx, user.ID, repo.passkeys[0].ID))
+	methods, _ := svc.MFAMethods(ctx, user.ID)
+	assert.Empty(t, methods)
+}
+
+func TestCBORDecode(t *testing.T) {
+	in := cborEncode(map[any]any{"a": []byte{1, 2}, -3: "x", 500: 70000})
+	v, rest, err := cborDecode(append(in, 0xff), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff}, rest)
+	assert.Equal(t, map[any]any{"a": []byte{1, 2}, int64(-3): "x", int64(500): int64(70000)}, v)
+
+	for _, bad := range [][]byte{{0x5f}, {0x42, 0x01}, {0xa1, 0x41, 0x00, 0x00}, {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}} {
+		_, _, err := cborDecode(bad, 0)
+		assert.Error(t, err, "% x", bad)
+	}
+	deep := make([]byte, cborMaxDepth+2)
+	for i := range deep {
+		deep[i] = 0x81
+	}
+	_, _, err = cborDecode(deep, 0)
+	assert.Error(t, err)
+}
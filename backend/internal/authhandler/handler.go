@@ -23,9 +23,28 @@ func (h *Handler) Mount(r chi.Router, jwt func(http.Handler) http.Handler) {
 		r.With(mw.AuthLimitMiddleware()).Post("/register", h.authHandler.Register)
 		r.With(mw.AuthLimitMiddleware()).Post("/login", h.authHandler.Login)
 		r.With(mw.AuthLimitMiddleware()).Post("/login/totp", h.authHandler.LoginTOTP)
+		r.With(mw.AuthLimitMiddleware()).Post("/login/passkey-options", h.authHandler.LoginPasskeyOptions)
 		r.With(mw.AuthLimitMiddleware()).Post("/refresh", h.authHandler.RefreshToken)
 		r.With(jwt).Post("/logout", h.authHandler.Logout)
 
+		// Single sign-on with the tenant's OIDC providers
+		r.Get("/oidc/providers", h.authHandler.OIDCProviders)
+		r.With(mw.AuthLimitMiddleware()).Get("/oidc/{provider}/start", h.authHandler.OIDCStart)
+		r.With(mw.AuthLimitMiddleware()).Get("/oidc/{provider}/callback", h.authHandler.OIDCCallback)
+		r.With(mw.AuthLimitMiddleware()).Post("/oidc/exchange", h.authHandler.OIDCExchange)
+		r.With(jwt).Post("/oidc/{provider}/link", h.authHandler.OIDCLink)
+		r.With(jwt).Get("/oidc/identities", h.authHandler.OIDCIdentities)
+		r.With(jwt).Delete("/oidc/identities/{id}", h.authHandler.OIDCUnlink)
+
+		// Passkeys (WebAuthn): passwordless login is public, management requires auth
+		r.With(mw.AuthLimitMiddleware()).Post("/passkeys/login-options", h.authHandler.PasskeyLoginOptions)
+		r.With(mw.AuthLimitMiddleware()).Post("/passkeys/login", h.authHandler.PasskeyLogin)
+		r.With(jwt).Post("/passkeys/register-options", h.authHandler.PasskeyRegisterOptions)
+		r.With(jwt).Post("/passkeys", h.authHandler.PasskeyRegister)
+		r.With(jwt).Get("/passkeys", h.authHandler.Passkeys)
+		r.With(jwt).Patch("/passkeys/{id}", h.authHandler.RenamePasskey)
+		r.With(jwt).Delete("/passkeys/{id}", h.authHandler.DeletePasskey)
+
 		// Email verification (public - token-authenticated)
 		r.With(mw.AuthLimitMiddleware()).Post("/verify-email", h.authHandler.VerifyEmail)
 		r.With(jwt).Post("/resend-verification", h.authHandler.ResendVerification)
@@ -47,6 +66,7 @@ func (h *Handler) Mount(r chi.Router, jwt func(http.Handler) http.Handler) {
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte(`{"status":"success"}`))
 		})
+		r.With(jwt).Post("/mfa-challenge/passkey-options", h.authHandler.MFAChallengePasskeyOptions)
 		// Expose TOTP status for the authenticated user and admin queries
 		r.With(jwt).Get("/totp", h.authHandler.TOTPStatus)
 		r.With(jwt).Get("/totp/{id}", h.authHandler.AdminTOTPStatus)
@@ -63,5 +83,10 @@ func (h *Handler) Mount(r chi.Router, jwt func(http.Handler) http.Handler) {
 		r.With(jwt).Post("/admin/totp/{id}/challenge", h.authHandler.AdminTriggerMFAChallenge)
 
 		r.With(jwt).Post("/admin/totp/{id}/generate-backup-codes", h.authHandler.AdminGenerateBackupCodes)
+
+		// Admin OIDC provider configuration (requires admin.general permission)
+		r.With(jwt).Get("/admin/oidc/providers", h.authHandler.AdminOIDCProviders)
+		r.With(jwt).Put("/admin/oidc/providers/{provider}", h.authHandler.AdminSaveOIDCProvider)
+		r.With(jwt).Delete("/admin/oidc/providers/{provider}", h.authHandler.AdminRemoveOIDCProvider)
 	})
 }
@@ -21,26 +21,46 @@ type Service struct {
 	client     pb.GrengoServiceClient
 	passcodeMu sync.RWMutex
 	passcode   string // "p1:p2" for X-Grengo-Passcode header; empty = no auth
+	token      string // grengo operator session token, sent as a bearer token
+	actor      string // signed-in user, recorded by grengo under the passcode
+	sourceIP   string
+	apiKey     string // sent as x-grengo-api-key; grengo applies its scopes
 	hub        *ws.Hub
 }
 
-// passcodeInterceptor injects X-Grengo-Passcode on every outgoing gRPC request.
+// passcodeInterceptor injects the operator token or X-Grengo-Passcode, and
+// the caller, on every outgoing gRPC request.
 type passcodeInterceptor struct {
 	passcode func() string
+	token    func() string
+	caller   func() (actor, sourceIP, apiKey string)
 }
 
-func (t *passcodeInterceptor) UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-	if passcode := t.passcode(); passcode != "" {
+func (t *passcodeInterceptor) outgoing(ctx context.Context) context.Context {
+	if token := t.token(); token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	} else if passcode := t.passcode(); passcode != "" {
 		ctx = metadata.AppendToOutgoingContext(ctx, "x-grengo-passcode", passcode)
 	}
-	return invoker(ctx, method, req, reply, cc, opts...)
+	actor, sourceIP, apiKey := t.caller()
+	if actor != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-grengo-actor", actor)
+	}
+	if sourceIP != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-forwarded-for", sourceIP)
+	}
+	if apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-grengo-api-key", apiKey)
+	}
+	return ctx
+}
+
+func (t *passcodeInterceptor) UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(t.outgoing(ctx), method, req, reply, cc, opts...)
 }
 
 func (t *passcodeInterceptor) StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-	if passcode := t.passcode(); passcode != "" {
-		ctx = metadata.AppendToOutgoingContext(ctx, "x-grengo-passcode", passcode)
-	}
-	return streamer(ctx, desc, cc, method, opts...)
+	return streamer(t.outgoing(ctx), desc, cc, method, opts...)
 }
 
 // NewService creates a grengo service that talks to the internal API.
@@ -50,7 +70,7 @@ func NewService(apiURL string, hub *ws.Hub) *Service {
 		grpcURL: grpcURL,
 		hub:     hub,
 	}
-	interceptor := &passcodeInterceptor{passcode: svc.currentPasscode}
+	interceptor := &passcodeInterceptor{passcode: svc.currentPasscode, token: svc.currentToken, caller: svc.currentCaller}
 
 	conn, _ := grpc.NewClient(grpcURL,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
@@ -113,6 +133,36 @@ func (s *Service) currentPasscode() string {
 	return s.passcode
 }
 
+// WithToken returns a new Service that authenticates as the grengo operator
+// the session token was issued to.
+func (s *Service) WithToken(token string) *Service {
+	svc := NewService(s.grpcURL, s.hub)
+	svc.passcodeMu.Lock()
+	svc.token = token
+	svc.passcodeMu.Unlock()
+	return svc
+}
+
+func (s *Service) currentToken() string {
+	s.passcodeMu.RLock()
+	defer s.passcodeMu.RUnlock()
+	return s.token
+}
+
+// SetCaller names the user, address and API key that later calls are made
+// for, so grengo can scope them and attribute them in its audit log.
+func (s *Service) SetCaller(actor, sourceIP, apiKey string) {
+	s.passcodeMu.Lock()
+	defer s.passcodeMu.Unlock()
+	s.actor, s.sourceIP, s.apiKey = actor, sourceIP, apiKey
+}
+
+func (s *Service) currentCaller() (string, string, string) {
+	s.passcodeMu.RLock()
+	defer s.passcodeMu.RUnlock()
+	return s.actor, s.sourceIP, s.apiKey
+}
+
 // Close gracefully closes the underlying gRPC connection
 func (s *Service) Close() {
 	if s.conn != nil {
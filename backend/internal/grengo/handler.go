@@ -2,11 +2,13 @@ package grengo
 
 import (
 	"encoding/json"
+	"errors"
 	log "github.com/skaia/backend/internal/syslog"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -15,8 +17,10 @@ import (
 	"github.com/google/uuid"
 	ictx "github.com/skaia/backend/internal/ctx"
 	ijwt "github.com/skaia/backend/internal/jwt"
+	"github.com/skaia/backend/internal/middleware"
 	"github.com/skaia/backend/internal/utils"
 	"github.com/skaia/backend/internal/workers"
+	"google.golang.org/grpc/status"
 )
 
 const sessionTTL = 10 * time.Minute
@@ -28,9 +32,12 @@ type session struct {
 	CreatedAt time.Time `json:"created_at"`
 	LastUsed  time.Time `json:"last_used"`
 	ExpiresAt time.Time `json:"expires_at"`
-	// Passcode pair cached so data routes don't need headers.
-	p1 string
-	p2 string
+	// Passcode pair or operator token cached so data routes don't need
+	// headers. A token session ends when the token does.
+	p1           string
+	p2           string
+	token        string
+	tokenExpires time.Time
 }
 
 // Handler exposes grengo management over HTTP.
@@ -78,6 +85,9 @@ func (h *Handler) touchSession(id string) *session {
 	}
 	s.LastUsed = time.Now()
 	s.ExpiresAt = s.LastUsed.Add(sessionTTL)
+	if !s.tokenExpires.IsZero() && s.tokenExpires.Before(s.ExpiresAt) {
+		s.ExpiresAt = s.tokenExpires
+	}
 	return s
 }
 
@@ -118,6 +128,7 @@ func (h *Handler) Mount(r chi.Router, jwtAuth func(http.Handler) http.Handler) {
 			sr.Get("/stats", h.handleStats)
 			sr.Get("/storage", h.handleStorage)
 			sr.Get("/sysinfo", h.handleSysInfo)
+			sr.Get("/logs", h.handleQueryLogs)
 
 			sr.Post("/sites/{name}/migrate", h.handleMigrateSite)
 			sr.Post("/migrate-all", h.handleMigrateAll)
@@ -157,17 +168,36 @@ func (h *Handler) requireSession(next http.Handler) http.Handler {
 	})
 }
 
-// svcFor returns an authenticated Service for the request's session.
+// svcFor returns an authenticated Service for the request's session, acting
+// for the signed-in user.
 func (h *Handler) svcFor(r *http.Request) *Service {
 	sid := chi.URLParam(r, "sessionId")
 	if s := h.touchSession(sid); s != nil {
-		return h.svc.WithPasscode(s.p1, s.p2)
+		svc := h.svc.WithPasscode(s.p1, s.p2)
+		if s.token != "" {
+			svc = h.svc.WithToken(s.token)
+		}
+		svc.SetCaller(requestActor(r), utils.RealIP(r), middleware.APIKeyFromRequest(r))
+		return svc
 	}
 	return h.svc
 }
 
+// requestActor names the signed-in user for the grengo audit log.
+func requestActor(r *http.Request) string {
+	claims, ok := r.Context().Value(ictx.CtxKeyClaims).(*ijwt.Claims)
+	if !ok {
+		return ""
+	}
+	if claims.Username != "" {
+		return claims.Username
+	}
+	return "user:" + strconv.FormatInt(claims.UserID, 10)
+}
+
 // Session handlers
-// handleCreateSession verifies admin + passcode, creates a temp session, returns the UUID.
+// handleCreateSession verifies admin + passcode (or a grengo operator login),
+// creates a temp session, returns the UUID.
 func (h *Handler) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 	// Require admin role.
 	claims, ok := r.Context().Value(ictx.CtxKeyClaims).(*ijwt.Claims)
@@ -187,31 +217,18 @@ func (h *Handler) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Require passcode.
-	if !h.svc.PasscodeConfigured() {
-		utils.WriteError(w, http.StatusServiceUnavailable, "grengo passcode not configured on server")
-		return
-	}
-
 	var body struct {
-		P1 string `json:"p1"`
-		P2 string `json:"p2"`
+		P1       string `json:"p1"`
+		P2       string `json:"p2"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		utils.WriteError(w, http.StatusBadRequest, "invalid json")
 		return
 	}
-	if body.P1 == "" || body.P2 == "" {
-		utils.WriteError(w, http.StatusBadRequest, "p1 and p2 required")
-		return
-	}
-	if !h.svc.VerifyPasscode(body.P1, body.P2) {
-		utils.WriteError(w, http.StatusForbidden, "invalid passcode")
-		return
-	}
-	h.svc.SetPasscode(body.P1, body.P2)
 
-	// Create session.
 	now := time.Now()
 	s := &session{
 		ID:        uuid.New().String(),
@@ -219,9 +236,40 @@ func (h *Handler) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 		CreatedAt: now,
 		LastUsed:  now,
 		ExpiresAt: now.Add(sessionTTL),
-		p1:        body.P1,
-		p2:        body.P2,
 	}
+	if body.Username != "" {
+		// Log in as a grengo operator; grengo checks its role on every call.
+		resp, err := h.svc.Login(body.Username, body.Password, body.TOTP)
+		if err != nil {
+			utils.WriteError(w, http.StatusForbidden, status.Convert(err).Message())
+			return
+		}
+		if resp.TotpRequired {
+			utils.WriteJSON(w, http.StatusUnauthorized, map[string]any{"error": "totp code required", "totp_required": true})
+			return
+		}
+		s.token, s.tokenExpires = resp.Token, time.Unix(resp.ExpiresAt, 0)
+		if s.tokenExpires.Before(s.ExpiresAt) {
+			s.ExpiresAt = s.tokenExpires
+		}
+	} else {
+		// Require passcode.
+		if !h.svc.PasscodeConfigured() {
+			utils.WriteError(w, http.StatusServiceUnavailable, "grengo passcode not configured on server")
+			return
+		}
+		if body.P1 == "" || body.P2 == "" {
+			utils.WriteError(w, http.StatusBadRequest, "p1 and p2 (or username and password) required")
+			return
+		}
+		if !h.svc.VerifyPasscode(body.P1, body.P2) {
+			utils.WriteError(w, http.StatusForbidden, "invalid passcode")
+			return
+		}
+		h.svc.SetPasscode(body.P1, body.P2)
+		s.p1, s.p2 = body.P1, body.P2
+	}
+
 	h.mu.Lock()
 	h.sessions[s.ID] = s
 	h.mu.Unlock()
@@ -411,6 +459,37 @@ func (h *Handler) handleStorage(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSON(w, http.StatusOK, info)
 }
 
+// handleQueryLogs searches stored container logs:
+// ?client=a&client=b&since=2h&until=&grep=&level=warn&limit=500.
+func (h *Handler) handleQueryLogs(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	q := LogQuery{
+		Clients: qs["client"],
+		Since:   qs.Get("since"),
+		Until:   qs.Get("until"),
+		Grep:    qs.Get("grep"),
+		Level:   qs.Get("level"),
+	}
+	if v := qs.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			utils.WriteError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		q.Limit = n
+	}
+	entries, err := h.svcFor(r).QueryLogs(q)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidLogQuery) {
+			status = http.StatusBadRequest
+		}
+		utils.WriteError(w, status, err.Error())
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, entries)
+}
+
 func (h *Handler) handleSysInfo(w http.ResponseWriter, r *http.Request) {
 	info, err := h.svcFor(r).GetSysInfo()
 	if err != nil {
@@ -0,0 +1,48 @@
+package grengo
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/skaia/backend/models"
+	pb "github.com/skaia/grpc/grengo"
+)
+
+// alertEvent is the part of a grengo alert the inbox needs.
+type alertEvent struct {
+	Users   []int64 `json:"users"`
+	Message string  `json:"message"`
+}
+
+// WatchAlerts subscribes to grengo alerts routed to this client and posts
+// each one as a system message to the listed users' inboxes.
+func (s *Service) WatchAlerts(inbox models.InboxSender, client string) {
+	if inbox == nil || client == "" {
+		return
+	}
+	for {
+		stream, err := s.client.WatchAlerts(context.Background(), &pb.WatchAlertsRequest{Client: client})
+		if err != nil {
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				break
+			}
+			var ev alertEvent
+			if err := json.Unmarshal([]byte(resp.EventJson), &ev); err != nil {
+				continue
+			}
+			for _, uid := range ev.Users {
+				if err := inbox.SendSystemMessage(uid, ev.Message, "text"); err != nil {
+					log.Printf("grengo: alert inbox message to user %d: %v", uid, err)
+				}
+			}
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
@@ -26,3 +26,13 @@ func (s *Service) VerifyPasscode(p1, p2 string) bool {
 	}
 	return resp.Valid
 }
+
+// Login exchanges a grengo operator's credentials for a session token. A
+// response with TotpRequired set asks for the TOTP code.
+func (s *Service) Login(username, password, totp string) (*pb.LoginResponse, error) {
+	return s.client.Login(context.Background(), &pb.LoginRequest{
+		Username: username,
+		Password: password,
+		Totp:     totp,
+	})
+}
@@ -0,0 +1,63 @@
+package grengo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/skaia/grpc/grengo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LogEntry is one stored container log line.
+type LogEntry struct {
+	Time      time.Time `json:"time"`
+	Client    string    `json:"client"`
+	Container string    `json:"container"`
+	Stream    string    `json:"stream"`
+	Level     string    `json:"level"`
+	Msg       string    `json:"msg"`
+}
+
+// LogQuery filters stored logs. Since and Until take a duration back from
+// now (90m, 2d) or a timestamp; Level is the lowest level returned.
+type LogQuery struct {
+	Clients []string
+	Since   string
+	Until   string
+	Grep    string
+	Level   string
+	Limit   int
+}
+
+// ErrInvalidLogQuery wraps grengo's rejection of a query's filters.
+var ErrInvalidLogQuery = errors.New("invalid log query")
+
+// QueryLogs searches the container logs grengo has stored, oldest first.
+func (s *Service) QueryLogs(q LogQuery) ([]LogEntry, error) {
+	resp, err := s.client.QueryLogs(context.Background(), &pb.QueryLogsRequest{
+		Clients: q.Clients,
+		Since:   q.Since,
+		Until:   q.Until,
+		Grep:    q.Grep,
+		Level:   q.Level,
+		Limit:   int32(q.Limit),
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidLogQuery, status.Convert(err).Message())
+		}
+		return nil, fmt.Errorf("grengo API: %w", err)
+	}
+	var entries []LogEntry
+	if err := json.Unmarshal([]byte(resp.EntriesJson), &entries); err != nil {
+		return nil, fmt.Errorf("decode log entries: %w", err)
+	}
+	if entries == nil {
+		entries = []LogEntry{}
+	}
+	return entries, nil
+}
@@ -85,27 +85,47 @@ type apiKeyRecord struct {
 	keyID     int64
 	userID    int64
 	threshold int
+	siteScope []string
 }
 
 // lookupAPIKey fetches key metadata from grengo_api_keys.
-// Returns sql.ErrNoRows when the key is not found or has been revoked.
+// Returns sql.ErrNoRows when the key is not found, revoked or expired.
 func lookupAPIKey(ctx context.Context, db *sql.DB, prefix, hashHex string) (apiKeyRecord, error) {
 	var rec apiKeyRecord
+	var sites string
 	err := db.QueryRowContext(ctx, `
-		SELECT id, user_id, threshold_per_minute
+		SELECT id, user_id, threshold_per_minute, array_to_string(site_scope, ',')
 		FROM grengo_api_keys
 		WHERE key_prefix = $1 AND key_hash = $2 AND revoked_at IS NULL
-	`, prefix, hashHex).Scan(&rec.keyID, &rec.userID, &rec.threshold)
+		  AND (expires_at IS NULL OR expires_at > NOW())
+	`, prefix, hashHex).Scan(&rec.keyID, &rec.userID, &rec.threshold, &sites)
+	if sites != "" {
+		rec.siteScope = strings.Split(sites, ",")
+	}
 	return rec, err
 }
 
+// siteAllowed reports whether a key limited to some sites may be used on
+// this tenant, named by CLIENT_NAME.
+func (rec apiKeyRecord) siteAllowed(client string) bool {
+	if len(rec.siteScope) == 0 {
+		return true
+	}
+	for _, s := range rec.siteScope {
+		if s == client {
+			return true
+		}
+	}
+	return false
+}
+
 // collectPermissions intersects the API key's module grants with the user's
 // tenant-level permissions and returns the allowed permission strings.
 func collectPermissions(ctx context.Context, grengoDb *sql.DB, rec apiKeyRecord) ([]string, error) {
 	rows, err := grengoDb.QueryContext(ctx, `
 		SELECT module, can_read, can_write
 		FROM grengo_api_key_permissions
-		WHERE api_key_id = $1
+		WHERE api_key_id = $1 AND inactive_at IS NULL
 	`, rec.keyID)
 	if err != nil {
 		return nil, err
@@ -212,6 +232,12 @@ func apiKeyWithinLimit(prefix string, threshold int) bool {
 	return true
 }
 
+// APIKeyFromRequest returns the raw API key a request was made with, if
+// any, so it can be passed on to grengo.
+func APIKeyFromRequest(r *http.Request) string {
+	return apiKeyFromRequestHeaders(r.Header, r.Header.Get("Authorization"))
+}
+
 // apiKeyFromRequestHeaders extracts a raw API key from request headers.
 // Checks X-Skaia-API-Key / X-Grengo-API-Key / X-API-Key first, then the
 // "ApiKey <key>" Authorization scheme.
@@ -245,7 +271,7 @@ func claimsFromAPIKey(ctx context.Context, rawKey string) (*ijwt.Claims, bool) {
 	hashHex := hashAPIKey(rawKey)
 
 	rec, err := lookupAPIKey(ctx, db, prefix, hashHex)
-	if err != nil {
+	if err != nil || !rec.siteAllowed(os.Getenv("CLIENT_NAME")) {
 		return nil, false
 	}
 	if !apiKeyWithinLimit(prefix, rec.threshold) {
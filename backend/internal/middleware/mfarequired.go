@@ -15,25 +15,28 @@ import (
 )
 
 const (
-	mfaChallengePath = "/api/auth/mfa-challenge"
-	mfaSessionTTL    = 24 * time.Hour
+	mfaChallengePath      = "/api/auth/mfa-challenge"
+	mfaPasskeyOptionsPath = "/api/auth/mfa-challenge/passkey-options"
+	mfaSessionTTL         = 24 * time.Hour
 )
 
 type mfaCodeRequest struct {
-	TOTPCode   string `json:"totp_code"`
-	BackupCode string `json:"backup_code"`
+	TOTPCode   string                  `json:"totp_code"`
+	BackupCode string                  `json:"backup_code"`
+	Passkey    *auth.PasskeyCredential `json:"passkey"`
 }
 
 type mfaRequiredError struct {
-	status models.MFAChallengeStatus
+	status  models.MFAChallengeStatus
+	methods []string
 }
 
 func (e *mfaRequiredError) Error() string { return "MFA Required" }
 
-// MFARequiredMiddleware enforces MFA verification for authenticated users with TOTP enabled.
-// It allows requests through if:
+// MFARequiredMiddleware enforces MFA verification for authenticated users with TOTP
+// or a passkey set up. It allows requests through if:
 //   - The request is unauthenticated
-//   - The user does not have TOTP enabled
+//   - The user has neither TOTP nor a passkey
 //   - The user has already completed an MFA challenge within the last 24 hours
 func MFARequiredMiddleware(authSvc *auth.Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -41,6 +44,7 @@ func MFARequiredMiddleware(authSvc *auth.Service) func(http.Handler) http.Handle
 			// Bypass certain routes
 			if r.URL.Path == "/api/auth/logout" ||
 				r.URL.Path == "/api/auth/refresh" ||
+				r.URL.Path == mfaPasskeyOptionsPath ||
 				strings.HasPrefix(r.URL.Path, "/api/auth/admin/recovery-requests/") ||
 				strings.HasPrefix(r.URL.Path, "/api/grengo/s/") {
 				next.ServeHTTP(w, r)
@@ -53,12 +57,12 @@ func MFARequiredMiddleware(authSvc *auth.Service) func(http.Handler) http.Handle
 				return
 			}
 
-			_, totpEnabled, err := authSvc.GetTOTPEnabled(r.Context(), userID)
+			methods, err := authSvc.MFAMethods(r.Context(), userID)
 			if err != nil {
 				utils.WriteError(w, http.StatusInternalServerError, "internal server error")
 				return
 			}
-			if !totpEnabled {
+			if len(methods) == 0 {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -68,10 +72,10 @@ func MFARequiredMiddleware(authSvc *auth.Service) func(http.Handler) http.Handle
 				return
 			}
 
-			if err := assertMFACleared(r, authSvc, userID); err != nil {
+			if err := assertMFACleared(r, authSvc, userID, methods); err != nil {
 				var required *mfaRequiredError
 				if errors.As(err, &required) {
-					writeMFARequired(w, required.status)
+					writeMFARequired(w, required.status, required.methods)
 					return
 				}
 				utils.WriteError(w, http.StatusInternalServerError, "failed to verify MFA status")
@@ -83,14 +87,21 @@ func MFARequiredMiddleware(authSvc *auth.Service) func(http.Handler) http.Handle
 	}
 }
 
-func writeMFARequired(w http.ResponseWriter, status models.MFAChallengeStatus) {
+// writeMFARequired reports a pending challenge. "challenge" names the
+// preferred method, TOTP when the user has it, and "methods" lists every
+// method /api/auth/mfa-challenge accepts for this user.
+func writeMFARequired(w http.ResponseWriter, status models.MFAChallengeStatus, methods []string) {
 	reason := status.Reason
 	if reason == "" {
 		reason = auth.MFAReasonAuthenticationRequired
 	}
+	if len(methods) == 0 {
+		methods = []string{auth.MFAMethodTOTP}
+	}
 	utils.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
 		"error":       "MFA Required",
-		"challenge":   "totp",
+		"challenge":   methods[0],
+		"methods":     methods,
 		"reason_code": reason,
 		"action":      status.Action,
 	})
@@ -105,13 +116,7 @@ func handleMFAChallenge(w http.ResponseWriter, r *http.Request, authSvc *auth.Se
 		return
 	}
 
-	var valid bool
-	if req.BackupCode != "" {
-		valid, err = authSvc.ValidateTOTPBackupCode(r.Context(), userID, req.BackupCode)
-	} else {
-		valid, err = authSvc.VerifyTOTP(r.Context(), userID, req.TOTPCode)
-	}
-	if err != nil || !valid {
+	if valid, err := verifyMFACode(r, authSvc, userID, req); err != nil || !valid {
 		utils.WriteError(w, http.StatusUnauthorized, "invalid verification code")
 		return
 	}
@@ -128,20 +133,14 @@ func handleMFAChallenge(w http.ResponseWriter, r *http.Request, authSvc *auth.Se
 // assertMFACleared returns nil if the user has a valid MFA session, otherwise errMFARequired.
 // As a convenience, it also accepts an inline MFA code on the request body and clears
 // the challenge if valid - allowing clients to piggyback verification on their first request.
-func assertMFACleared(r *http.Request, authSvc *auth.Service, userID int64) error {
+func assertMFACleared(r *http.Request, authSvc *auth.Service, userID int64, methods []string) error {
 	mfaStatus, err := authSvc.GetMFARequired(r.Context(), userID)
 	expired := !mfaStatus.UpdatedAt.IsZero() && time.Since(mfaStatus.UpdatedAt) > mfaSessionTTL
 	if err != nil || mfaStatus.Required || expired {
 		req, body, _ := readMFABody(r)
 		r.Body = io.NopCloser(bytes.NewBuffer(body))
 		if req != nil {
-			var valid bool
-			if req.BackupCode != "" {
-				valid, _ = authSvc.ValidateTOTPBackupCode(r.Context(), userID, req.BackupCode)
-			} else {
-				valid, _ = authSvc.VerifyTOTP(r.Context(), userID, req.TOTPCode)
-			}
-			if valid {
+			if valid, _ := verifyMFACode(r, authSvc, userID, req); valid {
 				_ = authSvc.SetMFARequired(r.Context(), userID, false)
 				return nil
 			}
@@ -153,11 +152,24 @@ func assertMFACleared(r *http.Request, authSvc *auth.Service, userID int64) erro
 			mfaStatus.Reason = auth.MFAReasonSessionExpired
 			mfaStatus.Action = ""
 		}
-		return &mfaRequiredError{status: mfaStatus}
+		return &mfaRequiredError{status: mfaStatus, methods: methods}
 	}
 	return nil
 }
 
+// verifyMFACode checks whichever answer the request carries: a passkey
+// assertion, a backup code or a TOTP code.
+func verifyMFACode(r *http.Request, authSvc *auth.Service, userID int64, req *mfaCodeRequest) (bool, error) {
+	switch {
+	case req.Passkey != nil:
+		return authSvc.VerifyPasskeyMFA(r.Context(), auth.RelyingPartyFor(r), userID, req.Passkey)
+	case req.BackupCode != "":
+		return authSvc.ValidateTOTPBackupCode(r.Context(), userID, req.BackupCode)
+	default:
+		return authSvc.VerifyTOTP(r.Context(), userID, req.TOTPCode)
+	}
+}
+
 // readMFABody reads the request body and decodes it into an mfaCodeRequest.
 // The raw bytes are always returned so callers can restore r.Body.
 func readMFABody(r *http.Request) (*mfaCodeRequest, []byte, error) {
@@ -171,8 +183,8 @@ func readMFABody(r *http.Request) (*mfaCodeRequest, []byte, error) {
 		return nil, body, errors.New("invalid JSON")
 	}
 
-	if req.TOTPCode == "" && req.BackupCode == "" {
-		return nil, body, errors.New("totp_code or backup_code required")
+	if req.TOTPCode == "" && req.BackupCode == "" && req.Passkey == nil {
+		return nil, body, errors.New("totp_code, backup_code or passkey required")
 	}
 
 	return &req, body, nil
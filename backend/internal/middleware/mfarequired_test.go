@@ -19,7 +19,7 @@ func TestWriteMFARequiredIncludesChallengeContext(t *testing.T) {
 		Required: true,
 		Reason:   auth.MFAReasonSensitiveAction,
 		Action:   "revoke session",
-	})
+	}, []string{auth.MFAMethodTOTP, auth.MFAMethodPasskey})
 
 	require.Equal(t, http.StatusUnauthorized, w.Code)
 	var response map[string]interface{}
@@ -28,14 +28,26 @@ func TestWriteMFARequiredIncludesChallengeContext(t *testing.T) {
 	assert.Equal(t, "totp", response["challenge"])
 	assert.Equal(t, auth.MFAReasonSensitiveAction, response["reason_code"])
 	assert.Equal(t, "revoke session", response["action"])
+	assert.Equal(t, []interface{}{"totp", "passkey"}, response["methods"])
 }
 
 func TestWriteMFARequiredDefaultsMissingReason(t *testing.T) {
 	w := httptest.NewRecorder()
 
-	writeMFARequired(w, models.MFAChallengeStatus{Required: true})
+	writeMFARequired(w, models.MFAChallengeStatus{Required: true}, nil)
 
 	var response map[string]interface{}
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
 	assert.Equal(t, auth.MFAReasonAuthenticationRequired, response["reason_code"])
+	assert.Equal(t, "totp", response["challenge"])
+}
+
+func TestWriteMFARequiredOffersPasskeyAlone(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	writeMFARequired(w, models.MFAChallengeStatus{Required: true}, []string{auth.MFAMethodPasskey})
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "passkey", response["challenge"])
 }
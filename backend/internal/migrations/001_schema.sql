@@ -481,6 +481,15 @@ ALTER TABLE pages DROP COLUMN IF EXISTS is_index;
 ALTER TABLE pages ADD COLUMN IF NOT EXISTS owner_id BIGINT REFERENCES users(id) ON DELETE SET NULL;
 CREATE INDEX IF NOT EXISTS idx_pages_owner ON pages(owner_id);
 
+-- Draft/published workflow: readers only see published pages.
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published';
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_pages_publish_at
+    ON pages(publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_pages_unpublish_at
+    ON pages(unpublish_at) WHERE unpublish_at IS NOT NULL;
+
 -- Multi-documentation hub. Article bodies are TipTap HTML and remain separate
 -- from custom pages and forum threads.
 CREATE TABLE IF NOT EXISTS documentations (
@@ -552,6 +561,82 @@ CREATE TABLE IF NOT EXISTS page_editors (
 CREATE INDEX IF NOT EXISTS idx_page_editors_page ON page_editors(page_id);
 CREATE INDEX IF NOT EXISTS idx_page_editors_user ON page_editors(user_id);
 
+-- Immutable page revisions; participant records are stripped before storage.
+CREATE TABLE IF NOT EXISTS page_revisions (
+    id              BIGSERIAL PRIMARY KEY,
+    page_id         BIGINT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    author_id       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    slug            VARCHAR(120) NOT NULL,
+    title           VARCHAR(255) NOT NULL DEFAULT '',
+    description     TEXT         NOT NULL DEFAULT '',
+    seo_title       VARCHAR(255) NOT NULL DEFAULT '',
+    seo_description TEXT         NOT NULL DEFAULT '',
+    seo_image       TEXT         NOT NULL DEFAULT '',
+    visibility      VARCHAR(20)  NOT NULL DEFAULT 'public',
+    content         JSONB        NOT NULL DEFAULT '[]',
+    restored_from   BIGINT REFERENCES page_revisions(id),
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_page_revisions_page
+    ON page_revisions(page_id, id DESC);
+
+-- One working draft per page, promoted into pages on publish.
+CREATE TABLE IF NOT EXISTS page_drafts (
+    page_id     BIGINT PRIMARY KEY REFERENCES pages(id) ON DELETE CASCADE,
+    author_id   BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    title       VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT         NOT NULL DEFAULT '',
+    content     JSONB        NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    cleared_at  TIMESTAMPTZ
+);
+
+-- Interactive section responses, keyed by page and builder section id.
+CREATE TABLE IF NOT EXISTS page_interactive_responses (
+    id                   VARCHAR(64)  PRIMARY KEY,
+    page_id              BIGINT       NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    section_id           BIGINT       NOT NULL,
+    user_id              BIGINT       REFERENCES users(id) ON DELETE SET NULL,
+    respondent_name      VARCHAR(255) NOT NULL DEFAULT '',
+    answers              JSONB        NOT NULL DEFAULT '{}',
+    status               VARCHAR(20)  NOT NULL DEFAULT 'submitted',
+    answer               TEXT         NOT NULL DEFAULT '',
+    pinned               BOOLEAN      NOT NULL DEFAULT FALSE,
+    idempotency_key_hash VARCHAR(64)  NOT NULL DEFAULT '',
+    submitted_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ,
+    deleted_at           TIMESTAMPTZ,
+    deleted_by           BIGINT       REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_page_interactive_responses_section
+    ON page_interactive_responses(page_id, section_id, submitted_at DESC, id)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_page_interactive_responses_user
+    ON page_interactive_responses(page_id, section_id, user_id)
+    WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_page_interactive_responses_idempotency
+    ON page_interactive_responses(page_id, section_id, user_id, idempotency_key_hash)
+    WHERE deleted_at IS NULL AND idempotency_key_hash <> '';
+
+-- Page template gallery; bundle is a portable page export.
+CREATE TABLE IF NOT EXISTS page_templates (
+    id          BIGSERIAL    PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    category    VARCHAR(80)  NOT NULL DEFAULT '',
+    bundle      JSONB        NOT NULL,
+    created_by  BIGINT       REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  BIGINT       REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_page_templates_name
+    ON page_templates(LOWER(name)) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_page_templates_category
+    ON page_templates(category, name) WHERE deleted_at IS NULL;
+
 -- Page engagement: views, likes, comments
 
 -- Old page_views table and pages.view_count replaced by resource_views (006).
@@ -705,6 +790,39 @@ CREATE TABLE IF NOT EXISTS auth_backup_codes (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE IF NOT EXISTS auth_identities (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider      VARCHAR(64) NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+    unlinked_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS auth_identities_active_subject_unique
+    ON auth_identities(provider, subject) WHERE unlinked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_auth_identities_user
+    ON auth_identities(user_id) WHERE unlinked_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS auth_webauthn_credentials (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id BYTEA NOT NULL,
+    public_key    BYTEA NOT NULL,
+    sign_count    BIGINT NOT NULL DEFAULT 0,
+    name          VARCHAR(64) NOT NULL DEFAULT '',
+    aaguid        VARCHAR(36) NOT NULL DEFAULT '',
+    transports    TEXT[] NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at  TIMESTAMPTZ,
+    cleared_at    TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS auth_webauthn_active_credential_unique
+    ON auth_webauthn_credentials(credential_id) WHERE cleared_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_auth_webauthn_user
+    ON auth_webauthn_credentials(user_id) WHERE cleared_at IS NULL;
+
 CREATE TABLE IF NOT EXISTS mfa_challenge_required (
     user_id     BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
     required    BOOLEAN NOT NULL DEFAULT false,
@@ -0,0 +1,22 @@
+-- Links between users and their accounts at the tenant's OIDC providers.
+-- Providers themselves live in site_config under oidc_providers.
+CREATE TABLE IF NOT EXISTS auth_identities (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider      VARCHAR(64) NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+    unlinked_at   TIMESTAMPTZ
+);
+
+-- A provider account links to at most one user; unlinked rows are kept.
+CREATE UNIQUE INDEX IF NOT EXISTS auth_identities_active_subject_unique
+    ON auth_identities(provider, subject) WHERE unlinked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_auth_identities_user
+    ON auth_identities(user_id) WHERE unlinked_at IS NULL;
+
+DROP TRIGGER IF EXISTS skaia_reject_hard_delete ON auth_identities;
+CREATE TRIGGER skaia_reject_hard_delete BEFORE DELETE ON auth_identities
+    FOR EACH ROW EXECUTE FUNCTION reject_skaia_hard_delete();
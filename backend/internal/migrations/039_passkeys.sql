@@ -0,0 +1,24 @@
+-- WebAuthn credentials (passkeys) used for passwordless login and as an
+-- MFA method next to TOTP.
+CREATE TABLE IF NOT EXISTS auth_webauthn_credentials (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id BYTEA NOT NULL,
+    public_key    BYTEA NOT NULL,
+    sign_count    BIGINT NOT NULL DEFAULT 0,
+    name          VARCHAR(64) NOT NULL DEFAULT '',
+    aaguid        VARCHAR(36) NOT NULL DEFAULT '',
+    transports    TEXT[] NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at  TIMESTAMPTZ,
+    cleared_at    TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS auth_webauthn_active_credential_unique
+    ON auth_webauthn_credentials(credential_id) WHERE cleared_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_auth_webauthn_user
+    ON auth_webauthn_credentials(user_id) WHERE cleared_at IS NULL;
+
+DROP TRIGGER IF EXISTS skaia_reject_hard_delete ON auth_webauthn_credentials;
+CREATE TRIGGER skaia_reject_hard_delete BEFORE DELETE ON auth_webauthn_credentials
+    FOR EACH ROW EXECUTE FUNCTION reject_skaia_hard_delete();
@@ -0,0 +1,48 @@
+-- Immutable page revisions. Every builder save, SEO change and restore appends
+-- one snapshot; interactive participant records are stripped before storage so
+-- history never becomes a second copy of form, poll or Q&A responses. Pages
+-- that predate this table receive a baseline snapshot on their first save.
+CREATE TABLE IF NOT EXISTS page_revisions (
+    id              BIGSERIAL PRIMARY KEY,
+    page_id         BIGINT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    author_id       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    slug            VARCHAR(120) NOT NULL,
+    title           VARCHAR(255) NOT NULL DEFAULT '',
+    description     TEXT         NOT NULL DEFAULT '',
+    seo_title       VARCHAR(255) NOT NULL DEFAULT '',
+    seo_description TEXT         NOT NULL DEFAULT '',
+    seo_image       TEXT         NOT NULL DEFAULT '',
+    visibility      VARCHAR(20)  NOT NULL DEFAULT 'public',
+    content         JSONB        NOT NULL DEFAULT '[]',
+    restored_from   BIGINT REFERENCES page_revisions(id),
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_page_revisions_page
+    ON page_revisions(page_id, id DESC);
+
+-- Only author_id may change after insert, and only through the users foreign
+-- key clearing it; the snapshot itself is append-only.
+CREATE OR REPLACE FUNCTION reject_page_revision_update() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF ROW(NEW.id, NEW.page_id, NEW.slug, NEW.title, NEW.description, NEW.seo_title,
+           NEW.seo_description, NEW.seo_image, NEW.visibility, NEW.content,
+           NEW.restored_from, NEW.created_at)
+       IS DISTINCT FROM
+       ROW(OLD.id, OLD.page_id, OLD.slug, OLD.title, OLD.description, OLD.seo_title,
+           OLD.seo_description, OLD.seo_image, OLD.visibility, OLD.content,
+           OLD.restored_from, OLD.created_at) THEN
+        RAISE EXCEPTION 'page revisions are immutable'
+            USING ERRCODE='42501', HINT='Restore a revision to create a new one.';
+    END IF;
+    RETURN NEW;
+END
+$$;
+
+DROP TRIGGER IF EXISTS page_revisions_immutable ON page_revisions;
+CREATE TRIGGER page_revisions_immutable BEFORE UPDATE ON page_revisions
+    FOR EACH ROW EXECUTE FUNCTION reject_page_revision_update();
+
+DROP TRIGGER IF EXISTS skaia_reject_hard_delete ON page_revisions;
+CREATE TRIGGER skaia_reject_hard_delete BEFORE DELETE ON page_revisions
+    FOR EACH ROW EXECUTE FUNCTION reject_skaia_hard_delete();
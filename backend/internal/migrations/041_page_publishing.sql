@@ -0,0 +1,32 @@
+-- Draft/published workflow. Readers only ever see published pages; editors
+-- stage changes in one working draft per page and promote it on publish,
+-- either immediately or at a scheduled time handled by the page scheduler.
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published';
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMPTZ;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'pages_status_check') THEN
+        ALTER TABLE pages ADD CONSTRAINT pages_status_check CHECK (status IN ('draft', 'published'));
+    END IF;
+END
+$$;
+CREATE INDEX IF NOT EXISTS idx_pages_publish_at
+    ON pages(publish_at) WHERE publish_at IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pages_unpublish_at
+    ON pages(unpublish_at) WHERE unpublish_at IS NOT NULL AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS page_drafts (
+    page_id     BIGINT PRIMARY KEY REFERENCES pages(id) ON DELETE CASCADE,
+    author_id   BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    title       VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT         NOT NULL DEFAULT '',
+    content     JSONB        NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    cleared_at  TIMESTAMPTZ
+);
+
+DROP TRIGGER IF EXISTS skaia_reject_hard_delete ON page_drafts;
+CREATE TRIGGER skaia_reject_hard_delete BEFORE DELETE ON page_drafts
+    FOR EACH ROW EXECUTE FUNCTION reject_skaia_hard_delete();
@@ -0,0 +1,137 @@
+-- Interactive section responses (forms, surveys, polls, votes and Q&A) live
+-- in their own table keyed by page and builder section id instead of the
+-- "records" array embedded in each section's config inside pages.content.
+CREATE TABLE IF NOT EXISTS page_interactive_responses (
+    id                   VARCHAR(64)  PRIMARY KEY,
+    page_id              BIGINT       NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    section_id           BIGINT       NOT NULL,
+    user_id              BIGINT       REFERENCES users(id) ON DELETE SET NULL,
+    respondent_name      VARCHAR(255) NOT NULL DEFAULT '',
+    answers              JSONB        NOT NULL DEFAULT '{}',
+    status               VARCHAR(20)  NOT NULL DEFAULT 'submitted',
+    answer               TEXT         NOT NULL DEFAULT '',
+    pinned               BOOLEAN      NOT NULL DEFAULT FALSE,
+    idempotency_key_hash VARCHAR(64)  NOT NULL DEFAULT '',
+    submitted_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ,
+    deleted_at           TIMESTAMPTZ,
+    deleted_by           BIGINT       REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_page_interactive_responses_section
+    ON page_interactive_responses(page_id, section_id, submitted_at DESC, id)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_page_interactive_responses_user
+    ON page_interactive_responses(page_id, section_id, user_id)
+    WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_page_interactive_responses_idempotency
+    ON page_interactive_responses(page_id, section_id, user_id, idempotency_key_hash)
+    WHERE deleted_at IS NULL AND idempotency_key_hash <> '';
+
+DROP TRIGGER IF EXISTS skaia_reject_hard_delete ON page_interactive_responses;
+CREATE TRIGGER skaia_reject_hard_delete BEFORE DELETE ON page_interactive_responses
+    FOR EACH ROW EXECUTE FUNCTION reject_skaia_hard_delete();
+
+-- Lift records still embedded in page content into the table, then clear them
+-- from the document. Each page is handled in its own subtransaction so one
+-- malformed config is logged and skipped rather than failing startup. The
+-- runtime never reads embedded records, so every record must land in the
+-- table: a record without an id, or whose id is already taken (ids are global)
+-- or repeated within the page, gets a deterministic key derived from its page,
+-- position and body, and a repeated idempotency key is dropped from all but
+-- the first copy. Once a page is lifted its content carries no records, so
+-- re-running is a no-op.
+DO $$
+DECLARE
+    page_row RECORD;
+BEGIN
+    FOR page_row IN
+        SELECT id, content FROM pages
+        WHERE jsonb_typeof(content) = 'array'
+          AND content::text ~ 'records\\?": ?\[\{'
+    LOOP
+        BEGIN
+            INSERT INTO page_interactive_responses (
+                id, page_id, section_id, user_id, respondent_name, answers, status,
+                answer, pinned, idempotency_key_hash, submitted_at, updated_at
+            )
+            SELECT
+                CASE WHEN lifted.rec_id <> ''
+                          AND COUNT(*) OVER (PARTITION BY lifted.rec_id) = 1
+                          AND NOT EXISTS (SELECT 1 FROM page_interactive_responses r WHERE r.id = lifted.rec_id)
+                     THEN lifted.rec_id
+                     ELSE md5(page_row.id || ':' || lifted.section_pos || ':' || lifted.rec_pos || ':' || lifted.rec::text)
+                END,
+                page_row.id,
+                lifted.section_id,
+                lifted.user_id,
+                LEFT(COALESCE(lifted.rec->>'respondent_name', ''), 255),
+                CASE WHEN jsonb_typeof(lifted.rec->'answers') = 'object' THEN lifted.rec->'answers' ELSE '{}'::jsonb END,
+                COALESCE(NULLIF(lifted.rec->>'status', ''), 'submitted'),
+                COALESCE(lifted.rec->>'answer', ''),
+                COALESCE((lifted.rec->>'pinned')::BOOLEAN, FALSE),
+                CASE WHEN lifted.key_hash <> ''
+                          AND ROW_NUMBER() OVER (
+                              PARTITION BY lifted.section_id, lifted.user_id, lifted.key_hash
+                              ORDER BY lifted.section_pos, lifted.rec_pos) = 1
+                          AND NOT EXISTS (
+                              SELECT 1 FROM page_interactive_responses r
+                              WHERE r.page_id = page_row.id AND r.section_id = lifted.section_id
+                                AND r.user_id = lifted.user_id AND r.idempotency_key_hash = lifted.key_hash
+                                AND r.deleted_at IS NULL)
+                     THEN lifted.key_hash
+                     ELSE ''
+                END,
+                COALESCE((lifted.rec->>'submitted_at')::TIMESTAMPTZ, NOW()),
+                NULLIF(lifted.rec->>'updated_at', '0001-01-01T00:00:00Z')::TIMESTAMPTZ
+            FROM (
+                SELECT
+                    sections.section_pos,
+                    records.rec_pos,
+                    records.rec,
+                    (sections.section->>'id')::BIGINT AS section_id,
+                    COALESCE(records.rec->>'id', '') AS rec_id,
+                    (SELECT u.id FROM users u WHERE u.id = (records.rec->>'user_id')::BIGINT) AS user_id,
+                    COALESCE(
+                        NULLIF(records.rec->>'idempotency_key_hash', ''),
+                        CASE WHEN COALESCE(records.rec->>'idempotency_key', '') <> ''
+                             THEN encode(sha256(convert_to(records.rec->>'idempotency_key', 'UTF8')), 'hex')
+                             ELSE '' END
+                    ) AS key_hash
+                FROM jsonb_array_elements(page_row.content) WITH ORDINALITY AS sections(section, section_pos)
+                CROSS JOIN LATERAL (
+                    SELECT CASE jsonb_typeof(sections.section->'config')
+                        WHEN 'string' THEN NULLIF(sections.section->>'config', '')::jsonb
+                        WHEN 'object' THEN sections.section->'config'
+                    END AS cfg
+                ) config
+                CROSS JOIN LATERAL jsonb_array_elements(
+                    CASE WHEN jsonb_typeof(config.cfg->'records') = 'array' THEN config.cfg->'records' ELSE '[]'::jsonb END
+                ) WITH ORDINALITY AS records(rec, rec_pos)
+                WHERE sections.section->>'section_type' IN ('form', 'qa', 'survey', 'poll', 'vote')
+                  AND (sections.section->>'id') ~ '^[0-9]+$'
+                  AND jsonb_typeof(records.rec) = 'object'
+            ) lifted;
+
+            UPDATE pages SET content = (
+                SELECT COALESCE(jsonb_agg(
+                    CASE
+                        WHEN section->>'section_type' IN ('form', 'qa', 'survey', 'poll', 'vote')
+                             AND jsonb_typeof(section->'config') = 'string'
+                             AND NULLIF(section->>'config', '') IS NOT NULL
+                        THEN jsonb_set(section, '{config}', to_jsonb(
+                            ((((section->>'config')::jsonb) - 'result_summary') || '{"records":[]}'::jsonb)::text))
+                        WHEN section->>'section_type' IN ('form', 'qa', 'survey', 'poll', 'vote')
+                             AND jsonb_typeof(section->'config') = 'object'
+                        THEN jsonb_set(section, '{config}',
+                            ((section->'config') - 'result_summary') || '{"records":[]}'::jsonb)
+                        ELSE section
+                    END ORDER BY position), '[]'::jsonb)
+                FROM jsonb_array_elements(page_row.content) WITH ORDINALITY AS elements(section, position)
+            )
+            WHERE id = page_row.id;
+        EXCEPTION WHEN OTHERS THEN
+            RAISE WARNING 'page_interactive_responses: page % kept its embedded records: %', page_row.id, SQLERRM;
+        END;
+    END LOOP;
+END
+$$;
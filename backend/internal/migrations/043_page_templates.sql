@@ -0,0 +1,24 @@
+-- Page template gallery. Each template stores a portable page bundle: the
+-- section document plus the custom sections, data sources (environment
+-- values redacted) and uploaded images it references, so a layout can be
+-- exported from one tenant and instantiated in another.
+CREATE TABLE IF NOT EXISTS page_templates (
+    id          BIGSERIAL    PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    category    VARCHAR(80)  NOT NULL DEFAULT '',
+    bundle      JSONB        NOT NULL,
+    created_by  BIGINT       REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  BIGINT       REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_page_templates_name
+    ON page_templates(LOWER(name)) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_page_templates_category
+    ON page_templates(category, name) WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS skaia_reject_hard_delete ON page_templates;
+CREATE TRIGGER skaia_reject_hard_delete BEFORE DELETE ON page_templates
+    FOR EACH ROW EXECUTE FUNCTION reject_skaia_hard_delete();
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOIDCIdentitiesHaveFreshAndIncrementalParity(t *testing.T) {
+	fresh, err := os.ReadFile("001_schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incremental, err := os.ReadFile("038_oidc_identities.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contract := range []string{"CREATE TABLE IF NOT EXISTS auth_identities", "auth_identities_active_subject_unique"} {
+		if !strings.Contains(string(fresh), contract) {
+			t.Errorf("fresh schema missing %s", contract)
+		}
+		if !strings.Contains(string(incremental), contract) {
+			t.Errorf("migration 038 missing %s", contract)
+		}
+	}
+	if !strings.Contains(string(incremental), "skaia_reject_hard_delete") {
+		t.Error("migration 038 missing the hard-delete guard")
+	}
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPageInteractiveResponsesHaveFreshAndIncrementalParity(t *testing.T) {
+	fresh, err := os.ReadFile("001_schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incremental, err := os.ReadFile("042_page_interactive_responses.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contract := range []string{
+		"CREATE TABLE IF NOT EXISTS page_interactive_responses",
+		"idx_page_interactive_responses_section",
+		"idx_page_interactive_responses_idempotency",
+	} {
+		if !strings.Contains(string(fresh), contract) {
+			t.Errorf("fresh schema missing %s", contract)
+		}
+		if !strings.Contains(string(incremental), contract) {
+			t.Errorf("migration 042 missing %s", contract)
+		}
+	}
+	for _, contract := range []string{
+		"reject_skaia_hard_delete", "EXCEPTION WHEN OTHERS", `'{"records":[]}'`,
+		// Taken or repeated ids are re-keyed so every record is lifted.
+		"WHERE r.id = lifted.rec_id", "md5(page_row.id",
+	} {
+		if !strings.Contains(string(incremental), contract) {
+			t.Errorf("migration 042 missing %s", contract)
+		}
+	}
+	if strings.Contains(string(incremental), "ON CONFLICT DO NOTHING") {
+		t.Error("migration 042 must not skip conflicting records before clearing them from content")
+	}
+}
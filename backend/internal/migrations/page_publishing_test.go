@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPagePublishingHasFreshAndIncrementalParity(t *testing.T) {
+	fresh, err := os.ReadFile("001_schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incremental, err := os.ReadFile("041_page_publishing.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contract := range []string{
+		"ADD COLUMN IF NOT EXISTS status", "ADD COLUMN IF NOT EXISTS publish_at",
+		"ADD COLUMN IF NOT EXISTS unpublish_at", "CREATE TABLE IF NOT EXISTS page_drafts",
+	} {
+		if !strings.Contains(string(fresh), contract) {
+			t.Errorf("fresh schema missing %s", contract)
+		}
+		if !strings.Contains(string(incremental), contract) {
+			t.Errorf("migration 041 missing %s", contract)
+		}
+	}
+	if !strings.Contains(string(incremental), "pages_status_check") {
+		t.Error("migration 041 missing the page status constraint")
+	}
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPageRevisionsHaveFreshAndIncrementalParity(t *testing.T) {
+	fresh, err := os.ReadFile("001_schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incremental, err := os.ReadFile("040_page_revisions.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contract := range []string{"CREATE TABLE IF NOT EXISTS page_revisions", "idx_page_revisions_page"} {
+		if !strings.Contains(string(fresh), contract) {
+			t.Errorf("fresh schema missing %s", contract)
+		}
+		if !strings.Contains(string(incremental), contract) {
+			t.Errorf("migration 040 missing %s", contract)
+		}
+	}
+	for _, guard := range []string{"page_revisions_immutable", "skaia_reject_hard_delete"} {
+		if !strings.Contains(string(incremental), guard) {
+			t.Errorf("migration 040 missing the %s guard", guard)
+		}
+	}
+}
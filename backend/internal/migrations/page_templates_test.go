@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPageTemplatesHaveFreshAndIncrementalParity(t *testing.T) {
+	fresh, err := os.ReadFile("001_schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incremental, err := os.ReadFile("043_page_templates.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contract := range []string{
+		"CREATE TABLE IF NOT EXISTS page_templates",
+		"idx_page_templates_name",
+		"idx_page_templates_category",
+	} {
+		if !strings.Contains(string(fresh), contract) {
+			t.Errorf("fresh schema missing %s", contract)
+		}
+		if !strings.Contains(string(incremental), contract) {
+			t.Errorf("migration 043 missing %s", contract)
+		}
+	}
+	if !strings.Contains(string(incremental), "reject_skaia_hard_delete") {
+		t.Error("migration 043 does not guard page_templates against hard deletes")
+	}
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPasskeysHaveFreshAndIncrementalParity(t *testing.T) {
+	fresh, err := os.ReadFile("001_schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incremental, err := os.ReadFile("039_passkeys.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contract := range []string{"CREATE TABLE IF NOT EXISTS auth_webauthn_credentials", "auth_webauthn_active_credential_unique"} {
+		if !strings.Contains(string(fresh), contract) {
+			t.Errorf("fresh schema missing %s", contract)
+		}
+		if !strings.Contains(string(incremental), contract) {
+			t.Errorf("migration 039 missing %s", contract)
+		}
+	}
+	if !strings.Contains(string(incremental), "skaia_reject_hard_delete") {
+		t.Error("migration 039 missing the hard-delete guard")
+	}
+}
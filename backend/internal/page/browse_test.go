@@ -1,6 +1,7 @@
 package page
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -11,6 +12,8 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	ictx "github.com/skaia/backend/internal/ctx"
+	"github.com/skaia/backend/internal/jwt"
 	"github.com/skaia/backend/models"
 )
 
@@ -25,6 +28,27 @@ type browseRepo struct {
 	isEditor      bool
 	isEditorErr   error
 	isEditorCalls int
+	responses     map[int64][]*InteractiveRecord
+}
+
+func (r *browseRepo) PageInteractiveRecords(_, viewerID int64, _ []int64, _ int) (map[int64][]*InteractiveRecord, error) {
+	own := map[int64][]*InteractiveRecord{}
+	for sectionID, records := range r.responses {
+		for _, rec := range records {
+			if viewerID > 0 && rec.UserID == viewerID {
+				own[sectionID] = append(own[sectionID], rec)
+			}
+		}
+	}
+	return own, nil
+}
+
+func (r *browseRepo) PageInteractiveSummaries(int64, map[int64][]string) (map[int64]*InteractiveSummary, error) {
+	summaries := map[int64]*InteractiveSummary{}
+	for sectionID, records := range r.responses {
+		summaries[sectionID] = &InteractiveSummary{Total: len(records)}
+	}
+	return summaries, nil
 }
 
 func (r *browseRepo) BrowsePages(options BrowseOptions) (*BrowseResult, error) {
@@ -147,8 +171,13 @@ func TestMergePageUpdatePreservesOmittedDocumentFields(t *testing.T) {
 
 func TestGetPreviewEnforcesPrivacyAndSanitizesInteractiveRecords(t *testing.T) {
 	ownerID := int64(3)
-	content := `[{"id":7,"section_type":"form","config":"{\"status\":\"open\",\"result_visibility\":\"never\",\"records\":[{\"id\":\"secret\",\"user_id\":44,\"answers\":{\"name\":\"Private\"}}]}"}]`
-	repo := &browseRepo{page: &models.Page{ID: 9, OwnerID: &ownerID, Visibility: "private", Content: content, UpdatedAt: time.Now()}}
+	content := `[{"id":7,"section_type":"form","config":"{\"status\":\"open\",\"result_visibility\":\"never\"}"}]`
+	repo := &browseRepo{
+		page: &models.Page{ID: 9, OwnerID: &ownerID, Visibility: "private", Content: content, UpdatedAt: time.Now()},
+		responses: map[int64][]*InteractiveRecord{7: {{
+			ID: "secret", UserID: 44, Answers: map[string]interface{}{"name": "Private"},
+		}}},
+	}
 	svc := NewService(repo, nil)
 
 	if _, err := svc.GetPreview(9, 0, false); !errors.Is(err, ErrPageForbidden) {
@@ -240,3 +269,27 @@ func TestBrowsePreviewHandlerStatusMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestInteractiveHandlersHideDraftPagesFromReaders(t *testing.T) {
+	// A published page reaches the service, which refuses readers the
+	// response list; a draft page does not exist for them at all.
+	for status, want := range map[string]int{
+		PageStatusDraft:     http.StatusNotFound,
+		PageStatusPublished: http.StatusForbidden,
+	} {
+		t.Run(status, func(t *testing.T) {
+			repo := &browseRepo{page: &models.Page{ID: 2, Status: status, Visibility: "public", Content: "[]"}}
+			handler := NewHandler(NewService(repo, nil), nil, nil, nil, nil, nil)
+			router := chi.NewRouter()
+			identity := func(next http.Handler) http.Handler { return next }
+			handler.Mount(router, identity, identity)
+			request := httptest.NewRequest(http.MethodGet, "/pages/2/sections/7/responses", nil)
+			request = request.WithContext(context.WithValue(request.Context(), ictx.CtxKeyClaims, &jwt.Claims{UserID: 42}))
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+			if response.Code != want {
+				t.Fatalf("expected %d, got %d: %s", want, response.Code, response.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,470 @@
+package page
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/skaia/backend/internal/s_registry"
+	log "github.com/skaia/backend/internal/syslog"
+	"github.com/skaia/backend/models"
+)
+
+var (
+	ErrInvalidBundle = errors.New("invalid page bundle")
+	ErrPageSlugTaken = errors.New("page slug is already in use")
+)
+
+const (
+	PageBundleFormat  = "skaia.page-bundle"
+	PageBundleVersion = 1
+
+	maxBundleDataSources    = 50
+	maxBundleCustomSections = 50
+	maxBundleAssets         = 50
+	maxBundleAssetBytes     = 10 << 20
+)
+
+// uploadURLPattern matches site-relative upload paths inside page content.
+var uploadURLPattern = regexp.MustCompile(`/uploads/[A-Za-z0-9._/-]+`)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// BundleDataSources reads and recreates the data sources a bundle carries.
+type BundleDataSources interface {
+	GetByID(id int64) (*models.DataSource, error)
+	Create(ds *models.DataSource) error
+	Delete(id, actorID int64) error
+}
+
+// BundleCustomSections reads and recreates the custom sections a bundle carries.
+type BundleCustomSections interface {
+	GetByID(id int64) (*models.CustomSection, error)
+	Create(actorID int64, cs *models.CustomSection) error
+	Delete(actorID, id int64) error
+}
+
+// BundleAssets reads and stores the uploaded images a bundle carries.
+type BundleAssets interface {
+	ReadImage(url string) ([]byte, string, error)
+	SaveImage(userID int64, data []byte) (string, error)
+	DeleteImage(url string)
+}
+
+func WithBundleStores(dataSources BundleDataSources, customSections BundleCustomSections, assets BundleAssets) Option {
+	return func(s *Service) {
+		s.bundleDataSources = dataSources
+		s.bundleCustomSections = customSections
+		s.bundleAssets = assets
+	}
+}
+
+// configID reads a positive integer reference such as datasource_id from a
+// decoded section config.
+func configID(value interface{}) int64 {
+	if id, ok := value.(float64); ok && id > 0 && id == float64(int64(id)) {
+		return int64(id)
+	}
+	return 0
+}
+
+// envKeys lists the variable names of a KEY=VALUE environment block, never
+// the values.
+func envKeys(envData string) []string {
+	var keys []string
+	for _, line := range strings.Split(envData, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, found := strings.Cut(line, "=")
+		if key = strings.TrimSpace(key); found && envKeyPattern.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ExportBundle packages a page's live layout with the custom sections, data
+// sources and uploaded images it references. Interactive records and data
+// source environment values are never included.
+func (s *Service) ExportBundle(pageID int64) (*models.PageBundle, error) {
+	p, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	content := ClearInteractiveRecords(p.Content)
+	if strings.TrimSpace(content) == "" {
+		content = "[]"
+	}
+	sections, err := decodePageSections(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+	bundle := &models.PageBundle{
+		Format: PageBundleFormat, Version: PageBundleVersion, ExportedAt: time.Now().UTC(),
+		Title: p.Title, Description: p.Description,
+		SEOTitle: p.SEOTitle, SEODesc: p.SEODesc, SEOImage: p.SEOImage,
+		Content:        json.RawMessage(content),
+		CustomSections: []models.PageBundleCustomSection{},
+		DataSources:    []models.PageBundleDataSource{},
+		Assets:         []models.PageBundleAsset{},
+	}
+
+	seenDataSources := map[int64]bool{}
+	addDataSource := func(id int64) error {
+		if id <= 0 || seenDataSources[id] {
+			return nil
+		}
+		seenDataSources[id] = true
+		if s.bundleDataSources == nil {
+			return fmt.Errorf("data source export is not configured")
+		}
+		ds, err := s.bundleDataSources.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("data source %d: %w", id, err)
+		}
+		bundle.DataSources = append(bundle.DataSources, models.PageBundleDataSource{
+			Ref: ds.ID, Name: ds.Name, Description: ds.Description, Code: ds.Code,
+			Files: ds.Files, CacheTTL: ds.CacheTTL, EnvKeys: envKeys(ds.EnvData),
+		})
+		return nil
+	}
+	seenCustomSections := map[int64]bool{}
+	for _, section := range sections {
+		typ, _ := section["section_type"].(string)
+		cfg, err := sectionConfig(section)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+		}
+		switch s_registry.CanonicalType(typ) {
+		case "derived_section":
+			if err := addDataSource(configID(cfg["datasource_id"])); err != nil {
+				return nil, err
+			}
+		case "custom_section":
+			id := configID(cfg["custom_section_id"])
+			if id == 0 || seenCustomSections[id] {
+				continue
+			}
+			seenCustomSections[id] = true
+			if s.bundleCustomSections == nil {
+				return nil, fmt.Errorf("custom section export is not configured")
+			}
+			cs, err := s.bundleCustomSections.GetByID(id)
+			if err != nil {
+				return nil, fmt.Errorf("custom section %d: %w", id, err)
+			}
+			bundle.CustomSections = append(bundle.CustomSections, models.PageBundleCustomSection{
+				Ref: cs.ID, Name: cs.Name, Description: cs.Description,
+				DataSourceRef: cs.DataSourceID, SectionType: cs.SectionType, Config: cs.Config,
+			})
+			if err := addDataSource(cs.DataSourceID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	urls := uploadURLPattern.FindAllString(content+"\n"+p.SEOImage, -1)
+	seenAssets := map[string]bool{}
+	for _, url := range urls {
+		if seenAssets[url] || s.bundleAssets == nil {
+			continue
+		}
+		seenAssets[url] = true
+		if len(bundle.Assets) >= maxBundleAssets {
+			return nil, fmt.Errorf("%w: page references more than %d uploaded images", ErrInvalidBundle, maxBundleAssets)
+		}
+		data, contentType, err := s.bundleAssets.ReadImage(url)
+		if err != nil {
+			// Missing files and non-image uploads keep their URL in the
+			// content; the importing tenant simply will not have them.
+			log.Printf("page.ExportBundle(%d): skip asset %s: %v", pageID, url, err)
+			continue
+		}
+		bundle.Assets = append(bundle.Assets, models.PageBundleAsset{URL: url, ContentType: contentType, Data: data})
+	}
+	return bundle, nil
+}
+
+// bundleResolver answers Resolver lookups from the refs a bundle carries, so
+// a bundle is fully validated before anything is created from it.
+type bundleResolver struct {
+	dataSources    map[int64]bool
+	customSections map[int64]bool
+}
+
+func (r bundleResolver) DataSourceExists(id int64) (bool, error)    { return r.dataSources[id], nil }
+func (r bundleResolver) CustomSectionExists(id int64) (bool, error) { return r.customSections[id], nil }
+
+// ValidateBundle checks a bundle's envelope, that every section type is
+// registered, and that every reference resolves within the bundle.
+func ValidateBundle(bundle *models.PageBundle) error {
+	if bundle == nil || bundle.Format != PageBundleFormat {
+		return fmt.Errorf("%w: format must be %q", ErrInvalidBundle, PageBundleFormat)
+	}
+	if bundle.Version < 1 || bundle.Version > PageBundleVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidBundle, bundle.Version)
+	}
+	if len(bundle.DataSources) > maxBundleDataSources || len(bundle.CustomSections) > maxBundleCustomSections || len(bundle.Assets) > maxBundleAssets {
+		return fmt.Errorf("%w: too many bundled data sources, custom sections or assets", ErrInvalidBundle)
+	}
+	registered := map[string]bool{}
+	for _, definition := range s_registry.List() {
+		registered[definition.Type] = true
+	}
+	sections, err := decodePageSections(string(bundle.Content))
+	if err != nil {
+		return fmt.Errorf("%w: content must be a JSON array of sections", ErrInvalidBundle)
+	}
+	for i, section := range sections {
+		typ, _ := section["section_type"].(string)
+		if !registered[s_registry.CanonicalType(typ)] {
+			return fmt.Errorf("%w: section %d has unsupported section_type %q", ErrInvalidBundle, i, typ)
+		}
+	}
+
+	resolver := bundleResolver{dataSources: map[int64]bool{}, customSections: map[int64]bool{}}
+	for _, ds := range bundle.DataSources {
+		if ds.Ref <= 0 || resolver.dataSources[ds.Ref] || strings.TrimSpace(ds.Name) == "" {
+			return fmt.Errorf("%w: data source refs must be unique and named", ErrInvalidBundle)
+		}
+		resolver.dataSources[ds.Ref] = true
+	}
+	for _, cs := range bundle.CustomSections {
+		if cs.Ref <= 0 || resolver.customSections[cs.Ref] || strings.TrimSpace(cs.Name) == "" {
+			return fmt.Errorf("%w: custom section refs must be unique and named", ErrInvalidBundle)
+		}
+		if cs.DataSourceRef != 0 && !resolver.dataSources[cs.DataSourceRef] {
+			return fmt.Errorf("%w: custom section %q references a data source missing from the bundle", ErrInvalidBundle, cs.Name)
+		}
+		resolver.customSections[cs.Ref] = true
+	}
+	for _, asset := range bundle.Assets {
+		if uploadURLPattern.FindString(asset.URL) != asset.URL {
+			return fmt.Errorf("%w: asset url %q is not an upload path", ErrInvalidBundle, asset.URL)
+		}
+		if len(asset.Data) == 0 || len(asset.Data) > maxBundleAssetBytes {
+			return fmt.Errorf("%w: asset %q is empty or too large", ErrInvalidBundle, asset.URL)
+		}
+	}
+	if err := s_registry.ValidateContent(string(bundle.Content), resolver); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	return nil
+}
+
+// ImportBundle creates a private page from a bundle. The bundle, and the
+// page it will become, are validated up front; its data sources (with empty
+// environment values), custom sections and images are then recreated here,
+// section ids are re-issued and every reference in the content is re-mapped
+// before the page is saved. If any step fails, the objects already created
+// are removed again.
+func (s *Service) ImportBundle(bundle *models.PageBundle, slug, title string, actorID int64) (*models.Page, error) {
+	if err := ValidateBundle(bundle); err != nil {
+		return nil, err
+	}
+	slug = strings.TrimSpace(slug)
+	if slug == "" {
+		return nil, fmt.Errorf("%w: slug is required", ErrInvalidBundle)
+	}
+	if _, err := s.repo.GetBySlug(slug); err == nil {
+		return nil, ErrPageSlugTaken
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if (len(bundle.DataSources) > 0 && s.bundleDataSources == nil) ||
+		(len(bundle.CustomSections) > 0 && s.bundleCustomSections == nil) ||
+		(len(bundle.Assets) > 0 && s.bundleAssets == nil) {
+		return nil, fmt.Errorf("bundle import is not configured")
+	}
+	firstID := time.Now().UnixMilli()
+	if err := validateBundlePage(bundle, slug, title, firstID); err != nil {
+		return nil, err
+	}
+
+	var undo []func()
+	imported := false
+	defer func() {
+		if imported {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}()
+
+	dataSourceIDs := map[int64]int64{}
+	for _, bundled := range bundle.DataSources {
+		env := ""
+		for _, key := range bundled.EnvKeys {
+			if envKeyPattern.MatchString(key) {
+				env += key + "=\n"
+			}
+		}
+		ds := &models.DataSource{
+			Name: bundled.Name, Description: bundled.Description, Code: bundled.Code,
+			Files: bundled.Files, EnvData: env, CacheTTL: bundled.CacheTTL, CreatedBy: &actorID,
+		}
+		if err := s.bundleDataSources.Create(ds); err != nil {
+			return nil, fmt.Errorf("import data source %q: %w", bundled.Name, err)
+		}
+		dataSourceIDs[bundled.Ref] = ds.ID
+		undo = append(undo, func() {
+			if err := s.bundleDataSources.Delete(ds.ID, actorID); err != nil {
+				log.Printf("page.ImportBundle: remove data source %d: %v", ds.ID, err)
+			}
+		})
+	}
+	customSectionIDs := map[int64]int64{}
+	for _, bundled := range bundle.CustomSections {
+		cs := &models.CustomSection{
+			Name: bundled.Name, Description: bundled.Description, DataSourceID: dataSourceIDs[bundled.DataSourceRef],
+			SectionType: bundled.SectionType, Config: bundled.Config, CreatedBy: &actorID,
+		}
+		if err := s.bundleCustomSections.Create(actorID, cs); err != nil {
+			return nil, fmt.Errorf("import custom section %q: %w", bundled.Name, err)
+		}
+		customSectionIDs[bundled.Ref] = cs.ID
+		undo = append(undo, func() {
+			if err := s.bundleCustomSections.Delete(actorID, cs.ID); err != nil {
+				log.Printf("page.ImportBundle: remove custom section %d: %v", cs.ID, err)
+			}
+		})
+	}
+	assetURLs := map[string]string{}
+	for _, asset := range bundle.Assets {
+		url, err := s.bundleAssets.SaveImage(actorID, asset.Data)
+		if err != nil {
+			return nil, fmt.Errorf("import asset %s: %w", asset.URL, err)
+		}
+		assetURLs[asset.URL] = url
+		undo = append(undo, func() { s.bundleAssets.DeleteImage(url) })
+	}
+
+	content, err := remapBundleContent(string(bundle.Content), firstID, dataSourceIDs, customSectionIDs, assetURLs)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	p := bundlePage(bundle, slug, title, content, assetURLs)
+	if err := s.Create(p, actorID); err != nil {
+		return nil, err
+	}
+	imported = true
+	return p, nil
+}
+
+// validateBundlePage checks the page a bundle imports as before anything is
+// created, with the bundle's refs standing in for the ids of their copies.
+func validateBundlePage(bundle *models.PageBundle, slug, title string, firstID int64) error {
+	dataSourceIDs, customSectionIDs := map[int64]int64{}, map[int64]int64{}
+	resolver := bundleResolver{dataSources: map[int64]bool{}, customSections: map[int64]bool{}}
+	for _, ds := range bundle.DataSources {
+		dataSourceIDs[ds.Ref], resolver.dataSources[ds.Ref] = ds.Ref, true
+	}
+	for _, cs := range bundle.CustomSections {
+		customSectionIDs[cs.Ref], resolver.customSections[cs.Ref] = cs.Ref, true
+	}
+	content, err := remapBundleContent(string(bundle.Content), firstID, dataSourceIDs, customSectionIDs, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	p := bundlePage(bundle, slug, title, content, nil)
+	if err := normalizePageSEO(p); err != nil {
+		return err
+	}
+	if err := s_registry.ValidateContent(p.Content, resolver); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+	return nil
+}
+
+// bundlePage is the private page ImportBundle saves for bundle.
+func bundlePage(bundle *models.PageBundle, slug, title, content string, assetURLs map[string]string) *models.Page {
+	if strings.TrimSpace(title) == "" {
+		title = bundle.Title
+	}
+	seoImage := bundle.SEOImage
+	if url, ok := assetURLs[seoImage]; ok {
+		seoImage = url
+	}
+	return &models.Page{
+		Slug: slug, Title: title, Description: bundle.Description,
+		SEOTitle: bundle.SEOTitle, SEODesc: bundle.SEODesc, SEOImage: seoImage,
+		Content: content, Visibility: "private", Status: PageStatusPublished,
+	}
+}
+
+// remapBundleContent issues fresh section ids starting at firstID and points
+// data source, custom section and image references at their imported copies.
+func remapBundleContent(content string, firstID int64, dataSourceIDs, customSectionIDs map[int64]int64, assetURLs map[string]string) (string, error) {
+	sections, err := decodePageSections(ClearInteractiveRecords(content))
+	if err != nil {
+		return "", err
+	}
+	for i, section := range sections {
+		id := firstID + int64(i)
+		section["id"] = id
+		if items, ok := section["items"].([]interface{}); ok {
+			for _, raw := range items {
+				if item, ok := raw.(map[string]interface{}); ok {
+					item["section_id"] = id
+				}
+			}
+		}
+		typ, _ := section["section_type"].(string)
+		var key string
+		var ids map[int64]int64
+		switch s_registry.CanonicalType(typ) {
+		case "derived_section":
+			key, ids = "datasource_id", dataSourceIDs
+		case "custom_section":
+			key, ids = "custom_section_id", customSectionIDs
+		default:
+			continue
+		}
+		cfg, err := sectionConfig(section)
+		if err != nil {
+			return "", err
+		}
+		if ref := configID(cfg[key]); ref > 0 {
+			cfg[key] = ids[ref]
+			if err := setSectionConfig(section, cfg); err != nil {
+				return "", err
+			}
+		}
+	}
+	raw, err := json.Marshal(sections)
+	if err != nil {
+		return "", err
+	}
+	return uploadURLPattern.ReplaceAllStringFunc(string(raw), func(url string) string {
+		if next, ok := assetURLs[url]; ok {
+			return next
+		}
+		return url
+	}), nil
+}
+
+// bundleSectionTypes lists the distinct section types of a bundle, sorted.
+func bundleSectionTypes(bundle *models.PageBundle) []string {
+	types := []string{}
+	sections, err := decodePageSections(string(bundle.Content))
+	if err != nil {
+		return types
+	}
+	seen := map[string]bool{}
+	for _, section := range sections {
+		if typ, _ := section["section_type"].(string); typ != "" && !seen[typ] {
+			seen[typ] = true
+			types = append(types, typ)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,290 @@
+package page
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/skaia/backend/models"
+)
+
+type bundleRepo struct {
+	*memoryInteractiveRepository
+	templates []*models.PageTemplate
+	createErr error
+}
+
+func (r *bundleRepo) Create(page *models.Page, authorID int64) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	return r.memoryInteractiveRepository.Create(page, authorID)
+}
+
+func (r *bundleRepo) GetBySlug(slug string) (*models.Page, error) {
+	if r.page.Slug == slug {
+		p := r.page
+		return &p, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *bundleRepo) CreateTemplate(t *models.PageTemplate) error {
+	for _, existing := range r.templates {
+		if strings.EqualFold(existing.Name, t.Name) {
+			return &pq.Error{Code: "23505"}
+		}
+	}
+	t.ID = int64(len(r.templates) + 1)
+	r.templates = append(r.templates, t)
+	return nil
+}
+
+func (r *bundleRepo) GetTemplate(id int64) (*models.PageTemplate, error) {
+	for _, t := range r.templates {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+type fakeBundleDataSources struct {
+	byID    map[int64]*models.DataSource
+	created []*models.DataSource
+	deleted []int64
+}
+
+func (f *fakeBundleDataSources) GetByID(id int64) (*models.DataSource, error) {
+	if ds, ok := f.byID[id]; ok {
+		return ds, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeBundleDataSources) Create(ds *models.DataSource) error {
+	ds.ID = int64(100 + len(f.created))
+	f.created = append(f.created, ds)
+	return nil
+}
+
+func (f *fakeBundleDataSources) Delete(id, _ int64) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+type fakeBundleCustomSections struct {
+	byID    map[int64]*models.CustomSection
+	created []*models.CustomSection
+	deleted []int64
+}
+
+func (f *fakeBundleCustomSections) GetByID(id int64) (*models.CustomSection, error) {
+	if cs, ok := f.byID[id]; ok {
+		return cs, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeBundleCustomSections) Create(_ int64, cs *models.CustomSection) error {
+	cs.ID = int64(200 + len(f.created))
+	f.created = append(f.created, cs)
+	return nil
+}
+
+func (f *fakeBundleCustomSections) Delete(_, id int64) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+type fakeBundleAssets struct {
+	files map[string][]byte
+	saved int
+}
+
+func (f *fakeBundleAssets) ReadImage(url string) ([]byte, string, error) {
+	if data, ok := f.files[url]; ok {
+		return data, "image/png", nil
+	}
+	return nil, "", errors.New("not found")
+}
+
+func (f *fakeBundleAssets) SaveImage(userID int64, data []byte) (string, error) {
+	f.saved++
+	url := fmt.Sprintf("/uploads/%d/images/imported-%d.png", userID, f.saved)
+	f.files[url] = data
+	return url, nil
+}
+
+func (f *fakeBundleAssets) DeleteImage(url string) {
+	delete(f.files, url)
+}
+
+type bundleFixture struct {
+	svc            *Service
+	repo           *bundleRepo
+	dataSources    *fakeBundleDataSources
+	customSections *fakeBundleCustomSections
+	assets         *fakeBundleAssets
+}
+
+func newBundleFixture() *bundleFixture {
+	content := `[
+		{"id":1700000000001,"display_order":1,"section_type":"derived_section","heading":"Stats","config":"{\"datasource_id\":3}"},
+		{"id":1700000000002,"display_order":2,"section_type":"custom_section","heading":"Feed","config":"{\"custom_section_id\":4}"},
+		{"id":1700000000003,"display_order":3,"section_type":"hero","heading":"Welcome","config":"{\"image\":\"/uploads/1/images/hero.png\"}"}
+	]`
+	f := &bundleFixture{
+		repo: &bundleRepo{memoryInteractiveRepository: &memoryInteractiveRepository{page: models.Page{
+			ID: 1, Slug: "landing", Title: "Landing", Content: content, Visibility: "public",
+			SEOImage: "/uploads/1/images/hero.png",
+		}}},
+		dataSources: &fakeBundleDataSources{byID: map[int64]*models.DataSource{
+			3: {ID: 3, Name: "Stats", Code: "return 1", EnvData: "API_KEY=secret-value\n# comment\nREGION=eu"},
+			5: {ID: 5, Name: "Feed source", Code: "return []"},
+		}},
+		customSections: &fakeBundleCustomSections{byID: map[int64]*models.CustomSection{
+			4: {ID: 4, Name: "Feed", DataSourceID: 5, SectionType: "list", Config: "{}"},
+		}},
+		assets: &fakeBundleAssets{files: map[string][]byte{"/uploads/1/images/hero.png": []byte("png-bytes")}},
+	}
+	f.svc = NewService(f.repo, nil, WithBundleStores(f.dataSources, f.customSections, f.assets))
+	return f
+}
+
+func TestExportBundleCarriesReferencesWithoutSecrets(t *testing.T) {
+	f := newBundleFixture()
+	bundle, err := f.svc.ExportBundle(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.DataSources) != 2 || len(bundle.CustomSections) != 1 || len(bundle.Assets) != 1 {
+		t.Fatalf("unexpected bundle contents: %#v", bundle)
+	}
+	if keys := strings.Join(bundle.DataSources[0].EnvKeys, ","); keys != "API_KEY,REGION" {
+		t.Fatalf("unexpected env keys: %q", keys)
+	}
+	raw, _ := json.Marshal(bundle)
+	if strings.Contains(string(raw), "secret-value") {
+		t.Fatal("bundle leaked a data source environment value")
+	}
+	if err := ValidateBundle(bundle); err != nil {
+		t.Fatalf("exported bundle does not validate: %v", err)
+	}
+}
+
+func TestImportBundleRemapsSectionsAndReferences(t *testing.T) {
+	f := newBundleFixture()
+	bundle, err := f.svc.ExportBundle(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.ImportBundle(bundle, "landing", "", 9); !errors.Is(err, ErrPageSlugTaken) {
+		t.Fatalf("taken slug accepted: %v", err)
+	}
+	p, err := f.svc.ImportBundle(bundle, "landing-copy", "", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Visibility != "private" || p.Title != "Landing" || p.SEOImage != "/uploads/9/images/imported-1.png" {
+		t.Fatalf("unexpected imported page: %#v", p)
+	}
+	if env := f.dataSources.created[0].EnvData; env != "API_KEY=\nREGION=\n" {
+		t.Fatalf("env values were not blanked: %q", env)
+	}
+	if f.customSections.created[0].DataSourceID != 101 {
+		t.Fatalf("custom section kept its source data source: %d", f.customSections.created[0].DataSourceID)
+	}
+
+	sections, err := decodePageSections(p.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{`"datasource_id":100`, `"custom_section_id":200`, `/uploads/9/images/imported-1.png`} {
+		if id := configID(sections[i]["id"]); id == 0 || id == 1700000000001+int64(i) {
+			t.Fatalf("section %d kept its source id: %v", i, sections[i]["id"])
+		}
+		if cfg, _ := sections[i]["config"].(string); !strings.Contains(cfg, want) {
+			t.Fatalf("section %d config %q does not contain %s", i, cfg, want)
+		}
+	}
+}
+
+func TestImportBundleLeavesNothingBehindOnFailure(t *testing.T) {
+	f := newBundleFixture()
+	bundle, err := f.svc.ExportBundle(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalidSEO := *bundle
+	invalidSEO.SEOTitle = strings.Repeat("x", 256)
+	if _, err := f.svc.ImportBundle(&invalidSEO, "landing-copy", "", 9); !errors.Is(err, ErrInvalidSEO) {
+		t.Fatalf("invalid SEO accepted: %v", err)
+	}
+	if len(f.dataSources.created) != 0 || len(f.customSections.created) != 0 || f.assets.saved != 0 {
+		t.Fatal("a bundle with invalid SEO created objects before it was rejected")
+	}
+
+	f.repo.createErr = ErrPageSlugTaken
+	if _, err := f.svc.ImportBundle(bundle, "landing-copy", "", 9); !errors.Is(err, ErrPageSlugTaken) {
+		t.Fatalf("slug race not reported: %v", err)
+	}
+	if len(f.dataSources.deleted) != 2 || len(f.customSections.deleted) != 1 || len(f.assets.files) != 1 {
+		t.Fatalf("imported objects left behind: data sources %v, custom sections %v, files %d",
+			f.dataSources.deleted, f.customSections.deleted, len(f.assets.files))
+	}
+}
+
+func TestValidateBundleRejectsUnknownTypesAndMissingRefs(t *testing.T) {
+	base := func(content string) *models.PageBundle {
+		return &models.PageBundle{Format: PageBundleFormat, Version: PageBundleVersion, Content: json.RawMessage(content)}
+	}
+	cases := map[string]*models.PageBundle{
+		"format":       {Format: "other", Version: 1, Content: json.RawMessage(`[]`)},
+		"version":      {Format: PageBundleFormat, Version: PageBundleVersion + 1, Content: json.RawMessage(`[]`)},
+		"section type": base(`[{"id":1,"section_type":"marquee","config":"{}"}]`),
+		"data source":  base(`[{"id":1,"section_type":"derived_section","config":"{\"datasource_id\":3}"}]`),
+		"asset url": func() *models.PageBundle {
+			b := base(`[]`)
+			b.Assets = []models.PageBundleAsset{{URL: "https://example.com/x.png", Data: []byte("x")}}
+			return b
+		}(),
+	}
+	for name, bundle := range cases {
+		if err := ValidateBundle(bundle); !errors.Is(err, ErrInvalidBundle) {
+			t.Errorf("%s: expected ErrInvalidBundle, got %v", name, err)
+		}
+	}
+}
+
+func TestTemplateGallerySaveAndInstantiate(t *testing.T) {
+	f := newBundleFixture()
+	tpl, err := f.svc.SaveTemplateFromPage(1, TemplateInput{Name: " Landing ", Category: "Marketing"}, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.Name != "Landing" || tpl.Category != "marketing" || strings.Join(tpl.SectionTypes, ",") != "custom_section,derived_section,hero" {
+		t.Fatalf("unexpected template: %#v", tpl)
+	}
+	if _, err := f.svc.SaveTemplateFromPage(1, TemplateInput{Name: "landing"}, 9); !errors.Is(err, ErrTemplateNameTaken) {
+		t.Fatalf("duplicate name accepted: %v", err)
+	}
+	if _, err := f.svc.SaveTemplate(TemplateInput{}, tpl.Bundle, 9); !errors.Is(err, ErrInvalidTemplate) {
+		t.Fatalf("unnamed template accepted: %v", err)
+	}
+	if _, err := f.svc.InstantiateTemplate(42, "new", "", 9); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("missing template instantiated: %v", err)
+	}
+	p, err := f.svc.InstantiateTemplate(tpl.ID, "from-template", "From template", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Slug != "from-template" || p.Title != "From template" || len(f.dataSources.created) != 2 {
+		t.Fatalf("unexpected instantiated page: %#v", p)
+	}
+}
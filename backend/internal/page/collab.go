@@ -0,0 +1,41 @@
+package page
+
+import "github.com/skaia/backend/models"
+
+// CollaborativeContent returns the builder document a collaborative editing
+// session starts from. Participant records stay in the database; the session
+// only ever sees section definitions.
+func (s *Service) CollaborativeContent(pageID int64) (string, error) {
+	p, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return "", err
+	}
+	content := ClearInteractiveRecords(p.Content)
+	if content == "" {
+		content = "[]"
+	}
+	return content, nil
+}
+
+// ValidateCollaborativeContent applies the builder save rules to a session
+// document before an op is accepted, so a session never holds content its
+// next save would reject.
+func (s *Service) ValidateCollaborativeContent(content string) error {
+	return s.validateContent(content)
+}
+
+// SaveCollaborative writes a session document to the live page. It is an
+// ordinary content update: other page fields are kept and a revision is
+// recorded for actorID.
+func (s *Service) SaveCollaborative(pageID, actorID int64, content string) (*models.Page, error) {
+	current, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	p := *current
+	p.Content = content
+	if err := s.Update(&p, actorID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(pageID)
+}
@@ -0,0 +1,39 @@
+package page
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skaia/backend/models"
+)
+
+func TestCollaborativeSessionNeverSeesOrDropsLiveRecords(t *testing.T) {
+	design := func(label string) string {
+		return interactiveContent(`{
+			"status":"open","result_visibility":"never","response_limit":1,
+			"fields":[{"key":"choice","type":"radio","label":"` + label + `","options":[{"key":"a","label":"A"}]}],
+			"records":[]
+		}`)
+	}
+	repo := &memoryInteractiveRepository{page: models.Page{ID: 1, Slug: "page", Title: "Live", Content: design("Before"), Visibility: "public"}}
+	svc := NewService(repo, nil)
+	if _, err := svc.SubmitInteractive(1, 7, 5, "Voter", "key", map[string]interface{}{"choice": "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := svc.CollaborativeContent(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(content, "user_id") {
+		t.Fatalf("session content carries participant records: %s", content)
+	}
+	saved, err := svc.SaveCollaborative(1, 3, strings.Replace(content, "Before", "After", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.SanitizeInteractivePage(saved, 5, false)
+	if saved.Title != "Live" || !strings.Contains(saved.Content, "After") || !strings.Contains(saved.Content, `\"user_id\":5`) {
+		t.Fatalf("collaborative save lost fields or live records: %#v", saved)
+	}
+}
@@ -200,7 +200,7 @@ func TestDuplicatePreservesFixtureDocumentExceptInteractiveRuntimeData(t *testin
 	repo := &memoryInteractiveRepository{page: models.Page{
 		ID: 55, Slug: "source", Title: "Source", Description: "Fixture", Visibility: "public", Content: string(content),
 	}}
-	duplicate, err := NewService(repo, nil).Duplicate(55, "copy", "")
+	duplicate, err := NewService(repo, nil).Duplicate(55, 0, "copy", "")
 	if err != nil {
 		t.Fatal(err)
 	}
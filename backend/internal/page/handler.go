@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -86,7 +88,27 @@ func (h *Handler) Mount(r chi.Router, jwt func(http.Handler) http.Handler, comme
 			r.Put("/{id}/seo", h.updatePageSEO)
 			r.Delete("/{id}", h.deletePage)
 			r.Post("/{id}/duplicate", h.duplicatePage)
+			r.Get("/{id}/bundle", h.exportBundle)
+			r.Post("/import", h.importBundle)
+			r.Get("/templates", h.listTemplates)
+			r.Post("/templates", h.createTemplate)
+			r.Get("/templates/{templateId}", h.getTemplate)
+			r.Delete("/templates/{templateId}", h.deleteTemplate)
+			r.Post("/templates/{templateId}/instantiate", h.instantiateTemplate)
+			r.Get("/{id}/revisions", h.listRevisions)
+			r.Get("/{id}/revisions/diff", h.diffRevisions)
+			r.Get("/{id}/revisions/{revisionId}", h.getRevision)
+			r.Post("/{id}/revisions/{revisionId}/restore", h.restoreRevision)
+			r.Get("/{id}/draft", h.getDraft)
+			r.Put("/{id}/draft", h.saveDraft)
+			r.Delete("/{id}/draft", h.discardDraft)
+			r.Post("/{id}/publish", h.publishPage)
+			r.Post("/{id}/unpublish", h.unpublishPage)
+			r.Put("/{id}/schedule", h.schedulePage)
 			r.Post("/{id}/sections/{sectionId}/responses", h.submitInteractiveResponse)
+			r.Get("/{id}/sections/{sectionId}/responses", h.listInteractiveResponses)
+			r.Get("/{id}/sections/{sectionId}/responses/export", h.exportInteractiveResponses)
+			r.Get("/{id}/sections/{sectionId}/responses/report", h.reportInteractiveResponses)
 			r.Patch("/{id}/sections/{sectionId}/responses/{recordId}", h.patchInteractiveResponse)
 			r.Delete("/{id}/sections/{sectionId}/responses/{recordId}", h.deleteInteractiveResponse)
 
@@ -257,6 +279,10 @@ func (h *Handler) getIndex(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusNotFound, "no landing page configured")
 		return
 	}
+	if IsDraft(p) && !h.canEditPage(r, p.ID) {
+		utils.WriteError(w, http.StatusNotFound, "no landing page configured")
+		return
+	}
 	h.svc.EnrichPage(p)
 	uid, _ := utils.UserIDFromCtx(r)
 	h.svc.EnrichPageEngagement(p, uidPtr(uid))
@@ -277,7 +303,14 @@ func (h *Handler) sanitizeInteractivePage(r *http.Request, p *models.Page) {
 		return
 	}
 	uid, _ := utils.UserIDFromCtx(r)
-	h.svc.SanitizeInteractivePage(p, uid, h.canEditPage(r, p.ID))
+	canEdit := h.canEditPage(r, p.ID)
+	h.svc.SanitizeInteractivePage(p, uid, canEdit)
+	if !canEdit {
+		// Draft and schedule state is editorial; readers only see the live page.
+		p.HasDraft = false
+		p.PublishAt = nil
+		p.UnpublishAt = nil
+	}
 }
 
 func (h *Handler) getBySlug(w http.ResponseWriter, r *http.Request) {
@@ -297,6 +330,10 @@ func (h *Handler) getBySlug(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusForbidden, "this page is private")
 		return
 	}
+	if IsDraft(p) && !h.canEditPage(r, p.ID) {
+		utils.WriteError(w, http.StatusNotFound, "page not found")
+		return
+	}
 
 	h.svc.EnrichPage(p)
 	uid, _ := utils.UserIDFromCtx(r)
@@ -320,8 +357,9 @@ func (h *Handler) createPage(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, "slug is required")
 		return
 	}
-	if err := h.svc.Create(&p); err != nil {
-		if errors.Is(err, ErrInvalidContent) || errors.Is(err, ErrInvalidSEO) {
+	userID, _ := utils.UserIDFromCtx(r)
+	if err := h.svc.Create(&p, userID); err != nil {
+		if errors.Is(err, ErrInvalidContent) || errors.Is(err, ErrInvalidSEO) || errors.Is(err, ErrInvalidStatus) {
 			utils.WriteError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -342,7 +380,6 @@ func (h *Handler) createPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	utils.WriteJSON(w, http.StatusCreated, p)
-	userID, _ := utils.UserIDFromCtx(r)
 	h.dispatcher.Dispatch(ievents.Job{
 		UserID:     userID,
 		Activity:   ievents.ActPageCreated,
@@ -375,7 +412,8 @@ func (h *Handler) duplicatePage(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, "slug is required")
 		return
 	}
-	dup, err := h.svc.Duplicate(id, body.Slug, body.Title)
+	userID, _ := utils.UserIDFromCtx(r)
+	dup, err := h.svc.Duplicate(id, userID, body.Slug, body.Title)
 	if err != nil {
 		log.Printf("page.duplicatePage: %v", err)
 		utils.WriteError(w, http.StatusInternalServerError, "duplicate failed")
@@ -383,7 +421,6 @@ func (h *Handler) duplicatePage(w http.ResponseWriter, r *http.Request) {
 	}
 	h.svc.EnrichPage(dup)
 	utils.WriteJSON(w, http.StatusCreated, dup)
-	userID, _ := utils.UserIDFromCtx(r)
 	h.dispatcher.Dispatch(ievents.Job{
 		UserID:     userID,
 		Activity:   ievents.ActPageCreated,
@@ -426,7 +463,8 @@ func (h *Handler) updatePage(w http.ResponseWriter, r *http.Request) {
 	p.SEOTitle = current.SEOTitle
 	p.SEODesc = current.SEODesc
 	p.SEOImage = current.SEOImage
-	if err := h.svc.Update(&p); err != nil {
+	userID, _ := utils.UserIDFromCtx(r)
+	if err := h.svc.Update(&p, userID); err != nil {
 		if errors.Is(err, ErrInvalidContent) || errors.Is(err, ErrInvalidSEO) {
 			utils.WriteError(w, http.StatusBadRequest, err.Error())
 			return
@@ -435,8 +473,8 @@ func (h *Handler) updatePage(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusInternalServerError, "update failed")
 		return
 	}
+	h.hub.ReloadPageCollab(id)
 	updated, _ := h.svc.GetByID(id)
-	userID, _ := utils.UserIDFromCtx(r)
 	if updated != nil {
 		h.svc.EnrichPage(updated)
 		h.sanitizeInteractivePage(r, updated)
@@ -493,7 +531,7 @@ func (h *Handler) updatePageSEO(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	updated, err := h.svc.UpdateSEO(id, body.Title, body.Description, body.Image)
+	updated, err := h.svc.UpdateSEO(id, uid, body.Title, body.Description, body.Image)
 	if err != nil {
 		if errors.Is(err, ErrInvalidSEO) {
 			utils.WriteError(w, http.StatusBadRequest, err.Error())
@@ -509,6 +547,291 @@ func (h *Handler) updatePageSEO(w http.ResponseWriter, r *http.Request) {
 	h.hub.BroadcastPageExceptUser(uid, "page_updated", pageUpdatePatch(updated))
 }
 
+// revisions
+func (h *Handler) editablePageTarget(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	if _, err := h.svc.GetByID(id); err != nil {
+		utils.WriteError(w, http.StatusNotFound, "page not found")
+		return 0, false
+	}
+	if !h.canEditPage(r, id) {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeRevisionError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, ErrRevisionNotFound):
+		utils.WriteError(w, http.StatusNotFound, "revision not found")
+	case errors.Is(err, ErrInvalidContent) || errors.Is(err, ErrInvalidSEO):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("page.%s: %v", op, err)
+		utils.WriteError(w, http.StatusInternalServerError, "failed")
+	}
+}
+
+func (h *Handler) listRevisions(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	revisions, err := h.svc.ListRevisions(id, limit, offset)
+	if err != nil {
+		writeRevisionError(w, "listRevisions", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, revisions)
+}
+
+func (h *Handler) getRevision(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	revisionID, err := parseID(r, "revisionId")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid revision id")
+		return
+	}
+	rev, err := h.svc.GetRevision(id, revisionID)
+	if err != nil {
+		writeRevisionError(w, "getRevision", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, rev)
+}
+
+func (h *Handler) diffRevisions(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	from, fromErr := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+	to, toErr := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+	if fromErr != nil || toErr != nil {
+		utils.WriteError(w, http.StatusBadRequest, "from and to revision ids are required")
+		return
+	}
+	diff, err := h.svc.DiffRevisions(id, from, to)
+	if err != nil {
+		writeRevisionError(w, "diffRevisions", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, diff)
+}
+
+func (h *Handler) restoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	revisionID, err := parseID(r, "revisionId")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid revision id")
+		return
+	}
+	userID, _ := utils.UserIDFromCtx(r)
+	restored, err := h.svc.RestoreRevision(id, revisionID, userID)
+	if err != nil {
+		writeRevisionError(w, "restoreRevision", err)
+		return
+	}
+	h.hub.ReloadPageCollab(id)
+	h.svc.EnrichPage(restored)
+	h.sanitizeInteractivePage(r, restored)
+	utils.WriteJSON(w, http.StatusOK, restored)
+	h.dispatcher.Dispatch(ievents.Job{
+		UserID:     userID,
+		Activity:   ievents.ActPageUpdated,
+		Resource:   ievents.ResPage,
+		ResourceID: id,
+		IP:         ievents.ClientIP(r),
+		Meta:       map[string]interface{}{"action": "restore_revision", "revision_id": revisionID},
+		Fn: func() {
+			h.hub.BroadcastPageExceptUser(userID, "page_updated", pageUpdatePatch(restored))
+		},
+	})
+}
+
+// drafts & publishing
+func writePublishingError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, ErrDraftNotFound):
+		utils.WriteError(w, http.StatusNotFound, "draft not found")
+	case errors.Is(err, ErrInvalidContent) || errors.Is(err, ErrInvalidSchedule):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("page.%s: %v", op, err)
+		utils.WriteError(w, http.StatusInternalServerError, "failed")
+	}
+}
+
+func (h *Handler) getDraft(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	draft, err := h.svc.GetDraft(id)
+	if err != nil {
+		writePublishingError(w, "getDraft", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, draft)
+}
+
+func (h *Handler) saveDraft(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	var body DraftInput
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	userID, _ := utils.UserIDFromCtx(r)
+	draft, err := h.svc.SaveDraft(id, userID, body)
+	if err != nil {
+		writePublishingError(w, "saveDraft", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, draft)
+}
+
+func (h *Handler) discardDraft(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DiscardDraft(id); err != nil {
+		writePublishingError(w, "discardDraft", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "discarded"})
+}
+
+func (h *Handler) publishPage(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := utils.UserIDFromCtx(r)
+	p, err := h.svc.Publish(id, userID)
+	if err != nil {
+		writePublishingError(w, "publishPage", err)
+		return
+	}
+	h.hub.ReloadPageCollab(id)
+	h.writePublishingChange(w, r, p, "publish")
+}
+
+func (h *Handler) unpublishPage(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	p, err := h.svc.Unpublish(id)
+	if err != nil {
+		writePublishingError(w, "unpublishPage", err)
+		return
+	}
+	h.writePublishingChange(w, r, p, "unpublish")
+}
+
+func (h *Handler) schedulePage(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.editablePageTarget(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		PublishAt   *time.Time `json:"publish_at"`
+		UnpublishAt *time.Time `json:"unpublish_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	p, err := h.svc.SetSchedule(id, body.PublishAt, body.UnpublishAt)
+	if err != nil {
+		writePublishingError(w, "schedulePage", err)
+		return
+	}
+	h.writePublishingChange(w, r, p, "schedule")
+}
+
+func (h *Handler) writePublishingChange(w http.ResponseWriter, r *http.Request, p *models.Page, action string) {
+	h.svc.EnrichPage(p)
+	h.sanitizeInteractivePage(r, p)
+	utils.WriteJSON(w, http.StatusOK, p)
+	userID, _ := utils.UserIDFromCtx(r)
+	h.dispatcher.Dispatch(ievents.Job{
+		UserID:     userID,
+		Activity:   ievents.ActPageUpdated,
+		Resource:   ievents.ResPage,
+		ResourceID: p.ID,
+		IP:         ievents.ClientIP(r),
+		Meta:       map[string]interface{}{"action": action, "status": p.Status},
+		Fn: func() {
+			h.hub.BroadcastPageExceptUser(userID, "page_updated", pageUpdatePatch(p))
+		},
+	})
+}
+
+// CollabBackend adapts the page service for collaborative editing sessions
+// on the websocket hub. Owners, listed editors and home.manage holders may
+// join; session saves are broadcast like builder saves.
+func (h *Handler) CollabBackend() ws.PageCollabBackend {
+	return ws.PageCollabBackend{
+		CanEdit: func(pageID, userID int64) error {
+			if userID > 0 && h.svc.CanEdit(pageID, userID, h.hasPermission(userID, "home.manage")) {
+				return nil
+			}
+			return ws.ErrCollabDenied
+		},
+		Load:     h.svc.CollaborativeContent,
+		Validate: h.svc.ValidateCollaborativeContent,
+		Save: func(pageID, userID int64, content string) error {
+			p, err := h.svc.SaveCollaborative(pageID, userID, content)
+			if err != nil {
+				return err
+			}
+			h.dispatcher.Dispatch(ievents.Job{
+				UserID:     userID,
+				Activity:   ievents.ActPageUpdated,
+				Resource:   ievents.ResPage,
+				ResourceID: pageID,
+				Meta:       map[string]interface{}{"action": "collaborative_save"},
+				Fn: func() {
+					h.hub.BroadcastPageExceptUser(userID, "page_updated", pageUpdatePatch(p))
+				},
+			})
+			return nil
+		},
+	}
+}
+
+// RunScheduler applies due publish and unpublish times every interval and
+// pushes each changed page to connected clients as a page:update.
+func (h *Handler) RunScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, p := range h.svc.ApplyDueSchedules(time.Now()) {
+			h.hub.ReloadPageCollab(p.ID)
+			h.hub.BroadcastPage("page_updated", pageUpdatePatch(p))
+		}
+	}
+}
+
 func (h *Handler) interactiveTarget(w http.ResponseWriter, r *http.Request) (int64, int64, int64, bool) {
 	pageID, err := parseID(r, "id")
 	if err != nil {
@@ -530,10 +853,15 @@ func (h *Handler) interactiveTarget(w http.ResponseWriter, r *http.Request) (int
 		utils.WriteError(w, http.StatusNotFound, "page not found")
 		return 0, 0, 0, false
 	}
-	if p.Visibility == "private" && !h.canEditPage(r, pageID) {
+	canEdit := h.canEditPage(r, pageID)
+	if p.Visibility == "private" && !canEdit {
 		utils.WriteError(w, http.StatusForbidden, "this page is private")
 		return 0, 0, 0, false
 	}
+	if IsDraft(p) && !canEdit {
+		utils.WriteError(w, http.StatusNotFound, "page not found")
+		return 0, 0, 0, false
+	}
 	return pageID, sectionID, uid, true
 }
 
@@ -605,9 +933,7 @@ func (h *Handler) submitInteractiveResponse(w http.ResponseWriter, r *http.Reque
 		writeInteractiveError(w, err)
 		return
 	}
-	safeRecord := *record
-	safeRecord.IdempotencyKey = ""
-	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"record": &safeRecord, "config": config})
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"record": record, "config": config})
 	h.dispatchInteractiveMutation(r, uid, pageID, sectionID, record.ID, ievents.ActPageResponseSubmitted, "submit_response")
 }
 
@@ -658,6 +984,247 @@ func (h *Handler) patchInteractiveResponse(w http.ResponseWriter, r *http.Reques
 	h.dispatchInteractiveMutation(r, uid, pageID, sectionID, recordID, ievents.ActPageResponseModerated, "moderate_response")
 }
 
+func (h *Handler) listInteractiveResponses(w http.ResponseWriter, r *http.Request) {
+	pageID, sectionID, uid, ok := h.interactiveTarget(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	list, err := h.svc.ListInteractiveResponses(pageID, sectionID, uid, limit, offset)
+	if err != nil {
+		writeInteractiveError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, list)
+}
+
+func (h *Handler) exportInteractiveResponses(w http.ResponseWriter, r *http.Request) {
+	pageID, sectionID, uid, ok := h.interactiveTarget(w, r)
+	if !ok {
+		return
+	}
+	export, err := h.svc.ExportInteractiveResponses(pageID, sectionID, uid, r.URL.Query().Get("format"))
+	if err != nil {
+		writeInteractiveError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.Filename}))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(export.Data)
+}
+
+func (h *Handler) reportInteractiveResponses(w http.ResponseWriter, r *http.Request) {
+	pageID, sectionID, uid, ok := h.interactiveTarget(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.svc.ReportInteractiveResponses(pageID, sectionID, uid)
+	if err != nil {
+		writeInteractiveError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, report)
+}
+
+// bundles & templates
+// maxBundleRequestBytes bounds an uploaded bundle: base64 inflates the
+// bundled images by a third.
+const maxBundleRequestBytes = 4 * maxBundleAssets * maxBundleAssetBytes / 3
+
+func writeBundleError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, ErrTemplateNotFound), errors.Is(err, sql.ErrNoRows):
+		utils.WriteError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, ErrPageSlugTaken), errors.Is(err, ErrTemplateNameTaken):
+		utils.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidBundle), errors.Is(err, ErrInvalidTemplate),
+		errors.Is(err, ErrInvalidContent), errors.Is(err, ErrInvalidSEO):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("page.%s: %v", op, err)
+		utils.WriteError(w, http.StatusInternalServerError, "failed")
+	}
+}
+
+// dispatchImportedPage records and broadcasts a page created from a bundle.
+func (h *Handler) dispatchImportedPage(r *http.Request, userID int64, p *models.Page, meta map[string]interface{}) {
+	meta["slug"] = p.Slug
+	h.dispatcher.Dispatch(ievents.Job{
+		UserID:     userID,
+		Activity:   ievents.ActPageCreated,
+		Resource:   ievents.ResPage,
+		ResourceID: p.ID,
+		IP:         ievents.ClientIP(r),
+		Meta:       meta,
+		Fn: func() {
+			h.hub.BroadcastPage("page_created", p)
+		},
+	})
+}
+
+func (h *Handler) exportBundle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	// Admins, owners, and editors may export, as they may duplicate.
+	if !h.requireHomeManage(r) && !h.canEditPage(r, id) {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	bundle, err := h.svc.ExportBundle(id)
+	if err != nil {
+		writeBundleError(w, "exportBundle", err)
+		return
+	}
+	filename := fmt.Sprintf("page-%d.page-bundle.json", id)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	utils.WriteJSON(w, http.StatusOK, bundle)
+}
+
+func (h *Handler) importBundle(w http.ResponseWriter, r *http.Request) {
+	if !h.requireHomeManage(r) {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	var body struct {
+		Slug   string             `json:"slug"`
+		Title  string             `json:"title"`
+		Bundle *models.PageBundle `json:"bundle"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBundleRequestBytes)).Decode(&body); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid bundle")
+		return
+	}
+	userID, _ := utils.UserIDFromCtx(r)
+	p, err := h.svc.ImportBundle(body.Bundle, body.Slug, body.Title, userID)
+	if err != nil {
+		writeBundleError(w, "importBundle", err)
+		return
+	}
+	h.svc.EnrichPage(p)
+	utils.WriteJSON(w, http.StatusCreated, p)
+	h.dispatchImportedPage(r, userID, p, map[string]interface{}{"imported": true})
+}
+
+func (h *Handler) listTemplates(w http.ResponseWriter, r *http.Request) {
+	if !h.requireHomeManage(r) {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	templates, err := h.svc.ListTemplates(r.URL.Query().Get("category"))
+	if err != nil {
+		writeBundleError(w, "listTemplates", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, templates)
+}
+
+// createTemplate saves either an existing page (page_id) or an uploaded
+// bundle to the gallery.
+func (h *Handler) createTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.requireHomeManage(r) {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	var body struct {
+		TemplateInput
+		PageID int64              `json:"page_id"`
+		Bundle *models.PageBundle `json:"bundle"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBundleRequestBytes)).Decode(&body); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid template")
+		return
+	}
+	if (body.PageID > 0) == (body.Bundle != nil) {
+		utils.WriteError(w, http.StatusBadRequest, "provide exactly one of page_id or bundle")
+		return
+	}
+	userID, _ := utils.UserIDFromCtx(r)
+	var t *models.PageTemplate
+	var err error
+	if body.PageID > 0 {
+		t, err = h.svc.SaveTemplateFromPage(body.PageID, body.TemplateInput, userID)
+	} else {
+		t, err = h.svc.SaveTemplate(body.TemplateInput, body.Bundle, userID)
+	}
+	if err != nil {
+		writeBundleError(w, "createTemplate", err)
+		return
+	}
+	t.Bundle = nil
+	utils.WriteJSON(w, http.StatusCreated, t)
+}
+
+func (h *Handler) getTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.requireHomeManage(r) {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	id, err := parseID(r, "templateId")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid template id")
+		return
+	}
+	t, err := h.svc.GetTemplate(id)
+	if err != nil {
+		writeBundleError(w, "getTemplate", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, t)
+}
+
+func (h *Handler) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.requireHomeManage(r) {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	id, err := parseID(r, "templateId")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid template id")
+		return
+	}
+	userID, _ := utils.UserIDFromCtx(r)
+	if err := h.svc.DeleteTemplate(id, userID); err != nil {
+		writeBundleError(w, "deleteTemplate", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+func (h *Handler) instantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.requireHomeManage(r) {
+		utils.WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	id, err := parseID(r, "templateId")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid template id")
+		return
+	}
+	var body struct {
+		Slug  string `json:"slug"`
+		Title string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Slug == "" {
+		utils.WriteError(w, http.StatusBadRequest, "slug is required")
+		return
+	}
+	userID, _ := utils.UserIDFromCtx(r)
+	p, err := h.svc.InstantiateTemplate(id, body.Slug, body.Title, userID)
+	if err != nil {
+		writeBundleError(w, "instantiateTemplate", err)
+		return
+	}
+	h.svc.EnrichPage(p)
+	utils.WriteJSON(w, http.StatusCreated, p)
+	h.dispatchImportedPage(r, userID, p, map[string]interface{}{"template_id": id})
+}
+
 func pageUpdatePatch(p *models.Page) map[string]interface{} {
 	if p == nil {
 		return nil
@@ -671,6 +1238,7 @@ func pageUpdatePatch(p *models.Page) map[string]interface{} {
 		"seo_description": p.SEODesc,
 		"seo_image":       p.SEOImage,
 		"visibility":      p.Visibility,
+		"status":          p.Status,
 		"owner_id":        p.OwnerID,
 		"updated_at":      p.UpdatedAt,
 		"partial":         true,
@@ -2,6 +2,7 @@ package page
 
 import (
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/skaia/backend/internal/s_registry"
+	log "github.com/skaia/backend/internal/syslog"
 	"github.com/skaia/backend/models"
 )
 
@@ -33,7 +35,6 @@ type InteractiveRecord struct {
 	Status             string                 `json:"status"`
 	Answer             string                 `json:"answer,omitempty"`
 	Pinned             bool                   `json:"pinned,omitempty"`
-	IdempotencyKey     string                 `json:"idempotency_key,omitempty"`
 	IdempotencyKeyHash string                 `json:"-"`
 	SubmittedAt        time.Time              `json:"submitted_at"`
 	UpdatedAt          time.Time              `json:"updated_at,omitempty"`
@@ -103,48 +104,6 @@ func recordsFromConfig(cfg map[string]interface{}) []interface{} {
 	return records
 }
 
-// mergeInteractiveRecords keeps participant-owned data from the locked current
-// document while applying an ordinary builder save to the rest of each section.
-func mergeInteractiveRecords(currentContent, incomingContent string) (string, error) {
-	current, err := decodePageSections(currentContent)
-	if err != nil {
-		return "", err
-	}
-	incoming, err := decodePageSections(incomingContent)
-	if err != nil {
-		return "", err
-	}
-	byID := make(map[int64]map[string]interface{}, len(current))
-	for _, section := range current {
-		byID[sectionID(section)] = section
-	}
-	for _, section := range incoming {
-		typ, _ := section["section_type"].(string)
-		if !s_registry.IsInteractive(typ) {
-			continue
-		}
-		prior := byID[sectionID(section)]
-		if prior == nil {
-			continue
-		}
-		priorCfg, err := sectionConfig(prior)
-		if err != nil {
-			return "", err
-		}
-		nextCfg, err := sectionConfig(section)
-		if err != nil {
-			return "", err
-		}
-		nextCfg["records"] = recordsFromConfig(priorCfg)
-		delete(nextCfg, "result_summary")
-		if err := setSectionConfig(section, nextCfg); err != nil {
-			return "", err
-		}
-	}
-	raw, err := json.Marshal(incoming)
-	return string(raw), err
-}
-
 func findInteractiveSection(sections []map[string]interface{}, id int64) (map[string]interface{}, string, error) {
 	for _, section := range sections {
 		if sectionID(section) != id {
@@ -324,7 +283,29 @@ func interactiveIdempotencyHash(value string) string {
 	return hex.EncodeToString(sum[:])
 }
 
-// SubmitInteractive appends a response to an existing PageSection config.
+// interactiveSection loads the live definition of an interactive section.
+func (s *Service) interactiveSection(pageID, targetSectionID int64) (map[string]interface{}, string, error) {
+	p, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return nil, "", err
+	}
+	sections, err := decodePageSections(p.Content)
+	if err != nil {
+		return nil, "", err
+	}
+	section, typ, err := findInteractiveSection(sections, targetSectionID)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := sectionConfig(section)
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, typ, nil
+}
+
+// SubmitInteractive stores a response to an interactive section. A repeated
+// idempotency key from the same participant returns the original response.
 func (s *Service) SubmitInteractive(pageID, targetSectionID, userID int64, respondentName, idempotencyKey string, answers map[string]interface{}) (*InteractiveRecord, error) {
 	if userID <= 0 {
 		return nil, ErrInteractiveForbidden
@@ -332,122 +313,47 @@ func (s *Service) SubmitInteractive(pageID, targetSectionID, userID int64, respo
 	if len(idempotencyKey) > 200 {
 		return nil, fmt.Errorf("idempotency key is too long")
 	}
-	var created *InteractiveRecord
-	err := s.repo.MutateContent(pageID, func(content string) (string, error) {
-		sections, err := decodePageSections(content)
-		if err != nil {
-			return "", err
-		}
-		section, typ, err := findInteractiveSection(sections, targetSectionID)
-		if err != nil {
-			return "", err
-		}
-		cfg, err := sectionConfig(section)
-		if err != nil {
-			return "", err
-		}
-		if err := s_registry.ValidateInteractiveConfig(typ, cfg); err != nil {
-			return "", fmt.Errorf("interactive config is invalid: %w", err)
-		}
-		if status, _ := cfg["status"].(string); status == "closed" {
-			return "", ErrInteractiveClosed
-		}
-		if err := validateAnswers(cfg, answers); err != nil {
-			return "", err
-		}
-		records := recordsFromConfig(cfg)
-		limit := numberSetting(cfg, "response_limit", 0)
-		if typ == "poll" || typ == "vote" {
-			limit = 1
-		}
-		count := 0
-		for _, raw := range records {
-			record, _ := raw.(map[string]interface{})
-			recordUserID, _ := record["user_id"].(float64)
-			key, _ := record["idempotency_key"].(string)
-			storedHash, _ := record["idempotency_key_hash"].(string)
-			if idempotencyKey != "" && int64(recordUserID) == userID && (key == idempotencyKey || storedHash == interactiveIdempotencyHash(idempotencyKey)) {
-				blob, _ := json.Marshal(record)
-				var existing InteractiveRecord
-				_ = json.Unmarshal(blob, &existing)
-				created = &existing
-				return content, nil
-			}
-			if int64(recordUserID) == userID {
-				count++
-			}
-		}
-		if limit > 0 && count >= limit {
-			return "", ErrInteractiveDuplicate
-		}
-		if len(records) >= 1000 {
-			return "", fmt.Errorf("section response capacity reached")
-		}
-		now := time.Now().UTC()
-		status := "submitted"
-		if typ == "qa" {
-			status = "pending"
-			if moderate, ok := cfg["moderation"].(bool); ok && !moderate {
-				status = "published"
-			}
-		}
-		created = &InteractiveRecord{
-			ID: uuid.NewString(), UserID: userID, RespondentName: respondentName,
-			Answers: answers, Status: status, IdempotencyKey: idempotencyKey, SubmittedAt: now,
-		}
-		records = append(records, created)
-		cfg["records"] = records
-		if err := setSectionConfig(section, cfg); err != nil {
-			return "", err
+	cfg, typ, err := s.interactiveSection(pageID, targetSectionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s_registry.ValidateInteractiveConfig(typ, cfg); err != nil {
+		return nil, fmt.Errorf("interactive config is invalid: %w", err)
+	}
+	if status, _ := cfg["status"].(string); status == "closed" {
+		return nil, ErrInteractiveClosed
+	}
+	if err := validateAnswers(cfg, answers); err != nil {
+		return nil, err
+	}
+	limit := numberSetting(cfg, "response_limit", 0)
+	if typ == "poll" || typ == "vote" {
+		limit = 1
+	}
+	status := "submitted"
+	if typ == "qa" {
+		status = "pending"
+		if moderate, ok := cfg["moderation"].(bool); ok && !moderate {
+			status = "published"
 		}
-		next, err := json.Marshal(sections)
-		return string(next), err
-	})
-	if err == nil {
-		s.invalidateSEOByID(pageID)
 	}
-	return created, err
+	return s.repo.CreateInteractiveResponse(pageID, targetSectionID, &InteractiveRecord{
+		ID: uuid.NewString(), UserID: userID, RespondentName: respondentName,
+		Answers: answers, Status: status, IdempotencyKeyHash: interactiveIdempotencyHash(idempotencyKey),
+		SubmittedAt: time.Now().UTC(),
+	}, limit)
 }
 
 func (s *Service) DeleteInteractiveRecord(pageID, targetSectionID int64, recordID string, actorID int64) error {
 	if err := s.requireInteractiveManager(pageID, actorID); err != nil {
 		return err
 	}
-	err := s.repo.MutateContent(pageID, func(content string) (string, error) {
-		sections, err := decodePageSections(content)
-		if err != nil {
-			return "", err
-		}
-		section, _, err := findInteractiveSection(sections, targetSectionID)
-		if err != nil {
-			return "", err
-		}
-		cfg, err := sectionConfig(section)
-		if err != nil {
-			return "", err
-		}
-		nextRecords := make([]interface{}, 0, len(recordsFromConfig(cfg)))
-		found := false
-		for _, raw := range recordsFromConfig(cfg) {
-			record, _ := raw.(map[string]interface{})
-			if id, _ := record["id"].(string); id == recordID {
-				found = true
-				continue
-			}
-			nextRecords = append(nextRecords, raw)
-		}
-		if !found {
-			return "", ErrInteractiveRecordNotFound
-		}
-		cfg["records"] = nextRecords
-		if err := setSectionConfig(section, cfg); err != nil {
-			return "", err
-		}
-		next, err := json.Marshal(sections)
-		return string(next), err
-	})
-	if err == nil {
-		s.invalidateSEOByID(pageID)
+	if _, _, err := s.interactiveSection(pageID, targetSectionID); err != nil {
+		return err
+	}
+	err := s.repo.DeleteInteractiveResponse(pageID, targetSectionID, recordID, actorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInteractiveRecordNotFound
 	}
 	return err
 }
@@ -456,60 +362,42 @@ func (s *Service) PatchInteractiveRecord(pageID, targetSectionID int64, recordID
 	if err := s.requireInteractiveManager(pageID, actorID); err != nil {
 		return err
 	}
-	err := s.repo.MutateContent(pageID, func(content string) (string, error) {
-		sections, err := decodePageSections(content)
-		if err != nil {
-			return "", err
-		}
-		section, typ, err := findInteractiveSection(sections, targetSectionID)
-		if err != nil {
-			return "", err
-		}
-		if typ != "qa" {
-			return "", fmt.Errorf("moderation is only supported for Q&A")
-		}
-		cfg, err := sectionConfig(section)
-		if err != nil {
-			return "", err
-		}
-		found := false
-		for _, raw := range recordsFromConfig(cfg) {
-			record, _ := raw.(map[string]interface{})
-			if id, _ := record["id"].(string); id != recordID {
-				continue
-			}
-			found = true
-			if patch.Status != nil {
-				if *patch.Status != "pending" && *patch.Status != "published" && *patch.Status != "answered" && *patch.Status != "archived" {
-					return "", fmt.Errorf("invalid moderation status")
-				}
-				record["status"] = *patch.Status
-			}
-			if patch.Answer != nil {
-				if len(*patch.Answer) > 10000 {
-					return "", fmt.Errorf("answer is too long")
-				}
-				record["answer"] = strings.TrimSpace(*patch.Answer)
-				if strings.TrimSpace(*patch.Answer) != "" {
-					record["status"] = "answered"
-				}
-			}
-			if patch.Pinned != nil {
-				record["pinned"] = *patch.Pinned
-			}
-			record["updated_at"] = time.Now().UTC()
+	_, typ, err := s.interactiveSection(pageID, targetSectionID)
+	if err != nil {
+		return err
+	}
+	if typ != "qa" {
+		return fmt.Errorf("moderation is only supported for Q&A")
+	}
+	record, err := s.repo.GetInteractiveResponse(pageID, targetSectionID, recordID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInteractiveRecordNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if patch.Status != nil {
+		if *patch.Status != "pending" && *patch.Status != "published" && *patch.Status != "answered" && *patch.Status != "archived" {
+			return fmt.Errorf("invalid moderation status")
 		}
-		if !found {
-			return "", ErrInteractiveRecordNotFound
+		record.Status = *patch.Status
+	}
+	if patch.Answer != nil {
+		if len(*patch.Answer) > 10000 {
+			return fmt.Errorf("answer is too long")
 		}
-		if err := setSectionConfig(section, cfg); err != nil {
-			return "", err
+		record.Answer = strings.TrimSpace(*patch.Answer)
+		if record.Answer != "" {
+			record.Status = "answered"
 		}
-		next, err := json.Marshal(sections)
-		return string(next), err
-	})
-	if err == nil {
-		s.invalidateSEOByID(pageID)
+	}
+	if patch.Pinned != nil {
+		record.Pinned = *patch.Pinned
+	}
+	record.UpdatedAt = time.Now().UTC()
+	err = s.repo.UpdateInteractiveResponse(pageID, targetSectionID, record)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInteractiveRecordNotFound
 	}
 	return err
 }
@@ -524,8 +412,21 @@ func (s *Service) requireInteractiveManager(pageID, actorID int64) error {
 	return nil
 }
 
-func interactiveResultSummary(cfg map[string]interface{}) map[string]interface{} {
-	counts := map[string]map[string]int{}
+// InteractiveSummary counts a section's live responses and, per aggregate
+// field, how often each answer was given.
+type InteractiveSummary struct {
+	Total  int
+	Counts map[string]map[string]int
+}
+
+// interactiveEmbedLimit bounds the records embedded per section when a page
+// is read: the viewer's own responses and, separately, published Q&A
+// questions. Managers read full lists through ListInteractiveResponses.
+const interactiveEmbedLimit = DefaultInteractiveResponseLimit
+
+// interactiveAggregateFields returns the keys of the fields counted in a
+// section's result summary. Free-text answers are never aggregated.
+func interactiveAggregateFields(cfg map[string]interface{}) map[string]bool {
 	aggregateFields := map[string]bool{}
 	fields, _ := cfg["fields"].([]interface{})
 	for _, raw := range fields {
@@ -537,26 +438,36 @@ func interactiveResultSummary(cfg map[string]interface{}) map[string]interface{}
 			aggregateFields[key] = true
 		}
 	}
+	return aggregateFields
+}
+
+func countInteractiveAnswers(counts map[string]map[string]int, aggregateFields map[string]bool, answers map[string]interface{}) {
+	for key, value := range answers {
+		if !aggregateFields[key] {
+			continue
+		}
+		values := []interface{}{value}
+		if list, ok := value.([]interface{}); ok {
+			values = list
+		}
+		if counts[key] == nil {
+			counts[key] = map[string]int{}
+		}
+		for _, item := range values {
+			label := fmt.Sprint(item)
+			counts[key][label]++
+		}
+	}
+}
+
+func interactiveResultSummary(cfg map[string]interface{}) map[string]interface{} {
+	counts := map[string]map[string]int{}
+	aggregateFields := interactiveAggregateFields(cfg)
 	records := recordsFromConfig(cfg)
 	for _, raw := range records {
 		record, _ := raw.(map[string]interface{})
 		answers, _ := record["answers"].(map[string]interface{})
-		for key, value := range answers {
-			if !aggregateFields[key] {
-				continue
-			}
-			values := []interface{}{value}
-			if list, ok := value.([]interface{}); ok {
-				values = list
-			}
-			if counts[key] == nil {
-				counts[key] = map[string]int{}
-			}
-			for _, item := range values {
-				label := fmt.Sprint(item)
-				counts[key][label]++
-			}
-		}
+		countInteractiveAnswers(counts, aggregateFields, answers)
 	}
 	return map[string]interface{}{"total": len(records), "counts": counts}
 }
@@ -605,7 +516,9 @@ func SanitizeInteractiveContent(content string, userID int64, canManage bool) st
 			return "[]"
 		}
 		allRecords := recordsFromConfig(cfg)
-		cfg["result_summary"] = interactiveResultSummary(cfg)
+		if _, ok := cfg["result_summary"].(map[string]interface{}); !ok {
+			cfg["result_summary"] = interactiveResultSummary(cfg)
+		}
 		visible := make([]interface{}, 0, len(allRecords))
 		if canManage {
 			for _, raw := range allRecords {
@@ -676,18 +589,107 @@ func extractInteractiveConfig(content string, targetSectionID int64) (string, er
 	return string(raw), err
 }
 
+// embedInteractiveResponses places stored responses into the "records" array
+// of each interactive section's config, the shape SanitizeInteractiveContent
+// filters for the viewer, alongside each section's result summary.
+func embedInteractiveResponses(content string, bySection map[int64][]*InteractiveRecord, summaries map[int64]*InteractiveSummary) string {
+	sections, err := decodePageSections(content)
+	if err != nil {
+		return content
+	}
+	for _, section := range sections {
+		typ, _ := section["section_type"].(string)
+		if !s_registry.IsInteractive(typ) {
+			continue
+		}
+		cfg, err := sectionConfig(section)
+		if err != nil {
+			continue
+		}
+		records := []interface{}{}
+		if blob, err := json.Marshal(bySection[sectionID(section)]); err == nil {
+			_ = json.Unmarshal(blob, &records)
+		}
+		if records == nil {
+			records = []interface{}{}
+		}
+		cfg["records"] = records
+		summary := summaries[sectionID(section)]
+		if summary == nil {
+			summary = &InteractiveSummary{}
+		}
+		counts := summary.Counts
+		if counts == nil {
+			counts = map[string]map[string]int{}
+		}
+		cfg["result_summary"] = map[string]interface{}{"total": summary.Total, "counts": counts}
+		_ = setSectionConfig(section, cfg)
+	}
+	raw, err := json.Marshal(sections)
+	if err != nil {
+		return content
+	}
+	return string(raw)
+}
+
+// withInteractiveResponses returns the page content with the responses
+// userID may read embedded: their own and published Q&A questions, at most
+// interactiveEmbedLimit of each per section, plus aggregates computed over
+// every response. Pages without interactive sections skip the lookup.
+func (s *Service) withInteractiveResponses(pageID int64, content string, userID int64) string {
+	sections, err := decodePageSections(content)
+	if err != nil {
+		return content
+	}
+	aggregate := map[int64][]string{}
+	var publicQA []int64
+	interactive := false
+	for _, section := range sections {
+		typ, _ := section["section_type"].(string)
+		if !s_registry.IsInteractive(typ) {
+			continue
+		}
+		interactive = true
+		id := sectionID(section)
+		if typ == "qa" {
+			publicQA = append(publicQA, id)
+		}
+		cfg, err := sectionConfig(section)
+		if err != nil {
+			continue
+		}
+		for key := range interactiveAggregateFields(cfg) {
+			aggregate[id] = append(aggregate[id], key)
+		}
+	}
+	if !interactive {
+		return content
+	}
+	summaries, err := s.repo.PageInteractiveSummaries(pageID, aggregate)
+	if err != nil {
+		log.Printf("page.withInteractiveResponses(%d): %v", pageID, err)
+		summaries = nil
+	}
+	bySection, err := s.repo.PageInteractiveRecords(pageID, userID, publicQA, interactiveEmbedLimit)
+	if err != nil {
+		log.Printf("page.withInteractiveResponses(%d): %v", pageID, err)
+		bySection = nil
+	}
+	return embedInteractiveResponses(content, bySection, summaries)
+}
+
 func (s *Service) InteractiveConfig(pageID, targetSectionID, userID int64, canManage bool) (string, error) {
 	p, err := s.repo.GetByID(pageID)
 	if err != nil {
 		return "", err
 	}
-	content := SanitizeInteractiveContent(p.Content, userID, canManage)
+	content := SanitizeInteractiveContent(s.withInteractiveResponses(pageID, p.Content, userID), userID, canManage)
 	return extractInteractiveConfig(content, targetSectionID)
 }
 
 func (s *Service) SanitizeInteractivePage(page *models.Page, userID int64, canManage bool) {
 	if page != nil {
-		page.Content = SanitizeInteractiveContent(page.Content, userID, canManage)
+		page.Content = SanitizeInteractiveContent(s.withInteractiveResponses(page.ID, page.Content, userID), userID, canManage)
 	}
 }
 
@@ -26,22 +26,56 @@ func interactiveContentOfType(sectionType, config string) string {
 	return string(raw)
 }
 
-func TestMergeInteractiveRecordsPreservesConcurrentResponses(t *testing.T) {
-	current := interactiveContent(`{"fields":[{"key":"choice","type":"radio"}],"records":[{"id":"r1","user_id":2,"answers":{"choice":"a"}}]}`)
-	incoming := interactiveContent(`{"fields":[{"key":"choice","type":"radio","label":"Updated"}],"records":[],"result_summary":{"total":0}}`)
-	merged, err := mergeInteractiveRecords(current, incoming)
+func TestBuilderSaveLeavesStoredResponsesUntouched(t *testing.T) {
+	design := func(label string) string {
+		return interactiveContent(`{
+			"status":"open","result_visibility":"always","response_limit":1,
+			"fields":[{"key":"choice","type":"radio","label":"` + label + `","options":[{"key":"a","label":"A"}]}],
+			"records":[],"result_summary":{"total":0}
+		}`)
+	}
+	repo := &memoryInteractiveRepository{page: models.Page{ID: 1, Slug: "page", Content: design("Before"), Visibility: "public"}}
+	svc := NewService(repo, nil)
+	if _, err := svc.SubmitInteractive(1, 7, 2, "Voter", "", map[string]interface{}{"choice": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Update(&models.Page{ID: 1, Slug: "page", Content: design("After"), Visibility: "public"}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(repo.page.Content, "result_summary") || strings.Contains(repo.page.Content, "user_id") {
+		t.Fatalf("builder save persisted derived or participant data: %s", repo.page.Content)
+	}
+	config, err := svc.InteractiveConfig(1, 7, 9, false)
 	if err != nil {
 		t.Fatal(err)
 	}
-	config, err := extractInteractiveConfig(merged, 7)
+	if !strings.Contains(config, "After") || !strings.Contains(config, `"total":1`) {
+		t.Fatalf("stored response missing after builder save: %s", config)
+	}
+}
+
+func TestInteractiveConfigEmbedsViewerRowsAndFullAggregates(t *testing.T) {
+	content := interactiveContent(`{
+		"status":"open","result_visibility":"always","response_limit":1,
+		"fields":[{"key":"choice","type":"radio","options":[{"key":"a","label":"A"}]},{"key":"note","type":"text"}],
+		"records":[]
+	}`)
+	repo := &memoryInteractiveRepository{page: models.Page{ID: 1, Slug: "page", Content: content, Visibility: "public"}}
+	svc := NewService(repo, nil)
+	for userID := int64(2); userID <= 4; userID++ {
+		if _, err := svc.SubmitInteractive(1, 7, userID, "Voter", "", map[string]interface{}{"choice": "a", "note": "private"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config, err := svc.InteractiveConfig(1, 7, 2, true)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if !strings.Contains(config, `"id":"r1"`) {
-		t.Fatalf("response was lost during builder merge: %s", config)
+	if strings.Count(config, `"user_id"`) != 1 || !strings.Contains(config, `"user_id":2`) {
+		t.Fatalf("page view embedded other participants' responses: %s", config)
 	}
-	if strings.Contains(config, "result_summary") {
-		t.Fatalf("derived result summary must not be persisted: %s", config)
+	if !strings.Contains(config, `"total":3`) || !strings.Contains(config, `"choice":{"a":3}`) || strings.Contains(config, `"note":{`) {
+		t.Fatalf("summary does not cover every response: %s", config)
 	}
 }
 
@@ -124,9 +158,11 @@ func TestValidateAnswersEnforcesStoredFieldContracts(t *testing.T) {
 
 type memoryInteractiveRepository struct {
 	Repository
-	mu      sync.Mutex
-	page    models.Page
-	editors map[int64]bool
+	mu        sync.Mutex
+	page      models.Page
+	editors   map[int64]bool
+	revisions []models.PageRevision
+	responses map[int64][]*InteractiveRecord
 }
 
 type fakePermissionChecker struct {
@@ -148,14 +184,137 @@ func (r *memoryInteractiveRepository) GetByID(id int64) (*models.Page, error) {
 	return &copyPage, nil
 }
 
-func (r *memoryInteractiveRepository) MutateContent(_ int64, mutate func(string) (string, error)) error {
+func (r *memoryInteractiveRepository) CreateInteractiveResponse(_, sectionID int64, rec *InteractiveRecord, limit int) (*InteractiveRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	own := 0
+	for _, existing := range r.responses[sectionID] {
+		if existing.UserID != rec.UserID {
+			continue
+		}
+		if rec.IdempotencyKeyHash != "" && existing.IdempotencyKeyHash == rec.IdempotencyKeyHash {
+			return existing, nil
+		}
+		own++
+	}
+	if limit > 0 && own >= limit {
+		return nil, ErrInteractiveDuplicate
+	}
+	if r.responses == nil {
+		r.responses = map[int64][]*InteractiveRecord{}
+	}
+	r.responses[sectionID] = append(r.responses[sectionID], rec)
+	return rec, nil
+}
+
+func (r *memoryInteractiveRepository) GetInteractiveResponse(_, sectionID int64, recordID string) (*InteractiveRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rec := range r.responses[sectionID] {
+		if rec.ID == recordID {
+			copyRecord := *rec
+			return &copyRecord, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *memoryInteractiveRepository) UpdateInteractiveResponse(_, sectionID int64, rec *InteractiveRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.responses[sectionID] {
+		if existing.ID == rec.ID {
+			copyRecord := *rec
+			r.responses[sectionID][i] = &copyRecord
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *memoryInteractiveRepository) DeleteInteractiveResponse(_, sectionID int64, recordID string, _ int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.responses[sectionID] {
+		if existing.ID == recordID {
+			r.responses[sectionID] = append(r.responses[sectionID][:i], r.responses[sectionID][i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *memoryInteractiveRepository) ListInteractiveResponses(_, sectionID int64, limit, offset int) ([]*InteractiveRecord, int, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	next, err := mutate(r.page.Content)
-	if err == nil {
-		r.page.Content = next
+	all := r.responses[sectionID]
+	var records []*InteractiveRecord
+	for i := len(all) - 1 - offset; i >= 0 && len(records) < limit; i-- {
+		records = append(records, all[i])
 	}
-	return err
+	return records, len(all), nil
+}
+
+func (r *memoryInteractiveRepository) ListInteractiveResponsesAfter(_, sectionID int64, after *InteractiveRecord, limit int) ([]*InteractiveRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := r.responses[sectionID]
+	start := 0
+	if after != nil {
+		for i, rec := range all {
+			if rec.ID == after.ID {
+				start = i + 1
+			}
+		}
+	}
+	var records []*InteractiveRecord
+	for i := start; i < len(all) && len(records) < limit; i++ {
+		records = append(records, all[i])
+	}
+	return records, nil
+}
+
+func (r *memoryInteractiveRepository) PageInteractiveRecords(_, viewerID int64, publicQA []int64, limit int) (map[int64][]*InteractiveRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	qa := map[int64]bool{}
+	for _, id := range publicQA {
+		qa[id] = true
+	}
+	bySection := make(map[int64][]*InteractiveRecord, len(r.responses))
+	for sectionID, records := range r.responses {
+		own, public := 0, 0
+		for _, rec := range records {
+			switch {
+			case viewerID > 0 && rec.UserID == viewerID && own < limit:
+				own++
+			case rec.UserID != viewerID && qa[sectionID] && (rec.Status == "published" || rec.Status == "answered") && public < limit:
+				public++
+			default:
+				continue
+			}
+			bySection[sectionID] = append(bySection[sectionID], rec)
+		}
+	}
+	return bySection, nil
+}
+
+func (r *memoryInteractiveRepository) PageInteractiveSummaries(_ int64, aggregate map[int64][]string) (map[int64]*InteractiveSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	summaries := make(map[int64]*InteractiveSummary, len(r.responses))
+	for sectionID, records := range r.responses {
+		fields := map[string]bool{}
+		for _, key := range aggregate[sectionID] {
+			fields[key] = true
+		}
+		summary := &InteractiveSummary{Total: len(records), Counts: map[string]map[string]int{}}
+		for _, rec := range records {
+			countInteractiveAnswers(summary.Counts, fields, rec.Answers)
+		}
+		summaries[sectionID] = summary
+	}
+	return summaries, nil
 }
 
 func (r *memoryInteractiveRepository) IsEditor(_ int64, userID int64) (bool, error) {
@@ -164,7 +323,7 @@ func (r *memoryInteractiveRepository) IsEditor(_ int64, userID int64) (bool, err
 	return r.editors[userID], nil
 }
 
-func (r *memoryInteractiveRepository) Create(page *models.Page) error {
+func (r *memoryInteractiveRepository) Create(page *models.Page, authorID int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.page = *page
@@ -172,22 +331,51 @@ func (r *memoryInteractiveRepository) Create(page *models.Page) error {
 		r.page.ID = 1
 		page.ID = r.page.ID
 	}
+	r.appendRevision(authorID, nil)
 	return nil
 }
 
-func (r *memoryInteractiveRepository) UpdatePreservingInteractive(page *models.Page) error {
+func (r *memoryInteractiveRepository) Update(page *models.Page, authorID int64) error {
+	return r.update(page, authorID, nil)
+}
+
+func (r *memoryInteractiveRepository) RestoreRevision(page *models.Page, authorID, revisionID int64) error {
+	return r.update(page, authorID, &revisionID)
+}
+
+func (r *memoryInteractiveRepository) update(page *models.Page, authorID int64, restoredFrom *int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	merged, err := mergeInteractiveRecords(r.page.Content, page.Content)
-	if err != nil {
-		return err
-	}
-	page.Content = merged
 	r.page = *page
+	r.appendRevision(authorID, restoredFrom)
 	return nil
 }
 
-func (r *memoryInteractiveRepository) UpdateSEO(pageID int64, title, description, image string) error {
+func (r *memoryInteractiveRepository) appendRevision(authorID int64, restoredFrom *int64) {
+	rev := models.PageRevision{
+		ID: int64(len(r.revisions) + 1), PageID: r.page.ID, Slug: r.page.Slug, Title: r.page.Title,
+		Description: r.page.Description, SEOTitle: r.page.SEOTitle, SEODesc: r.page.SEODesc,
+		SEOImage: r.page.SEOImage, Visibility: r.page.Visibility,
+		Content: ClearInteractiveRecords(r.page.Content), RestoredFrom: restoredFrom,
+	}
+	if authorID > 0 {
+		rev.AuthorID = &authorID
+	}
+	r.revisions = append(r.revisions, rev)
+}
+
+func (r *memoryInteractiveRepository) GetRevision(pageID, revisionID int64) (*models.PageRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rev := range r.revisions {
+		if rev.PageID == pageID && rev.ID == revisionID {
+			return &rev, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *memoryInteractiveRepository) UpdateSEO(pageID, _ int64, title, description, image string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if r.page.ID != pageID {
@@ -202,9 +390,11 @@ func (r *memoryInteractiveRepository) UpdateSEO(pageID int64, title, description
 func TestSubmitInteractiveScopesIdempotencyToParticipant(t *testing.T) {
 	repo := &memoryInteractiveRepository{page: models.Page{ID: 1, Content: interactiveContent(`{
 		"status":"open","result_visibility":"after_participation","response_limit":1,
-		"fields":[{"key":"choice","type":"radio","options":[{"key":"a","label":"A"}]}],
-		"records":[{"id":"other-record","user_id":2,"idempotency_key":"shared-key","answers":{"choice":"a"},"status":"submitted"}]
-	}`)}}
+		"fields":[{"key":"choice","type":"radio","options":[{"key":"a","label":"A"}]}]
+	}`)}, responses: map[int64][]*InteractiveRecord{7: {{
+		ID: "other-record", UserID: 2, IdempotencyKeyHash: interactiveIdempotencyHash("shared-key"),
+		Answers: map[string]interface{}{"choice": "a"}, Status: "submitted",
+	}}}}
 	svc := NewService(repo, nil)
 	created, err := svc.SubmitInteractive(1, 7, 3, "Third user", "shared-key", map[string]interface{}{"choice": "a"})
 	if err != nil {
@@ -224,10 +414,12 @@ func TestInteractiveManagerPolicyFailsClosed(t *testing.T) {
 	repo := &memoryInteractiveRepository{
 		page: models.Page{ID: 1, OwnerID: &ownerID, Content: interactiveContentOfType("qa", `{
 			"status":"open","result_visibility":"never","response_limit":0,
-			"fields":[{"key":"question","type":"textarea"}],
-			"records":[{"id":"question-1","user_id":2,"answers":{"question":"Private"},"status":"pending"}]
+			"fields":[{"key":"question","type":"textarea"}]
 		}`)},
 		editors: map[int64]bool{12: true},
+		responses: map[int64][]*InteractiveRecord{7: {{
+			ID: "question-1", UserID: 2, Answers: map[string]interface{}{"question": "Private"}, Status: "pending",
+		}}},
 	}
 	svc := NewService(repo, nil, WithInteractivePolicy(isecurity.NewPagePolicy(repo, fakePermissionChecker{})))
 	status := "published"
@@ -262,7 +454,7 @@ func TestCreateStripsClientSuppliedInteractiveRecords(t *testing.T) {
 		"fields":[{"key":"choice","type":"radio","options":[{"key":"a","label":"A"}]}],
 		"records":[{"id":"imported","user_id":9,"answers":{"choice":"a"}}]
 	}`)}
-	if err := svc.Create(p); err != nil {
+	if err := svc.Create(p, 0); err != nil {
 		t.Fatal(err)
 	}
 	if strings.Contains(repo.page.Content, "imported") || strings.Contains(repo.page.Content, `"user_id":9`) {
@@ -283,7 +475,7 @@ func TestUpdateDoesNotImportRecordsIntoRekeyedSection(t *testing.T) {
 	}}
 	raw, _ := json.Marshal(incoming)
 	p := &models.Page{ID: 1, Slug: "page", Content: string(raw), Visibility: "public"}
-	if err := svc.Update(p); err != nil {
+	if err := svc.Update(p, 0); err != nil {
 		t.Fatal(err)
 	}
 	if strings.Contains(repo.page.Content, "stored") || strings.Contains(repo.page.Content, "imported") {
@@ -345,7 +537,7 @@ func TestDatabaseConcurrentBuilderSavePreservesSubmission(t *testing.T) {
 		"fields":[{"key":"name","type":"text","label":"Name"}],"records":[]
 	}`)
 	p := &models.Page{Slug: "interactive-concurrency-" + uuid.NewString(), Title: "Concurrency", Content: content, Visibility: "private"}
-	if err := repo.Create(p); err != nil {
+	if err := repo.Create(p, 0); err != nil {
 		t.Fatal(err)
 	}
 	defer repo.Delete(p.ID, 1)
@@ -364,7 +556,7 @@ func TestDatabaseConcurrentBuilderSavePreservesSubmission(t *testing.T) {
 	}()
 	go func() {
 		<-start
-		errs <- svc.Update(&models.Page{ID: p.ID, Slug: p.Slug, Title: p.Title, Content: updated, Visibility: p.Visibility})
+		errs <- svc.Update(&models.Page{ID: p.ID, Slug: p.Slug, Title: p.Title, Content: updated, Visibility: p.Visibility}, 0)
 	}()
 	close(start)
 	for i := 0; i < 2; i++ {
@@ -380,7 +572,11 @@ func TestDatabaseConcurrentBuilderSavePreservesSubmission(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if !strings.Contains(storedConfig, "Updated name") || !strings.Contains(storedConfig, `"user_id":5`) {
-		t.Fatalf("concurrent builder save lost design or response: %s", stored.Content)
+	responses, err := repo.ListInteractiveResponsesAfter(p.ID, 7, nil, MaxInteractiveResponseLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(storedConfig, "Updated name") || len(responses) != 1 || responses[0].UserID != 5 {
+		t.Fatalf("concurrent builder save lost design or response: %s %#v", stored.Content, responses)
 	}
 }
@@ -19,6 +19,13 @@ type BrowseOptions struct {
 	IsAdmin bool
 }
 
+// ScheduledTransition is a publish or unpublish time that has come due.
+type ScheduledTransition struct {
+	PageID int64
+	Action string // SchedulePublish or ScheduleUnpublish
+	At     time.Time
+}
+
 type BrowseResult struct {
 	Pages   []*models.PageBrowseSummary
 	HasMore bool
@@ -28,14 +35,44 @@ type BrowseResult struct {
 type Repository interface {
 	GetBySlug(slug string) (*models.Page, error)
 	GetByID(id int64) (*models.Page, error)
-	Create(p *models.Page) error
-	UpdatePreservingInteractive(p *models.Page) error
-	UpdateSEO(pageID int64, title, description, image string) error
-	MutateContent(pageID int64, mutate func(string) (string, error)) error
+	Create(p *models.Page, authorID int64) error
+	Update(p *models.Page, authorID int64) error
+	UpdateSEO(pageID, authorID int64, title, description, image string) error
 	Delete(id, actorID int64) error
 	DeleteAll(actorID int64) error
 	List() ([]*models.Page, error)
 
+	// Revisions
+	ListRevisions(pageID int64, limit, offset int) ([]*models.PageRevision, error)
+	GetRevision(pageID, revisionID int64) (*models.PageRevision, error)
+	RestoreRevision(p *models.Page, authorID, revisionID int64) error
+
+	// Drafts & publishing
+	GetDraft(pageID int64) (*models.PageDraft, error)
+	SaveDraft(d *models.PageDraft) error
+	DiscardDraft(pageID int64) error
+	Publish(p *models.Page, authorID int64, draft *models.PageDraft) error
+	Unpublish(pageID int64) error
+	SetSchedule(pageID int64, publishAt, unpublishAt *time.Time) error
+	ClearSchedule(pageID int64, action string) error
+	DueSchedules(now time.Time) ([]ScheduledTransition, error)
+
+	// Interactive responses
+	CreateInteractiveResponse(pageID, sectionID int64, rec *InteractiveRecord, limit int) (*InteractiveRecord, error)
+	GetInteractiveResponse(pageID, sectionID int64, recordID string) (*InteractiveRecord, error)
+	UpdateInteractiveResponse(pageID, sectionID int64, rec *InteractiveRecord) error
+	DeleteInteractiveResponse(pageID, sectionID int64, recordID string, actorID int64) error
+	ListInteractiveResponses(pageID, sectionID int64, limit, offset int) ([]*InteractiveRecord, int, error)
+	ListInteractiveResponsesAfter(pageID, sectionID int64, after *InteractiveRecord, limit int) ([]*InteractiveRecord, error)
+	PageInteractiveRecords(pageID, viewerID int64, publicQA []int64, limit int) (map[int64][]*InteractiveRecord, error)
+	PageInteractiveSummaries(pageID int64, aggregate map[int64][]string) (map[int64]*InteractiveSummary, error)
+
+	// Template gallery
+	CreateTemplate(t *models.PageTemplate) error
+	ListTemplates(category string) ([]*models.PageTemplate, error)
+	GetTemplate(id int64) (*models.PageTemplate, error)
+	DeleteTemplate(id, actorID int64) error
+
 	// Ownership & editors
 	SetOwner(pageID, ownerID int64) error
 	ClearOwner(pageID int64) error
@@ -0,0 +1,188 @@
+package page
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/skaia/backend/internal/syslog"
+	"github.com/skaia/backend/models"
+)
+
+const (
+	PageStatusPublished = "published"
+	PageStatusDraft     = "draft"
+
+	SchedulePublish   = "publish"
+	ScheduleUnpublish = "unpublish"
+)
+
+var ErrDraftNotFound = errors.New("page draft not found")
+var ErrInvalidSchedule = errors.New("invalid page schedule")
+var ErrInvalidStatus = errors.New("invalid page status")
+
+// DraftInput is a partial draft save. Nil fields keep the current draft value,
+// or the live page value when no draft exists yet.
+type DraftInput struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Content     *string `json:"content"`
+}
+
+// IsDraft reports whether readers are kept away from p. An empty status is
+// treated as published so callers built before the workflow keep working.
+func IsDraft(p *models.Page) bool {
+	return p != nil && p.Status == PageStatusDraft
+}
+
+func normalizePageStatus(p *models.Page) error {
+	switch p.Status {
+	case "":
+		p.Status = PageStatusPublished
+	case PageStatusPublished, PageStatusDraft:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, p.Status)
+	}
+	return nil
+}
+
+func (s *Service) GetDraft(pageID int64) (*models.PageDraft, error) {
+	draft, err := s.repo.GetDraft(pageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDraftNotFound
+	}
+	return draft, err
+}
+
+// SaveDraft stores the editor's working copy. It is validated like a live
+// save but never touches pages.content, so readers are unaffected.
+func (s *Service) SaveDraft(pageID, actorID int64, input DraftInput) (*models.PageDraft, error) {
+	draft, err := s.GetDraft(pageID)
+	if errors.Is(err, ErrDraftNotFound) {
+		current, getErr := s.repo.GetByID(pageID)
+		if getErr != nil {
+			return nil, getErr
+		}
+		draft = &models.PageDraft{
+			PageID:      pageID,
+			Title:       current.Title,
+			Description: current.Description,
+			Content:     current.Content,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if input.Title != nil {
+		draft.Title = *input.Title
+	}
+	if input.Description != nil {
+		draft.Description = *input.Description
+	}
+	if input.Content != nil {
+		draft.Content = *input.Content
+	}
+	if draft.Content == "" {
+		draft.Content = "[]"
+	}
+	draft.Content = ClearInteractiveRecords(draft.Content)
+	if err := s.validateContent(draft.Content); err != nil {
+		return nil, err
+	}
+	draft.AuthorID = nil
+	if actorID > 0 {
+		draft.AuthorID = &actorID
+	}
+	if err := s.repo.SaveDraft(draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (s *Service) DiscardDraft(pageID int64) error {
+	return s.repo.DiscardDraft(pageID)
+}
+
+// Publish promotes the page's draft, if any, and makes the page visible to
+// readers. It is shared by the publish endpoint and the scheduler.
+func (s *Service) Publish(pageID, actorID int64) (*models.Page, error) {
+	current, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := s.GetDraft(pageID)
+	if errors.Is(err, ErrDraftNotFound) {
+		draft = nil
+	} else if err != nil {
+		return nil, err
+	}
+	p := *current
+	if draft != nil {
+		p.Title = draft.Title
+		p.Description = draft.Description
+		p.Content = ClearInteractiveRecords(draft.Content)
+		if err := s.validateContent(p.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Publish(&p, actorID, draft); err != nil {
+		return nil, err
+	}
+	s.invalidateSEOByID(pageID)
+	return s.repo.GetByID(pageID)
+}
+
+// Unpublish hides the page from readers without touching its content.
+func (s *Service) Unpublish(pageID int64) (*models.Page, error) {
+	if err := s.repo.Unpublish(pageID); err != nil {
+		return nil, err
+	}
+	s.invalidateSEOByID(pageID)
+	return s.repo.GetByID(pageID)
+}
+
+// SetSchedule replaces the page's publish and unpublish times. A nil time
+// clears that side of the schedule.
+func (s *Service) SetSchedule(pageID int64, publishAt, unpublishAt *time.Time) (*models.Page, error) {
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return nil, fmt.Errorf("%w: unpublish_at must be after publish_at", ErrInvalidSchedule)
+	}
+	if err := s.repo.SetSchedule(pageID, publishAt, unpublishAt); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(pageID)
+}
+
+// ApplyDueSchedules runs every publish and unpublish whose time has passed
+// and returns the pages that changed. A scheduled publish whose draft no
+// longer validates is dropped from the schedule rather than retried forever.
+func (s *Service) ApplyDueSchedules(now time.Time) []*models.Page {
+	due, err := s.repo.DueSchedules(now)
+	if err != nil {
+		log.Printf("page: load due schedules: %v", err)
+		return nil
+	}
+	var changed []*models.Page
+	for _, transition := range due {
+		var p *models.Page
+		switch transition.Action {
+		case SchedulePublish:
+			p, err = s.Publish(transition.PageID, 0)
+		case ScheduleUnpublish:
+			p, err = s.Unpublish(transition.PageID)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Printf("page: scheduled %s of page %d: %v", transition.Action, transition.PageID, err)
+			if clearErr := s.repo.ClearSchedule(transition.PageID, transition.Action); clearErr != nil {
+				log.Printf("page: clear %s schedule of page %d: %v", transition.Action, transition.PageID, clearErr)
+			}
+			continue
+		}
+		if p != nil {
+			changed = append(changed, p)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,150 @@
+package page
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skaia/backend/models"
+)
+
+type memoryPublishingRepository struct {
+	*memoryInteractiveRepository
+	draft   *models.PageDraft
+	due     []ScheduledTransition
+	cleared []string
+}
+
+func (r *memoryPublishingRepository) GetDraft(pageID int64) (*models.PageDraft, error) {
+	if r.draft == nil || r.draft.PageID != pageID {
+		return nil, sql.ErrNoRows
+	}
+	copyDraft := *r.draft
+	return &copyDraft, nil
+}
+
+func (r *memoryPublishingRepository) SaveDraft(d *models.PageDraft) error {
+	d.UpdatedAt = time.Now()
+	copyDraft := *d
+	r.draft = &copyDraft
+	return nil
+}
+
+func (r *memoryPublishingRepository) DiscardDraft(int64) error {
+	r.draft = nil
+	return nil
+}
+
+func (r *memoryPublishingRepository) Publish(p *models.Page, authorID int64, draft *models.PageDraft) error {
+	if draft != nil {
+		if err := r.Update(p, authorID); err != nil {
+			return err
+		}
+		r.draft = nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.page.Status = PageStatusPublished
+	r.page.PublishAt = nil
+	return nil
+}
+
+func (r *memoryPublishingRepository) Unpublish(int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.page.Status = PageStatusDraft
+	r.page.UnpublishAt = nil
+	return nil
+}
+
+func (r *memoryPublishingRepository) ClearSchedule(_ int64, action string) error {
+	r.cleared = append(r.cleared, action)
+	return nil
+}
+
+func (r *memoryPublishingRepository) DueSchedules(time.Time) ([]ScheduledTransition, error) {
+	return r.due, nil
+}
+
+func TestDraftIsInvisibleUntilPublishedAndKeepsLiveRecords(t *testing.T) {
+	design := func(label string) string {
+		return interactiveContent(`{
+			"status":"open","result_visibility":"never","response_limit":1,
+			"fields":[{"key":"choice","type":"radio","label":"` + label + `","options":[{"key":"a","label":"A"}]}],
+			"records":[]
+		}`)
+	}
+	repo := &memoryPublishingRepository{memoryInteractiveRepository: &memoryInteractiveRepository{
+		page: models.Page{ID: 1, Slug: "page", Title: "Live", Content: design("Live label"), Visibility: "public", Status: PageStatusPublished},
+	}}
+	svc := NewService(repo, nil)
+
+	title := "Staged"
+	content := design("Staged label")
+	if _, err := svc.SaveDraft(1, 3, DraftInput{Title: &title, Content: &content}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.page.Title != "Live" || strings.Contains(repo.page.Content, "Staged label") {
+		t.Fatalf("draft save reached the live page: %#v", repo.page)
+	}
+	if _, err := svc.SubmitInteractive(1, 7, 5, "Voter", "key", map[string]interface{}{"choice": "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	published, err := svc.Publish(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published.Title != "Staged" || !strings.Contains(published.Content, "Staged label") {
+		t.Fatalf("publish did not promote the draft: %#v", published)
+	}
+	svc.SanitizeInteractivePage(published, 5, false)
+	if !strings.Contains(published.Content, `\"user_id\":5`) {
+		t.Fatalf("publish dropped a live response: %s", published.Content)
+	}
+	if _, err := svc.GetDraft(1); !errors.Is(err, ErrDraftNotFound) {
+		t.Fatalf("draft survived publish: %v", err)
+	}
+}
+
+func TestApplyDueSchedulesUnpublishesAndDropsInvalidPublishes(t *testing.T) {
+	repo := &memoryPublishingRepository{
+		memoryInteractiveRepository: &memoryInteractiveRepository{
+			page: models.Page{ID: 1, Slug: "page", Content: "[]", Visibility: "public", Status: PageStatusPublished},
+		},
+		draft: &models.PageDraft{PageID: 1, Content: "not json"},
+		due: []ScheduledTransition{
+			{PageID: 1, Action: SchedulePublish},
+			{PageID: 1, Action: ScheduleUnpublish},
+		},
+	}
+	changed := NewService(repo, nil).ApplyDueSchedules(time.Now())
+	if len(changed) != 1 || changed[0].Status != PageStatusDraft {
+		t.Fatalf("unexpected scheduled changes: %#v", changed)
+	}
+	if len(repo.cleared) != 1 || repo.cleared[0] != SchedulePublish {
+		t.Fatalf("invalid scheduled publish was not cleared: %#v", repo.cleared)
+	}
+}
+
+func TestSetScheduleRejectsUnpublishBeforePublish(t *testing.T) {
+	svc := NewService(&memoryPublishingRepository{memoryInteractiveRepository: &memoryInteractiveRepository{}}, nil)
+	publishAt := time.Now().Add(time.Hour)
+	unpublishAt := publishAt.Add(-time.Minute)
+	if _, err := svc.SetSchedule(1, &publishAt, &unpublishAt); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("SetSchedule error = %v", err)
+	}
+}
+
+func TestGetPreviewHidesDraftPagesFromReaders(t *testing.T) {
+	repo := &memoryInteractiveRepository{page: models.Page{ID: 1, Content: "[]", Visibility: "public", Status: PageStatusDraft}}
+	svc := NewService(repo, nil)
+	if _, err := svc.GetPreview(1, 0, false); !errors.Is(err, ErrPageForbidden) {
+		t.Fatalf("reader preview of draft page returned %v", err)
+	}
+	if _, err := svc.GetPreview(1, 0, true); err != nil {
+		t.Fatalf("admin preview of draft page failed: %v", err)
+	}
+}
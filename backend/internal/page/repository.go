@@ -3,7 +3,9 @@ package page
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"strconv"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/skaia/backend/database"
@@ -23,10 +25,13 @@ func (r *sqlRepository) GetBySlug(slug string) (*models.Page, error) {
 		`SELECT id, slug, title, description, seo_title, seo_description, seo_image, content::text,
 		        owner_id,
 		        COALESCE((SELECT COUNT(*) FROM resource_views WHERE resource='page' AND resource_id=pages.id), 0),
-		        visibility, created_at, updated_at
+		        visibility, status, publish_at, unpublish_at,
+		        EXISTS (SELECT 1 FROM page_drafts d WHERE d.page_id=pages.id AND d.cleared_at IS NULL),
+		        created_at, updated_at
 		 FROM pages WHERE slug = $1 AND deleted_at IS NULL`, slug,
 	).Scan(&p.ID, &p.Slug, &p.Title, &p.Description, &p.SEOTitle, &p.SEODesc, &p.SEOImage,
-		&p.Content, &ownerID, &p.ViewCount, &p.Visibility, &p.CreatedAt, &p.UpdatedAt)
+		&p.Content, &ownerID, &p.ViewCount, &p.Visibility, &p.Status, &p.PublishAt, &p.UnpublishAt, &p.HasDraft,
+		&p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -43,10 +48,13 @@ func (r *sqlRepository) GetByID(id int64) (*models.Page, error) {
 		`SELECT id, slug, title, description, seo_title, seo_description, seo_image, content::text,
 		        owner_id,
 		        COALESCE((SELECT COUNT(*) FROM resource_views WHERE resource='page' AND resource_id=pages.id), 0),
-		        visibility, created_at, updated_at
+		        visibility, status, publish_at, unpublish_at,
+		        EXISTS (SELECT 1 FROM page_drafts d WHERE d.page_id=pages.id AND d.cleared_at IS NULL),
+		        created_at, updated_at
 		 FROM pages WHERE id = $1 AND deleted_at IS NULL`, id,
 	).Scan(&p.ID, &p.Slug, &p.Title, &p.Description, &p.SEOTitle, &p.SEODesc, &p.SEOImage,
-		&p.Content, &ownerID, &p.ViewCount, &p.Visibility, &p.CreatedAt, &p.UpdatedAt)
+		&p.Content, &ownerID, &p.ViewCount, &p.Visibility, &p.Status, &p.PublishAt, &p.UnpublishAt, &p.HasDraft,
+		&p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +69,9 @@ func (r *sqlRepository) List() ([]*models.Page, error) {
 		`SELECT id, slug, title, description, seo_title, seo_description, seo_image, content::text,
 		        owner_id,
 		        COALESCE((SELECT COUNT(*) FROM resource_views WHERE resource='page' AND resource_id=pages.id), 0),
-		        visibility, created_at, updated_at
+		        visibility, status, publish_at, unpublish_at,
+		        EXISTS (SELECT 1 FROM page_drafts d WHERE d.page_id=pages.id AND d.cleared_at IS NULL),
+		        created_at, updated_at
 		 FROM pages WHERE deleted_at IS NULL ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, err
@@ -73,7 +83,8 @@ func (r *sqlRepository) List() ([]*models.Page, error) {
 		p := &models.Page{}
 		var ownerID sql.NullInt64
 		if err := rows.Scan(&p.ID, &p.Slug, &p.Title, &p.Description, &p.SEOTitle, &p.SEODesc, &p.SEOImage,
-			&p.Content, &ownerID, &p.ViewCount, &p.Visibility, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			&p.Content, &ownerID, &p.ViewCount, &p.Visibility, &p.Status, &p.PublishAt, &p.UnpublishAt, &p.HasDraft,
+			&p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, err
 		}
 		if ownerID.Valid {
@@ -85,32 +96,41 @@ func (r *sqlRepository) List() ([]*models.Page, error) {
 }
 
 // writes
-func (r *sqlRepository) Create(p *models.Page) error {
-	return r.db.QueryRow(
-		`INSERT INTO pages (slug, title, description, seo_title, seo_description, seo_image, content, owner_id, visibility)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8, $9)
-			 RETURNING id, created_at, updated_at`,
-		p.Slug, p.Title, p.Description, p.SEOTitle, p.SEODesc, p.SEOImage, p.Content, p.OwnerID, p.Visibility,
-	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
-}
-
-// UpdatePreservingInteractive serializes an ordinary page-builder save with
-// participant writes and preserves the records owned by interactive configs.
-func (r *sqlRepository) UpdatePreservingInteractive(p *models.Page) error {
+func (r *sqlRepository) Create(p *models.Page, authorID int64) error {
 	return database.TransactionalExecutor(context.Background(), r.db, func(exec database.Executor) error {
-		var current string
 		if err := exec.QueryRow(
-			`SELECT content::text FROM pages
-			 WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
-			p.ID,
-		).Scan(&current); err != nil {
+			`INSERT INTO pages (slug, title, description, seo_title, seo_description, seo_image, content, owner_id, visibility, status)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8, $9, $10)
+				 RETURNING id, created_at, updated_at`,
+			p.Slug, p.Title, p.Description, p.SEOTitle, p.SEODesc, p.SEOImage, p.Content, p.OwnerID, p.Visibility, p.Status,
+		).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return err
 		}
-		merged, err := mergeInteractiveRecords(current, p.Content)
+		return insertRevision(exec, p, authorID, nil)
+	})
+}
+
+// Update saves an ordinary page-builder edit and records a revision.
+// Interactive responses live in page_interactive_responses and are untouched.
+func (r *sqlRepository) Update(p *models.Page, authorID int64) error {
+	return r.update(p, authorID, nil)
+}
+
+// RestoreRevision saves p like an ordinary builder save and records which
+// revision it was restored from.
+func (r *sqlRepository) RestoreRevision(p *models.Page, authorID, revisionID int64) error {
+	return r.update(p, authorID, &revisionID)
+}
+
+func (r *sqlRepository) update(p *models.Page, authorID int64, restoredFrom *int64) error {
+	return database.TransactionalExecutor(context.Background(), r.db, func(exec database.Executor) error {
+		current, err := lockPageForRevision(exec, p.ID)
 		if err != nil {
 			return err
 		}
-		p.Content = merged
+		if err := ensureBaselineRevision(exec, current); err != nil {
+			return err
+		}
 		if err := exec.QueryRow(
 			`UPDATE pages
 				 SET slug = $2, title = $3, description = $4,
@@ -122,42 +142,28 @@ func (r *sqlRepository) UpdatePreservingInteractive(p *models.Page) error {
 		).Scan(&p.UpdatedAt); err != nil {
 			return err
 		}
-		return nil
+		return insertRevision(exec, p, authorID, restoredFrom)
 	})
 }
 
-func (r *sqlRepository) UpdateSEO(pageID int64, title, description, image string) error {
-	_, err := r.db.Exec(
-		`UPDATE pages SET seo_title=$2,seo_description=$3,seo_image=$4,updated_at=CURRENT_TIMESTAMP
-		 WHERE id=$1 AND deleted_at IS NULL`,
-		pageID, title, description, image,
-	)
-	return err
-}
-
-// MutateContent locks and rewrites the authoritative pages.content document.
-func (r *sqlRepository) MutateContent(pageID int64, mutate func(string) (string, error)) error {
+func (r *sqlRepository) UpdateSEO(pageID, authorID int64, title, description, image string) error {
 	return database.TransactionalExecutor(context.Background(), r.db, func(exec database.Executor) error {
-		var current string
-		if err := exec.QueryRow(
-			`SELECT content::text FROM pages
-			 WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
-			pageID,
-		).Scan(&current); err != nil {
+		current, err := lockPageForRevision(exec, pageID)
+		if err != nil {
 			return err
 		}
-		next, err := mutate(current)
-		if err != nil {
+		if err := ensureBaselineRevision(exec, current); err != nil {
 			return err
 		}
-		if _, err = exec.Exec(
-			`UPDATE pages SET content = $2::jsonb, updated_at = CURRENT_TIMESTAMP
-			 WHERE id = $1 AND deleted_at IS NULL`,
-			pageID, next,
+		if _, err := exec.Exec(
+			`UPDATE pages SET seo_title=$2,seo_description=$3,seo_image=$4,updated_at=CURRENT_TIMESTAMP
+			 WHERE id=$1 AND deleted_at IS NULL`,
+			pageID, title, description, image,
 		); err != nil {
 			return err
 		}
-		return nil
+		current.SEOTitle, current.SEODesc, current.SEOImage = title, description, image
+		return insertRevision(exec, current, authorID, nil)
 	})
 }
 
@@ -217,6 +223,251 @@ func (r *sqlRepository) DeleteAll(actorID int64) error {
 	})
 }
 
+// revisions
+const pageRevisionColumns = `r.id, r.page_id, r.author_id, r.slug, r.title, r.description,
+	r.seo_title, r.seo_description, r.seo_image, r.visibility, r.restored_from, r.created_at,
+	u.id, u.username, u.display_name, COALESCE(u.avatar_url, '')`
+
+func lockPageForRevision(exec database.Executor, pageID int64) (*models.Page, error) {
+	p := &models.Page{ID: pageID}
+	err := exec.QueryRow(
+		`SELECT slug, title, description, seo_title, seo_description, seo_image, visibility, content::text
+		 FROM pages WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		pageID,
+	).Scan(&p.Slug, &p.Title, &p.Description, &p.SEOTitle, &p.SEODesc, &p.SEOImage, &p.Visibility, &p.Content)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ensureBaselineRevision snapshots a page that predates revision history so
+// the save about to overwrite it stays recoverable.
+func ensureBaselineRevision(exec database.Executor, current *models.Page) error {
+	var exists bool
+	if err := exec.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM page_revisions WHERE page_id = $1)`, current.ID,
+	).Scan(&exists); err != nil || exists {
+		return err
+	}
+	return insertRevision(exec, current, 0, nil)
+}
+
+func insertRevision(exec database.Executor, p *models.Page, authorID int64, restoredFrom *int64) error {
+	_, err := exec.Exec(
+		`INSERT INTO page_revisions (page_id, author_id, slug, title, description,
+		                             seo_title, seo_description, seo_image, visibility, content, restored_from)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb, $11)`,
+		p.ID, sql.NullInt64{Int64: authorID, Valid: authorID > 0}, p.Slug, p.Title, p.Description,
+		p.SEOTitle, p.SEODesc, p.SEOImage, p.Visibility, ClearInteractiveRecords(p.Content), restoredFrom,
+	)
+	return err
+}
+
+func scanRevision(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*models.PageRevision, error) {
+	rev := &models.PageRevision{}
+	var authorID, restoredFrom, userID sql.NullInt64
+	var username, displayName, avatar sql.NullString
+	dest := []interface{}{&rev.ID, &rev.PageID, &authorID, &rev.Slug, &rev.Title, &rev.Description,
+		&rev.SEOTitle, &rev.SEODesc, &rev.SEOImage, &rev.Visibility, &restoredFrom, &rev.CreatedAt,
+		&userID, &username, &displayName, &avatar}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if authorID.Valid {
+		rev.AuthorID = &authorID.Int64
+	}
+	if restoredFrom.Valid {
+		rev.RestoredFrom = &restoredFrom.Int64
+	}
+	if userID.Valid {
+		rev.Author = &models.PageUser{
+			ID:          userID.Int64,
+			Username:    username.String,
+			DisplayName: displayName.String,
+			AvatarURL:   avatar.String,
+		}
+	}
+	return rev, nil
+}
+
+func (r *sqlRepository) ListRevisions(pageID int64, limit, offset int) ([]*models.PageRevision, error) {
+	rows, err := r.db.Query(
+		`SELECT `+pageRevisionColumns+`
+		 FROM page_revisions r
+		 JOIN pages p ON p.id = r.page_id AND p.deleted_at IS NULL
+		 LEFT JOIN users u ON u.id = r.author_id
+		 WHERE r.page_id = $1
+		 ORDER BY r.id DESC
+		 LIMIT $2 OFFSET $3`, pageID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var revisions []*models.PageRevision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *sqlRepository) GetRevision(pageID, revisionID int64) (*models.PageRevision, error) {
+	var content string
+	rev, err := scanRevision(r.db.QueryRow(
+		`SELECT `+pageRevisionColumns+`, r.content::text
+		 FROM page_revisions r
+		 JOIN pages p ON p.id = r.page_id AND p.deleted_at IS NULL
+		 LEFT JOIN users u ON u.id = r.author_id
+		 WHERE r.page_id = $1 AND r.id = $2`, pageID, revisionID,
+	), &content)
+	if err != nil {
+		return nil, err
+	}
+	rev.Content = content
+	return rev, nil
+}
+
+// drafts & publishing
+func (r *sqlRepository) GetDraft(pageID int64) (*models.PageDraft, error) {
+	d := &models.PageDraft{}
+	var authorID sql.NullInt64
+	err := r.db.QueryRow(
+		`SELECT d.page_id, d.author_id, d.title, d.description, d.content::text, d.created_at, d.updated_at
+		 FROM page_drafts d
+		 JOIN pages p ON p.id = d.page_id AND p.deleted_at IS NULL
+		 WHERE d.page_id = $1 AND d.cleared_at IS NULL`, pageID,
+	).Scan(&d.PageID, &authorID, &d.Title, &d.Description, &d.Content, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if authorID.Valid {
+		d.AuthorID = &authorID.Int64
+	}
+	return d, nil
+}
+
+func (r *sqlRepository) SaveDraft(d *models.PageDraft) error {
+	return r.db.QueryRow(
+		`INSERT INTO page_drafts (page_id, author_id, title, description, content)
+		 SELECT $1, $2, $3, $4, $5::jsonb
+		 WHERE EXISTS (SELECT 1 FROM pages WHERE id=$1 AND deleted_at IS NULL)
+		 ON CONFLICT (page_id) DO UPDATE
+		 SET author_id=EXCLUDED.author_id, title=EXCLUDED.title, description=EXCLUDED.description,
+		     content=EXCLUDED.content, updated_at=NOW(), cleared_at=NULL,
+		     created_at=CASE WHEN page_drafts.cleared_at IS NULL THEN page_drafts.created_at ELSE NOW() END
+		 RETURNING created_at, updated_at`,
+		d.PageID, d.AuthorID, d.Title, d.Description, d.Content,
+	).Scan(&d.CreatedAt, &d.UpdatedAt)
+}
+
+func (r *sqlRepository) DiscardDraft(pageID int64) error {
+	_, err := r.db.Exec(
+		`UPDATE page_drafts SET cleared_at=COALESCE(cleared_at, NOW())
+		 WHERE page_id=$1 AND cleared_at IS NULL`,
+		pageID,
+	)
+	return err
+}
+
+// Publish marks the page published and, when draft is non-nil, promotes it
+// through the same locked path as a builder save. The draft
+// is only cleared if nobody saved over it while it was being validated.
+func (r *sqlRepository) Publish(p *models.Page, authorID int64, draft *models.PageDraft) error {
+	return database.TransactionalExecutor(context.Background(), r.db, func(exec database.Executor) error {
+		current, err := lockPageForRevision(exec, p.ID)
+		if err != nil {
+			return err
+		}
+		if draft == nil {
+			_, err := exec.Exec(
+				`UPDATE pages SET status='published', publish_at=NULL, updated_at=CURRENT_TIMESTAMP
+				 WHERE id=$1 AND deleted_at IS NULL`,
+				p.ID,
+			)
+			return err
+		}
+		if err := ensureBaselineRevision(exec, current); err != nil {
+			return err
+		}
+		if err := exec.QueryRow(
+			`UPDATE pages
+			 SET title=$2, description=$3, content=$4::jsonb,
+			     status='published', publish_at=NULL, updated_at=CURRENT_TIMESTAMP
+			 WHERE id=$1 AND deleted_at IS NULL
+			 RETURNING updated_at`,
+			p.ID, p.Title, p.Description, p.Content,
+		).Scan(&p.UpdatedAt); err != nil {
+			return err
+		}
+		if err := insertRevision(exec, p, authorID, nil); err != nil {
+			return err
+		}
+		_, err = exec.Exec(
+			`UPDATE page_drafts SET cleared_at=NOW()
+			 WHERE page_id=$1 AND cleared_at IS NULL AND updated_at=$2`,
+			p.ID, draft.UpdatedAt,
+		)
+		return err
+	})
+}
+
+func (r *sqlRepository) Unpublish(pageID int64) error {
+	_, err := r.db.Exec(
+		`UPDATE pages SET status='draft', unpublish_at=NULL, updated_at=CURRENT_TIMESTAMP
+		 WHERE id=$1 AND deleted_at IS NULL`,
+		pageID,
+	)
+	return err
+}
+
+func (r *sqlRepository) SetSchedule(pageID int64, publishAt, unpublishAt *time.Time) error {
+	_, err := r.db.Exec(
+		`UPDATE pages SET publish_at=$2, unpublish_at=$3
+		 WHERE id=$1 AND deleted_at IS NULL`,
+		pageID, publishAt, unpublishAt,
+	)
+	return err
+}
+
+func (r *sqlRepository) ClearSchedule(pageID int64, action string) error {
+	column := "publish_at"
+	if action == ScheduleUnpublish {
+		column = "unpublish_at"
+	}
+	_, err := r.db.Exec(`UPDATE pages SET `+column+`=NULL WHERE id=$1`, pageID)
+	return err
+}
+
+func (r *sqlRepository) DueSchedules(now time.Time) ([]ScheduledTransition, error) {
+	rows, err := r.db.Query(
+		`SELECT id, 'publish', publish_at FROM pages
+		 WHERE publish_at <= $1 AND deleted_at IS NULL
+		 UNION ALL
+		 SELECT id, 'unpublish', unpublish_at FROM pages
+		 WHERE unpublish_at <= $1 AND deleted_at IS NULL
+		 ORDER BY 3, 1`,
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var due []ScheduledTransition
+	for rows.Next() {
+		var t ScheduledTransition
+		if err := rows.Scan(&t.PageID, &t.Action, &t.At); err != nil {
+			return nil, err
+		}
+		due = append(due, t)
+	}
+	return due, rows.Err()
+}
+
 // ownership & editors
 func (r *sqlRepository) SetOwner(pageID, ownerID int64) error {
 	_, err := r.db.Exec(
@@ -335,13 +586,13 @@ func (r *sqlRepository) BrowsePages(options BrowseOptions) (*BrowseResult, error
 	}
 
 	rows, err := r.db.Query(
-		`SELECT p.id, p.slug, p.title, p.description, p.visibility, p.owner_id,
+		`SELECT p.id, p.slug, p.title, p.description, p.visibility, p.status, p.owner_id,
 		        p.created_at, p.updated_at,
 		        u.id, u.username, u.display_name, COALESCE(u.avatar_url, '')
 		 FROM pages p
 		 LEFT JOIN users u ON u.id = p.owner_id
 		 WHERE p.deleted_at IS NULL
-		   AND ($1 OR p.visibility <> 'private' OR p.owner_id = $2 OR EXISTS (
+		   AND ($1 OR (p.visibility <> 'private' AND p.status = 'published') OR p.owner_id = $2 OR EXISTS (
 		       SELECT 1 FROM page_editors pe
 		       WHERE pe.page_id = p.id AND pe.user_id = $2 AND pe.inactive_at IS NULL
 		   ))
@@ -365,7 +616,7 @@ func (r *sqlRepository) BrowsePages(options BrowseOptions) (*BrowseResult, error
 		var ownerID, ownerUserID sql.NullInt64
 		var ownerUsername, ownerDisplayName, ownerAvatar sql.NullString
 		if err := rows.Scan(
-			&page.ID, &page.Slug, &page.Title, &page.Description, &page.Visibility,
+			&page.ID, &page.Slug, &page.Title, &page.Description, &page.Visibility, &page.Status,
 			&ownerID, &page.CreatedAt, &page.UpdatedAt,
 			&ownerUserID, &ownerUsername, &ownerDisplayName, &ownerAvatar,
 		); err != nil {
@@ -729,3 +980,407 @@ func (r *sqlRepository) GetNoreplyUserID() (int64, error) {
 	err := r.db.QueryRow(`SELECT id FROM users WHERE username='noreply' AND deleted_at IS NULL LIMIT 1`).Scan(&id)
 	return id, err
 }
+
+// interactive responses
+const interactiveResponseColumns = `id, user_id, respondent_name, answers::text, status, answer, pinned, submitted_at, updated_at`
+
+func scanInteractiveResponse(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*InteractiveRecord, error) {
+	rec := &InteractiveRecord{}
+	var userID sql.NullInt64
+	var updatedAt sql.NullTime
+	var answers string
+	dest := []interface{}{&rec.ID, &userID, &rec.RespondentName, &answers, &rec.Status, &rec.Answer, &rec.Pinned, &rec.SubmittedAt, &updatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	rec.UserID = userID.Int64
+	if updatedAt.Valid {
+		rec.UpdatedAt = updatedAt.Time
+	}
+	if err := json.Unmarshal([]byte(answers), &rec.Answers); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// CreateInteractiveResponse stores rec unless the participant already
+// submitted with the same idempotency key, in which case that response is
+// returned. The page row lock serialises submissions so the limit check
+// cannot race.
+func (r *sqlRepository) CreateInteractiveResponse(pageID, sectionID int64, rec *InteractiveRecord, limit int) (*InteractiveRecord, error) {
+	var stored *InteractiveRecord
+	err := database.TransactionalExecutor(context.Background(), r.db, func(exec database.Executor) error {
+		var locked int64
+		if err := exec.QueryRow(
+			`SELECT id FROM pages WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, pageID,
+		).Scan(&locked); err != nil {
+			return err
+		}
+		if rec.IdempotencyKeyHash != "" {
+			existing, err := scanInteractiveResponse(exec.QueryRow(
+				`SELECT `+interactiveResponseColumns+` FROM page_interactive_responses
+				 WHERE page_id=$1 AND section_id=$2 AND user_id=$3 AND idempotency_key_hash=$4 AND deleted_at IS NULL`,
+				pageID, sectionID, rec.UserID, rec.IdempotencyKeyHash,
+			))
+			if err == nil {
+				stored = existing
+				return nil
+			}
+			if err != sql.ErrNoRows {
+				return err
+			}
+		}
+		if limit > 0 {
+			var own int
+			if err := exec.QueryRow(
+				`SELECT COUNT(*) FROM page_interactive_responses
+				 WHERE page_id=$1 AND section_id=$2 AND user_id=$3 AND deleted_at IS NULL`,
+				pageID, sectionID, rec.UserID,
+			).Scan(&own); err != nil {
+				return err
+			}
+			if own >= limit {
+				return ErrInteractiveDuplicate
+			}
+		}
+		answers, err := json.Marshal(rec.Answers)
+		if err != nil {
+			return err
+		}
+		if _, err := exec.Exec(
+			`INSERT INTO page_interactive_responses
+			     (id, page_id, section_id, user_id, respondent_name, answers, status, idempotency_key_hash, submitted_at)
+			 VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8, $9)`,
+			rec.ID, pageID, sectionID, sql.NullInt64{Int64: rec.UserID, Valid: rec.UserID > 0},
+			rec.RespondentName, string(answers), rec.Status, rec.IdempotencyKeyHash, rec.SubmittedAt,
+		); err != nil {
+			return err
+		}
+		stored = rec
+		return nil
+	})
+	return stored, err
+}
+
+func (r *sqlRepository) GetInteractiveResponse(pageID, sectionID int64, recordID string) (*InteractiveRecord, error) {
+	return scanInteractiveResponse(r.db.QueryRow(
+		`SELECT `+interactiveResponseColumns+` FROM page_interactive_responses
+		 WHERE page_id=$1 AND section_id=$2 AND id=$3 AND deleted_at IS NULL`,
+		pageID, sectionID, recordID,
+	))
+}
+
+// UpdateInteractiveResponse writes the moderation fields of rec.
+func (r *sqlRepository) UpdateInteractiveResponse(pageID, sectionID int64, rec *InteractiveRecord) error {
+	res, err := r.db.Exec(
+		`UPDATE page_interactive_responses
+		 SET status=$4, answer=$5, pinned=$6, updated_at=$7
+		 WHERE page_id=$1 AND section_id=$2 AND id=$3 AND deleted_at IS NULL`,
+		pageID, sectionID, rec.ID, rec.Status, rec.Answer, rec.Pinned, rec.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *sqlRepository) DeleteInteractiveResponse(pageID, sectionID int64, recordID string, actorID int64) error {
+	res, err := r.db.Exec(
+		`UPDATE page_interactive_responses
+		 SET deleted_at=NOW(), deleted_by=$4
+		 WHERE page_id=$1 AND section_id=$2 AND id=$3 AND deleted_at IS NULL`,
+		pageID, sectionID, recordID, sql.NullInt64{Int64: actorID, Valid: actorID > 0},
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListInteractiveResponses returns one page of a section's responses, newest
+// first, with the section's total response count.
+func (r *sqlRepository) ListInteractiveResponses(pageID, sectionID int64, limit, offset int) ([]*InteractiveRecord, int, error) {
+	rows, err := r.db.Query(
+		`SELECT `+interactiveResponseColumns+`, COUNT(*) OVER ()
+		 FROM page_interactive_responses
+		 WHERE page_id=$1 AND section_id=$2 AND deleted_at IS NULL
+		 ORDER BY submitted_at DESC, id DESC
+		 LIMIT $3 OFFSET $4`,
+		pageID, sectionID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var records []*InteractiveRecord
+	total := 0
+	for rows.Next() {
+		rec, err := scanInteractiveResponse(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if len(records) == 0 && offset > 0 {
+		if err := r.db.QueryRow(
+			`SELECT COUNT(*) FROM page_interactive_responses
+			 WHERE page_id=$1 AND section_id=$2 AND deleted_at IS NULL`,
+			pageID, sectionID,
+		).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+	return records, total, nil
+}
+
+// ListInteractiveResponsesAfter returns up to limit of a section's responses
+// that follow after in (submitted_at, id) order, oldest first; a nil after
+// starts at the beginning. Paging by key keeps deletes and new submissions
+// from shifting rows between pages.
+func (r *sqlRepository) ListInteractiveResponsesAfter(pageID, sectionID int64, after *InteractiveRecord, limit int) ([]*InteractiveRecord, error) {
+	var afterAt time.Time
+	afterID := ""
+	if after != nil {
+		afterAt, afterID = after.SubmittedAt, after.ID
+	}
+	rows, err := r.db.Query(
+		`SELECT `+interactiveResponseColumns+`
+		 FROM page_interactive_responses
+		 WHERE page_id=$1 AND section_id=$2 AND deleted_at IS NULL
+		   AND (submitted_at, id) > ($3, $4)
+		 ORDER BY submitted_at, id
+		 LIMIT $5`,
+		pageID, sectionID, afterAt, afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []*InteractiveRecord
+	for rows.Next() {
+		rec, err := scanInteractiveResponse(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// PageInteractiveRecords returns, grouped by section id and oldest first, the
+// live responses a page view embeds: viewerID's own and the published or
+// answered questions of the publicQA sections, at most limit of each per
+// section with pinned and recent rows kept first.
+func (r *sqlRepository) PageInteractiveRecords(pageID, viewerID int64, publicQA []int64, limit int) (map[int64][]*InteractiveRecord, error) {
+	rows, err := r.db.Query(
+		`SELECT `+interactiveResponseColumns+`, section_id
+		 FROM (
+		     SELECT *, ROW_NUMBER() OVER (
+		         PARTITION BY section_id, COALESCE(user_id = $2, FALSE)
+		         ORDER BY pinned DESC, submitted_at DESC, id DESC) AS n
+		     FROM page_interactive_responses
+		     WHERE page_id=$1 AND deleted_at IS NULL
+		       AND (user_id = $2 OR (section_id = ANY($3) AND status IN ('published', 'answered')))
+		 ) visible
+		 WHERE n <= $4
+		 ORDER BY section_id, submitted_at, id`,
+		pageID, viewerID, pq.Array(publicQA), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	bySection := map[int64][]*InteractiveRecord{}
+	for rows.Next() {
+		var sectionID int64
+		rec, err := scanInteractiveResponse(rows, &sectionID)
+		if err != nil {
+			return nil, err
+		}
+		bySection[sectionID] = append(bySection[sectionID], rec)
+	}
+	return bySection, rows.Err()
+}
+
+// PageInteractiveSummaries counts every live response on a page per section
+// and, for the field keys listed in aggregate, how often each answer was
+// given. Array answers count once per element.
+func (r *sqlRepository) PageInteractiveSummaries(pageID int64, aggregate map[int64][]string) (map[int64]*InteractiveSummary, error) {
+	summaries := map[int64]*InteractiveSummary{}
+	summary := func(sectionID int64) *InteractiveSummary {
+		if summaries[sectionID] == nil {
+			summaries[sectionID] = &InteractiveSummary{Counts: map[string]map[string]int{}}
+		}
+		return summaries[sectionID]
+	}
+	rows, err := r.db.Query(
+		`SELECT section_id, COUNT(*) FROM page_interactive_responses
+		 WHERE page_id=$1 AND deleted_at IS NULL
+		 GROUP BY section_id`,
+		pageID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sectionID int64
+		var total int
+		if err := rows.Scan(&sectionID, &total); err != nil {
+			return nil, err
+		}
+		summary(sectionID).Total = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var fields []string
+	for sectionID, keys := range aggregate {
+		for _, key := range keys {
+			fields = append(fields, strconv.FormatInt(sectionID, 10)+":"+key)
+		}
+	}
+	if len(fields) == 0 {
+		return summaries, nil
+	}
+	counted, err := r.db.Query(
+		`SELECT r.section_id, a.key, COALESCE(e.value, a.value) #>> '{}', COUNT(*)
+		 FROM page_interactive_responses r
+		 CROSS JOIN LATERAL jsonb_each(r.answers) AS a
+		 LEFT JOIN LATERAL jsonb_array_elements(
+		     CASE WHEN jsonb_typeof(a.value) = 'array' THEN a.value ELSE '[]'::jsonb END) AS e ON TRUE
+		 WHERE r.page_id=$1 AND r.deleted_at IS NULL
+		   AND r.section_id::text || ':' || a.key = ANY($2)
+		   AND NOT (jsonb_typeof(a.value) = 'array' AND e.value IS NULL)
+		   AND jsonb_typeof(COALESCE(e.value, a.value)) <> 'null'
+		 GROUP BY 1, 2, 3`,
+		pageID, pq.Array(fields),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer counted.Close()
+	for counted.Next() {
+		var sectionID int64
+		var key, label string
+		var count int
+		if err := counted.Scan(&sectionID, &key, &label, &count); err != nil {
+			return nil, err
+		}
+		s := summary(sectionID)
+		if s.Counts[key] == nil {
+			s.Counts[key] = map[string]int{}
+		}
+		s.Counts[key][label] = count
+	}
+	return summaries, counted.Err()
+}
+
+// template gallery
+const pageTemplateColumns = `t.id, t.name, t.description, t.category,
+	ARRAY(SELECT DISTINCT section->>'section_type'
+	      FROM jsonb_array_elements(CASE WHEN jsonb_typeof(t.bundle->'content') = 'array'
+	                                     THEN t.bundle->'content' ELSE '[]'::jsonb END) AS section
+	      WHERE section->>'section_type' IS NOT NULL
+	      ORDER BY 1),
+	t.created_by, t.created_at, t.updated_at`
+
+func scanTemplate(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*models.PageTemplate, error) {
+	t := &models.PageTemplate{}
+	var createdBy sql.NullInt64
+	var sectionTypes pq.StringArray
+	dest := []interface{}{&t.ID, &t.Name, &t.Description, &t.Category, &sectionTypes, &createdBy, &t.CreatedAt, &t.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	t.SectionTypes = []string(sectionTypes)
+	if t.SectionTypes == nil {
+		t.SectionTypes = []string{}
+	}
+	if createdBy.Valid {
+		t.CreatedBy = &createdBy.Int64
+	}
+	return t, nil
+}
+
+func (r *sqlRepository) CreateTemplate(t *models.PageTemplate) error {
+	bundle, err := json.Marshal(t.Bundle)
+	if err != nil {
+		return err
+	}
+	var createdBy sql.NullInt64
+	if t.CreatedBy != nil {
+		createdBy = sql.NullInt64{Int64: *t.CreatedBy, Valid: true}
+	}
+	return r.db.QueryRow(
+		`INSERT INTO page_templates (name, description, category, bundle, created_by)
+		 VALUES ($1, $2, $3, $4::jsonb, $5)
+		 RETURNING id, created_at, updated_at`,
+		t.Name, t.Description, t.Category, string(bundle), createdBy,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+// ListTemplates returns the gallery without bundles, optionally narrowed to
+// one category.
+func (r *sqlRepository) ListTemplates(category string) ([]*models.PageTemplate, error) {
+	rows, err := r.db.Query(
+		`SELECT `+pageTemplateColumns+`
+		 FROM page_templates t
+		 WHERE t.deleted_at IS NULL AND ($1 = '' OR t.category = $1)
+		 ORDER BY t.category, LOWER(t.name), t.id`, category,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var templates []*models.PageTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+func (r *sqlRepository) GetTemplate(id int64) (*models.PageTemplate, error) {
+	var bundle string
+	t, err := scanTemplate(r.db.QueryRow(
+		`SELECT `+pageTemplateColumns+`, t.bundle::text
+		 FROM page_templates t
+		 WHERE t.id = $1 AND t.deleted_at IS NULL`, id,
+	), &bundle)
+	if err != nil {
+		return nil, err
+	}
+	t.Bundle = &models.PageBundle{}
+	if err := json.Unmarshal([]byte(bundle), t.Bundle); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *sqlRepository) DeleteTemplate(id, actorID int64) error {
+	res, err := r.db.Exec(
+		`UPDATE page_templates SET deleted_at = NOW(), deleted_by = $2
+		 WHERE id = $1 AND deleted_at IS NULL`,
+		id, sql.NullInt64{Int64: actorID, Valid: actorID > 0},
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
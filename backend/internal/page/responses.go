@@ -0,0 +1,520 @@
+package page
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInteractiveExportFormat = errors.New("export format must be csv, json or xlsx")
+
+const (
+	DefaultInteractiveResponseLimit = 50
+	MaxInteractiveResponseLimit     = 200
+
+	// interactiveReportSamples bounds the recent free-text answers a report
+	// quotes per question.
+	interactiveReportSamples = 5
+)
+
+// InteractiveResponseList is one page of a section's responses, newest first.
+type InteractiveResponseList struct {
+	Records []*InteractiveRecord `json:"records"`
+	Total   int                  `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
+
+// InteractiveExport is a rendered response export ready to be downloaded.
+type InteractiveExport struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// InteractiveReport aggregates every live response of one section.
+type InteractiveReport struct {
+	PageID           int64                       `json:"page_id"`
+	SectionID        int64                       `json:"section_id"`
+	SectionType      string                      `json:"section_type"`
+	Total            int                         `json:"total"`
+	Statuses         map[string]int              `json:"statuses"`
+	FirstSubmittedAt *time.Time                  `json:"first_submitted_at,omitempty"`
+	LastSubmittedAt  *time.Time                  `json:"last_submitted_at,omitempty"`
+	Questions        []InteractiveQuestionReport `json:"questions"`
+}
+
+// InteractiveQuestionReport summarises the answers to one configured field.
+// Which of Options, Numeric, NPS and Samples are set depends on the field type.
+type InteractiveQuestionReport struct {
+	Key      string                   `json:"key"`
+	Label    string                   `json:"label"`
+	Type     string                   `json:"type"`
+	Answered int                      `json:"answered"`
+	Skipped  int                      `json:"skipped"`
+	Options  []InteractiveOptionCount `json:"options,omitempty"`
+	Numeric  *InteractiveNumericStats `json:"numeric,omitempty"`
+	NPS      *float64                 `json:"nps,omitempty"`
+	Samples  []string                 `json:"samples,omitempty"`
+}
+
+// InteractiveOptionCount is how many respondents picked an option. Percent is
+// relative to the respondents who answered the question.
+type InteractiveOptionCount struct {
+	Key     string  `json:"key"`
+	Label   string  `json:"label"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+type InteractiveNumericStats struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+}
+
+// ListInteractiveResponses pages through a section's responses for a page
+// manager.
+func (s *Service) ListInteractiveResponses(pageID, targetSectionID, actorID int64, limit, offset int) (*InteractiveResponseList, error) {
+	if err := s.requireInteractiveManager(pageID, actorID); err != nil {
+		return nil, err
+	}
+	if _, _, err := s.interactiveSection(pageID, targetSectionID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultInteractiveResponseLimit
+	}
+	if limit > MaxInteractiveResponseLimit {
+		limit = MaxInteractiveResponseLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	records, total, err := s.repo.ListInteractiveResponses(pageID, targetSectionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*InteractiveRecord{}
+	}
+	return &InteractiveResponseList{Records: records, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// allInteractiveResponses returns every live response of a section, oldest
+// first. It reads MaxInteractiveResponseLimit rows at a time, keyed on the
+// last row read, so a large section never needs one unbounded query and
+// concurrent deletes cannot shift rows past the reader.
+func (s *Service) allInteractiveResponses(pageID, targetSectionID int64) ([]*InteractiveRecord, error) {
+	var records []*InteractiveRecord
+	var after *InteractiveRecord
+	for {
+		page, err := s.repo.ListInteractiveResponsesAfter(pageID, targetSectionID, after, MaxInteractiveResponseLimit)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+		if len(page) < MaxInteractiveResponseLimit {
+			return records, nil
+		}
+		after = page[len(page)-1]
+	}
+}
+
+// ExportInteractiveResponses renders every response of a section as csv, json
+// or xlsx. Tabular formats get one column per configured field.
+func (s *Service) ExportInteractiveResponses(pageID, targetSectionID, actorID int64, format string) (*InteractiveExport, error) {
+	if err := s.requireInteractiveManager(pageID, actorID); err != nil {
+		return nil, err
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" && format != "xlsx" {
+		return nil, ErrInteractiveExportFormat
+	}
+	cfg, typ, err := s.interactiveSection(pageID, targetSectionID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.allInteractiveResponses(pageID, targetSectionID)
+	if err != nil {
+		return nil, err
+	}
+	export := &InteractiveExport{Filename: fmt.Sprintf("page-%d-section-%d-responses.%s", pageID, targetSectionID, format)}
+	switch format {
+	case "json":
+		export.ContentType = "application/json"
+		export.Data, err = json.MarshalIndent(map[string]interface{}{
+			"page_id":      pageID,
+			"section_id":   targetSectionID,
+			"section_type": typ,
+			"fields":       cfg["fields"],
+			"records":      records,
+		}, "", "  ")
+	case "csv":
+		export.ContentType = "text/csv; charset=utf-8"
+		export.Data, err = encodeResponsesCSV(interactiveExportRows(typ, cfg, records))
+	case "xlsx":
+		export.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		export.Data, err = encodeResponsesXLSX(interactiveExportRows(typ, cfg, records))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// ReportInteractiveResponses aggregates a section's responses per question.
+func (s *Service) ReportInteractiveResponses(pageID, targetSectionID, actorID int64) (*InteractiveReport, error) {
+	if err := s.requireInteractiveManager(pageID, actorID); err != nil {
+		return nil, err
+	}
+	cfg, typ, err := s.interactiveSection(pageID, targetSectionID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.allInteractiveResponses(pageID, targetSectionID)
+	if err != nil {
+		return nil, err
+	}
+	return buildInteractiveReport(pageID, targetSectionID, typ, cfg, records), nil
+}
+
+func interactiveFields(cfg map[string]interface{}) []map[string]interface{} {
+	raw, _ := cfg["fields"].([]interface{})
+	fields := make([]map[string]interface{}, 0, len(raw))
+	for _, item := range raw {
+		if field, ok := item.(map[string]interface{}); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func fieldLabel(field map[string]interface{}) string {
+	if label, _ := field["label"].(string); strings.TrimSpace(label) != "" {
+		return label
+	}
+	key, _ := field["key"].(string)
+	return key
+}
+
+func fieldOptionLabels(field map[string]interface{}) map[string]string {
+	labels := map[string]string{}
+	options, _ := field["options"].([]interface{})
+	for _, raw := range options {
+		option, _ := raw.(map[string]interface{})
+		key, _ := option["key"].(string)
+		label, _ := option["label"].(string)
+		labels[key] = label
+	}
+	return labels
+}
+
+// exportAnswer formats one answer for a spreadsheet cell, showing option
+// labels instead of keys.
+func exportAnswer(field map[string]interface{}, labels map[string]string, value interface{}) string {
+	label := func(key string) string {
+		if text := labels[key]; text != "" {
+			return text
+		}
+		return key
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		if fieldType, _ := field["type"].(string); fieldType == "select" || fieldType == "radio" {
+			return label(v)
+		}
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, label(fmt.Sprint(item)))
+		}
+		return strings.Join(parts, "; ")
+	default:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	}
+}
+
+func interactiveExportRows(typ string, cfg map[string]interface{}, records []*InteractiveRecord) [][]string {
+	fields := interactiveFields(cfg)
+	header := []string{"id", "submitted_at", "updated_at", "user_id", "respondent_name", "status"}
+	if typ == "qa" {
+		header = append(header, "answer", "pinned")
+	}
+	labels := make([]map[string]string, len(fields))
+	for i, field := range fields {
+		header = append(header, fieldLabel(field))
+		labels[i] = fieldOptionLabels(field)
+	}
+	rows := [][]string{header}
+	for _, rec := range records {
+		row := []string{rec.ID, rec.SubmittedAt.UTC().Format(time.RFC3339), "", "", rec.RespondentName, rec.Status}
+		if !rec.UpdatedAt.IsZero() {
+			row[2] = rec.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+		if rec.UserID > 0 {
+			row[3] = strconv.FormatInt(rec.UserID, 10)
+		}
+		if typ == "qa" {
+			row = append(row, rec.Answer, strconv.FormatBool(rec.Pinned))
+		}
+		for i, field := range fields {
+			key, _ := field["key"].(string)
+			row = append(row, exportAnswer(field, labels[i], rec.Answers[key]))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// csvSafeCell stops spreadsheet applications from evaluating respondent text
+// as a formula. Plain numbers are left alone.
+func csvSafeCell(value string) string {
+	if value == "" || strings.IndexAny(value[:1], "=+-@\t\r") < 0 {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+func encodeResponsesCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, row := range rows {
+		safe := make([]string, len(row))
+		for i, cell := range row {
+			safe[i] = csvSafeCell(cell)
+		}
+		if err := w.Write(safe); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// encodeResponsesXLSX writes a single-sheet workbook using inline strings,
+// which is all a response export needs and avoids a spreadsheet dependency.
+func encodeResponsesXLSX(rows [][]string) ([]byte, error) {
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			fmt.Fprintf(&sheet, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumn(c), r+1)
+			if err := xml.EscapeText(&sheet, []byte(cell)); err != nil {
+				return nil, err
+			}
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Responses" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func roundTenth(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+func buildInteractiveReport(pageID, targetSectionID int64, typ string, cfg map[string]interface{}, records []*InteractiveRecord) *InteractiveReport {
+	report := &InteractiveReport{
+		PageID: pageID, SectionID: targetSectionID, SectionType: typ,
+		Total: len(records), Statuses: map[string]int{},
+		Questions: []InteractiveQuestionReport{},
+	}
+	for _, rec := range records {
+		report.Statuses[rec.Status]++
+		submitted := rec.SubmittedAt
+		if report.FirstSubmittedAt == nil || submitted.Before(*report.FirstSubmittedAt) {
+			report.FirstSubmittedAt = &submitted
+		}
+		if report.LastSubmittedAt == nil || submitted.After(*report.LastSubmittedAt) {
+			report.LastSubmittedAt = &submitted
+		}
+	}
+	for _, field := range interactiveFields(cfg) {
+		key, _ := field["key"].(string)
+		fieldType, _ := field["type"].(string)
+		q := InteractiveQuestionReport{Key: key, Label: fieldLabel(field), Type: fieldType}
+		var values []interface{}
+		for _, rec := range records {
+			// An unchecked box is still an answer, unlike an empty string.
+			value := rec.Answers[key]
+			if _, isBool := value.(bool); isBool || answerPresent(value) {
+				values = append(values, value)
+			}
+		}
+		q.Answered = len(values)
+		q.Skipped = len(records) - q.Answered
+		switch fieldType {
+		case "select", "radio", "multi_select":
+			q.Options = reportOptions(field, values)
+		case "checkbox", "consent":
+			yes := 0
+			for _, value := range values {
+				if checked, _ := value.(bool); checked {
+					yes++
+				}
+			}
+			q.Options = []InteractiveOptionCount{
+				{Key: "true", Label: "Yes", Count: yes},
+				{Key: "false", Label: "No", Count: q.Answered - yes},
+			}
+			for i := range q.Options {
+				if q.Answered > 0 {
+					q.Options[i].Percent = roundTenth(float64(q.Options[i].Count) * 100 / float64(q.Answered))
+				}
+			}
+		case "number", "rating", "scale", "nps":
+			q.Numeric, q.NPS = reportNumeric(fieldType, values)
+		default:
+			for i := len(values) - 1; i >= 0 && len(q.Samples) < interactiveReportSamples; i-- {
+				if text, _ := values[i].(string); strings.TrimSpace(text) != "" {
+					q.Samples = append(q.Samples, text)
+				}
+			}
+		}
+		report.Questions = append(report.Questions, q)
+	}
+	return report
+}
+
+func reportOptions(field map[string]interface{}, values []interface{}) []InteractiveOptionCount {
+	options, _ := field["options"].([]interface{})
+	counts := make([]InteractiveOptionCount, 0, len(options))
+	index := map[string]int{}
+	for _, raw := range options {
+		option, _ := raw.(map[string]interface{})
+		key, _ := option["key"].(string)
+		label, _ := option["label"].(string)
+		index[key] = len(counts)
+		counts = append(counts, InteractiveOptionCount{Key: key, Label: label})
+	}
+	for _, value := range values {
+		picked := []interface{}{value}
+		if list, ok := value.([]interface{}); ok {
+			picked = list
+		}
+		for _, item := range picked {
+			key := fmt.Sprint(item)
+			i, ok := index[key]
+			if !ok {
+				// An option removed after responses were collected still counts.
+				i = len(counts)
+				index[key] = i
+				counts = append(counts, InteractiveOptionCount{Key: key, Label: key})
+			}
+			counts[i].Count++
+		}
+	}
+	for i := range counts {
+		if len(values) > 0 {
+			counts[i].Percent = roundTenth(float64(counts[i].Count) * 100 / float64(len(values)))
+		}
+	}
+	return counts
+}
+
+func reportNumeric(fieldType string, values []interface{}) (*InteractiveNumericStats, *float64) {
+	numbers := make([]float64, 0, len(values))
+	for _, value := range values {
+		if number, ok := value.(float64); ok {
+			numbers = append(numbers, number)
+		}
+	}
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+	sort.Float64s(numbers)
+	sum := 0.0
+	promoters, detractors := 0, 0
+	for _, number := range numbers {
+		sum += number
+		if number >= 9 {
+			promoters++
+		} else if number <= 6 {
+			detractors++
+		}
+	}
+	median := numbers[len(numbers)/2]
+	if len(numbers)%2 == 0 {
+		median = (numbers[len(numbers)/2-1] + median) / 2
+	}
+	stats := &InteractiveNumericStats{
+		Min: numbers[0], Max: numbers[len(numbers)-1],
+		Mean: math.Round(sum/float64(len(numbers))*100) / 100, Median: median,
+	}
+	if fieldType != "nps" {
+		return stats, nil
+	}
+	nps := roundTenth(float64(promoters-detractors) * 100 / float64(len(numbers)))
+	return stats, &nps
+}
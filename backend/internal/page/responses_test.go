@@ -0,0 +1,155 @@
+package page
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	isecurity "github.com/skaia/backend/internal/security"
+	"github.com/skaia/backend/models"
+)
+
+func surveyResponsesService(t *testing.T) (*Service, *memoryInteractiveRepository) {
+	t.Helper()
+	ownerID := int64(10)
+	repo := &memoryInteractiveRepository{page: models.Page{ID: 1, OwnerID: &ownerID, Content: interactiveContentOfType("survey", `{
+		"status":"open","result_visibility":"never","response_limit":0,
+		"fields":[
+			{"key":"color","type":"multi_select","label":"Colors","options":[{"key":"r","label":"Red"},{"key":"g","label":"Green"}]},
+			{"key":"nps","type":"nps","label":"Recommend"},
+			{"key":"agree","type":"checkbox"},
+			{"key":"note","type":"textarea","label":"Notes"}
+		]
+	}`)}}
+	svc := NewService(repo, nil, WithInteractivePolicy(isecurity.NewPagePolicy(repo, fakePermissionChecker{})))
+	answers := []map[string]interface{}{
+		{"color": []interface{}{"r", "g"}, "nps": float64(10), "agree": true, "note": "=HYPERLINK(\"http://evil\")"},
+		{"color": []interface{}{"r"}, "nps": float64(9), "agree": false},
+		{"nps": float64(3), "note": "Too slow"},
+	}
+	for i, answer := range answers {
+		rec, err := svc.SubmitInteractive(1, 7, int64(20+i), "User", "", answer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.SubmittedAt = time.Date(2026, 1, 1+i, 0, 0, 0, 0, time.UTC)
+	}
+	return svc, repo
+}
+
+func TestInteractiveResponsesRequireManager(t *testing.T) {
+	svc, _ := surveyResponsesService(t)
+	if _, err := svc.ListInteractiveResponses(1, 7, 20, 0, 0); !errors.Is(err, ErrInteractiveForbidden) {
+		t.Fatalf("respondent listed responses: %v", err)
+	}
+	if _, err := svc.ExportInteractiveResponses(1, 7, 20, "csv"); !errors.Is(err, ErrInteractiveForbidden) {
+		t.Fatalf("respondent exported responses: %v", err)
+	}
+	if _, err := svc.ReportInteractiveResponses(1, 7, 20); !errors.Is(err, ErrInteractiveForbidden) {
+		t.Fatalf("respondent read the report: %v", err)
+	}
+	if _, err := svc.ExportInteractiveResponses(1, 7, 10, "pdf"); !errors.Is(err, ErrInteractiveExportFormat) {
+		t.Fatalf("unsupported format accepted: %v", err)
+	}
+}
+
+func TestListInteractiveResponsesPaginatesNewestFirst(t *testing.T) {
+	svc, _ := surveyResponsesService(t)
+	list, err := svc.ListInteractiveResponses(1, 7, 10, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 3 || len(list.Records) != 2 || list.Records[0].UserID != 21 || list.Records[1].UserID != 20 {
+		t.Fatalf("unexpected page: %#v", list)
+	}
+}
+
+func TestExportInteractiveResponsesCSVAndXLSX(t *testing.T) {
+	svc, _ := surveyResponsesService(t)
+	export, err := svc.ExportInteractiveResponses(1, 7, 10, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(export.Data)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || strings.Join(rows[0][6:], ",") != "Colors,Recommend,agree,Notes" {
+		t.Fatalf("unexpected header: %v", rows)
+	}
+	if rows[1][6] != "Red; Green" || rows[1][8] != "yes" || rows[2][8] != "no" {
+		t.Fatalf("answers not rendered with labels: %v", rows[1:])
+	}
+	if !strings.HasPrefix(rows[1][9], "'=") {
+		t.Fatalf("formula was not neutralised: %q", rows[1][9])
+	}
+
+	export, err = svc.ExportInteractiveResponses(1, 7, 10, "xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(export.Data), int64(len(export.Data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			raw, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(raw)
+		}
+	}
+	if !strings.Contains(sheet, `<c r="G2" t="inlineStr"><is><t xml:space="preserve">Red; Green</t>`) || !strings.Contains(sheet, "Too slow") {
+		t.Fatalf("unexpected worksheet: %s", sheet)
+	}
+}
+
+func TestReportInteractiveResponsesAggregatesPerQuestion(t *testing.T) {
+	svc, _ := surveyResponsesService(t)
+	report, err := svc.ReportInteractiveResponses(1, 7, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 3 || report.Statuses["submitted"] != 3 || len(report.Questions) != 4 {
+		t.Fatalf("unexpected totals: %#v", report)
+	}
+	colors := report.Questions[0]
+	if colors.Answered != 2 || colors.Skipped != 1 || colors.Options[0].Count != 2 || colors.Options[0].Percent != 100 || colors.Options[1].Percent != 50 {
+		t.Fatalf("unexpected option counts: %#v", colors)
+	}
+	nps := report.Questions[1]
+	if nps.Numeric == nil || nps.Numeric.Median != 9 || nps.Numeric.Min != 3 || nps.NPS == nil || *nps.NPS != 33.3 {
+		t.Fatalf("unexpected numeric summary: %#v", nps)
+	}
+	agree := report.Questions[2]
+	if agree.Answered != 2 || agree.Options[0].Count != 1 || agree.Options[1].Count != 1 {
+		t.Fatalf("unchecked box was not counted: %#v", agree)
+	}
+	if note := report.Questions[3]; len(note.Samples) != 2 || note.Samples[0] != "Too slow" {
+		t.Fatalf("unexpected text samples: %#v", note.Samples)
+	}
+}
+
+func TestReportInteractiveResponsesReadsEveryPage(t *testing.T) {
+	svc, _ := surveyResponsesService(t)
+	extra := 2*MaxInteractiveResponseLimit + 7
+	for i := 0; i < extra; i++ {
+		if _, err := svc.SubmitInteractive(1, 7, int64(100+i), "User", "", map[string]interface{}{"nps": float64(5)}); err != nil {
+			t.Fatalf("submission %d refused: %v", i, err)
+		}
+	}
+	report, err := svc.ReportInteractiveResponses(1, 7, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 3+extra {
+		t.Fatalf("report covered %d of %d responses", report.Total, 3+extra)
+	}
+}
@@ -0,0 +1,294 @@
+package page
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/skaia/backend/models"
+)
+
+var ErrRevisionNotFound = errors.New("page revision not found")
+
+const (
+	DefaultRevisionLimit = 50
+	MaxRevisionLimit     = 200
+)
+
+// ListRevisions returns page history newest first without revision content.
+func (s *Service) ListRevisions(pageID int64, limit, offset int) ([]*models.PageRevision, error) {
+	if limit <= 0 {
+		limit = DefaultRevisionLimit
+	}
+	if limit > MaxRevisionLimit {
+		limit = MaxRevisionLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	revisions, err := s.repo.ListRevisions(pageID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []*models.PageRevision{}
+	}
+	return revisions, nil
+}
+
+func (s *Service) GetRevision(pageID, revisionID int64) (*models.PageRevision, error) {
+	rev, err := s.repo.GetRevision(pageID, revisionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// DiffRevisions compares two revisions of the same page.
+func (s *Service) DiffRevisions(pageID, fromID, toID int64) (*models.PageRevisionDiff, error) {
+	from, err := s.GetRevision(pageID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetRevision(pageID, toID)
+	if err != nil {
+		return nil, err
+	}
+	return diffPageRevisions(from, to)
+}
+
+// RestoreRevision writes a revision's title, description, SEO fields and
+// content back to the page as a new revision. Slug and visibility stay as they
+// are so a restore never moves the page or changes who can read it.
+// Interactive responses live outside the document and are left as they are.
+func (s *Service) RestoreRevision(pageID, revisionID, actorID int64) (*models.Page, error) {
+	current, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.GetRevision(pageID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	p := *current
+	p.Title = rev.Title
+	p.Description = rev.Description
+	p.SEOTitle = rev.SEOTitle
+	p.SEODesc = rev.SEODesc
+	p.SEOImage = rev.SEOImage
+	p.Content = ClearInteractiveRecords(rev.Content)
+	if p.Content == "" {
+		p.Content = "[]"
+	}
+	if err := normalizePageSEO(&p); err != nil {
+		return nil, err
+	}
+	if err := s.validateContent(p.Content); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RestoreRevision(&p, actorID, revisionID); err != nil {
+		return nil, err
+	}
+	s.invalidateSEO(p.Slug)
+	return s.repo.GetByID(pageID)
+}
+
+func diffPageRevisions(from, to *models.PageRevision) (*models.PageRevisionDiff, error) {
+	diff := &models.PageRevisionDiff{
+		From:     from.ID,
+		To:       to.ID,
+		Fields:   []models.PageFieldChange{},
+		Sections: []models.PageSectionChange{},
+	}
+	for _, field := range []struct {
+		name          string
+		before, after string
+	}{
+		{"slug", from.Slug, to.Slug},
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"seo_title", from.SEOTitle, to.SEOTitle},
+		{"seo_description", from.SEODesc, to.SEODesc},
+		{"seo_image", from.SEOImage, to.SEOImage},
+		{"visibility", from.Visibility, to.Visibility},
+	} {
+		if field.before != field.after {
+			diff.Fields = append(diff.Fields, models.PageFieldChange{Field: field.name, Before: field.before, After: field.after})
+		}
+	}
+
+	before, err := decodeRevisionSections(from.Content)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeRevisionSections(to.Content)
+	if err != nil {
+		return nil, err
+	}
+	beforeIndex := make(map[string]int, len(before))
+	for i, section := range before {
+		beforeIndex[revisionSectionKey(section, i)] = i
+	}
+	afterIndex := make(map[string]int, len(after))
+	for i, section := range after {
+		afterIndex[revisionSectionKey(section, i)] = i
+	}
+
+	for i, section := range before {
+		if _, ok := afterIndex[revisionSectionKey(section, i)]; ok {
+			continue
+		}
+		change, err := sectionChange(section, revisionSectionKey(section, i), "removed")
+		if err != nil {
+			return nil, err
+		}
+		change.FromIndex = intPtr(i)
+		diff.Sections = append(diff.Sections, change)
+	}
+
+	var beforeOrder, afterOrder []string
+	for i, section := range before {
+		if _, ok := afterIndex[revisionSectionKey(section, i)]; ok {
+			beforeOrder = append(beforeOrder, revisionSectionKey(section, i))
+		}
+	}
+	for i, section := range after {
+		if _, ok := beforeIndex[revisionSectionKey(section, i)]; ok {
+			afterOrder = append(afterOrder, revisionSectionKey(section, i))
+		}
+	}
+	stable := longestCommonOrder(beforeOrder, afterOrder)
+
+	for i, section := range after {
+		key := revisionSectionKey(section, i)
+		j, existed := beforeIndex[key]
+		if !existed {
+			change, err := sectionChange(section, key, "added")
+			if err != nil {
+				return nil, err
+			}
+			change.ToIndex = intPtr(i)
+			diff.Sections = append(diff.Sections, change)
+			continue
+		}
+		prior := before[j]
+		kind := ""
+		if !sameSectionBody(prior, section) {
+			kind = "modified"
+		} else if !stable[key] {
+			kind = "moved"
+		}
+		if kind == "" {
+			continue
+		}
+		change, err := sectionChange(section, key, kind)
+		if err != nil {
+			return nil, err
+		}
+		change.FromIndex = intPtr(j)
+		change.ToIndex = intPtr(i)
+		if change.Before, err = json.Marshal(prior); err != nil {
+			return nil, err
+		}
+		diff.Sections = append(diff.Sections, change)
+	}
+	return diff, nil
+}
+
+func decodeRevisionSections(content string) ([]map[string]interface{}, error) {
+	if content == "" {
+		return []map[string]interface{}{}, nil
+	}
+	sections, err := decodePageSections(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+	return sections, nil
+}
+
+// revisionSectionKey identifies a section across revisions. Builder sections
+// carry numeric or string ids; the rare id-less legacy section falls back to
+// its position.
+func revisionSectionKey(section map[string]interface{}, index int) string {
+	switch id := section["id"].(type) {
+	case nil:
+		return fmt.Sprintf("index:%d", index)
+	case string:
+		if id == "" {
+			return fmt.Sprintf("index:%d", index)
+		}
+		return id
+	default:
+		raw, _ := json.Marshal(id)
+		return string(raw)
+	}
+}
+
+// sameSectionBody ignores display_order, which changes whenever a neighbour
+// is inserted or removed; reordering is reported separately.
+func sameSectionBody(a, b map[string]interface{}) bool {
+	strip := func(section map[string]interface{}) string {
+		copySection := make(map[string]interface{}, len(section))
+		for k, v := range section {
+			if k != "display_order" {
+				copySection[k] = v
+			}
+		}
+		raw, _ := json.Marshal(copySection)
+		return string(raw)
+	}
+	return strip(a) == strip(b)
+}
+
+func sectionChange(section map[string]interface{}, key, kind string) (models.PageSectionChange, error) {
+	typ, _ := section["section_type"].(string)
+	heading, _ := section["heading"].(string)
+	change := models.PageSectionChange{SectionID: key, SectionType: typ, Heading: heading, Change: kind}
+	raw, err := json.Marshal(section)
+	if err != nil {
+		return change, err
+	}
+	if kind == "removed" {
+		change.Before = raw
+	} else {
+		change.After = raw
+	}
+	return change, nil
+}
+
+// longestCommonOrder marks the sections that kept their relative order, so a
+// single drag reports one moved section instead of every shifted neighbour.
+func longestCommonOrder(a, b []string) map[string]bool {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	stable := make(map[string]bool, lengths[0][0])
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			stable[a[i]] = true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return stable
+}
+
+func intPtr(v int) *int { return &v }
@@ -0,0 +1,107 @@
+package page
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/skaia/backend/models"
+)
+
+func TestDiffPageRevisionsReportsFieldAndSectionChanges(t *testing.T) {
+	from := &models.PageRevision{ID: 1, Title: "Old", SEOTitle: "Search", Content: `[
+		{"id":1,"display_order":1,"section_type":"hero","heading":"Hero","config":"{}"},
+		{"id":2,"display_order":2,"section_type":"rich_text","heading":"Body","config":"{\"html\":\"a\"}"},
+		{"id":3,"display_order":3,"section_type":"cta","heading":"Removed","config":"{}"}
+	]`}
+	to := &models.PageRevision{ID: 2, Title: "New", SEOTitle: "Search", Content: `[
+		{"id":4,"display_order":1,"section_type":"cta","heading":"Added","config":"{}"},
+		{"id":1,"display_order":2,"section_type":"hero","heading":"Hero","config":"{}"},
+		{"id":2,"display_order":3,"section_type":"rich_text","heading":"Body","config":"{\"html\":\"b\"}"}
+	]`}
+	diff, err := diffPageRevisions(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Fields) != 1 || diff.Fields[0].Field != "title" || diff.Fields[0].After != "New" {
+		t.Fatalf("unexpected field changes: %#v", diff.Fields)
+	}
+	changes := map[string]string{}
+	for _, change := range diff.Sections {
+		changes[change.SectionID] = change.Change
+	}
+	want := map[string]string{"3": "removed", "4": "added", "2": "modified"}
+	if len(changes) != len(want) {
+		t.Fatalf("section changes = %#v, want %#v", changes, want)
+	}
+	for id, kind := range want {
+		if changes[id] != kind {
+			t.Errorf("section %s change = %q, want %q", id, changes[id], kind)
+		}
+	}
+}
+
+func TestDiffPageRevisionsReportsOnlyTheMovedSection(t *testing.T) {
+	from := &models.PageRevision{ID: 1, Content: `[
+		{"id":1,"section_type":"hero"},{"id":2,"section_type":"cta"},
+		{"id":3,"section_type":"faq"},{"id":4,"section_type":"gallery"}
+	]`}
+	to := &models.PageRevision{ID: 2, Content: `[
+		{"id":2,"section_type":"cta"},{"id":3,"section_type":"faq"},
+		{"id":4,"section_type":"gallery"},{"id":1,"section_type":"hero"}
+	]`}
+	diff, err := diffPageRevisions(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Sections) != 1 || diff.Sections[0].SectionID != "1" || diff.Sections[0].Change != "moved" {
+		t.Fatalf("unexpected move diff: %#v", diff.Sections)
+	}
+}
+
+func TestRestoreRevisionKeepsLiveRecordsAndSlug(t *testing.T) {
+	design := func(label string) string {
+		return interactiveContent(`{
+			"status":"open","result_visibility":"never","response_limit":1,
+			"fields":[{"key":"choice","type":"radio","label":"` + label + `","options":[{"key":"a","label":"A"}]}],
+			"records":[]
+		}`)
+	}
+	repo := &memoryInteractiveRepository{}
+	svc := NewService(repo, nil)
+	p := &models.Page{Slug: "page", Title: "First", Content: design("First label"), Visibility: "public"}
+	if err := svc.Create(p, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Update(&models.Page{ID: p.ID, Slug: "renamed", Title: "Second", Content: design("Second label"), Visibility: "public"}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SubmitInteractive(p.ID, 7, 5, "Voter", "key", map[string]interface{}{"choice": "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := svc.RestoreRevision(p.ID, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Title != "First" || restored.Slug != "renamed" {
+		t.Fatalf("restore applied wrong fields: title %q slug %q", restored.Title, restored.Slug)
+	}
+	svc.SanitizeInteractivePage(restored, 5, false)
+	if !strings.Contains(restored.Content, "First label") || !strings.Contains(restored.Content, `\"user_id\":5`) {
+		t.Fatalf("restore lost design or live response: %s", restored.Content)
+	}
+	last := repo.revisions[len(repo.revisions)-1]
+	if last.RestoredFrom == nil || *last.RestoredFrom != 1 || last.AuthorID == nil || *last.AuthorID != 4 {
+		t.Fatalf("restore revision metadata = %#v", last)
+	}
+	for _, rev := range repo.revisions {
+		if strings.Contains(rev.Content, "user_id") {
+			t.Fatalf("revision %d stored participant records: %s", rev.ID, rev.Content)
+		}
+	}
+
+	if _, err := svc.RestoreRevision(p.ID, 99, 4); !errors.Is(err, ErrRevisionNotFound) {
+		t.Fatalf("missing revision restore returned %v", err)
+	}
+}
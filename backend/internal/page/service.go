@@ -122,6 +122,10 @@ type Service struct {
 	contentResolver   s_registry.Resolver
 	rdb               *redis.Client
 	interactivePolicy InteractivePolicy
+
+	bundleDataSources    BundleDataSources
+	bundleCustomSections BundleCustomSections
+	bundleAssets         BundleAssets
 }
 
 // NewService creates a new page Service.
@@ -232,11 +236,11 @@ func (s *Service) GetPreview(pageID, actorID int64, isAdmin bool) (*models.PageP
 		isEditor, editorErr := s.repo.IsEditor(pageID, actorID)
 		if editorErr == nil {
 			canManage = isEditor
-		} else if page.Visibility == "private" {
+		} else if page.Visibility == "private" || IsDraft(page) {
 			return nil, ErrPageForbidden
 		}
 	}
-	if page.Visibility == "private" && !canManage {
+	if (page.Visibility == "private" || IsDraft(page)) && !canManage {
 		return nil, ErrPageForbidden
 	}
 	s.SanitizeInteractivePage(page, actorID, canManage)
@@ -268,7 +272,7 @@ func (s *Service) invalidateSEO(slug string) {
 	}
 }
 
-func (s *Service) Create(p *models.Page) error {
+func (s *Service) Create(p *models.Page, actorID int64) error {
 	if p.Content == "" {
 		p.Content = "[]"
 	}
@@ -276,20 +280,23 @@ func (s *Service) Create(p *models.Page) error {
 		p.Visibility = "public"
 	}
 	p.Content = ClearInteractiveRecords(p.Content)
+	if err := normalizePageStatus(p); err != nil {
+		return err
+	}
 	if err := normalizePageSEO(p); err != nil {
 		return err
 	}
 	if err := s.validateContent(p.Content); err != nil {
 		return err
 	}
-	if err := s.repo.Create(p); err != nil {
+	if err := s.repo.Create(p, actorID); err != nil {
 		return err
 	}
 	s.invalidateSEO(p.Slug)
 	return nil
 }
 
-func (s *Service) Update(p *models.Page) error {
+func (s *Service) Update(p *models.Page, actorID int64) error {
 	if p.Content == "" {
 		p.Content = "[]"
 	}
@@ -304,7 +311,7 @@ func (s *Service) Update(p *models.Page) error {
 	if err := s.validateContent(p.Content); err != nil {
 		return err
 	}
-	err = s.repo.UpdatePreservingInteractive(p)
+	err = s.repo.Update(p, actorID)
 	if err == nil {
 		if current.Slug != p.Slug {
 			s.invalidateSEO(current.Slug)
@@ -314,7 +321,7 @@ func (s *Service) Update(p *models.Page) error {
 	return err
 }
 
-func (s *Service) UpdateSEO(pageID int64, title, description, image string) (*models.Page, error) {
+func (s *Service) UpdateSEO(pageID, actorID int64, title, description, image string) (*models.Page, error) {
 	p, err := s.repo.GetByID(pageID)
 	if err != nil {
 		return nil, err
@@ -325,7 +332,7 @@ func (s *Service) UpdateSEO(pageID int64, title, description, image string) (*mo
 	if err := normalizePageSEO(p); err != nil {
 		return nil, err
 	}
-	if err := s.repo.UpdateSEO(pageID, p.SEOTitle, p.SEODesc, p.SEOImage); err != nil {
+	if err := s.repo.UpdateSEO(pageID, actorID, p.SEOTitle, p.SEODesc, p.SEOImage); err != nil {
 		return nil, err
 	}
 	s.invalidateSEO(p.Slug)
@@ -340,7 +347,7 @@ func (s *Service) validateContent(content string) error {
 }
 
 // Duplicate creates a copy of an existing page under a new slug.
-func (s *Service) Duplicate(fromID int64, newSlug, newTitle string) (*models.Page, error) {
+func (s *Service) Duplicate(fromID, actorID int64, newSlug, newTitle string) (*models.Page, error) {
 	src, err := s.GetByID(fromID)
 	if err != nil {
 		return nil, fmt.Errorf("source page not found: %w", err)
@@ -358,11 +365,12 @@ func (s *Service) Duplicate(fromID int64, newSlug, newTitle string) (*models.Pag
 		SEOImage:    src.SEOImage,
 		Content:     ClearInteractiveRecords(src.Content),
 		Visibility:  "private",
+		Status:      PageStatusPublished,
 	}
 	if dup.Content == "" {
 		dup.Content = "[]"
 	}
-	if err := s.repo.Create(dup); err != nil {
+	if err := s.repo.Create(dup, actorID); err != nil {
 		return nil, err
 	}
 	s.invalidateSEO(dup.Slug)
@@ -585,9 +593,10 @@ func (s *Service) ClaimPage(userID int64, slug string, isAdmin bool) (*models.Pa
 		Slug:    slug,
 		Title:   "",
 		Content: "[]",
+		Status:  PageStatusPublished,
 		OwnerID: &userID,
 	}
-	if err := s.repo.Create(p); err != nil {
+	if err := s.repo.Create(p, userID); err != nil {
 		return nil, err
 	}
 	if err := s.repo.SetOwner(p.ID, userID); err != nil {
@@ -0,0 +1,113 @@
+package page
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/skaia/backend/models"
+)
+
+var (
+	ErrTemplateNotFound  = errors.New("page template not found")
+	ErrTemplateNameTaken = errors.New("a page template with this name already exists")
+	ErrInvalidTemplate   = errors.New("invalid page template")
+)
+
+// TemplateInput names a gallery entry.
+type TemplateInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+}
+
+func (in TemplateInput) normalize() (TemplateInput, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Description = strings.TrimSpace(in.Description)
+	in.Category = strings.ToLower(strings.TrimSpace(in.Category))
+	if in.Name == "" || len([]rune(in.Name)) > 255 {
+		return in, fmt.Errorf("%w: name must be 1 to 255 characters", ErrInvalidTemplate)
+	}
+	if len([]rune(in.Description)) > 2000 {
+		return in, fmt.Errorf("%w: description must be 2000 characters or fewer", ErrInvalidTemplate)
+	}
+	if len([]rune(in.Category)) > 80 {
+		return in, fmt.Errorf("%w: category must be 80 characters or fewer", ErrInvalidTemplate)
+	}
+	return in, nil
+}
+
+// ListTemplates returns the tenant's template gallery without bundles.
+func (s *Service) ListTemplates(category string) ([]*models.PageTemplate, error) {
+	templates, err := s.repo.ListTemplates(strings.ToLower(strings.TrimSpace(category)))
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []*models.PageTemplate{}
+	}
+	return templates, nil
+}
+
+func (s *Service) GetTemplate(id int64) (*models.PageTemplate, error) {
+	t, err := s.repo.GetTemplate(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	return t, err
+}
+
+// SaveTemplate adds a bundle to the gallery. The bundle must pass the same
+// validation an import does, so every gallery entry can be instantiated.
+func (s *Service) SaveTemplate(in TemplateInput, bundle *models.PageBundle, actorID int64) (*models.PageTemplate, error) {
+	in, err := in.normalize()
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateBundle(bundle); err != nil {
+		return nil, err
+	}
+	t := &models.PageTemplate{
+		Name: in.Name, Description: in.Description, Category: in.Category,
+		SectionTypes: bundleSectionTypes(bundle), Bundle: bundle,
+	}
+	if actorID > 0 {
+		t.CreatedBy = &actorID
+	}
+	if err := s.repo.CreateTemplate(t); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrTemplateNameTaken
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// SaveTemplateFromPage exports a page and adds the bundle to the gallery.
+func (s *Service) SaveTemplateFromPage(pageID int64, in TemplateInput, actorID int64) (*models.PageTemplate, error) {
+	bundle, err := s.ExportBundle(pageID)
+	if err != nil {
+		return nil, err
+	}
+	return s.SaveTemplate(in, bundle, actorID)
+}
+
+// InstantiateTemplate creates a private page from a gallery template.
+func (s *Service) InstantiateTemplate(templateID int64, slug, title string, actorID int64) (*models.Page, error) {
+	t, err := s.GetTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ImportBundle(t.Bundle, slug, title, actorID)
+}
+
+func (s *Service) DeleteTemplate(id, actorID int64) error {
+	err := s.repo.DeleteTemplate(id, actorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTemplateNotFound
+	}
+	return err
+}
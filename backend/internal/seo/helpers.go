@@ -362,7 +362,7 @@ func resolvePageSEO(ctx context.Context, db rowQueryer, slug string) seoResoluti
 
 	var title, desc, seoTitle, seoDesc, seoImage, content string
 	err := db.QueryRowContext(ctx,
-		"SELECT title,description,seo_title,seo_description,seo_image,content::text FROM pages WHERE slug = $1 AND visibility IN ('public', 'unlisted') AND deleted_at IS NULL",
+		"SELECT title,description,seo_title,seo_description,seo_image,content::text FROM pages WHERE slug = $1 AND visibility IN ('public', 'unlisted') AND status = 'published' AND deleted_at IS NULL",
 		slug,
 	).Scan(&title, &desc, &seoTitle, &seoDesc, &seoImage, &content)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -379,7 +379,7 @@ func resolveLandingPageSEO(ctx context.Context, db rowQueryer) seoResolution {
 	err := db.QueryRowContext(ctx, `SELECT p.slug,p.title,p.description,p.seo_title,p.seo_description,p.seo_image,p.content::text
 		FROM site_config sc JOIN pages p ON p.slug=(sc.value #>> '{}')
 		WHERE sc.key='landing_page_slug' AND sc.deleted_at IS NULL
-		AND p.visibility IN ('public','unlisted') AND p.deleted_at IS NULL`).Scan(&slug, &title, &desc, &seoTitle, &seoDesc, &seoImage, &content)
+		AND p.visibility IN ('public','unlisted') AND p.status = 'published' AND p.deleted_at IS NULL`).Scan(&slug, &title, &desc, &seoTitle, &seoDesc, &seoImage, &content)
 	if errors.Is(err, sql.ErrNoRows) {
 		// Sites without a configured public landing page retain their ordinary
 		// global home metadata rather than turning the application shell into a 404.
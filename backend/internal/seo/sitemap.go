@@ -160,6 +160,7 @@ func contentSitemapEntries(ctx context.Context, db *sql.DB) ([]sitemapEntry, err
 				p.updated_at
 			FROM pages p
 			WHERE p.visibility = 'public'
+			  AND p.status = 'published'
 			  AND p.deleted_at IS NULL
 			  AND p.slug NOT IN ('privacy', 'tos')
 
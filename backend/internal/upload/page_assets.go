@@ -0,0 +1,78 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var errNotPageAsset = errors.New("not an uploaded image")
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// PageAssets reads and stores the uploaded images carried by page bundles.
+// Content types are sniffed from the bytes, never taken from the bundle.
+type PageAssets struct{}
+
+// ReadImage loads an image previously served from url.
+func (PageAssets) ReadImage(url string) ([]byte, string, error) {
+	if !strings.HasPrefix(url, "/uploads/") || strings.Contains(url, "..") {
+		return nil, "", errNotPageAsset
+	}
+	fp := "." + url
+	info, err := os.Stat(fp)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.IsDir() || info.Size() > MaxImgSize {
+		return nil, "", errNotPageAsset
+	}
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, "", err
+	}
+	ct := http.DetectContentType(data)
+	if !typeAllowed(ct, AllowedImageTypes) {
+		return nil, "", errNotPageAsset
+	}
+	return data, ct, nil
+}
+
+// SaveImage stores data in userID's image uploads, subject to the same type,
+// size and quota limits as an editor upload, and returns its public URL.
+func (PageAssets) SaveImage(userID int64, data []byte) (string, error) {
+	ct := http.DetectContentType(data)
+	if !typeAllowed(ct, AllowedImageTypes) {
+		return "", errors.New("only JPEG, PNG, WEBP, and GIF images are allowed")
+	}
+	if len(data) > MaxImgSize {
+		return "", fmt.Errorf("image exceeds %s", humanSize(MaxImgSize))
+	}
+	if msg := CheckUserQuota(userID, int64(len(data))); msg != "" {
+		return "", errors.New(msg)
+	}
+	if msg := CheckTotalQuota(int64(len(data))); msg != "" {
+		return "", errors.New(msg)
+	}
+	dir, err := userDir(userID, "images")
+	if err != nil {
+		return "", err
+	}
+	filename := fmt.Sprintf("%d%s", time.Now().UnixNano(), imageExtensions[ct])
+	url, _, err := saveFile(bytes.NewReader(data), dir, filename, userID, "images")
+	return url, err
+}
+
+// DeleteImage removes an image SaveImage stored, which also frees its quota.
+func (PageAssets) DeleteImage(url string) {
+	DeleteUploadFile(url)
+}
@@ -52,6 +52,7 @@ type Client struct {
 	broadcastLimit rateBucket
 	signalLimit    rateBucket
 	mediaLimit     rateBucket
+	collabLimit    rateBucket
 	// lastChatAt tracks when the last global chat message was sent, for slow-mode enforcement.
 	lastChatAt      time.Time
 	chatBudgetRetry time.Duration
@@ -235,6 +236,12 @@ func (c *Client) handleMessage(msg Message) {
 			return
 		}
 		c.Hub.mediaUpdates <- MediaUpdateAction{Client: c, Message: msg}
+	case PageCollabJoin, PageCollabLeave, PageCollabOp, PageCollabLock:
+		if !c.collabLimit.allow() {
+			c.sendClientError("You are sending page edits too quickly.", c.collabLimit.nextAvailable())
+			return
+		}
+		c.handlePageCollab(msg)
 	case GrengoJobAction:
 		c.handleGrengoJobAction(msg)
 	case ApiRequest:
@@ -641,6 +648,48 @@ func (c *Client) handleVoiceControlMsg(msg Message) {
 	}
 }
 
+// handlePageCollab routes collaborative page editing messages to the hub.
+// Membership is checked on join; later messages require an existing join.
+func (c *Client) handlePageCollab(msg Message) {
+	switch msg.Type {
+	case PageCollabJoin, PageCollabLeave:
+		var p struct {
+			PageID int64 `json:"page_id"`
+		}
+		if err := json.Unmarshal(msg.Payload, &p); err != nil || p.PageID <= 0 {
+			return
+		}
+		if msg.Type == PageCollabLeave {
+			c.Hub.leavePageCollab(c, p.PageID)
+			return
+		}
+		if !c.Hub.canMutateAccount(c) {
+			c.sendClientErrorAction("account_provisional", "Page editing requires an established account.", 0)
+			return
+		}
+		if err := c.Hub.joinPageCollab(c, p.PageID); err != nil {
+			if errors.Is(err, ErrCollabDenied) {
+				c.sendClientErrorAction("collab_forbidden", "You are not allowed to edit this page.", 0)
+				return
+			}
+			log.Printf("ws: join page %d collaboration session: %v", p.PageID, err)
+			c.sendClientErrorAction("collab_unavailable", "The page editing session could not be opened.", 0)
+		}
+	case PageCollabOp:
+		var p PageCollabOpPayload
+		if err := json.Unmarshal(msg.Payload, &p); err != nil || p.PageID <= 0 {
+			return
+		}
+		c.Hub.applyPageCollabOp(c, p)
+	case PageCollabLock:
+		var p PageCollabLockPayload
+		if err := json.Unmarshal(msg.Payload, &p); err != nil || p.PageID <= 0 || p.SectionID == "" {
+			return
+		}
+		c.Hub.setPageCollabLock(c, p)
+	}
+}
+
 func validPresenceRoute(route string) bool {
 	if route == "" || len(route) > 256 || route[0] != '/' {
 		return false
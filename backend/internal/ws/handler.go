@@ -144,6 +144,7 @@ func HandleConnection(w http.ResponseWriter, r *http.Request, hub *Hub) {
 		broadcastLimit: newRateBucket(10, 10),
 		signalLimit:    newRateBucket(30, 30),
 		mediaLimit:     newRateBucket(2, 2),
+		collabLimit:    newRateBucket(20, 40),
 	}
 
 	hub.register <- client
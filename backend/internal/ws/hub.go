@@ -190,6 +190,11 @@ type Hub struct {
 	mediaMu     sync.RWMutex
 	mediaRoutes map[string]*MediaState
 
+	// collaborative page editing sessions - protected by collabMu
+	collabMu       sync.Mutex
+	collabSessions map[int64]*pageCollabSession
+	pageCollab     PageCollabBackend
+
 	// clients + subscriptions - protected by mu
 	mu sync.RWMutex
 
@@ -266,6 +271,7 @@ func NewHub() *Hub {
 		mediaUpdates:    make(chan MediaUpdateAction, 256),
 		voiceRoutes:     make(map[string]*VoicePermissions),
 		mediaRoutes:     make(map[string]*MediaState),
+		collabSessions:  make(map[int64]*pageCollabSession),
 		sessions:        make(map[int64]int),
 		chatRings:       make(map[int64]*sessionChatRing),
 		manager: conveyor.CreateManager().
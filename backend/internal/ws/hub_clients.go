@@ -73,6 +73,7 @@ func (h *Hub) handleUnregister(client *Client) {
 	h.connCount.Add(-1)
 	h.mu.Unlock()
 	client.close()
+	h.leaveAllPageCollab(client)
 
 	// Release session slot before acquiring the main lock.
 	h.sessionMu.Lock()
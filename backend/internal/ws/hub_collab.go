@@ -0,0 +1,815 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/skaia/backend/internal/syslog"
+)
+
+var ErrCollabDenied = errors.New("page collaboration denied")
+
+const (
+	// collabHistoryLimit bounds the applied ops kept for transforming late
+	// client ops. A client further behind than this receives a snapshot.
+	collabHistoryLimit = 256
+	// collabLockTTL is how long a section soft lock survives without being
+	// refreshed by another acquire or by an op from its holder.
+	collabLockTTL = 30 * time.Second
+	// collabSaveDelay coalesces bursts of ops into one page save.
+	collabSaveDelay = 2 * time.Second
+	// maxCollabOpBytes caps a single inbound section or field patch.
+	maxCollabOpBytes = 256 << 10
+)
+
+// PageCollabBackend connects collaborative editing sessions to the page
+// service. Load returns the builder document without interactive records and
+// Save writes it back through the ordinary page update path. CanEdit is
+// checked at join and again for every editor before their edits are saved.
+// Validate is optional; CanEdit, Load and Save are required and their
+// absence denies every join.
+type PageCollabBackend struct {
+	CanEdit  func(pageID, userID int64) error
+	Load     func(pageID int64) (string, error)
+	Validate func(content string) error
+	Save     func(pageID, userID int64, content string) error
+}
+
+// PageSectionOp is one section-level edit. Sections are addressed by their
+// builder id; Index is a position in the document the op was based on and is
+// only used by insert and move ("place before the section now at Index").
+// Update merges Fields into the section, so concurrent edits to different
+// fields of one section both survive and the later write wins per field.
+type PageSectionOp struct {
+	Kind      string                     `json:"kind"` // "insert", "delete", "move", "update"
+	SectionID string                     `json:"section_id,omitempty"`
+	Index     int                        `json:"index,omitempty"`
+	Section   json.RawMessage            `json:"section,omitempty"`
+	Fields    map[string]json.RawMessage `json:"fields,omitempty"`
+}
+
+// PageCollabOpPayload is an op as sent by a client. BaseVersion is the last
+// session version the client had applied when it produced the op.
+type PageCollabOpPayload struct {
+	PageID      int64         `json:"page_id"`
+	OpID        string        `json:"op_id"`
+	BaseVersion int64         `json:"base_version"`
+	Op          PageSectionOp `json:"op"`
+}
+
+// PageCollabLockPayload acquires or releases a section soft lock.
+type PageCollabLockPayload struct {
+	PageID    int64  `json:"page_id"`
+	SectionID string `json:"section_id"`
+	Action    string `json:"action"` // "acquire", "release"
+}
+
+// PageCollabParticipant is one editor in a session. SectionID is the section
+// whose soft lock the editor holds, if any.
+type PageCollabParticipant struct {
+	UserID        int64      `json:"user_id"`
+	UserName      string     `json:"user_name"`
+	Avatar        string     `json:"avatar"`
+	SectionID     string     `json:"section_id,omitempty"`
+	LockExpiresAt *time.Time `json:"lock_expires_at,omitempty"`
+}
+
+type collabSection struct {
+	key    string
+	fields map[string]json.RawMessage
+}
+
+// appliedSectionOp records where an op actually removed (From) and inserted
+// (To) a section, which is all later ops need to be transformed past it.
+type appliedSectionOp struct {
+	op   PageSectionOp
+	from int
+	to   int
+}
+
+type collabParticipant struct {
+	userID   int64
+	userName string
+	avatar   string
+}
+
+// collabSave is session content waiting to be written, credited to the one
+// editor whose ops produced it.
+type collabSave struct {
+	editorID int64
+	content  string
+}
+
+type sectionLock struct {
+	client    *Client
+	userID    int64
+	expiresAt time.Time
+}
+
+// pageCollabSession is the authoritative in-memory document for one page
+// while at least one editor is joined. Lock order is saveMu, Hub.collabMu,
+// then mu.
+//
+// Unsaved edits are kept per editor: when an op arrives from someone other
+// than the editor of the dirty document, the document as it stood is queued
+// in pending under the previous editor, so each save records a revision for
+// the person whose edits it holds.
+type pageCollabSession struct {
+	mu           sync.Mutex
+	pageID       int64
+	version      int64
+	sections     []collabSection
+	history      []appliedSectionOp
+	participants map[*Client]*collabParticipant
+	locks        map[string]*sectionLock
+	dirty        bool
+	editorID     int64
+	pending      []collabSave
+	// epoch counts reloads from the stored page. A flush that captured
+	// content under an older epoch must not write it back.
+	epoch     int64
+	saveTimer *time.Timer
+	saveMu    sync.Mutex // serialises Save calls
+}
+
+// SetPageCollab installs the page service adapter used by collaborative
+// editing sessions. It must be configured before clients are accepted.
+func (h *Hub) SetPageCollab(backend PageCollabBackend) {
+	h.pageCollab = backend
+}
+
+func (h *Hub) joinPageCollab(client *Client, pageID int64) error {
+	backend := h.pageCollab
+	if client == nil || client.UserID <= 0 || pageID <= 0 ||
+		backend.CanEdit == nil || backend.Load == nil || backend.Save == nil {
+		return ErrCollabDenied
+	}
+	if err := backend.CanEdit(pageID, client.UserID); err != nil {
+		return ErrCollabDenied
+	}
+
+	h.collabMu.Lock()
+	s := h.collabSessions[pageID]
+	if s == nil {
+		h.collabMu.Unlock()
+		content, err := backend.Load(pageID)
+		if err != nil {
+			return err
+		}
+		sections, err := decodeCollabSections(content)
+		if err != nil {
+			return err
+		}
+		h.collabMu.Lock()
+		if s = h.collabSessions[pageID]; s == nil {
+			s = &pageCollabSession{
+				pageID:       pageID,
+				sections:     sections,
+				participants: make(map[*Client]*collabParticipant),
+				locks:        make(map[string]*sectionLock),
+			}
+			h.collabSessions[pageID] = s
+		}
+	}
+	s.mu.Lock()
+	h.collabMu.Unlock()
+	defer s.mu.Unlock()
+
+	h.mu.RLock()
+	participant := &collabParticipant{userID: client.UserID, userName: client.UserName, avatar: client.Avatar}
+	h.mu.RUnlock()
+	s.participants[client] = participant
+	s.sendSnapshot(client)
+	s.broadcastPresence()
+	return nil
+}
+
+func (h *Hub) leavePageCollab(client *Client, pageID int64) {
+	h.collabMu.Lock()
+	s := h.collabSessions[pageID]
+	if s == nil {
+		h.collabMu.Unlock()
+		return
+	}
+	s.mu.Lock()
+	if _, ok := s.participants[client]; !ok {
+		s.mu.Unlock()
+		h.collabMu.Unlock()
+		return
+	}
+	delete(s.participants, client)
+	for key, lock := range s.locks {
+		if lock.client == client {
+			delete(s.locks, key)
+		}
+	}
+	empty := len(s.participants) == 0
+	if empty {
+		delete(h.collabSessions, pageID)
+		if s.saveTimer != nil {
+			s.saveTimer.Stop()
+			s.saveTimer = nil
+		}
+	} else {
+		s.broadcastPresence()
+	}
+	s.mu.Unlock()
+	h.collabMu.Unlock()
+
+	if empty {
+		// The final save writes to the database; keep it off the caller,
+		// which may be the hub's run loop handling a disconnect.
+		go h.flushPageCollab(s)
+	}
+}
+
+// leaveAllPageCollab drops a disconnecting client from every session it
+// joined, saving and closing sessions it was the last editor of.
+func (h *Hub) leaveAllPageCollab(client *Client) {
+	h.collabMu.Lock()
+	var pageIDs []int64
+	for pageID, s := range h.collabSessions {
+		s.mu.Lock()
+		if _, ok := s.participants[client]; ok {
+			pageIDs = append(pageIDs, pageID)
+		}
+		s.mu.Unlock()
+	}
+	h.collabMu.Unlock()
+	for _, pageID := range pageIDs {
+		h.leavePageCollab(client, pageID)
+	}
+}
+
+func (h *Hub) pageCollabSession(client *Client, pageID int64) *pageCollabSession {
+	h.collabMu.Lock()
+	defer h.collabMu.Unlock()
+	s := h.collabSessions[pageID]
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	_, joined := s.participants[client]
+	s.mu.Unlock()
+	if !joined {
+		return nil
+	}
+	return s
+}
+
+// applyPageCollabOp transforms a client op past every op the client had not
+// seen, applies it, acks the sender and relays it to the other editors.
+func (h *Hub) applyPageCollabOp(client *Client, payload PageCollabOpPayload) {
+	s := h.pageCollabSession(client, payload.PageID)
+	if s == nil {
+		client.sendClientErrorAction("collab_not_joined", "Join the page editing session first.", 0)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.participants[client]; !ok {
+		client.sendClientErrorAction("collab_not_joined", "Join the page editing session first.", 0)
+		return
+	}
+	now := time.Now()
+	s.expireLocks(now)
+
+	reject := func(reason string, resync bool) {
+		s.sendAck(client, payload.OpID, s.version, false, reason, nil)
+		if resync {
+			s.sendSnapshot(client)
+		}
+	}
+
+	op := payload.Op
+	if len(op.Section) > maxCollabOpBytes || len(op.Fields) > 64 {
+		reject("op_too_large", false)
+		return
+	}
+	if op.Kind == "insert" {
+		key, err := insertSectionKey(op.Section)
+		if err != nil {
+			reject("invalid_section", false)
+			return
+		}
+		op.SectionID = key
+	}
+
+	historyBase := s.version - int64(len(s.history))
+	if payload.BaseVersion > s.version || payload.BaseVersion < historyBase {
+		reject("stale_version", true)
+		return
+	}
+	for _, prior := range s.history[payload.BaseVersion-historyBase:] {
+		var noop bool
+		var reason string
+		op, noop, reason = transformSectionOp(op, prior)
+		if reason != "" {
+			reject(reason, true)
+			return
+		}
+		if noop {
+			s.sendAck(client, payload.OpID, s.version, true, "", nil)
+			return
+		}
+	}
+	if op.Kind != "insert" {
+		if lock := s.locks[op.SectionID]; lock != nil && lock.client != client {
+			reject("section_locked", false)
+			return
+		}
+	}
+
+	next, applied, reason := applySectionOp(s.sections, op)
+	if reason != "" {
+		reject(reason, true)
+		return
+	}
+	if h.pageCollab.Validate != nil {
+		content, err := encodeCollabSections(next)
+		if err != nil || h.pageCollab.Validate(content) != nil {
+			reject("invalid_content", false)
+			return
+		}
+	}
+
+	if s.dirty && s.editorID != client.UserID {
+		if content, err := encodeCollabSections(s.sections); err == nil {
+			s.pending = append(s.pending, collabSave{editorID: s.editorID, content: content})
+		}
+	}
+	s.sections = next
+	s.version++
+	s.history = append(s.history, applied)
+	if len(s.history) > collabHistoryLimit {
+		s.history = append([]appliedSectionOp(nil), s.history[len(s.history)-collabHistoryLimit:]...)
+	}
+	if lock := s.locks[op.SectionID]; lock != nil && lock.client == client {
+		if op.Kind == "delete" {
+			delete(s.locks, op.SectionID)
+		} else {
+			lock.expiresAt = now.Add(collabLockTTL)
+		}
+	}
+	s.dirty = true
+	s.editorID = client.UserID
+	if s.saveTimer == nil {
+		s.saveTimer = time.AfterFunc(collabSaveDelay, func() { h.flushPageCollab(s) })
+	}
+
+	s.sendAck(client, payload.OpID, s.version, true, "", &op)
+	relay, _ := json.Marshal(map[string]interface{}{
+		"page_id": s.pageID,
+		"version": s.version,
+		"user_id": client.UserID,
+		"op":      op,
+	})
+	msg := &Message{Type: PageCollabOp, UserID: client.UserID, Payload: relay}
+	for other := range s.participants {
+		if other != client {
+			other.queueMessage(msg)
+		}
+	}
+}
+
+// setPageCollabLock acquires or releases a section soft lock. A client holds
+// at most one lock, so acquiring a section releases the previous one.
+func (h *Hub) setPageCollabLock(client *Client, payload PageCollabLockPayload) {
+	s := h.pageCollabSession(client, payload.PageID)
+	if s == nil {
+		client.sendClientErrorAction("collab_not_joined", "Join the page editing session first.", 0)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.participants[client]; !ok {
+		return
+	}
+	now := time.Now()
+	s.expireLocks(now)
+
+	switch payload.Action {
+	case "acquire":
+		if s.sectionIndex(payload.SectionID) < 0 {
+			client.sendClientErrorAction("section_not_found", "That section no longer exists.", 0)
+			return
+		}
+		if lock := s.locks[payload.SectionID]; lock != nil && lock.client != client {
+			client.sendClientErrorAction("section_locked", "Another editor is working on that section.", 0)
+			return
+		}
+		for key, lock := range s.locks {
+			if lock.client == client && key != payload.SectionID {
+				delete(s.locks, key)
+			}
+		}
+		s.locks[payload.SectionID] = &sectionLock{client: client, userID: client.UserID, expiresAt: now.Add(collabLockTTL)}
+	case "release":
+		lock := s.locks[payload.SectionID]
+		if lock == nil || lock.client != client {
+			return
+		}
+		delete(s.locks, payload.SectionID)
+	default:
+		return
+	}
+	s.broadcastPresence()
+}
+
+// ReloadPageCollab replaces a live session's document with the stored page
+// after a save that did not go through the session, such as a builder save,
+// revision restore or publish. The outside save wins: pending session edits
+// are dropped and every editor is resynchronised with a snapshot.
+func (h *Hub) ReloadPageCollab(pageID int64) {
+	h.collabMu.Lock()
+	s := h.collabSessions[pageID]
+	h.collabMu.Unlock()
+	if s == nil || h.pageCollab.Load == nil {
+		return
+	}
+	h.reloadPageCollab(s)
+}
+
+// reloadPageCollab resets s to the stored page and moves it to a new epoch,
+// so a flush that captured content before the reload does not save it.
+func (h *Hub) reloadPageCollab(s *pageCollabSession) {
+	content, err := h.pageCollab.Load(s.pageID)
+	if err != nil {
+		log.Printf("ws: reload page %d collaboration session: %v", s.pageID, err)
+		return
+	}
+	sections, err := decodeCollabSections(content)
+	if err != nil {
+		log.Printf("ws: reload page %d collaboration session: %v", s.pageID, err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sections = sections
+	s.version++
+	s.epoch++
+	s.history = nil
+	s.dirty = false
+	s.pending = nil
+	for key := range s.locks {
+		if s.sectionIndex(key) < 0 {
+			delete(s.locks, key)
+		}
+	}
+	for client := range s.participants {
+		s.sendSnapshot(client)
+	}
+	s.broadcastPresence()
+}
+
+// flushPageCollab writes the session's unsaved edits, one save per editor in
+// the order they were made. Every editor is re-checked with CanEdit first: if
+// one has lost access, they are removed from the session and the unsaved
+// edits are discarded by reloading the stored page. A failed save keeps the
+// remaining edits queued so the next op retries them.
+func (h *Hub) flushPageCollab(s *pageCollabSession) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	s.saveTimer = nil
+	if s.dirty {
+		content, err := encodeCollabSections(s.sections)
+		if err != nil {
+			s.mu.Unlock()
+			log.Printf("ws: save page %d collaboration session: %v", s.pageID, err)
+			return
+		}
+		s.pending = append(s.pending, collabSave{editorID: s.editorID, content: content})
+		s.dirty = false
+	}
+	saves := s.pending
+	s.pending = nil
+	epoch := s.epoch
+	s.mu.Unlock()
+	if len(saves) == 0 {
+		return
+	}
+
+	revoked := make(map[int64]bool)
+	checked := make(map[int64]bool)
+	for _, save := range saves {
+		if checked[save.editorID] {
+			continue
+		}
+		checked[save.editorID] = true
+		if err := h.pageCollab.CanEdit(s.pageID, save.editorID); err != nil {
+			revoked[save.editorID] = true
+		}
+	}
+	if len(revoked) > 0 {
+		if h.dropPageCollabEditors(s, revoked) {
+			h.reloadPageCollab(s)
+		}
+		return
+	}
+
+	for i, save := range saves {
+		s.mu.Lock()
+		stale := s.epoch != epoch
+		s.mu.Unlock()
+		if stale {
+			// An outside save replaced the document after these edits
+			// were captured; they were dropped with the old content.
+			return
+		}
+		err := h.pageCollab.Save(s.pageID, save.editorID, save.content)
+		if err == nil {
+			continue
+		}
+		log.Printf("ws: save page %d collaboration session: %v", s.pageID, err)
+		s.mu.Lock()
+		s.pending = append(append([]collabSave(nil), saves[i:]...), s.pending...)
+		for client := range s.participants {
+			client.sendClientErrorAction("collab_save_failed", "Recent edits could not be saved yet.", 0)
+		}
+		s.mu.Unlock()
+		return
+	}
+}
+
+// dropPageCollabEditors removes the revoked users from s and reports whether
+// anyone is still editing. A session left empty is closed.
+func (h *Hub) dropPageCollabEditors(s *pageCollabSession, revoked map[int64]bool) bool {
+	h.collabMu.Lock()
+	defer h.collabMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client, p := range s.participants {
+		if !revoked[p.userID] {
+			continue
+		}
+		delete(s.participants, client)
+		for key, lock := range s.locks {
+			if lock.client == client {
+				delete(s.locks, key)
+			}
+		}
+		client.sendClientErrorAction("collab_denied", "You can no longer edit this page.", 0)
+	}
+	if len(s.participants) == 0 {
+		if h.collabSessions[s.pageID] == s {
+			delete(h.collabSessions, s.pageID)
+		}
+		if s.saveTimer != nil {
+			s.saveTimer.Stop()
+			s.saveTimer = nil
+		}
+		return false
+	}
+	s.broadcastPresence()
+	return true
+}
+
+// session helpers (callers hold s.mu)
+func (s *pageCollabSession) sectionIndex(key string) int {
+	for i, section := range s.sections {
+		if section.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *pageCollabSession) expireLocks(now time.Time) {
+	for key, lock := range s.locks {
+		if !now.Before(lock.expiresAt) {
+			delete(s.locks, key)
+		}
+	}
+}
+
+func (s *pageCollabSession) sendSnapshot(client *Client) {
+	content, err := encodeCollabSections(s.sections)
+	if err != nil {
+		return
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"page_id": s.pageID,
+		"version": s.version,
+		"content": json.RawMessage(content),
+	})
+	client.queueMessage(&Message{Type: PageCollabSnapshot, Payload: payload})
+}
+
+func (s *pageCollabSession) sendAck(client *Client, opID string, version int64, accepted bool, reason string, op *PageSectionOp) {
+	ack := map[string]interface{}{
+		"page_id":  s.pageID,
+		"op_id":    opID,
+		"version":  version,
+		"accepted": accepted,
+	}
+	if reason != "" {
+		ack["reason"] = reason
+	}
+	if op != nil {
+		ack["op"] = op
+	}
+	payload, _ := json.Marshal(ack)
+	client.queueMessage(&Message{Type: PageCollabAck, Payload: payload})
+}
+
+func (s *pageCollabSession) broadcastPresence() {
+	participants := make([]PageCollabParticipant, 0, len(s.participants))
+	for client, p := range s.participants {
+		entry := PageCollabParticipant{UserID: p.userID, UserName: p.userName, Avatar: p.avatar}
+		for key, lock := range s.locks {
+			if lock.client == client {
+				expiresAt := lock.expiresAt
+				entry.SectionID = key
+				entry.LockExpiresAt = &expiresAt
+			}
+		}
+		participants = append(participants, entry)
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"page_id":      s.pageID,
+		"participants": participants,
+	})
+	msg := &Message{Type: PageCollabPresence, Payload: payload}
+	for client := range s.participants {
+		client.queueMessage(msg)
+	}
+}
+
+// section operational transform
+// transformSectionOp rewrites op so it applies after prior, an op the client
+// had not seen. Positions shift past prior's removal and insertion; ops on a
+// section prior deleted conflict, except a second delete which becomes a no-op.
+func transformSectionOp(op PageSectionOp, prior appliedSectionOp) (PageSectionOp, bool, string) {
+	if prior.op.Kind == "delete" && prior.op.SectionID == op.SectionID {
+		if op.Kind == "delete" {
+			return op, true, ""
+		}
+		return op, false, "section_deleted"
+	}
+	if op.Kind == "insert" && prior.op.Kind == "insert" && prior.op.SectionID == op.SectionID {
+		return op, false, "duplicate_section"
+	}
+	if op.Kind == "insert" || op.Kind == "move" {
+		if prior.from >= 0 && prior.from < op.Index {
+			op.Index--
+		}
+		if prior.to >= 0 && prior.to <= op.Index {
+			op.Index++
+		}
+	}
+	return op, false, ""
+}
+
+// applySectionOp returns the document with op applied, leaving sections
+// untouched so a rejected op needs no rollback.
+func applySectionOp(sections []collabSection, op PageSectionOp) ([]collabSection, appliedSectionOp, string) {
+	applied := appliedSectionOp{op: op, from: -1, to: -1}
+	index := -1
+	for i, section := range sections {
+		if section.key == op.SectionID {
+			index = i
+			break
+		}
+	}
+	next := make([]collabSection, 0, len(sections)+1)
+
+	switch op.Kind {
+	case "insert":
+		if index >= 0 {
+			return nil, applied, "duplicate_section"
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(op.Section, &fields); err != nil {
+			return nil, applied, "invalid_section"
+		}
+		at := clampIndex(op.Index, len(sections))
+		next = append(next, sections[:at]...)
+		next = append(next, collabSection{key: op.SectionID, fields: fields})
+		next = append(next, sections[at:]...)
+		applied.to = at
+	case "delete":
+		if index < 0 {
+			return nil, applied, "section_not_found"
+		}
+		next = append(next, sections[:index]...)
+		next = append(next, sections[index+1:]...)
+		applied.from = index
+	case "move":
+		if index < 0 {
+			return nil, applied, "section_not_found"
+		}
+		at := clampIndex(op.Index, len(sections))
+		if index < at {
+			at--
+		}
+		moved := sections[index]
+		next = append(next, sections[:index]...)
+		next = append(next, sections[index+1:]...)
+		next = append(next[:at], append([]collabSection{moved}, next[at:]...)...)
+		applied.from = index
+		applied.to = at
+	case "update":
+		if index < 0 {
+			return nil, applied, "section_not_found"
+		}
+		fields := make(map[string]json.RawMessage, len(sections[index].fields)+len(op.Fields))
+		for k, v := range sections[index].fields {
+			fields[k] = v
+		}
+		for k, v := range op.Fields {
+			if k == "id" || k == "display_order" {
+				continue
+			}
+			if !json.Valid(v) {
+				return nil, applied, "invalid_section"
+			}
+			fields[k] = v
+		}
+		next = append(next, sections...)
+		next[index] = collabSection{key: op.SectionID, fields: fields}
+	default:
+		return nil, applied, "invalid_op"
+	}
+	return next, applied, ""
+}
+
+func clampIndex(index, length int) int {
+	if index < 0 {
+		return 0
+	}
+	if index > length {
+		return length
+	}
+	return index
+}
+
+func decodeCollabSections(content string) ([]collabSection, error) {
+	if content == "" {
+		return []collabSection{}, nil
+	}
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("decode page content: %w", err)
+	}
+	sections := make([]collabSection, 0, len(raw))
+	for i, fields := range raw {
+		key := collabSectionKey(fields["id"])
+		if key == "" {
+			key = "index:" + strconv.Itoa(i)
+		}
+		sections = append(sections, collabSection{key: key, fields: fields})
+	}
+	return sections, nil
+}
+
+// encodeCollabSections renders the session document as page content,
+// renumbering display_order to match the session order.
+func encodeCollabSections(sections []collabSection) (string, error) {
+	out := make([]map[string]json.RawMessage, 0, len(sections))
+	for i, section := range sections {
+		fields := make(map[string]json.RawMessage, len(section.fields)+1)
+		for k, v := range section.fields {
+			fields[k] = v
+		}
+		fields["display_order"] = json.RawMessage(strconv.Itoa(i + 1))
+		out = append(out, fields)
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// collabSectionKey identifies a section by its builder id: the string itself
+// for string ids, the JSON literal for numeric ones.
+func collabSectionKey(id json.RawMessage) string {
+	id = bytes.TrimSpace(id)
+	if len(id) == 0 || bytes.Equal(id, []byte("null")) {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(id, &s); err == nil {
+		return s
+	}
+	return string(id)
+}
+
+func insertSectionKey(section json.RawMessage) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(section, &fields); err != nil {
+		return "", err
+	}
+	key := collabSectionKey(fields["id"])
+	if key == "" {
+		return "", errors.New("section id is required")
+	}
+	return key, nil
+}
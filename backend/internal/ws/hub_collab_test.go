@@ -0,0 +1,331 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type collabTestPage struct {
+	mu      sync.Mutex
+	content string
+	saves   []string
+	authors []int64
+	editors map[int64]bool
+	// onCanEdit runs inside CanEdit, between a flush capturing content
+	// and saving it.
+	onCanEdit func()
+}
+
+func (p *collabTestPage) setContent(content string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.content = content
+}
+
+func (p *collabTestPage) savedBy() ([]string, []int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.saves...), append([]int64(nil), p.authors...)
+}
+
+func newCollabTestHub(page *collabTestPage) *Hub {
+	hub := NewHub()
+	hub.SetPageCollab(PageCollabBackend{
+		CanEdit: func(pageID, userID int64) error {
+			page.mu.Lock()
+			allowed, hook := page.editors[userID], page.onCanEdit
+			page.mu.Unlock()
+			if hook != nil {
+				hook()
+			}
+			if pageID == 1 && allowed {
+				return nil
+			}
+			return ErrCollabDenied
+		},
+		Load: func(int64) (string, error) {
+			page.mu.Lock()
+			defer page.mu.Unlock()
+			return page.content, nil
+		},
+		Save: func(_, userID int64, content string) error {
+			page.mu.Lock()
+			defer page.mu.Unlock()
+			page.saves = append(page.saves, content)
+			page.authors = append(page.authors, userID)
+			page.content = content
+			return nil
+		},
+	})
+	return hub
+}
+
+// waitForSaves waits for the final save a last leave starts in the
+// background.
+func waitForSaves(t *testing.T, page *collabTestPage, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		saves, _ := page.savedBy()
+		if len(saves) >= n || time.Now().After(deadline) {
+			return saves
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newCollabTestClient(hub *Hub, userID int64) *Client {
+	client := newSecurityTestClient(hub, userID)
+	client.Send = make(chan []byte, 64)
+	return client
+}
+
+func drainCollab(t *testing.T, client *Client) []Message {
+	t.Helper()
+	var out []Message
+	for {
+		select {
+		case data := <-client.Send:
+			msg, err := decodeProtoMessage(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msg)
+		default:
+			return out
+		}
+	}
+}
+
+func lastCollab(t *testing.T, client *Client, messageType MessageType) map[string]json.RawMessage {
+	t.Helper()
+	var payload map[string]json.RawMessage
+	for _, msg := range drainCollab(t, client) {
+		if msg.Type == messageType {
+			payload = nil
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if payload == nil {
+		t.Fatalf("no %s message received", messageType)
+	}
+	return payload
+}
+
+func sectionOrder(t *testing.T, hub *Hub) string {
+	t.Helper()
+	s := hub.collabSessions[1]
+	keys := make([]string, 0, len(s.sections))
+	for _, section := range s.sections {
+		keys = append(keys, section.key)
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestPageCollabTransformsConcurrentInsertsAndMoves(t *testing.T) {
+	page := &collabTestPage{
+		content: `[{"id":1,"section_type":"hero"},{"id":2,"section_type":"cta"},{"id":3,"section_type":"faq"}]`,
+		editors: map[int64]bool{7: true, 8: true},
+	}
+	hub := newCollabTestHub(page)
+	alice, bob := newCollabTestClient(hub, 7), newCollabTestClient(hub, 8)
+	for _, client := range []*Client{alice, bob} {
+		if err := hub.joinPageCollab(client, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both clients edit version 0: Alice inserts before "faq", Bob moves
+	// "hero" to the end and then inserts at the front, unaware of Alice.
+	hub.applyPageCollabOp(alice, PageCollabOpPayload{PageID: 1, OpID: "a1", BaseVersion: 0,
+		Op: PageSectionOp{Kind: "insert", Index: 2, Section: json.RawMessage(`{"id":4,"section_type":"gallery"}`)}})
+	hub.applyPageCollabOp(bob, PageCollabOpPayload{PageID: 1, OpID: "b1", BaseVersion: 0,
+		Op: PageSectionOp{Kind: "move", SectionID: "1", Index: 3}})
+	hub.applyPageCollabOp(bob, PageCollabOpPayload{PageID: 1, OpID: "b2", BaseVersion: 0,
+		Op: PageSectionOp{Kind: "insert", Index: 0, Section: json.RawMessage(`{"id":5,"section_type":"cta"}`)}})
+
+	if got := sectionOrder(t, hub); got != "5,2,4,3,1" {
+		t.Fatalf("section order = %s, want 5,2,4,3,1", got)
+	}
+	ack := lastCollab(t, bob, PageCollabAck)
+	if string(ack["accepted"]) != "true" || string(ack["version"]) != "3" {
+		t.Fatalf("unexpected ack: %s %s", ack["accepted"], ack["version"])
+	}
+	relay := lastCollab(t, alice, PageCollabOp)
+	if string(relay["version"]) != "3" || string(relay["user_id"]) != "8" {
+		t.Fatalf("unexpected relay: %v", relay)
+	}
+}
+
+func TestPageCollabRejectsDeletedAndLockedSections(t *testing.T) {
+	page := &collabTestPage{
+		content: `[{"id":1,"heading":"One"},{"id":2,"heading":"Two"}]`,
+		editors: map[int64]bool{7: true, 8: true},
+	}
+	hub := newCollabTestHub(page)
+	alice, bob := newCollabTestClient(hub, 7), newCollabTestClient(hub, 8)
+	hub.joinPageCollab(alice, 1)
+	hub.joinPageCollab(bob, 1)
+
+	hub.applyPageCollabOp(alice, PageCollabOpPayload{PageID: 1, OpID: "a1", BaseVersion: 0,
+		Op: PageSectionOp{Kind: "delete", SectionID: "2"}})
+	drainCollab(t, bob)
+	hub.applyPageCollabOp(bob, PageCollabOpPayload{PageID: 1, OpID: "b1", BaseVersion: 0,
+		Op: PageSectionOp{Kind: "update", SectionID: "2", Fields: map[string]json.RawMessage{"heading": json.RawMessage(`"Late"`)}}})
+	ack := lastCollab(t, bob, PageCollabAck)
+	if string(ack["accepted"]) != "false" || string(ack["reason"]) != `"section_deleted"` {
+		t.Fatalf("concurrent edit of deleted section was not rejected: %v", ack)
+	}
+
+	hub.setPageCollabLock(alice, PageCollabLockPayload{PageID: 1, SectionID: "1", Action: "acquire"})
+	presence := lastCollab(t, bob, PageCollabPresence)
+	if !strings.Contains(string(presence["participants"]), `"section_id":"1"`) {
+		t.Fatalf("presence does not show the held section: %s", presence["participants"])
+	}
+	hub.applyPageCollabOp(bob, PageCollabOpPayload{PageID: 1, OpID: "b2", BaseVersion: 1,
+		Op: PageSectionOp{Kind: "update", SectionID: "1", Fields: map[string]json.RawMessage{"heading": json.RawMessage(`"Bob"`)}}})
+	ack = lastCollab(t, bob, PageCollabAck)
+	if string(ack["reason"]) != `"section_locked"` {
+		t.Fatalf("edit of a section locked by another editor was not rejected: %v", ack)
+	}
+	hub.applyPageCollabOp(alice, PageCollabOpPayload{PageID: 1, OpID: "a2", BaseVersion: 1,
+		Op: PageSectionOp{Kind: "update", SectionID: "1", Fields: map[string]json.RawMessage{"heading": json.RawMessage(`"Alice"`)}}})
+	if ack := lastCollab(t, alice, PageCollabAck); string(ack["accepted"]) != "true" {
+		t.Fatalf("lock holder edit rejected: %v", ack)
+	}
+}
+
+func TestPageCollabJoinRequiresEditorAndLastLeaveSaves(t *testing.T) {
+	page := &collabTestPage{
+		content: `[{"id":1,"heading":"One","display_order":1}]`,
+		editors: map[int64]bool{7: true},
+	}
+	hub := newCollabTestHub(page)
+	editor, reader := newCollabTestClient(hub, 7), newCollabTestClient(hub, 9)
+	if err := hub.joinPageCollab(reader, 1); !errors.Is(err, ErrCollabDenied) {
+		t.Fatalf("non-editor join returned %v", err)
+	}
+	if err := hub.joinPageCollab(newCollabTestClient(hub, 0), 1); !errors.Is(err, ErrCollabDenied) {
+		t.Fatalf("guest join returned %v", err)
+	}
+	if err := hub.joinPageCollab(editor, 1); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := lastCollab(t, editor, PageCollabSnapshot)
+	if string(snapshot["version"]) != "0" || !strings.Contains(string(snapshot["content"]), `"One"`) {
+		t.Fatalf("unexpected snapshot: %v", snapshot)
+	}
+
+	hub.applyPageCollabOp(reader, PageCollabOpPayload{PageID: 1, OpID: "r1",
+		Op: PageSectionOp{Kind: "delete", SectionID: "1"}})
+	if len(hub.collabSessions[1].sections) != 1 {
+		t.Fatal("op from a client outside the session was applied")
+	}
+	hub.applyPageCollabOp(editor, PageCollabOpPayload{PageID: 1, OpID: "e1",
+		Op: PageSectionOp{Kind: "insert", Index: 0, Section: json.RawMessage(`{"id":"intro","heading":"Intro"}`)}})
+
+	hub.leaveAllPageCollab(editor)
+	if _, open := hub.collabSessions[1]; open {
+		t.Fatal("session outlived its last editor")
+	}
+	saves := waitForSaves(t, page, 1)
+	if len(saves) != 1 {
+		t.Fatalf("closing the session saved %d times, want 1", len(saves))
+	}
+	if !strings.Contains(saves[0], `"display_order":1,"heading":"Intro","id":"intro"`) {
+		t.Fatalf("saved content was not renumbered: %s", saves[0])
+	}
+}
+
+func TestPageCollabSavesEachEditorsEditsSeparately(t *testing.T) {
+	page := &collabTestPage{
+		content: `[{"id":1,"heading":"One"}]`,
+		editors: map[int64]bool{7: true, 8: true},
+	}
+	hub := newCollabTestHub(page)
+	alice, bob := newCollabTestClient(hub, 7), newCollabTestClient(hub, 8)
+	hub.joinPageCollab(alice, 1)
+	hub.joinPageCollab(bob, 1)
+
+	update := func(client *Client, version int64, heading string) {
+		hub.applyPageCollabOp(client, PageCollabOpPayload{PageID: 1, OpID: heading, BaseVersion: version,
+			Op: PageSectionOp{Kind: "update", SectionID: "1", Fields: map[string]json.RawMessage{"heading": json.RawMessage(`"` + heading + `"`)}}})
+	}
+	update(alice, 0, "A1")
+	update(alice, 1, "A2")
+	update(bob, 2, "B1")
+	update(alice, 3, "A3")
+	hub.flushPageCollab(hub.collabSessions[1])
+
+	saves, authors := page.savedBy()
+	if len(authors) != 3 || authors[0] != 7 || authors[1] != 8 || authors[2] != 7 {
+		t.Fatalf("saves credited to %v, want [7 8 7]", authors)
+	}
+	for i, want := range []string{"A2", "B1", "A3"} {
+		if !strings.Contains(saves[i], `"heading":"`+want+`"`) {
+			t.Fatalf("save %d = %s, want heading %s", i, saves[i], want)
+		}
+	}
+}
+
+func TestPageCollabFlushDropsEditorsWhoLostAccess(t *testing.T) {
+	page := &collabTestPage{
+		content: `[{"id":1,"heading":"One"}]`,
+		editors: map[int64]bool{7: true, 8: true},
+	}
+	hub := newCollabTestHub(page)
+	alice, bob := newCollabTestClient(hub, 7), newCollabTestClient(hub, 8)
+	hub.joinPageCollab(alice, 1)
+	hub.joinPageCollab(bob, 1)
+
+	page.mu.Lock()
+	delete(page.editors, 8)
+	page.mu.Unlock()
+	hub.applyPageCollabOp(bob, PageCollabOpPayload{PageID: 1, OpID: "b1",
+		Op: PageSectionOp{Kind: "delete", SectionID: "1"}})
+	hub.flushPageCollab(hub.collabSessions[1])
+
+	if saves, _ := page.savedBy(); len(saves) != 0 {
+		t.Fatalf("edits of a revoked editor were saved: %v", saves)
+	}
+	s := hub.collabSessions[1]
+	if _, joined := s.participants[bob]; joined || len(s.participants) != 1 {
+		t.Fatal("revoked editor is still in the session")
+	}
+	if got := sectionOrder(t, hub); got != "1" {
+		t.Fatalf("session kept the revoked edit: %s", got)
+	}
+}
+
+func TestPageCollabFlushSkipsContentReplacedByReload(t *testing.T) {
+	page := &collabTestPage{
+		content: `[{"id":1,"heading":"One"}]`,
+		editors: map[int64]bool{7: true},
+	}
+	hub := newCollabTestHub(page)
+	alice := newCollabTestClient(hub, 7)
+	hub.joinPageCollab(alice, 1)
+	hub.applyPageCollabOp(alice, PageCollabOpPayload{PageID: 1, OpID: "a1",
+		Op: PageSectionOp{Kind: "update", SectionID: "1", Fields: map[string]json.RawMessage{"heading": json.RawMessage(`"Session"`)}}})
+
+	// A builder save lands after the flush captured the session document.
+	page.onCanEdit = func() {
+		page.onCanEdit = nil
+		page.setContent(`[{"id":1,"heading":"Builder"}]`)
+		hub.ReloadPageCollab(1)
+	}
+	hub.flushPageCollab(hub.collabSessions[1])
+
+	if saves, _ := page.savedBy(); len(saves) != 0 {
+		t.Fatalf("stale session content overwrote the builder save: %v", saves)
+	}
+	if content, _ := encodeCollabSections(hub.collabSessions[1].sections); !strings.Contains(content, "Builder") {
+		t.Fatalf("session was not reloaded: %s", content)
+	}
+}
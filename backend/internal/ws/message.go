@@ -38,6 +38,13 @@ const (
 	RecoveryRequestAccepted MessageType = "recovery_request:accepted" // server => requester: account recovery approved
 	ConfigUpdate            MessageType = "config:update"             // server => all: branding/seo/footer/landing changed
 	PageUpdate              MessageType = "page:update"               // server => all: CMS page created/updated/deleted
+	PageCollabJoin          MessageType = "page:collab:join"          // client => server: join a page's collaborative editing session
+	PageCollabLeave         MessageType = "page:collab:leave"         // client => server: leave a page's editing session
+	PageCollabOp            MessageType = "page:collab:op"            // client => server => session: section-level edit operation
+	PageCollabAck           MessageType = "page:collab:ack"           // server => client: op accepted (with its version) or rejected
+	PageCollabSnapshot      MessageType = "page:collab:snapshot"      // server => client: full section document at a version
+	PageCollabLock          MessageType = "page:collab:lock"          // client => server: acquire/release a section soft lock
+	PageCollabPresence      MessageType = "page:collab:presence"      // server => session: editors and the sections they hold
 	DocumentationUpdate     MessageType = "documentation:update"      // server => clients: documentation manifest/article invalidation
 	TrashUpdate             MessageType = "trash:update"              // server => all: content-free restore invalidation
 	Cursor                  MessageType = "cursor:update"             // client => server => same-route clients: cursor position
@@ -643,6 +643,7 @@ func buildRouter(db *sql.DB, hub *ws.Hub, dispatcher *ievents.Dispatcher, rdb *r
 
 	cfgRepo := icfg.NewRepository(db)
 	cfgSvc := icfg.NewService(cfgRepo, icfg.WithRedisClient(rdb))
+	authSvc.UseSiteConfig(cfgSvc)
 
 	// Bootstrap hub chat slow-mode from the persisted config so it takes
 	// effect on the first connection rather than waiting for the next toggle.
@@ -1061,8 +1062,11 @@ func buildRouter(db *sql.DB, hub *ws.Hub, dispatcher *ievents.Dispatcher, rdb *r
 
 		pageRepo := ipage.NewRepository(db)
 		pagePolicy := isecurity.NewPagePolicy(pageRepo, userSvc)
-		pageSvc := ipage.NewService(pageRepo, inboxSvc, ipage.WithIntegrationResolvers(dsSvc, csSvc), ipage.WithRedisClient(rdb), ipage.WithInteractivePolicy(pagePolicy))
-		ipage.NewHandler(pageSvc, cfgSvc, userSvc, hub, dispatcher, analyticsSvc).Mount(api, imw.JWTAuthMiddleware, commentSlowMode)
+		pageSvc := ipage.NewService(pageRepo, inboxSvc, ipage.WithIntegrationResolvers(dsSvc, csSvc), ipage.WithRedisClient(rdb), ipage.WithInteractivePolicy(pagePolicy), ipage.WithBundleStores(dsSvc, csSvc, iupload.PageAssets{}))
+		pageHandler := ipage.NewHandler(pageSvc, cfgSvc, userSvc, hub, dispatcher, analyticsSvc)
+		pageHandler.Mount(api, imw.JWTAuthMiddleware, commentSlowMode)
+		go pageHandler.RunScheduler(30 * time.Second)
+		hub.SetPageCollab(pageHandler.CollabBackend())
 
 		// Events log admin API.
 		eventsRepo := ievents.NewRepository(db)
@@ -1087,6 +1091,7 @@ func buildRouter(db *sql.DB, hub *ws.Hub, dispatcher *ievents.Dispatcher, rdb *r
 			go grengoSvc.WatchStorage()
 			go grengoSvc.WatchHardware()
 			go grengoSvc.WatchLogs()
+			go grengoSvc.WatchAlerts(inboxSender, os.Getenv("CLIENT_NAME"))
 			igrengo.NewHandler(grengoSvc).Mount(api, imw.JWTAuthMiddleware)
 		}
 
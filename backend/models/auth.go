@@ -38,6 +38,32 @@ type RegisterRequest struct {
 	DisplayName string `json:"display_name"`
 }
 
+// OIDCIdentity links a user to their account at an external OIDC provider.
+type OIDCIdentity struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"-"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// Passkey is a WebAuthn credential registered to a user. The credential ID
+// and COSE public key are only read by the auth service.
+type Passkey struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	CredentialID []byte     `json:"-"`
+	PublicKey    []byte     `json:"-"`
+	SignCount    uint32     `json:"-"`
+	Name         string     `json:"name"`
+	AAGUID       string     `json:"aaguid,omitempty"`
+	Transports   []string   `json:"transports,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+}
+
 // LoginRequest represents a user login request.
 type LoginRequest struct {
 	Email    string `json:"email"`
@@ -57,6 +83,7 @@ type AuthResponse struct {
 	ExpiresIn    int       `json:"expires_in"`
 	RequiresTOTP bool      `json:"requires_totp,omitempty"`
 	TOTPToken    string    `json:"totp_token,omitempty"`
+	MFAMethods   []string  `json:"mfa_methods,omitempty"` // second factors accepted when RequiresTOTP is set
 }
 
 // AuthUser is the user profile shape returned by auth flows. It deliberately
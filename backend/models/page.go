@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type PageItemCardWidth string
 
@@ -13,19 +16,23 @@ const (
 
 // Page is a routable custom page with block-builder content stored as JSON.
 type Page struct {
-	ID          int64     `json:"id"`
-	Slug        string    `json:"slug"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	SEOTitle    string    `json:"seo_title"`
-	SEODesc     string    `json:"seo_description"`
-	SEOImage    string    `json:"seo_image"`
-	Visibility  string    `json:"visibility"` // "public", "private", "unlisted"
-	Content     string    `json:"content"`    // raw JSON array of sections
-	OwnerID     *int64    `json:"owner_id,omitempty"`
-	ViewCount   int       `json:"view_count"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	Slug        string     `json:"slug"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	SEOTitle    string     `json:"seo_title"`
+	SEODesc     string     `json:"seo_description"`
+	SEOImage    string     `json:"seo_image"`
+	Visibility  string     `json:"visibility"` // "public", "private", "unlisted"
+	Content     string     `json:"content"`    // raw JSON array of sections
+	Status      string     `json:"status"`     // "published", "draft"
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time `json:"unpublish_at,omitempty"`
+	HasDraft    bool       `json:"has_draft,omitempty"`
+	OwnerID     *int64     `json:"owner_id,omitempty"`
+	ViewCount   int        `json:"view_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 
 	// Enriched fields (not stored directly in pages table)
 	Owner        *PageUser   `json:"owner,omitempty"`
@@ -45,6 +52,7 @@ type PageBrowseSummary struct {
 	Title       string      `json:"title"`
 	Description string      `json:"description"`
 	Visibility  string      `json:"visibility"`
+	Status      string      `json:"status"`
 	OwnerID     *int64      `json:"-"`
 	Owner       *PageUser   `json:"owner,omitempty"`
 	Editors     []*PageUser `json:"editors"`
@@ -62,6 +70,130 @@ type PagePreview struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// PageDraft is the single working copy editors save and preview without
+// affecting readers. Publishing promotes it into the live page.
+type PageDraft struct {
+	PageID      int64     `json:"page_id"`
+	AuthorID    *int64    `json:"author_id,omitempty"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PageRevision is an immutable snapshot written on every page save. Content
+// never carries interactive records and is omitted from history listings.
+type PageRevision struct {
+	ID           int64     `json:"id"`
+	PageID       int64     `json:"page_id"`
+	AuthorID     *int64    `json:"author_id,omitempty"`
+	Author       *PageUser `json:"author,omitempty"`
+	Slug         string    `json:"slug"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	SEOTitle     string    `json:"seo_title"`
+	SEODesc      string    `json:"seo_description"`
+	SEOImage     string    `json:"seo_image"`
+	Visibility   string    `json:"visibility"`
+	Content      string    `json:"content,omitempty"`
+	RestoredFrom *int64    `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PageRevisionDiff compares two revisions of the same page field by field and
+// section by section.
+type PageRevisionDiff struct {
+	From     int64               `json:"from"`
+	To       int64               `json:"to"`
+	Fields   []PageFieldChange   `json:"fields"`
+	Sections []PageSectionChange `json:"sections"`
+}
+
+// PageFieldChange is a changed top-level page or SEO field.
+type PageFieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// PageSectionChange describes one section that was added, removed, modified
+// or moved between two revisions. Before/After hold the raw section JSON.
+type PageSectionChange struct {
+	SectionID   string          `json:"section_id"`
+	SectionType string          `json:"section_type"`
+	Heading     string          `json:"heading"`
+	Change      string          `json:"change"` // "added", "removed", "modified", "moved"
+	FromIndex   *int            `json:"from_index,omitempty"`
+	ToIndex     *int            `json:"to_index,omitempty"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+}
+
+// PageBundle is the portable export of a page layout. Content is the section
+// document without interactive records; the custom sections, data sources and
+// uploaded images it references travel alongside under bundle-local refs so an
+// import can recreate them in another tenant.
+type PageBundle struct {
+	Format         string                    `json:"format"`
+	Version        int                       `json:"version"`
+	ExportedAt     time.Time                 `json:"exported_at"`
+	Title          string                    `json:"title"`
+	Description    string                    `json:"description"`
+	SEOTitle       string                    `json:"seo_title"`
+	SEODesc        string                    `json:"seo_description"`
+	SEOImage       string                    `json:"seo_image"`
+	Content        json.RawMessage           `json:"content"`
+	CustomSections []PageBundleCustomSection `json:"custom_sections"`
+	DataSources    []PageBundleDataSource    `json:"data_sources"`
+	Assets         []PageBundleAsset         `json:"assets"`
+}
+
+// PageBundleDataSource is a data source carried in a bundle. Environment
+// values are never exported; EnvKeys lists the variable names to fill in.
+type PageBundleDataSource struct {
+	Ref         int64           `json:"ref"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Code        string          `json:"code"`
+	Files       json.RawMessage `json:"files,omitempty"`
+	CacheTTL    int             `json:"cache_ttl"`
+	EnvKeys     []string        `json:"env_keys,omitempty"`
+}
+
+// PageBundleCustomSection is a custom section preset carried in a bundle.
+// DataSourceRef points at a PageBundleDataSource.Ref.
+type PageBundleCustomSection struct {
+	Ref           int64  `json:"ref"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	DataSourceRef int64  `json:"datasource_ref"`
+	SectionType   string `json:"section_type"`
+	Config        string `json:"config"`
+}
+
+// PageBundleAsset is an uploaded image referenced by the page. URL is the
+// path the bundled content uses; Data is the file itself.
+type PageBundleAsset struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
+
+// PageTemplate is a tenant's saved page bundle offered in the template gallery.
+// Bundle is omitted from gallery listings.
+type PageTemplate struct {
+	ID           int64       `json:"id"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Category     string      `json:"category"`
+	SectionTypes []string    `json:"section_types"`
+	Bundle       *PageBundle `json:"bundle,omitempty"`
+	CreatedBy    *int64      `json:"created_by,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
 // PageSection is an ordered block on a custom page (not just the landing page).
 type PageSection struct {
 	ID           int64       `json:"id"`
@@ -998,29 +998,1013 @@ func (x *ExportSiteResponse) GetFilename() string {
 }
 
 type ImportSiteRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ArchivePath string                 `protobuf:"bytes,1,opt,name=archive_path,json=archivePath,proto3" json:"archive_path,omitempty"`
+	NewName     string                 `protobuf:"bytes,2,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"`
+	NewPort     string                 `protobuf:"bytes,3,opt,name=new_port,json=newPort,proto3" json:"new_port,omitempty"`
+	// Name of a backup target configured in the root .env ("default" is
+	// BACKUP_TARGET, otherwise BACKUP_TARGET_<NAME>).
+	// When set, archive_path names an archive stored on that target.
+	Target        string `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportSiteRequest) Reset() {
+	*x = ImportSiteRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportSiteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportSiteRequest) ProtoMessage() {}
+
+func (x *ImportSiteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportSiteRequest.ProtoReflect.Descriptor instead.
+func (*ImportSiteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ImportSiteRequest) GetArchivePath() string {
+	if x != nil {
+		return x.ArchivePath
+	}
+	return ""
+}
+
+func (x *ImportSiteRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
+	}
+	return ""
+}
+
+func (x *ImportSiteRequest) GetNewPort() string {
+	if x != nil {
+		return x.NewPort
+	}
+	return ""
+}
+
+func (x *ImportSiteRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+type ImportSiteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportSiteResponse) Reset() {
+	*x = ImportSiteResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportSiteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportSiteResponse) ProtoMessage() {}
+
+func (x *ImportSiteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportSiteResponse.ProtoReflect.Descriptor instead.
+func (*ImportSiteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ImportSiteResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type ListReleasesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReleasesJson  string                 `protobuf:"bytes,1,opt,name=releases_json,json=releasesJson,proto3" json:"releases_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReleasesResponse) Reset() {
+	*x = ListReleasesResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReleasesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReleasesResponse) ProtoMessage() {}
+
+func (x *ListReleasesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReleasesResponse.ProtoReflect.Descriptor instead.
+func (*ListReleasesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListReleasesResponse) GetReleasesJson() string {
+	if x != nil {
+		return x.ReleasesJson
+	}
+	return ""
+}
+
+type RollbackSiteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ReleaseId     int64                  `protobuf:"varint,2,opt,name=release_id,json=releaseId,proto3" json:"release_id,omitempty"`
+	RestoreDb     bool                   `protobuf:"varint,3,opt,name=restore_db,json=restoreDb,proto3" json:"restore_db,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackSiteRequest) Reset() {
+	*x = RollbackSiteRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackSiteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackSiteRequest) ProtoMessage() {}
+
+func (x *RollbackSiteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackSiteRequest.ProtoReflect.Descriptor instead.
+func (*RollbackSiteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RollbackSiteRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RollbackSiteRequest) GetReleaseId() int64 {
+	if x != nil {
+		return x.ReleaseId
+	}
+	return 0
+}
+
+func (x *RollbackSiteRequest) GetRestoreDb() bool {
+	if x != nil {
+		return x.RestoreDb
+	}
+	return false
+}
+
+type RollbackSiteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackSiteResponse) Reset() {
+	*x = RollbackSiteResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackSiteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackSiteResponse) ProtoMessage() {}
+
+func (x *RollbackSiteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackSiteResponse.ProtoReflect.Descriptor instead.
+func (*RollbackSiteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RollbackSiteResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// Replaces a client's quota. Zero limits are unlimited; enforce is warn, arm
+// (default) or disable.
+type SetSiteQuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	CpuShares     int32                  `protobuf:"varint,2,opt,name=cpu_shares,json=cpuShares,proto3" json:"cpu_shares,omitempty"`
+	MemoryMb      int64                  `protobuf:"varint,3,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	PidsLimit     int32                  `protobuf:"varint,4,opt,name=pids_limit,json=pidsLimit,proto3" json:"pids_limit,omitempty"`
+	UploadsMb     int64                  `protobuf:"varint,5,opt,name=uploads_mb,json=uploadsMb,proto3" json:"uploads_mb,omitempty"`
+	DbMb          int64                  `protobuf:"varint,6,opt,name=db_mb,json=dbMb,proto3" json:"db_mb,omitempty"`
+	Enforce       string                 `protobuf:"bytes,7,opt,name=enforce,proto3" json:"enforce,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSiteQuotaRequest) Reset() {
+	*x = SetSiteQuotaRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSiteQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSiteQuotaRequest) ProtoMessage() {}
+
+func (x *SetSiteQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSiteQuotaRequest.ProtoReflect.Descriptor instead.
+func (*SetSiteQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SetSiteQuotaRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SetSiteQuotaRequest) GetCpuShares() int32 {
+	if x != nil {
+		return x.CpuShares
+	}
+	return 0
+}
+
+func (x *SetSiteQuotaRequest) GetMemoryMb() int64 {
+	if x != nil {
+		return x.MemoryMb
+	}
+	return 0
+}
+
+func (x *SetSiteQuotaRequest) GetPidsLimit() int32 {
+	if x != nil {
+		return x.PidsLimit
+	}
+	return 0
+}
+
+func (x *SetSiteQuotaRequest) GetUploadsMb() int64 {
+	if x != nil {
+		return x.UploadsMb
+	}
+	return 0
+}
+
+func (x *SetSiteQuotaRequest) GetDbMb() int64 {
+	if x != nil {
+		return x.DbMb
+	}
+	return 0
+}
+
+func (x *SetSiteQuotaRequest) GetEnforce() string {
+	if x != nil {
+		return x.Enforce
+	}
+	return ""
+}
+
+// quota_json is null when the client has no quota. job_id is set when a
+// running client is recreated to apply new container limits.
+type SiteQuotaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuotaJson     string                 `protobuf:"bytes,1,opt,name=quota_json,json=quotaJson,proto3" json:"quota_json,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SiteQuotaResponse) Reset() {
+	*x = SiteQuotaResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SiteQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SiteQuotaResponse) ProtoMessage() {}
+
+func (x *SiteQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SiteQuotaResponse.ProtoReflect.Descriptor instead.
+func (*SiteQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SiteQuotaResponse) GetQuotaJson() string {
+	if x != nil {
+		return x.QuotaJson
+	}
+	return ""
+}
+
+func (x *SiteQuotaResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// A backend subscribes to the alerts addressed to its client's inbox.
+type WatchAlertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Client        string                 `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAlertsRequest) Reset() {
+	*x = WatchAlertsRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAlertsRequest) ProtoMessage() {}
+
+func (x *WatchAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAlertsRequest.ProtoReflect.Descriptor instead.
+func (*WatchAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WatchAlertsRequest) GetClient() string {
+	if x != nil {
+		return x.Client
+	}
+	return ""
+}
+
+// event_json is {"client", "users", "status", "rule", "severity", "target",
+// "summary", "message"}.
+type AlertEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventJson     string                 `protobuf:"bytes,1,opt,name=event_json,json=eventJson,proto3" json:"event_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertEvent) Reset() {
+	*x = AlertEvent{}
+	mi := &file_proto_grengo_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertEvent) ProtoMessage() {}
+
+func (x *AlertEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertEvent.ProtoReflect.Descriptor instead.
+func (*AlertEvent) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *AlertEvent) GetEventJson() string {
+	if x != nil {
+		return x.EventJson
+	}
+	return ""
+}
+
+type ListAlertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FiringOnly    bool                   `protobuf:"varint,1,opt,name=firing_only,json=firingOnly,proto3" json:"firing_only,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // default 50
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAlertsRequest) Reset() {
+	*x = ListAlertsRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertsRequest) ProtoMessage() {}
+
+func (x *ListAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertsRequest.ProtoReflect.Descriptor instead.
+func (*ListAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListAlertsRequest) GetFiringOnly() bool {
+	if x != nil {
+		return x.FiringOnly
+	}
+	return false
+}
+
+func (x *ListAlertsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListAlertsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AlertsJson    string                 `protobuf:"bytes,1,opt,name=alerts_json,json=alertsJson,proto3" json:"alerts_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAlertsResponse) Reset() {
+	*x = ListAlertsResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAlertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertsResponse) ProtoMessage() {}
+
+func (x *ListAlertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertsResponse.ProtoReflect.Descriptor instead.
+func (*ListAlertsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListAlertsResponse) GetAlertsJson() string {
+	if x != nil {
+		return x.AlertsJson
+	}
+	return ""
+}
+
+// Searches the stored container logs. since and until take a duration back
+// from now (90m, 2d) or a timestamp; level is the lowest level returned.
+type QueryLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Clients       []string               `protobuf:"bytes,1,rep,name=clients,proto3" json:"clients,omitempty"` // empty for every client
+	Since         string                 `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
+	Until         string                 `protobuf:"bytes,3,opt,name=until,proto3" json:"until,omitempty"`
+	Grep          string                 `protobuf:"bytes,4,opt,name=grep,proto3" json:"grep,omitempty"`    // regular expression on the message
+	Level         string                 `protobuf:"bytes,5,opt,name=level,proto3" json:"level,omitempty"`  // debug, info, warn or error
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"` // newest lines kept, default 500
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryLogsRequest) Reset() {
+	*x = QueryLogsRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryLogsRequest) ProtoMessage() {}
+
+func (x *QueryLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryLogsRequest.ProtoReflect.Descriptor instead.
+func (*QueryLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *QueryLogsRequest) GetClients() []string {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+func (x *QueryLogsRequest) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *QueryLogsRequest) GetUntil() string {
+	if x != nil {
+		return x.Until
+	}
+	return ""
+}
+
+func (x *QueryLogsRequest) GetGrep() string {
+	if x != nil {
+		return x.Grep
+	}
+	return ""
+}
+
+func (x *QueryLogsRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *QueryLogsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// entries_json is [{"time", "client", "container", "stream", "level", "msg"}]
+// oldest first.
+type QueryLogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntriesJson   string                 `protobuf:"bytes,1,opt,name=entries_json,json=entriesJson,proto3" json:"entries_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryLogsResponse) Reset() {
+	*x = QueryLogsResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryLogsResponse) ProtoMessage() {}
+
+func (x *QueryLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryLogsResponse.ProtoReflect.Descriptor instead.
+func (*QueryLogsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *QueryLogsResponse) GetEntriesJson() string {
+	if x != nil {
+		return x.EntriesJson
+	}
+	return ""
+}
+
+type ListWebhookDeliveriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Site          string                 `protobuf:"bytes,1,opt,name=site,proto3" json:"site,omitempty"`    // empty for every delivery
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // default 50
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhookDeliveriesRequest) Reset() {
+	*x = ListWebhookDeliveriesRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookDeliveriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookDeliveriesRequest) ProtoMessage() {}
+
+func (x *ListWebhookDeliveriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookDeliveriesRequest.ProtoReflect.Descriptor instead.
+func (*ListWebhookDeliveriesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListWebhookDeliveriesRequest) GetSite() string {
+	if x != nil {
+		return x.Site
+	}
+	return ""
+}
+
+func (x *ListWebhookDeliveriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListWebhookDeliveriesResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	DeliveriesJson string                 `protobuf:"bytes,1,opt,name=deliveries_json,json=deliveriesJson,proto3" json:"deliveries_json,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListWebhookDeliveriesResponse) Reset() {
+	*x = ListWebhookDeliveriesResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookDeliveriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookDeliveriesResponse) ProtoMessage() {}
+
+func (x *ListWebhookDeliveriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookDeliveriesResponse.ProtoReflect.Descriptor instead.
+func (*ListWebhookDeliveriesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListWebhookDeliveriesResponse) GetDeliveriesJson() string {
+	if x != nil {
+		return x.DeliveriesJson
+	}
+	return ""
+}
+
+// One entry per node: {"node", "addr", "error", "data"}, where data is that
+// node's ListSites, Stats or Storage JSON.
+type FleetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodesJson     string                 `protobuf:"bytes,1,opt,name=nodes_json,json=nodesJson,proto3" json:"nodes_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FleetResponse) Reset() {
+	*x = FleetResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FleetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FleetResponse) ProtoMessage() {}
+
+func (x *FleetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FleetResponse.ProtoReflect.Descriptor instead.
+func (*FleetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *FleetResponse) GetNodesJson() string {
+	if x != nil {
+		return x.NodesJson
+	}
+	return ""
+}
+
+type MoveSiteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	FromNode      string                 `protobuf:"bytes,2,opt,name=from_node,json=fromNode,proto3" json:"from_node,omitempty"` // "local" for this node
+	ToNode        string                 `protobuf:"bytes,3,opt,name=to_node,json=toNode,proto3" json:"to_node,omitempty"`
+	KeepSource    bool                   `protobuf:"varint,4,opt,name=keep_source,json=keepSource,proto3" json:"keep_source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveSiteRequest) Reset() {
+	*x = MoveSiteRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveSiteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveSiteRequest) ProtoMessage() {}
+
+func (x *MoveSiteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveSiteRequest.ProtoReflect.Descriptor instead.
+func (*MoveSiteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *MoveSiteRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MoveSiteRequest) GetFromNode() string {
+	if x != nil {
+		return x.FromNode
+	}
+	return ""
+}
+
+func (x *MoveSiteRequest) GetToNode() string {
+	if x != nil {
+		return x.ToNode
+	}
+	return ""
+}
+
+func (x *MoveSiteRequest) GetKeepSource() bool {
+	if x != nil {
+		return x.KeepSource
+	}
+	return false
+}
+
+type MoveSiteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveSiteResponse) Reset() {
+	*x = MoveSiteResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveSiteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveSiteResponse) ProtoMessage() {}
+
+func (x *MoveSiteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveSiteResponse.ProtoReflect.Descriptor instead.
+func (*MoveSiteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *MoveSiteResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// The first chunk carries the archive file name.
+type UploadExportChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ArchivePath   string                 `protobuf:"bytes,1,opt,name=archive_path,json=archivePath,proto3" json:"archive_path,omitempty"`
-	NewName       string                 `protobuf:"bytes,2,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"`
-	NewPort       string                 `protobuf:"bytes,3,opt,name=new_port,json=newPort,proto3" json:"new_port,omitempty"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Chunk         []byte                 `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ImportSiteRequest) Reset() {
-	*x = ImportSiteRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[20]
+func (x *UploadExportChunk) Reset() {
+	*x = UploadExportChunk{}
+	mi := &file_proto_grengo_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ImportSiteRequest) String() string {
+func (x *UploadExportChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ImportSiteRequest) ProtoMessage() {}
+func (*UploadExportChunk) ProtoMessage() {}
 
-func (x *ImportSiteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[20]
+func (x *UploadExportChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1031,54 +2015,47 @@ func (x *ImportSiteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ImportSiteRequest.ProtoReflect.Descriptor instead.
-func (*ImportSiteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{20}
-}
-
-func (x *ImportSiteRequest) GetArchivePath() string {
-	if x != nil {
-		return x.ArchivePath
-	}
-	return ""
+// Deprecated: Use UploadExportChunk.ProtoReflect.Descriptor instead.
+func (*UploadExportChunk) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *ImportSiteRequest) GetNewName() string {
+func (x *UploadExportChunk) GetFilename() string {
 	if x != nil {
-		return x.NewName
+		return x.Filename
 	}
 	return ""
 }
 
-func (x *ImportSiteRequest) GetNewPort() string {
+func (x *UploadExportChunk) GetChunk() []byte {
 	if x != nil {
-		return x.NewPort
+		return x.Chunk
 	}
-	return ""
+	return nil
 }
 
-type ImportSiteResponse struct {
+type UploadExportResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ImportSiteResponse) Reset() {
-	*x = ImportSiteResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[21]
+func (x *UploadExportResponse) Reset() {
+	*x = UploadExportResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ImportSiteResponse) String() string {
+func (x *UploadExportResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ImportSiteResponse) ProtoMessage() {}
+func (*UploadExportResponse) ProtoMessage() {}
 
-func (x *ImportSiteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[21]
+func (x *UploadExportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1089,14 +2066,14 @@ func (x *ImportSiteResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ImportSiteResponse.ProtoReflect.Descriptor instead.
-func (*ImportSiteResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use UploadExportResponse.ProtoReflect.Descriptor instead.
+func (*UploadExportResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *ImportSiteResponse) GetFilename() string {
+func (x *UploadExportResponse) GetPath() string {
 	if x != nil {
-		return x.Filename
+		return x.Path
 	}
 	return ""
 }
@@ -1111,7 +2088,7 @@ type MigrateSiteRequest struct {
 
 func (x *MigrateSiteRequest) Reset() {
 	*x = MigrateSiteRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[22]
+	mi := &file_proto_grengo_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1123,7 +2100,7 @@ func (x *MigrateSiteRequest) String() string {
 func (*MigrateSiteRequest) ProtoMessage() {}
 
 func (x *MigrateSiteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[22]
+	mi := &file_proto_grengo_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1136,7 +2113,7 @@ func (x *MigrateSiteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MigrateSiteRequest.ProtoReflect.Descriptor instead.
 func (*MigrateSiteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{22}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *MigrateSiteRequest) GetName() string {
@@ -1162,7 +2139,7 @@ type MigrateSiteResponse struct {
 
 func (x *MigrateSiteResponse) Reset() {
 	*x = MigrateSiteResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[23]
+	mi := &file_proto_grengo_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1174,7 +2151,7 @@ func (x *MigrateSiteResponse) String() string {
 func (*MigrateSiteResponse) ProtoMessage() {}
 
 func (x *MigrateSiteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[23]
+	mi := &file_proto_grengo_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1187,7 +2164,7 @@ func (x *MigrateSiteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MigrateSiteResponse.ProtoReflect.Descriptor instead.
 func (*MigrateSiteResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{23}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *MigrateSiteResponse) GetResultJson() string {
@@ -1206,7 +2183,7 @@ type MigrateAllRequest struct {
 
 func (x *MigrateAllRequest) Reset() {
 	*x = MigrateAllRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[24]
+	mi := &file_proto_grengo_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1218,7 +2195,7 @@ func (x *MigrateAllRequest) String() string {
 func (*MigrateAllRequest) ProtoMessage() {}
 
 func (x *MigrateAllRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[24]
+	mi := &file_proto_grengo_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1231,7 +2208,7 @@ func (x *MigrateAllRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MigrateAllRequest.ProtoReflect.Descriptor instead.
 func (*MigrateAllRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{24}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *MigrateAllRequest) GetRebuild() bool {
@@ -1250,7 +2227,7 @@ type MigrateAllResponse struct {
 
 func (x *MigrateAllResponse) Reset() {
 	*x = MigrateAllResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[25]
+	mi := &file_proto_grengo_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1262,7 +2239,7 @@ func (x *MigrateAllResponse) String() string {
 func (*MigrateAllResponse) ProtoMessage() {}
 
 func (x *MigrateAllResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[25]
+	mi := &file_proto_grengo_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1275,7 +2252,7 @@ func (x *MigrateAllResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MigrateAllResponse.ProtoReflect.Descriptor instead.
 func (*MigrateAllResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{25}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *MigrateAllResponse) GetResultJson() string {
@@ -1294,7 +2271,7 @@ type ExportNodeResponse struct {
 
 func (x *ExportNodeResponse) Reset() {
 	*x = ExportNodeResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[26]
+	mi := &file_proto_grengo_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1306,7 +2283,7 @@ func (x *ExportNodeResponse) String() string {
 func (*ExportNodeResponse) ProtoMessage() {}
 
 func (x *ExportNodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[26]
+	mi := &file_proto_grengo_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1319,7 +2296,7 @@ func (x *ExportNodeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExportNodeResponse.ProtoReflect.Descriptor instead.
 func (*ExportNodeResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{26}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *ExportNodeResponse) GetFilename() string {
@@ -1332,13 +2309,14 @@ func (x *ExportNodeResponse) GetFilename() string {
 type ImportNodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ArchivePath   string                 `protobuf:"bytes,1,opt,name=archive_path,json=archivePath,proto3" json:"archive_path,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ImportNodeRequest) Reset() {
 	*x = ImportNodeRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[27]
+	mi := &file_proto_grengo_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1350,7 +2328,7 @@ func (x *ImportNodeRequest) String() string {
 func (*ImportNodeRequest) ProtoMessage() {}
 
 func (x *ImportNodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[27]
+	mi := &file_proto_grengo_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1363,7 +2341,7 @@ func (x *ImportNodeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ImportNodeRequest.ProtoReflect.Descriptor instead.
 func (*ImportNodeRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{27}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *ImportNodeRequest) GetArchivePath() string {
@@ -1373,6 +2351,13 @@ func (x *ImportNodeRequest) GetArchivePath() string {
 	return ""
 }
 
+func (x *ImportNodeRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
 type ImportNodeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
@@ -1382,7 +2367,7 @@ type ImportNodeResponse struct {
 
 func (x *ImportNodeResponse) Reset() {
 	*x = ImportNodeResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[28]
+	mi := &file_proto_grengo_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1394,7 +2379,7 @@ func (x *ImportNodeResponse) String() string {
 func (*ImportNodeResponse) ProtoMessage() {}
 
 func (x *ImportNodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[28]
+	mi := &file_proto_grengo_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1407,7 +2392,7 @@ func (x *ImportNodeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ImportNodeResponse.ProtoReflect.Descriptor instead.
 func (*ImportNodeResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{28}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *ImportNodeResponse) GetFilename() string {
@@ -1426,7 +2411,7 @@ type ListExportsResponse struct {
 
 func (x *ListExportsResponse) Reset() {
 	*x = ListExportsResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[29]
+	mi := &file_proto_grengo_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1438,7 +2423,7 @@ func (x *ListExportsResponse) String() string {
 func (*ListExportsResponse) ProtoMessage() {}
 
 func (x *ListExportsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[29]
+	mi := &file_proto_grengo_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1451,7 +2436,7 @@ func (x *ListExportsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListExportsResponse.ProtoReflect.Descriptor instead.
 func (*ListExportsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{29}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *ListExportsResponse) GetExportsJson() string {
@@ -1461,6 +2446,50 @@ func (x *ListExportsResponse) GetExportsJson() string {
 	return ""
 }
 
+type TargetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetRequest) Reset() {
+	*x = TargetRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetRequest) ProtoMessage() {}
+
+func (x *TargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetRequest.ProtoReflect.Descriptor instead.
+func (*TargetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *TargetRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
 type DownloadExportRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
@@ -1470,7 +2499,7 @@ type DownloadExportRequest struct {
 
 func (x *DownloadExportRequest) Reset() {
 	*x = DownloadExportRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[30]
+	mi := &file_proto_grengo_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1482,7 +2511,7 @@ func (x *DownloadExportRequest) String() string {
 func (*DownloadExportRequest) ProtoMessage() {}
 
 func (x *DownloadExportRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[30]
+	mi := &file_proto_grengo_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1495,7 +2524,7 @@ func (x *DownloadExportRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DownloadExportRequest.ProtoReflect.Descriptor instead.
 func (*DownloadExportRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{30}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{49}
 }
 
 func (x *DownloadExportRequest) GetFilename() string {
@@ -1514,7 +2543,7 @@ type DeleteExportRequest struct {
 
 func (x *DeleteExportRequest) Reset() {
 	*x = DeleteExportRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[31]
+	mi := &file_proto_grengo_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1526,7 +2555,7 @@ func (x *DeleteExportRequest) String() string {
 func (*DeleteExportRequest) ProtoMessage() {}
 
 func (x *DeleteExportRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[31]
+	mi := &file_proto_grengo_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1539,7 +2568,7 @@ func (x *DeleteExportRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteExportRequest.ProtoReflect.Descriptor instead.
 func (*DeleteExportRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{31}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *DeleteExportRequest) GetFilename() string {
@@ -1558,7 +2587,7 @@ type FileChunk struct {
 
 func (x *FileChunk) Reset() {
 	*x = FileChunk{}
-	mi := &file_proto_grengo_proto_msgTypes[32]
+	mi := &file_proto_grengo_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1570,7 +2599,7 @@ func (x *FileChunk) String() string {
 func (*FileChunk) ProtoMessage() {}
 
 func (x *FileChunk) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[32]
+	mi := &file_proto_grengo_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1583,7 +2612,7 @@ func (x *FileChunk) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
 func (*FileChunk) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{32}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{51}
 }
 
 func (x *FileChunk) GetChunk() []byte {
@@ -1602,7 +2631,7 @@ type ListJobsResponse struct {
 
 func (x *ListJobsResponse) Reset() {
 	*x = ListJobsResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[33]
+	mi := &file_proto_grengo_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1614,7 +2643,7 @@ func (x *ListJobsResponse) String() string {
 func (*ListJobsResponse) ProtoMessage() {}
 
 func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[33]
+	mi := &file_proto_grengo_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1627,7 +2656,7 @@ func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
 func (*ListJobsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{33}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{52}
 }
 
 func (x *ListJobsResponse) GetJobsJson() string {
@@ -1646,7 +2675,7 @@ type GetJobRequest struct {
 
 func (x *GetJobRequest) Reset() {
 	*x = GetJobRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[34]
+	mi := &file_proto_grengo_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1658,7 +2687,7 @@ func (x *GetJobRequest) String() string {
 func (*GetJobRequest) ProtoMessage() {}
 
 func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[34]
+	mi := &file_proto_grengo_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1671,7 +2700,7 @@ func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
 func (*GetJobRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{34}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *GetJobRequest) GetId() string {
@@ -1690,7 +2719,7 @@ type GetJobResponse struct {
 
 func (x *GetJobResponse) Reset() {
 	*x = GetJobResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[35]
+	mi := &file_proto_grengo_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1702,7 +2731,7 @@ func (x *GetJobResponse) String() string {
 func (*GetJobResponse) ProtoMessage() {}
 
 func (x *GetJobResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[35]
+	mi := &file_proto_grengo_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1715,7 +2744,7 @@ func (x *GetJobResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetJobResponse.ProtoReflect.Descriptor instead.
 func (*GetJobResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{35}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{54}
 }
 
 func (x *GetJobResponse) GetJobJson() string {
@@ -1734,7 +2763,7 @@ type DownloadJobRequest struct {
 
 func (x *DownloadJobRequest) Reset() {
 	*x = DownloadJobRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[36]
+	mi := &file_proto_grengo_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1746,7 +2775,7 @@ func (x *DownloadJobRequest) String() string {
 func (*DownloadJobRequest) ProtoMessage() {}
 
 func (x *DownloadJobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[36]
+	mi := &file_proto_grengo_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1759,7 +2788,7 @@ func (x *DownloadJobRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DownloadJobRequest.ProtoReflect.Descriptor instead.
 func (*DownloadJobRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{36}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{55}
 }
 
 func (x *DownloadJobRequest) GetId() string {
@@ -1778,7 +2807,7 @@ type JobEvent struct {
 
 func (x *JobEvent) Reset() {
 	*x = JobEvent{}
-	mi := &file_proto_grengo_proto_msgTypes[37]
+	mi := &file_proto_grengo_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1790,7 +2819,7 @@ func (x *JobEvent) String() string {
 func (*JobEvent) ProtoMessage() {}
 
 func (x *JobEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[37]
+	mi := &file_proto_grengo_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1803,7 +2832,7 @@ func (x *JobEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JobEvent.ProtoReflect.Descriptor instead.
 func (*JobEvent) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{37}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{56}
 }
 
 func (x *JobEvent) GetEventJson() string {
@@ -1822,7 +2851,7 @@ type SendActionRequest struct {
 
 func (x *SendActionRequest) Reset() {
 	*x = SendActionRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[38]
+	mi := &file_proto_grengo_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1834,7 +2863,7 @@ func (x *SendActionRequest) String() string {
 func (*SendActionRequest) ProtoMessage() {}
 
 func (x *SendActionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[38]
+	mi := &file_proto_grengo_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1847,7 +2876,7 @@ func (x *SendActionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendActionRequest.ProtoReflect.Descriptor instead.
 func (*SendActionRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{38}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{57}
 }
 
 func (x *SendActionRequest) GetAction() []byte {
@@ -1868,7 +2897,7 @@ type SendActionResponse struct {
 
 func (x *SendActionResponse) Reset() {
 	*x = SendActionResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[39]
+	mi := &file_proto_grengo_proto_msgTypes[58]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1880,7 +2909,7 @@ func (x *SendActionResponse) String() string {
 func (*SendActionResponse) ProtoMessage() {}
 
 func (x *SendActionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[39]
+	mi := &file_proto_grengo_proto_msgTypes[58]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1893,7 +2922,7 @@ func (x *SendActionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendActionResponse.ProtoReflect.Descriptor instead.
 func (*SendActionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{39}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{58}
 }
 
 func (x *SendActionResponse) GetAccepted() bool {
@@ -1926,7 +2955,7 @@ type PasscodeStatusResponse struct {
 
 func (x *PasscodeStatusResponse) Reset() {
 	*x = PasscodeStatusResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[40]
+	mi := &file_proto_grengo_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1938,7 +2967,7 @@ func (x *PasscodeStatusResponse) String() string {
 func (*PasscodeStatusResponse) ProtoMessage() {}
 
 func (x *PasscodeStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[40]
+	mi := &file_proto_grengo_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1951,7 +2980,7 @@ func (x *PasscodeStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PasscodeStatusResponse.ProtoReflect.Descriptor instead.
 func (*PasscodeStatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{40}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{59}
 }
 
 func (x *PasscodeStatusResponse) GetConfigured() bool {
@@ -1971,7 +3000,7 @@ type VerifyPasscodeRequest struct {
 
 func (x *VerifyPasscodeRequest) Reset() {
 	*x = VerifyPasscodeRequest{}
-	mi := &file_proto_grengo_proto_msgTypes[41]
+	mi := &file_proto_grengo_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1983,7 +3012,7 @@ func (x *VerifyPasscodeRequest) String() string {
 func (*VerifyPasscodeRequest) ProtoMessage() {}
 
 func (x *VerifyPasscodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[41]
+	mi := &file_proto_grengo_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1996,7 +3025,7 @@ func (x *VerifyPasscodeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VerifyPasscodeRequest.ProtoReflect.Descriptor instead.
 func (*VerifyPasscodeRequest) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{41}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{60}
 }
 
 func (x *VerifyPasscodeRequest) GetP1() string {
@@ -2022,7 +3051,7 @@ type VerifyPasscodeResponse struct {
 
 func (x *VerifyPasscodeResponse) Reset() {
 	*x = VerifyPasscodeResponse{}
-	mi := &file_proto_grengo_proto_msgTypes[42]
+	mi := &file_proto_grengo_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2034,7 +3063,7 @@ func (x *VerifyPasscodeResponse) String() string {
 func (*VerifyPasscodeResponse) ProtoMessage() {}
 
 func (x *VerifyPasscodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_grengo_proto_msgTypes[42]
+	mi := &file_proto_grengo_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2047,7 +3076,7 @@ func (x *VerifyPasscodeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VerifyPasscodeResponse.ProtoReflect.Descriptor instead.
 func (*VerifyPasscodeResponse) Descriptor() ([]byte, []int) {
-	return file_proto_grengo_proto_rawDescGZIP(), []int{42}
+	return file_proto_grengo_proto_rawDescGZIP(), []int{61}
 }
 
 func (x *VerifyPasscodeResponse) GetValid() bool {
@@ -2057,6 +3086,137 @@ func (x *VerifyPasscodeResponse) GetValid() bool {
 	return false
 }
 
+// Login exchanges operator credentials for a short-lived session token,
+// sent afterwards as "authorization: Bearer <token>". totp_required is set,
+// without a token, when the operator has TOTP and no code was given.
+type LoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Totp          string                 `protobuf:"bytes,3,opt,name=totp,proto3" json:"totp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_proto_grengo_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *LoginRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetTotp() string {
+	if x != nil {
+		return x.Totp
+	}
+	return ""
+}
+
+type LoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	TotpRequired  bool                   `protobuf:"varint,4,opt,name=totp_required,json=totpRequired,proto3" json:"totp_required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_proto_grengo_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_grengo_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_grengo_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *LoginResponse) GetTotpRequired() bool {
+	if x != nil {
+		return x.TotpRequired
+	}
+	return false
+}
+
 var File_proto_grengo_proto protoreflect.FileDescriptor
 
 const file_proto_grengo_proto_rawDesc = "" +
@@ -2116,13 +3276,82 @@ const file_proto_grengo_proto_rawDesc = "" +
 	"\x10HardwareResponse\x12#\n" +
 	"\rhardware_json\x18\x01 \x01(\tR\fhardwareJson\"0\n" +
 	"\x12ExportSiteResponse\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\"l\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"\x84\x01\n" +
 	"\x11ImportSiteRequest\x12!\n" +
 	"\farchive_path\x18\x01 \x01(\tR\varchivePath\x12\x19\n" +
 	"\bnew_name\x18\x02 \x01(\tR\anewName\x12\x19\n" +
-	"\bnew_port\x18\x03 \x01(\tR\anewPort\"0\n" +
+	"\bnew_port\x18\x03 \x01(\tR\anewPort\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\"0\n" +
 	"\x12ImportSiteResponse\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\"B\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\";\n" +
+	"\x14ListReleasesResponse\x12#\n" +
+	"\rreleases_json\x18\x01 \x01(\tR\freleasesJson\"g\n" +
+	"\x13RollbackSiteRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"release_id\x18\x02 \x01(\x03R\treleaseId\x12\x1d\n" +
+	"\n" +
+	"restore_db\x18\x03 \x01(\bR\trestoreDb\"-\n" +
+	"\x14RollbackSiteResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xd2\x01\n" +
+	"\x13SetSiteQuotaRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"cpu_shares\x18\x02 \x01(\x05R\tcpuShares\x12\x1b\n" +
+	"\tmemory_mb\x18\x03 \x01(\x03R\bmemoryMb\x12\x1d\n" +
+	"\n" +
+	"pids_limit\x18\x04 \x01(\x05R\tpidsLimit\x12\x1d\n" +
+	"\n" +
+	"uploads_mb\x18\x05 \x01(\x03R\tuploadsMb\x12\x13\n" +
+	"\x05db_mb\x18\x06 \x01(\x03R\x04dbMb\x12\x18\n" +
+	"\aenforce\x18\a \x01(\tR\aenforce\"I\n" +
+	"\x11SiteQuotaResponse\x12\x1d\n" +
+	"\n" +
+	"quota_json\x18\x01 \x01(\tR\tquotaJson\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\",\n" +
+	"\x12WatchAlertsRequest\x12\x16\n" +
+	"\x06client\x18\x01 \x01(\tR\x06client\"+\n" +
+	"\n" +
+	"AlertEvent\x12\x1d\n" +
+	"\n" +
+	"event_json\x18\x01 \x01(\tR\teventJson\"J\n" +
+	"\x11ListAlertsRequest\x12\x1f\n" +
+	"\vfiring_only\x18\x01 \x01(\bR\n" +
+	"firingOnly\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"5\n" +
+	"\x12ListAlertsResponse\x12\x1f\n" +
+	"\valerts_json\x18\x01 \x01(\tR\n" +
+	"alertsJson\"\x98\x01\n" +
+	"\x10QueryLogsRequest\x12\x18\n" +
+	"\aclients\x18\x01 \x03(\tR\aclients\x12\x14\n" +
+	"\x05since\x18\x02 \x01(\tR\x05since\x12\x14\n" +
+	"\x05until\x18\x03 \x01(\tR\x05until\x12\x12\n" +
+	"\x04grep\x18\x04 \x01(\tR\x04grep\x12\x14\n" +
+	"\x05level\x18\x05 \x01(\tR\x05level\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\"6\n" +
+	"\x11QueryLogsResponse\x12!\n" +
+	"\fentries_json\x18\x01 \x01(\tR\ventriesJson\"H\n" +
+	"\x1cListWebhookDeliveriesRequest\x12\x12\n" +
+	"\x04site\x18\x01 \x01(\tR\x04site\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"H\n" +
+	"\x1dListWebhookDeliveriesResponse\x12'\n" +
+	"\x0fdeliveries_json\x18\x01 \x01(\tR\x0edeliveriesJson\".\n" +
+	"\rFleetResponse\x12\x1d\n" +
+	"\n" +
+	"nodes_json\x18\x01 \x01(\tR\tnodesJson\"|\n" +
+	"\x0fMoveSiteRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tfrom_node\x18\x02 \x01(\tR\bfromNode\x12\x17\n" +
+	"\ato_node\x18\x03 \x01(\tR\x06toNode\x12\x1f\n" +
+	"\vkeep_source\x18\x04 \x01(\bR\n" +
+	"keepSource\")\n" +
+	"\x10MoveSiteResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"E\n" +
+	"\x11UploadExportChunk\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x14\n" +
+	"\x05chunk\x18\x02 \x01(\fR\x05chunk\"*\n" +
+	"\x14UploadExportResponse\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"B\n" +
 	"\x12MigrateSiteRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\arebuild\x18\x02 \x01(\bR\arebuild\"6\n" +
@@ -2135,13 +3364,16 @@ const file_proto_grengo_proto_rawDesc = "" +
 	"\vresult_json\x18\x01 \x01(\tR\n" +
 	"resultJson\"0\n" +
 	"\x12ExportNodeResponse\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\"6\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"N\n" +
 	"\x11ImportNodeRequest\x12!\n" +
-	"\farchive_path\x18\x01 \x01(\tR\varchivePath\"0\n" +
+	"\farchive_path\x18\x01 \x01(\tR\varchivePath\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\"0\n" +
 	"\x12ImportNodeResponse\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\"8\n" +
 	"\x13ListExportsResponse\x12!\n" +
-	"\fexports_json\x18\x01 \x01(\tR\vexportsJson\"3\n" +
+	"\fexports_json\x18\x01 \x01(\tR\vexportsJson\"'\n" +
+	"\rTargetRequest\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\"3\n" +
 	"\x15DownloadExportRequest\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\"1\n" +
 	"\x13DeleteExportRequest\x12\x1a\n" +
@@ -2173,7 +3405,17 @@ const file_proto_grengo_proto_rawDesc = "" +
 	"\x02p1\x18\x01 \x01(\tR\x02p1\x12\x0e\n" +
 	"\x02p2\x18\x02 \x01(\tR\x02p2\".\n" +
 	"\x16VerifyPasscodeResponse\x12\x14\n" +
-	"\x05valid\x18\x01 \x01(\bR\x05valid2\xab\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\"Z\n" +
+	"\fLoginRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x12\n" +
+	"\x04totp\x18\x03 \x01(\tR\x04totp\"}\n" +
+	"\rLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\x12#\n" +
+	"\rtotp_required\x18\x04 \x01(\bR\ftotpRequired2\xc5\x1d\n" +
 	"\rGrengoService\x12J\n" +
 	"\tListSites\x12\x1d.grengo.grpc.ListSitesRequest\x1a\x1e.grengo.grpc.ListSitesResponse\x12;\n" +
 	"\x04Exec\x12\x18.grengo.grpc.ExecRequest\x1a\x19.grengo.grpc.ExecResponse\x12M\n" +
@@ -2210,18 +3452,35 @@ const file_proto_grengo_proto_rawDesc = "" +
 	"ExportNode\x12\x19.grengo.grpc.EmptyRequest\x1a\x1f.grengo.grpc.ExportNodeResponse\x12M\n" +
 	"\n" +
 	"ImportNode\x12\x1e.grengo.grpc.ImportNodeRequest\x1a\x1f.grengo.grpc.ImportNodeResponse\x12J\n" +
-	"\vListExports\x12\x19.grengo.grpc.EmptyRequest\x1a .grengo.grpc.ListExportsResponse\x12N\n" +
+	"\vListExports\x12\x19.grengo.grpc.EmptyRequest\x1a .grengo.grpc.ListExportsResponse\x12Q\n" +
+	"\x11ListTargetExports\x12\x1a.grengo.grpc.TargetRequest\x1a .grengo.grpc.ListExportsResponse\x12N\n" +
 	"\x0eDownloadExport\x12\".grengo.grpc.DownloadExportRequest\x1a\x16.grengo.grpc.FileChunk0\x01\x12L\n" +
-	"\fDeleteExport\x12 .grengo.grpc.DeleteExportRequest\x1a\x1a.grengo.grpc.EmptyResponse\x12D\n" +
+	"\fDeleteExport\x12 .grengo.grpc.DeleteExportRequest\x1a\x1a.grengo.grpc.EmptyResponse\x12K\n" +
+	"\fListReleases\x12\x18.grengo.grpc.SiteRequest\x1a!.grengo.grpc.ListReleasesResponse\x12S\n" +
+	"\fRollbackSite\x12 .grengo.grpc.RollbackSiteRequest\x1a!.grengo.grpc.RollbackSiteResponse\x12P\n" +
+	"\fSetSiteQuota\x12 .grengo.grpc.SetSiteQuotaRequest\x1a\x1e.grengo.grpc.SiteQuotaResponse\x12H\n" +
+	"\fGetSiteQuota\x12\x18.grengo.grpc.SiteRequest\x1a\x1e.grengo.grpc.SiteQuotaResponse\x12I\n" +
+	"\vWatchAlerts\x12\x1f.grengo.grpc.WatchAlertsRequest\x1a\x17.grengo.grpc.AlertEvent0\x01\x12M\n" +
+	"\n" +
+	"ListAlerts\x12\x1e.grengo.grpc.ListAlertsRequest\x1a\x1f.grengo.grpc.ListAlertsResponse\x12n\n" +
+	"\x15ListWebhookDeliveries\x12).grengo.grpc.ListWebhookDeliveriesRequest\x1a*.grengo.grpc.ListWebhookDeliveriesResponse\x12G\n" +
+	"\x0eFleetListSites\x12\x19.grengo.grpc.EmptyRequest\x1a\x1a.grengo.grpc.FleetResponse\x12C\n" +
+	"\n" +
+	"FleetStats\x12\x19.grengo.grpc.EmptyRequest\x1a\x1a.grengo.grpc.FleetResponse\x12E\n" +
+	"\fFleetStorage\x12\x19.grengo.grpc.EmptyRequest\x1a\x1a.grengo.grpc.FleetResponse\x12G\n" +
+	"\bMoveSite\x12\x1c.grengo.grpc.MoveSiteRequest\x1a\x1d.grengo.grpc.MoveSiteResponse\x12S\n" +
+	"\fUploadExport\x12\x1e.grengo.grpc.UploadExportChunk\x1a!.grengo.grpc.UploadExportResponse(\x01\x12D\n" +
 	"\bListJobs\x12\x19.grengo.grpc.EmptyRequest\x1a\x1d.grengo.grpc.ListJobsResponse\x12A\n" +
 	"\x06GetJob\x12\x1a.grengo.grpc.GetJobRequest\x1a\x1b.grengo.grpc.GetJobResponse\x12H\n" +
 	"\vDownloadJob\x12\x1f.grengo.grpc.DownloadJobRequest\x1a\x16.grengo.grpc.FileChunk0\x01\x12?\n" +
 	"\tWatchJobs\x12\x19.grengo.grpc.EmptyRequest\x1a\x15.grengo.grpc.JobEvent0\x01\x12H\n" +
-	"\tWatchLogs\x12\x19.grengo.grpc.EmptyRequest\x1a\x1e.grengo.grpc.LogStreamResponse0\x01\x12M\n" +
+	"\tWatchLogs\x12\x19.grengo.grpc.EmptyRequest\x1a\x1e.grengo.grpc.LogStreamResponse0\x01\x12J\n" +
+	"\tQueryLogs\x12\x1d.grengo.grpc.QueryLogsRequest\x1a\x1e.grengo.grpc.QueryLogsResponse\x12M\n" +
 	"\n" +
 	"SendAction\x12\x1e.grengo.grpc.SendActionRequest\x1a\x1f.grengo.grpc.SendActionResponse\x12P\n" +
 	"\x0ePasscodeStatus\x12\x19.grengo.grpc.EmptyRequest\x1a#.grengo.grpc.PasscodeStatusResponse\x12Y\n" +
-	"\x0eVerifyPasscode\x12\".grengo.grpc.VerifyPasscodeRequest\x1a#.grengo.grpc.VerifyPasscodeResponseB\x1eZ\x1cgithub.com/skaia/grpc/grengob\x06proto3"
+	"\x0eVerifyPasscode\x12\".grengo.grpc.VerifyPasscodeRequest\x1a#.grengo.grpc.VerifyPasscodeResponse\x12>\n" +
+	"\x05Login\x12\x19.grengo.grpc.LoginRequest\x1a\x1a.grengo.grpc.LoginResponseB\x1eZ\x1cgithub.com/skaia/grpc/grengob\x06proto3"
 
 var (
 	file_proto_grengo_proto_rawDescOnce sync.Once
@@ -2235,51 +3494,72 @@ func file_proto_grengo_proto_rawDescGZIP() []byte {
 	return file_proto_grengo_proto_rawDescData
 }
 
-var file_proto_grengo_proto_msgTypes = make([]protoimpl.MessageInfo, 43)
+var file_proto_grengo_proto_msgTypes = make([]protoimpl.MessageInfo, 64)
 var file_proto_grengo_proto_goTypes = []any{
-	(*EmptyRequest)(nil),           // 0: grengo.grpc.EmptyRequest
-	(*EmptyResponse)(nil),          // 1: grengo.grpc.EmptyResponse
-	(*SiteRequest)(nil),            // 2: grengo.grpc.SiteRequest
-	(*ListSitesRequest)(nil),       // 3: grengo.grpc.ListSitesRequest
-	(*ListSitesResponse)(nil),      // 4: grengo.grpc.ListSitesResponse
-	(*ExecRequest)(nil),            // 5: grengo.grpc.ExecRequest
-	(*ExecResponse)(nil),           // 6: grengo.grpc.ExecResponse
-	(*CreateSiteRequest)(nil),      // 7: grengo.grpc.CreateSiteRequest
-	(*CreateSiteResponse)(nil),     // 8: grengo.grpc.CreateSiteResponse
-	(*ProvisionFrappeRequest)(nil), // 9: grengo.grpc.ProvisionFrappeRequest
-	(*LogStreamResponse)(nil),      // 10: grengo.grpc.LogStreamResponse
-	(*FrappeApp)(nil),              // 11: grengo.grpc.FrappeApp
-	(*GetFrappeAppsResponse)(nil),  // 12: grengo.grpc.GetFrappeAppsResponse
-	(*GetSiteEnvResponse)(nil),     // 13: grengo.grpc.GetSiteEnvResponse
-	(*UpdateSiteEnvRequest)(nil),   // 14: grengo.grpc.UpdateSiteEnvRequest
-	(*StatsResponse)(nil),          // 15: grengo.grpc.StatsResponse
-	(*StorageResponse)(nil),        // 16: grengo.grpc.StorageResponse
-	(*SysInfoResponse)(nil),        // 17: grengo.grpc.SysInfoResponse
-	(*HardwareResponse)(nil),       // 18: grengo.grpc.HardwareResponse
-	(*ExportSiteResponse)(nil),     // 19: grengo.grpc.ExportSiteResponse
-	(*ImportSiteRequest)(nil),      // 20: grengo.grpc.ImportSiteRequest
-	(*ImportSiteResponse)(nil),     // 21: grengo.grpc.ImportSiteResponse
-	(*MigrateSiteRequest)(nil),     // 22: grengo.grpc.MigrateSiteRequest
-	(*MigrateSiteResponse)(nil),    // 23: grengo.grpc.MigrateSiteResponse
-	(*MigrateAllRequest)(nil),      // 24: grengo.grpc.MigrateAllRequest
-	(*MigrateAllResponse)(nil),     // 25: grengo.grpc.MigrateAllResponse
-	(*ExportNodeResponse)(nil),     // 26: grengo.grpc.ExportNodeResponse
-	(*ImportNodeRequest)(nil),      // 27: grengo.grpc.ImportNodeRequest
-	(*ImportNodeResponse)(nil),     // 28: grengo.grpc.ImportNodeResponse
-	(*ListExportsResponse)(nil),    // 29: grengo.grpc.ListExportsResponse
-	(*DownloadExportRequest)(nil),  // 30: grengo.grpc.DownloadExportRequest
-	(*DeleteExportRequest)(nil),    // 31: grengo.grpc.DeleteExportRequest
-	(*FileChunk)(nil),              // 32: grengo.grpc.FileChunk
-	(*ListJobsResponse)(nil),       // 33: grengo.grpc.ListJobsResponse
-	(*GetJobRequest)(nil),          // 34: grengo.grpc.GetJobRequest
-	(*GetJobResponse)(nil),         // 35: grengo.grpc.GetJobResponse
-	(*DownloadJobRequest)(nil),     // 36: grengo.grpc.DownloadJobRequest
-	(*JobEvent)(nil),               // 37: grengo.grpc.JobEvent
-	(*SendActionRequest)(nil),      // 38: grengo.grpc.SendActionRequest
-	(*SendActionResponse)(nil),     // 39: grengo.grpc.SendActionResponse
-	(*PasscodeStatusResponse)(nil), // 40: grengo.grpc.PasscodeStatusResponse
-	(*VerifyPasscodeRequest)(nil),  // 41: grengo.grpc.VerifyPasscodeRequest
-	(*VerifyPasscodeResponse)(nil), // 42: grengo.grpc.VerifyPasscodeResponse
+	(*EmptyRequest)(nil),                  // 0: grengo.grpc.EmptyRequest
+	(*EmptyResponse)(nil),                 // 1: grengo.grpc.EmptyResponse
+	(*SiteRequest)(nil),                   // 2: grengo.grpc.SiteRequest
+	(*ListSitesRequest)(nil),              // 3: grengo.grpc.ListSitesRequest
+	(*ListSitesResponse)(nil),             // 4: grengo.grpc.ListSitesResponse
+	(*ExecRequest)(nil),                   // 5: grengo.grpc.ExecRequest
+	(*ExecResponse)(nil),                  // 6: grengo.grpc.ExecResponse
+	(*CreateSiteRequest)(nil),             // 7: grengo.grpc.CreateSiteRequest
+	(*CreateSiteResponse)(nil),            // 8: grengo.grpc.CreateSiteResponse
+	(*ProvisionFrappeRequest)(nil),        // 9: grengo.grpc.ProvisionFrappeRequest
+	(*LogStreamResponse)(nil),             // 10: grengo.grpc.LogStreamResponse
+	(*FrappeApp)(nil),                     // 11: grengo.grpc.FrappeApp
+	(*GetFrappeAppsResponse)(nil),         // 12: grengo.grpc.GetFrappeAppsResponse
+	(*GetSiteEnvResponse)(nil),            // 13: grengo.grpc.GetSiteEnvResponse
+	(*UpdateSiteEnvRequest)(nil),          // 14: grengo.grpc.UpdateSiteEnvRequest
+	(*StatsResponse)(nil),                 // 15: grengo.grpc.StatsResponse
+	(*StorageResponse)(nil),               // 16: grengo.grpc.StorageResponse
+	(*SysInfoResponse)(nil),               // 17: grengo.grpc.SysInfoResponse
+	(*HardwareResponse)(nil),              // 18: grengo.grpc.HardwareResponse
+	(*ExportSiteResponse)(nil),            // 19: grengo.grpc.ExportSiteResponse
+	(*ImportSiteRequest)(nil),             // 20: grengo.grpc.ImportSiteRequest
+	(*ImportSiteResponse)(nil),            // 21: grengo.grpc.ImportSiteResponse
+	(*ListReleasesResponse)(nil),          // 22: grengo.grpc.ListReleasesResponse
+	(*RollbackSiteRequest)(nil),           // 23: grengo.grpc.RollbackSiteRequest
+	(*RollbackSiteResponse)(nil),          // 24: grengo.grpc.RollbackSiteResponse
+	(*SetSiteQuotaRequest)(nil),           // 25: grengo.grpc.SetSiteQuotaRequest
+	(*SiteQuotaResponse)(nil),             // 26: grengo.grpc.SiteQuotaResponse
+	(*WatchAlertsRequest)(nil),            // 27: grengo.grpc.WatchAlertsRequest
+	(*AlertEvent)(nil),                    // 28: grengo.grpc.AlertEvent
+	(*ListAlertsRequest)(nil),             // 29: grengo.grpc.ListAlertsRequest
+	(*ListAlertsResponse)(nil),            // 30: grengo.grpc.ListAlertsResponse
+	(*QueryLogsRequest)(nil),              // 31: grengo.grpc.QueryLogsRequest
+	(*QueryLogsResponse)(nil),             // 32: grengo.grpc.QueryLogsResponse
+	(*ListWebhookDeliveriesRequest)(nil),  // 33: grengo.grpc.ListWebhookDeliveriesRequest
+	(*ListWebhookDeliveriesResponse)(nil), // 34: grengo.grpc.ListWebhookDeliveriesResponse
+	(*FleetResponse)(nil),                 // 35: grengo.grpc.FleetResponse
+	(*MoveSiteRequest)(nil),               // 36: grengo.grpc.MoveSiteRequest
+	(*MoveSiteResponse)(nil),              // 37: grengo.grpc.MoveSiteResponse
+	(*UploadExportChunk)(nil),             // 38: grengo.grpc.UploadExportChunk
+	(*UploadExportResponse)(nil),          // 39: grengo.grpc.UploadExportResponse
+	(*MigrateSiteRequest)(nil),            // 40: grengo.grpc.MigrateSiteRequest
+	(*MigrateSiteResponse)(nil),           // 41: grengo.grpc.MigrateSiteResponse
+	(*MigrateAllRequest)(nil),             // 42: grengo.grpc.MigrateAllRequest
+	(*MigrateAllResponse)(nil),            // 43: grengo.grpc.MigrateAllResponse
+	(*ExportNodeResponse)(nil),            // 44: grengo.grpc.ExportNodeResponse
+	(*ImportNodeRequest)(nil),             // 45: grengo.grpc.ImportNodeRequest
+	(*ImportNodeResponse)(nil),            // 46: grengo.grpc.ImportNodeResponse
+	(*ListExportsResponse)(nil),           // 47: grengo.grpc.ListExportsResponse
+	(*TargetRequest)(nil),                 // 48: grengo.grpc.TargetRequest
+	(*DownloadExportRequest)(nil),         // 49: grengo.grpc.DownloadExportRequest
+	(*DeleteExportRequest)(nil),           // 50: grengo.grpc.DeleteExportRequest
+	(*FileChunk)(nil),                     // 51: grengo.grpc.FileChunk
+	(*ListJobsResponse)(nil),              // 52: grengo.grpc.ListJobsResponse
+	(*GetJobRequest)(nil),                 // 53: grengo.grpc.GetJobRequest
+	(*GetJobResponse)(nil),                // 54: grengo.grpc.GetJobResponse
+	(*DownloadJobRequest)(nil),            // 55: grengo.grpc.DownloadJobRequest
+	(*JobEvent)(nil),                      // 56: grengo.grpc.JobEvent
+	(*SendActionRequest)(nil),             // 57: grengo.grpc.SendActionRequest
+	(*SendActionResponse)(nil),            // 58: grengo.grpc.SendActionResponse
+	(*PasscodeStatusResponse)(nil),        // 59: grengo.grpc.PasscodeStatusResponse
+	(*VerifyPasscodeRequest)(nil),         // 60: grengo.grpc.VerifyPasscodeRequest
+	(*VerifyPasscodeResponse)(nil),        // 61: grengo.grpc.VerifyPasscodeResponse
+	(*LoginRequest)(nil),                  // 62: grengo.grpc.LoginRequest
+	(*LoginResponse)(nil),                 // 63: grengo.grpc.LoginResponse
 }
 var file_proto_grengo_proto_depIdxs = []int32{
 	11, // 0: grengo.grpc.GetFrappeAppsResponse.apps:type_name -> grengo.grpc.FrappeApp
@@ -2303,58 +3583,88 @@ var file_proto_grengo_proto_depIdxs = []int32{
 	0,  // 18: grengo.grpc.GrengoService.GetHardware:input_type -> grengo.grpc.EmptyRequest
 	2,  // 19: grengo.grpc.GrengoService.ExportSite:input_type -> grengo.grpc.SiteRequest
 	20, // 20: grengo.grpc.GrengoService.ImportSite:input_type -> grengo.grpc.ImportSiteRequest
-	22, // 21: grengo.grpc.GrengoService.MigrateSite:input_type -> grengo.grpc.MigrateSiteRequest
-	24, // 22: grengo.grpc.GrengoService.MigrateAll:input_type -> grengo.grpc.MigrateAllRequest
+	40, // 21: grengo.grpc.GrengoService.MigrateSite:input_type -> grengo.grpc.MigrateSiteRequest
+	42, // 22: grengo.grpc.GrengoService.MigrateAll:input_type -> grengo.grpc.MigrateAllRequest
 	0,  // 23: grengo.grpc.GrengoService.ExportNode:input_type -> grengo.grpc.EmptyRequest
-	27, // 24: grengo.grpc.GrengoService.ImportNode:input_type -> grengo.grpc.ImportNodeRequest
+	45, // 24: grengo.grpc.GrengoService.ImportNode:input_type -> grengo.grpc.ImportNodeRequest
 	0,  // 25: grengo.grpc.GrengoService.ListExports:input_type -> grengo.grpc.EmptyRequest
-	30, // 26: grengo.grpc.GrengoService.DownloadExport:input_type -> grengo.grpc.DownloadExportRequest
-	31, // 27: grengo.grpc.GrengoService.DeleteExport:input_type -> grengo.grpc.DeleteExportRequest
-	0,  // 28: grengo.grpc.GrengoService.ListJobs:input_type -> grengo.grpc.EmptyRequest
-	34, // 29: grengo.grpc.GrengoService.GetJob:input_type -> grengo.grpc.GetJobRequest
-	36, // 30: grengo.grpc.GrengoService.DownloadJob:input_type -> grengo.grpc.DownloadJobRequest
-	0,  // 31: grengo.grpc.GrengoService.WatchJobs:input_type -> grengo.grpc.EmptyRequest
-	0,  // 32: grengo.grpc.GrengoService.WatchLogs:input_type -> grengo.grpc.EmptyRequest
-	38, // 33: grengo.grpc.GrengoService.SendAction:input_type -> grengo.grpc.SendActionRequest
-	0,  // 34: grengo.grpc.GrengoService.PasscodeStatus:input_type -> grengo.grpc.EmptyRequest
-	41, // 35: grengo.grpc.GrengoService.VerifyPasscode:input_type -> grengo.grpc.VerifyPasscodeRequest
-	4,  // 36: grengo.grpc.GrengoService.ListSites:output_type -> grengo.grpc.ListSitesResponse
-	6,  // 37: grengo.grpc.GrengoService.Exec:output_type -> grengo.grpc.ExecResponse
-	8,  // 38: grengo.grpc.GrengoService.CreateSite:output_type -> grengo.grpc.CreateSiteResponse
-	10, // 39: grengo.grpc.GrengoService.ProvisionFrappe:output_type -> grengo.grpc.LogStreamResponse
-	12, // 40: grengo.grpc.GrengoService.GetFrappeApps:output_type -> grengo.grpc.GetFrappeAppsResponse
-	1,  // 41: grengo.grpc.GrengoService.DeleteSite:output_type -> grengo.grpc.EmptyResponse
-	1,  // 42: grengo.grpc.GrengoService.StartSite:output_type -> grengo.grpc.EmptyResponse
-	1,  // 43: grengo.grpc.GrengoService.StopSite:output_type -> grengo.grpc.EmptyResponse
-	1,  // 44: grengo.grpc.GrengoService.EnableSite:output_type -> grengo.grpc.EmptyResponse
-	1,  // 45: grengo.grpc.GrengoService.DisableSite:output_type -> grengo.grpc.EmptyResponse
-	1,  // 46: grengo.grpc.GrengoService.ArmSite:output_type -> grengo.grpc.EmptyResponse
-	1,  // 47: grengo.grpc.GrengoService.DisarmSite:output_type -> grengo.grpc.EmptyResponse
-	13, // 48: grengo.grpc.GrengoService.GetSiteEnv:output_type -> grengo.grpc.GetSiteEnvResponse
-	1,  // 49: grengo.grpc.GrengoService.UpdateSiteEnv:output_type -> grengo.grpc.EmptyResponse
-	15, // 50: grengo.grpc.GrengoService.Stats:output_type -> grengo.grpc.StatsResponse
-	16, // 51: grengo.grpc.GrengoService.Storage:output_type -> grengo.grpc.StorageResponse
-	17, // 52: grengo.grpc.GrengoService.GetSysInfo:output_type -> grengo.grpc.SysInfoResponse
-	18, // 53: grengo.grpc.GrengoService.GetHardware:output_type -> grengo.grpc.HardwareResponse
-	19, // 54: grengo.grpc.GrengoService.ExportSite:output_type -> grengo.grpc.ExportSiteResponse
-	21, // 55: grengo.grpc.GrengoService.ImportSite:output_type -> grengo.grpc.ImportSiteResponse
-	23, // 56: grengo.grpc.GrengoService.MigrateSite:output_type -> grengo.grpc.MigrateSiteResponse
-	25, // 57: grengo.grpc.GrengoService.MigrateAll:output_type -> grengo.grpc.MigrateAllResponse
-	26, // 58: grengo.grpc.GrengoService.ExportNode:output_type -> grengo.grpc.ExportNodeResponse
-	28, // 59: grengo.grpc.GrengoService.ImportNode:output_type -> grengo.grpc.ImportNodeResponse
-	29, // 60: grengo.grpc.GrengoService.ListExports:output_type -> grengo.grpc.ListExportsResponse
-	32, // 61: grengo.grpc.GrengoService.DownloadExport:output_type -> grengo.grpc.FileChunk
-	1,  // 62: grengo.grpc.GrengoService.DeleteExport:output_type -> grengo.grpc.EmptyResponse
-	33, // 63: grengo.grpc.GrengoService.ListJobs:output_type -> grengo.grpc.ListJobsResponse
-	35, // 64: grengo.grpc.GrengoService.GetJob:output_type -> grengo.grpc.GetJobResponse
-	32, // 65: grengo.grpc.GrengoService.DownloadJob:output_type -> grengo.grpc.FileChunk
-	37, // 66: grengo.grpc.GrengoService.WatchJobs:output_type -> grengo.grpc.JobEvent
-	10, // 67: grengo.grpc.GrengoService.WatchLogs:output_type -> grengo.grpc.LogStreamResponse
-	39, // 68: grengo.grpc.GrengoService.SendAction:output_type -> grengo.grpc.SendActionResponse
-	40, // 69: grengo.grpc.GrengoService.PasscodeStatus:output_type -> grengo.grpc.PasscodeStatusResponse
-	42, // 70: grengo.grpc.GrengoService.VerifyPasscode:output_type -> grengo.grpc.VerifyPasscodeResponse
-	36, // [36:71] is the sub-list for method output_type
-	1,  // [1:36] is the sub-list for method input_type
+	48, // 26: grengo.grpc.GrengoService.ListTargetExports:input_type -> grengo.grpc.TargetRequest
+	49, // 27: grengo.grpc.GrengoService.DownloadExport:input_type -> grengo.grpc.DownloadExportRequest
+	50, // 28: grengo.grpc.GrengoService.DeleteExport:input_type -> grengo.grpc.DeleteExportRequest
+	2,  // 29: grengo.grpc.GrengoService.ListReleases:input_type -> grengo.grpc.SiteRequest
+	23, // 30: grengo.grpc.GrengoService.RollbackSite:input_type -> grengo.grpc.RollbackSiteRequest
+	25, // 31: grengo.grpc.GrengoService.SetSiteQuota:input_type -> grengo.grpc.SetSiteQuotaRequest
+	2,  // 32: grengo.grpc.GrengoService.GetSiteQuota:input_type -> grengo.grpc.SiteRequest
+	27, // 33: grengo.grpc.GrengoService.WatchAlerts:input_type -> grengo.grpc.WatchAlertsRequest
+	29, // 34: grengo.grpc.GrengoService.ListAlerts:input_type -> grengo.grpc.ListAlertsRequest
+	33, // 35: grengo.grpc.GrengoService.ListWebhookDeliveries:input_type -> grengo.grpc.ListWebhookDeliveriesRequest
+	0,  // 36: grengo.grpc.GrengoService.FleetListSites:input_type -> grengo.grpc.EmptyRequest
+	0,  // 37: grengo.grpc.GrengoService.FleetStats:input_type -> grengo.grpc.EmptyRequest
+	0,  // 38: grengo.grpc.GrengoService.FleetStorage:input_type -> grengo.grpc.EmptyRequest
+	36, // 39: grengo.grpc.GrengoService.MoveSite:input_type -> grengo.grpc.MoveSiteRequest
+	38, // 40: grengo.grpc.GrengoService.UploadExport:input_type -> grengo.grpc.UploadExportChunk
+	0,  // 41: grengo.grpc.GrengoService.ListJobs:input_type -> grengo.grpc.EmptyRequest
+	53, // 42: grengo.grpc.GrengoService.GetJob:input_type -> grengo.grpc.GetJobRequest
+	55, // 43: grengo.grpc.GrengoService.DownloadJob:input_type -> grengo.grpc.DownloadJobRequest
+	0,  // 44: grengo.grpc.GrengoService.WatchJobs:input_type -> grengo.grpc.EmptyRequest
+	0,  // 45: grengo.grpc.GrengoService.WatchLogs:input_type -> grengo.grpc.EmptyRequest
+	31, // 46: grengo.grpc.GrengoService.QueryLogs:input_type -> grengo.grpc.QueryLogsRequest
+	57, // 47: grengo.grpc.GrengoService.SendAction:input_type -> grengo.grpc.SendActionRequest
+	0,  // 48: grengo.grpc.GrengoService.PasscodeStatus:input_type -> grengo.grpc.EmptyRequest
+	60, // 49: grengo.grpc.GrengoService.VerifyPasscode:input_type -> grengo.grpc.VerifyPasscodeRequest
+	62, // 50: grengo.grpc.GrengoService.Login:input_type -> grengo.grpc.LoginRequest
+	4,  // 51: grengo.grpc.GrengoService.ListSites:output_type -> grengo.grpc.ListSitesResponse
+	6,  // 52: grengo.grpc.GrengoService.Exec:output_type -> grengo.grpc.ExecResponse
+	8,  // 53: grengo.grpc.GrengoService.CreateSite:output_type -> grengo.grpc.CreateSiteResponse
+	10, // 54: grengo.grpc.GrengoService.ProvisionFrappe:output_type -> grengo.grpc.LogStreamResponse
+	12, // 55: grengo.grpc.GrengoService.GetFrappeApps:output_type -> grengo.grpc.GetFrappeAppsResponse
+	1,  // 56: grengo.grpc.GrengoService.DeleteSite:output_type -> grengo.grpc.EmptyResponse
+	1,  // 57: grengo.grpc.GrengoService.StartSite:output_type -> grengo.grpc.EmptyResponse
+	1,  // 58: grengo.grpc.GrengoService.StopSite:output_type -> grengo.grpc.EmptyResponse
+	1,  // 59: grengo.grpc.GrengoService.EnableSite:output_type -> grengo.grpc.EmptyResponse
+	1,  // 60: grengo.grpc.GrengoService.DisableSite:output_type -> grengo.grpc.EmptyResponse
+	1,  // 61: grengo.grpc.GrengoService.ArmSite:output_type -> grengo.grpc.EmptyResponse
+	1,  // 62: grengo.grpc.GrengoService.DisarmSite:output_type -> grengo.grpc.EmptyResponse
+	13, // 63: grengo.grpc.GrengoService.GetSiteEnv:output_type -> grengo.grpc.GetSiteEnvResponse
+	1,  // 64: grengo.grpc.GrengoService.UpdateSiteEnv:output_type -> grengo.grpc.EmptyResponse
+	15, // 65: grengo.grpc.GrengoService.Stats:output_type -> grengo.grpc.StatsResponse
+	16, // 66: grengo.grpc.GrengoService.Storage:output_type -> grengo.grpc.StorageResponse
+	17, // 67: grengo.grpc.GrengoService.GetSysInfo:output_type -> grengo.grpc.SysInfoResponse
+	18, // 68: grengo.grpc.GrengoService.GetHardware:output_type -> grengo.grpc.HardwareResponse
+	19, // 69: grengo.grpc.GrengoService.ExportSite:output_type -> grengo.grpc.ExportSiteResponse
+	21, // 70: grengo.grpc.GrengoService.ImportSite:output_type -> grengo.grpc.ImportSiteResponse
+	41, // 71: grengo.grpc.GrengoService.MigrateSite:output_type -> grengo.grpc.MigrateSiteResponse
+	43, // 72: grengo.grpc.GrengoService.MigrateAll:output_type -> grengo.grpc.MigrateAllResponse
+	44, // 73: grengo.grpc.GrengoService.ExportNode:output_type -> grengo.grpc.ExportNodeResponse
+	46, // 74: grengo.grpc.GrengoService.ImportNode:output_type -> grengo.grpc.ImportNodeResponse
+	47, // 75: grengo.grpc.GrengoService.ListExports:output_type -> grengo.grpc.ListExportsResponse
+	47, // 76: grengo.grpc.GrengoService.ListTargetExports:output_type -> grengo.grpc.ListExportsResponse
+	51, // 77: grengo.grpc.GrengoService.DownloadExport:output_type -> grengo.grpc.FileChunk
+	1,  // 78: grengo.grpc.GrengoService.DeleteExport:output_type -> grengo.grpc.EmptyResponse
+	22, // 79: grengo.grpc.GrengoService.ListReleases:output_type -> grengo.grpc.ListReleasesResponse
+	24, // 80: grengo.grpc.GrengoService.RollbackSite:output_type -> grengo.grpc.RollbackSiteResponse
+	26, // 81: grengo.grpc.GrengoService.SetSiteQuota:output_type -> grengo.grpc.SiteQuotaResponse
+	26, // 82: grengo.grpc.GrengoService.GetSiteQuota:output_type -> grengo.grpc.SiteQuotaResponse
+	28, // 83: grengo.grpc.GrengoService.WatchAlerts:output_type -> grengo.grpc.AlertEvent
+	30, // 84: grengo.grpc.GrengoService.ListAlerts:output_type -> grengo.grpc.ListAlertsResponse
+	34, // 85: grengo.grpc.GrengoService.ListWebhookDeliveries:output_type -> grengo.grpc.ListWebhookDeliveriesResponse
+	35, // 86: grengo.grpc.GrengoService.FleetListSites:output_type -> grengo.grpc.FleetResponse
+	35, // 87: grengo.grpc.GrengoService.FleetStats:output_type -> grengo.grpc.FleetResponse
+	35, // 88: grengo.grpc.GrengoService.FleetStorage:output_type -> grengo.grpc.FleetResponse
+	37, // 89: grengo.grpc.GrengoService.MoveSite:output_type -> grengo.grpc.MoveSiteResponse
+	39, // 90: grengo.grpc.GrengoService.UploadExport:output_type -> grengo.grpc.UploadExportResponse
+	52, // 91: grengo.grpc.GrengoService.ListJobs:output_type -> grengo.grpc.ListJobsResponse
+	54, // 92: grengo.grpc.GrengoService.GetJob:output_type -> grengo.grpc.GetJobResponse
+	51, // 93: grengo.grpc.GrengoService.DownloadJob:output_type -> grengo.grpc.FileChunk
+	56, // 94: grengo.grpc.GrengoService.WatchJobs:output_type -> grengo.grpc.JobEvent
+	10, // 95: grengo.grpc.GrengoService.WatchLogs:output_type -> grengo.grpc.LogStreamResponse
+	32, // 96: grengo.grpc.GrengoService.QueryLogs:output_type -> grengo.grpc.QueryLogsResponse
+	58, // 97: grengo.grpc.GrengoService.SendAction:output_type -> grengo.grpc.SendActionResponse
+	59, // 98: grengo.grpc.GrengoService.PasscodeStatus:output_type -> grengo.grpc.PasscodeStatusResponse
+	61, // 99: grengo.grpc.GrengoService.VerifyPasscode:output_type -> grengo.grpc.VerifyPasscodeResponse
+	63, // 100: grengo.grpc.GrengoService.Login:output_type -> grengo.grpc.LoginResponse
+	51, // [51:101] is the sub-list for method output_type
+	1,  // [1:51] is the sub-list for method input_type
 	1,  // [1:1] is the sub-list for extension type_name
 	1,  // [1:1] is the sub-list for extension extendee
 	0,  // [0:1] is the sub-list for field type_name
@@ -2371,7 +3681,7 @@ func file_proto_grengo_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_grengo_proto_rawDesc), len(file_proto_grengo_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   43,
+			NumMessages:   64,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,41 +19,56 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	GrengoService_ListSites_FullMethodName       = "/grengo.grpc.GrengoService/ListSites"
-	GrengoService_Exec_FullMethodName            = "/grengo.grpc.GrengoService/Exec"
-	GrengoService_CreateSite_FullMethodName      = "/grengo.grpc.GrengoService/CreateSite"
-	GrengoService_ProvisionFrappe_FullMethodName = "/grengo.grpc.GrengoService/ProvisionFrappe"
-	GrengoService_GetFrappeApps_FullMethodName   = "/grengo.grpc.GrengoService/GetFrappeApps"
-	GrengoService_DeleteSite_FullMethodName      = "/grengo.grpc.GrengoService/DeleteSite"
-	GrengoService_StartSite_FullMethodName       = "/grengo.grpc.GrengoService/StartSite"
-	GrengoService_StopSite_FullMethodName        = "/grengo.grpc.GrengoService/StopSite"
-	GrengoService_EnableSite_FullMethodName      = "/grengo.grpc.GrengoService/EnableSite"
-	GrengoService_DisableSite_FullMethodName     = "/grengo.grpc.GrengoService/DisableSite"
-	GrengoService_ArmSite_FullMethodName         = "/grengo.grpc.GrengoService/ArmSite"
-	GrengoService_DisarmSite_FullMethodName      = "/grengo.grpc.GrengoService/DisarmSite"
-	GrengoService_GetSiteEnv_FullMethodName      = "/grengo.grpc.GrengoService/GetSiteEnv"
-	GrengoService_UpdateSiteEnv_FullMethodName   = "/grengo.grpc.GrengoService/UpdateSiteEnv"
-	GrengoService_Stats_FullMethodName           = "/grengo.grpc.GrengoService/Stats"
-	GrengoService_Storage_FullMethodName         = "/grengo.grpc.GrengoService/Storage"
-	GrengoService_GetSysInfo_FullMethodName      = "/grengo.grpc.GrengoService/GetSysInfo"
-	GrengoService_GetHardware_FullMethodName     = "/grengo.grpc.GrengoService/GetHardware"
-	GrengoService_ExportSite_FullMethodName      = "/grengo.grpc.GrengoService/ExportSite"
-	GrengoService_ImportSite_FullMethodName      = "/grengo.grpc.GrengoService/ImportSite"
-	GrengoService_MigrateSite_FullMethodName     = "/grengo.grpc.GrengoService/MigrateSite"
-	GrengoService_MigrateAll_FullMethodName      = "/grengo.grpc.GrengoService/MigrateAll"
-	GrengoService_ExportNode_FullMethodName      = "/grengo.grpc.GrengoService/ExportNode"
-	GrengoService_ImportNode_FullMethodName      = "/grengo.grpc.GrengoService/ImportNode"
-	GrengoService_ListExports_FullMethodName     = "/grengo.grpc.GrengoService/ListExports"
-	GrengoService_DownloadExport_FullMethodName  = "/grengo.grpc.GrengoService/DownloadExport"
-	GrengoService_DeleteExport_FullMethodName    = "/grengo.grpc.GrengoService/DeleteExport"
-	GrengoService_ListJobs_FullMethodName        = "/grengo.grpc.GrengoService/ListJobs"
-	GrengoService_GetJob_FullMethodName          = "/grengo.grpc.GrengoService/GetJob"
-	GrengoService_DownloadJob_FullMethodName     = "/grengo.grpc.GrengoService/DownloadJob"
-	GrengoService_WatchJobs_FullMethodName       = "/grengo.grpc.GrengoService/WatchJobs"
-	GrengoService_WatchLogs_FullMethodName       = "/grengo.grpc.GrengoService/WatchLogs"
-	GrengoService_SendAction_FullMethodName      = "/grengo.grpc.GrengoService/SendAction"
-	GrengoService_PasscodeStatus_FullMethodName  = "/grengo.grpc.GrengoService/PasscodeStatus"
-	GrengoService_VerifyPasscode_FullMethodName  = "/grengo.grpc.GrengoService/VerifyPasscode"
+	GrengoService_ListSites_FullMethodName             = "/grengo.grpc.GrengoService/ListSites"
+	GrengoService_Exec_FullMethodName                  = "/grengo.grpc.GrengoService/Exec"
+	GrengoService_CreateSite_FullMethodName            = "/grengo.grpc.GrengoService/CreateSite"
+	GrengoService_ProvisionFrappe_FullMethodName       = "/grengo.grpc.GrengoService/ProvisionFrappe"
+	GrengoService_GetFrappeApps_FullMethodName         = "/grengo.grpc.GrengoService/GetFrappeApps"
+	GrengoService_DeleteSite_FullMethodName            = "/grengo.grpc.GrengoService/DeleteSite"
+	GrengoService_StartSite_FullMethodName             = "/grengo.grpc.GrengoService/StartSite"
+	GrengoService_StopSite_FullMethodName              = "/grengo.grpc.GrengoService/StopSite"
+	GrengoService_EnableSite_FullMethodName            = "/grengo.grpc.GrengoService/EnableSite"
+	GrengoService_DisableSite_FullMethodName           = "/grengo.grpc.GrengoService/DisableSite"
+	GrengoService_ArmSite_FullMethodName               = "/grengo.grpc.GrengoService/ArmSite"
+	GrengoService_DisarmSite_FullMethodName            = "/grengo.grpc.GrengoService/DisarmSite"
+	GrengoService_GetSiteEnv_FullMethodName            = "/grengo.grpc.GrengoService/GetSiteEnv"
+	GrengoService_UpdateSiteEnv_FullMethodName         = "/grengo.grpc.GrengoService/UpdateSiteEnv"
+	GrengoService_Stats_FullMethodName                 = "/grengo.grpc.GrengoService/Stats"
+	GrengoService_Storage_FullMethodName               = "/grengo.grpc.GrengoService/Storage"
+	GrengoService_GetSysInfo_FullMethodName            = "/grengo.grpc.GrengoService/GetSysInfo"
+	GrengoService_GetHardware_FullMethodName           = "/grengo.grpc.GrengoService/GetHardware"
+	GrengoService_ExportSite_FullMethodName            = "/grengo.grpc.GrengoService/ExportSite"
+	GrengoService_ImportSite_FullMethodName            = "/grengo.grpc.GrengoService/ImportSite"
+	GrengoService_MigrateSite_FullMethodName           = "/grengo.grpc.GrengoService/MigrateSite"
+	GrengoService_MigrateAll_FullMethodName            = "/grengo.grpc.GrengoService/MigrateAll"
+	GrengoService_ExportNode_FullMethodName            = "/grengo.grpc.GrengoService/ExportNode"
+	GrengoService_ImportNode_FullMethodName            = "/grengo.grpc.GrengoService/ImportNode"
+	GrengoService_ListExports_FullMethodName           = "/grengo.grpc.GrengoService/ListExports"
+	GrengoService_ListTargetExports_FullMethodName     = "/grengo.grpc.GrengoService/ListTargetExports"
+	GrengoService_DownloadExport_FullMethodName        = "/grengo.grpc.GrengoService/DownloadExport"
+	GrengoService_DeleteExport_FullMethodName          = "/grengo.grpc.GrengoService/DeleteExport"
+	GrengoService_ListReleases_FullMethodName          = "/grengo.grpc.GrengoService/ListReleases"
+	GrengoService_RollbackSite_FullMethodName          = "/grengo.grpc.GrengoService/RollbackSite"
+	GrengoService_SetSiteQuota_FullMethodName          = "/grengo.grpc.GrengoService/SetSiteQuota"
+	GrengoService_GetSiteQuota_FullMethodName          = "/grengo.grpc.GrengoService/GetSiteQuota"
+	GrengoService_WatchAlerts_FullMethodName           = "/grengo.grpc.GrengoService/WatchAlerts"
+	GrengoService_ListAlerts_FullMethodName            = "/grengo.grpc.GrengoService/ListAlerts"
+	GrengoService_ListWebhookDeliveries_FullMethodName = "/grengo.grpc.GrengoService/ListWebhookDeliveries"
+	GrengoService_FleetListSites_FullMethodName        = "/grengo.grpc.GrengoService/FleetListSites"
+	GrengoService_FleetStats_FullMethodName            = "/grengo.grpc.GrengoService/FleetStats"
+	GrengoService_FleetStorage_FullMethodName          = "/grengo.grpc.GrengoService/FleetStorage"
+	GrengoService_MoveSite_FullMethodName              = "/grengo.grpc.GrengoService/MoveSite"
+	GrengoService_UploadExport_FullMethodName          = "/grengo.grpc.GrengoService/UploadExport"
+	GrengoService_ListJobs_FullMethodName              = "/grengo.grpc.GrengoService/ListJobs"
+	GrengoService_GetJob_FullMethodName                = "/grengo.grpc.GrengoService/GetJob"
+	GrengoService_DownloadJob_FullMethodName           = "/grengo.grpc.GrengoService/DownloadJob"
+	GrengoService_WatchJobs_FullMethodName             = "/grengo.grpc.GrengoService/WatchJobs"
+	GrengoService_WatchLogs_FullMethodName             = "/grengo.grpc.GrengoService/WatchLogs"
+	GrengoService_QueryLogs_FullMethodName             = "/grengo.grpc.GrengoService/QueryLogs"
+	GrengoService_SendAction_FullMethodName            = "/grengo.grpc.GrengoService/SendAction"
+	GrengoService_PasscodeStatus_FullMethodName        = "/grengo.grpc.GrengoService/PasscodeStatus"
+	GrengoService_VerifyPasscode_FullMethodName        = "/grengo.grpc.GrengoService/VerifyPasscode"
+	GrengoService_Login_FullMethodName                 = "/grengo.grpc.GrengoService/Login"
 )
 
 // GrengoServiceClient is the client API for GrengoService service.
@@ -89,19 +104,39 @@ type GrengoServiceClient interface {
 	ExportNode(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ExportNodeResponse, error)
 	ImportNode(ctx context.Context, in *ImportNodeRequest, opts ...grpc.CallOption) (*ImportNodeResponse, error)
 	ListExports(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ListExportsResponse, error)
+	ListTargetExports(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ListExportsResponse, error)
 	DownloadExport(ctx context.Context, in *DownloadExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error)
 	DeleteExport(ctx context.Context, in *DeleteExportRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	// Releases
+	ListReleases(ctx context.Context, in *SiteRequest, opts ...grpc.CallOption) (*ListReleasesResponse, error)
+	RollbackSite(ctx context.Context, in *RollbackSiteRequest, opts ...grpc.CallOption) (*RollbackSiteResponse, error)
+	// Quotas
+	SetSiteQuota(ctx context.Context, in *SetSiteQuotaRequest, opts ...grpc.CallOption) (*SiteQuotaResponse, error)
+	GetSiteQuota(ctx context.Context, in *SiteRequest, opts ...grpc.CallOption) (*SiteQuotaResponse, error)
+	// Alerts
+	WatchAlerts(ctx context.Context, in *WatchAlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AlertEvent], error)
+	ListAlerts(ctx context.Context, in *ListAlertsRequest, opts ...grpc.CallOption) (*ListAlertsResponse, error)
+	// Webhooks
+	ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error)
+	// Fleet
+	FleetListSites(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*FleetResponse, error)
+	FleetStats(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*FleetResponse, error)
+	FleetStorage(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*FleetResponse, error)
+	MoveSite(ctx context.Context, in *MoveSiteRequest, opts ...grpc.CallOption) (*MoveSiteResponse, error)
+	UploadExport(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadExportChunk, UploadExportResponse], error)
 	// Jobs
 	ListJobs(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
 	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*GetJobResponse, error)
 	DownloadJob(ctx context.Context, in *DownloadJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error)
 	WatchJobs(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobEvent], error)
 	WatchLogs(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogStreamResponse], error)
+	QueryLogs(ctx context.Context, in *QueryLogsRequest, opts ...grpc.CallOption) (*QueryLogsResponse, error)
 	// WS proxy replacement
 	SendAction(ctx context.Context, in *SendActionRequest, opts ...grpc.CallOption) (*SendActionResponse, error)
 	// Auth
 	PasscodeStatus(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*PasscodeStatusResponse, error)
 	VerifyPasscode(ctx context.Context, in *VerifyPasscodeRequest, opts ...grpc.CallOption) (*VerifyPasscodeResponse, error)
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
 }
 
 type grengoServiceClient struct {
@@ -371,6 +406,16 @@ func (c *grengoServiceClient) ListExports(ctx context.Context, in *EmptyRequest,
 	return out, nil
 }
 
+func (c *grengoServiceClient) ListTargetExports(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ListExportsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListExportsResponse)
+	err := c.cc.Invoke(ctx, GrengoService_ListTargetExports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *grengoServiceClient) DownloadExport(ctx context.Context, in *DownloadExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[1], GrengoService_DownloadExport_FullMethodName, cOpts...)
@@ -400,6 +445,138 @@ func (c *grengoServiceClient) DeleteExport(ctx context.Context, in *DeleteExport
 	return out, nil
 }
 
+func (c *grengoServiceClient) ListReleases(ctx context.Context, in *SiteRequest, opts ...grpc.CallOption) (*ListReleasesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReleasesResponse)
+	err := c.cc.Invoke(ctx, GrengoService_ListReleases_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) RollbackSite(ctx context.Context, in *RollbackSiteRequest, opts ...grpc.CallOption) (*RollbackSiteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RollbackSiteResponse)
+	err := c.cc.Invoke(ctx, GrengoService_RollbackSite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) SetSiteQuota(ctx context.Context, in *SetSiteQuotaRequest, opts ...grpc.CallOption) (*SiteQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SiteQuotaResponse)
+	err := c.cc.Invoke(ctx, GrengoService_SetSiteQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) GetSiteQuota(ctx context.Context, in *SiteRequest, opts ...grpc.CallOption) (*SiteQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SiteQuotaResponse)
+	err := c.cc.Invoke(ctx, GrengoService_GetSiteQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) WatchAlerts(ctx context.Context, in *WatchAlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AlertEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[2], GrengoService_WatchAlerts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAlertsRequest, AlertEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrengoService_WatchAlertsClient = grpc.ServerStreamingClient[AlertEvent]
+
+func (c *grengoServiceClient) ListAlerts(ctx context.Context, in *ListAlertsRequest, opts ...grpc.CallOption) (*ListAlertsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAlertsResponse)
+	err := c.cc.Invoke(ctx, GrengoService_ListAlerts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, GrengoService_ListWebhookDeliveries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) FleetListSites(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*FleetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FleetResponse)
+	err := c.cc.Invoke(ctx, GrengoService_FleetListSites_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) FleetStats(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*FleetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FleetResponse)
+	err := c.cc.Invoke(ctx, GrengoService_FleetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) FleetStorage(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*FleetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FleetResponse)
+	err := c.cc.Invoke(ctx, GrengoService_FleetStorage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) MoveSite(ctx context.Context, in *MoveSiteRequest, opts ...grpc.CallOption) (*MoveSiteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoveSiteResponse)
+	err := c.cc.Invoke(ctx, GrengoService_MoveSite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grengoServiceClient) UploadExport(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadExportChunk, UploadExportResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[3], GrengoService_UploadExport_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadExportChunk, UploadExportResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrengoService_UploadExportClient = grpc.ClientStreamingClient[UploadExportChunk, UploadExportResponse]
+
 func (c *grengoServiceClient) ListJobs(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListJobsResponse)
@@ -422,7 +599,7 @@ func (c *grengoServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opt
 
 func (c *grengoServiceClient) DownloadJob(ctx context.Context, in *DownloadJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[2], GrengoService_DownloadJob_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[4], GrengoService_DownloadJob_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -441,7 +618,7 @@ type GrengoService_DownloadJobClient = grpc.ServerStreamingClient[FileChunk]
 
 func (c *grengoServiceClient) WatchJobs(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobEvent], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[3], GrengoService_WatchJobs_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[5], GrengoService_WatchJobs_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -460,7 +637,7 @@ type GrengoService_WatchJobsClient = grpc.ServerStreamingClient[JobEvent]
 
 func (c *grengoServiceClient) WatchLogs(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogStreamResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[4], GrengoService_WatchLogs_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &GrengoService_ServiceDesc.Streams[6], GrengoService_WatchLogs_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -477,6 +654,16 @@ func (c *grengoServiceClient) WatchLogs(ctx context.Context, in *EmptyRequest, o
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GrengoService_WatchLogsClient = grpc.ServerStreamingClient[LogStreamResponse]
 
+func (c *grengoServiceClient) QueryLogs(ctx context.Context, in *QueryLogsRequest, opts ...grpc.CallOption) (*QueryLogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryLogsResponse)
+	err := c.cc.Invoke(ctx, GrengoService_QueryLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *grengoServiceClient) SendAction(ctx context.Context, in *SendActionRequest, opts ...grpc.CallOption) (*SendActionResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SendActionResponse)
@@ -507,6 +694,16 @@ func (c *grengoServiceClient) VerifyPasscode(ctx context.Context, in *VerifyPass
 	return out, nil
 }
 
+func (c *grengoServiceClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, GrengoService_Login_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GrengoServiceServer is the server API for GrengoService service.
 // All implementations must embed UnimplementedGrengoServiceServer
 // for forward compatibility.
@@ -540,19 +737,39 @@ type GrengoServiceServer interface {
 	ExportNode(context.Context, *EmptyRequest) (*ExportNodeResponse, error)
 	ImportNode(context.Context, *ImportNodeRequest) (*ImportNodeResponse, error)
 	ListExports(context.Context, *EmptyRequest) (*ListExportsResponse, error)
+	ListTargetExports(context.Context, *TargetRequest) (*ListExportsResponse, error)
 	DownloadExport(*DownloadExportRequest, grpc.ServerStreamingServer[FileChunk]) error
 	DeleteExport(context.Context, *DeleteExportRequest) (*EmptyResponse, error)
+	// Releases
+	ListReleases(context.Context, *SiteRequest) (*ListReleasesResponse, error)
+	RollbackSite(context.Context, *RollbackSiteRequest) (*RollbackSiteResponse, error)
+	// Quotas
+	SetSiteQuota(context.Context, *SetSiteQuotaRequest) (*SiteQuotaResponse, error)
+	GetSiteQuota(context.Context, *SiteRequest) (*SiteQuotaResponse, error)
+	// Alerts
+	WatchAlerts(*WatchAlertsRequest, grpc.ServerStreamingServer[AlertEvent]) error
+	ListAlerts(context.Context, *ListAlertsRequest) (*ListAlertsResponse, error)
+	// Webhooks
+	ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+	// Fleet
+	FleetListSites(context.Context, *EmptyRequest) (*FleetResponse, error)
+	FleetStats(context.Context, *EmptyRequest) (*FleetResponse, error)
+	FleetStorage(context.Context, *EmptyRequest) (*FleetResponse, error)
+	MoveSite(context.Context, *MoveSiteRequest) (*MoveSiteResponse, error)
+	UploadExport(grpc.ClientStreamingServer[UploadExportChunk, UploadExportResponse]) error
 	// Jobs
 	ListJobs(context.Context, *EmptyRequest) (*ListJobsResponse, error)
 	GetJob(context.Context, *GetJobRequest) (*GetJobResponse, error)
 	DownloadJob(*DownloadJobRequest, grpc.ServerStreamingServer[FileChunk]) error
 	WatchJobs(*EmptyRequest, grpc.ServerStreamingServer[JobEvent]) error
 	WatchLogs(*EmptyRequest, grpc.ServerStreamingServer[LogStreamResponse]) error
+	QueryLogs(context.Context, *QueryLogsRequest) (*QueryLogsResponse, error)
 	// WS proxy replacement
 	SendAction(context.Context, *SendActionRequest) (*SendActionResponse, error)
 	// Auth
 	PasscodeStatus(context.Context, *EmptyRequest) (*PasscodeStatusResponse, error)
 	VerifyPasscode(context.Context, *VerifyPasscodeRequest) (*VerifyPasscodeResponse, error)
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
 	mustEmbedUnimplementedGrengoServiceServer()
 }
 
@@ -638,12 +855,51 @@ func (UnimplementedGrengoServiceServer) ImportNode(context.Context, *ImportNodeR
 func (UnimplementedGrengoServiceServer) ListExports(context.Context, *EmptyRequest) (*ListExportsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListExports not implemented")
 }
+func (UnimplementedGrengoServiceServer) ListTargetExports(context.Context, *TargetRequest) (*ListExportsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTargetExports not implemented")
+}
 func (UnimplementedGrengoServiceServer) DownloadExport(*DownloadExportRequest, grpc.ServerStreamingServer[FileChunk]) error {
 	return status.Error(codes.Unimplemented, "method DownloadExport not implemented")
 }
 func (UnimplementedGrengoServiceServer) DeleteExport(context.Context, *DeleteExportRequest) (*EmptyResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method DeleteExport not implemented")
 }
+func (UnimplementedGrengoServiceServer) ListReleases(context.Context, *SiteRequest) (*ListReleasesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListReleases not implemented")
+}
+func (UnimplementedGrengoServiceServer) RollbackSite(context.Context, *RollbackSiteRequest) (*RollbackSiteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RollbackSite not implemented")
+}
+func (UnimplementedGrengoServiceServer) SetSiteQuota(context.Context, *SetSiteQuotaRequest) (*SiteQuotaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetSiteQuota not implemented")
+}
+func (UnimplementedGrengoServiceServer) GetSiteQuota(context.Context, *SiteRequest) (*SiteQuotaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSiteQuota not implemented")
+}
+func (UnimplementedGrengoServiceServer) WatchAlerts(*WatchAlertsRequest, grpc.ServerStreamingServer[AlertEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchAlerts not implemented")
+}
+func (UnimplementedGrengoServiceServer) ListAlerts(context.Context, *ListAlertsRequest) (*ListAlertsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAlerts not implemented")
+}
+func (UnimplementedGrengoServiceServer) ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (UnimplementedGrengoServiceServer) FleetListSites(context.Context, *EmptyRequest) (*FleetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FleetListSites not implemented")
+}
+func (UnimplementedGrengoServiceServer) FleetStats(context.Context, *EmptyRequest) (*FleetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FleetStats not implemented")
+}
+func (UnimplementedGrengoServiceServer) FleetStorage(context.Context, *EmptyRequest) (*FleetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FleetStorage not implemented")
+}
+func (UnimplementedGrengoServiceServer) MoveSite(context.Context, *MoveSiteRequest) (*MoveSiteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MoveSite not implemented")
+}
+func (UnimplementedGrengoServiceServer) UploadExport(grpc.ClientStreamingServer[UploadExportChunk, UploadExportResponse]) error {
+	return status.Error(codes.Unimplemented, "method UploadExport not implemented")
+}
 func (UnimplementedGrengoServiceServer) ListJobs(context.Context, *EmptyRequest) (*ListJobsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListJobs not implemented")
 }
@@ -659,6 +915,9 @@ func (UnimplementedGrengoServiceServer) WatchJobs(*EmptyRequest, grpc.ServerStre
 func (UnimplementedGrengoServiceServer) WatchLogs(*EmptyRequest, grpc.ServerStreamingServer[LogStreamResponse]) error {
 	return status.Error(codes.Unimplemented, "method WatchLogs not implemented")
 }
+func (UnimplementedGrengoServiceServer) QueryLogs(context.Context, *QueryLogsRequest) (*QueryLogsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method QueryLogs not implemented")
+}
 func (UnimplementedGrengoServiceServer) SendAction(context.Context, *SendActionRequest) (*SendActionResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SendAction not implemented")
 }
@@ -668,6 +927,9 @@ func (UnimplementedGrengoServiceServer) PasscodeStatus(context.Context, *EmptyRe
 func (UnimplementedGrengoServiceServer) VerifyPasscode(context.Context, *VerifyPasscodeRequest) (*VerifyPasscodeResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method VerifyPasscode not implemented")
 }
+func (UnimplementedGrengoServiceServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Login not implemented")
+}
 func (UnimplementedGrengoServiceServer) mustEmbedUnimplementedGrengoServiceServer() {}
 func (UnimplementedGrengoServiceServer) testEmbeddedByValue()                       {}
 
@@ -1132,6 +1394,24 @@ func _GrengoService_ListExports_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GrengoService_ListTargetExports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).ListTargetExports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_ListTargetExports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).ListTargetExports(ctx, req.(*TargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GrengoService_DownloadExport_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(DownloadExportRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -1161,6 +1441,204 @@ func _GrengoService_DeleteExport_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GrengoService_ListReleases_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SiteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).ListReleases(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_ListReleases_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).ListReleases(ctx, req.(*SiteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_RollbackSite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackSiteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).RollbackSite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_RollbackSite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).RollbackSite(ctx, req.(*RollbackSiteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_SetSiteQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSiteQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).SetSiteQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_SetSiteQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).SetSiteQuota(ctx, req.(*SetSiteQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_GetSiteQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SiteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).GetSiteQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_GetSiteQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).GetSiteQuota(ctx, req.(*SiteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_WatchAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAlertsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrengoServiceServer).WatchAlerts(m, &grpc.GenericServerStream[WatchAlertsRequest, AlertEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrengoService_WatchAlertsServer = grpc.ServerStreamingServer[AlertEvent]
+
+func _GrengoService_ListAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAlertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).ListAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_ListAlerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).ListAlerts(ctx, req.(*ListAlertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_ListWebhookDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).ListWebhookDeliveries(ctx, req.(*ListWebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_FleetListSites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).FleetListSites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_FleetListSites_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).FleetListSites(ctx, req.(*EmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_FleetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).FleetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_FleetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).FleetStats(ctx, req.(*EmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_FleetStorage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).FleetStorage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_FleetStorage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).FleetStorage(ctx, req.(*EmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_MoveSite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveSiteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).MoveSite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_MoveSite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).MoveSite(ctx, req.(*MoveSiteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrengoService_UploadExport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GrengoServiceServer).UploadExport(&grpc.GenericServerStream[UploadExportChunk, UploadExportResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrengoService_UploadExportServer = grpc.ClientStreamingServer[UploadExportChunk, UploadExportResponse]
+
 func _GrengoService_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EmptyRequest)
 	if err := dec(in); err != nil {
@@ -1230,6 +1708,24 @@ func _GrengoService_WatchLogs_Handler(srv interface{}, stream grpc.ServerStream)
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GrengoService_WatchLogsServer = grpc.ServerStreamingServer[LogStreamResponse]
 
+func _GrengoService_QueryLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).QueryLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_QueryLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).QueryLogs(ctx, req.(*QueryLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GrengoService_SendAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SendActionRequest)
 	if err := dec(in); err != nil {
@@ -1284,6 +1780,24 @@ func _GrengoService_VerifyPasscode_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GrengoService_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrengoServiceServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrengoService_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrengoServiceServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // GrengoService_ServiceDesc is the grpc.ServiceDesc for GrengoService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1387,10 +1901,54 @@ var GrengoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListExports",
 			Handler:    _GrengoService_ListExports_Handler,
 		},
+		{
+			MethodName: "ListTargetExports",
+			Handler:    _GrengoService_ListTargetExports_Handler,
+		},
 		{
 			MethodName: "DeleteExport",
 			Handler:    _GrengoService_DeleteExport_Handler,
 		},
+		{
+			MethodName: "ListReleases",
+			Handler:    _GrengoService_ListReleases_Handler,
+		},
+		{
+			MethodName: "RollbackSite",
+			Handler:    _GrengoService_RollbackSite_Handler,
+		},
+		{
+			MethodName: "SetSiteQuota",
+			Handler:    _GrengoService_SetSiteQuota_Handler,
+		},
+		{
+			MethodName: "GetSiteQuota",
+			Handler:    _GrengoService_GetSiteQuota_Handler,
+		},
+		{
+			MethodName: "ListAlerts",
+			Handler:    _GrengoService_ListAlerts_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _GrengoService_ListWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "FleetListSites",
+			Handler:    _GrengoService_FleetListSites_Handler,
+		},
+		{
+			MethodName: "FleetStats",
+			Handler:    _GrengoService_FleetStats_Handler,
+		},
+		{
+			MethodName: "FleetStorage",
+			Handler:    _GrengoService_FleetStorage_Handler,
+		},
+		{
+			MethodName: "MoveSite",
+			Handler:    _GrengoService_MoveSite_Handler,
+		},
 		{
 			MethodName: "ListJobs",
 			Handler:    _GrengoService_ListJobs_Handler,
@@ -1399,6 +1957,10 @@ var GrengoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetJob",
 			Handler:    _GrengoService_GetJob_Handler,
 		},
+		{
+			MethodName: "QueryLogs",
+			Handler:    _GrengoService_QueryLogs_Handler,
+		},
 		{
 			MethodName: "SendAction",
 			Handler:    _GrengoService_SendAction_Handler,
@@ -1411,6 +1973,10 @@ var GrengoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "VerifyPasscode",
 			Handler:    _GrengoService_VerifyPasscode_Handler,
 		},
+		{
+			MethodName: "Login",
+			Handler:    _GrengoService_Login_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1423,6 +1989,16 @@ var GrengoService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _GrengoService_DownloadExport_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchAlerts",
+			Handler:       _GrengoService_WatchAlerts_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadExport",
+			Handler:       _GrengoService_UploadExport_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "DownloadJob",
 			Handler:       _GrengoService_DownloadJob_Handler,
@@ -38,6 +38,8 @@ interface Props {
 
 type Tab = "preview" | "responses" | "results" | "design";
 
+const RESPONSE_PAGE_SIZE = 50;
+
 const choiceField = (type: InteractiveFieldType) =>
   type === "radio" || type === "select" || type === "multi_select";
 
@@ -434,6 +436,10 @@ export function InteractiveSectionBlock({ section, canEdit, onUpdate }: Props) {
   const [config, setConfig] = useState(initialConfig);
   const [tab, setTab] = useState<Tab>("preview");
   const [expanded, setExpanded] = useState<string | null>(null);
+  const [managed, setManaged] = useState<{ records: InteractiveRecord[]; total: number }>({
+    records: [],
+    total: 0,
+  });
   const submissionRef = useRef<{ fingerprint: string; key: string } | null>(null);
   const { pageId, canManagePage } = usePageBuilderContext();
   const isAuthenticated = useAtomValue(isAuthenticatedAtom);
@@ -450,6 +456,27 @@ export function InteractiveSectionBlock({ section, canEdit, onUpdate }: Props) {
     interactiveResponseLimitReached(type, config.response_limit, ownRecords.length);
 
   const replaceRuntimeConfig = (raw: string) => setConfig(parseInteractiveConfig(raw, type));
+
+  // Page views embed only the viewer's own responses, so managers page
+  // through the full list separately.
+  const loadResponses = async (offset = 0) => {
+    if (!pageId) return;
+    try {
+      const list = await apiRequest<{ records: InteractiveRecord[]; total: number }>(
+        `/pages/${pageId}/sections/${section.id}/responses?limit=${RESPONSE_PAGE_SIZE}&offset=${offset}`
+      );
+      setManaged(prev => ({
+        records: offset === 0 ? list.records : [...prev.records, ...list.records],
+        total: list.total,
+      }));
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : "Could not load responses");
+    }
+  };
+
+  useEffect(() => {
+    if (tab === "responses" && canManagePage) void loadResponses();
+  }, [tab, canManagePage, pageId, section.id]);
   const persistDesign = (next: InteractiveConfig) => {
     const { result_summary: _summary, ...stored } = next;
     setConfig(next);
@@ -508,6 +535,7 @@ export function InteractiveSectionBlock({ section, canEdit, onUpdate }: Props) {
       );
       replaceRuntimeConfig(response.config);
       if (expanded === record.id) setExpanded(null);
+      if (canManagePage) void loadResponses();
       toast.success("Record deleted");
     } catch (error) {
       toast.error(error instanceof Error ? error.message : "Could not delete record");
@@ -528,6 +556,7 @@ export function InteractiveSectionBlock({ section, canEdit, onUpdate }: Props) {
         }
       );
       replaceRuntimeConfig(response.config);
+      if (canManagePage) void loadResponses();
       toast.success("Question updated");
     } catch (error) {
       toast.error(error instanceof Error ? error.message : "Could not update question");
@@ -738,7 +767,7 @@ export function InteractiveSectionBlock({ section, canEdit, onUpdate }: Props) {
       {tab === "responses" && canManagePage && (
         <div className="interactive-responses">
           <TableView
-            data={config.records}
+            data={managed.records}
             columns={columns}
             rowKey={record => record.id}
             chrome="embedded"
@@ -747,7 +776,7 @@ export function InteractiveSectionBlock({ section, canEdit, onUpdate }: Props) {
           />
           {expanded &&
             (() => {
-              const record = config.records.find(item => item.id === expanded);
+              const record = managed.records.find(item => item.id === expanded);
               if (!record) return null;
               return (
                 <div className="interactive-expanded">
@@ -758,6 +787,15 @@ export function InteractiveSectionBlock({ section, canEdit, onUpdate }: Props) {
                 </div>
               );
             })()}
+          {managed.records.length < managed.total && (
+            <Button
+              type="button"
+              size="sm"
+              onClick={() => void loadResponses(managed.records.length)}
+            >
+              Load more
+            </Button>
+          )}
         </div>
       )}
       {tab === "results" && <ResultsView config={config} />}
@@ -7,7 +7,9 @@ require (
 	github.com/jaypipes/ghw v0.24.0
 	github.com/shirou/gopsutil/v3 v3.24.5
 	github.com/skaia/grpc v0.0.0
+	golang.org/x/crypto v0.48.0
 	google.golang.org/grpc v1.81.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -24,7 +26,6 @@ require (
 	golang.org/x/text v0.34.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260226221140-a57be14db171 // indirect
 	google.golang.org/protobuf v1.36.11 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	howett.net/plist v1.0.2-0.20250314012144-ee69052608d9 // indirect
 )
 
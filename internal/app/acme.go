@@ -0,0 +1,571 @@
+package app
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	// acmeRenewBefore is how long before expiry a certificate is renewed.
+	acmeRenewBefore     = 30 * 24 * time.Hour
+	acmeRenewInterval   = 12 * time.Hour
+	acmeOrderTimeout    = 10 * time.Minute
+	acmeChallengeHTTP01 = "http-01"
+	acmeChallengeDNS01  = "dns-01"
+	defaultHSTSMaxAge   = 365 * 24 * 60 * 60
+
+	// nginxTLSMount is where tlsDir is mounted in the nginx container.
+	nginxTLSMount = "/etc/nginx/tls"
+)
+
+// tlsDir holds the ACME account key and one directory of certificates per
+// client. It is mounted read-only into nginx.
+func tlsDir() string                      { return filepath.Join(nginxDir(), "tls") }
+func acmeAccountKeyFile() string          { return filepath.Join(tlsDir(), "account.key") }
+func acmeChallengeDir() string            { return filepath.Join(nginxDir(), "acme-challenge") }
+func clientTLSFile(name, f string) string { return filepath.Join(tlsDir(), name, f) }
+func nginxTLSPath(name, f string) string  { return nginxTLSMount + "/" + name + "/" + f }
+
+// acmeConfig is the node-wide ACME setup from the root .env. ACME is off
+// until GRENGO_ACME_EMAIL is set.
+type acmeConfig struct {
+	Email     string
+	Directory string
+	Challenge string
+	DNSHook   string
+	CACert    string // extra root for the directory's TLS, e.g. Pebble's
+}
+
+func loadACMEConfig() acmeConfig {
+	env := rootEnvFile()
+	cfg := acmeConfig{
+		Email:     strings.TrimSpace(envVal(env, "GRENGO_ACME_EMAIL")),
+		Directory: strings.TrimSpace(envVal(env, "GRENGO_ACME_DIRECTORY")),
+		Challenge: strings.TrimSpace(envVal(env, "GRENGO_ACME_CHALLENGE")),
+		DNSHook:   strings.TrimSpace(envVal(env, "GRENGO_ACME_DNS_HOOK")),
+		CACert:    strings.TrimSpace(envVal(env, "GRENGO_ACME_CA_CERT")),
+	}
+	if cfg.Directory == "" {
+		cfg.Directory = acme.LetsEncryptURL
+	}
+	if cfg.Challenge == "" {
+		cfg.Challenge = acmeChallengeHTTP01
+	}
+	return cfg
+}
+
+func (cfg acmeConfig) enabled() bool { return cfg.Email != "" }
+
+func (cfg acmeConfig) validate() error {
+	switch cfg.Challenge {
+	case acmeChallengeHTTP01:
+	case acmeChallengeDNS01:
+		if cfg.DNSHook == "" {
+			return fmt.Errorf("GRENGO_ACME_CHALLENGE=dns-01 needs GRENGO_ACME_DNS_HOOK")
+		}
+	default:
+		return fmt.Errorf("unsupported GRENGO_ACME_CHALLENGE %q (use http-01 or dns-01)", cfg.Challenge)
+	}
+	return nil
+}
+
+// hstsHeader is the Strict-Transport-Security value for HTTPS blocks, or ""
+// when GRENGO_HSTS_MAX_AGE is 0.
+func hstsHeader() string {
+	maxAge := defaultHSTSMaxAge
+	if v := strings.TrimSpace(envVal(rootEnvFile(), "GRENGO_HSTS_MAX_AGE")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxAge = n
+		}
+	}
+	if maxAge == 0 {
+		return ""
+	}
+	return fmt.Sprintf("max-age=%d", maxAge)
+}
+
+// tlsDomains returns the client hosts a public CA can certify: the names
+// from expandDomains, minus localhost and IP addresses. Clients opt out with
+// TLS=off in their .env.
+func tlsDomains(c clientInfo) []string {
+	if strings.EqualFold(strings.TrimSpace(envVal(clientEnvFile(c.Name), "TLS")), "off") {
+		return nil
+	}
+	var out []string
+	for _, d := range c.Domains {
+		if d == "localhost" || !strings.Contains(d, ".") || isIPAddress(d) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// clientCertificate parses the leaf of a client's stored certificate.
+func clientCertificate(name string) (*x509.Certificate, error) {
+	pair, err := tls.LoadX509KeyPair(clientTLSFile(name, "fullchain.pem"), clientTLSFile(name, "privkey.pem"))
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(pair.Certificate[0])
+}
+
+// certifiedHosts maps each client to the hosts its unexpired certificate
+// covers. nginx serves exactly these hosts over HTTPS.
+func certifiedHosts(clients []clientInfo) map[string]map[string]bool {
+	out := map[string]map[string]bool{}
+	now := time.Now()
+	for _, c := range clients {
+		leaf, err := clientCertificate(c.Name)
+		if err != nil || now.After(leaf.NotAfter) {
+			continue
+		}
+		for _, host := range tlsDomains(c) {
+			if leaf.VerifyHostname(host) == nil {
+				if out[c.Name] == nil {
+					out[c.Name] = map[string]bool{}
+				}
+				out[c.Name][host] = true
+			}
+		}
+	}
+	return out
+}
+
+// certificateDue reports why a client needs a new certificate, or "" when
+// the stored one is current and covers all its domains. A www. name that
+// expandDomains added rather than DOMAINS listing does not make a current
+// certificate due: issueClientCertificate drops it when it fails
+// validation, and reordering for it would only repeat that failure until
+// the CA rate-limits the node. Renewal near expiry tries it again.
+func certificateDue(c clientInfo, now time.Time) string {
+	domains := tlsDomains(c)
+	if len(domains) == 0 {
+		return ""
+	}
+	leaf, err := clientCertificate(c.Name)
+	if err != nil {
+		return "no certificate"
+	}
+	if now.Add(acmeRenewBefore).After(leaf.NotAfter) {
+		return "expires " + leaf.NotAfter.Format("2006-01-02")
+	}
+	configured := configuredDomains(c.Name)
+	for _, d := range domains {
+		if strings.HasPrefix(d, "www.") && !configured[d] {
+			continue
+		}
+		if leaf.VerifyHostname(d) != nil {
+			return "does not cover " + d
+		}
+	}
+	return ""
+}
+
+// configuredDomains returns the hosts listed in a client's DOMAINS, without
+// the names expandDomains derives from them.
+func configuredDomains(name string) map[string]bool {
+	configured := map[string]bool{}
+	for _, d := range strings.Fields(envVal(clientEnvFile(name), "DOMAINS")) {
+		if host, err := normalizedDomainHost(strings.TrimPrefix(d, "*.")); err == nil {
+			configured[host] = true
+		}
+	}
+	return configured
+}
+
+// acmeClient returns a client for the configured directory with the node's
+// account registered.
+func acmeClient(ctx context.Context, cfg acmeConfig) (*acme.Client, error) {
+	key, err := loadOrCreateACMEAccountKey()
+	if err != nil {
+		return nil, err
+	}
+	client := &acme.Client{Key: key, DirectoryURL: cfg.Directory, UserAgent: "grengo"}
+	if cfg.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		data, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates in %s", cfg.CACert)
+		}
+		client.HTTPClient = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	}
+	acct := &acme.Account{Contact: []string{"mailto:" + cfg.Email}}
+	if _, err := client.Register(ctx, acct, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("register ACME account: %w", err)
+	}
+	return client, nil
+}
+
+func loadOrCreateACMEAccountKey() (crypto.Signer, error) {
+	if data, err := os.ReadFile(acmeAccountKeyFile()); err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("invalid ACME account key in %s", acmeAccountKeyFile())
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported ACME account key in %s", acmeAccountKeyFile())
+		}
+		return signer, nil
+	}
+	key, keyPEM, err := newECDSAKey()
+	if err != nil {
+		return nil, err
+	}
+	ensureWritableDir(tlsDir())
+	if err := os.WriteFile(acmeAccountKeyFile(), keyPEM, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// acmeDomainError is a failed authorization for a single domain.
+type acmeDomainError struct {
+	Domain string
+	Err    error
+}
+
+func (e *acmeDomainError) Error() string { return fmt.Sprintf("%s: %v", e.Domain, e.Err) }
+
+// obtainCertificate runs one ACME order for domains and stores the result
+// as the client's certificate.
+func obtainCertificate(ctx context.Context, client *acme.Client, cfg acmeConfig, name string, domains []string) error {
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(domains...))
+	if err != nil {
+		return err
+	}
+	for _, url := range order.AuthzURLs {
+		if err := completeAuthorization(ctx, client, cfg, url); err != nil {
+			return err
+		}
+	}
+	if _, err := client.WaitOrder(ctx, order.URI); err != nil {
+		return err
+	}
+
+	key, keyPEM, err := newECDSAKey()
+	if err != nil {
+		return err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: domains}, key)
+	if err != nil {
+		return err
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return err
+	}
+	var fullchain []byte
+	for _, der := range chain {
+		fullchain = append(fullchain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	return writeClientCertificate(name, fullchain, keyPEM)
+}
+
+// writeClientCertificate replaces a client's certificate and key together.
+func writeClientCertificate(name string, fullchain, keyPEM []byte) error {
+	dir := filepath.Dir(clientTLSFile(name, "fullchain.pem"))
+	ensureWritableDir(dir)
+	// The key stays private to its owner: the nginx master process loads
+	// certificates as root before dropping privileges.
+	for _, f := range []struct {
+		name string
+		data []byte
+		mode os.FileMode
+	}{{"fullchain.pem", fullchain, 0644}, {"privkey.pem", keyPEM, 0600}} {
+		tmp := filepath.Join(dir, "."+f.name)
+		if err := os.WriteFile(tmp, f.data, f.mode); err != nil {
+			return err
+		}
+		// WriteFile keeps the mode of a leftover temp file.
+		if err := os.Chmod(tmp, f.mode); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, f.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeAuthorization proves control of one domain with the configured
+// challenge type.
+func completeAuthorization(ctx context.Context, client *acme.Client, cfg acmeConfig, url string) error {
+	authz, err := client.GetAuthorization(ctx, url)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	domain := authz.Identifier.Value
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == cfg.Challenge {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return &acmeDomainError{domain, fmt.Errorf("CA offers no %s challenge", cfg.Challenge)}
+	}
+
+	var cleanup func()
+	switch cfg.Challenge {
+	case acmeChallengeHTTP01:
+		body, err := client.HTTP01ChallengeResponse(chal.Token)
+		if err != nil {
+			return err
+		}
+		ensureWritableDir(acmeChallengeDir())
+		file := filepath.Join(acmeChallengeDir(), filepath.Base(chal.Token))
+		if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+			return err
+		}
+		cleanup = func() { os.Remove(file) }
+	case acmeChallengeDNS01:
+		value, err := client.DNS01ChallengeRecord(chal.Token)
+		if err != nil {
+			return err
+		}
+		if err := runACMEDNSHook(cfg.DNSHook, "present", domain, value); err != nil {
+			return &acmeDomainError{domain, err}
+		}
+		cleanup = func() {
+			if err := runACMEDNSHook(cfg.DNSHook, "cleanup", domain, value); err != nil {
+				warn("ACME DNS cleanup for %s failed: %v", domain, err)
+			}
+		}
+	}
+	defer cleanup()
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return &acmeDomainError{domain, err}
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return &acmeDomainError{domain, err}
+	}
+	return nil
+}
+
+// runACMEDNSHook runs GRENGO_ACME_DNS_HOOK to publish or remove a DNS-01
+// TXT record. The hook must not return from "present" before the record is
+// visible to the CA.
+func runACMEDNSHook(hook, action, domain, value string) error {
+	cmd := exec.Command("sh", "-c", hook)
+	cmd.Dir = ProjectRoot()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"ACME_ACTION="+action,
+		"ACME_DOMAIN="+domain,
+		"ACME_RECORD=_acme-challenge."+strings.TrimPrefix(domain, "*."),
+		"ACME_VALUE="+value,
+	)
+	return cmd.Run()
+}
+
+// issueClientCertificate orders a certificate for the client's domains. The
+// www. names expandDomains adds are dropped and the order retried when they
+// are what fails validation, since they often lack DNS records.
+func issueClientCertificate(ctx context.Context, client *acme.Client, cfg acmeConfig, c clientInfo) ([]string, error) {
+	domains := tlsDomains(c)
+	configured := configuredDomains(c.Name)
+	for {
+		err := obtainCertificate(ctx, client, cfg, c.Name, domains)
+		var derr *acmeDomainError
+		if err == nil || !errors.As(err, &derr) || configured[derr.Domain] || !strings.HasPrefix(derr.Domain, "www.") {
+			return domains, err
+		}
+		warn("%s: %v - retrying without it", c.Name, err)
+		var kept []string
+		for _, d := range domains {
+			if d != derr.Domain {
+				kept = append(kept, d)
+			}
+		}
+		domains = kept
+	}
+}
+
+// renewCertificates issues certificates for every client that lacks a
+// current one (or for all clients when force is set) and reloads nginx when
+// any changed. It returns the number of failures.
+func renewCertificates(force bool) int {
+	cfg := loadACMEConfig()
+	if !cfg.enabled() {
+		die("ACME is disabled - set GRENGO_ACME_EMAIL in %s", rootEnvFile())
+	}
+	if err := cfg.validate(); err != nil {
+		die("%v", err)
+	}
+	var due []clientInfo
+	now := time.Now()
+	for _, c := range enabledClients() {
+		if len(tlsDomains(c)) == 0 {
+			continue
+		}
+		if reason := certificateDue(c, now); force || reason != "" {
+			due = append(due, c)
+		}
+	}
+	if len(due) == 0 {
+		info("All certificates are current")
+		return 0
+	}
+
+	// HTTP-01 answers come from nginx, so it must already serve the
+	// challenge location.
+	ensureWritableDir(acmeChallengeDir())
+	generateNginxConfig()
+	reloadNginxIfRunning()
+
+	ctx, cancel := context.WithTimeout(context.Background(), acmeOrderTimeout*time.Duration(len(due)))
+	defer cancel()
+	client, err := acmeClient(ctx, cfg)
+	if err != nil {
+		die("%v", err)
+	}
+	failed, issued := 0, 0
+	for _, c := range due {
+		log("Requesting certificate for %s (%s)…", bold(c.Name), strings.Join(tlsDomains(c), " "))
+		domains, err := issueClientCertificate(ctx, client, cfg, c)
+		if err != nil {
+			failed++
+			warn("%s: certificate not issued: %v", c.Name, err)
+			continue
+		}
+		issued++
+		info("%s: certificate issued for %s", c.Name, strings.Join(domains, " "))
+	}
+	if issued > 0 {
+		generateNginxConfig()
+		reloadNginxIfRunning()
+	}
+	return failed
+}
+
+// runTLSRenewLoop starts 'grengo tls renew' from the API whenever a client
+// needs a certificate. Running it as a job keeps ACME failures out of the
+// API process and shows them in the job list.
+func runTLSRenewLoop() {
+	for {
+		if cfg := loadACMEConfig(); cfg.enabled() {
+			now := time.Now()
+			for _, c := range enabledClients() {
+				if certificateDue(c, now) != "" {
+					startGlobalCommand("tls", []string{"renew"})
+					break
+				}
+			}
+		}
+		time.Sleep(acmeRenewInterval)
+	}
+}
+
+// cmdTLSRenew issues or renews certificates that are missing, expiring or
+// no longer cover a client's domains.
+func cmdTLSRenew(force bool) {
+	if failed := renewCertificates(force); failed > 0 {
+		die("%d certificate(s) could not be issued", failed)
+	}
+}
+
+// cmdTLSIssue forces a new certificate for one client.
+func cmdTLSIssue(name string) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	cfg := loadACMEConfig()
+	if !cfg.enabled() {
+		die("ACME is disabled - set GRENGO_ACME_EMAIL in %s", rootEnvFile())
+	}
+	if err := cfg.validate(); err != nil {
+		die("%v", err)
+	}
+	var target *clientInfo
+	for _, c := range enabledClients() {
+		if c.Name == name {
+			target = &c
+			break
+		}
+	}
+	if target == nil {
+		die("Client '%s' is disabled", name)
+	}
+	if len(tlsDomains(*target)) == 0 {
+		die("Client '%s' has no public domains (or TLS=off)", name)
+	}
+	ensureWritableDir(acmeChallengeDir())
+	generateNginxConfig()
+	reloadNginxIfRunning()
+
+	ctx, cancel := context.WithTimeout(context.Background(), acmeOrderTimeout)
+	defer cancel()
+	client, err := acmeClient(ctx, cfg)
+	if err != nil {
+		die("%v", err)
+	}
+	domains, err := issueClientCertificate(ctx, client, cfg, *target)
+	if err != nil {
+		die("Certificate not issued: %v", err)
+	}
+	log("Certificate issued for %s", strings.Join(domains, " "))
+	generateNginxConfig()
+	reloadNginxIfRunning()
+}
+
+// cmdTLSStatus lists each client's certificate and when it renews.
+func cmdTLSStatus() {
+	cfg := loadACMEConfig()
+	if cfg.enabled() {
+		info("ACME: %s via %s (%s)", cfg.Email, cfg.Directory, cfg.Challenge)
+	} else {
+		warn("ACME is disabled - set GRENGO_ACME_EMAIL to issue certificates")
+	}
+	now := time.Now()
+	fmt.Printf("%s%-20s %-12s %-12s %s%s\n", colorBold, "CLIENT", "EXPIRES", "STATE", "DOMAINS", colorReset)
+	for _, c := range enabledClients() {
+		domains := tlsDomains(c)
+		if len(domains) == 0 {
+			fmt.Printf("%-20s %-12s %-12s %s\n", c.Name, "-", "http only", strings.Join(c.Domains, " "))
+			continue
+		}
+		expires := "-"
+		if leaf, err := clientCertificate(c.Name); err == nil {
+			expires = leaf.NotAfter.Format("2006-01-02")
+		}
+		state, color := "ok", colorGreen
+		if reason := certificateDue(c, now); reason != "" {
+			state, color = "renew", colorYellow
+			domains = append(domains, "("+reason+")")
+		}
+		fmt.Printf("%-20s %-12s %s%-12s%s %s\n", c.Name, expires, color, state, colorReset, strings.Join(domains, " "))
+	}
+}
@@ -0,0 +1,328 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate stores a self-signed certificate for hosts as the
+// client's certificate.
+func writeTestCertificate(t *testing.T, name string, hosts []string, notAfter time.Time) {
+	t.Helper()
+	key, keyPEM, err := newECDSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeClientCertificate(name, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), keyPEM); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertificateDue(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	writeTestClient(t, "shop", "1080", "DOMAINS=shop.example.com\n")
+	writeTestClient(t, "local", "1081", "DOMAINS=localhost 127.0.0.1\n")
+	writeTestClient(t, "optout", "1082", "DOMAINS=optout.example.com\nTLS=off\n")
+	writeTestClient(t, "blog", "1083", "DOMAINS=blog.example.com www.blog.example.com\n")
+
+	now := time.Now()
+	clients := map[string]clientInfo{}
+	for _, c := range enabledClients() {
+		clients[c.Name] = c
+	}
+	if got := certificateDue(clients["local"], now); got != "" {
+		t.Fatalf("localhost client due: %q", got)
+	}
+	if got := certificateDue(clients["optout"], now); got != "" {
+		t.Fatalf("TLS=off client due: %q", got)
+	}
+	if got := certificateDue(clients["shop"], now); got != "no certificate" {
+		t.Fatalf("missing certificate: %q", got)
+	}
+
+	writeTestCertificate(t, "blog", []string{"blog.example.com"}, now.Add(60*24*time.Hour))
+	if got := certificateDue(clients["blog"], now); got != "does not cover www.blog.example.com" {
+		t.Fatalf("partial certificate: %q", got)
+	}
+	writeTestCertificate(t, "shop", []string{"shop.example.com"}, now.Add(60*24*time.Hour))
+	if got := certificateDue(clients["shop"], now); got != "" {
+		t.Fatalf("certificate without the derived www name due: %q", got)
+	}
+	if info, err := os.Stat(clientTLSFile("shop", "privkey.pem")); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Fatalf("private key mode %v, want 0600", info.Mode().Perm())
+	}
+	writeTestCertificate(t, "shop", []string{"shop.example.com", "www.shop.example.com"}, now.Add(60*24*time.Hour))
+	if got := certificateDue(clients["shop"], now); got != "" {
+		t.Fatalf("current certificate due: %q", got)
+	}
+	writeTestCertificate(t, "shop", []string{"shop.example.com", "www.shop.example.com"}, now.Add(10*24*time.Hour))
+	if got := certificateDue(clients["shop"], now); !strings.HasPrefix(got, "expires ") {
+		t.Fatalf("expiring certificate: %q", got)
+	}
+}
+
+// fakeACMEServer is a minimal RFC 8555 CA. Authorizations for www. names
+// offer no HTTP-01 challenge, the way a missing DNS record fails them;
+// other names are already valid. It counts the orders placed.
+func fakeACMEServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	caKey, _, err := newECDSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	var mu sync.Mutex
+	orders := 0
+	identifiers := map[string][]map[string]string{}
+	certs := map[string][]byte{}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", time.Now().UnixNano()))
+		var jws struct{ Payload string }
+		json.NewDecoder(r.Body).Decode(&jws)
+		payload, _ := base64.RawURLEncoding.DecodeString(jws.Payload)
+		reply := func(status int, v any) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(v)
+		}
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch parts[0] {
+		case "directory":
+			reply(http.StatusOK, map[string]string{
+				"newNonce": srv.URL + "/nonce", "newAccount": srv.URL + "/account",
+				"newOrder": srv.URL + "/order", "revokeCert": srv.URL + "/revoke", "keyChange": srv.URL + "/key",
+			})
+		case "nonce":
+			w.WriteHeader(http.StatusOK)
+		case "account":
+			w.Header().Set("Location", srv.URL+"/account/1")
+			reply(http.StatusCreated, map[string]string{"status": "valid"})
+		case "order":
+			if len(parts) == 1 {
+				orders++
+				var req struct{ Identifiers []map[string]string }
+				json.Unmarshal(payload, &req)
+				parts = append(parts, fmt.Sprint(orders))
+				identifiers[parts[1]] = req.Identifiers
+				w.Header().Set("Location", srv.URL+"/order/"+parts[1])
+			}
+			var authz []string
+			for _, id := range identifiers[parts[1]] {
+				authz = append(authz, srv.URL+"/authz/"+id["value"])
+			}
+			status, code := "ready", http.StatusOK
+			if w.Header().Get("Location") != "" {
+				status, code = "pending", http.StatusCreated
+			}
+			reply(code, map[string]any{
+				"status": status, "identifiers": identifiers[parts[1]], "authorizations": authz,
+				"finalize": srv.URL + "/finalize/" + parts[1],
+			})
+		case "authz":
+			domain := parts[1]
+			if strings.HasPrefix(domain, "www.") {
+				reply(http.StatusOK, map[string]any{
+					"status": "pending", "identifier": map[string]string{"type": "dns", "value": domain},
+					"challenges": []map[string]string{{"type": "dns-01", "url": srv.URL + "/chal", "token": "t", "status": "pending"}},
+				})
+				return
+			}
+			reply(http.StatusOK, map[string]any{
+				"status": "valid", "identifier": map[string]string{"type": "dns", "value": domain}, "challenges": []any{},
+			})
+		case "finalize":
+			var req struct{ CSR string }
+			json.Unmarshal(payload, &req)
+			der, _ := base64.RawURLEncoding.DecodeString(req.CSR)
+			csr, err := x509.ParseCertificateRequest(der)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			leaf := &x509.Certificate{
+				SerialNumber: big.NewInt(time.Now().UnixNano()),
+				Subject:      pkix.Name{CommonName: csr.DNSNames[0]},
+				DNSNames:     csr.DNSNames,
+				NotBefore:    time.Now().Add(-time.Hour),
+				NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+			}
+			leafDER, err := x509.CreateCertificate(rand.Reader, leaf, caCert, csr.PublicKey, caKey)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			certs[parts[1]] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+			w.Header().Set("Location", srv.URL+"/order/"+parts[1])
+			reply(http.StatusOK, map[string]any{"status": "valid", "certificate": srv.URL + "/cert/" + parts[1]})
+		case "cert":
+			w.Header().Set("Content-Type", "application/pem-certificate-chain")
+			w.Write(certs[parts[1]])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &orders
+}
+
+// TestRenewDropsFailingWWWNameOnce covers a client whose derived www. name
+// fails validation: the name is left off the certificate, and later
+// renewals must not order again until the certificate nears expiry.
+func TestRenewDropsFailingWWWNameOnce(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GRENGO_ROOT", root)
+	ca, orders := fakeACMEServer(t)
+	os.WriteFile(filepath.Join(root, ".env"), []byte("GRENGO_ACME_EMAIL=ops@example.com\nGRENGO_ACME_DIRECTORY="+ca.URL+"/directory\n"), 0644)
+	writeTestClient(t, "shop", "1080", "DOMAINS=shop.example.com\n")
+
+	if failed := renewCertificates(false); failed != 0 {
+		t.Fatalf("%d certificate(s) failed", failed)
+	}
+	leaf, err := clientCertificate("shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "shop.example.com" || *orders != 2 {
+		t.Fatalf("certificate names %v after %d orders, want the apex after a retry", leaf.DNSNames, *orders)
+	}
+
+	if failed := renewCertificates(false); failed != 0 || *orders != 2 {
+		t.Fatalf("renewal reordered for the dropped www name: %d failed, %d orders", failed, *orders)
+	}
+}
+
+func TestGenerateNginxConfigServesCertifiedClientsOverHTTPS(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GRENGO_ROOT", root)
+	os.WriteFile(filepath.Join(root, ".env"), []byte("GRENGO_HSTS_MAX_AGE=600\n"), 0644)
+	writeTestClient(t, "writer", "1080", "DOMAINS=thewriterco.com\n")
+	writeTestClient(t, "skaia", "1081", "DOMAINS=skaiacraft.com\n")
+	writeTestCertificate(t, "writer", []string{"thewriterco.com"}, time.Now().Add(60*24*time.Hour))
+
+	generateNginxConfig()
+	data, err := os.ReadFile(filepath.Join(root, "nginx", "default.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := string(data)
+
+	for _, expected := range []string{
+		"server {\n    listen 80;\n    server_name skaiacraft.com ~^site[0-9]+\\.skaiacraft\\.com$ www.skaiacraft.com ~^site[0-9]+\\.thewriterco\\.com$ www.thewriterco.com;",
+		"server {\n    listen 80;\n    server_name thewriterco.com;\n",
+		"return 301 https://$host$request_uri;",
+		"server {\n    listen 443 ssl;\n    http2 on;\n    server_name thewriterco.com;\n",
+		"ssl_certificate     /etc/nginx/tls/writer/fullchain.pem;",
+		`add_header Strict-Transport-Security "max-age=600" always;`,
+		"location ^~ /.well-known/acme-challenge/",
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("generated config missing %q", expected)
+		}
+	}
+	if strings.Count(config, "listen 443 ssl") != 1 {
+		t.Errorf("expected one HTTPS block:\n%s", config)
+	}
+	if strings.Count(config, "Strict-Transport-Security") != 1 {
+		t.Error("HSTS sent outside the HTTPS block")
+	}
+	if !strings.Contains(nginxDefaultServerBlock(), "/.well-known/acme-challenge/") {
+		t.Error("default server does not answer ACME challenges")
+	}
+
+	// Expired certificates fall back to plain HTTP.
+	writeTestCertificate(t, "writer", []string{"thewriterco.com"}, time.Now().Add(-time.Hour))
+	generateNginxConfig()
+	data, _ = os.ReadFile(filepath.Join(root, "nginx", "default.conf"))
+	if strings.Contains(string(data), "listen 443") {
+		t.Fatal("expired certificate still served")
+	}
+}
+
+// TestACMEPebble issues a real certificate from a Pebble server. It runs
+// when GRENGO_TEST_PEBBLE_DIR names the directory URL, e.g.
+// https://localhost:14000/dir, with GRENGO_TEST_PEBBLE_CA pointing at
+// pebble.minica.pem. Pebble must resolve the test domain to this host
+// (pebble-challtestsrv -defaultIPv4 127.0.0.1) and validate HTTP-01 on
+// GRENGO_TEST_PEBBLE_HTTP_PORT (default 5002).
+func TestACMEPebble(t *testing.T) {
+	dir := os.Getenv("GRENGO_TEST_PEBBLE_DIR")
+	if dir == "" {
+		t.Skip("GRENGO_TEST_PEBBLE_DIR not set")
+	}
+	root := t.TempDir()
+	t.Setenv("GRENGO_ROOT", root)
+	env := "GRENGO_ACME_EMAIL=ops@example.com\nGRENGO_ACME_DIRECTORY=" + dir + "\nGRENGO_ACME_CA_CERT=" + os.Getenv("GRENGO_TEST_PEBBLE_CA") + "\n"
+	os.WriteFile(filepath.Join(root, ".env"), []byte(env), 0644)
+	writeTestClient(t, "shop", "1080", "DOMAINS=shop.grengo.test\n")
+
+	port := os.Getenv("GRENGO_TEST_PEBBLE_HTTP_PORT")
+	if port == "" {
+		port = "5002"
+	}
+	// Stands in for nginx's /.well-known/acme-challenge/ location.
+	ensureWritableDir(acmeChallengeDir())
+	srv := httptest.NewUnstartedServer(http.StripPrefix("/.well-known/acme-challenge/", http.FileServer(http.Dir(acmeChallengeDir()))))
+	l, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", ":"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+
+	if failed := renewCertificates(false); failed != 0 {
+		t.Fatalf("%d certificate(s) failed", failed)
+	}
+	leaf, err := clientCertificate("shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.VerifyHostname("shop.grengo.test") != nil {
+		t.Fatalf("certificate names %v", leaf.DNSNames)
+	}
+}
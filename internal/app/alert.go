@@ -0,0 +1,813 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	alertKindSiteUnreachable = "site_unreachable"
+	alertKindContainerMemory = "container_memory"
+	alertKindDiskUsage       = "disk_usage"
+	alertKindJobFailed       = "job_failed"
+
+	alertStatusFiring   = "firing"
+	alertStatusResolved = "resolved"
+
+	defaultAlertInterval = 30 * time.Second
+	defaultAlertRepeat   = 4 * time.Hour
+	defaultAlertChecks   = 3
+)
+
+// alertConfig is the declarative alerting setup kept in alerts.yaml.
+type alertConfig struct {
+	Interval  time.Duration            `yaml:"interval"`
+	Repeat    string                   `yaml:"repeat"` // "off" notifies once per alert
+	Notifiers map[string]alertNotifier `yaml:"notifiers"`
+	Rules     []alertRule              `yaml:"rules"`
+	Silences  []alertSilence           `yaml:"silences"`
+
+	repeat time.Duration
+}
+
+// alertRule fires one alert per matching target: a site or client, a
+// container, a filesystem path or a failed job.
+type alertRule struct {
+	Name     string   `yaml:"name"`
+	Kind     string   `yaml:"kind"`
+	Match    string   `yaml:"match"`    // glob on the target (job type for job_failed)
+	Checks   int      `yaml:"checks"`   // site_unreachable: failed checks in a row
+	Above    float64  `yaml:"above"`    // container_memory, disk_usage: percent
+	Paths    []string `yaml:"paths"`    // disk_usage: defaults to the project root
+	Severity string   `yaml:"severity"` // warning (default) or critical
+	Notify   []string `yaml:"notify"`
+}
+
+// alertNotifier delivers notices by e-mail, to a webhook or to the inbox of
+// users on a client's site.
+type alertNotifier struct {
+	Type   string   `yaml:"type"`   // smtp, webhook or inbox
+	To     []string `yaml:"to"`     // smtp
+	URL    string   `yaml:"url"`    // webhook
+	Secret string   `yaml:"secret"` // webhook: signs the body as X-Grengo-Signature
+	Client string   `yaml:"client"` // inbox
+	Users  []int64  `yaml:"users"`  // inbox: user ids on that client
+}
+
+// alertSilence mutes matching alerts, either daily between two local times
+// or between from and until. Silences from 'grengo alert silence' are kept
+// in the management DB.
+type alertSilence struct {
+	ID      int64     `yaml:"-" json:"id"`
+	Rule    string    `yaml:"rule" json:"rule"`
+	Target  string    `yaml:"target" json:"target"`
+	Daily   string    `yaml:"daily" json:"-"` // "HH:MM-HH:MM"
+	From    time.Time `yaml:"from" json:"starts_at"`
+	Until   time.Time `yaml:"until" json:"ends_at"`
+	Comment string    `yaml:"comment" json:"comment"`
+}
+
+func alertConfigFile() string {
+	return filepath.Join(ProjectRoot(), "alerts.yaml")
+}
+
+// loadAlertConfig reads and validates alerts.yaml. A missing file returns an
+// error wrapping os.ErrNotExist.
+func loadAlertConfig(file string) (alertConfig, error) {
+	var cfg alertConfig
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return cfg, err
+	}
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("parse %s: %v", file, err)
+	}
+	return cfg, validateAlertConfig(&cfg)
+}
+
+func validateAlertConfig(cfg *alertConfig) error {
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultAlertInterval
+	}
+	if cfg.Interval < 5*time.Second {
+		return fmt.Errorf("interval must be at least 5s")
+	}
+	switch cfg.Repeat {
+	case "":
+		cfg.repeat = defaultAlertRepeat
+	case "off":
+		cfg.repeat = 0
+	default:
+		d, err := time.ParseDuration(cfg.Repeat)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid repeat %q (a duration or off)", cfg.Repeat)
+		}
+		cfg.repeat = d
+	}
+
+	for name, n := range cfg.Notifiers {
+		switch n.Type {
+		case "smtp":
+			if len(n.To) == 0 {
+				return fmt.Errorf("notifier %s: smtp needs at least one address in to", name)
+			}
+		case "webhook":
+			if !strings.HasPrefix(n.URL, "http://") && !strings.HasPrefix(n.URL, "https://") {
+				return fmt.Errorf("notifier %s: webhook needs an http(s) url", name)
+			}
+		case "inbox":
+			if msg := nameError(n.Client); msg != "" {
+				return fmt.Errorf("notifier %s: client %q: %s", name, n.Client, msg)
+			}
+			if len(n.Users) == 0 {
+				return fmt.Errorf("notifier %s: inbox needs at least one user id", name)
+			}
+		default:
+			return fmt.Errorf("notifier %s: unknown type %q (smtp, webhook or inbox)", name, n.Type)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := range cfg.Rules {
+		r := &cfg.Rules[i]
+		if r.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("rule %s is defined twice", r.Name)
+		}
+		seen[r.Name] = true
+		switch r.Kind {
+		case alertKindSiteUnreachable:
+			if r.Checks == 0 {
+				r.Checks = defaultAlertChecks
+			}
+			if r.Checks < 1 {
+				return fmt.Errorf("rule %s: checks must be positive", r.Name)
+			}
+		case alertKindContainerMemory, alertKindDiskUsage:
+			if r.Above <= 0 || r.Above > 100 {
+				return fmt.Errorf("rule %s: above must be a percentage between 0 and 100", r.Name)
+			}
+		case alertKindJobFailed:
+		default:
+			return fmt.Errorf("rule %s: unknown kind %q", r.Name, r.Kind)
+		}
+		if r.Kind == alertKindDiskUsage && len(r.Paths) == 0 {
+			r.Paths = []string{ProjectRoot()}
+		}
+		if _, err := path.Match(r.Match, ""); err != nil {
+			return fmt.Errorf("rule %s: invalid match %q", r.Name, r.Match)
+		}
+		switch r.Severity {
+		case "":
+			r.Severity = "warning"
+		case "warning", "critical":
+		default:
+			return fmt.Errorf("rule %s: severity must be warning or critical", r.Name)
+		}
+		for _, n := range r.Notify {
+			if _, ok := cfg.Notifiers[n]; !ok {
+				return fmt.Errorf("rule %s: unknown notifier %s", r.Name, n)
+			}
+		}
+	}
+
+	for i, s := range cfg.Silences {
+		if s.Daily == "" && s.Until.IsZero() {
+			return fmt.Errorf("silence %d needs daily or until", i+1)
+		}
+		if s.Daily != "" {
+			if _, _, err := parseDailyWindow(s.Daily); err != nil {
+				return fmt.Errorf("silence %d: %v", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseDailyWindow parses "HH:MM-HH:MM" into minutes after midnight. The end
+// may be before the start for windows that span midnight.
+func parseDailyWindow(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("daily window %q is not HH:MM-HH:MM", s)
+	}
+	parse := func(v string) (int, error) {
+		t, err := time.Parse("15:04", strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("daily window %q is not HH:MM-HH:MM", s)
+		}
+		return t.Hour()*60 + t.Minute(), nil
+	}
+	if start, err = parse(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parse(to); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// alertGlob matches a rule or target pattern; empty and "*" match anything.
+func alertGlob(pattern, s string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
+
+func (s alertSilence) active(now time.Time) bool {
+	if !s.From.IsZero() && now.Before(s.From) {
+		return false
+	}
+	if !s.Until.IsZero() && !now.Before(s.Until) {
+		return false
+	}
+	if s.Daily == "" {
+		return true
+	}
+	start, end, err := parseDailyWindow(s.Daily)
+	if err != nil {
+		return false
+	}
+	local := now.Local()
+	m := local.Hour()*60 + local.Minute()
+	if start <= end {
+		return m >= start && m < end
+	}
+	return m >= start || m < end
+}
+
+func (s alertSilence) matches(rule, target string) bool {
+	return alertGlob(s.Rule, rule) && alertGlob(s.Target, target)
+}
+
+func alertSilenced(silences []alertSilence, rule, target string, now time.Time) bool {
+	for _, s := range silences {
+		if s.active(now) && s.matches(rule, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// diskUsage is one filesystem as df reports it.
+type diskUsage struct {
+	Path  string
+	Used  uint64
+	Avail uint64
+}
+
+func (d diskUsage) percent() float64 {
+	if d.Used+d.Avail == 0 {
+		return 0
+	}
+	return float64(d.Used) / float64(d.Used+d.Avail) * 100
+}
+
+func statDisk(p string) (diskUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(p, &st); err != nil {
+		return diskUsage{}, err
+	}
+	bsize := uint64(st.Bsize)
+	return diskUsage{Path: p, Used: (st.Blocks - st.Bfree) * bsize, Avail: st.Bavail * bsize}, nil
+}
+
+// alertSnapshot is what one evaluation sees of the node.
+type alertSnapshot struct {
+	Probes     map[string]bool // site or client name to whether it answered
+	Containers []containerStats
+	Disks      []diskUsage
+	Jobs       []jobStatus
+}
+
+// alertNotice is one notification to send for an alert.
+type alertNotice struct {
+	Rule   alertRule
+	Alert  alertRecord
+	Status string
+}
+
+// alertEngine holds the firing alerts between evaluations so each alert
+// notifies once when it fires, again every repeat interval, and once when it
+// resolves.
+type alertEngine struct {
+	mu          sync.Mutex
+	active      map[string]*alertRecord
+	failures    map[string]int
+	seenJobs    map[string]bool
+	frappeSites []string
+	probes      map[string]bool
+}
+
+func newAlertEngine() *alertEngine {
+	return &alertEngine{
+		active:   map[string]*alertRecord{},
+		failures: map[string]int{},
+		seenJobs: map[string]bool{},
+	}
+}
+
+// alerts is the API process's engine; /metrics reads it.
+var alerts = newAlertEngine()
+
+func alertKey(rule, target string) string { return rule + "\x00" + target }
+
+// restore seeds the engine with alerts left firing by a previous API run, so
+// a restart does not notify them again.
+func (e *alertEngine) restore(records []alertRecord) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range records {
+		rec := records[i]
+		e.active[alertKey(rec.Rule, rec.Target)] = &rec
+	}
+}
+
+type alertCondition struct {
+	rule    alertRule
+	target  string
+	summary string
+	event   bool // fires once and resolves without a notice
+}
+
+// evaluate compares the snapshot against the rules. It returns the notices
+// to send and the alerts whose stored state changed; alerts with ID 0 are
+// new.
+func (e *alertEngine) evaluate(cfg alertConfig, snap alertSnapshot, silences []alertSilence, now time.Time) ([]alertNotice, []*alertRecord) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for target := range e.failures {
+		if _, ok := snap.Probes[target]; !ok {
+			delete(e.failures, target)
+		}
+	}
+	for target, up := range snap.Probes {
+		if up {
+			delete(e.failures, target)
+		} else {
+			e.failures[target]++
+		}
+	}
+	e.probes = snap.Probes
+
+	var conditions []alertCondition
+	for _, r := range cfg.Rules {
+		switch r.Kind {
+		case alertKindSiteUnreachable:
+			for target, n := range e.failures {
+				_, firing := e.active[alertKey(r.Name, target)]
+				if alertGlob(r.Match, target) && (n >= r.Checks || firing) {
+					conditions = append(conditions, alertCondition{rule: r, target: target,
+						summary: fmt.Sprintf("%s is unreachable (%d failed checks in a row)", target, n)})
+				}
+			}
+		case alertKindContainerMemory:
+			for _, c := range snap.Containers {
+				if alertGlob(r.Match, c.Name) && c.MemMax > 0 && c.MemPct >= r.Above {
+					conditions = append(conditions, alertCondition{rule: r, target: c.Name,
+						summary: fmt.Sprintf("%s memory at %.1f%% (%s of %s)", c.Name, c.MemPct, c.MemUsage, c.MemLimit)})
+				}
+			}
+		case alertKindDiskUsage:
+			for _, d := range snap.Disks {
+				if containsString(r.Paths, d.Path) && d.percent() >= r.Above {
+					conditions = append(conditions, alertCondition{rule: r, target: d.Path,
+						summary: fmt.Sprintf("%s at %.1f%% (%s free)", d.Path, d.percent(), humanBytes(d.Avail))})
+				}
+			}
+		case alertKindJobFailed:
+			for _, j := range snap.Jobs {
+				if j.Status == "failed" && !e.seenJobs[j.ID] && alertGlob(r.Match, j.Type) {
+					summary := fmt.Sprintf("%s job %s failed", j.Type, j.ID)
+					if j.Target != "" {
+						summary = fmt.Sprintf("%s job for %s failed", j.Type, j.Target)
+					}
+					if j.Error != "" {
+						summary += ": " + j.Error
+					}
+					conditions = append(conditions, alertCondition{rule: r, target: j.ID, summary: summary, event: true})
+				}
+			}
+		}
+	}
+	for _, j := range snap.Jobs {
+		if j.Status == "failed" {
+			e.seenJobs[j.ID] = true
+		}
+	}
+
+	var notices []alertNotice
+	var changed []*alertRecord
+	current := map[string]bool{}
+	for _, c := range conditions {
+		key := alertKey(c.rule.Name, c.target)
+		current[key] = true
+		rec, ok := e.active[key]
+		if !ok {
+			rec = &alertRecord{Rule: c.rule.Name, Kind: c.rule.Kind, Target: c.target, Severity: c.rule.Severity, State: alertStatusFiring, StartedAt: now}
+			e.active[key] = rec
+			changed = append(changed, rec)
+		}
+		rec.Summary = c.summary
+		silenced := alertSilenced(silences, c.rule.Name, c.target, now)
+		if !silenced && (rec.NotifiedAt == nil || (cfg.repeat > 0 && now.Sub(*rec.NotifiedAt) >= cfg.repeat)) {
+			at := now
+			rec.NotifiedAt = &at
+			notices = append(notices, alertNotice{Rule: c.rule, Alert: *rec, Status: alertStatusFiring})
+			if ok {
+				changed = append(changed, rec)
+			}
+		}
+		if c.event {
+			rec.State = alertStatusResolved
+			rec.ResolvedAt = &now
+			delete(e.active, key)
+		}
+	}
+
+	rules := map[string]alertRule{}
+	for _, r := range cfg.Rules {
+		rules[r.Name] = r
+	}
+	keys := make([]string, 0, len(e.active))
+	for key := range e.active {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if current[key] {
+			continue
+		}
+		rec := e.active[key]
+		delete(e.active, key)
+		at := now
+		rec.State, rec.ResolvedAt = alertStatusResolved, &at
+		changed = append(changed, rec)
+		r, ok := rules[rec.Rule]
+		if ok && rec.NotifiedAt != nil && !alertSilenced(silences, rec.Rule, rec.Target, now) {
+			notices = append(notices, alertNotice{Rule: r, Alert: *rec, Status: alertStatusResolved})
+		}
+	}
+	return notices, changed
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// firing returns the firing alerts sorted by rule and target.
+func (e *alertEngine) firing() []alertRecord {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]alertRecord, 0, len(e.active))
+	for _, rec := range e.active {
+		out = append(out, *rec)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return alertKey(out[i].Rule, out[i].Target) < alertKey(out[j].Rule, out[j].Target)
+	})
+	return out
+}
+
+// lastProbes returns the outcome of the latest site and client checks.
+func (e *alertEngine) lastProbes() map[string]bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.probes
+}
+
+// probeSites checks every Frappe site and every running client. When the
+// Frappe API stops answering, the sites it listed last count as down.
+func (e *alertEngine) probeSites() (probes map[string]bool, newlyDownFrappe bool) {
+	probes = map[string]bool{}
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	sites, err := listSites()
+	e.mu.Lock()
+	if err == nil {
+		e.frappeSites = sites
+	} else {
+		sites = e.frappeSites
+	}
+	e.mu.Unlock()
+	for _, site := range sites {
+		up := false
+		if err == nil {
+			st := checkSite(client, site)
+			up = st.APIReachable && st.HTTPReachable
+		}
+		probes[site] = up
+		e.mu.Lock()
+		newlyDownFrappe = newlyDownFrappe || (!up && e.failures[site] == 0)
+		e.mu.Unlock()
+	}
+
+	for _, c := range enabledClients() {
+		if !clientRunning(c.Name) {
+			continue
+		}
+		resp, err := client.Get("http://127.0.0.1:" + c.Port + "/health")
+		up := err == nil && resp.StatusCode < 400
+		if err == nil {
+			resp.Body.Close()
+		}
+		probes[c.Name] = up
+	}
+	return probes, newlyDownFrappe
+}
+
+// snapshot gathers only what cfg's rules look at.
+func (e *alertEngine) snapshot(cfg alertConfig) (alertSnapshot, bool) {
+	var snap alertSnapshot
+	var newlyDownFrappe bool
+	kinds := map[string]bool{}
+	var paths []string
+	for _, r := range cfg.Rules {
+		kinds[r.Kind] = true
+		for _, p := range r.Paths {
+			if !containsString(paths, p) {
+				paths = append(paths, p)
+			}
+		}
+	}
+	if kinds[alertKindSiteUnreachable] {
+		snap.Probes, newlyDownFrappe = e.probeSites()
+	}
+	if kinds[alertKindContainerMemory] {
+		snap.Containers = gatherStats()
+	}
+	for _, p := range paths {
+		if d, err := statDisk(p); err == nil {
+			snap.Disks = append(snap.Disks, d)
+		}
+	}
+	if kinds[alertKindJobFailed] {
+		jobsMu.RLock()
+		for _, j := range jobs {
+			snap.Jobs = append(snap.Jobs, *j)
+		}
+		jobsMu.RUnlock()
+	}
+	return snap, newlyDownFrappe
+}
+
+// runAlertLoop evaluates alerts.yaml in the API process. Without the file
+// it only waits for one to appear.
+func runAlertLoop() {
+	svc := newGrengoService()
+	prefix := logPrefix("alert")
+	restored := false
+	lastErr := ""
+	for {
+		cfg, err := loadAlertConfig(alertConfigFile())
+		interval := defaultAlertInterval
+		if err != nil {
+			if msg := err.Error(); !errors.Is(err, os.ErrNotExist) && msg != lastErr {
+				BroadcastLog("ERROR", prefix, fmt.Sprintf("alert rules not loaded: %v", err))
+				lastErr = msg
+			}
+			time.Sleep(interval)
+			continue
+		}
+		lastErr = ""
+		interval = cfg.Interval
+
+		if !restored {
+			if firing, err := svc.ListAlerts(true, 1000); err == nil {
+				alerts.restore(firing)
+			}
+			restored = true
+		}
+		silences := cfg.Silences
+		if stored, err := svc.ListAlertSilences(); err == nil {
+			silences = append(silences, stored...)
+		}
+
+		snap, newlyDownFrappe := alerts.snapshot(cfg)
+		if newlyDownFrappe {
+			// One reload per outage is worth a try: stale upstreams are the
+			// usual reason a Frappe site stops answering after a deploy.
+			if ok, err := triggerDeploy(); err != nil || !ok {
+				BroadcastLog("WARN", prefix, fmt.Sprintf("Frappe nginx reload failed: %v", err))
+			}
+		}
+		notices, changed := alerts.evaluate(cfg, snap, silences, time.Now())
+		for _, rec := range changed {
+			if rec.ID == 0 {
+				if id, err := svc.OpenAlert(*rec); err == nil {
+					rec.ID = id
+				}
+			} else {
+				svc.UpdateAlert(*rec)
+			}
+		}
+		for _, n := range notices {
+			level := "WARN"
+			if n.Status == alertStatusResolved {
+				level = "INFO"
+			}
+			BroadcastLog(level, prefix, fmt.Sprintf("%s %s: %s", strings.ToUpper(n.Status), n.Rule.Name, n.Alert.Summary))
+			deliverAlert(cfg, n)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// cmdAlertRules validates alerts.yaml and prints what it configures.
+func cmdAlertRules() {
+	cfg, err := loadAlertConfig(alertConfigFile())
+	if errors.Is(err, os.ErrNotExist) {
+		info("No alert rules: %s does not exist", alertConfigFile())
+		return
+	}
+	if err != nil {
+		die("%v", err)
+	}
+	log("%s is valid (checked every %s)", alertConfigFile(), cfg.Interval)
+	fmt.Println()
+	fmt.Printf("  %-20s %-18s %-9s %-24s %s\n", "RULE", "KIND", "SEVERITY", "CONDITION", "NOTIFY")
+	for _, r := range cfg.Rules {
+		cond := ""
+		switch r.Kind {
+		case alertKindSiteUnreachable:
+			cond = fmt.Sprintf("%d failed checks", r.Checks)
+		case alertKindContainerMemory:
+			cond = fmt.Sprintf("memory >= %g%%", r.Above)
+		case alertKindDiskUsage:
+			cond = fmt.Sprintf("%s >= %g%%", strings.Join(r.Paths, ","), r.Above)
+		case alertKindJobFailed:
+			cond = "any failed job"
+		}
+		if r.Match != "" {
+			cond += " on " + r.Match
+		}
+		fmt.Printf("  %-20s %-18s %-9s %-24s %s\n", r.Name, r.Kind, r.Severity, cond, strings.Join(r.Notify, ","))
+	}
+	fmt.Println()
+	names := make([]string, 0, len(cfg.Notifiers))
+	for name := range cfg.Notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := cfg.Notifiers[name]
+		dest := n.URL
+		switch n.Type {
+		case "smtp":
+			dest = strings.Join(n.To, ", ")
+		case "inbox":
+			ids := make([]string, len(n.Users))
+			for i, id := range n.Users {
+				ids[i] = strconv.FormatInt(id, 10)
+			}
+			dest = fmt.Sprintf("users %s on %s", strings.Join(ids, ","), n.Client)
+		}
+		fmt.Printf("  %-20s %-8s %s\n", name, n.Type, dest)
+	}
+	repeat := "off"
+	if cfg.repeat > 0 {
+		repeat = cfg.repeat.String()
+	}
+	fmt.Printf("\n  Firing alerts notify again every %s.\n", repeat)
+}
+
+// cmdAlertList prints firing alerts, or the latest alerts with all.
+func cmdAlertList(all bool, limit int) {
+	records, err := newGrengoService().ListAlerts(!all, limit)
+	if err != nil {
+		die("Cannot list alerts: %v", err)
+	}
+	if len(records) == 0 {
+		if all {
+			info("No alerts recorded")
+		} else {
+			info("No alerts firing")
+		}
+		return
+	}
+	fmt.Printf("  %-6s %-9s %-18s %-9s %-17s %s\n", "ID", "STATE", "RULE", "SEVERITY", "SINCE", "SUMMARY")
+	for _, a := range records {
+		state := fmt.Sprintf("%-9s", a.State)
+		if a.State == alertStatusFiring {
+			state = colorRed + state + colorReset
+		}
+		fmt.Printf("  %-6d %s %-18s %-9s %-17s %s\n", a.ID, state, a.Rule, a.Severity, a.StartedAt.Local().Format("2006-01-02 15:04"), a.Summary)
+	}
+}
+
+// cmdAlertSilence mutes rule (a glob) on target for a duration like 2h.
+func cmdAlertSilence(rule, target, duration, comment string) {
+	d, err := time.ParseDuration(duration)
+	if err != nil || d <= 0 {
+		die("Invalid silence duration %q (e.g. 30m or 2h)", duration)
+	}
+	if _, err := path.Match(rule, ""); err != nil {
+		die("Invalid rule pattern %q", rule)
+	}
+	if _, err := path.Match(target, ""); err != nil {
+		die("Invalid target pattern %q", target)
+	}
+	if target == "" {
+		target = "*"
+	}
+	now := time.Now()
+	s := alertSilence{Rule: rule, Target: target, Comment: comment, From: now, Until: now.Add(d)}
+	id, err := newGrengoService().AddAlertSilence(s)
+	if err != nil {
+		die("Cannot store silence: %v", err)
+	}
+	log("Silence %d mutes %s on %s until %s", id, rule, target, s.Until.Local().Format("2006-01-02 15:04"))
+}
+
+func cmdAlertUnsilence(id int) {
+	if err := newGrengoService().DeleteAlertSilence(int64(id)); err != nil {
+		die("Cannot remove silence: %v", err)
+	}
+	log("Silence %d removed", id)
+}
+
+// cmdAlertSilences lists the silences from alerts.yaml and the management DB.
+func cmdAlertSilences() {
+	var silences []alertSilence
+	if cfg, err := loadAlertConfig(alertConfigFile()); err == nil {
+		silences = cfg.Silences
+	}
+	stored, err := newGrengoService().ListAlertSilences()
+	if err != nil {
+		warn("Cannot read stored silences: %v", err)
+	}
+	silences = append(silences, stored...)
+	if len(silences) == 0 {
+		info("No silences")
+		return
+	}
+	fmt.Printf("  %-6s %-18s %-24s %-28s %s\n", "ID", "RULE", "TARGET", "WINDOW", "COMMENT")
+	for _, s := range silences {
+		id := "file"
+		if s.ID > 0 {
+			id = strconv.FormatInt(s.ID, 10)
+		}
+		window := "daily " + s.Daily
+		if s.Daily == "" {
+			window = "until " + s.Until.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("  %-6s %-18s %-24s %-28s %s\n", id, orDefault(s.Rule, "*"), orDefault(s.Target, "*"), window, s.Comment)
+	}
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// cmdAlertTest sends a test notice through one notifier.
+func cmdAlertTest(name string) {
+	cfg, err := loadAlertConfig(alertConfigFile())
+	if err != nil {
+		die("%v", err)
+	}
+	n, ok := cfg.Notifiers[name]
+	if !ok {
+		die("Unknown notifier %s", name)
+	}
+	if n.Type == "inbox" {
+		die("Inbox notices are delivered by the running API to connected backends; add %s to a rule to test it", name)
+	}
+	now := time.Now()
+	notice := alertNotice{
+		Rule:   alertRule{Name: "test", Kind: "test", Severity: "warning"},
+		Alert:  alertRecord{Rule: "test", Kind: "test", Target: name, Severity: "warning", Summary: "Test notice from grengo alert test", StartedAt: now},
+		Status: alertStatusFiring,
+	}
+	if err := n.send(notice); err != nil {
+		die("Notifier %s failed: %v", name, err)
+	}
+	log("Test notice sent through %s", name)
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// alertEvent is what notifiers send, as JSON for webhooks and the inbox.
+type alertEvent struct {
+	Client     string     `json:"client,omitempty"`
+	Users      []int64    `json:"users,omitempty"`
+	Status     string     `json:"status"`
+	Rule       string     `json:"rule"`
+	Kind       string     `json:"kind"`
+	Severity   string     `json:"severity"`
+	Target     string     `json:"target"`
+	Summary    string     `json:"summary"`
+	Node       string     `json:"node"`
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	Message    string     `json:"message"`
+}
+
+func alertNodeName() string {
+	if node := fleetNodeName(); node != fleetLocalNode {
+		return node
+	}
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return fleetLocalNode
+}
+
+func (n alertNotice) subject() string {
+	return fmt.Sprintf("[grengo %s] %s %s: %s", alertNodeName(), strings.ToUpper(n.Status), n.Alert.Severity, n.Rule.Name)
+}
+
+func (n alertNotice) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", n.Alert.Summary)
+	fmt.Fprintf(&b, "Rule:     %s (%s)\n", n.Rule.Name, n.Alert.Kind)
+	fmt.Fprintf(&b, "Target:   %s\n", n.Alert.Target)
+	fmt.Fprintf(&b, "Node:     %s\n", alertNodeName())
+	fmt.Fprintf(&b, "Since:    %s\n", n.Alert.StartedAt.Local().Format(time.RFC1123))
+	if n.Alert.ResolvedAt != nil {
+		fmt.Fprintf(&b, "Resolved: %s\n", n.Alert.ResolvedAt.Local().Format(time.RFC1123))
+	}
+	return b.String()
+}
+
+func (n alertNotice) event() alertEvent {
+	return alertEvent{
+		Status:     n.Status,
+		Rule:       n.Rule.Name,
+		Kind:       n.Alert.Kind,
+		Severity:   n.Alert.Severity,
+		Target:     n.Alert.Target,
+		Summary:    n.Alert.Summary,
+		Node:       alertNodeName(),
+		StartedAt:  n.Alert.StartedAt,
+		ResolvedAt: n.Alert.ResolvedAt,
+		Message:    n.subject() + "\n" + n.Alert.Summary,
+	}
+}
+
+// deliverAlert sends a notice through each of its rule's notifiers. Failures
+// are logged; the alert stays recorded either way.
+func deliverAlert(cfg alertConfig, n alertNotice) {
+	for _, name := range n.Rule.Notify {
+		if err := cfg.Notifiers[name].send(n); err != nil {
+			BroadcastLog("ERROR", logPrefix("alert", n.Rule.Name), fmt.Sprintf("notifier %s: %v", name, err))
+		}
+	}
+}
+
+func (nt alertNotifier) send(n alertNotice) error {
+	switch nt.Type {
+	case "smtp":
+		return sendAlertMail(nt.To, n.subject(), n.text())
+	case "webhook":
+		body, _ := json.Marshal(n.event())
+		return postAlertWebhook(nt.URL, nt.Secret, body)
+	case "inbox":
+		ev := n.event()
+		ev.Client, ev.Users = nt.Client, nt.Users
+		return publishInboxAlert(ev)
+	}
+	return fmt.Errorf("unknown notifier type %q", nt.Type)
+}
+
+// sendAlertMail sends a plain-text mail through GRENGO_SMTP_HOST, with
+// implicit TLS on port 465 and STARTTLS where the server offers it.
+func sendAlertMail(to []string, subject, body string) error {
+	env := rootEnvFile()
+	host := envVal(env, "GRENGO_SMTP_HOST")
+	if host == "" {
+		return fmt.Errorf("GRENGO_SMTP_HOST is not set")
+	}
+	port, _ := strconv.Atoi(envVal(env, "GRENGO_SMTP_PORT"))
+	if port == 0 {
+		port = 587
+	}
+	from := envVal(env, "GRENGO_SMTP_FROM")
+	if from == "" {
+		from = "grengo@" + alertNodeName()
+	}
+	var auth smtp.Auth
+	if user := envVal(env, "GRENGO_SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, envVal(env, "GRENGO_SMTP_PASSWORD"), host)
+	}
+	msg := []byte(strings.Join([]string{
+		"From: " + from,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		`Content-Type: text/plain; charset="UTF-8"`,
+	}, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	if port != 465 {
+		return smtp.SendMail(addr, auth, from, to, msg)
+	}
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: host})
+	if err != nil {
+		return fmt.Errorf("smtp tls dial: %w", err)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp client: %w", err)
+	}
+	defer c.Close()
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+var alertWebhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// postAlertWebhook posts body to url. With a secret the body is signed the
+// way grengo's generic webhook receiver checks it.
+func postAlertWebhook(url, secret string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "grengo-alerts")
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Grengo-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := alertWebhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Backends watching alerts through WatchAlerts, by client name.
+var (
+	alertListenersMu sync.Mutex
+	alertListeners   = map[chan alertEvent]string{}
+)
+
+func watchAlerts(client string) (chan alertEvent, func()) {
+	ch := make(chan alertEvent, 16)
+	alertListenersMu.Lock()
+	alertListeners[ch] = client
+	alertListenersMu.Unlock()
+	return ch, func() {
+		alertListenersMu.Lock()
+		delete(alertListeners, ch)
+		alertListenersMu.Unlock()
+	}
+}
+
+// publishInboxAlert hands an event to the client's backends, which post it
+// to the users' inboxes as a system message.
+func publishInboxAlert(ev alertEvent) error {
+	alertListenersMu.Lock()
+	defer alertListenersMu.Unlock()
+	delivered := false
+	for ch, client := range alertListeners {
+		if client != ev.Client {
+			continue
+		}
+		select {
+		case ch <- ev:
+			delivered = true
+		default:
+		}
+	}
+	if !delivered {
+		return fmt.Errorf("no backend of %s is watching alerts", ev.Client)
+	}
+	return nil
+}
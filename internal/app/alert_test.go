@@ -0,0 +1,215 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAlertConfig(t *testing.T) {
+	cfg := alertConfig{
+		Notifiers: map[string]alertNotifier{"ops": {Type: "smtp", To: []string{"ops@example.com"}}},
+		Rules: []alertRule{
+			{Name: "down", Kind: alertKindSiteUnreachable, Notify: []string{"ops"}},
+			{Name: "disk", Kind: alertKindDiskUsage, Above: 90},
+		},
+	}
+	if err := validateAlertConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Interval != defaultAlertInterval || cfg.repeat != defaultAlertRepeat {
+		t.Errorf("interval %v, repeat %v", cfg.Interval, cfg.repeat)
+	}
+	if cfg.Rules[0].Checks != defaultAlertChecks || cfg.Rules[0].Severity != "warning" {
+		t.Errorf("site rule defaults = %+v", cfg.Rules[0])
+	}
+	if len(cfg.Rules[1].Paths) != 1 {
+		t.Errorf("disk rule paths = %v", cfg.Rules[1].Paths)
+	}
+
+	for name, bad := range map[string]alertConfig{
+		"unknown notifier": {Rules: []alertRule{{Name: "r", Kind: alertKindJobFailed, Notify: []string{"nope"}}}},
+		"unknown kind":     {Rules: []alertRule{{Name: "r", Kind: "cpu"}}},
+		"threshold":        {Rules: []alertRule{{Name: "r", Kind: alertKindContainerMemory, Above: 120}}},
+		"duplicate rule":   {Rules: []alertRule{{Name: "r", Kind: alertKindJobFailed}, {Name: "r", Kind: alertKindJobFailed}}},
+		"webhook url":      {Notifiers: map[string]alertNotifier{"w": {Type: "webhook", URL: "example.com"}}},
+		"repeat":           {Repeat: "sometimes"},
+		"silence window":   {Silences: []alertSilence{{Daily: "2am-3am"}}},
+	} {
+		if err := validateAlertConfig(&bad); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestAlertSilenceActive(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2026, 3, 1, hour, min, 0, 0, time.Local)
+	}
+	nightly := alertSilence{Daily: "23:30-01:00"}
+	for _, c := range []struct {
+		now  time.Time
+		want bool
+	}{
+		{at(23, 29), false},
+		{at(23, 30), true},
+		{at(0, 59), true},
+		{at(1, 0), false},
+	} {
+		if got := nightly.active(c.now); got != c.want {
+			t.Errorf("active at %s = %v", c.now.Format("15:04"), got)
+		}
+	}
+
+	until := alertSilence{Rule: "disk", Target: "/srv/*", Until: at(12, 0)}
+	if !until.active(at(11, 0)) || until.active(at(12, 0)) {
+		t.Error("until window not honoured")
+	}
+	if !until.matches("disk", "/srv/data") || until.matches("disk", "/") || until.matches("down", "/srv/data") {
+		t.Error("silence matched the wrong alerts")
+	}
+}
+
+func TestAlertEvaluateSite(t *testing.T) {
+	cfg := alertConfig{Rules: []alertRule{{Name: "down", Kind: alertKindSiteUnreachable, Checks: 2, Severity: "critical"}}}
+	cfg.repeat = time.Hour
+	e := newAlertEngine()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	down := alertSnapshot{Probes: map[string]bool{"shop": false, "blog": true}}
+
+	notices, _ := e.evaluate(cfg, down, nil, now)
+	if len(notices) != 0 {
+		t.Fatalf("fired after one failed check: %+v", notices)
+	}
+	notices, changed := e.evaluate(cfg, down, nil, now.Add(30*time.Second))
+	if len(notices) != 1 || notices[0].Status != alertStatusFiring || notices[0].Alert.Target != "shop" {
+		t.Fatalf("notices = %+v", notices)
+	}
+	if len(changed) != 1 || changed[0].ID != 0 {
+		t.Fatalf("changed = %+v", changed)
+	}
+	if notices, _ := e.evaluate(cfg, down, nil, now.Add(time.Minute)); len(notices) != 0 {
+		t.Fatalf("notified again before repeat: %+v", notices)
+	}
+	if notices, _ := e.evaluate(cfg, down, nil, now.Add(time.Hour+time.Minute)); len(notices) != 1 {
+		t.Fatalf("no repeat notice after an hour: %+v", notices)
+	}
+
+	notices, changed = e.evaluate(cfg, alertSnapshot{Probes: map[string]bool{"shop": true}}, nil, now.Add(2*time.Hour))
+	if len(notices) != 1 || notices[0].Status != alertStatusResolved {
+		t.Fatalf("resolve notices = %+v", notices)
+	}
+	if len(changed) != 1 || changed[0].State != alertStatusResolved || changed[0].ResolvedAt == nil {
+		t.Fatalf("resolve changed = %+v", changed)
+	}
+	if len(e.firing()) != 0 {
+		t.Fatal("alert still firing after recovery")
+	}
+}
+
+func TestAlertEvaluateSilenced(t *testing.T) {
+	cfg := alertConfig{Rules: []alertRule{{Name: "down", Kind: alertKindSiteUnreachable, Checks: 1}}}
+	e := newAlertEngine()
+	now := time.Now()
+	silences := []alertSilence{{Rule: "down", Target: "shop", Until: now.Add(time.Hour)}}
+
+	notices, changed := e.evaluate(cfg, alertSnapshot{Probes: map[string]bool{"shop": false}}, silences, now)
+	if len(notices) != 0 || len(changed) != 1 {
+		t.Fatalf("silenced alert: notices %+v, changed %+v", notices, changed)
+	}
+	if notices, _ := e.evaluate(cfg, alertSnapshot{Probes: map[string]bool{"shop": true}}, nil, now.Add(time.Minute)); len(notices) != 0 {
+		t.Fatalf("resolved notice for an alert that never notified: %+v", notices)
+	}
+}
+
+func TestAlertEvaluateThresholdsAndJobs(t *testing.T) {
+	cfg := alertConfig{Rules: []alertRule{
+		{Name: "mem", Kind: alertKindContainerMemory, Above: 90, Match: "*-backend"},
+		{Name: "disk", Kind: alertKindDiskUsage, Above: 80, Paths: []string{"/"}},
+		{Name: "jobs", Kind: alertKindJobFailed, Match: "backup*"},
+	}}
+	e := newAlertEngine()
+	snap := alertSnapshot{
+		Containers: []containerStats{
+			{Name: "shop-backend", MemPct: 95, MemMax: 1 << 30},
+			{Name: "shop-frontend", MemPct: 99, MemMax: 1 << 30},
+			{Name: "blog-backend", MemPct: 50, MemMax: 1 << 30},
+		},
+		Disks: []diskUsage{{Path: "/", Used: 90, Avail: 10}, {Path: "/tmp", Used: 99, Avail: 1}},
+		Jobs: []jobStatus{
+			{ID: "j1", Type: "backup", Target: "shop", Status: "failed", Error: "disk full"},
+			{ID: "j2", Type: "deploy", Status: "failed"},
+			{ID: "j3", Type: "backup", Status: "completed"},
+		},
+	}
+	notices, _ := e.evaluate(cfg, snap, nil, time.Now())
+	got := map[string]string{}
+	for _, n := range notices {
+		got[n.Rule.Name+" "+n.Alert.Target] = n.Alert.Summary
+	}
+	if len(got) != 3 || got["mem shop-backend"] == "" || got["disk /"] == "" {
+		t.Fatalf("notices = %v", got)
+	}
+	if s := got["jobs j1"]; !strings.Contains(s, "for shop failed: disk full") {
+		t.Fatalf("job summary = %q", s)
+	}
+	if len(e.firing()) != 2 {
+		t.Fatalf("job failure left firing: %+v", e.firing())
+	}
+
+	notices, _ = e.evaluate(cfg, alertSnapshot{Jobs: snap.Jobs}, nil, time.Now())
+	for _, n := range notices {
+		if n.Rule.Name == "jobs" {
+			t.Fatalf("job failure notified twice: %+v", n)
+		}
+	}
+}
+
+func TestPostAlertWebhook(t *testing.T) {
+	var signature, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		signature, body = r.Header.Get("X-Grengo-Signature"), string(data)
+	}))
+	defer srv.Close()
+
+	if err := postAlertWebhook(srv.URL, "s3cret", []byte(`{"rule":"down"}`)); err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(body))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Fatalf("signature %q, want %q", signature, want)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	if err := postAlertWebhook(failing.URL, "", nil); err == nil {
+		t.Fatal("error status not reported")
+	}
+}
+
+func TestPublishInboxAlert(t *testing.T) {
+	if err := publishInboxAlert(alertEvent{Client: "shop"}); err == nil {
+		t.Fatal("published without a watching backend")
+	}
+	ch, stop := watchAlerts("shop")
+	defer stop()
+	if err := publishInboxAlert(alertEvent{Client: "blog"}); err == nil {
+		t.Fatal("published to another client's backend")
+	}
+	if err := publishInboxAlert(alertEvent{Client: "shop", Users: []int64{1}, Message: "down"}); err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-ch; ev.Message != "down" || len(ev.Users) != 1 {
+		t.Fatalf("event = %+v", ev)
+	}
+}
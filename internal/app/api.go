@@ -64,10 +64,19 @@ func cmdAPIStart(port int) {
 	} else {
 		migrateFallbackPasscodeIfPossible()
 	}
+	go runBackupSchedulerLoop()
+	go runTLSRenewLoop()
+	go runQuotaCheckerLoop()
+	go runAlertLoop()
+	go runLogCollectorLoop()
+	go runAuditWriter()
+	webhookServer := serveWebhooks()
+	fleetServer := serveFleet()
+	metricsServer := serveMetrics()
 
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(passcodeInterceptor),
-		grpc.StreamInterceptor(passcodeStreamInterceptor),
+		grpc.ChainUnaryInterceptor(auditInterceptor, authInterceptor, apiKeyInterceptor),
+		grpc.ChainStreamInterceptor(auditStreamInterceptor, authStreamInterceptor, apiKeyStreamInterceptor),
 	)
 	pb.RegisterGrengoServiceServer(grpcServer, &GrengoServer{})
 	reflection.Register(grpcServer)
@@ -77,11 +86,18 @@ func cmdAPIStart(port int) {
 	go func() {
 		<-done
 		log("Shutting down grengo gRPC API…")
+		webhookServer.Close()
+		metricsServer.Close()
+		if fleetServer != nil {
+			fleetServer.GracefulStop()
+		}
 		grpcServer.GracefulStop()
 	}()
 
 	log("Grengo internal gRPC API listening on %s (PID %d)", addr, os.Getpid())
 	info("Accessible from this host and local Docker containers")
+	info("Webhooks served on 127.0.0.1:%d/webhook/<provider>[/<client>]", webhookPort())
+	info("Metrics served on 0.0.0.0:%d/metrics", metricsPort())
 	info("Stop with: grengo api stop  (or Ctrl-C)")
 
 	if err := grpcServer.Serve(listener); err != nil {
@@ -188,37 +204,43 @@ type APIHandlers struct {
 	WebSocket       http.HandlerFunc
 	VerifyPasscode  http.HandlerFunc
 	PasscodeStatus  http.HandlerFunc
-	WebhookGithub   http.HandlerFunc
+	Login           http.HandlerFunc
+	Webhook         http.HandlerFunc
+	Metrics         http.HandlerFunc
 }
 
+// Handlers returns the API's HTTP handlers. Each is guarded by the role it
+// needs, viewer, deployer or admin, as the gRPC methods are.
 func Handlers() APIHandlers {
 	return APIHandlers{
-		ListSites:       apiListSites,
-		Stats:           apiStats,
-		Storage:         apiStorage,
-		SysInfo:         apiSysInfo,
-		GetEnv:          apiGetEnv,
-		PutEnv:          apiPutEnv,
-		Exec:            apiExec,
-		FrappeProvision: apiFrappeProvision,
-		ExportSite:      apiExportSite,
-		ImportSite:      apiImportSite,
-		ArmSite:         apiArmSite,
-		DisarmSite:      apiDisarmSite,
-		MigrateSite:     apiMigrateSite,
-		MigrateAll:      apiMigrateAll,
-		ExportNode:      apiExportNode,
-		ImportNode:      apiImportNode,
-		ListJobs:        apiListJobs,
-		GetJob:          apiGetJob,
-		DownloadJob:     apiDownloadJob,
-		ListExports:     apiListExports,
-		DownloadExport:  apiDownloadExport,
-		DeleteExport:    apiDeleteExport,
-		WebSocket:       apiWebSocket,
-		VerifyPasscode:  apiVerifyPasscode,
+		ListSites:       requireRole(roleViewer, apiListSites),
+		Stats:           requireRole(roleViewer, apiStats),
+		Storage:         requireRole(roleViewer, apiStorage),
+		SysInfo:         requireRole(roleViewer, apiSysInfo),
+		GetEnv:          requireRole(roleAdmin, apiGetEnv),
+		PutEnv:          auditedHTTP("update-env", requireRole(roleAdmin, apiPutEnv)),
+		Exec:            auditedHTTP("exec", requireRole(roleAdmin, apiExec)),
+		FrappeProvision: auditedHTTP("provision-frappe", requireRole(roleDeployer, apiFrappeProvision)),
+		ExportSite:      auditedHTTP("export-site", requireRole(roleDeployer, apiExportSite)),
+		ImportSite:      auditedHTTP("import-site", requireRole(roleDeployer, apiImportSite)),
+		ArmSite:         auditedHTTP("arm-site", requireRole(roleAdmin, apiArmSite)),
+		DisarmSite:      auditedHTTP("disarm-site", requireRole(roleAdmin, apiDisarmSite)),
+		MigrateSite:     auditedHTTP("migrate-site", requireRole(roleDeployer, apiMigrateSite)),
+		MigrateAll:      auditedHTTP("migrate-all", requireRole(roleDeployer, apiMigrateAll)),
+		ExportNode:      auditedHTTP("export-node", requireRole(roleAdmin, apiExportNode)),
+		ImportNode:      auditedHTTP("import-node", requireRole(roleAdmin, apiImportNode)),
+		ListJobs:        requireRole(roleViewer, apiListJobs),
+		GetJob:          requireRole(roleViewer, apiGetJob),
+		DownloadJob:     requireRole(roleAdmin, apiDownloadJob),
+		ListExports:     requireRole(roleViewer, apiListExports),
+		DownloadExport:  requireRole(roleAdmin, apiDownloadExport),
+		DeleteExport:    auditedHTTP("delete-export", requireRole(roleAdmin, apiDeleteExport)),
+		WebSocket:       requireRole(roleViewer, apiWebSocket),
+		VerifyPasscode:  auditedHTTP("passcode-verify", apiVerifyPasscode),
 		PasscodeStatus:  apiPasscodeStatus,
-		WebhookGithub:   apiWebhookGithub,
+		Login:           auditedHTTP("login", apiLogin),
+		Webhook:         apiWebhook,
+		Metrics:         apiMetrics,
 	}
 }
 
@@ -39,7 +39,7 @@ func startSiteExport(name string) string {
 		prefix := logPrefix("export", name)
 		BroadcastLog("INFO", prefix, fmt.Sprintf("exporting %s", name))
 		self, _ := os.Executable()
-		cmd := exec.Command(self, "export", name, "-o", outPath)
+		cmd := exec.Command(self, withDefaultTarget([]string{"export", name, "-o", outPath})...)
 		cmd.Dir = ProjectRoot()
 		cmd.Env = append(os.Environ(), "GRENGO_NONINTERACTIVE=1")
 
@@ -108,7 +108,7 @@ func startNodeExport() string {
 		prefix := "export-node"
 		BroadcastLog("INFO", prefix, "exporting node")
 		self, _ := os.Executable()
-		cmd := exec.Command(self, "export-node", "-o", outPath)
+		cmd := exec.Command(self, withDefaultTarget([]string{"export-node", "-o", outPath})...)
 		cmd.Dir = ProjectRoot()
 		cmd.Env = append(os.Environ(), "GRENGO_NONINTERACTIVE=1")
 
@@ -30,7 +30,7 @@ func apiExec(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Block recursive / dangerous commands.
-	if commandBlocked(req.Command) {
+	if commandBlocked(req.Command, req.Args) {
 		apiError(w, http.StatusBadRequest, fmt.Sprintf("command %q not allowed via API", req.Command))
 		return
 	}
@@ -102,15 +102,35 @@ func (fw *flushWriter) Write(p []byte) (n int, err error) {
 	return
 }
 
-func commandBlocked(command string) bool {
+// commandBlocked reports whether API callers may not run command with args.
+// Commands that manage credentials, signing trust or the API itself stay on
+// the CLI, and no caller may switch off archive signature checks.
+func commandBlocked(command string, args []string) bool {
 	blocked := map[string]bool{
 		"api":      true,
 		"wipe":     true,
 		"remove":   true,
 		"rm":       true,
 		"passcode": true,
+		"apikey":   true,
+		"operator": true,
+		"fleet":    true,
+		"tls":      true,
+		"audit":    true,
+		"keys":     true,
 	}
-	return blocked[command]
+	if blocked[command] {
+		return true
+	}
+	if command == "webhook" && len(args) > 0 && args[0] == "secret" {
+		return true
+	}
+	for _, arg := range args {
+		if arg == "--allow-unsigned" || strings.HasPrefix(arg, "--allow-unsigned=") {
+			return true
+		}
+	}
+	return false
 }
 
 func logPrefix(parts ...string) string {
@@ -221,7 +241,7 @@ func startGenericCommand(command string, args []string) string {
 
 	go func() {
 		writer := NewLogWriter(logPrefix("exec", command), "INFO")
-		if commandBlocked(command) {
+		if commandBlocked(command, args) {
 			jobsMu.Lock()
 			j.Status = "failed"
 			j.Error = fmt.Sprintf("command %q not allowed via API", command)
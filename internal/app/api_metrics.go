@@ -28,6 +28,15 @@ type containerStats struct {
 	NetIO    string  `json:"net_io"`
 	BlockIO  string  `json:"block_io"`
 	PIDs     int     `json:"pids"`
+
+	// Raw counters behind the formatted fields, exported on /metrics.
+	CPUSeconds float64 `json:"-"`
+	MemBytes   uint64  `json:"-"`
+	MemMax     uint64  `json:"-"`
+	NetRx      uint64  `json:"-"`
+	NetTx      uint64  `json:"-"`
+	BlockRead  uint64  `json:"-"`
+	BlockWrite uint64  `json:"-"`
 }
 
 // dockerAPIClient talks to the Docker Engine API via the Unix socket.
@@ -163,6 +172,14 @@ func fetchContainerStats(name string) (*containerStats, error) {
 		NetIO:    fmt.Sprintf("%s / %s", humanBytes(rxBytes), humanBytes(txBytes)),
 		BlockIO:  fmt.Sprintf("%s / %s", humanBytes(blkRead), humanBytes(blkWrite)),
 		PIDs:     raw.PidsStats.Current,
+
+		CPUSeconds: float64(raw.CPUStats.CPUUsage.TotalUsage) / 1e9,
+		MemBytes:   memUsage,
+		MemMax:     memLimit,
+		NetRx:      rxBytes,
+		NetTx:      txBytes,
+		BlockRead:  blkRead,
+		BlockWrite: blkWrite,
 	}, nil
 }
 
@@ -257,6 +274,7 @@ type siteStorageInfo struct {
 	Name      string `json:"name"`
 	Used      int64  `json:"used"`
 	UsedHuman string `json:"used_human"`
+	Limit     int64  `json:"limit,omitempty"`
 }
 
 func gatherStorage() *storageInfo {
@@ -286,7 +304,7 @@ func gatherStorage() *storageInfo {
 		grpcPort := strconv.Itoa(portInt + 100)
 		grpcAddr := fmt.Sprintf("127.0.0.1:%s", grpcPort)
 
-		var used int64
+		var used, limit int64
 
 		conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		if err == nil {
@@ -300,7 +318,7 @@ func gatherStorage() *storageInfo {
 				}
 				if err := json.Unmarshal([]byte(resp.StorageJson), &payload); err == nil {
 					totalLimit += payload.Limit
-					used = payload.Used
+					used, limit = payload.Used, payload.Limit
 				}
 			}
 			cancel()
@@ -313,6 +331,7 @@ func gatherStorage() *storageInfo {
 			Name:      name,
 			Used:      used,
 			UsedHuman: humanBytes(uint64(used)),
+			Limit:     limit,
 		})
 	}
 
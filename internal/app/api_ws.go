@@ -12,13 +12,16 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/skaia/grengo/internal/hardware"
 )
 
 // WebSocket & Background Jobs
 type jobStatus struct {
 	ID        string    `json:"id"`
-	Type      string    `json:"type"`             // "export-node", "export-site"
+	Type      string    `json:"type"`             // "export-node", "export-site", "backup-site"
 	Target    string    `json:"target,omitempty"` // site name or node
 	Status    string    `json:"status"`           // "running", "completed", "failed"
 	Message   string    `json:"message,omitempty"`
@@ -49,6 +52,16 @@ type grengoActionRequest struct {
 }
 
 func dispatchGrengoAction(req grengoActionRequest) (string, error) {
+	switch req.Action {
+	case "site-cmd", "global-cmd", "exec":
+		args := req.Args
+		if req.Action == "site-cmd" {
+			args = append([]string{req.Name}, req.Args...)
+		}
+		if commandBlocked(req.Command, args) {
+			return "", fmt.Errorf("command %q not allowed via API", req.Command)
+		}
+	}
 	switch req.Action {
 	case "export-node":
 		return startNodeExport(), nil
@@ -57,6 +70,19 @@ func dispatchGrengoAction(req grengoActionRequest) (string, error) {
 			return "", fmt.Errorf("name required")
 		}
 		return startSiteExport(req.Name), nil
+	case "backup-site":
+		if req.Name == "" {
+			return "", fmt.Errorf("name required")
+		}
+		if !clientExists(req.Name) {
+			return "", fmt.Errorf("site '%s' not found", req.Name)
+		}
+		sched, ok := findBackupSchedule(req.Name)
+		if !ok {
+			policy := defaultBackupRetention()
+			sched = backupSchedule{SiteName: req.Name, KeepDaily: policy.Daily, KeepWeekly: policy.Weekly, KeepMonthly: policy.Monthly}
+		}
+		return startSiteBackup(sched), nil
 	case "site-cmd":
 		if req.Name == "" || req.Command == "" {
 			return "", fmt.Errorf("name and command required")
@@ -100,7 +126,14 @@ func apiWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 		var req grengoActionRequest
 		if err := json.Unmarshal(msg, &req); err == nil {
-			_, _ = dispatchGrengoAction(req)
+			start := time.Now()
+			var err error
+			if op := operatorFromContext(r.Context()); op != nil && !roleAllows(op.Role, actionRole(req.Action)) {
+				err = status.Errorf(codes.PermissionDenied, "%s role required", actionRole(req.Action))
+			} else {
+				_, err = dispatchGrengoAction(req)
+			}
+			auditWSAction(r, msg, req, start, err)
 		}
 	}
 }
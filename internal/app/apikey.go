@@ -0,0 +1,557 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/skaia/grpc/grengo"
+)
+
+const (
+	// apiKeyPrefixLen is the length of the lookup prefix stored in
+	// grengo_api_keys, as the Skaia backend middleware reads it.
+	apiKeyPrefixLen = 12
+	apiKeyTag       = "grk_"
+	// apiKeyModule is the scope that grants access to GrengoServer itself.
+	apiKeyModule        = "grengo"
+	defaultAPIKeyRate   = 60
+	defaultAPIKeyExpiry = "90d"
+	apiKeyCacheTTL      = 30 * time.Second
+	apiKeyTouchInterval = time.Minute
+)
+
+var apiKeyModuleName = regexp.MustCompile(`^[a-z][a-z0-9_.-]*$`)
+
+// apiKeyListMethods may be called by a site-scoped key without naming a
+// site; their results are filtered to the key's sites.
+var apiKeyListMethods = map[string]bool{
+	pb.GrengoService_ListSites_FullMethodName: true,
+}
+
+func apiKeyPrefix(key string) string {
+	key = strings.TrimSpace(key)
+	if len(key) > apiKeyPrefixLen {
+		return key[:apiKeyPrefixLen]
+	}
+	return key
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAPIKeySecret returns a fresh key such as grk_3f9a0c1b.... The first
+// 12 characters are its lookup prefix.
+func newAPIKeySecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyTag + hex.EncodeToString(b), nil
+}
+
+// parseAPIKeyScope reads module[:r|w|rw]; a bare module grants read.
+func parseAPIKeyScope(s string) (apiKeyScope, error) {
+	module, access, _ := strings.Cut(strings.TrimSpace(s), ":")
+	if !apiKeyModuleName.MatchString(module) {
+		return apiKeyScope{}, fmt.Errorf("invalid scope module %q", module)
+	}
+	scope := apiKeyScope{Module: module}
+	switch access {
+	case "", "r":
+		scope.Read = true
+	case "w":
+		scope.Write = true
+	case "rw", "wr":
+		scope.Read, scope.Write = true, true
+	default:
+		return apiKeyScope{}, fmt.Errorf("invalid access %q in scope %s (r, w or rw)", access, s)
+	}
+	return scope, nil
+}
+
+// parseAPIKeyExpiry reads how long a key lives: a duration ahead (12h, 90d),
+// a date or time, or "never".
+func parseAPIKeyExpiry(s string, now time.Time) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "never" {
+		return nil, nil
+	}
+	var t time.Time
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			t = now.AddDate(0, 0, n)
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		t = now.Add(d)
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t.IsZero() {
+			if parsed, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				t = parsed
+			}
+		}
+	}
+	if t.IsZero() {
+		return nil, fmt.Errorf("invalid expiry %q (e.g. 90d, 2027-01-31 or never)", s)
+	}
+	if !t.After(now) {
+		return nil, fmt.Errorf("expiry %s is in the past", t.Local().Format("2006-01-02 15:04"))
+	}
+	return &t, nil
+}
+
+// state describes whether a key can still be used.
+func (k *apiKey) state(now time.Time) string {
+	switch {
+	case k.RevokedAt != nil && k.RotatedTo != nil:
+		return "rotated"
+	case k.RevokedAt != nil:
+		return "revoked"
+	case k.ExpiresAt != nil && !k.ExpiresAt.After(now):
+		return "expired"
+	}
+	return "active"
+}
+
+func (k *apiKey) allows(module string, write bool) bool {
+	for _, s := range k.Scopes {
+		if s.Module == module {
+			return s.Write || (!write && s.Read)
+		}
+	}
+	return false
+}
+
+func (k *apiKey) siteAllowed(name string) bool {
+	if len(k.SiteScope) == 0 {
+		return true
+	}
+	for _, s := range k.SiteScope {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAPIKeyMethod decides whether k may call method at all. Audited
+// methods change the node and need grengo write scope.
+func checkAPIKeyMethod(k *apiKey, method string, now time.Time) error {
+	if st := k.state(now); st != "active" {
+		return status.Errorf(codes.Unauthenticated, "api key %s", st)
+	}
+	_, write := auditedMethods[method]
+	if !k.allows(apiKeyModule, write) {
+		access := "r"
+		if write {
+			access = "w"
+		}
+		return status.Errorf(codes.PermissionDenied, "api key lacks %s:%s scope", apiKeyModule, access)
+	}
+	return nil
+}
+
+// checkAPIKeySites keeps a site-scoped key to the clients it names. Calls
+// that name no client are refused unless their results are filtered.
+func checkAPIKeySites(k *apiKey, method string, decoded any) error {
+	if len(k.SiteScope) == 0 {
+		return nil
+	}
+	sites := requestSites(decoded)
+	if len(sites) == 0 && !apiKeyListMethods[method] {
+		return status.Errorf(codes.PermissionDenied, "api key is limited to %s", strings.Join(k.SiteScope, ", "))
+	}
+	for _, site := range sites {
+		if !k.siteAllowed(site) {
+			return status.Errorf(codes.PermissionDenied, "api key may not act on %s", site)
+		}
+	}
+	return nil
+}
+
+// apiKeyCache keeps looked-up keys briefly so each call does not reach the
+// management DB, and throttles last-used updates.
+type apiKeyCache struct {
+	mu      sync.Mutex
+	entries map[string]apiKeyCacheEntry
+}
+
+type apiKeyCacheEntry struct {
+	key     *apiKey
+	loaded  time.Time
+	touched time.Time
+}
+
+var apiKeys = &apiKeyCache{entries: map[string]apiKeyCacheEntry{}}
+
+// resolve returns the key matching raw, or nil when there is none. touch
+// reports whether its last use should be recorded.
+func (c *apiKeyCache) resolve(raw string, now time.Time, lookup func(prefix, hash string) (*apiKey, error)) (*apiKey, bool, error) {
+	hash := hashAPIKey(raw)
+	c.mu.Lock()
+	e, ok := c.entries[hash]
+	c.mu.Unlock()
+	if !ok || now.Sub(e.loaded) >= apiKeyCacheTTL {
+		k, err := lookup(apiKeyPrefix(raw), hash)
+		if err != nil {
+			return nil, false, err
+		}
+		e = apiKeyCacheEntry{key: k, loaded: now, touched: e.touched}
+	}
+	touch := e.key != nil && now.Sub(e.touched) >= apiKeyTouchInterval
+	if touch {
+		e.touched = now
+	}
+	c.mu.Lock()
+	c.entries[hash] = e
+	c.mu.Unlock()
+	return e.key, touch, nil
+}
+
+// cached returns the key matching raw if a call resolved it recently,
+// without reaching the management DB.
+func (c *apiKeyCache) cached(raw string) *apiKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[hashAPIKey(raw)].key
+}
+
+type apiKeyContextKey struct{}
+
+// apiKeyFromContext returns the API key a call was made with, if any.
+func apiKeyFromContext(ctx context.Context) *apiKey {
+	k, _ := ctx.Value(apiKeyContextKey{}).(*apiKey)
+	return k
+}
+
+// callerAPIKey resolves the x-grengo-api-key sent with a call. Calls
+// without one are left to the passcode or an operator session.
+func callerAPIKey(ctx context.Context) (*apiKey, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	vals := md.Get("x-grengo-api-key")
+	if len(vals) == 0 || strings.TrimSpace(vals[0]) == "" {
+		return nil, nil
+	}
+	svc := newGrengoService()
+	k, touch, err := apiKeys.resolve(strings.TrimSpace(vals[0]), time.Now(), svc.LookupAPIKey)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "cannot check api key: %v", err)
+	}
+	if k == nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid api key")
+	}
+	if touch {
+		go svc.TouchAPIKey(k.ID)
+	}
+	return k, nil
+}
+
+// role is the operator role a key stands in for when it is a call's only
+// credential: grengo:w reaches deployer methods, anything else viewer ones.
+// Admin methods always need an operator session or the passcode.
+func (k *apiKey) role() string {
+	if k.allows(apiKeyModule, true) {
+		return roleDeployer
+	}
+	return roleViewer
+}
+
+// authorizeAPIKeyCall admits a call whose only credential is an API key, so
+// an integration holds the key alone and cannot shed its scopes by leaving
+// it out. apiKeyInterceptor then applies the scopes as for any keyed call.
+func authorizeAPIKeyCall(ctx context.Context, method string, decoded any) (context.Context, error) {
+	k, err := callerAPIKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if role := methodRole(method, decoded); !roleAllows(k.role(), role) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s role required", role)
+	}
+	return ctx, nil
+}
+
+// apiKeyInterceptor applies a key's expiry, module scope and site scope.
+// It runs after the passcode check.
+func apiKeyInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if passcodeExemptMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	k, err := callerAPIKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return handler(ctx, req)
+	}
+	if err := checkAPIKeyMethod(k, info.FullMethod, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := checkAPIKeySites(k, info.FullMethod, decodeRequest(req)); err != nil {
+		return nil, err
+	}
+	return handler(context.WithValue(ctx, apiKeyContextKey{}, k), req)
+}
+
+// apiKeyServerStream checks the site scope against the first message of a
+// stream, which is when the request becomes known.
+type apiKeyServerStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	key     *apiKey
+	method  string
+	checked bool
+}
+
+func (s *apiKeyServerStream) Context() context.Context { return s.ctx }
+
+func (s *apiKeyServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.checked {
+		s.checked = true
+		return checkAPIKeySites(s.key, s.method, decodeRequest(m))
+	}
+	return nil
+}
+
+func apiKeyStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if passcodeExemptMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	k, err := callerAPIKey(ss.Context())
+	if err != nil {
+		return err
+	}
+	if k == nil {
+		return handler(srv, ss)
+	}
+	if err := checkAPIKeyMethod(k, info.FullMethod, time.Now()); err != nil {
+		return err
+	}
+	return handler(srv, &apiKeyServerStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), apiKeyContextKey{}, k),
+		key:          k,
+		method:       info.FullMethod,
+	})
+}
+
+// findAPIKey resolves a key by id or prefix for the CLI.
+func findAPIKey(keys []apiKey, ref string) *apiKey {
+	for i := range keys {
+		if strconv.FormatInt(keys[i].ID, 10) == ref || keys[i].Prefix == ref {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+func formatAPIKeyScopes(scopes []apiKeyScope) string {
+	parts := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		access := ""
+		if s.Read {
+			access += "r"
+		}
+		if s.Write {
+			access += "w"
+		}
+		parts = append(parts, s.Module+":"+access)
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatKeyTime(t *time.Time, none string) string {
+	if t == nil {
+		return none
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func printNewAPIKey(raw string) {
+	fmt.Println()
+	fmt.Printf("  %s%s%s\n", colorBold, raw, colorReset)
+	fmt.Println()
+	warn("This is the only time the key is shown; store it now")
+	info("Send it as the X-Grengo-API-Key header (or x-grengo-api-key gRPC metadata)")
+}
+
+// cmdAPIKeyCreate issues a key for userID limited to scopes and, when
+// given, to sites.
+func cmdAPIKeyCreate(name string, userID int64, expires string, scopes, sites []string, rate int) {
+	start := time.Now()
+	if strings.TrimSpace(name) == "" {
+		die("Key name is required")
+	}
+	if len(scopes) == 0 {
+		die("Give at least one --scope <module>[:r|w|rw], e.g. --scope %s:r", apiKeyModule)
+	}
+	k := apiKey{Name: name, UserID: userID, SiteScope: sites, RatePerMinute: rate}
+	if k.RatePerMinute <= 0 {
+		k.RatePerMinute = defaultAPIKeyRate
+	}
+	seen := map[string]bool{}
+	for _, s := range scopes {
+		scope, err := parseAPIKeyScope(s)
+		if err != nil {
+			die("%v", err)
+		}
+		if seen[scope.Module] {
+			die("Scope %s given twice", scope.Module)
+		}
+		seen[scope.Module] = true
+		k.Scopes = append(k.Scopes, scope)
+	}
+	for _, site := range sites {
+		if msg := nameError(site); msg != "" {
+			die("%s: %s", site, msg)
+		}
+		if !clientExists(site) {
+			warn("Client '%s' does not exist on this node", site)
+		}
+	}
+	if expires == "" {
+		expires = defaultAPIKeyExpiry
+	}
+	var err error
+	if k.ExpiresAt, err = parseAPIKeyExpiry(expires, start); err != nil {
+		die("%v", err)
+	}
+
+	raw, err := newAPIKeySecret()
+	if err != nil {
+		die("Cannot generate key: %v", err)
+	}
+	k.Prefix = apiKeyPrefix(raw)
+	id, err := newGrengoService().CreateAPIKey(k, hashAPIKey(raw))
+	auditCLI("apikey-create", map[string]any{"name": name, "key_prefix": k.Prefix, "user_id": userID, "scopes": scopes, "sites": sites}, start, err)
+	if err != nil {
+		die("Cannot create key: %v", err)
+	}
+	log("API key %d '%s' created (%s, expires %s)", id, name, formatAPIKeyScopes(k.Scopes), formatKeyTime(k.ExpiresAt, "never"))
+	printNewAPIKey(raw)
+}
+
+// cmdAPIKeyList shows keys with their scopes and when they were last used.
+func cmdAPIKeyList(all bool) {
+	keys, err := newGrengoService().ListAPIKeys(all)
+	if err != nil {
+		die("Cannot list keys: %v", err)
+	}
+	if len(keys) == 0 {
+		info("No API keys; create one with: grengo apikey create <name> --scope %s:r", apiKeyModule)
+		return
+	}
+	now := time.Now()
+	fmt.Printf("  %-5s %-13s %-18s %-6s %-22s %-16s %-17s %-17s %s\n", "ID", "PREFIX", "NAME", "USER", "SCOPES", "SITES", "EXPIRES", "LAST USED", "STATE")
+	for _, k := range keys {
+		sites := strings.Join(k.SiteScope, ",")
+		if sites == "" {
+			sites = "all"
+		}
+		st := k.state(now)
+		switch st {
+		case "active":
+			if k.ExpiresAt != nil && k.ExpiresAt.Sub(now) < 7*24*time.Hour {
+				st = colorYellow + "expiring" + colorReset
+			}
+		default:
+			st = colorRed + st + colorReset
+		}
+		fmt.Printf("  %-5d %-13s %-18s %-6d %-22s %-16s %-17s %-17s %s\n", k.ID, k.Prefix, k.Name, k.UserID,
+			formatAPIKeyScopes(k.Scopes), sites, formatKeyTime(k.ExpiresAt, "never"), formatKeyTime(k.LastUsedAt, "never"), st)
+	}
+}
+
+// cmdAPIKeyRotate replaces a key's secret. The new key keeps the old one's
+// lifetime unless expires is given; the old key stops working now or after
+// grace.
+func cmdAPIKeyRotate(ref, expires, grace string) {
+	start := time.Now()
+	svc := newGrengoService()
+	keys, err := svc.ListAPIKeys(false)
+	if err != nil {
+		die("Cannot list keys: %v", err)
+	}
+	old := findAPIKey(keys, ref)
+	if old == nil {
+		die("No active API key '%s' (use the id or prefix from 'grengo apikey list')", ref)
+	}
+	var graceDur time.Duration
+	if grace != "" {
+		if graceDur, err = time.ParseDuration(grace); err != nil || graceDur < 0 {
+			die("Invalid --grace %q (e.g. 1h)", grace)
+		}
+	}
+	var expiresAt *time.Time
+	switch {
+	case expires != "":
+		if expiresAt, err = parseAPIKeyExpiry(expires, start); err != nil {
+			die("%v", err)
+		}
+	case old.ExpiresAt != nil:
+		t := start.Add(old.ExpiresAt.Sub(old.CreatedAt))
+		expiresAt = &t
+	}
+
+	raw, err := newAPIKeySecret()
+	if err != nil {
+		die("Cannot generate key: %v", err)
+	}
+	id, err := svc.RotateAPIKey(old.ID, apiKeyPrefix(raw), hashAPIKey(raw), expiresAt, graceDur)
+	auditCLI("apikey-rotate", map[string]any{"name": old.Name, "key_prefix": old.Prefix, "new_key_prefix": apiKeyPrefix(raw), "grace": grace}, start, err)
+	if err != nil {
+		die("Cannot rotate key: %v", err)
+	}
+	if graceDur > 0 {
+		log("API key '%s' rotated to id %d; the old key (%s) keeps working for %s", old.Name, id, old.Prefix, graceDur)
+	} else {
+		log("API key '%s' rotated to id %d; the old key (%s) is revoked", old.Name, id, old.Prefix)
+	}
+	printNewAPIKey(raw)
+}
+
+// cmdAPIKeyRevoke disables a key by id or prefix.
+func cmdAPIKeyRevoke(ref string) {
+	start := time.Now()
+	svc := newGrengoService()
+	keys, err := svc.ListAPIKeys(true)
+	if err != nil {
+		die("Cannot list keys: %v", err)
+	}
+	k := findAPIKey(keys, ref)
+	if k == nil {
+		die("No API key '%s'", ref)
+	}
+	if k.RevokedAt != nil {
+		info("API key '%s' (%s) is already revoked", k.Name, k.Prefix)
+		return
+	}
+	err = svc.RevokeAPIKey(k.ID)
+	auditCLI("apikey-revoke", map[string]any{"name": k.Name, "key_prefix": k.Prefix}, start, err)
+	if err != nil {
+		die("Cannot revoke key: %v", err)
+	}
+	log("API key '%s' (%s) revoked", k.Name, k.Prefix)
+	if _, err := os.Stat(pidFilePath()); err == nil {
+		info("The running API may accept it for up to %s while its cache expires", apiKeyCacheTTL)
+	}
+}
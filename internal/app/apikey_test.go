@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/skaia/grpc/grengo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseAPIKeyScopeAndExpiry(t *testing.T) {
+	for in, want := range map[string]apiKeyScope{
+		"grengo":     {Module: "grengo", Read: true},
+		"forum:w":    {Module: "forum", Write: true},
+		"store.x:rw": {Module: "store.x", Read: true, Write: true},
+	} {
+		if got, err := parseAPIKeyScope(in); err != nil || got != want {
+			t.Errorf("parseAPIKeyScope(%q) = %+v, %v", in, got, err)
+		}
+	}
+	for _, bad := range []string{"", "Forum", "forum:x", ":r"} {
+		if _, err := parseAPIKeyScope(bad); err == nil {
+			t.Errorf("accepted scope %q", bad)
+		}
+	}
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"90d":        now.AddDate(0, 0, 90),
+		"12h":        now.Add(12 * time.Hour),
+		"2027-01-31": time.Date(2027, 1, 31, 0, 0, 0, 0, time.Local),
+	} {
+		if got, err := parseAPIKeyExpiry(in, now); err != nil || !got.Equal(want) {
+			t.Errorf("parseAPIKeyExpiry(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if got, err := parseAPIKeyExpiry("never", now); got != nil || err != nil {
+		t.Errorf("never = %v, %v", got, err)
+	}
+	for _, bad := range []string{"soon", "2020-01-01", "-5d"} {
+		if _, err := parseAPIKeyExpiry(bad, now); err == nil {
+			t.Errorf("accepted expiry %q", bad)
+		}
+	}
+	if raw, err := newAPIKeySecret(); err != nil || !strings.HasPrefix(raw, apiKeyTag) || len(apiKeyPrefix(raw)) != apiKeyPrefixLen {
+		t.Errorf("newAPIKeySecret = %q, %v", raw, err)
+	}
+}
+
+func TestCheckAPIKeyAccess(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	reader := &apiKey{Scopes: []apiKeyScope{{Module: apiKeyModule, Read: true}}}
+	writer := &apiKey{Scopes: []apiKeyScope{{Module: apiKeyModule, Read: true, Write: true}}, SiteScope: []string{"shop"}}
+
+	for _, tc := range []struct {
+		key    *apiKey
+		method string
+		req    any
+		code   codes.Code
+	}{
+		{reader, pb.GrengoService_ListSites_FullMethodName, &pb.ListSitesRequest{}, codes.OK},
+		{reader, pb.GrengoService_DeleteSite_FullMethodName, &pb.SiteRequest{Name: "shop"}, codes.PermissionDenied},
+		{&apiKey{Scopes: []apiKeyScope{{Module: "forum", Read: true}}}, pb.GrengoService_ListSites_FullMethodName, nil, codes.PermissionDenied},
+		{&apiKey{Scopes: reader.Scopes, ExpiresAt: &past}, pb.GrengoService_ListSites_FullMethodName, nil, codes.Unauthenticated},
+		{&apiKey{Scopes: reader.Scopes, RevokedAt: &past}, pb.GrengoService_ListSites_FullMethodName, nil, codes.Unauthenticated},
+		{writer, pb.GrengoService_ArmSite_FullMethodName, &pb.SiteRequest{Name: "shop"}, codes.OK},
+		{writer, pb.GrengoService_ArmSite_FullMethodName, &pb.SiteRequest{Name: "blog"}, codes.PermissionDenied},
+		{writer, pb.GrengoService_ListSites_FullMethodName, &pb.ListSitesRequest{}, codes.OK},
+		{writer, pb.GrengoService_MigrateAll_FullMethodName, &pb.MigrateAllRequest{}, codes.PermissionDenied},
+		{writer, pb.GrengoService_Exec_FullMethodName, &pb.ExecRequest{Command: "logs", Args: []string{"shop"}}, codes.OK},
+		{writer, pb.GrengoService_QueryLogs_FullMethodName, &pb.QueryLogsRequest{Clients: []string{"shop", "blog"}}, codes.PermissionDenied},
+		{writer, pb.GrengoService_SendAction_FullMethodName, &pb.SendActionRequest{Action: []byte(`{"action":"export-site","name":"shop"}`)}, codes.OK},
+	} {
+		err := checkAPIKeyMethod(tc.key, tc.method, now)
+		if err == nil {
+			err = checkAPIKeySites(tc.key, tc.method, decodeRequest(tc.req))
+		}
+		if status.Code(err) != tc.code {
+			t.Errorf("%s %T: %v, want %s", tc.method, tc.req, err, tc.code)
+		}
+	}
+}
+
+func TestAPIKeyCacheResolve(t *testing.T) {
+	c := &apiKeyCache{entries: map[string]apiKeyCacheEntry{}}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	lookups := 0
+	lookup := func(prefix, hash string) (*apiKey, error) {
+		lookups++
+		if prefix != "grk_00112233" || hash != hashAPIKey("grk_0011223344") {
+			return nil, nil
+		}
+		return &apiKey{ID: 7}, nil
+	}
+
+	k, touch, err := c.resolve("grk_0011223344", now, lookup)
+	if err != nil || k == nil || k.ID != 7 || !touch {
+		t.Fatalf("first resolve = %+v, %v, %v", k, touch, err)
+	}
+	if _, touch, _ = c.resolve("grk_0011223344", now.Add(10*time.Second), lookup); touch || lookups != 1 {
+		t.Errorf("cached resolve touched=%v lookups=%d", touch, lookups)
+	}
+	if _, touch, _ = c.resolve("grk_0011223344", now.Add(2*time.Minute), lookup); !touch || lookups != 2 {
+		t.Errorf("expired resolve touched=%v lookups=%d", touch, lookups)
+	}
+	if k, _, _ := c.resolve("grk_bogus", now, lookup); k != nil {
+		t.Errorf("unknown key resolved to %+v", k)
+	}
+	if _, _, err := c.resolve("grk_other", now, func(string, string) (*apiKey, error) { return nil, errors.New("db down") }); err == nil {
+		t.Error("lookup error not returned")
+	}
+}
+
+func TestAPIKeyInterceptorWithoutKey(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: pb.GrengoService_DeleteSite_FullMethodName}
+	called := false
+	_, err := apiKeyInterceptor(context.Background(), &pb.SiteRequest{Name: "shop"}, info, func(ctx context.Context, _ any) (any, error) {
+		called = apiKeyFromContext(ctx) == nil
+		return nil, nil
+	})
+	if err != nil || !called {
+		t.Errorf("call without a key: err=%v called=%v", err, called)
+	}
+}
+
+func TestAuthorizeCallWithAPIKeyAlone(t *testing.T) {
+	now := time.Now()
+	keys := map[string]*apiKey{
+		"grk_reader0000": {ID: 1, Scopes: []apiKeyScope{{Module: apiKeyModule, Read: true}}},
+		"grk_writer0000": {ID: 2, Scopes: []apiKeyScope{{Module: apiKeyModule, Read: true, Write: true}}},
+	}
+	for raw, k := range keys {
+		apiKeys.mu.Lock()
+		apiKeys.entries[hashAPIKey(raw)] = apiKeyCacheEntry{key: k, loaded: now, touched: now}
+		apiKeys.mu.Unlock()
+		t.Cleanup(func() {
+			apiKeys.mu.Lock()
+			delete(apiKeys.entries, hashAPIKey(raw))
+			apiKeys.mu.Unlock()
+		})
+	}
+
+	for _, tc := range []struct {
+		key    string
+		method string
+		req    any
+		code   codes.Code
+	}{
+		{"grk_reader0000", pb.GrengoService_ListSites_FullMethodName, &pb.ListSitesRequest{}, codes.OK},
+		{"grk_reader0000", pb.GrengoService_DeleteSite_FullMethodName, &pb.SiteRequest{Name: "shop"}, codes.PermissionDenied},
+		{"grk_writer0000", pb.GrengoService_StartSite_FullMethodName, &pb.SiteRequest{Name: "shop"}, codes.OK},
+		{"grk_writer0000", pb.GrengoService_Exec_FullMethodName, &pb.ExecRequest{Command: "logs"}, codes.PermissionDenied},
+	} {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-grengo-api-key", tc.key))
+		_, err := authorizeCall(ctx, tc.method, decodeRequest(tc.req))
+		if status.Code(err) != tc.code {
+			t.Errorf("%s with %s: %v, want %s", tc.method, tc.key, err, tc.code)
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveOptions controls encryption and signing of export archives.
+type archiveOptions struct {
+	Target        string // backup target spec, see parseBackupTarget
+	Passphrase    string // encrypt/decrypt with a passphrase-derived key
+	Recipient     string // encrypt to a grengo-x25519 public key
+	IdentityFile  string // decrypt with this x25519 identity (default: node identity)
+	Sign          bool   // write a detached <archive>.sig with the node signing key
+	AllowUnsigned bool   // imports only: accept archives without a trusted signature
+	Incremental   bool   // store a deduplicated snapshot instead of an archive
+	Store         string // chunk store for incremental exports (default exports/store)
+	DryRun        bool   // imports only: print the plan without changing the node
+}
+
+const (
+	archiveEncMagic      = "GRENGO-ENC-1\n"
+	archiveChunkSize     = 64 << 10
+	archiveKDFIterations = 600000
+	// archiveMaxKDFIterations bounds the count read from an archive header,
+	// which a crafted archive could otherwise set high enough to hang an
+	// import.
+	archiveMaxKDFIterations = 4 * archiveKDFIterations
+
+	signingPublicPrefix  = "grengo-ed25519:"
+	identityPublicPrefix = "grengo-x25519:"
+	signingSecretPrefix  = "GRENGO-ED25519-SECRET:"
+	identitySecretPrefix = "GRENGO-X25519-SECRET:"
+)
+
+// errArchiveAuth is returned when an encrypted archive fails authentication,
+// either because the key is wrong or because the ciphertext was modified.
+var errArchiveAuth = errors.New("archive authentication failed (wrong key or tampered archive)")
+
+// Archive keys
+// Node keys live in <root>/.grengo-keys: signing.key (Ed25519) signs
+// manifests, identity.key (X25519) decrypts archives sent to this node and
+// trusted_signers lists the public keys whose signatures imports accept.
+func archiveKeysDir() string     { return filepath.Join(ProjectRoot(), ".grengo-keys") }
+func signingKeyFile() string     { return filepath.Join(archiveKeysDir(), "signing.key") }
+func identityKeyFile() string    { return filepath.Join(archiveKeysDir(), "identity.key") }
+func trustedSignersFile() string { return filepath.Join(archiveKeysDir(), "trusted_signers") }
+
+func encodeKey(prefix string, key []byte) string {
+	return prefix + base64.RawURLEncoding.EncodeToString(key)
+}
+
+func decodeKey(prefix, s string, size int) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, prefix) {
+		return nil, fmt.Errorf("key must start with %q", prefix)
+	}
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, prefix))
+	if err != nil || len(key) != size {
+		return nil, fmt.Errorf("malformed %s key", strings.TrimSuffix(prefix, ":"))
+	}
+	return key, nil
+}
+
+func loadSigningKey() (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(signingKeyFile())
+	if err != nil {
+		return nil, err
+	}
+	seed, err := decodeKey(signingSecretPrefix, string(data), ed25519.SeedSize)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func loadIdentity(file string) (*ecdh.PrivateKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := decodeKey(identitySecretPrefix, string(data), 32)
+	if err != nil {
+		return nil, err
+	}
+	return ecdh.X25519().NewPrivateKey(raw)
+}
+
+// trustedSigners returns the public keys accepted on import: the node's own
+// signing key plus every key listed in trusted_signers.
+func trustedSigners() map[string]bool {
+	trusted := map[string]bool{}
+	if key, err := loadSigningKey(); err == nil {
+		trusted[encodeKey(signingPublicPrefix, key.Public().(ed25519.PublicKey))] = true
+	}
+	data, _ := os.ReadFile(trustedSignersFile())
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		trusted[strings.Fields(line)[0]] = true
+	}
+	return trusted
+}
+
+// cmdKeysInit creates the node signing key and encryption identity if they
+// do not exist yet, then prints the public halves.
+func cmdKeysInit() {
+	ensureWritableDir(archiveKeysDir())
+	os.Chmod(archiveKeysDir(), 0700)
+
+	if _, err := os.Stat(signingKeyFile()); os.IsNotExist(err) {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			die("Cannot generate signing key: %v", err)
+		}
+		if err := os.WriteFile(signingKeyFile(), []byte(encodeKey(signingSecretPrefix, priv.Seed())+"\n"), 0600); err != nil {
+			die("Cannot write %s: %v", signingKeyFile(), err)
+		}
+		log("Signing key created => %s", signingKeyFile())
+	}
+	if _, err := os.Stat(identityKeyFile()); os.IsNotExist(err) {
+		priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			die("Cannot generate identity: %v", err)
+		}
+		if err := os.WriteFile(identityKeyFile(), []byte(encodeKey(identitySecretPrefix, priv.Bytes())+"\n"), 0600); err != nil {
+			die("Cannot write %s: %v", identityKeyFile(), err)
+		}
+		log("Encryption identity created => %s", identityKeyFile())
+	}
+	cmdKeysShow()
+}
+
+// cmdKeysShow prints this node's public signing key and recipient key.
+func cmdKeysShow() {
+	sk, err := loadSigningKey()
+	if err != nil {
+		die("No signing key - run: grengo keys init")
+	}
+	id, err := loadIdentity(identityKeyFile())
+	if err != nil {
+		die("No encryption identity - run: grengo keys init")
+	}
+	fmt.Printf("Signing key:   %s\n", encodeKey(signingPublicPrefix, sk.Public().(ed25519.PublicKey)))
+	fmt.Printf("Recipient key: %s\n", encodeKey(identityPublicPrefix, id.PublicKey().Bytes()))
+}
+
+// cmdKeysTrust adds another node's signing key to trusted_signers.
+func cmdKeysTrust(pub, label string) {
+	if _, err := decodeKey(signingPublicPrefix, pub, ed25519.PublicKeySize); err != nil {
+		die("Invalid signing key: %v", err)
+	}
+	if trustedSigners()[pub] {
+		info("Key already trusted")
+		return
+	}
+	ensureWritableDir(archiveKeysDir())
+	f, err := os.OpenFile(trustedSignersFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		die("Cannot open %s: %v", trustedSignersFile(), err)
+	}
+	defer f.Close()
+	line := pub
+	if label != "" {
+		line += " " + label
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		die("Cannot write %s: %v", trustedSignersFile(), err)
+	}
+	log("Trusted signer added => %s", trustedSignersFile())
+}
+
+// defaultSigning turns signing on for exports whenever this node has a
+// signing key, so its archives pass the signature check imports make by
+// default.
+func defaultSigning(opts archiveOptions) archiveOptions {
+	if !opts.Sign {
+		if _, err := loadSigningKey(); err == nil {
+			opts.Sign = true
+		}
+	}
+	return opts
+}
+
+// Manifest signatures
+// archiveSignature is the detached <archive>.sig file. It signs the exact
+// bytes of manifest.json, which in turn lists the SHA-256 of every entry.
+type archiveSignature struct {
+	Key       string `json:"key"`
+	Signature string `json:"signature"`
+}
+
+func signManifest(archivePath string, manifest []byte) {
+	key, err := loadSigningKey()
+	if err != nil {
+		die("Cannot sign archive - run 'grengo keys init' first (%v)", err)
+	}
+	sig := archiveSignature{
+		Key:       encodeKey(signingPublicPrefix, key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifest)),
+	}
+	data, _ := json.MarshalIndent(sig, "", "  ")
+	if err := os.WriteFile(archivePath+".sig", append(data, '\n'), 0644); err != nil {
+		die("Cannot write signature: %v", err)
+	}
+	log("Archive signed => %s.sig", archivePath)
+}
+
+// verifyManifestSignature checks sigData against manifest and the trusted
+// signer set and returns the signing key.
+func verifyManifestSignature(sigData, manifest []byte, trusted map[string]bool) (string, error) {
+	var sig archiveSignature
+	if err := json.Unmarshal(sigData, &sig); err != nil {
+		return "", fmt.Errorf("malformed signature file: %w", err)
+	}
+	pub, err := decodeKey(signingPublicPrefix, sig.Key, ed25519.PublicKeySize)
+	if err != nil {
+		return "", err
+	}
+	raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil || !ed25519.Verify(pub, manifest, raw) {
+		return "", fmt.Errorf("signature does not match the archive manifest")
+	}
+	if !trusted[sig.Key] {
+		return sig.Key, fmt.Errorf("archive is signed by untrusted key %s - add it with 'grengo keys trust'", sig.Key)
+	}
+	return sig.Key, nil
+}
+
+// Encryption
+// Encrypted archives start with archiveEncMagic, a length-prefixed JSON
+// header and then the gzip stream sealed in AES-256-GCM chunks. The per-file
+// key is wrapped either with a PBKDF2 passphrase key or with an X25519
+// shared secret. Chunk nonces carry a counter and a final-chunk flag so
+// reordering, truncation and appended data are all detected.
+type archiveEncHeader struct {
+	Mode       string `json:"mode"` // "passphrase" or "x25519"
+	Salt       []byte `json:"salt,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+	Ephemeral  []byte `json:"ephemeral,omitempty"`
+	Recipient  string `json:"recipient,omitempty"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Each key-encryption key is derived fresh per archive (random salt or
+// ephemeral key), so a fixed nonce for the single wrap operation is safe.
+var wrapNonce = make([]byte, 12)
+
+func deriveX25519KEK(shared, ephemeral, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	return hkdf.Key(sha256.New, shared, salt, "grengo archive key", 32)
+}
+
+// newArchiveEncryptor writes the encryption header to dst and returns a
+// writer that seals everything written to it. Close must be called to emit
+// the final chunk.
+func newArchiveEncryptor(dst io.Writer, opts archiveOptions) (io.WriteCloser, error) {
+	fileKey := make([]byte, 32)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+
+	var hdr archiveEncHeader
+	var kek []byte
+	switch {
+	case opts.Recipient != "":
+		raw, err := decodeKey(identityPublicPrefix, opts.Recipient, 32)
+		if err != nil {
+			return nil, err
+		}
+		recipient, err := ecdh.X25519().NewPublicKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		shared, err := eph.ECDH(recipient)
+		if err != nil {
+			return nil, err
+		}
+		if kek, err = deriveX25519KEK(shared, eph.PublicKey().Bytes(), raw); err != nil {
+			return nil, err
+		}
+		hdr = archiveEncHeader{Mode: "x25519", Ephemeral: eph.PublicKey().Bytes(), Recipient: opts.Recipient}
+	case opts.Passphrase != "":
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		var err error
+		if kek, err = pbkdf2.Key(sha256.New, opts.Passphrase, salt, archiveKDFIterations, 32); err != nil {
+			return nil, err
+		}
+		hdr = archiveEncHeader{Mode: "passphrase", Salt: salt, Iterations: archiveKDFIterations}
+	default:
+		return nil, fmt.Errorf("encryption needs a passphrase or recipient")
+	}
+
+	wrap, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	hdr.WrappedKey = wrap.Seal(nil, wrapNonce, fileKey, []byte(hdr.Mode))
+
+	hdrJSON, _ := json.Marshal(hdr)
+	var prefix bytes.Buffer
+	prefix.WriteString(archiveEncMagic)
+	binary.Write(&prefix, binary.BigEndian, uint32(len(hdrJSON)))
+	prefix.Write(hdrJSON)
+	if _, err := dst.Write(prefix.Bytes()); err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkSealer{dst: dst, aead: aead, buf: make([]byte, 0, archiveChunkSize)}, nil
+}
+
+func chunkNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+type chunkSealer struct {
+	dst     io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+func (s *chunkSealer) Write(p []byte) (int, error) {
+	total := len(p)
+	for len(p) > 0 {
+		// Only flush a full chunk once more data arrives, so the last chunk
+		// is always the one sealed by Close with the final flag.
+		if len(s.buf) == archiveChunkSize {
+			if err := s.flush(false); err != nil {
+				return total - len(p), err
+			}
+		}
+		n := copy(s.buf[len(s.buf):archiveChunkSize], p)
+		s.buf = s.buf[:len(s.buf)+n]
+		p = p[n:]
+	}
+	return total, nil
+}
+
+func (s *chunkSealer) flush(final bool) error {
+	sealed := s.aead.Seal(nil, chunkNonce(s.counter, final), s.buf, nil)
+	s.counter++
+	s.buf = s.buf[:0]
+	_, err := s.dst.Write(sealed)
+	return err
+}
+
+func (s *chunkSealer) Close() error {
+	return s.flush(true)
+}
+
+// isEncryptedArchive reports whether r starts with the encryption magic.
+func isEncryptedArchive(r *bufio.Reader) bool {
+	head, _ := r.Peek(len(archiveEncMagic))
+	return string(head) == archiveEncMagic
+}
+
+// newArchiveDecryptor reads the encryption header from r, unwraps the file
+// key with opts and returns a reader over the plaintext gzip stream.
+func newArchiveDecryptor(r *bufio.Reader, opts archiveOptions) (io.Reader, error) {
+	if _, err := r.Discard(len(archiveEncMagic)); err != nil {
+		return nil, err
+	}
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, fmt.Errorf("truncated encryption header")
+	}
+	if n > 1<<16 {
+		return nil, fmt.Errorf("encryption header too large")
+	}
+	hdrJSON := make([]byte, n)
+	if _, err := io.ReadFull(r, hdrJSON); err != nil {
+		return nil, fmt.Errorf("truncated encryption header")
+	}
+	var hdr archiveEncHeader
+	if err := json.Unmarshal(hdrJSON, &hdr); err != nil {
+		return nil, fmt.Errorf("malformed encryption header: %w", err)
+	}
+
+	var kek []byte
+	switch hdr.Mode {
+	case "passphrase":
+		if opts.Passphrase == "" {
+			return nil, fmt.Errorf("archive is passphrase-encrypted - use --passphrase-file")
+		}
+		if hdr.Iterations <= 0 || hdr.Iterations > archiveMaxKDFIterations || len(hdr.Salt) == 0 {
+			return nil, fmt.Errorf("malformed encryption header")
+		}
+		var err error
+		if kek, err = pbkdf2.Key(sha256.New, opts.Passphrase, hdr.Salt, hdr.Iterations, 32); err != nil {
+			return nil, err
+		}
+	case "x25519":
+		idFile := opts.IdentityFile
+		if idFile == "" {
+			idFile = identityKeyFile()
+		}
+		id, err := loadIdentity(idFile)
+		if err != nil {
+			return nil, fmt.Errorf("archive is encrypted to %s - use --identity (%v)", hdr.Recipient, err)
+		}
+		eph, err := ecdh.X25519().NewPublicKey(hdr.Ephemeral)
+		if err != nil {
+			return nil, fmt.Errorf("malformed encryption header")
+		}
+		shared, err := id.ECDH(eph)
+		if err != nil {
+			return nil, err
+		}
+		if kek, err = deriveX25519KEK(shared, hdr.Ephemeral, id.PublicKey().Bytes()); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption mode %q", hdr.Mode)
+	}
+
+	wrap, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := wrap.Open(nil, wrapNonce, hdr.WrappedKey, []byte(hdr.Mode))
+	if err != nil {
+		return nil, errArchiveAuth
+	}
+	aead, err := newGCM(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkOpener{src: r, aead: aead, sealed: make([]byte, archiveChunkSize+aead.Overhead())}, nil
+}
+
+type chunkOpener struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	sealed  []byte
+	plain   []byte
+	counter uint64
+	done    bool
+	err     error
+}
+
+func (o *chunkOpener) Read(p []byte) (int, error) {
+	for len(o.plain) == 0 {
+		if o.err != nil {
+			return 0, o.err
+		}
+		if o.done {
+			// Anything after the final chunk is tampering.
+			if _, err := o.src.Peek(1); err == nil {
+				o.err = errArchiveAuth
+				continue
+			}
+			return 0, io.EOF
+		}
+		o.err = o.next()
+	}
+	n := copy(p, o.plain)
+	o.plain = o.plain[n:]
+	return n, nil
+}
+
+func (o *chunkOpener) next() error {
+	n, err := io.ReadFull(o.src, o.sealed)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		// A stream that ends without a final chunk has been truncated.
+		return errArchiveAuth
+	}
+	final := err == io.ErrUnexpectedEOF
+	if !final {
+		if _, perr := o.src.Peek(1); perr != nil {
+			final = true
+		}
+	}
+	plain, oerr := o.aead.Open(nil, chunkNonce(o.counter, final), o.sealed[:n], nil)
+	if oerr != nil {
+		return errArchiveAuth
+	}
+	o.counter++
+	o.plain = plain
+	o.done = final
+	return nil
+}
+
+// readPassphraseFile returns the first line of path, for --passphrase-file.
+func readPassphraseFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		die("Cannot read passphrase file: %v", err)
+	}
+	pass := strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r")
+	if pass == "" {
+		die("Passphrase file %s is empty", path)
+	}
+	return pass
+}
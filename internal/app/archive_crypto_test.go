@@ -0,0 +1,179 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sealBytes(t *testing.T, plain []byte, opts archiveOptions) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := newArchiveEncryptor(&buf, opts)
+	if err != nil {
+		t.Fatalf("newArchiveEncryptor: %v", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func openBytes(sealed []byte, opts archiveOptions) ([]byte, error) {
+	br := bufio.NewReader(bytes.NewReader(sealed))
+	if !isEncryptedArchive(br) {
+		return nil, errors.New("missing magic")
+	}
+	r, err := newArchiveDecryptor(br, opts)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestArchiveEncryptionRoundTrip(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	id, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idFile := filepath.Join(t.TempDir(), "identity.key")
+	os.WriteFile(idFile, []byte(encodeKey(identitySecretPrefix, id.Bytes())), 0600)
+	recipient := encodeKey(identityPublicPrefix, id.PublicKey().Bytes())
+
+	sizes := []int{0, 1, archiveChunkSize - 1, archiveChunkSize, archiveChunkSize + 1, 3*archiveChunkSize + 17}
+	for _, size := range sizes {
+		plain := make([]byte, size)
+		rand.Read(plain)
+
+		sealed := sealBytes(t, plain, archiveOptions{Passphrase: "correct horse"})
+		got, err := openBytes(sealed, archiveOptions{Passphrase: "correct horse"})
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Fatalf("passphrase round trip (size %d): err=%v equal=%v", size, err, bytes.Equal(got, plain))
+		}
+		if _, err := openBytes(sealed, archiveOptions{Passphrase: "wrong"}); !errors.Is(err, errArchiveAuth) {
+			t.Fatalf("wrong passphrase (size %d): err=%v", size, err)
+		}
+
+		sealed = sealBytes(t, plain, archiveOptions{Recipient: recipient})
+		got, err = openBytes(sealed, archiveOptions{IdentityFile: idFile})
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Fatalf("recipient round trip (size %d): err=%v", size, err)
+		}
+	}
+}
+
+func TestArchiveEncryptionDetectsTampering(t *testing.T) {
+	plain := bytes.Repeat([]byte("grengo"), archiveChunkSize/2)
+	opts := archiveOptions{Passphrase: "pw"}
+	sealed := sealBytes(t, plain, opts)
+	chunk := archiveChunkSize + 16
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[len(flipped)-chunk-5] ^= 1
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "flipped bit", data: flipped},
+		{name: "truncated at chunk boundary", data: sealed[:len(sealed)-chunk]},
+		{name: "truncated mid chunk", data: sealed[:len(sealed)-3]},
+		{name: "appended data", data: append(append([]byte(nil), sealed...), 0)},
+	}
+	for _, tt := range tests {
+		if _, err := openBytes(tt.data, opts); !errors.Is(err, errArchiveAuth) {
+			t.Fatalf("%s: err = %v, want errArchiveAuth", tt.name, err)
+		}
+	}
+}
+
+func TestArchiveDecryptorBoundsKDFIterations(t *testing.T) {
+	hdr, _ := json.Marshal(archiveEncHeader{Mode: "passphrase", Salt: []byte("salt"), Iterations: archiveMaxKDFIterations + 1})
+	var buf bytes.Buffer
+	buf.WriteString(archiveEncMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(len(hdr)))
+	buf.Write(hdr)
+	_, err := newArchiveDecryptor(bufio.NewReader(&buf), archiveOptions{Passphrase: "pw"})
+	if err == nil || !strings.Contains(err.Error(), "malformed encryption header") {
+		t.Fatalf("oversized iteration count accepted: %v", err)
+	}
+}
+
+func TestVerifyManifestSignature(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	key := encodeKey(signingPublicPrefix, pub)
+	manifest := []byte(`{"version":1,"files":{"env":"abc"}}`)
+	sig := []byte(`{"key":"` + key + `","signature":"` + base64.StdEncoding.EncodeToString(ed25519.Sign(priv, manifest)) + `"}`)
+
+	if signer, err := verifyManifestSignature(sig, manifest, map[string]bool{key: true}); err != nil || signer != key {
+		t.Fatalf("trusted signature: signer=%q err=%v", signer, err)
+	}
+	if _, err := verifyManifestSignature(sig, manifest, map[string]bool{}); err == nil || !strings.Contains(err.Error(), "untrusted") {
+		t.Fatalf("untrusted key accepted: %v", err)
+	}
+	if _, err := verifyManifestSignature(sig, append(manifest, ' '), map[string]bool{key: true}); err == nil {
+		t.Fatal("signature accepted for a modified manifest")
+	}
+}
+
+func TestArchiveManifestAndSignature(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	os.MkdirAll(archiveKeysDir(), 0700)
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	os.WriteFile(signingKeyFile(), []byte(encodeKey(signingSecretPrefix, priv.Seed())), 0600)
+
+	out := filepath.Join(t.TempDir(), "grengo-client-a.tar.gz")
+	opts := archiveOptions{Passphrase: "pw", Sign: true}
+	w := createArchive(out, opts)
+	writeMeta(w, archiveMeta{Version: archiveVersion, Type: "client", Name: "a"})
+	addBytesToArchive(w, []byte("PORT=1080\n"), "env")
+	finishArchive(w, out, opts)
+
+	files, err := loadArchive(out, opts)
+	if err != nil {
+		t.Fatalf("loadArchive: %v", err)
+	}
+	if _, ok := files["manifest.json"]; !ok {
+		t.Fatal("manifest.json missing")
+	}
+	if signer, err := verifyArchive(out, files, opts); err != nil || signer == "" {
+		t.Fatalf("verifyArchive: signer=%q err=%v", signer, err)
+	}
+
+	files["env"] = []byte("PORT=9999\n")
+	if _, err := verifyArchive(out, files, opts); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("modified entry accepted: %v", err)
+	}
+
+	if _, err := loadArchive(out, archiveOptions{}); err == nil || !strings.Contains(err.Error(), "passphrase") {
+		t.Fatalf("encrypted archive opened without a passphrase: %v", err)
+	}
+
+	os.Remove(out + ".sig")
+	files, _ = loadArchive(out, opts)
+	if _, err := verifyArchive(out, files, archiveOptions{}); err == nil {
+		t.Fatal("unsigned archive accepted by default")
+	}
+	if _, err := verifyArchive(out, files, archiveOptions{AllowUnsigned: true}); err != nil {
+		t.Fatalf("unsigned archive refused with AllowUnsigned: %v", err)
+	}
+	delete(files, "manifest.json")
+	if _, err := verifyArchive(out, files, archiveOptions{}); err == nil {
+		t.Fatal("archive without a manifest accepted by default")
+	}
+}
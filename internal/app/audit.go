@@ -0,0 +1,650 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/skaia/grpc/grengo"
+)
+
+const (
+	auditViaGRPC = "grpc"
+	auditViaHTTP = "http"
+	auditViaWS   = "ws"
+	auditViaCLI  = "cli"
+
+	auditResultOK     = "ok"
+	auditResultError  = "error"
+	auditResultDenied = "denied"
+
+	auditRedacted = "[redacted]"
+)
+
+// auditSecretKey matches argument, flag and env names whose values are
+// never written to the audit log.
+var auditSecretKey = regexp.MustCompile(`(?i)(secret|password|passwd|passcode|passphrase|totp|token|api_?key|private|credential|dsn|database_url|^p[12]$)`)
+
+// auditedMethods are the gRPC methods that change the node, by the command
+// name they are logged under. Reads are not audited.
+var auditedMethods = map[string]string{
+	pb.GrengoService_Exec_FullMethodName:            "exec",
+	pb.GrengoService_SendAction_FullMethodName:      "action",
+	pb.GrengoService_CreateSite_FullMethodName:      "create-site",
+	pb.GrengoService_ProvisionFrappe_FullMethodName: "provision-frappe",
+	pb.GrengoService_DeleteSite_FullMethodName:      "delete-site",
+	pb.GrengoService_StartSite_FullMethodName:       "start-site",
+	pb.GrengoService_StopSite_FullMethodName:        "stop-site",
+	pb.GrengoService_EnableSite_FullMethodName:      "enable-site",
+	pb.GrengoService_DisableSite_FullMethodName:     "disable-site",
+	pb.GrengoService_ArmSite_FullMethodName:         "arm-site",
+	pb.GrengoService_DisarmSite_FullMethodName:      "disarm-site",
+	pb.GrengoService_UpdateSiteEnv_FullMethodName:   "update-env",
+	pb.GrengoService_ExportSite_FullMethodName:      "export-site",
+	pb.GrengoService_ImportSite_FullMethodName:      "import-site",
+	pb.GrengoService_MigrateSite_FullMethodName:     "migrate-site",
+	pb.GrengoService_MigrateAll_FullMethodName:      "migrate-all",
+	pb.GrengoService_ExportNode_FullMethodName:      "export-node",
+	pb.GrengoService_ImportNode_FullMethodName:      "import-node",
+	pb.GrengoService_DeleteExport_FullMethodName:    "delete-export",
+	pb.GrengoService_RollbackSite_FullMethodName:    "rollback-site",
+	pb.GrengoService_SetSiteQuota_FullMethodName:    "set-quota",
+	pb.GrengoService_MoveSite_FullMethodName:        "move-site",
+	pb.GrengoService_UploadExport_FullMethodName:    "upload-export",
+	pb.GrengoService_VerifyPasscode_FullMethodName:  "passcode-verify",
+	pb.GrengoService_Login_FullMethodName:           "login",
+}
+
+// auditCaller is who asked for an action and from where.
+type auditCaller struct {
+	Actor        string
+	APIKeyPrefix string
+	SourceIP     string
+}
+
+// callerActor names the credential a call presented: the operator behind
+// a session token, an API key by name, or the shared passcode. The Skaia
+// backend calls with the passcode and names its signed-in user in
+// x-grengo-actor; that name is client-supplied, so it is only recorded
+// under the passcode and never stands in for an operator or key.
+func callerActor(token, passcode, key, claimed string) string {
+	if op, err := sessionFromToken(token); err == nil {
+		return op.Username
+	}
+	if key != "" {
+		if k := apiKeys.cached(key); k != nil {
+			return "apikey:" + k.Name
+		}
+	}
+	if passcode != "" {
+		if claimed = strings.TrimSpace(claimed); claimed != "" {
+			return "passcode:" + claimed
+		}
+		return "passcode"
+	}
+	return ""
+}
+
+// grpcAuditCaller reads the caller from request metadata. Fleet peers are
+// named by their certificate and other callers by callerActor; the
+// browser's address comes from x-forwarded-for.
+func grpcAuditCaller(ctx context.Context) auditCaller {
+	var c auditCaller
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			c.SourceIP = host
+		}
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			c.Actor = "fleet:" + tlsInfo.State.PeerCertificates[0].Subject.CommonName
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		key := strings.TrimSpace(metadataValue(md, "x-grengo-api-key"))
+		if actor := callerActor(bearerToken(metadataValue(md, "authorization")), metadataValue(md, "x-grengo-passcode"),
+			key, metadataValue(md, "x-grengo-actor")); actor != "" {
+			c.Actor = actor
+		}
+		if key != "" {
+			c.APIKeyPrefix = apiKeyPrefix(key)
+		}
+		if v := md.Get("x-forwarded-for"); len(v) > 0 {
+			if ip := strings.TrimSpace(strings.Split(v[0], ",")[0]); ip != "" {
+				c.SourceIP = ip
+			}
+		}
+	}
+	return c
+}
+
+// httpAuditCaller is grpcAuditCaller for the HTTP handlers. X-Forwarded-For
+// is only trusted from loopback, where the Skaia backend proxies from.
+func httpAuditCaller(r *http.Request) auditCaller {
+	key := strings.TrimSpace(r.Header.Get("X-Grengo-API-Key"))
+	c := auditCaller{
+		Actor: callerActor(bearerToken(r.Header.Get("Authorization")), r.Header.Get("X-Grengo-Passcode"),
+			key, r.Header.Get("X-Grengo-Actor")),
+		APIKeyPrefix: apiKeyPrefix(key),
+	}
+	c.SourceIP, _, _ = net.SplitHostPort(r.RemoteAddr)
+	if ip := net.ParseIP(c.SourceIP); ip != nil && ip.IsLoopback() {
+		if fwd := strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-For"), ",")[0]); fwd != "" {
+			c.SourceIP = fwd
+		}
+	}
+	return c
+}
+
+// cliAuditCaller names the operator running grengo on this host.
+func cliAuditCaller() auditCaller {
+	name := os.Getenv("SUDO_USER")
+	if name == "" {
+		if u, err := user.Current(); err == nil {
+			name = u.Username
+		}
+	}
+	return auditCaller{Actor: name, SourceIP: "local"}
+}
+
+// redactEnvContent keeps the keys of a .env file and hides secret values.
+func redactEnvContent(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		key, _, ok := strings.Cut(line, "=")
+		if ok && !strings.HasPrefix(strings.TrimSpace(line), "#") && auditSecretKey.MatchString(strings.TrimSpace(key)) {
+			lines[i] = key + "=" + auditRedacted
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// redactAuditArgs hides secrets in decoded request arguments: values under
+// secret-looking keys, the value after a secret-looking --flag, KEY=VALUE
+// pairs with a secret key and the values in .env content.
+func redactAuditArgs(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			switch {
+			case k == "chunk":
+				delete(t, k)
+			case auditSecretKey.MatchString(k):
+				t[k] = auditRedacted
+			case k == "content":
+				if s, ok := val.(string); ok {
+					t[k] = redactEnvContent(s)
+				}
+			default:
+				t[k] = redactAuditArgs(val)
+			}
+		}
+		return t
+	case []any:
+		hideNext := false
+		for i, val := range t {
+			s, ok := val.(string)
+			if !ok {
+				t[i] = redactAuditArgs(val)
+				continue
+			}
+			if hideNext {
+				t[i], hideNext = auditRedacted, false
+				continue
+			}
+			if key, _, ok := strings.Cut(s, "="); ok && auditSecretKey.MatchString(strings.TrimLeft(key, "-")) {
+				t[i] = key + "=" + auditRedacted
+			} else if strings.HasPrefix(s, "--") && auditSecretKey.MatchString(strings.TrimLeft(s, "-")) {
+				hideNext = true
+			}
+		}
+		return t
+	}
+	return v
+}
+
+// decodeRequest turns a gRPC request into plain JSON values. SendAction
+// carries its request as JSON bytes, which are decoded in place of the
+// wrapper.
+func decodeRequest(req any) any {
+	var raw []byte
+	if a, ok := req.(*pb.SendActionRequest); ok {
+		raw = a.Action
+	} else {
+		raw, _ = json.Marshal(req)
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+	return decoded
+}
+
+// requestSites names the clients a decoded request acts on.
+func requestSites(decoded any) []string {
+	m, ok := decoded.(map[string]any)
+	if !ok {
+		return nil
+	}
+	for _, k := range []string{"name", "site", "site_name", "client"} {
+		if s, ok := m[k].(string); ok && s != "" {
+			return []string{s}
+		}
+	}
+	var sites []string
+	if list, ok := m["clients"].([]any); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok && s != "" {
+				sites = append(sites, s)
+			}
+		}
+		return sites
+	}
+	// Exec runs a grengo command whose first argument is usually the
+	// client, as in 'enable shop'.
+	if list, ok := m["args"].([]any); ok && len(list) > 0 {
+		if s, ok := list[0].(string); ok && nameError(s) == "" {
+			return []string{s}
+		}
+	}
+	return nil
+}
+
+// auditArgs renders a request as redacted JSON and names its target site.
+func auditArgs(req any) (json.RawMessage, string) {
+	decoded := decodeRequest(req)
+	if decoded == nil {
+		return json.RawMessage("{}"), ""
+	}
+	target := ""
+	if sites := requestSites(decoded); len(sites) > 0 {
+		target = sites[0]
+	}
+	out, _ := json.Marshal(redactAuditArgs(decoded))
+	return out, target
+}
+
+// auditOutcome classifies a finished call. Responses that report failure in
+// their body (ok, accepted or valid false) count as errors or denials.
+func auditOutcome(resp any, err error) (string, string) {
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return auditResultDenied, status.Convert(err).Message()
+		}
+		return auditResultError, err.Error()
+	}
+	msg := ""
+	if r, ok := resp.(interface{ GetError() string }); ok {
+		msg = r.GetError()
+	}
+	if r, ok := resp.(interface{ GetValid() bool }); ok && !r.GetValid() {
+		return auditResultDenied, "invalid passcode"
+	}
+	if r, ok := resp.(interface{ GetTotpRequired() bool }); ok && r.GetTotpRequired() {
+		return auditResultDenied, "totp code required"
+	}
+	if r, ok := resp.(interface{ GetOk() bool }); ok && !r.GetOk() {
+		return auditResultError, msg
+	}
+	if r, ok := resp.(interface{ GetAccepted() bool }); ok && !r.GetAccepted() {
+		return auditResultError, msg
+	}
+	return auditResultOK, ""
+}
+
+func newAuditEntry(via, command string, c auditCaller, start time.Time) auditEntry {
+	return auditEntry{
+		At:           start.UTC(),
+		Actor:        c.Actor,
+		APIKeyPrefix: c.APIKeyPrefix,
+		SourceIP:     c.SourceIP,
+		Via:          via,
+		Command:      command,
+		Args:         json.RawMessage("{}"),
+		DurationMS:   time.Since(start).Milliseconds(),
+	}
+}
+
+// auditInterceptor records audited unary calls. It runs before the passcode
+// check so rejected attempts are logged as denied.
+func auditInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	command, ok := auditedMethods[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	e := newAuditEntry(auditViaGRPC, command, grpcAuditCaller(ctx), start)
+	e.Args, e.Target = auditArgs(req)
+	if a, ok := req.(*pb.SendActionRequest); ok {
+		var parsed grengoActionRequest
+		if json.Unmarshal(a.Action, &parsed) == nil && parsed.Action != "" {
+			e.Command = parsed.Action
+		}
+	}
+	e.Result, e.Error = auditOutcome(resp, err)
+	recordAudit(e)
+	return resp, err
+}
+
+// auditServerStream keeps the first message a client sends on a stream,
+// which carries the request for server streams and the file name for
+// uploads.
+type auditServerStream struct {
+	grpc.ServerStream
+	first any
+}
+
+func (s *auditServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.first == nil {
+		s.first = m
+	}
+	return err
+}
+
+func auditStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	command, ok := auditedMethods[info.FullMethod]
+	if !ok {
+		return handler(srv, ss)
+	}
+	start := time.Now()
+	wrapped := &auditServerStream{ServerStream: ss}
+	err := handler(srv, wrapped)
+	e := newAuditEntry(auditViaGRPC, command, grpcAuditCaller(ss.Context()), start)
+	if wrapped.first != nil {
+		e.Args, e.Target = auditArgs(wrapped.first)
+	}
+	e.Result, e.Error = auditOutcome(nil, err)
+	recordAudit(e)
+	return err
+}
+
+// auditStatusWriter remembers the status an HTTP handler answered with and
+// the start of its body.
+type auditStatusWriter struct {
+	http.ResponseWriter
+	status int
+	body   []byte
+}
+
+func (w *auditStatusWriter) Write(b []byte) (int, error) {
+	if room := 4096 - len(w.body); room > 0 {
+		w.body = append(w.body, b[:min(room, len(b))]...)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditStatusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditStatusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// auditedHTTP records calls to an HTTP handler that changes the node. JSON
+// bodies up to 1MB are logged, redacted; other bodies are not.
+func auditedHTTP(command string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		args := map[string]any{}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") || r.Header.Get("Content-Type") == "" {
+			body, _ := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+			json.Unmarshal(body, &args)
+		}
+		for _, k := range []string{"name", "filename"} {
+			if v := r.PathValue(k); v != "" {
+				args[k] = v
+			}
+		}
+
+		sw := &auditStatusWriter{ResponseWriter: w}
+		h(sw, r)
+
+		e := newAuditEntry(auditViaHTTP, command, httpAuditCaller(r), start)
+		e.Args, e.Target = auditArgs(args)
+		var reply struct {
+			Valid *bool  `json:"valid"`
+			Error string `json:"error"`
+		}
+		json.Unmarshal(sw.body, &reply)
+		e.Result = auditResultOK
+		switch {
+		case sw.status == http.StatusUnauthorized || sw.status == http.StatusForbidden:
+			e.Result, e.Error = auditResultDenied, reply.Error
+		case sw.status >= 400:
+			e.Result, e.Error = auditResultError, reply.Error
+			if e.Error == "" {
+				e.Error = http.StatusText(sw.status)
+			}
+		case reply.Valid != nil && !*reply.Valid:
+			e.Result, e.Error = auditResultDenied, "invalid passcode"
+		}
+		recordAudit(e)
+	}
+}
+
+// The API process hands entries to one writer so an audited call never
+// waits on the management DB.
+var auditQueue = make(chan auditEntry, 256)
+
+func recordAudit(e auditEntry) {
+	select {
+	case auditQueue <- e:
+	default:
+		spoolAudit(e)
+	}
+}
+
+// runAuditWriter stores queued entries, spooling them to disk while the
+// management DB is unavailable.
+func runAuditWriter() {
+	svc := newGrengoService()
+	for e := range auditQueue {
+		writeAudit(svc, e)
+	}
+}
+
+// writeAudit stores an entry after anything still spooled, so the log keeps
+// its order across DB outages.
+func writeAudit(svc grengoService, e auditEntry) {
+	if err := flushAuditSpool(svc); err == nil {
+		if err = svc.AppendAudit(e); err == nil {
+			return
+		}
+	}
+	spoolAudit(e)
+}
+
+func auditSpoolFile() string { return filepath.Join(ProjectRoot(), ".grengo-audit-spool.jsonl") }
+
+func spoolAudit(e auditEntry) {
+	f, err := os.OpenFile(auditSpoolFile(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		BroadcastLog("ERROR", logPrefix("audit"), fmt.Sprintf("audit entry lost: %v", err))
+		return
+	}
+	defer f.Close()
+	syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+	line, _ := json.Marshal(e)
+	f.Write(append(line, '\n'))
+}
+
+// flushAuditSpool moves spooled entries into the DB, keeping those that
+// still fail.
+func flushAuditSpool(svc grengoService) error {
+	f, err := os.OpenFile(auditSpoolFile(), os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	var pending [][]byte
+	var flushErr error
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 4<<20)
+	for sc.Scan() {
+		line := append([]byte(nil), sc.Bytes()...)
+		var e auditEntry
+		if json.Unmarshal(line, &e) != nil {
+			continue
+		}
+		if flushErr == nil {
+			flushErr = svc.AppendAudit(e)
+		}
+		if flushErr != nil {
+			pending = append(pending, line)
+		}
+	}
+	if len(pending) == 0 {
+		os.Remove(auditSpoolFile())
+		return nil
+	}
+	f.Truncate(0)
+	f.WriteAt(append(bytes.Join(pending, []byte("\n")), '\n'), 0)
+	return flushErr
+}
+
+// auditWSAction records an action sent over the HTTP WebSocket.
+func auditWSAction(r *http.Request, msg []byte, req grengoActionRequest, start time.Time, err error) {
+	e := newAuditEntry(auditViaWS, req.Action, httpAuditCaller(r), start)
+	e.Args, e.Target = auditArgs(&pb.SendActionRequest{Action: msg})
+	e.Result = auditResultOK
+	if err != nil {
+		e.Result, e.Error = auditResultError, err.Error()
+	}
+	recordAudit(e)
+}
+
+// auditCLI records a control-plane change made from the command line.
+func auditCLI(command string, args map[string]any, start time.Time, err error) {
+	e := newAuditEntry(auditViaCLI, command, cliAuditCaller(), start)
+	e.Args, e.Target = auditArgs(args)
+	e.Result = auditResultOK
+	if err != nil {
+		e.Result, e.Error = auditResultError, err.Error()
+	}
+	writeAudit(newGrengoService(), e)
+}
+
+// auditFilterFrom resolves the CLI filters; since and until take the same
+// forms as 'logs --since'.
+func auditFilterFrom(actor, command, target, result, since, until string, limit int) auditFilter {
+	f := auditFilter{Actor: actor, Command: command, Target: target, Result: result, Limit: limit}
+	switch result {
+	case "", auditResultOK, auditResultError, auditResultDenied:
+	default:
+		die("Invalid --result %q (ok, error or denied)", result)
+	}
+	var err error
+	now := time.Now()
+	if f.Since, err = parseLogTime(since, now); err != nil {
+		die("%v", err)
+	}
+	if f.Until, err = parseLogTime(until, now); err != nil {
+		die("%v", err)
+	}
+	return f
+}
+
+// cmdAuditList prints the newest matching audit entries.
+func cmdAuditList(actor, command, target, result, since, until string, limit int) {
+	entries, err := newGrengoService().ListAudit(auditFilterFrom(actor, command, target, result, since, until, limit))
+	if err != nil {
+		die("Cannot read audit log: %v", err)
+	}
+	if len(entries) == 0 {
+		info("No audit entries")
+		return
+	}
+	fmt.Printf("  %-7s %-19s %-5s %-16s %-16s %-16s %-7s %s\n", "ID", "AT", "VIA", "ACTOR", "COMMAND", "TARGET", "RESULT", "SOURCE")
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		actor := e.Actor
+		if e.APIKeyPrefix != "" {
+			actor += " (" + e.APIKeyPrefix + ")"
+		}
+		res := fmt.Sprintf("%-7s", e.Result)
+		switch e.Result {
+		case auditResultError:
+			res = colorRed + res + colorReset
+		case auditResultDenied:
+			res = colorYellow + res + colorReset
+		}
+		fmt.Printf("  %-7d %-19s %-5s %-16s %-16s %-16s %s %s\n", e.ID, e.At.Local().Format("2006-01-02 15:04:05"),
+			e.Via, actor, e.Command, e.Target, res, e.SourceIP)
+		if e.Error != "" {
+			fmt.Printf("          %s%s%s\n", colorRed, e.Error, colorReset)
+		}
+	}
+}
+
+// cmdAuditExport writes every matching entry as JSON lines, oldest first,
+// to outFile or stdout.
+func cmdAuditExport(actor, command, target, result, since, until, outFile string) {
+	f := auditFilterFrom(actor, command, target, result, since, until, 1000)
+	f.Ascending = true
+	out := os.Stdout
+	if outFile != "" && outFile != "-" {
+		file, err := os.OpenFile(outFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			die("Cannot create %s: %v", outFile, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	w := bufio.NewWriter(out)
+	svc := newGrengoService()
+	total := 0
+	for {
+		entries, err := svc.ListAudit(f)
+		if err != nil {
+			die("Cannot read audit log: %v", err)
+		}
+		for _, e := range entries {
+			line, _ := json.Marshal(e)
+			w.Write(append(line, '\n'))
+			f.AfterID = e.ID
+		}
+		total += len(entries)
+		if len(entries) < f.Limit {
+			break
+		}
+	}
+	if err := w.Flush(); err != nil {
+		die("Cannot write audit export: %v", err)
+	}
+	if out != os.Stdout {
+		log("Exported %d audit entries to %s", total, outFile)
+	}
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/skaia/grpc/grengo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuditArgsRedaction(t *testing.T) {
+	args, target := auditArgs(&pb.ExecRequest{Command: "db", Args: []string{"shop", "--password", "hunter2", "--user", "admin", "DB_PASSWORD=hunter2", "PORT=80"}})
+	if target != "shop" {
+		t.Errorf("target = %q", target)
+	}
+	s := string(args)
+	if strings.Contains(s, "hunter2") {
+		t.Errorf("secret leaked: %s", s)
+	}
+	for _, want := range []string{`"admin"`, `"PORT=80"`, `"DB_PASSWORD=[redacted]"`, `"db"`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("args %s missing %s", s, want)
+		}
+	}
+
+	args, _ = auditArgs(&pb.UpdateSiteEnvRequest{Name: "shop", Content: "PORT=8080\nJWT_SECRET=abc\n# STRIPE_KEY=old\nSMTP_PASSWORD=xyz"})
+	var env struct{ Content string }
+	json.Unmarshal(args, &env)
+	if want := "PORT=8080\nJWT_SECRET=[redacted]\n# STRIPE_KEY=old\nSMTP_PASSWORD=[redacted]"; env.Content != want {
+		t.Errorf("env content = %q, want %q", env.Content, want)
+	}
+
+	args, _ = auditArgs(&pb.VerifyPasscodeRequest{P1: "one", P2: "two"})
+	if strings.Contains(string(args), "one") || strings.Contains(string(args), "two") {
+		t.Errorf("passcode leaked: %s", args)
+	}
+
+	args, target = auditArgs(&pb.SendActionRequest{Action: []byte(`{"action":"export-site","name":"blog","args":["--passphrase","x1"]}`)})
+	if target != "blog" || strings.Contains(string(args), "x1") {
+		t.Errorf("action args = %s, target %q", args, target)
+	}
+}
+
+func TestAuditOutcome(t *testing.T) {
+	for _, tc := range []struct {
+		resp   any
+		err    error
+		result string
+	}{
+		{&pb.ExecResponse{Ok: true}, nil, auditResultOK},
+		{&pb.ExecResponse{Ok: false, Error: "exit 1"}, nil, auditResultError},
+		{&pb.VerifyPasscodeResponse{Valid: false}, nil, auditResultDenied},
+		{nil, status.Error(codes.Unauthenticated, "invalid passcode"), auditResultDenied},
+		{nil, status.Error(codes.NotFound, "no such site"), auditResultError},
+		{&pb.EmptyResponse{}, nil, auditResultOK},
+	} {
+		if got, _ := auditOutcome(tc.resp, tc.err); got != tc.result {
+			t.Errorf("auditOutcome(%T, %v) = %s, want %s", tc.resp, tc.err, got, tc.result)
+		}
+	}
+}
+
+func TestAuditInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-grengo-actor", "alice",
+		"x-grengo-api-key", "grengo_live_abcdef0123456789",
+		"x-forwarded-for", "203.0.113.7, 10.0.0.1",
+	))
+	info := &grpc.UnaryServerInfo{FullMethod: pb.GrengoService_DeleteSite_FullMethodName}
+	_, err := auditInterceptor(ctx, &pb.SiteRequest{Name: "shop"}, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.PermissionDenied, "armed")
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("err = %v", err)
+	}
+	e := <-auditQueue
+	if e.Actor != "" || e.APIKeyPrefix != "grengo_live_" || e.SourceIP != "203.0.113.7" || e.Via != auditViaGRPC ||
+		e.Command != "delete-site" || e.Target != "shop" || e.Result != auditResultDenied {
+		t.Errorf("entry = %+v", e)
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-grengo-actor", "alice",
+		"x-grengo-passcode", "p1:p2",
+	))
+	auditInterceptor(ctx, &pb.SiteRequest{Name: "shop"}, info, func(context.Context, any) (any, error) { return nil, nil })
+	if e := <-auditQueue; e.Actor != "passcode:alice" {
+		t.Errorf("passcode caller recorded as %q", e.Actor)
+	}
+
+	raw := "grengo_live_abcdef0123456789"
+	apiKeys.mu.Lock()
+	apiKeys.entries[hashAPIKey(raw)] = apiKeyCacheEntry{key: &apiKey{Name: "ci"}, loaded: time.Now()}
+	apiKeys.mu.Unlock()
+	t.Cleanup(func() {
+		apiKeys.mu.Lock()
+		delete(apiKeys.entries, hashAPIKey(raw))
+		apiKeys.mu.Unlock()
+	})
+	if actor := callerActor("", "p1:p2", raw, "alice"); actor != "apikey:ci" {
+		t.Errorf("api key caller recorded as %q", actor)
+	}
+
+	info.FullMethod = pb.GrengoService_ListSites_FullMethodName
+	auditInterceptor(ctx, &pb.ListSitesRequest{}, info, func(context.Context, any) (any, error) { return nil, nil })
+	select {
+	case e := <-auditQueue:
+		t.Errorf("read-only call audited: %+v", e)
+	default:
+	}
+}
+
+func TestAuditedHTTP(t *testing.T) {
+	h := auditedHTTP("update-env", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["content"] == "" {
+			apiError(w, http.StatusBadRequest, "content required")
+			return
+		}
+		apiJSON(w, http.StatusOK, map[string]any{"ok": true})
+	})
+	req := httptest.NewRequest(http.MethodPut, "/sites/shop/env", bytes.NewBufferString(`{"name":"shop","content":"API_KEY=k1"}`))
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set("X-Grengo-Actor", "bob")
+	req.Header.Set("X-Grengo-Passcode", "p1:p2")
+	req.Header.Set("X-Forwarded-For", "198.51.100.2")
+	h(httptest.NewRecorder(), req)
+	e := <-auditQueue
+	if e.Result != auditResultOK || e.Actor != "passcode:bob" || e.SourceIP != "198.51.100.2" || e.Target != "shop" || strings.Contains(string(e.Args), "k1") {
+		t.Errorf("entry = %+v args %s", e, e.Args)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/sites/shop/env", bytes.NewBufferString(`{}`))
+	req.RemoteAddr = "192.0.2.1:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.2")
+	h(httptest.NewRecorder(), req)
+	e = <-auditQueue
+	if e.Result != auditResultError || e.Error != "content required" || e.SourceIP != "192.0.2.1" {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestSpoolAudit(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	for _, cmd := range []string{"exec", "arm-site"} {
+		spoolAudit(auditEntry{At: time.Now(), Via: auditViaCLI, Command: cmd, Args: json.RawMessage(`{}`), Result: auditResultOK})
+	}
+	data, err := os.ReadFile(auditSpoolFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("spooled %d lines", len(lines))
+	}
+	var e auditEntry
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil || e.Command != "arm-site" {
+		t.Errorf("second line = %s, %v", lines[1], err)
+	}
+}
@@ -0,0 +1,401 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skaia/grengo/internal/services"
+)
+
+const (
+	backupArchivePrefix  = "grengo-backup-"
+	backupTimeLayout     = "20060102-150405"
+	defaultBackupCron    = "@daily"
+	defaultKeepDaily     = 7
+	defaultKeepWeekly    = 4
+	defaultKeepMonthly   = 6
+	backupSchedulerDelay = 30 * time.Second
+)
+
+// backupRetention is a grandfather-father-son retention policy: the newest
+// archive of each of the last Daily days, Weekly ISO weeks and Monthly months
+// is kept. The newest archive overall is always kept.
+type backupRetention struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+type backupArchive struct {
+	Name string
+	At   time.Time
+}
+
+func defaultBackupRetention() backupRetention {
+	return backupRetention{Daily: defaultKeepDaily, Weekly: defaultKeepWeekly, Monthly: defaultKeepMonthly}
+}
+
+func (s backupSchedule) retention() backupRetention {
+	return backupRetention{Daily: s.KeepDaily, Weekly: s.KeepWeekly, Monthly: s.KeepMonthly}
+}
+
+// backupArchiveName returns the exports/ filename for a scheduled backup.
+// Backups live next to manual exports so they show up in ListExports and can
+// be downloaded or deleted through the same endpoints.
+func backupArchiveName(site string, at time.Time) string {
+	return fmt.Sprintf("%s%s-%s.tar.gz", backupArchivePrefix, site, at.Format(backupTimeLayout))
+}
+
+// parseBackupArchiveName extracts the timestamp from a backup filename for site.
+func parseBackupArchiveName(site, filename string) (time.Time, bool) {
+	prefix := backupArchivePrefix + site + "-"
+	if !strings.HasPrefix(filename, prefix) || !strings.HasSuffix(filename, ".tar.gz") {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(filename, prefix), ".tar.gz")
+	at, err := time.ParseInLocation(backupTimeLayout, stamp, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// siteBackupArchives lists the backup archives for site in the exports directory.
+func siteBackupArchives(site string) ([]backupArchive, error) {
+	entries, err := os.ReadDir(exportsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var archives []backupArchive
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if at, ok := parseBackupArchiveName(site, e.Name()); ok {
+			archives = append(archives, backupArchive{Name: e.Name(), At: at})
+		}
+	}
+	return archives, nil
+}
+
+// selectGFSArchives splits archives into the ones retained by policy and the
+// ones that can be pruned. Both slices are ordered newest first.
+func selectGFSArchives(archives []backupArchive, policy backupRetention) (keep, prune []backupArchive) {
+	sorted := append([]backupArchive(nil), archives...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].At.After(sorted[j].At) })
+
+	retained := map[string]bool{}
+	bucket := func(limit int, key func(time.Time) string) {
+		seen := map[string]bool{}
+		for _, a := range sorted {
+			if len(seen) >= limit {
+				return
+			}
+			k := key(a.At)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			retained[a.Name] = true
+		}
+	}
+	bucket(policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") })
+	bucket(policy.Weekly, func(t time.Time) string {
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	})
+	bucket(policy.Monthly, func(t time.Time) string { return t.Format("2006-01") })
+	if len(sorted) > 0 {
+		retained[sorted[0].Name] = true
+	}
+
+	for _, a := range sorted {
+		if retained[a.Name] {
+			keep = append(keep, a)
+		} else {
+			prune = append(prune, a)
+		}
+	}
+	return keep, prune
+}
+
+// pruneSiteBackups deletes backup archives for site that fall outside policy
+// and returns the names that were removed.
+func pruneSiteBackups(site string, policy backupRetention) ([]string, error) {
+	archives, err := siteBackupArchives(site)
+	if err != nil {
+		return nil, err
+	}
+	_, prune := selectGFSArchives(archives, policy)
+
+	var removed []string
+	for _, a := range prune {
+		if err := os.Remove(filepath.Join(exportsDir(), a.Name)); err != nil {
+			return removed, fmt.Errorf("remove %s: %w", a.Name, err)
+		}
+		removed = append(removed, a.Name)
+	}
+	if len(removed) > 0 {
+		if err := newGrengoService().MarkBackupsPruned(removed); err != nil {
+			warn("Cannot record pruned backups: %v", err)
+		}
+	}
+	return removed, nil
+}
+
+// CLI
+// cmdBackupSchedule creates or replaces the backup schedule for a client.
+func cmdBackupSchedule(name, cronExpr string, keepDaily, keepWeekly, keepMonthly int) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	if cronExpr == "" {
+		cronExpr = defaultBackupCron
+	}
+	sched, err := parseCron(cronExpr)
+	if err != nil {
+		die("Invalid cron expression: %v", err)
+	}
+	if keepDaily < 0 || keepWeekly < 0 || keepMonthly < 0 {
+		die("Retention counts must be zero or greater")
+	}
+
+	err = newGrengoService().UpsertBackupSchedule(backupSchedule{
+		SiteName:    name,
+		CronExpr:    sched.String(),
+		KeepDaily:   keepDaily,
+		KeepWeekly:  keepWeekly,
+		KeepMonthly: keepMonthly,
+		Enabled:     true,
+	})
+	if err != nil {
+		die("Cannot save backup schedule: %v", err)
+	}
+	log("Backup schedule for '%s' set to %q (keep %d daily, %d weekly, %d monthly)",
+		name, sched.String(), keepDaily, keepWeekly, keepMonthly)
+	if next := sched.Next(time.Now()); !next.IsZero() {
+		info("Next run: %s (runs while 'grengo api' is up)", next.Format(time.RFC1123))
+	}
+}
+
+// cmdBackupUnschedule removes the backup schedule for a client. Existing
+// archives are left in place.
+func cmdBackupUnschedule(name string) {
+	if err := newGrengoService().DeleteBackupSchedule(name); err != nil {
+		die("Cannot remove backup schedule: %v", err)
+	}
+	log("Backup schedule for '%s' removed (existing archives kept)", name)
+}
+
+// cmdBackupList prints every backup schedule with its last result.
+func cmdBackupList() {
+	schedules, err := newGrengoService().ListBackupSchedules()
+	if err != nil {
+		die("Cannot list backup schedules: %v", err)
+	}
+	if len(schedules) == 0 {
+		info("No backup schedules. Create one with: grengo backup schedule <name>")
+		return
+	}
+
+	fmt.Printf("%s%-20s %-16s %-12s %-20s %-10s %s%s\n", colorBold, "CLIENT", "CRON", "KEEP D/W/M", "LAST RUN", "STATUS", "ARCHIVES", colorReset)
+	for _, s := range schedules {
+		lastRun := "never"
+		if s.LastRunAt != nil {
+			lastRun = s.LastRunAt.Local().Format("2006-01-02 15:04")
+		}
+		status := s.LastStatus
+		if status == "" {
+			status = "-"
+		}
+		if !s.Enabled {
+			status = "paused"
+		}
+		archives, _ := siteBackupArchives(s.SiteName)
+		fmt.Printf("%-20s %-16s %-12s %-20s %-10s %d\n", s.SiteName, s.CronExpr,
+			fmt.Sprintf("%d/%d/%d", s.KeepDaily, s.KeepWeekly, s.KeepMonthly), lastRun, status, len(archives))
+	}
+}
+
+// cmdBackupRun takes a backup of a client immediately and applies its
+// retention policy (or the default one when it has no schedule).
+func cmdBackupRun(name string) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	ensureWritableDir(exportsDir())
+	archiveName := backupArchiveName(name, time.Now())
+	cmdExportClient(name, filepath.Join(exportsDir(), archiveName), archiveOptions{Target: defaultBackupTarget()})
+	cmdBackupPrune(name)
+}
+
+// cmdBackupPrune applies the client's retention policy to its backup archives.
+func cmdBackupPrune(name string) {
+	policy := defaultBackupRetention()
+	if sched, ok := findBackupSchedule(name); ok {
+		policy = sched.retention()
+	}
+	removed, err := pruneSiteBackups(name, policy)
+	for _, archive := range removed {
+		log("Pruned old backup: %s", archive)
+	}
+	if err != nil {
+		die("Prune failed: %v", err)
+	}
+	if len(removed) == 0 {
+		info("No backups to prune for '%s'", name)
+	}
+}
+
+func findBackupSchedule(name string) (backupSchedule, bool) {
+	schedules, err := newGrengoService().ListBackupSchedules()
+	if err != nil {
+		return backupSchedule{}, false
+	}
+	for _, s := range schedules {
+		if s.SiteName == name {
+			return s, true
+		}
+	}
+	return backupSchedule{}, false
+}
+
+// Scheduler
+var backupSchedulerFired = struct {
+	sync.Mutex
+	last map[int64]time.Time
+}{last: map[int64]time.Time{}}
+
+// runBackupSchedulerLoop fires due backup schedules. It runs inside the
+// grengo API process and checks the schedule table twice a minute; schedules
+// are evaluated in the node's local time zone.
+func runBackupSchedulerLoop() {
+	for {
+		runDueBackups(time.Now())
+		time.Sleep(backupSchedulerDelay)
+	}
+}
+
+func runDueBackups(now time.Time) {
+	minute := now.Truncate(time.Minute)
+	schedules, err := newGrengoService().ListBackupSchedules()
+	if err != nil {
+		return
+	}
+	for _, s := range schedules {
+		if !backupScheduleDue(s, minute) {
+			continue
+		}
+		backupSchedulerFired.Lock()
+		if backupSchedulerFired.last[s.ID].Equal(minute) {
+			backupSchedulerFired.Unlock()
+			continue
+		}
+		backupSchedulerFired.last[s.ID] = minute
+		backupSchedulerFired.Unlock()
+
+		if !clientExists(s.SiteName) {
+			BroadcastLog("WARN", logPrefix("backup", s.SiteName), "client no longer exists - skipping scheduled backup")
+			continue
+		}
+		startSiteBackup(s)
+	}
+}
+
+// backupScheduleDue reports whether s should fire in the given minute and has
+// not already run during it (guards against restarts within the same minute).
+func backupScheduleDue(s backupSchedule, minute time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	sched, err := parseCron(s.CronExpr)
+	if err != nil || !sched.Matches(minute) {
+		return false
+	}
+	return s.LastRunAt == nil || s.LastRunAt.Before(minute)
+}
+
+// startSiteBackup runs the export pipeline for a scheduled backup as a
+// background job, records the run, and prunes old archives afterwards.
+func startSiteBackup(s backupSchedule) string {
+	os.MkdirAll(exportsDir(), 0755)
+	jobID := fmt.Sprintf("job-backup-%d", time.Now().UnixNano())
+	archiveName := backupArchiveName(s.SiteName, time.Now())
+	outPath := filepath.Join(exportsDir(), archiveName)
+
+	j := &jobStatus{
+		ID:        jobID,
+		Type:      "backup-site",
+		Target:    s.SiteName,
+		Status:    "running",
+		CreatedAt: time.Now(),
+		filePath:  outPath,
+	}
+	jobsMu.Lock()
+	jobs[jobID] = j
+	broadcastJobStatus(j)
+	jobsMu.Unlock()
+
+	go func() {
+		prefix := logPrefix("backup", s.SiteName)
+		svc := newGrengoService()
+		runID, err := svc.StartBackupRun(s.ID, s.SiteName, jobID, archiveName)
+		if err != nil {
+			BroadcastLog("WARN", prefix, fmt.Sprintf("cannot record backup run: %v", err))
+		}
+
+		BroadcastLog("INFO", prefix, fmt.Sprintf("backing up %s => %s", s.SiteName, archiveName))
+		writer := NewLogWriter(prefix, "INFO")
+		result, err := services.NewCommandRunner(ProjectRoot()).RunSelfStream(writer, withDefaultTarget([]string{"export", s.SiteName, "-o", outPath})...)
+
+		jobsMu.Lock()
+		if err != nil || result.ExitCode != 0 {
+			j.Status = "failed"
+			if err != nil {
+				j.Error = fmt.Sprintf("backup failed: %v", err)
+			} else {
+				j.Error = fmt.Sprintf("backup failed with exit code %d", result.ExitCode)
+			}
+			os.Remove(outPath)
+		} else {
+			j.Status = "completed"
+			j.Message = archiveName
+		}
+		status, jobErr := j.Status, j.Error
+		jobsMu.Unlock()
+
+		if runID > 0 {
+			if err := svc.FinishBackupRun(runID, s.ID, status, jobErr); err != nil {
+				BroadcastLog("WARN", prefix, fmt.Sprintf("cannot record backup result: %v", err))
+			}
+		}
+
+		if status == "completed" {
+			removed, err := pruneSiteBackups(s.SiteName, s.retention())
+			for _, name := range removed {
+				BroadcastLog("INFO", prefix, fmt.Sprintf("pruned %s", name))
+			}
+			if err != nil {
+				BroadcastLog("WARN", prefix, fmt.Sprintf("prune failed: %v", err))
+			}
+		}
+
+		jobsMu.Lock()
+		defer jobsMu.Unlock()
+		if status == "failed" {
+			BroadcastLog("ERROR", prefix, jobErr)
+		} else {
+			BroadcastLog("INFO", prefix, "completed")
+		}
+		broadcastJobStatus(j)
+	}()
+	return jobID
+}
@@ -0,0 +1,743 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BackupTarget is a storage location for export archives. Archives are
+// addressed by a flat key (the archive file name).
+type BackupTarget interface {
+	// String returns the target spec without credentials.
+	String() string
+	// Upload copies the local file at src to key.
+	Upload(src, key string) error
+	// List returns every archive stored on the target, newest first.
+	List() ([]backupObject, error)
+	// Download copies key to the local file at dest.
+	Download(key, dest string) error
+}
+
+// backupObject describes one archive on a BackupTarget. The JSON shape
+// matches the entries returned by ListExports.
+type backupObject struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// parseBackupTarget resolves a target spec:
+//
+//	/abs/dir or file:///abs/dir       local directory
+//	s3://bucket[/prefix]              S3-compatible bucket (BACKUP_S3_* in root .env)
+//	sftp://[user@]host[:port]/dir     remote directory via the sftp binary
+func parseBackupTarget(spec string) (BackupTarget, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty backup target")
+	}
+	if !strings.Contains(spec, "://") {
+		return localTarget{dir: spec}, nil
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup target %q: %w", spec, err)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("file target needs a directory path")
+		}
+		return localTarget{dir: u.Path}, nil
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("s3 target needs a bucket name")
+		}
+		return newS3Target(u.Host, strings.Trim(u.Path, "/"), loadEnvMap(rootEnvFile()))
+	case "sftp":
+		if u.Host == "" {
+			return nil, fmt.Errorf("sftp target needs a host")
+		}
+		return sftpTarget{
+			user:     u.User.Username(),
+			host:     u.Hostname(),
+			port:     u.Port(),
+			dir:      strings.TrimSuffix(u.Path, "/"),
+			identity: envVal(rootEnvFile(), "BACKUP_SFTP_IDENTITY"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported backup target scheme %q (use file, s3 or sftp)", u.Scheme)
+	}
+}
+
+// defaultBackupTargetName names the BACKUP_TARGET entry in the root .env.
+const defaultBackupTargetName = "default"
+
+// configuredBackupTargets returns the target specs set in the root .env,
+// keyed by name: BACKUP_TARGET is "default" and BACKUP_TARGET_<NAME> is
+// "<name>" in lower case.
+func configuredBackupTargets() map[string]string {
+	targets := map[string]string{}
+	for key, value := range loadEnvMap(rootEnvFile()) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch {
+		case key == "BACKUP_TARGET":
+			targets[defaultBackupTargetName] = value
+		case strings.HasPrefix(key, "BACKUP_TARGET_"):
+			targets[strings.ToLower(strings.TrimPrefix(key, "BACKUP_TARGET_"))] = value
+		}
+	}
+	return targets
+}
+
+// defaultBackupTarget returns BACKUP_TARGET, or "" when exports stay local.
+func defaultBackupTarget() string {
+	return configuredBackupTargets()[defaultBackupTargetName]
+}
+
+// namedBackupTarget resolves a target name sent by an API caller. Callers
+// never pass a spec, so they cannot reach directories or buckets the
+// operator has not configured.
+func namedBackupTarget(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	spec, ok := configuredBackupTargets()[name]
+	if !ok {
+		return "", fmt.Errorf("unknown backup target %q - configure it in %s", name, rootEnvFile())
+	}
+	if _, err := parseBackupTarget(spec); err != nil {
+		return "", err
+	}
+	return spec, nil
+}
+
+// withDefaultTarget appends --target BACKUP_TARGET to export arguments so
+// exports started by the API or a schedule upload like CLI ones do.
+func withDefaultTarget(args []string) []string {
+	if spec := defaultBackupTarget(); spec != "" {
+		return append(args, "--target", spec)
+	}
+	return args
+}
+
+// validateArchiveKey rejects keys that could escape the target directory.
+func validateArchiveKey(key string) error {
+	if key == "" || key != filepath.Base(key) || key == "." || key == ".." {
+		return fmt.Errorf("invalid archive name %q", key)
+	}
+	return nil
+}
+
+// sortBackupObjects orders objects newest first, breaking ties by name.
+func sortBackupObjects(objs []backupObject) {
+	sort.Slice(objs, func(i, j int) bool {
+		if !objs[i].CreatedAt.Equal(objs[j].CreatedAt) {
+			return objs[i].CreatedAt.After(objs[j].CreatedAt)
+		}
+		return objs[i].Name > objs[j].Name
+	})
+}
+
+// uploadToTarget pushes a finished archive, and its detached signature if
+// there is one, to spec and logs the result.
+func uploadToTarget(spec, src string) {
+	target, err := parseBackupTarget(spec)
+	if err != nil {
+		die("%v", err)
+	}
+	key := filepath.Base(src)
+	log("Uploading %s to %s…", key, target)
+	if err := target.Upload(src, key); err != nil {
+		die("Upload to %s failed: %v", target, err)
+	}
+	if _, err := os.Stat(src + ".sig"); err == nil {
+		if err := target.Upload(src+".sig", key+".sig"); err != nil {
+			die("Signature upload to %s failed: %v", target, err)
+		}
+	}
+	log("Uploaded => %s", target)
+}
+
+// fetchFromTarget downloads key (and key.sig when the target has one) from
+// spec into a temporary file and returns its path. The caller removes the
+// file with removeArchive.
+func fetchFromTarget(spec, key string) string {
+	target, err := parseBackupTarget(spec)
+	if err != nil {
+		die("%v", err)
+	}
+	if err := validateArchiveKey(key); err != nil {
+		die("%v", err)
+	}
+	tmp, err := os.CreateTemp("", "grengo-restore-*.tar.gz")
+	if err != nil {
+		die("Cannot create temp file: %v", err)
+	}
+	tmp.Close()
+	log("Downloading %s from %s…", key, target)
+	if err := target.Download(key, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+		die("Download from %s failed: %v", target, err)
+	}
+	if err := target.Download(key+".sig", tmp.Name()+".sig"); err != nil {
+		os.Remove(tmp.Name() + ".sig")
+	}
+	return tmp.Name()
+}
+
+// cmdTargetList prints the archives stored on a backup target.
+func cmdTargetList(spec string) {
+	target, err := parseBackupTarget(spec)
+	if err != nil {
+		die("%v", err)
+	}
+	objs, err := target.List()
+	if err != nil {
+		die("Cannot list %s: %v", target, err)
+	}
+	if len(objs) == 0 {
+		info("No archives on %s", target)
+		return
+	}
+	fmt.Printf("%-50s %12s  %s\n", "ARCHIVE", "SIZE", "CREATED")
+	for _, o := range objs {
+		fmt.Printf("%-50s %12d  %s\n", o.Name, o.Size, o.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+}
+
+// Local directory
+// localTarget stores archives in a directory on this host, typically a
+// mounted network share.
+type localTarget struct {
+	dir string
+}
+
+func (t localTarget) String() string { return "file://" + t.dir }
+
+func (t localTarget) Upload(src, key string) error {
+	if err := validateArchiveKey(key); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return err
+	}
+	dest := filepath.Join(t.dir, key)
+	if same, _ := sameFile(src, dest); same {
+		return nil
+	}
+	return copyFile(src, dest)
+}
+
+func (t localTarget) List() ([]backupObject, error) {
+	entries, err := os.ReadDir(t.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var objs []backupObject
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".tar.gz") {
+			continue
+		}
+		if fi, err := e.Info(); err == nil {
+			objs = append(objs, backupObject{Name: e.Name(), Size: fi.Size(), CreatedAt: fi.ModTime()})
+		}
+	}
+	sortBackupObjects(objs)
+	return objs, nil
+}
+
+func (t localTarget) Download(key, dest string) error {
+	if err := validateArchiveKey(key); err != nil {
+		return err
+	}
+	return copyFile(filepath.Join(t.dir, key), dest)
+}
+
+func sameFile(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(ai, bi), nil
+}
+
+// copyFile copies src to dest through a temporary file so a failed copy
+// never leaves a truncated archive behind.
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".grengo-copy-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// S3-compatible object storage
+// s3Target speaks the S3 REST API with path-style addressing and SigV4
+// signing, which works against AWS as well as MinIO and other compatibles.
+// Uploads are a single PUT, so archives are limited to 5 GiB.
+type s3Target struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	prefix    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func newS3Target(bucket, prefix string, env map[string]string) (*s3Target, error) {
+	region := env["BACKUP_S3_REGION"]
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := env["BACKUP_S3_ENDPOINT"]
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid BACKUP_S3_ENDPOINT %q", endpoint)
+	}
+	if env["BACKUP_S3_ACCESS_KEY"] == "" || env["BACKUP_S3_SECRET_KEY"] == "" {
+		return nil, fmt.Errorf("BACKUP_S3_ACCESS_KEY and BACKUP_S3_SECRET_KEY must be set in %s", rootEnvFile())
+	}
+	return &s3Target{
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		prefix:    prefix,
+		accessKey: env["BACKUP_S3_ACCESS_KEY"],
+		secretKey: env["BACKUP_S3_SECRET_KEY"],
+		client:    &http.Client{Timeout: 30 * time.Minute},
+		now:       time.Now,
+	}, nil
+}
+
+func (t *s3Target) String() string {
+	if t.prefix == "" {
+		return "s3://" + t.bucket
+	}
+	return "s3://" + t.bucket + "/" + t.prefix
+}
+
+func (t *s3Target) objectKey(key string) string {
+	if t.prefix == "" {
+		return key
+	}
+	return t.prefix + "/" + key
+}
+
+func (t *s3Target) Upload(src, key string) error {
+	if err := validateArchiveKey(key); err != nil {
+		return err
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	req, err := t.newRequest(http.MethodPut, t.objectKey(key), nil, f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = fi.Size()
+	req.Header.Set("Content-Type", "application/gzip")
+	resp, err := t.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (t *s3Target) List() ([]backupObject, error) {
+	prefix := ""
+	if t.prefix != "" {
+		prefix = t.prefix + "/"
+	}
+	var objs []backupObject
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		req, err := t.newRequest(http.MethodGet, "", q, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.do(req)
+		if err != nil {
+			return nil, err
+		}
+		var res s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode list response: %w", err)
+		}
+		for _, c := range res.Contents {
+			name := strings.TrimPrefix(c.Key, prefix)
+			if name == "" || strings.Contains(name, "/") || !strings.HasSuffix(name, ".tar.gz") {
+				continue
+			}
+			objs = append(objs, backupObject{Name: name, Size: c.Size, CreatedAt: c.LastModified})
+		}
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			break
+		}
+		token = res.NextContinuationToken
+	}
+	sortBackupObjects(objs)
+	return objs, nil
+}
+
+func (t *s3Target) Download(key, dest string) error {
+	if err := validateArchiveKey(key); err != nil {
+		return err
+	}
+	req, err := t.newRequest(http.MethodGet, t.objectKey(key), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// newRequest builds a signed path-style request for bucket/objectKey.
+// Payloads are sent as UNSIGNED-PAYLOAD so archives stream from disk.
+func (t *s3Target) newRequest(method, objectKey string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := *t.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + t.bucket
+	if objectKey != "" {
+		u.Path += "/" + objectKey
+	}
+	u.RawPath = s3EscapePath(u.Path)
+	u.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	t.sign(req, "UNSIGNED-PAYLOAD")
+	return req, nil
+}
+
+func (t *s3Target) do(req *http.Request) (*http.Response, error) {
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req.
+func (t *s3Target) sign(req *http.Request, payloadHash string) {
+	now := t.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + t.region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	key := hmacSHA256([]byte("AWS4"+t.secretKey), day)
+	key = hmacSHA256(key, t.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.accessKey, scope, strings.Join(signed, ";"), signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Escape percent-encodes everything except the SigV4 unreserved set.
+func s3Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func s3EscapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = s3Escape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+func s3CanonicalQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// SFTP
+// sftpTarget drives the OpenSSH sftp client in batch mode, so it uses the
+// operator's existing SSH keys and known_hosts. BACKUP_SFTP_IDENTITY in the
+// root .env selects a specific private key.
+type sftpTarget struct {
+	user     string
+	host     string
+	port     string
+	dir      string
+	identity string
+}
+
+func (t sftpTarget) String() string {
+	host := t.host
+	if t.user != "" {
+		host = t.user + "@" + host
+	}
+	if t.port != "" {
+		host += ":" + t.port
+	}
+	return "sftp://" + host + t.dir
+}
+
+func (t sftpTarget) remotePath(key string) string {
+	if t.dir == "" {
+		return key
+	}
+	return path.Join(t.dir, key)
+}
+
+// sftpQuote wraps an argument in double quotes for an sftp batch line.
+func sftpQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (t sftpTarget) batch(commands ...string) (string, error) {
+	args := []string{"-b", "-", "-o", "BatchMode=yes"}
+	if t.port != "" {
+		args = append(args, "-P", t.port)
+	}
+	if t.identity != "" {
+		args = append(args, "-i", t.identity)
+	}
+	dest := t.host
+	if t.user != "" {
+		dest = t.user + "@" + t.host
+	}
+	args = append(args, dest)
+
+	cmd := exec.Command("sftp", args...)
+	cmd.Stdin = strings.NewReader(strings.Join(commands, "\n") + "\n")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("sftp: %v: %s", err, strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
+
+func (t sftpTarget) Upload(src, key string) error {
+	if err := validateArchiveKey(key); err != nil {
+		return err
+	}
+	// Upload under a temporary name and rename, so readers never see a
+	// partial archive. The leading "-" lets mkdir fail if the dir exists.
+	tmp := t.remotePath("." + key + ".part")
+	cmds := []string{}
+	if t.dir != "" {
+		cmds = append(cmds, "-mkdir "+sftpQuote(t.dir))
+	}
+	cmds = append(cmds,
+		"put "+sftpQuote(src)+" "+sftpQuote(tmp),
+		"-rm "+sftpQuote(t.remotePath(key)),
+		"rename "+sftpQuote(tmp)+" "+sftpQuote(t.remotePath(key)),
+	)
+	_, err := t.batch(cmds...)
+	return err
+}
+
+func (t sftpTarget) List() ([]backupObject, error) {
+	dir := t.dir
+	if dir == "" {
+		dir = "."
+	}
+	out, err := t.batch("ls -ln " + sftpQuote(dir))
+	if err != nil {
+		return nil, err
+	}
+	objs := parseSFTPListing(out, time.Now())
+	sortBackupObjects(objs)
+	return objs, nil
+}
+
+func (t sftpTarget) Download(key, dest string) error {
+	if err := validateArchiveKey(key); err != nil {
+		return err
+	}
+	_, err := t.batch("get " + sftpQuote(t.remotePath(key)) + " " + sftpQuote(dest))
+	return err
+}
+
+// parseSFTPListing parses `ls -ln` output from sftp batch mode, e.g.
+// "-rw-r--r-- 1 1000 1000 5120 Mar 4 02:30 /backups/a.tar.gz". Recent entries
+// show a time instead of a year, so now supplies the year for those.
+func parseSFTPListing(out string, now time.Time) []backupObject {
+	var objs []backupObject
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 9 || !strings.HasPrefix(fields[0], "-") {
+			continue
+		}
+		name := path.Base(strings.Join(fields[8:], " "))
+		if !strings.HasSuffix(name, ".tar.gz") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			continue
+		}
+		objs = append(objs, backupObject{Name: name, Size: size, CreatedAt: parseLsTime(fields[5], fields[6], fields[7], now)})
+	}
+	return objs
+}
+
+func parseLsTime(month, day, clockOrYear string, now time.Time) time.Time {
+	if strings.Contains(clockOrYear, ":") {
+		t, err := time.ParseInLocation("Jan 2 2006 15:04", fmt.Sprintf("%s %s %d %s", month, day, now.Year(), clockOrYear), time.Local)
+		if err != nil {
+			return time.Time{}
+		}
+		if t.After(now.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t
+	}
+	t, err := time.ParseInLocation("Jan 2 2006", fmt.Sprintf("%s %s %s", month, day, clockOrYear), time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronMatches(t *testing.T) {
+	tests := []struct {
+		expr  string
+		at    time.Time
+		match bool
+	}{
+		{expr: "30 2 * * *", at: time.Date(2026, 3, 4, 2, 30, 0, 0, time.UTC), match: true},
+		{expr: "30 2 * * *", at: time.Date(2026, 3, 4, 2, 31, 0, 0, time.UTC), match: false},
+		{expr: "*/15 * * * *", at: time.Date(2026, 3, 4, 9, 45, 0, 0, time.UTC), match: true},
+		{expr: "0 3 * * 1-5", at: time.Date(2026, 3, 7, 3, 0, 0, 0, time.UTC), match: false}, // Saturday
+		{expr: "0 3 * * 7", at: time.Date(2026, 3, 8, 3, 0, 0, 0, time.UTC), match: true},    // Sunday alias
+		{expr: "0 3 1 * 0", at: time.Date(2026, 3, 8, 3, 0, 0, 0, time.UTC), match: true},    // day OR weekday
+		{expr: "@monthly", at: time.Date(2026, 4, 1, 3, 0, 0, 0, time.UTC), match: true},
+	}
+	for _, tt := range tests {
+		sched, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q) error: %v", tt.expr, err)
+		}
+		if got := sched.Matches(tt.at); got != tt.match {
+			t.Fatalf("parseCron(%q).Matches(%s) = %v, want %v", tt.expr, tt.at, got, tt.match)
+		}
+	}
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a b c d e"} {
+		if _, err := parseCron(bad); err == nil {
+			t.Fatalf("parseCron(%q) returned nil error", bad)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	sched, err := parseCron("0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 3, 5, 3, 0, 0, 0, time.UTC)
+	if got := sched.Next(from); !got.Equal(want) {
+		t.Fatalf("Next(%s) = %s, want %s", from, got, want)
+	}
+}
+
+func TestBackupArchiveNameRoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 4, 2, 30, 5, 0, time.Local)
+	name := backupArchiveName("my-site", at)
+	got, ok := parseBackupArchiveName("my-site", name)
+	if !ok || !got.Equal(at) {
+		t.Fatalf("parseBackupArchiveName(%q) = %s, %v", name, got, ok)
+	}
+	if _, ok := parseBackupArchiveName("my", name); ok {
+		t.Fatalf("archive %q must not match a site whose name is a prefix", name)
+	}
+}
+
+func TestSelectGFSArchives(t *testing.T) {
+	start := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	var archives []backupArchive
+	// 90 nightly backups plus a second backup on the newest day.
+	for i := 0; i < 90; i++ {
+		at := start.AddDate(0, 0, i)
+		archives = append(archives, backupArchive{Name: at.Format("20060102-150405"), At: at})
+	}
+	newest := start.AddDate(0, 0, 89).Add(6 * time.Hour)
+	archives = append(archives, backupArchive{Name: newest.Format("20060102-150405"), At: newest})
+
+	keep, prune := selectGFSArchives(archives, backupRetention{Daily: 7, Weekly: 4, Monthly: 3})
+	if len(keep)+len(prune) != len(archives) {
+		t.Fatalf("keep+prune = %d, want %d", len(keep)+len(prune), len(archives))
+	}
+	if keep[0].Name != newest.Format("20060102-150405") {
+		t.Fatalf("newest archive must be kept first, got %s", keep[0].Name)
+	}
+
+	kept := map[string]bool{}
+	for _, a := range keep {
+		kept[a.Name] = true
+	}
+	// The older backup on the newest day is superseded by the later one.
+	if kept[start.AddDate(0, 0, 89).Format("20060102-150405")] {
+		t.Fatal("same-day older archive should be pruned")
+	}
+	// Seven distinct days (Mar 25-31), the newest of the two ISO weeks not
+	// already covered (Mar 22, Mar 15) and of February and January.
+	if len(keep) != 11 {
+		t.Fatalf("kept %d archives, want 11: %v", len(keep), keep)
+	}
+	// January's newest backup is the monthly representative.
+	if !kept[time.Date(2026, 1, 31, 3, 0, 0, 0, time.UTC).Format("20060102-150405")] {
+		t.Fatal("monthly representative for January was pruned")
+	}
+
+	keep, _ = selectGFSArchives(archives, backupRetention{})
+	if len(keep) != 1 {
+		t.Fatalf("zero retention must still keep the newest archive, kept %d", len(keep))
+	}
+}
+
+func TestBackupScheduleDue(t *testing.T) {
+	minute := time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)
+	s := backupSchedule{ID: 1, SiteName: "mysite", CronExpr: "0 3 * * *", Enabled: true}
+	if !backupScheduleDue(s, minute) {
+		t.Fatal("schedule should be due")
+	}
+	s.LastRunAt = &minute
+	if backupScheduleDue(s, minute) {
+		t.Fatal("schedule already ran this minute")
+	}
+	s.LastRunAt = nil
+	s.Enabled = false
+	if backupScheduleDue(s, minute) {
+		t.Fatal("disabled schedule must not fire")
+	}
+}
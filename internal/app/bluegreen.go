@@ -0,0 +1,159 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultDrainSeconds is how long a replaced backend keeps running after
+	// nginx stops routing new requests to it.
+	defaultDrainSeconds = 10
+
+	// blueGreenHealthTimeout bounds how long a new backend may take to report
+	// healthy before the update is rolled back.
+	blueGreenHealthTimeout = 120
+)
+
+// cmdUpdateBlueGreen replaces a client's backend without dropping traffic.
+//
+// The new release first runs as a shadow container on a spare port. Once it
+// is healthy nginx is pointed at it and the old container is drained and
+// recreated from compose.yml on the client's own port. When that is healthy
+// too, nginx is pointed back and the shadow is drained and removed, leaving
+// the client exactly as 'grengo start' would. If a new container never turns
+// healthy, traffic stays on (or returns to) the last healthy one.
+func cmdUpdateBlueGreen(name string, drain int) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	if !clientEnabled(name) {
+		die("Client '%s' is disabled – enable it first", name)
+	}
+	if !clientRunning(name) {
+		die("Client '%s' is not running – use 'grengo start %s'", name, name)
+	}
+	if drain < 0 {
+		drain = defaultDrainSeconds
+	}
+	livePort := envVal(clientEnvFile(name), "PORT")
+
+	log("Building frontend first to ensure valid state...")
+	distDir := buildFrontend()
+
+	ensureNetwork()
+	ensureImage()
+	pinned := releaseRollbackPin(name)
+
+	spare := nextPort()
+	shadow := shadowContainer(name)
+	log("Starting new release of %s on spare port %d…", name, spare)
+	if err := shadowCompose(name, spare, "up", "-d", "--force-recreate"); err != nil {
+		removeShadow(name, spare)
+		restoreRollbackPin(name, pinned)
+		die("Failed to start %s: %v - %s is unchanged", shadow, err, name)
+	}
+	if err := waitHealthy(shadow, blueGreenHealthTimeout); err != nil {
+		removeShadow(name, spare)
+		restoreRollbackPin(name, pinned)
+		die("%v - rolled back, %s still serves on port %s", err, name, livePort)
+	}
+	shipFrontendToContainer(shadow, distDir)
+
+	setUpstreamPort(name, spare)
+	drainBackend(name+"-backend", drain)
+
+	log("Recreating %s on port %s…", name, livePort)
+	err := dockerCompose(clientComposeFile(name), "up", "-d", "--force-recreate")
+	if err == nil {
+		err = waitHealthy(name+"-backend", blueGreenHealthTimeout)
+	}
+	if err != nil {
+		die("%v - traffic stays on %s (port %d); fix the backend and rerun 'grengo update --blue-green %s'", err, shadow, spare, name)
+	}
+	shipFrontendToContainer(name+"-backend", distDir)
+
+	setUpstreamPort(name, 0)
+	drainBackend(shadow, drain)
+	removeShadow(name, spare)
+	log("%s updated without downtime", name)
+	recordRelease(name, cliTrigger("update --blue-green"), distDir, "")
+}
+
+// shadowContainer is the container name of a client's temporary backend.
+func shadowContainer(name string) string {
+	return name + "-backend-next"
+}
+
+// shadowCompose runs docker compose for the client's shadow backend. It uses
+// the client's own compose.yml under a separate project, with PORT pointed at
+// the spare port both for interpolation and inside the container.
+func shadowCompose(name string, port int, args ...string) error {
+	override := filepath.Join(clientDir(name), ".compose.next.yml")
+	content := fmt.Sprintf("services:\n  backend:\n    container_name: %s\n    environment:\n      PORT: \"%d\"\n", shadowContainer(name), port)
+	if err := os.WriteFile(override, []byte(content), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(override)
+
+	cmdArgs := append([]string{"compose", "-p", name + "-next", "-f", clientComposeFile(name), "-f", override}, args...)
+	cmd := exec.Command("docker", cmdArgs...)
+	cmd.Env = append(os.Environ(), "PORT="+strconv.Itoa(port))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// removeShadow stops and removes a client's shadow backend, if any.
+func removeShadow(name string, port int) {
+	if err := shadowCompose(name, port, "down"); err != nil {
+		warn("Could not remove %s: %v", shadowContainer(name), err)
+	}
+}
+
+// drainBackend gives in-flight requests on container time to finish after
+// nginx has stopped routing to it.
+func drainBackend(container string, seconds int) {
+	if seconds <= 0 {
+		return
+	}
+	info("Draining %s for %ds…", container, seconds)
+	time.Sleep(time.Duration(seconds) * time.Second)
+}
+
+// upstreamPortFile overrides the port nginx routes a client to while a
+// blue/green update is in progress.
+func upstreamPortFile(name string) string {
+	return filepath.Join(clientDir(name), ".upstream-port")
+}
+
+// upstreamPort returns the port nginx should route the client to: the
+// override left by a blue/green update, or "" to use PORT.
+func upstreamPort(name string) string {
+	data, err := os.ReadFile(upstreamPortFile(name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// setUpstreamPort points nginx at port (0 restores the client's own PORT),
+// then regenerates and reloads the config.
+func setUpstreamPort(name string, port int) {
+	if port == 0 {
+		os.Remove(upstreamPortFile(name))
+		info("Routing %s back to port %s", name, envVal(clientEnvFile(name), "PORT"))
+	} else {
+		if err := os.WriteFile(upstreamPortFile(name), []byte(strconv.Itoa(port)+"\n"), 0644); err != nil {
+			die("Cannot write %s: %v", upstreamPortFile(name), err)
+		}
+		info("Routing %s to port %d", name, port)
+	}
+	generateNginxConfig()
+	reloadNginxIfRunning()
+}
@@ -49,8 +49,9 @@ func buildFrontend() string {
 	return distDir
 }
 
-// cmdRebuildFrontend builds the SPA once and hot-copies it into running backend containers.
-func cmdRebuildFrontend(target string) {
+// cmdRebuildFrontend builds the SPA once and hot-copies it into running backend
+// containers, recording a release for each with the given trigger.
+func cmdRebuildFrontend(target, trigger string) {
 	if target == "" {
 		target = "all"
 	}
@@ -61,16 +62,36 @@ func cmdRebuildFrontend(target string) {
 	if len(targets) == 0 {
 		die("No running backend containers found to update")
 	}
+	shipFrontendToClients(targets, distDir, trigger)
+}
 
+// shipFrontendToClients copies a built dist into each client's backend and
+// records a release for it.
+func shipFrontendToClients(targets []string, distDir, trigger string) {
 	for _, name := range targets {
 		shipFrontendDist(name, distDir)
+		recordRelease(name, trigger, distDir, "")
 	}
 	log("Frontend shipped to %d backend(s) without restarting containers", len(targets))
 }
 
-func cmdShipFrontend() {
+// cmdShipFrontend rebuilds the frontend and ships it to the named clients, or
+// to every running client when names is empty. trigger is recorded in the
+// release ledger and defaults to the invoking CLI command.
+func cmdShipFrontend(names []string, trigger string) {
+	if trigger == "" {
+		trigger = cliTrigger("ship frontend")
+	}
 	log("Rebuilding and shipping frontend...")
-	cmdRebuildFrontend("all")
+	if len(names) == 0 {
+		cmdRebuildFrontend("all", trigger)
+		return
+	}
+	var targets []string
+	for _, name := range names {
+		targets = append(targets, frontendRebuildTargets(name)...)
+	}
+	shipFrontendToClients(targets, buildFrontend(), trigger)
 }
 
 func frontendRebuildTargets(target string) []string {
@@ -116,7 +137,11 @@ func frontendRebuildTargets(target string) []string {
 }
 
 func shipFrontendDist(name, distDir string) {
-	container := name + "-backend"
+	shipFrontendToContainer(name+"-backend", distDir)
+}
+
+// shipFrontendToContainer copies the built frontend into a backend container.
+func shipFrontendToContainer(container, distDir string) {
 	dest := "/app/frontend/dist"
 
 	log("Shipping frontend assets to %s …", container)
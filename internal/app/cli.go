@@ -4,43 +4,118 @@ import "github.com/skaia/grengo/internal/cli"
 
 func CLICommands() cli.Commands {
 	return cli.Commands{
-		DefaultAPIPort: DefaultAPIPort,
-		Die:            die,
-		New:            cmdNew,
-		List:           cmdList,
-		Enable:         cmdEnable,
-		Disable:        cmdDisable,
-		Start:          cmdStart,
-		Stop:           cmdStop,
-		Remove:         cmdRemove,
-		Build:          cmdBuild,
-		GlobalStart:    cmdGlobalStart,
-		GlobalStop:     cmdGlobalStop,
-		GlobalRestart:  cmdGlobalRestart,
-		ShipFrontend:   cmdShipFrontend,
-		Dev:            cmdDev,
-		ComposeUp:      cmdComposeUp,
-		ComposeDown:    cmdComposeDown,
-		LiveKit:        cmdLiveKit,
-		NginxReload:    cmdNginxReload,
-		DBInit:         cmdDBInit,
-		Migrate:        cmdMigrate,
-		MigrateAll:     cmdMigrateAll,
-		Logs:           cmdLogs,
-		UpdateClient:   cmdUpdateClient,
-		UpdateAll:      cmdUpdateAll,
-		ExportClient:   cmdExportClient,
-		ImportClient:   cmdImportClient,
-		ExportNode:     cmdExportNode,
-		ImportNode:     cmdImportNode,
-		WipeAll:        cmdWipeAll,
-		APIStart:       cmdAPIStart,
-		APIStop:        cmdAPIStop,
-		APIStatus:      cmdAPIStatus,
-		PasscodeSet:    cmdPasscodeSet,
-		PasscodeVerify: cmdPasscodeVerify,
-		PasscodeClear:  cmdPasscodeClear,
-		PasscodeStatus: cmdPasscodeStatus,
+		DefaultAPIPort:   DefaultAPIPort,
+		Die:              die,
+		New:              cmdNew,
+		List:             cmdList,
+		Enable:           cmdEnable,
+		Disable:          cmdDisable,
+		Start:            cmdStart,
+		Stop:             cmdStop,
+		Remove:           cmdRemove,
+		Build:            cmdBuild,
+		GlobalStart:      cmdGlobalStart,
+		GlobalStop:       cmdGlobalStop,
+		GlobalRestart:    cmdGlobalRestart,
+		ShipFrontend:     cmdShipFrontend,
+		Dev:              cmdDev,
+		ComposeUp:        cmdComposeUp,
+		ComposeDown:      cmdComposeDown,
+		LiveKit:          cmdLiveKit,
+		NginxReload:      cmdNginxReload,
+		DBInit:           cmdDBInit,
+		Migrate:          cmdMigrate,
+		MigrateAll:       cmdMigrateAll,
+		Logs:             cmdLogs,
+		LogsRetention:    cmdLogsRetention,
+		UpdateClient:     cmdUpdateClient,
+		UpdateAll:        cmdUpdateAll,
+		UpdateBlueGreen:  cmdUpdateBlueGreen,
+		Rollback:         cmdRollback,
+		ReleaseList:      cmdReleaseList,
+		Plan:             cmdPlan,
+		Apply:            cmdApply,
+		TargetList:       cmdTargetList,
+		KeysInit:         cmdKeysInit,
+		KeysShow:         cmdKeysShow,
+		KeysTrust:        cmdKeysTrust,
+		SnapshotList:     cmdSnapshotList,
+		SnapshotRestore:  cmdSnapshotRestore,
+		WipeAll:          cmdWipeAll,
+		APIStart:         cmdAPIStart,
+		APIStop:          cmdAPIStop,
+		APIStatus:        cmdAPIStatus,
+		PasscodeSet:      cmdPasscodeSet,
+		PasscodeVerify:   cmdPasscodeVerify,
+		PasscodeClear:    cmdPasscodeClear,
+		PasscodeStatus:   cmdPasscodeStatus,
+		BackupSchedule:   cmdBackupSchedule,
+		BackupUnschedule: cmdBackupUnschedule,
+		BackupList:       cmdBackupList,
+		BackupRun:        cmdBackupRun,
+		BackupPrune:      cmdBackupPrune,
+		WebhookLog:       cmdWebhookLog,
+		WebhookSecret:    cmdWebhookSecret,
+		WebhookTrack:     cmdWebhookTrack,
+		APIKeyList:       cmdAPIKeyList,
+		APIKeyRotate:     cmdAPIKeyRotate,
+		APIKeyRevoke:     cmdAPIKeyRevoke,
+		OperatorAdd:      cmdOperatorAdd,
+		OperatorList:     cmdOperatorList,
+		OperatorUpdate:   cmdOperatorUpdate,
+		QuotaShow:        cmdQuotaShow,
+		QuotaClear:       cmdQuotaClear,
+		QuotaApply:       cmdQuotaApply,
+		QuotaCheck:       cmdQuotaCheck,
+		AlertRules:       cmdAlertRules,
+		AlertList:        cmdAlertList,
+		AlertSilence:     cmdAlertSilence,
+		AlertSilences:    cmdAlertSilences,
+		AlertUnsilence:   cmdAlertUnsilence,
+		AlertTest:        cmdAlertTest,
+		TLSStatus:        cmdTLSStatus,
+		TLSIssue:         cmdTLSIssue,
+		TLSRenew:         cmdTLSRenew,
+		FleetInit:        cmdFleetInit,
+		FleetIssue:       cmdFleetIssue,
+		FleetJoin:        cmdFleetJoin,
+		FleetAdd:         cmdFleetAdd,
+		FleetRemove:      cmdFleetRemove,
+		FleetList:        cmdFleetList,
+		FleetSites:       cmdFleetSites,
+		FleetStats:       cmdFleetStats,
+		FleetStorage:     cmdFleetStorage,
+		FleetMove:        cmdFleetMove,
+		LogsQuery: func(opts cli.LogQueryOptions) {
+			cmdLogsQuery(opts.Clients, opts.Since, opts.Until, opts.Grep, opts.Level, opts.Limit)
+		},
+		AuditList: func(opts cli.AuditOptions) {
+			cmdAuditList(opts.Actor, opts.Command, opts.Target, opts.Result, opts.Since, opts.Until, opts.Limit)
+		},
+		AuditExport: func(opts cli.AuditOptions) {
+			cmdAuditExport(opts.Actor, opts.Command, opts.Target, opts.Result, opts.Since, opts.Until, opts.Output)
+		},
+		APIKeyCreate: func(name string, opts cli.APIKeyOptions) {
+			cmdAPIKeyCreate(name, int64(opts.User), opts.Expires, opts.Scopes, opts.Sites, opts.Rate)
+		},
+		QuotaSet: func(name string, opts cli.QuotaOptions) {
+			cmdQuotaSet(name, quotaOptions(opts))
+		},
+		ExportClient: func(name, outFile string, opts cli.ArchiveOptions) {
+			cmdExportClient(name, outFile, archiveOptionsFromCLI(opts))
+		},
+		ImportClient: func(archivePath, newName, newPort string, opts cli.ArchiveOptions) {
+			cmdImportClient(archivePath, newName, newPort, archiveOptionsFromCLI(opts))
+		},
+		ExportNode: func(outFile string, opts cli.ArchiveOptions) {
+			cmdExportNode(outFile, archiveOptionsFromCLI(opts))
+		},
+		ImportNode: func(archivePath string, opts cli.ArchiveOptions) {
+			cmdImportNode(archivePath, archiveOptionsFromCLI(opts))
+		},
+		Verify: func(archivePath string, opts cli.ArchiveOptions) {
+			cmdVerifyArchive(archivePath, archiveOptionsFromCLI(opts))
+		},
 		FrappeProvision: func(siteName, version string) {
 			cmdFrappeProvision(siteName, version)
 		},
@@ -49,3 +124,28 @@ func CLICommands() cli.Commands {
 		},
 	}
 }
+
+// archiveOptionsFromCLI resolves parsed export/import flags, reading the
+// passphrase file if one was given.
+func archiveOptionsFromCLI(o cli.ArchiveOptions) archiveOptions {
+	opts := archiveOptions{
+		Target:        o.Target,
+		Recipient:     o.Recipient,
+		IdentityFile:  o.Identity,
+		Sign:          o.Sign,
+		AllowUnsigned: o.AllowUnsigned,
+		Incremental:   o.Incremental,
+		Store:         o.Store,
+		DryRun:        o.DryRun,
+	}
+	if o.PassphraseFile != "" {
+		opts.Passphrase = readPassphraseFile(o.PassphraseFile)
+	}
+	if opts.Passphrase != "" && opts.Recipient != "" {
+		die("Use either --passphrase-file or --recipient, not both")
+	}
+	if opts.Incremental && (opts.Passphrase != "" || opts.Recipient != "" || opts.Sign || opts.Target != "") {
+		die("--incremental cannot be combined with encryption, signing or --target")
+	}
+	return opts
+}
@@ -34,6 +34,7 @@ func nginxDir() string    { return repo.New(ProjectRoot()).NginxDir() }
 func backendSrc() string  { return repo.New(ProjectRoot()).BackendSrc() }
 func composeFile() string { return repo.New(ProjectRoot()).ComposeFile() }
 func rootEnvFile() string { return repo.New(ProjectRoot()).RootEnvFile() }
+func exportsDir() string  { return filepath.Join(ProjectRoot(), "exports") }
 
 // ensureWritableDir creates dir (with parents) if it doesn't exist.
 // If it already exists but is not writable by the current user (common when
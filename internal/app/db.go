@@ -128,6 +128,7 @@ func cmdMigrateClient(name string, rebuild bool) {
 	log("Applying migrations to '%s'…", dbName)
 	runMigrations(dbName, env)
 	log("Migrate complete for '%s'", name)
+	recordRelease(name, cliTrigger("migrate"), "", backupFile)
 }
 
 func cmdMigrateGrengo() {
@@ -182,6 +183,7 @@ func cmdMigrateRebuild(name, dbName string, env SharedEnv) {
 	}
 
 	log("Rebuild-migrate complete for '%s'", name)
+	recordRelease(name, cliTrigger("migrate --rebuild"), "", backupFile)
 }
 
 // cmdMigrateAll runs migrate for every client on this node.
@@ -125,6 +125,14 @@ func ensureNetwork() {
 
 // waitForHealthy polls a container's health status until it reports healthy.
 func waitForHealthy(container string, timeout int) {
+	if err := waitHealthy(container, timeout); err != nil {
+		die("%v", err)
+	}
+}
+
+// waitHealthy is waitForHealthy without exiting: it returns an error once
+// timeout seconds pass.
+func waitHealthy(container string, timeout int) error {
 	if timeout <= 0 {
 		timeout = 60
 	}
@@ -132,11 +140,11 @@ func waitForHealthy(container string, timeout int) {
 	for i := 0; i < timeout; i++ {
 		status, err := dockerOutput("inspect", "--format", "{{.State.Health.Status}}", container)
 		if err == nil && status == "healthy" {
-			return
+			return nil
 		}
 		time.Sleep(1 * time.Second)
 	}
-	die("%s did not become healthy within %ds", container, timeout)
+	return fmt.Errorf("%s did not become healthy within %ds", container, timeout)
 }
 
 // pgRunning checks if the shared PostgreSQL container is running.
@@ -172,7 +180,9 @@ func dbExists(dbName string, env SharedEnv) bool {
 // reloadNginxIfRunning sends a reload signal to nginx if its container is up.
 func reloadNginxIfRunning() {
 	if containerRunning("skaia-nginx") {
-		if err := dockerRunSilent("exec", "skaia-nginx", "nginx", "-s", "reload"); err != nil {
+		err := dockerRunSilent("exec", "skaia-nginx", "nginx", "-s", "reload")
+		recordNginxReload(err)
+		if err != nil {
 			warn("nginx reload failed – you may need to restart it")
 		} else {
 			log("nginx reloaded")
@@ -527,20 +527,28 @@ func clientEnabled(name string) bool {
 
 // validateName checks that a client name is valid.
 func validateName(name string) {
+	if msg := nameError(name); msg != "" {
+		die("%s", msg)
+	}
+}
+
+// nameError returns why name is not a valid client name, or "" if it is.
+func nameError(name string) string {
 	if name == "" {
-		die("Name is required")
+		return "Name is required"
 	}
 	if len(name) > 32 {
-		die("Name must be ≤ 32 characters")
+		return "Name must be ≤ 32 characters"
 	}
 	if name[0] < 'a' || name[0] > 'z' {
-		die("Name must start with a lowercase letter")
+		return "Name must start with a lowercase letter"
 	}
 	for _, c := range name {
 		if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-') {
-			die("Name must contain only lowercase letters, numbers, hyphens")
+			return "Name must contain only lowercase letters, numbers, hyphens"
 		}
 	}
+	return ""
 }
 
 // clientEnvFile returns the path to a client's .env file.
@@ -0,0 +1,710 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/skaia/grpc/grengo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Fleet mode lets one grengo instance manage peer nodes. Every node in a
+// fleet holds a certificate issued by the fleet CA (created with 'grengo
+// fleet init' on the controlling node) and serves the regular gRPC API on a
+// second, mutual-TLS listener. Peers authenticate each other by certificate
+// instead of passcode.
+const (
+	fleetCAValidity   = 10 * 365 * 24 * time.Hour
+	fleetCertValidity = 2 * 365 * 24 * time.Hour
+	fleetCallTimeout  = 15 * time.Second
+
+	// fleetLocalNode names this node in fleet commands.
+	fleetLocalNode = "local"
+)
+
+func fleetDir() string          { return filepath.Join(archiveKeysDir(), "fleet") }
+func fleetCACertFile() string   { return filepath.Join(fleetDir(), "ca.pem") }
+func fleetCAKeyFile() string    { return filepath.Join(fleetDir(), "ca.key") }
+func fleetNodeCertFile() string { return filepath.Join(fleetDir(), "node.pem") }
+func fleetNodeKeyFile() string  { return filepath.Join(fleetDir(), "node.key") }
+func fleetPeersFile() string    { return filepath.Join(fleetDir(), "peers.json") }
+
+// fleetPort is the mutual-TLS listener peers connect to.
+func fleetPort() int {
+	if p, err := strconv.Atoi(envVal(rootEnvFile(), "GRENGO_FLEET_PORT")); err == nil && p > 0 {
+		return p
+	}
+	return DefaultAPIPort + 2
+}
+
+// fleetPeer is a registered peer node.
+type fleetPeer struct {
+	Name string `json:"name"`
+	Addr string `json:"addr"` // host:port of the peer's fleet listener
+}
+
+func loadFleetPeers() ([]fleetPeer, error) {
+	data, err := os.ReadFile(fleetPeersFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var peers []fleetPeer
+	if err := json.Unmarshal(data, &peers); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", fleetPeersFile(), err)
+	}
+	return peers, nil
+}
+
+func saveFleetPeers(peers []fleetPeer) error {
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Name < peers[j].Name })
+	data, err := json.MarshalIndent(peers, "", "  ")
+	if err != nil {
+		return err
+	}
+	ensureWritableDir(fleetDir())
+	return os.WriteFile(fleetPeersFile(), append(data, '\n'), 0600)
+}
+
+// findFleetPeer looks a peer up by name.
+func findFleetPeer(name string) (fleetPeer, error) {
+	peers, err := loadFleetPeers()
+	if err != nil {
+		return fleetPeer{}, err
+	}
+	for _, p := range peers {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return fleetPeer{}, fmt.Errorf("unknown fleet node %q (see 'grengo fleet list')", name)
+}
+
+// newECDSAKey generates the ECDSA P-256 key used for fleet and ACME
+// certificates and returns it with its PKCS8 PEM encoding.
+func newECDSAKey() (*ecdsa.PrivateKey, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+}
+
+// newFleetCA creates a self-signed fleet CA and returns its PEM cert and key.
+func newFleetCA() (certPEM, keyPEM []byte, err error) {
+	key, keyPEM, err := newECDSAKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "grengo fleet CA", Organization: []string{"grengo"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(fleetCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), keyPEM, nil
+}
+
+// issueFleetCert signs a node certificate usable both as TLS server and
+// client. hosts become DNS or IP SANs; loopback is always included so the
+// node can reach its own fleet listener.
+func issueFleetCert(caCertPEM, caKeyPEM []byte, node string, hosts []string) (certPEM, keyPEM []byte, err error) {
+	ca, err := tls.X509KeyPair(caCertPEM, caKeyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load fleet CA: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	key, keyPEM, err := newECDSAKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: node, Organization: []string{"grengo"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(fleetCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, h := range append([]string{"localhost", "127.0.0.1"}, hosts...) {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if h != "" {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, ca.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), keyPEM, nil
+}
+
+// fleetTLSConfig loads this node's certificate and the fleet CA. Servers
+// require and verify a client certificate from the same CA.
+func fleetTLSConfig(server bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(fleetNodeCertFile(), fleetNodeKeyFile())
+	if err != nil {
+		return nil, fmt.Errorf("fleet certificate not installed (grengo fleet init or fleet join): %w", err)
+	}
+	caPEM, err := os.ReadFile(fleetCACertFile())
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("invalid fleet CA certificate in %s", fleetCACertFile())
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS13}
+	if server {
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// fleetNodeName is the common name of this node's fleet certificate.
+func fleetNodeName() string {
+	cert, err := tls.LoadX509KeyPair(fleetNodeCertFile(), fleetNodeKeyFile())
+	if err == nil {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+			return leaf.Subject.CommonName
+		}
+	}
+	return fleetLocalNode
+}
+
+// serveFleet starts the mutual-TLS listener when this node has a fleet
+// certificate. It returns nil when fleet mode is not set up.
+func serveFleet() *grpc.Server {
+	if _, err := os.Stat(fleetNodeCertFile()); err != nil {
+		return nil
+	}
+	cfg, err := fleetTLSConfig(true)
+	if err != nil {
+		warn("Fleet listener disabled: %v", err)
+		return nil
+	}
+	addr := fmt.Sprintf("0.0.0.0:%d", fleetPort())
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		warn("Fleet listener disabled: cannot listen on %s: %v", addr, err)
+		return nil
+	}
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(cfg)),
+		grpc.ChainUnaryInterceptor(auditInterceptor, fleetAuthInterceptor, apiKeyInterceptor),
+		grpc.ChainStreamInterceptor(auditStreamInterceptor, fleetAuthStreamInterceptor, apiKeyStreamInterceptor),
+	)
+	pb.RegisterGrengoServiceServer(srv, &GrengoServer{})
+	go func() {
+		if err := srv.Serve(listener); err != nil {
+			warn("Fleet listener stopped: %v", err)
+		}
+	}()
+	info("Fleet peers served with mutual TLS on %s as %s", addr, fleetNodeName())
+	return srv
+}
+
+// fleetPeerMethods are the calls above viewer a peer makes without an
+// operator session: the steps of a client move.
+var fleetPeerMethods = map[string]bool{
+	pb.GrengoService_ExportSite_FullMethodName:   true,
+	pb.GrengoService_DownloadJob_FullMethodName:  true,
+	pb.GrengoService_UploadExport_FullMethodName: true,
+	pb.GrengoService_ImportSite_FullMethodName:   true,
+	pb.GrengoService_StartSite_FullMethodName:    true,
+	pb.GrengoService_DisableSite_FullMethodName:  true,
+}
+
+// authorizeFleetCall checks a call on the fleet listener. A call carrying
+// a session token or passcode is held to the operator roles like on the
+// API; a bare fleet certificate only reaches viewer methods and the move
+// steps, never Exec or other admin methods.
+func authorizeFleetCall(ctx context.Context, method string, decoded any) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if metadataValue(md, "authorization") != "" || metadataValue(md, "x-grengo-passcode") != "" {
+		return authorizeCall(ctx, method, decoded)
+	}
+	if methodRole(method, decoded) == roleViewer || fleetPeerMethods[method] {
+		return ctx, nil
+	}
+	return nil, status.Errorf(codes.PermissionDenied, "fleet peers may not call %s", method)
+}
+
+func fleetAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if passcodeExemptMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	ctx, err := authorizeFleetCall(ctx, info.FullMethod, decodeRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func fleetAuthStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if passcodeExemptMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	ctx, err := authorizeFleetCall(ss.Context(), info.FullMethod, nil)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// dialFleet opens a mutual-TLS connection to a peer.
+func dialFleet(addr string) (*grpc.ClientConn, pb.GrengoServiceClient, error) {
+	cfg, err := fleetTLSConfig(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, pb.NewGrengoServiceClient(conn), nil
+}
+
+// fleetNodeAddr resolves a node name for fleet commands. "local" (or this
+// node's own name) is reached through its own fleet listener.
+func fleetNodeAddr(name string) (string, error) {
+	if name == fleetLocalNode || name == fleetNodeName() {
+		return fmt.Sprintf("127.0.0.1:%d", fleetPort()), nil
+	}
+	p, err := findFleetPeer(name)
+	if err != nil {
+		return "", err
+	}
+	return p.Addr, nil
+}
+
+// fleetResult is one node's answer to a fleet-wide query.
+type fleetResult struct {
+	Node  string          `json:"node"`
+	Addr  string          `json:"addr,omitempty"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// fleetQuery runs a query on this node and every registered peer in
+// parallel. Unreachable peers are reported in the result, not as an error.
+func fleetQuery(ctx context.Context, local func() (string, error), remote func(context.Context, pb.GrengoServiceClient) (string, error)) ([]fleetResult, error) {
+	peers, err := loadFleetPeers()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]fleetResult, len(peers)+1)
+	results[0] = fleetResult{Node: fleetNodeName()}
+	if data, err := local(); err != nil {
+		results[0].Error = err.Error()
+	} else {
+		results[0].Data = json.RawMessage(data)
+	}
+
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		wg.Add(1)
+		go func(r *fleetResult, p fleetPeer) {
+			defer wg.Done()
+			r.Node, r.Addr = p.Name, p.Addr
+			conn, client, err := dialFleet(p.Addr)
+			if err != nil {
+				r.Error = err.Error()
+				return
+			}
+			defer conn.Close()
+			cctx, cancel := context.WithTimeout(ctx, fleetCallTimeout)
+			defer cancel()
+			data, err := remote(cctx, client)
+			if err != nil {
+				r.Error = err.Error()
+				return
+			}
+			r.Data = json.RawMessage(data)
+		}(&results[i+1], p)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+func fleetListSites(ctx context.Context) ([]fleetResult, error) {
+	return fleetQuery(ctx, func() (string, error) {
+		resp, err := (&GrengoServer{}).ListSites(ctx, &pb.ListSitesRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.SitesJson, nil
+	}, func(ctx context.Context, c pb.GrengoServiceClient) (string, error) {
+		resp, err := c.ListSites(ctx, &pb.ListSitesRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.SitesJson, nil
+	})
+}
+
+func fleetStats(ctx context.Context) ([]fleetResult, error) {
+	return fleetQuery(ctx, func() (string, error) {
+		b, err := json.Marshal(gatherStats())
+		return string(b), err
+	}, func(ctx context.Context, c pb.GrengoServiceClient) (string, error) {
+		resp, err := c.Stats(ctx, &pb.EmptyRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.StatsJson, nil
+	})
+}
+
+func fleetStorage(ctx context.Context) ([]fleetResult, error) {
+	return fleetQuery(ctx, func() (string, error) {
+		b, err := json.Marshal(gatherStorage())
+		return string(b), err
+	}, func(ctx context.Context, c pb.GrengoServiceClient) (string, error) {
+		resp, err := c.Storage(ctx, &pb.EmptyRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.StorageJson, nil
+	})
+}
+
+// cmdFleetInit creates the fleet CA and this node's certificate, making this
+// node the one that issues certificates to peers.
+func cmdFleetInit(node string, hosts []string) {
+	if _, err := os.Stat(fleetCAKeyFile()); err == nil {
+		die("Fleet CA already exists in %s", fleetDir())
+	}
+	if node == "" {
+		node, _ = os.Hostname()
+	}
+	if msg := nameError(node); msg != "" {
+		die("Invalid node name %q: %s (use --name)", node, msg)
+	}
+	caCert, caKey, err := newFleetCA()
+	if err != nil {
+		die("Cannot create fleet CA: %v", err)
+	}
+	cert, key, err := issueFleetCert(caCert, caKey, node, hosts)
+	if err != nil {
+		die("Cannot issue node certificate: %v", err)
+	}
+	ensureWritableDir(fleetDir())
+	os.Chmod(fleetDir(), 0700)
+	for file, data := range map[string][]byte{
+		fleetCACertFile():   caCert,
+		fleetCAKeyFile():    caKey,
+		fleetNodeCertFile(): cert,
+		fleetNodeKeyFile():  key,
+	} {
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			die("Cannot write %s: %v", file, err)
+		}
+	}
+	log("Fleet CA created; this node is %s", bold(node))
+	info("Restart the API to serve peers on port %d: grengo api stop && grengo api start", fleetPort())
+	info("Add a peer: grengo fleet issue <node> --host <address> -o <node>.pem, then on that node: grengo fleet join <node>.pem")
+}
+
+// cmdFleetIssue writes a bundle with the CA certificate and a new node
+// certificate and key for a peer. The bundle contains a private key and
+// must be copied to the peer over a trusted channel.
+func cmdFleetIssue(node string, hosts []string, outFile string) {
+	if msg := nameError(node); msg != "" {
+		die("Invalid node name %q: %s", node, msg)
+	}
+	if len(hosts) == 0 {
+		die("Usage: grengo fleet issue <node> --host <address> [--host <address>]… [-o <file>]")
+	}
+	caCert, err := os.ReadFile(fleetCACertFile())
+	if err != nil {
+		die("No fleet CA on this node - run 'grengo fleet init' first")
+	}
+	caKey, err := os.ReadFile(fleetCAKeyFile())
+	if err != nil {
+		die("This node holds no fleet CA key; issue certificates on the node that ran 'grengo fleet init'")
+	}
+	cert, key, err := issueFleetCert(caCert, caKey, node, hosts)
+	if err != nil {
+		die("Cannot issue certificate: %v", err)
+	}
+	if outFile == "" {
+		outFile = node + ".fleet.pem"
+	}
+	bundle := append(append(fleetBundleBlock("ca", caCert), fleetBundleBlock("node", cert)...), fleetBundleBlock("node", key)...)
+	if err := os.WriteFile(outFile, bundle, 0600); err != nil {
+		die("Cannot write %s: %v", outFile, err)
+	}
+	log("Fleet bundle for %s written to %s", bold(node), outFile)
+	info("On %s run: grengo fleet join %s", node, filepath.Base(outFile))
+	info("Then here:  grengo fleet add %s %s:%d", node, hosts[0], fleetPort())
+}
+
+// fleetBundleBlock re-encodes a PEM block with a Grengo-Role header.
+func fleetBundleBlock(role string, data []byte) []byte {
+	block, _ := pem.Decode(data)
+	block.Headers = map[string]string{"Grengo-Role": role}
+	return pem.EncodeToMemory(block)
+}
+
+// parseFleetBundle splits a bundle into the CA certificate and the node's
+// certificate and key, all PEM encoded.
+func parseFleetBundle(data []byte) (caCert, cert, key []byte, err error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		role := block.Headers["Grengo-Role"]
+		block.Headers = nil
+		encoded := pem.EncodeToMemory(block)
+		switch {
+		case role == "ca" && block.Type == "CERTIFICATE":
+			caCert = encoded
+		case role == "node" && block.Type == "CERTIFICATE":
+			cert = encoded
+		case role == "node" && block.Type == "PRIVATE KEY":
+			key = encoded
+		}
+	}
+	if caCert == nil || cert == nil || key == nil {
+		return nil, nil, nil, fmt.Errorf("not a grengo fleet bundle")
+	}
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return nil, nil, nil, fmt.Errorf("bundle certificate and key do not match: %w", err)
+	}
+	return caCert, cert, key, nil
+}
+
+// cmdFleetJoin installs a bundle issued by the fleet CA on this node.
+func cmdFleetJoin(bundlePath string) {
+	data, err := os.ReadFile(bundlePath)
+	if err != nil {
+		die("Cannot read %s: %v", bundlePath, err)
+	}
+	caCert, cert, key, err := parseFleetBundle(data)
+	if err != nil {
+		die("%v", err)
+	}
+	if _, err := os.Stat(fleetCAKeyFile()); err == nil {
+		die("This node holds the fleet CA; it cannot join another fleet")
+	}
+	ensureWritableDir(fleetDir())
+	os.Chmod(fleetDir(), 0700)
+	for file, data := range map[string][]byte{
+		fleetCACertFile():   caCert,
+		fleetNodeCertFile(): cert,
+		fleetNodeKeyFile():  key,
+	} {
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			die("Cannot write %s: %v", file, err)
+		}
+	}
+	log("Joined the fleet as %s", bold(fleetNodeName()))
+	info("Restart the API to serve the fleet listener on port %d, and delete %s", fleetPort(), bundlePath)
+}
+
+// cmdFleetAdd registers a peer after checking it answers with a certificate
+// from the fleet CA.
+func cmdFleetAdd(name, addr string) {
+	if msg := nameError(name); msg != "" {
+		die("Invalid node name %q: %s", name, msg)
+	}
+	if name == fleetLocalNode || name == fleetNodeName() {
+		die("%q refers to this node", name)
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, strconv.Itoa(fleetPort()))
+	}
+	peers, err := loadFleetPeers()
+	if err != nil {
+		die("%v", err)
+	}
+	for _, p := range peers {
+		if p.Name == name {
+			die("Fleet node %s is already registered (%s)", name, p.Addr)
+		}
+	}
+
+	conn, client, err := dialFleet(addr)
+	if err != nil {
+		die("%v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), fleetCallTimeout)
+	defer cancel()
+	if _, err := client.ListSites(ctx, &pb.ListSitesRequest{}); err != nil {
+		die("Cannot reach %s at %s: %v", name, addr, err)
+	}
+
+	if err := saveFleetPeers(append(peers, fleetPeer{Name: name, Addr: addr})); err != nil {
+		die("Cannot save fleet peers: %v", err)
+	}
+	log("Fleet node %s added (%s)", bold(name), addr)
+}
+
+// cmdFleetRemove unregisters a peer.
+func cmdFleetRemove(name string) {
+	peers, err := loadFleetPeers()
+	if err != nil {
+		die("%v", err)
+	}
+	var kept []fleetPeer
+	for _, p := range peers {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(peers) {
+		die("Unknown fleet node %q", name)
+	}
+	if err := saveFleetPeers(kept); err != nil {
+		die("Cannot save fleet peers: %v", err)
+	}
+	log("Fleet node %s removed", name)
+}
+
+// cmdFleetSites lists the clients on every node.
+func cmdFleetSites() {
+	results, err := fleetListSites(context.Background())
+	if err != nil {
+		die("%v", err)
+	}
+	fmt.Printf("%s%-16s %-20s %-8s %-10s %-8s %s%s\n", colorBold, "NODE", "CLIENT", "PORT", "STATUS", "RUNNING", "DOMAINS", colorReset)
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Printf("%-16s %sunreachable: %s%s\n", r.Node, colorRed, r.Error, colorReset)
+			continue
+		}
+		var sites []apiSiteInfo
+		json.Unmarshal(r.Data, &sites)
+		for _, s := range sites {
+			running := "no"
+			if s.Running {
+				running = "yes"
+			}
+			fmt.Printf("%-16s %-20s %-8s %-10s %-8s %s\n", r.Node, s.Name, s.Port, s.Status, running, strings.Join(s.Domains, " "))
+		}
+	}
+}
+
+// cmdFleetList shows the registered peers and whether they answer.
+func cmdFleetList() {
+	results, err := fleetStorage(context.Background())
+	if err != nil {
+		die("%v", err)
+	}
+	fmt.Printf("%s%-16s %-28s %-12s %s%s\n", colorBold, "NODE", "ADDRESS", "STATE", "STORAGE", colorReset)
+	for _, r := range results {
+		addr := r.Addr
+		if addr == "" {
+			addr = fmt.Sprintf("(this node, :%d)", fleetPort())
+		}
+		if r.Error != "" {
+			fmt.Printf("%-16s %-28s %s%-12s%s %s\n", r.Node, addr, colorRed, "unreachable", colorReset, r.Error)
+			continue
+		}
+		var st storageInfo
+		json.Unmarshal(r.Data, &st)
+		fmt.Printf("%-16s %-28s %s%-12s%s %s across %d client(s)\n", r.Node, addr, colorGreen, "ok", colorReset, st.TotalHuman, len(st.Sites))
+	}
+}
+
+// cmdFleetStats prints container resource usage on every node.
+func cmdFleetStats() {
+	results, err := fleetStats(context.Background())
+	if err != nil {
+		die("%v", err)
+	}
+	fmt.Printf("%s%-16s %-28s %7s %22s %7s%s\n", colorBold, "NODE", "CONTAINER", "CPU%", "MEMORY", "MEM%", colorReset)
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Printf("%-16s %sunreachable: %s%s\n", r.Node, colorRed, r.Error, colorReset)
+			continue
+		}
+		var stats []containerStats
+		json.Unmarshal(r.Data, &stats)
+		for _, s := range stats {
+			fmt.Printf("%-16s %-28s %7.1f %22s %7.1f\n", r.Node, s.Name, s.CPU, s.MemUsage+" / "+s.MemLimit, s.MemPct)
+		}
+	}
+}
+
+// cmdFleetStorage prints per-client storage on every node.
+func cmdFleetStorage() {
+	results, err := fleetStorage(context.Background())
+	if err != nil {
+		die("%v", err)
+	}
+	fmt.Printf("%s%-16s %-20s %s%s\n", colorBold, "NODE", "CLIENT", "USED", colorReset)
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Printf("%-16s %sunreachable: %s%s\n", r.Node, colorRed, r.Error, colorReset)
+			continue
+		}
+		var st storageInfo
+		json.Unmarshal(r.Data, &st)
+		for _, s := range st.Sites {
+			fmt.Printf("%-16s %-20s %s\n", r.Node, s.Name, s.UsedHuman)
+		}
+		fmt.Printf("%-16s %-20s %s\n", r.Node, "(total)", st.TotalHuman)
+	}
+}
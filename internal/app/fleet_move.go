@@ -0,0 +1,343 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/skaia/grpc/grengo"
+	"google.golang.org/grpc"
+)
+
+// fleetMoveJobTimeout bounds how long an export or import on a node may run
+// during a move.
+const fleetMoveJobTimeout = 2 * time.Hour
+
+// fleetNode is a connected node taking part in a move.
+type fleetNode struct {
+	name   string
+	addr   string
+	conn   *grpc.ClientConn
+	client pb.GrengoServiceClient
+}
+
+func connectFleetNode(name string) (*fleetNode, error) {
+	addr, err := fleetNodeAddr(name)
+	if err != nil {
+		return nil, err
+	}
+	conn, client, err := dialFleet(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &fleetNode{name: name, addr: addr, conn: conn, client: client}, nil
+}
+
+// site returns the node's view of a client, or nil if it has none.
+func (n *fleetNode) site(ctx context.Context, name string) (*apiSiteInfo, error) {
+	cctx, cancel := context.WithTimeout(ctx, fleetCallTimeout)
+	defer cancel()
+	resp, err := n.client.ListSites(cctx, &pb.ListSitesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	var sites []apiSiteInfo
+	if err := json.Unmarshal([]byte(resp.SitesJson), &sites); err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	for i := range sites {
+		if sites[i].Name == name {
+			return &sites[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// waitJob polls a job on the node until it leaves the running state.
+func (n *fleetNode) waitJob(ctx context.Context, id string) error {
+	deadline := time.Now().Add(fleetMoveJobTimeout)
+	for time.Now().Before(deadline) {
+		cctx, cancel := context.WithTimeout(ctx, fleetCallTimeout)
+		resp, err := n.client.GetJob(cctx, &pb.GetJobRequest{Id: id})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: job %s: %w", n.name, id, err)
+		}
+		var j jobStatus
+		json.Unmarshal([]byte(resp.JobJson), &j)
+		switch j.Status {
+		case "completed":
+			return nil
+		case "failed":
+			return fmt.Errorf("%s: %s", n.name, j.Error)
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return fmt.Errorf("%s: job %s did not finish within %s", n.name, id, fleetMoveJobTimeout)
+}
+
+// download streams a finished job's archive into path.
+func (n *fleetNode) download(ctx context.Context, jobID, path string) (int64, error) {
+	stream, err := n.client.DownloadJob(ctx, &pb.DownloadJobRequest{Id: jobID})
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var total int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+		w, err := f.Write(chunk.Chunk)
+		total += int64(w)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, f.Close()
+}
+
+// upload sends a local archive, and its detached signature when it has one,
+// to the node's exports directory and returns the path the archive was
+// stored under there. The destination verifies the signature on import.
+func (n *fleetNode) upload(ctx context.Context, path string) (string, error) {
+	remote, err := n.uploadFile(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path + ".sig"); err == nil {
+		if _, err := n.uploadFile(ctx, path+".sig"); err != nil {
+			return "", fmt.Errorf("signature: %w", err)
+		}
+	}
+	return remote, nil
+}
+
+func (n *fleetNode) uploadFile(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	stream, err := n.client.UploadExport(ctx)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, 64*1024)
+	first := true
+	for {
+		r, err := f.Read(buf)
+		if r > 0 {
+			msg := &pb.UploadExportChunk{Chunk: buf[:r]}
+			if first {
+				msg.Filename = filepath.Base(path)
+				first = false
+			}
+			if err := stream.Send(msg); err != nil {
+				return "", err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return "", err
+	}
+	return resp.Path, nil
+}
+
+// host returns the address other systems (DNS) should use for the node.
+func (n *fleetNode) host() string {
+	if strings.HasPrefix(n.addr, "127.0.0.1:") {
+		return envVal(rootEnvFile(), "GRENGO_PUBLIC_HOST")
+	}
+	host, _, _ := net.SplitHostPort(n.addr)
+	return host
+}
+
+// cmdFleetMove moves a client between fleet nodes: export on the source,
+// transfer the archive, import and start it on the destination, run the
+// cutover hook, then disable the client on the source. The source copy is
+// kept (disabled) so the move can be reverted with 'grengo enable'.
+func cmdFleetMove(name, from, to string, keepSource bool) {
+	if from == to {
+		die("Source and destination are the same node")
+	}
+	ctx := context.Background()
+	src, err := connectFleetNode(from)
+	if err != nil {
+		die("%v", err)
+	}
+	defer src.conn.Close()
+	dst, err := connectFleetNode(to)
+	if err != nil {
+		die("%v", err)
+	}
+	defer dst.conn.Close()
+
+	site, err := src.site(ctx, name)
+	if err != nil {
+		die("%v", err)
+	}
+	if site == nil {
+		die("Client '%s' not found on %s", name, from)
+	}
+	if existing, err := dst.site(ctx, name); err != nil {
+		die("%v", err)
+	} else if existing != nil {
+		die("Client '%s' already exists on %s", name, to)
+	}
+
+	log("Exporting %s on %s…", name, from)
+	exp, err := src.client.ExportSite(ctx, &pb.SiteRequest{Name: name})
+	if err != nil {
+		die("%s: %v", from, err)
+	}
+	if err := src.waitJob(ctx, exp.Filename); err != nil {
+		die("Export failed: %v", err)
+	}
+
+	exportsDir := filepath.Join(ProjectRoot(), "exports")
+	ensureWritableDir(exportsDir)
+	local := filepath.Join(exportsDir, fmt.Sprintf("grengo-client-%s-move-%s.tar.gz", name, time.Now().Format("20060102-150405")))
+	defer os.Remove(local)
+	size, err := src.download(ctx, exp.Filename, local)
+	if err != nil {
+		die("Download from %s failed: %v", from, err)
+	}
+	defer os.Remove(local + ".sig")
+	if _, err := src.download(ctx, exp.Filename+".sig", local+".sig"); err != nil {
+		die("%s did not sign the export (%v) - run 'grengo keys init' there and trust its key on %s with 'grengo keys trust'", from, err, to)
+	}
+	info("Fetched archive from %s (%s)", from, humanBytes(uint64(size)))
+
+	remotePath, err := dst.upload(ctx, local)
+	if err != nil {
+		die("Upload to %s failed: %v", to, err)
+	}
+	log("Importing %s on %s…", name, to)
+	imp, err := dst.client.ImportSite(ctx, &pb.ImportSiteRequest{ArchivePath: remotePath, NewName: name})
+	if err != nil {
+		die("%s: %v", to, err)
+	}
+	if err := dst.waitJob(ctx, imp.Filename); err != nil {
+		die("Import failed: %v - %s is unchanged on %s", err, name, from)
+	}
+
+	log("Starting %s on %s…", name, to)
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	_, err = dst.client.StartSite(cctx, &pb.SiteRequest{Name: name})
+	cancel()
+	if err != nil {
+		die("%s: %v", to, err)
+	}
+	moved, err := dst.site(ctx, name)
+	if err != nil || moved == nil || !moved.Running {
+		die("%s did not start on %s - traffic stays on %s; inspect with 'grengo logs %s' there", name, to, from, name)
+	}
+
+	runFleetCutoverHook(site, src, dst)
+
+	if keepSource {
+		warn("%s is still running on %s (--keep-source)", name, from)
+	} else {
+		cctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		_, err := src.client.DisableSite(cctx, &pb.SiteRequest{Name: name})
+		cancel()
+		if err != nil {
+			warn("Could not disable %s on %s: %v", name, from, err)
+		} else {
+			info("Disabled %s on %s; its data is kept until you remove it there", name, from)
+		}
+	}
+	log("Client %s moved from %s to %s (port %s)", bold(name), from, to, moved.Port)
+}
+
+// runFleetCutoverHook runs GRENGO_FLEET_CUTOVER_HOOK so DNS can follow the
+// client to its new node. Without a hook the required DNS change is printed.
+func runFleetCutoverHook(site *apiSiteInfo, src, dst *fleetNode) {
+	hook := envVal(rootEnvFile(), "GRENGO_FLEET_CUTOVER_HOOK")
+	if hook == "" {
+		warn("No GRENGO_FLEET_CUTOVER_HOOK set - point DNS for %s to %s", strings.Join(site.Domains, " "), orDash(dst.host()))
+		return
+	}
+	log("Running cutover hook…")
+	cmd := exec.Command("sh", "-c", hook)
+	cmd.Dir = ProjectRoot()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GRENGO_CLIENT="+site.Name,
+		"GRENGO_DOMAINS="+strings.Join(site.Domains, " "),
+		"GRENGO_FROM_NODE="+src.name,
+		"GRENGO_FROM_HOST="+src.host(),
+		"GRENGO_TO_NODE="+dst.name,
+		"GRENGO_TO_HOST="+dst.host(),
+	)
+	if err := cmd.Run(); err != nil {
+		die("Cutover hook failed: %v - %s runs on both nodes; rerun the hook, then 'grengo disable %s' on %s", err, site.Name, site.Name, src.name)
+	}
+}
+
+// receiveUpload stores an archive, or an archive's detached .sig, streamed by
+// a fleet peer in exports/ and returns its path.
+func receiveUpload(recv func() (*pb.UploadExportChunk, error)) (string, error) {
+	exportsDir := filepath.Join(ProjectRoot(), "exports")
+	if err := os.MkdirAll(exportsDir, 0755); err != nil {
+		return "", err
+	}
+	first, err := recv()
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(first.Filename)
+	if !(strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tar.gz.sig")) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid archive name %q", first.Filename)
+	}
+	path := filepath.Join(exportsDir, name)
+	tmp, err := os.CreateTemp(exportsDir, ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	for msg := first; ; {
+		if _, err := tmp.Write(msg.Chunk); err != nil {
+			return "", err
+		}
+		msg, err = recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/skaia/grpc/grengo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// installFleetNode writes a fresh fleet CA and node certificate under the
+// test's GRENGO_ROOT and returns the CA so more certificates can be issued.
+func installFleetNode(t *testing.T, node string) (caCert, caKey []byte) {
+	t.Helper()
+	caCert, caKey, err := newFleetCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key, err := issueFleetCert(caCert, caKey, node, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(fleetDir(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for file, data := range map[string][]byte{
+		fleetCACertFile():   caCert,
+		fleetCAKeyFile():    caKey,
+		fleetNodeCertFile(): cert,
+		fleetNodeKeyFile():  key,
+	} {
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return caCert, caKey
+}
+
+// startTestFleetServer serves GrengoServer with the node's fleet TLS config
+// on a random loopback port.
+func startTestFleetServer(t *testing.T) string {
+	t.Helper()
+	cfg, err := fleetTLSConfig(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(cfg)),
+		grpc.UnaryInterceptor(fleetAuthInterceptor),
+		grpc.StreamInterceptor(fleetAuthStreamInterceptor),
+	)
+	pb.RegisterGrengoServiceServer(srv, &GrengoServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func TestFleetBundleRoundTrip(t *testing.T) {
+	caCert, caKey, err := newFleetCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key, err := issueFleetCert(caCert, caKey, "edge-1", []string{"10.0.0.7", "edge-1.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := append(append(fleetBundleBlock("ca", caCert), fleetBundleBlock("node", cert)...), fleetBundleBlock("node", key)...)
+	gotCA, gotCert, gotKey, err := parseFleetBundle(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotCA) != string(caCert) || string(gotCert) != string(cert) || string(gotKey) != string(key) {
+		t.Fatal("bundle did not round-trip")
+	}
+
+	pair, err := tls.X509KeyPair(gotCert, gotKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(pair.Certificate[0])
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(gotCA)
+	for _, host := range []string{"10.0.0.7", "edge-1.example.com", "127.0.0.1"} {
+		if _, err := leaf.Verify(x509.VerifyOptions{Roots: pool, DNSName: host, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+			t.Errorf("certificate not valid for %s: %v", host, err)
+		}
+	}
+
+	if _, _, _, err := parseFleetBundle(caCert); err == nil {
+		t.Fatal("bare CA certificate accepted as a bundle")
+	}
+}
+
+func TestFleetMutualTLS(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	installFleetNode(t, "core")
+	if got := fleetNodeName(); got != "core" {
+		t.Fatalf("fleetNodeName = %q", got)
+	}
+	addr := startTestFleetServer(t)
+
+	conn, client, err := dialFleet(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), fleetCallTimeout)
+	defer cancel()
+	if _, err := client.ListJobs(ctx, &pb.EmptyRequest{}); err != nil {
+		t.Fatalf("fleet member rejected: %v", err)
+	}
+	if _, err := client.Exec(ctx, &pb.ExecRequest{Command: "logs"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("fleet certificate alone reached Exec: %v", err)
+	}
+
+	// A certificate from another CA is refused even though the client
+	// trusts the server.
+	otherCA, otherKey, _ := newFleetCA()
+	cert, key, _ := issueFleetCert(otherCA, otherKey, "intruder", nil)
+	pair, _ := tls.X509KeyPair(cert, key)
+	cfg, _ := fleetTLSConfig(false)
+	cfg.Certificates = []tls.Certificate{pair}
+	bad, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bad.Close()
+	if _, err := pb.NewGrengoServiceClient(bad).ListJobs(ctx, &pb.EmptyRequest{}); err == nil {
+		t.Fatal("certificate from a foreign CA accepted")
+	}
+}
+
+func TestFleetUploadExport(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	installFleetNode(t, "core")
+	addr := startTestFleetServer(t)
+	conn, client, err := dialFleet(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	node := &fleetNode{name: "core", addr: addr, conn: conn, client: client}
+
+	src := filepath.Join(t.TempDir(), "grengo-client-shop-move.tar.gz")
+	payload := strings.Repeat("archive-bytes", 20000)
+	os.WriteFile(src, []byte(payload), 0600)
+	os.WriteFile(src+".sig", []byte("signature"), 0600)
+	path, err := node.upload(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(ProjectRoot(), "exports", "grengo-client-shop-move.tar.gz") {
+		t.Fatalf("stored at %s", path)
+	}
+	if got, _ := os.ReadFile(path); string(got) != payload {
+		t.Fatalf("uploaded %d bytes, want %d", len(got), len(payload))
+	}
+	if got, _ := os.ReadFile(path + ".sig"); string(got) != "signature" {
+		t.Fatalf("signature not stored beside the archive: %q", got)
+	}
+
+	for _, name := range []string{"notes.txt", ".hidden.tar.gz"} {
+		bad := filepath.Join(t.TempDir(), name)
+		os.WriteFile(bad, []byte("x"), 0600)
+		if _, err := node.upload(context.Background(), bad); err == nil {
+			t.Errorf("upload of %s accepted", name)
+		}
+	}
+}
+
+func TestFleetPeers(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	if err := saveFleetPeers([]fleetPeer{{Name: "west", Addr: "10.0.0.2:9102"}, {Name: "east", Addr: "10.0.0.1:9102"}}); err != nil {
+		t.Fatal(err)
+	}
+	peers, err := loadFleetPeers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 2 || peers[0].Name != "east" {
+		t.Fatalf("peers = %+v", peers)
+	}
+	if addr, err := fleetNodeAddr("west"); err != nil || addr != "10.0.0.2:9102" {
+		t.Fatalf("fleetNodeAddr(west) = %q, %v", addr, err)
+	}
+	if addr, _ := fleetNodeAddr(fleetLocalNode); addr != "127.0.0.1:9102" {
+		t.Fatalf("fleetNodeAddr(local) = %q", addr)
+	}
+	if _, err := fleetNodeAddr("north"); err == nil {
+		t.Fatal("unknown node resolved")
+	}
+}
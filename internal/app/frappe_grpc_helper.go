@@ -129,5 +129,4 @@ func grpcFrappeProvision(siteName, branch string, grpcPort int) {
 	}
 
 	fmt.Println("Frappe Framework multi-tenant site successfully provisioned via gRPC API and is now RUNNING.")
-	StartFrappeHealthRoutine()
 }
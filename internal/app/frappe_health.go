@@ -14,46 +14,8 @@ import (
 
 const frappeGRPCEndpoint = "127.0.0.1:3001"
 
-// StartFrappeHealthRoutine starts the singleton background health routine.
-// Call once at CLI startup. Discovers sites from the API each tick.
-func StartFrappeHealthRoutine() {
-	go runFrappeHealthLoop()
-}
-
-func runFrappeHealthLoop() {
-	client := &http.Client{Timeout: 10 * time.Second}
-	interval := 30 * time.Second
-
-	fmt.Println("[HEALTH] Frappe health routine started")
-
-	for {
-		sites, err := listSites()
-		if err != nil {
-			fmt.Printf("[HEALTH] could not list sites via gRPC: %v\n", err)
-			time.Sleep(interval)
-			continue
-		}
-
-		for _, site := range sites {
-			status := checkSite(client, site)
-			logStatus(status)
-
-			if !status.APIReachable || !status.HTTPReachable {
-				ok, err := triggerDeploy()
-				if err != nil {
-					fmt.Printf("[HEALTH][%s] nginx reload trigger failed: %v\n", site, err)
-				} else if ok {
-					fmt.Printf("[HEALTH][%s] nginx config regenerated and reloaded successfully\n", site)
-				} else {
-					fmt.Printf("[HEALTH][%s] deploy returned non-OK\n", site)
-				}
-			}
-		}
-
-		time.Sleep(interval)
-	}
-}
-
+// listSites returns the sites of the Frappe cluster. runAlertLoop probes
+// them with checkSite.
 func listSites() ([]string, error) {
 	conn, err := grpc.NewClient(frappeGRPCEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -88,32 +50,25 @@ func checkSite(client *http.Client, site string) SiteHealthStatus {
 	conn, err := grpc.NewClient(frappeGRPCEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		status.Error = fmt.Sprintf("API probe error (dial): %v", err)
-		fmt.Printf("[HEALTH][%s] API unreachable (gRPC dial): %v\n", site, err)
 	} else {
 		defer conn.Close()
 		c := pb.NewGoFTWServiceClient(conn)
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		resp, err := c.CheckSite(ctx, &pb.CheckSiteRequest{SiteName: site})
-		if err != nil {
+		if _, err := c.CheckSite(ctx, &pb.CheckSiteRequest{SiteName: site}); err != nil {
 			status.Error = fmt.Sprintf("API status: %v", err)
-			fmt.Printf("[HEALTH][%s] API error via gRPC: %v\n", site, err)
 		} else {
 			status.APIReachable = true
-			fmt.Printf("[HEALTH][%s] API OK (gRPC): %s\n", site, resp.StatusJson)
 		}
 	}
 
 	// 2. Direct HTTP probe on port 8000 with Host header for multi-tenant routing
 	req, err := http.NewRequest("GET", "http://127.0.0.1:8000", nil)
-	if err != nil {
-		fmt.Printf("[HEALTH][%s] could not build direct HTTP request: %v\n", site, err)
-	} else {
+	if err == nil {
 		req.Host = site
 		httpResp, err := client.Do(req)
 		if err != nil {
-			fmt.Printf("[HEALTH][%s] direct HTTP probe failed: %v\n", site, err)
 			if status.Error == "" {
 				status.Error = fmt.Sprintf("HTTP probe error: %v", err)
 			}
@@ -123,9 +78,8 @@ func checkSite(client *http.Client, site string) SiteHealthStatus {
 			// 200 or 302 (Frappe login redirect) both mean nginx is routing correctly
 			if httpResp.StatusCode == 200 || httpResp.StatusCode == 302 {
 				status.HTTPReachable = true
-				fmt.Printf("[HEALTH][%s] direct HTTP OK (%d)\n", site, httpResp.StatusCode)
-			} else {
-				fmt.Printf("[HEALTH][%s] direct HTTP returned %d\n", site, httpResp.StatusCode)
+			} else if status.Error == "" {
+				status.Error = fmt.Sprintf("HTTP probe returned %d", httpResp.StatusCode)
 			}
 		}
 	}
@@ -133,6 +87,8 @@ func checkSite(client *http.Client, site string) SiteHealthStatus {
 	return status
 }
 
+// triggerDeploy asks the Frappe cluster to regenerate and reload its nginx
+// config.
 func triggerDeploy() (bool, error) {
 	conn, err := grpc.NewClient(frappeGRPCEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -148,20 +104,5 @@ func triggerDeploy() (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("rpc: %w", err)
 	}
-
-	fmt.Printf("[HEALTH][DEPLOY] gRPC ReloadNginx -> success: %v\n", resp.Success)
-
 	return resp.Success, nil
 }
-
-func logStatus(s SiteHealthStatus) {
-	apiMark := "✓"
-	if !s.APIReachable {
-		apiMark = "✗"
-	}
-	httpMark := "✓"
-	if !s.HTTPReachable {
-		httpMark = "✗"
-	}
-	fmt.Printf("[HEALTH][%s] API:%s HTTP:%s\n", s.Site, apiMark, httpMark)
-}
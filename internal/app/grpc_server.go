@@ -12,9 +12,7 @@ import (
 	"sync"
 	"time"
 
-	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/skaia/grengo/internal/hardware"
@@ -35,6 +33,10 @@ func (s *GrengoServer) VerifyPasscode(ctx context.Context, req *pb.VerifyPasscod
 	return &pb.VerifyPasscodeResponse{Valid: verifyPasscode(req.P1, req.P2)}, nil
 }
 
+func (s *GrengoServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	return operatorLogin(req.Username, req.Password, req.Totp)
+}
+
 func (s *GrengoServer) ListSites(ctx context.Context, req *pb.ListSitesRequest) (*pb.ListSitesResponse, error) {
 	// Replicate the logic of apiListSites without http response
 	store := repo.New(ProjectRoot())
@@ -46,9 +48,10 @@ func (s *GrengoServer) ListSites(ctx context.Context, req *pb.ListSitesRequest)
 		return nil, err
 	}
 
+	key := apiKeyFromContext(ctx)
 	sites := []apiSiteInfo{}
 	for _, e := range entries {
-		if !e.IsDir() {
+		if !e.IsDir() || (key != nil && !key.siteAllowed(e.Name())) {
 			continue
 		}
 		ef := store.SiteEnvFile(e.Name())
@@ -91,7 +94,7 @@ func (s *GrengoServer) ListSites(ctx context.Context, req *pb.ListSitesRequest)
 }
 
 func (s *GrengoServer) Exec(ctx context.Context, req *pb.ExecRequest) (*pb.ExecResponse, error) {
-	if commandBlocked(req.Command) {
+	if commandBlocked(req.Command, req.Args) {
 		return &pb.ExecResponse{Ok: false, Error: "command not allowed"}, nil
 	}
 	args := append([]string{req.Command}, req.Args...)
@@ -293,12 +296,22 @@ func (s *GrengoServer) GetHardware(ctx context.Context, req *pb.EmptyRequest) (*
 }
 
 func (s *GrengoServer) ExportSite(ctx context.Context, req *pb.SiteRequest) (*pb.ExportSiteResponse, error) {
-	jobID := startSiteCommand(req.Name, "export", nil)
+	if !clientExists(req.Name) {
+		return nil, fmt.Errorf("site %q not found", req.Name)
+	}
+	jobID := startSiteExport(req.Name)
 	return &pb.ExportSiteResponse{Filename: jobID}, nil
 }
 
 func (s *GrengoServer) ImportSite(ctx context.Context, req *pb.ImportSiteRequest) (*pb.ImportSiteResponse, error) {
 	args := []string{req.ArchivePath, "--name", req.NewName, "--port", req.NewPort}
+	if req.Target != "" {
+		spec, err := namedBackupTarget(req.Target)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--target", spec)
+	}
 	jobID := startGlobalCommand("import", args)
 	return &pb.ImportSiteResponse{Filename: jobID}, nil
 }
@@ -334,12 +347,20 @@ func (s *GrengoServer) MigrateAll(ctx context.Context, req *pb.MigrateAllRequest
 }
 
 func (s *GrengoServer) ExportNode(ctx context.Context, req *pb.EmptyRequest) (*pb.ExportNodeResponse, error) {
-	jobID := startGlobalCommand("export-node", nil)
+	jobID := startGlobalCommand("export-node", withDefaultTarget(nil))
 	return &pb.ExportNodeResponse{Filename: jobID}, nil
 }
 
 func (s *GrengoServer) ImportNode(ctx context.Context, req *pb.ImportNodeRequest) (*pb.ImportNodeResponse, error) {
-	jobID := startGlobalCommand("import-node", []string{req.ArchivePath})
+	args := []string{req.ArchivePath}
+	if req.Target != "" {
+		spec, err := namedBackupTarget(req.Target)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--target", spec)
+	}
+	jobID := startGlobalCommand("import-node", args)
 	return &pb.ImportNodeResponse{Filename: jobID}, nil
 }
 
@@ -376,6 +397,202 @@ func (s *GrengoServer) ListExports(ctx context.Context, req *pb.EmptyRequest) (*
 	return &pb.ListExportsResponse{ExportsJson: string(b)}, nil
 }
 
+func (s *GrengoServer) ListTargetExports(ctx context.Context, req *pb.TargetRequest) (*pb.ListExportsResponse, error) {
+	spec, err := namedBackupTarget(req.Target)
+	if err != nil {
+		return nil, err
+	}
+	target, err := parseBackupTarget(spec)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := target.List()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list %s: %w", target, err)
+	}
+	if objs == nil {
+		objs = []backupObject{}
+	}
+	b, _ := json.Marshal(objs)
+	return &pb.ListExportsResponse{ExportsJson: string(b)}, nil
+}
+
+func (s *GrengoServer) ListReleases(ctx context.Context, req *pb.SiteRequest) (*pb.ListReleasesResponse, error) {
+	if !clientExists(req.Name) {
+		return nil, fmt.Errorf("site %q not found", req.Name)
+	}
+	releases, err := newGrengoService().ListReleases(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if releases == nil {
+		releases = []release{}
+	}
+	b, _ := json.Marshal(releases)
+	return &pb.ListReleasesResponse{ReleasesJson: string(b)}, nil
+}
+
+func (s *GrengoServer) RollbackSite(ctx context.Context, req *pb.RollbackSiteRequest) (*pb.RollbackSiteResponse, error) {
+	if !clientExists(req.Name) {
+		return nil, fmt.Errorf("site %q not found", req.Name)
+	}
+	var args []string
+	if req.ReleaseId > 0 {
+		args = append(args, strconv.FormatInt(req.ReleaseId, 10))
+	}
+	if req.RestoreDb {
+		args = append(args, "--restore-db")
+	}
+	jobID := startSiteCommand(req.Name, "rollback", args)
+	return &pb.RollbackSiteResponse{JobId: jobID}, nil
+}
+
+func (s *GrengoServer) WatchAlerts(req *pb.WatchAlertsRequest, stream pb.GrengoService_WatchAlertsServer) error {
+	if msg := nameError(req.Client); msg != "" {
+		return fmt.Errorf("client %q: %s", req.Client, msg)
+	}
+	ch, stop := watchAlerts(req.Client)
+	defer stop()
+	for {
+		select {
+		case ev := <-ch:
+			b, _ := json.Marshal(ev)
+			if err := stream.Send(&pb.AlertEvent{EventJson: string(b)}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *GrengoServer) ListAlerts(ctx context.Context, req *pb.ListAlertsRequest) (*pb.ListAlertsResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	records, err := newGrengoService().ListAlerts(req.FiringOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []alertRecord{}
+	}
+	b, _ := json.Marshal(records)
+	return &pb.ListAlertsResponse{AlertsJson: string(b)}, nil
+}
+
+func (s *GrengoServer) ListWebhookDeliveries(ctx context.Context, req *pb.ListWebhookDeliveriesRequest) (*pb.ListWebhookDeliveriesResponse, error) {
+	if req.Site != "" && !clientExists(req.Site) {
+		return nil, fmt.Errorf("site %q not found", req.Site)
+	}
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	deliveries, err := newGrengoService().ListWebhookDeliveries(req.Site, limit)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []webhookDelivery{}
+	}
+	b, _ := json.Marshal(deliveries)
+	return &pb.ListWebhookDeliveriesResponse{DeliveriesJson: string(b)}, nil
+}
+
+func (s *GrengoServer) SetSiteQuota(ctx context.Context, req *pb.SetSiteQuotaRequest) (*pb.SiteQuotaResponse, error) {
+	if !clientExists(req.Name) {
+		return nil, fmt.Errorf("site %q not found", req.Name)
+	}
+	q := siteQuota{
+		SiteName:  req.Name,
+		CPUShares: int(req.CpuShares),
+		MemoryMB:  req.MemoryMb,
+		PidsLimit: int(req.PidsLimit),
+		UploadsMB: req.UploadsMb,
+		DBMB:      req.DbMb,
+		Enforce:   req.Enforce,
+	}
+	if q.Enforce == "" {
+		q.Enforce = quotaEnforceArm
+	}
+	if err := validateSiteQuota(q); err != nil {
+		return nil, err
+	}
+	if err := newGrengoService().UpsertSiteQuota(q); err != nil {
+		return nil, err
+	}
+	if err := applyComposeLimits(req.Name, &q); err != nil {
+		return nil, err
+	}
+	resp, err := s.GetSiteQuota(ctx, &pb.SiteRequest{Name: req.Name})
+	if err != nil {
+		return nil, err
+	}
+	if clientRunning(req.Name) {
+		resp.JobId = startGlobalCommand("quota", []string{"apply", req.Name})
+	}
+	return resp, nil
+}
+
+func (s *GrengoServer) GetSiteQuota(ctx context.Context, req *pb.SiteRequest) (*pb.SiteQuotaResponse, error) {
+	if !clientExists(req.Name) {
+		return nil, fmt.Errorf("site %q not found", req.Name)
+	}
+	quotas, err := newGrengoService().ListSiteQuotas(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotas) == 0 {
+		return &pb.SiteQuotaResponse{QuotaJson: "null"}, nil
+	}
+	b, _ := json.Marshal(quotas[0])
+	return &pb.SiteQuotaResponse{QuotaJson: string(b)}, nil
+}
+
+func (s *GrengoServer) FleetListSites(ctx context.Context, req *pb.EmptyRequest) (*pb.FleetResponse, error) {
+	return fleetResponse(fleetListSites(ctx))
+}
+
+func (s *GrengoServer) FleetStats(ctx context.Context, req *pb.EmptyRequest) (*pb.FleetResponse, error) {
+	return fleetResponse(fleetStats(ctx))
+}
+
+func (s *GrengoServer) FleetStorage(ctx context.Context, req *pb.EmptyRequest) (*pb.FleetResponse, error) {
+	return fleetResponse(fleetStorage(ctx))
+}
+
+func fleetResponse(results []fleetResult, err error) (*pb.FleetResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	b, _ := json.Marshal(results)
+	return &pb.FleetResponse{NodesJson: string(b)}, nil
+}
+
+func (s *GrengoServer) MoveSite(ctx context.Context, req *pb.MoveSiteRequest) (*pb.MoveSiteResponse, error) {
+	for _, node := range []string{req.FromNode, req.ToNode} {
+		if _, err := fleetNodeAddr(node); err != nil {
+			return nil, err
+		}
+	}
+	args := []string{"move", req.Name, req.FromNode, req.ToNode}
+	if req.KeepSource {
+		args = append(args, "--keep-source")
+	}
+	jobID := startGlobalCommand("fleet", args)
+	return &pb.MoveSiteResponse{JobId: jobID}, nil
+}
+
+func (s *GrengoServer) UploadExport(stream pb.GrengoService_UploadExportServer) error {
+	path, err := receiveUpload(stream.Recv)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&pb.UploadExportResponse{Path: path})
+}
+
 func (s *GrengoServer) ListJobs(ctx context.Context, req *pb.EmptyRequest) (*pb.ListJobsResponse, error) {
 	jobsMu.Lock()
 	var list []*jobStatus
@@ -466,14 +683,28 @@ func (s *GrengoServer) WatchLogs(req *pb.EmptyRequest, stream pb.GrengoService_W
 	}
 }
 
+// QueryLogs searches the container logs collected by runLogCollectorLoop.
+func (s *GrengoServer) QueryLogs(ctx context.Context, req *pb.QueryLogsRequest) (*pb.QueryLogsResponse, error) {
+	q, err := newLogQuery(req.Clients, req.Since, req.Until, req.Grep, req.Level, int(req.Limit))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	entries, err := queryLogs(logsDir(), q)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []logEntry{}
+	}
+	b, _ := json.Marshal(entries)
+	return &pb.QueryLogsResponse{EntriesJson: string(b)}, nil
+}
+
 func (s *GrengoServer) SendAction(ctx context.Context, req *pb.SendActionRequest) (*pb.SendActionResponse, error) {
 	var parsed grengoActionRequest
 	if err := json.Unmarshal(req.Action, &parsed); err != nil {
 		return &pb.SendActionResponse{Accepted: false, Error: "invalid action payload"}, nil
 	}
-	if parsed.Action == "exec" && commandBlocked(parsed.Command) {
-		return &pb.SendActionResponse{Accepted: false, Error: fmt.Sprintf("command %q not allowed via API", parsed.Command)}, nil
-	}
 	jobID, err := dispatchGrengoAction(parsed)
 	if err != nil {
 		return &pb.SendActionResponse{Accepted: false, Error: err.Error()}, nil
@@ -520,9 +751,13 @@ func (s *GrengoServer) DownloadExport(req *pb.DownloadExportRequest, stream pb.G
 	return nil
 }
 
+// DownloadJob streams a job's archive. An id with a ".sig" suffix streams the
+// archive's detached signature instead, which a client move carries to the
+// destination node.
 func (s *GrengoServer) DownloadJob(req *pb.DownloadJobRequest, stream pb.GrengoService_DownloadJobServer) error {
+	id, signature := strings.CutSuffix(req.Id, ".sig")
 	jobsMu.Lock()
-	j := jobs[req.Id]
+	j := jobs[id]
 	jobsMu.Unlock()
 	if j == nil {
 		return fmt.Errorf("job not found")
@@ -530,8 +765,12 @@ func (s *GrengoServer) DownloadJob(req *pb.DownloadJobRequest, stream pb.GrengoS
 	if j.filePath == "" {
 		return fmt.Errorf("job has no archive")
 	}
+	path := j.filePath
+	if signature {
+		path += ".sig"
+	}
 
-	f, err := os.Open(j.filePath)
+	f, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("could not open job archive: %w", err)
 	}
@@ -553,54 +792,11 @@ func (s *GrengoServer) DownloadJob(req *pb.DownloadJobRequest, stream pb.GrengoS
 	return nil
 }
 
-func passcodeInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	if passcodeExemptMethod(info.FullMethod) {
-		return handler(ctx, req)
-	}
-	if !passcodeConfigured() {
-		return handler(ctx, req)
-	}
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, status.Errorf(codes.Unauthenticated, "missing metadata")
-	}
-	vals := md.Get("x-grengo-passcode")
-	if len(vals) == 0 {
-		return nil, status.Errorf(codes.Unauthenticated, "missing passcode")
-	}
-	parts := strings.SplitN(vals[0], ":", 2)
-	if len(parts) != 2 || !verifyPasscode(parts[0], parts[1]) {
-		return nil, status.Errorf(codes.Unauthenticated, "invalid passcode")
-	}
-	return handler(ctx, req)
-}
-
-func passcodeStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-	if passcodeExemptMethod(info.FullMethod) {
-		return handler(srv, ss)
-	}
-	if !passcodeConfigured() {
-		return handler(srv, ss)
-	}
-	md, ok := metadata.FromIncomingContext(ss.Context())
-	if !ok {
-		return status.Errorf(codes.Unauthenticated, "missing metadata")
-	}
-	vals := md.Get("x-grengo-passcode")
-	if len(vals) == 0 {
-		return status.Errorf(codes.Unauthenticated, "missing passcode")
-	}
-	parts := strings.SplitN(vals[0], ":", 2)
-	if len(parts) != 2 || !verifyPasscode(parts[0], parts[1]) {
-		return status.Errorf(codes.Unauthenticated, "invalid passcode")
-	}
-	return handler(srv, ss)
-}
-
 func passcodeExemptMethod(method string) bool {
 	switch method {
 	case pb.GrengoService_PasscodeStatus_FullMethodName,
-		pb.GrengoService_VerifyPasscode_FullMethodName:
+		pb.GrengoService_VerifyPasscode_FullMethodName,
+		pb.GrengoService_Login_FullMethodName:
 		return true
 	default:
 		return false
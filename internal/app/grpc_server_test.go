@@ -21,3 +21,27 @@ func TestPasscodeExemptMethod(t *testing.T) {
 		t.Fatalf("expected %s to require passcode", pb.GrengoService_ListSites_FullMethodName)
 	}
 }
+
+func TestCommandBlocked(t *testing.T) {
+	cases := []struct {
+		command string
+		args    []string
+		blocked bool
+	}{
+		{"logs", []string{"shop"}, false},
+		{"webhook", []string{"log", "shop"}, false},
+		{"keys", []string{"trust", "ed25519:abc"}, true},
+		{"keys", []string{"init"}, true},
+		{"webhook", []string{"secret", "shop"}, true},
+		{"import", []string{"exports/shop.tar.gz", "--allow-unsigned"}, true},
+		{"verify", []string{"exports/shop.tar.gz", "--allow-unsigned=true"}, true},
+	}
+	for _, c := range cases {
+		if got := commandBlocked(c.command, c.args); got != c.blocked {
+			t.Errorf("commandBlocked(%s %v) = %v, want %v", c.command, c.args, got, c.blocked)
+		}
+	}
+	if _, err := dispatchGrengoAction(grengoActionRequest{Action: "site-cmd", Name: "secret", Command: "webhook"}); err == nil {
+		t.Fatal("site-cmd rotated a webhook secret")
+	}
+}
@@ -0,0 +1,347 @@
+package app
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// importPlan describes everything an import would change on this node,
+// computed without touching it. Problems are conditions that would make the
+// import fail or clobber existing data.
+type importPlan struct {
+	Archive  string
+	Meta     archiveMeta
+	Signer   string
+	Verified string
+	Clients  []clientImportPlan
+	Problems []string
+}
+
+// clientImportPlan is the per-client part of an importPlan.
+type clientImportPlan struct {
+	Name         string
+	Skip         string // reason the client would be skipped, if any
+	ArchivedPort string
+	Port         string
+	PortNote     string
+	DBName       string
+	DBExists     bool
+	DumpBytes    int
+	Restore      string // result of the throwaway test restore
+	Uploads      int
+	UploadBytes  int64
+	HasCompose   bool
+}
+
+// cmdVerifyArchive checks an archive end to end (decryption, checksums,
+// signature, metadata, collisions, DB dump) and prints the import plan
+// without changing anything. It exits non-zero if any check fails.
+func cmdVerifyArchive(archivePath string, opts archiveOptions) {
+	plan := planArchiveImport(archivePath, "", "", opts)
+	printImportPlan(plan)
+	if len(plan.Problems) > 0 {
+		die("Verification failed - %d problem(s) found", len(plan.Problems))
+	}
+	log("Archive verified - safe to import")
+}
+
+// dryRunImport prints the plan for 'grengo import --dry-run' or
+// 'grengo import-node --dry-run' and exits non-zero if the import would fail.
+func dryRunImport(archivePath, newName, newPort string, opts archiveOptions) {
+	plan := planArchiveImport(archivePath, newName, newPort, opts)
+	printImportPlan(plan)
+	if len(plan.Problems) > 0 {
+		die("Dry run found %d problem(s) - nothing was changed", len(plan.Problems))
+	}
+	log("Dry run complete - nothing was changed")
+}
+
+// planArchiveImport loads and verifies the archive at archivePath and works
+// out what importing it would do. newName and newPort are the overrides of a
+// single-client import and must be empty for node archives.
+func planArchiveImport(archivePath, newName, newPort string, opts archiveOptions) importPlan {
+	plan := importPlan{Archive: archivePath}
+	if opts.Target != "" {
+		plan.Archive = opts.Target + "/" + archivePath
+		archivePath = fetchFromTarget(opts.Target, archivePath)
+		defer removeArchive(archivePath)
+	}
+
+	files, err := loadArchive(archivePath, opts)
+	if err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+		return plan
+	}
+	if _, ok := files["manifest.json"]; ok {
+		plan.Verified = "all entries match manifest.json"
+	} else {
+		plan.Verified = "no manifest (archive predates checksums)"
+	}
+	signer, err := verifyArchive(archivePath, files, opts)
+	if err != nil {
+		plan.Verified = "FAILED"
+		plan.Problems = append(plan.Problems, err.Error())
+	}
+	plan.Signer = signer
+
+	meta, err := decodeMeta(files)
+	if err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+		return plan
+	}
+	plan.Meta = meta
+	if meta.Version > archiveVersion {
+		plan.Problems = append(plan.Problems, fmt.Sprintf("archive version %d is newer than this grengo supports (%d)", meta.Version, archiveVersion))
+	}
+
+	pgUp := pgRunning()
+	ports := newPortAllocator()
+	switch meta.Type {
+	case "client":
+		name := meta.Name
+		if newName != "" {
+			name = newName
+		}
+		cp := planClientImport(files, "", name, newPort, ports, pgUp, true)
+		plan.Clients = append(plan.Clients, cp)
+	case "node":
+		if newName != "" || newPort != "" {
+			plan.Problems = append(plan.Problems, "--name and --port only apply to single-client archives")
+		}
+		for _, name := range meta.Clients {
+			cp := planClientImport(files, "clients/"+name+"/", name, "", ports, pgUp, false)
+			plan.Clients = append(plan.Clients, cp)
+		}
+	default:
+		plan.Problems = append(plan.Problems, fmt.Sprintf("unknown archive type '%s'", meta.Type))
+	}
+
+	for _, cp := range plan.Clients {
+		if cp.Skip != "" && meta.Type == "client" {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("client '%s': %s", cp.Name, cp.Skip))
+		}
+		if cp.Skip == "" && cp.DBExists {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("client '%s': database '%s' already exists and would be restored into", cp.Name, cp.DBName))
+		}
+		if strings.HasPrefix(cp.Restore, "failed") {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("client '%s': test restore %s", cp.Name, cp.Restore))
+		}
+		if strings.HasPrefix(cp.PortNote, "conflict") {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("client '%s': port %s", cp.Name, cp.PortNote))
+		}
+	}
+	return plan
+}
+
+// planClientImport mirrors cmdImportClient / cmdImportNode for one client.
+// strict selects the single-client rules, where an existing client is an
+// error rather than a skip.
+func planClientImport(files map[string][]byte, pfx, name, newPort string, ports *portAllocator, pgUp, strict bool) clientImportPlan {
+	cp := clientImportPlan{Name: name}
+	switch {
+	case name == "":
+		cp.Skip = "cannot determine client name - use --name <name>"
+		return cp
+	case nameError(name) != "":
+		cp.Skip = nameError(name)
+		return cp
+	case clientExists(name):
+		cp.Skip = "client already exists"
+		if strict {
+			cp.Skip += " - use --name to import under a different name"
+		}
+		return cp
+	}
+
+	envData, ok := files[pfx+"env"]
+	if !ok {
+		cp.Skip = "archive is missing the env file"
+		return cp
+	}
+	envMap := parseEnvBytes(envData)
+	cp.ArchivedPort = envMap["PORT"]
+	cp.Port, cp.PortNote = ports.resolve(cp.ArchivedPort, newPort)
+	_, cp.HasCompose = files[pfx+"compose.yml"]
+
+	uploadsBase := pfx + "uploads/"
+	for archPath, data := range files {
+		if strings.HasPrefix(archPath, uploadsBase) {
+			cp.Uploads++
+			cp.UploadBytes += int64(len(data))
+		}
+	}
+
+	cp.DBName = envMap["POSTGRES_DB"]
+	if cp.DBName == "" {
+		cp.DBName = name
+	}
+	dump := files[pfx+"db.sql"]
+	cp.DumpBytes = len(dump)
+	switch {
+	case len(dump) == 0:
+		cp.Restore = "skipped (no dump in archive)"
+	case !pgUp:
+		cp.Restore = "skipped (PostgreSQL not running)"
+	default:
+		env := loadSharedEnv()
+		cp.DBExists = dbExists(cp.DBName, env)
+		if err := testRestoreDump(dump, env); err != nil {
+			cp.Restore = "failed: " + err.Error()
+		} else {
+			cp.Restore = "ok"
+		}
+	}
+	return cp
+}
+
+// portAllocator simulates resolvePort across several clients: ports handed
+// out earlier in the plan count as used, just as they would after the real
+// import wrote each client's .env.
+type portAllocator struct {
+	used map[int]bool
+	max  int
+}
+
+func newPortAllocator() *portAllocator {
+	a := &portAllocator{used: map[int]bool{}}
+	for _, p := range usedPorts() {
+		a.take(p)
+	}
+	return a
+}
+
+func (a *portAllocator) take(p int) {
+	a.used[p] = true
+	if p > a.max {
+		a.max = p
+	}
+}
+
+// resolve returns the port the import would assign and a note explaining
+// it. A note starting with "conflict" means the real import would abort.
+func (a *portAllocator) resolve(archived, override string) (string, string) {
+	if override != "" {
+		p, err := strconv.Atoi(override)
+		if err != nil {
+			return override, "conflict: invalid port value"
+		}
+		if a.used[p] {
+			return override, fmt.Sprintf("conflict: %d is already in use", p)
+		}
+		a.take(p)
+		return override, "from --port"
+	}
+	if p, err := strconv.Atoi(archived); err == nil && p > 0 && !a.used[p] {
+		a.take(p)
+		return archived, "archived port is free"
+	}
+	next := BasePort
+	if len(a.used) > 0 {
+		next = a.max + 1
+	}
+	a.take(next)
+	if archived == "" {
+		return strconv.Itoa(next), "no archived port - auto-assigned"
+	}
+	return strconv.Itoa(next), fmt.Sprintf("archived port %s is taken - auto-assigned", archived)
+}
+
+// testRestoreDump restores dump into a throwaway database that is dropped
+// again afterwards, stopping at the first SQL error.
+func testRestoreDump(dump []byte, env SharedEnv) error {
+	b := make([]byte, 6)
+	rand.Read(b)
+	dbName := "grengo_verify_" + hex.EncodeToString(b)
+
+	createSQL := fmt.Sprintf(`CREATE DATABASE "%s";`, dbName)
+	if _, err := dockerExecOutput("skaia-postgres", "psql", "-U", env.PostgresUser, "-d", "template1", "-c", createSQL); err != nil {
+		return fmt.Errorf("cannot create scratch database: %v", err)
+	}
+	defer func() {
+		dropSQL := fmt.Sprintf(`DROP DATABASE IF EXISTS "%s";`, dbName)
+		if _, err := dockerExecOutput("skaia-postgres", "psql", "-U", env.PostgresUser, "-d", "template1", "-c", dropSQL); err != nil {
+			warn("Could not drop scratch database '%s': %v", dbName, err)
+		}
+	}()
+
+	cmd := exec.Command("docker", "exec", "-i", "skaia-postgres",
+		"psql", "-q", "-v", "ON_ERROR_STOP=1", "-U", env.PostgresUser, "-d", dbName)
+	cmd.Stdin = bytes.NewReader(dump)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			lines := strings.Split(msg, "\n")
+			return fmt.Errorf("%s", lines[len(lines)-1])
+		}
+		return err
+	}
+	return nil
+}
+
+// printImportPlan writes a human-readable plan to stdout.
+func printImportPlan(p importPlan) {
+	fmt.Printf("Archive:    %s\n", p.Archive)
+	if p.Meta.Type != "" {
+		fmt.Printf("Type:       %s (version %d, exported %s)\n", p.Meta.Type, p.Meta.Version, orDash(p.Meta.ExportedAt))
+	}
+	if p.Verified != "" {
+		fmt.Printf("Checksums:  %s\n", p.Verified)
+	}
+	if p.Signer != "" {
+		fmt.Printf("Signature:  trusted (%s)\n", p.Signer)
+	} else if p.Verified != "" {
+		fmt.Printf("Signature:  none\n")
+	}
+
+	creates := 0
+	for _, c := range p.Clients {
+		fmt.Println()
+		if c.Skip != "" {
+			fmt.Printf("  skip    %s  (%s)\n", orDash(c.Name), c.Skip)
+			continue
+		}
+		creates++
+		fmt.Printf("  create  %s\n", c.Name)
+		fmt.Printf("          directory  %s\n", clientDir(c.Name))
+		fmt.Printf("          port       %s  (%s)\n", c.Port, c.PortNote)
+		compose := "archived copy"
+		if !c.HasCompose {
+			compose = "none in archive"
+		}
+		fmt.Printf("          compose    %s\n", compose)
+		fmt.Printf("          uploads    %d file(s), %s\n", c.Uploads, humanBytes(uint64(c.UploadBytes)))
+		db := c.DBName
+		if c.DBExists {
+			db += "  (ALREADY EXISTS)"
+		}
+		fmt.Printf("          database   %s\n", db)
+		fmt.Printf("          dump       %s, test restore %s\n", humanBytes(uint64(c.DumpBytes)), c.Restore)
+	}
+	if creates > 0 {
+		fmt.Printf("\nThen: regenerate nginx config and reload nginx (%d client(s) created)\n", creates)
+	}
+
+	if len(p.Problems) > 0 {
+		fmt.Println()
+		sorted := append([]string(nil), p.Problems...)
+		sort.Strings(sorted)
+		for _, problem := range sorted {
+			fmt.Printf("  problem: %s\n", problem)
+		}
+	}
+	fmt.Println()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestClient creates a client directory whose .env holds the name, the
+// port and any extra env lines.
+func writeTestClient(t *testing.T, name, port, env string) {
+	t.Helper()
+	if err := os.MkdirAll(clientDir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(clientEnvFile(name), []byte("CLIENT_NAME="+name+"\nPORT="+port+"\n"+env), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPortAllocator(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	writeTestClient(t, "a", "1080", "")
+	writeTestClient(t, "b", "1081", "")
+
+	ports := newPortAllocator()
+	if port, note := ports.resolve("1090", ""); port != "1090" || note != "archived port is free" {
+		t.Fatalf("free archived port: %s (%s)", port, note)
+	}
+	// 1080 is taken and 1090 was handed out above, so the next client gets 1091.
+	if port, _ := ports.resolve("1080", ""); port != "1091" {
+		t.Fatalf("taken archived port resolved to %s, want 1091", port)
+	}
+	if _, note := ports.resolve("", "1081"); !strings.HasPrefix(note, "conflict") {
+		t.Fatalf("--port on a used port: %s", note)
+	}
+}
+
+func TestPlanArchiveImport(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	writeTestClient(t, "shop", "1080", "")
+
+	out := filepath.Join(t.TempDir(), "grengo-node.tar.gz")
+	w := createArchive(out, archiveOptions{})
+	writeMeta(w, archiveMeta{Version: archiveVersion, Type: "node", Clients: []string{"shop", "blog"}})
+	addBytesToArchive(w, []byte("PORT=1080\n"), "clients/shop/env")
+	addBytesToArchive(w, []byte("PORT=1080\nPOSTGRES_DB=blog_db\n"), "clients/blog/env")
+	addBytesToArchive(w, []byte("hello"), "clients/blog/uploads/a.txt")
+	finishArchive(w, out, archiveOptions{})
+
+	plan := planArchiveImport(out, "", "", archiveOptions{AllowUnsigned: true})
+	if len(plan.Problems) != 0 {
+		t.Fatalf("unexpected problems: %v", plan.Problems)
+	}
+	if len(plan.Clients) != 2 || plan.Clients[0].Skip == "" {
+		t.Fatalf("existing client 'shop' should be skipped: %+v", plan.Clients)
+	}
+	blog := plan.Clients[1]
+	if blog.Skip != "" || blog.Port != "1081" || blog.DBName != "blog_db" || blog.Uploads != 1 {
+		t.Fatalf("blog plan = %+v", blog)
+	}
+	if clientExists("blog") {
+		t.Fatal("planning created the client")
+	}
+
+	// A single-client import of an existing name is a hard failure.
+	out = filepath.Join(t.TempDir(), "grengo-client-shop.tar.gz")
+	w = createArchive(out, archiveOptions{})
+	writeMeta(w, archiveMeta{Version: archiveVersion, Type: "client", Name: "shop"})
+	addBytesToArchive(w, []byte("PORT=1080\n"), "env")
+	finishArchive(w, out, archiveOptions{})
+	if plan := planArchiveImport(out, "", "", archiveOptions{AllowUnsigned: true}); len(plan.Problems) != 1 {
+		t.Fatalf("problems = %v, want the name collision", plan.Problems)
+	}
+	if plan := planArchiveImport(out, "shop2", "", archiveOptions{AllowUnsigned: true}); len(plan.Problems) != 0 {
+		t.Fatalf("problems with --name = %v", plan.Problems)
+	}
+	if plan := planArchiveImport(out, "shop2", "", archiveOptions{}); plan.Verified != "FAILED" {
+		t.Fatalf("unsigned archive verified by default: %s", plan.Verified)
+	}
+
+	// Tampered archives are reported rather than imported.
+	data, _ := os.ReadFile(out)
+	data[len(data)/2] ^= 0xff
+	os.WriteFile(out, data, 0644)
+	if plan := planArchiveImport(out, "shop2", "", archiveOptions{AllowUnsigned: true}); len(plan.Problems) == 0 {
+		t.Fatal("corrupt archive produced no problems")
+	}
+}
@@ -52,6 +52,7 @@ func cmdStart(name string) {
 
 	ensureNetwork()
 	ensureImage()
+	releaseRollbackPin(name)
 
 	log("Starting %s…", name)
 	if err := dockerCompose(clientComposeFile(name), "up", "-d"); err != nil {
@@ -61,6 +62,7 @@ func cmdStart(name string) {
 
 	shipFrontendDist(name, distDir)
 	log("Frontend shipped to %s", name)
+	recordRelease(name, cliTrigger("start"), distDir, "")
 }
 
 // cmdStop stops a client's backend container.
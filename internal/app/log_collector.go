@@ -0,0 +1,148 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	logCollectInterval = 15 * time.Second
+	logPruneInterval   = 10 * time.Minute
+	// logInitialBackfill is how far back a container is read the first time
+	// it is seen.
+	logInitialBackfill = 24 * time.Hour
+)
+
+// logCollector follows the containers of every client with docker logs and
+// writes their lines to the log store.
+type logCollector struct {
+	store   *logStore
+	mu      sync.Mutex
+	tailing map[string]bool
+}
+
+// containerClient returns the client a container belongs to: the longest
+// client name it starts with followed by a dash.
+func containerClient(container string, clients []string) string {
+	owner := ""
+	for _, c := range clients {
+		if strings.HasPrefix(container, c+"-") && len(c) > len(owner) {
+			owner = c
+		}
+	}
+	return owner
+}
+
+// parseDockerLogLine splits a line of docker logs --timestamps.
+func parseDockerLogLine(line string) (time.Time, string) {
+	ts, msg, ok := strings.Cut(line, " ")
+	if ok {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			return t, msg
+		}
+	}
+	return time.Now(), line
+}
+
+func (c *logCollector) tail(client, container string) {
+	c.mu.Lock()
+	if c.tailing[container] {
+		c.mu.Unlock()
+		return
+	}
+	c.tailing[container] = true
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.tailing, container)
+			c.mu.Unlock()
+		}()
+		since := c.store.cursor(client, container)
+		from := since
+		if from.IsZero() {
+			from = time.Now().Add(-logInitialBackfill)
+		}
+		cmd := exec.Command("docker", "logs", "--follow", "--timestamps", "--since", from.UTC().Format(time.RFC3339Nano), container)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return
+		}
+		stderr, err := cmd.StderrPipe()
+		if err != nil {
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			return
+		}
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go c.read(client, container, "stdout", stdout, since, &wg)
+		go c.read(client, container, "stderr", stderr, since, &wg)
+		wg.Wait()
+		cmd.Wait()
+	}()
+}
+
+// read stores each line of one stream. Lines at or before since were stored
+// by a previous run.
+func (c *logCollector) read(client, container, stream string, r io.Reader, since time.Time, wg *sync.WaitGroup) {
+	defer wg.Done()
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		t, msg := parseDockerLogLine(sc.Text())
+		if !t.After(since) {
+			continue
+		}
+		err := c.store.append(client, logEntry{Time: t, Container: container, Stream: stream, Level: detectLogLevel(msg), Msg: msg})
+		if err != nil {
+			BroadcastLog("ERROR", logPrefix("logs", client), fmt.Sprintf("store %s: %v", container, err))
+			return
+		}
+	}
+}
+
+// logRetentionFor returns a client's stored policy or the default.
+func logRetentionFor(policies []logRetention, client string) logRetention {
+	for _, p := range policies {
+		if p.SiteName == client {
+			return p
+		}
+	}
+	return logRetention{SiteName: client, MaxAgeDays: defaultLogMaxAgeDays, MaxMB: defaultLogMaxMB}
+}
+
+// runLogCollectorLoop keeps a docker logs follower on every running client
+// container and applies retention, in the API process.
+func runLogCollectorLoop() {
+	c := &logCollector{store: newLogStore(logsDir()), tailing: map[string]bool{}}
+	svc := newGrengoService()
+	var lastPrune time.Time
+	for {
+		clients := clientNamesWithEnv()
+		if out, err := dockerOutput("ps", "--format", "{{.Names}}"); err == nil {
+			for _, container := range strings.Fields(out) {
+				if client := containerClient(container, clients); client != "" {
+					c.tail(client, container)
+				}
+			}
+		}
+		c.store.saveCursors()
+
+		if time.Since(lastPrune) >= logPruneInterval {
+			policies, _ := svc.ListLogRetention()
+			for _, client := range storedLogClients(c.store.dir) {
+				c.store.prune(client, logRetentionFor(policies, client), time.Now())
+			}
+			lastPrune = time.Now()
+		}
+		time.Sleep(logCollectInterval)
+	}
+}
@@ -1,5 +1,12 @@
 package app
 
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
 func cmdLogs(name string, extra []string) {
 	if !clientExists(name) {
 		die("Client '%s' not found", name)
@@ -8,3 +15,96 @@ func cmdLogs(name string, extra []string) {
 		die("Failed to get logs: %v", err)
 	}
 }
+
+// cmdLogsQuery searches the log store that 'grengo api' fills.
+func cmdLogsQuery(clients []string, since, until, grep, level string, limit int) {
+	q, err := newLogQuery(clients, since, until, grep, level, limit)
+	if err != nil {
+		die("%v", err)
+	}
+	entries, err := queryLogs(logsDir(), q)
+	if err != nil {
+		die("Cannot read logs: %v", err)
+	}
+	if len(entries) == 0 {
+		if _, err := os.Stat(logsDir()); os.IsNotExist(err) {
+			info("No stored logs yet; 'grengo api' collects them from running clients")
+		}
+		return
+	}
+	for _, e := range entries {
+		color := ""
+		switch e.Level {
+		case "error":
+			color = colorRed
+		case "warn":
+			color = colorYellow
+		}
+		fmt.Printf("%s %-20s %s%-5s%s %s\n", e.Time.Local().Format("2006-01-02 15:04:05.000"),
+			e.Container, color, strings.ToUpper(e.Level), colorReset, e.Msg)
+	}
+	if len(entries) == q.Limit {
+		info("Showing the newest %d lines; narrow the window or raise --limit", q.Limit)
+	}
+}
+
+// cmdLogsRetention sets, resets or shows how long stored logs are kept.
+func cmdLogsRetention(name string, days int, size string, reset bool) {
+	svc := newGrengoService()
+	if name != "" && (days != 0 || size != "" || reset) {
+		if !clientExists(name) {
+			die("Client '%s' not found", name)
+		}
+		if reset {
+			if err := svc.DeleteLogRetention(name); err != nil {
+				die("Cannot reset log retention: %v", err)
+			}
+			log("Log retention for '%s' reset to %d days, %s", name, defaultLogMaxAgeDays, formatQuotaMB(defaultLogMaxMB))
+			return
+		}
+		policies, err := svc.ListLogRetention()
+		if err != nil {
+			die("Cannot read log retention: %v", err)
+		}
+		p := logRetentionFor(policies, name)
+		if days != 0 {
+			p.MaxAgeDays = days
+		}
+		if size != "" {
+			if p.MaxMB, err = parseSizeMB(size); err != nil {
+				die("%v", err)
+			}
+		}
+		if p.MaxAgeDays <= 0 || p.MaxMB <= 0 {
+			die("Retention needs a positive number of days and size")
+		}
+		if err := svc.UpsertLogRetention(p); err != nil {
+			die("Cannot save log retention: %v", err)
+		}
+		log("Log retention for '%s' set to %d days, %s", name, p.MaxAgeDays, formatQuotaMB(p.MaxMB))
+		info("Applied by 'grengo api' within %s", logPruneInterval)
+		return
+	}
+
+	policies, err := svc.ListLogRetention()
+	if err != nil {
+		die("Cannot read log retention: %v", err)
+	}
+	clients := clientNamesWithEnv()
+	if name != "" {
+		clients = []string{name}
+	}
+	fmt.Printf("%s%-20s %-8s %-10s %-10s %s%s\n", colorBold, "CLIENT", "DAYS", "MAX SIZE", "STORED", "OLDEST", colorReset)
+	for _, c := range clients {
+		p := logRetentionFor(policies, c)
+		var stored int64
+		oldest := "-"
+		if segs, err := logSegments(logsDir(), c); err == nil && len(segs) > 0 {
+			for _, seg := range segs {
+				stored += seg.Size
+			}
+			oldest = segs[0].First.Local().Format(time.DateTime)
+		}
+		fmt.Printf("%-20s %-8d %-10s %-10s %s\n", c, p.MaxAgeDays, formatQuotaMB(p.MaxMB), humanBytes(uint64(stored)), oldest)
+	}
+}
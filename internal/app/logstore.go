@@ -0,0 +1,482 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// A client's log rolls over to a new segment at this size or age.
+	logSegmentMaxBytes = 16 << 20
+	logSegmentSpan     = time.Hour
+	// logSegmentLayout names segments by their first and last line, so a
+	// query only opens the segments that overlap its window.
+	logSegmentLayout = "20060102T150405.000000000Z"
+
+	defaultLogMaxAgeDays = 14
+	defaultLogMaxMB      = 512
+	defaultLogQueryLimit = 500
+	maxLogQueryLimit     = 5000
+)
+
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// logLevelWord finds a level near the start of a plain-text line, as in
+// "ERROR ...", "[warn] ..." or "level=info ...".
+var logLevelWord = regexp.MustCompile(`(?i)\b(panic|fatal|critical|crit|error|err|warning|warn|info|notice|debug|trace)\b`)
+
+// logEntry is one stored container log line. Client is implied by the
+// directory on disk and filled in when the line is read back.
+type logEntry struct {
+	Time      time.Time `json:"time"`
+	Client    string    `json:"client,omitempty"`
+	Container string    `json:"container"`
+	Stream    string    `json:"stream"`
+	Level     string    `json:"level"`
+	Msg       string    `json:"msg"`
+}
+
+// normalizeLogLevel maps the level names applications use onto debug, info,
+// warn and error. Unknown names return "".
+func normalizeLogLevel(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "panic", "fatal", "critical", "crit", "error", "err", "alert", "emerg":
+		return "error"
+	case "warning", "warn":
+		return "warn"
+	case "info", "notice", "information":
+		return "info"
+	case "debug", "trace":
+		return "debug"
+	}
+	return ""
+}
+
+func logLevelRank(level string) int {
+	for i, l := range logLevels {
+		if l == level {
+			return i
+		}
+	}
+	return 1
+}
+
+// detectLogLevel reads the level from a JSON line's level field, or from the
+// first level word near the start of a text line. Lines without one are info.
+func detectLogLevel(msg string) string {
+	if strings.HasPrefix(msg, "{") {
+		var fields map[string]any
+		if json.Unmarshal([]byte(msg), &fields) == nil {
+			for _, key := range []string{"level", "lvl", "severity"} {
+				if s, ok := fields[key].(string); ok {
+					if level := normalizeLogLevel(s); level != "" {
+						return level
+					}
+				}
+			}
+		}
+	}
+	head := msg
+	if len(head) > 80 {
+		head = head[:80]
+	}
+	if m := logLevelWord.FindString(head); m != "" {
+		return normalizeLogLevel(m)
+	}
+	return "info"
+}
+
+func logsDir() string { return filepath.Join(ProjectRoot(), "logs") }
+
+// logSegmentFile is one file of a client's log. The segment being written is
+// named by its first line only; on rollover it is renamed to the time range
+// of its oldest and newest lines.
+type logSegmentFile struct {
+	Path  string
+	First time.Time
+	Last  time.Time // zero while the segment is open
+	Size  int64
+}
+
+func (f logSegmentFile) open() bool { return f.Last.IsZero() }
+
+// logSegments lists a client's segments, oldest first.
+func logSegments(dir, client string) ([]logSegmentFile, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, client))
+	if err != nil {
+		return nil, err
+	}
+	var segs []logSegmentFile
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".log")
+		if !ok || e.IsDir() {
+			continue
+		}
+		first, last, _ := strings.Cut(name, "-")
+		seg := logSegmentFile{Path: filepath.Join(dir, client, e.Name())}
+		if seg.First, err = time.Parse(logSegmentLayout, first); err != nil {
+			continue
+		}
+		if last != "" {
+			if seg.Last, err = time.Parse(logSegmentLayout, last); err != nil {
+				continue
+			}
+		}
+		if fi, err := e.Info(); err == nil {
+			seg.Size = fi.Size()
+		}
+		segs = append(segs, seg)
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].First.Before(segs[j].First) })
+	return segs, nil
+}
+
+// sealLogSegment renames a finished segment to carry its time range. With
+// zero times the range is read from the file, for segments left open by a
+// previous run.
+func sealLogSegment(seg logSegmentFile, first, last time.Time) error {
+	if first.IsZero() || last.IsZero() {
+		first, last = seg.First, seg.First
+		scanLogSegment(seg.Path, func(e logEntry) {
+			if e.Time.Before(first) {
+				first = e.Time
+			}
+			if e.Time.After(last) {
+				last = e.Time
+			}
+		})
+	}
+	name := first.UTC().Format(logSegmentLayout) + "-" + last.UTC().Format(logSegmentLayout) + ".log"
+	return os.Rename(seg.Path, filepath.Join(filepath.Dir(seg.Path), name))
+}
+
+func scanLogSegment(path string, fn func(logEntry)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		var e logEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			fn(e)
+		}
+	}
+	return sc.Err()
+}
+
+// logStore appends container log lines to per-client segment files under
+// logs/<client>/ and remembers, per container, the newest line stored.
+type logStore struct {
+	dir     string
+	mu      sync.Mutex
+	writers map[string]*logSegmentWriter
+	cursors map[string]map[string]time.Time
+	dirty   map[string]bool
+}
+
+type logSegmentWriter struct {
+	seg         logSegmentFile
+	f           *os.File
+	opened      time.Time
+	first, last time.Time
+}
+
+func newLogStore(dir string) *logStore {
+	return &logStore{
+		dir:     dir,
+		writers: map[string]*logSegmentWriter{},
+		cursors: map[string]map[string]time.Time{},
+		dirty:   map[string]bool{},
+	}
+}
+
+// cursor returns the time of the newest stored line from container.
+func (s *logStore) cursor(client, container string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadCursors(client)[container]
+}
+
+func (s *logStore) loadCursors(client string) map[string]time.Time {
+	if c, ok := s.cursors[client]; ok {
+		return c
+	}
+	c := map[string]time.Time{}
+	if data, err := os.ReadFile(filepath.Join(s.dir, client, "cursor.json")); err == nil {
+		json.Unmarshal(data, &c)
+	}
+	s.cursors[client] = c
+	return c
+}
+
+// saveCursors writes the cursors that moved since the last save.
+func (s *logStore) saveCursors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.dirty {
+		data, _ := json.Marshal(s.cursors[client])
+		writeFileAtomic(filepath.Join(s.dir, client, "cursor.json"), data, 0644)
+		delete(s.dirty, client)
+	}
+}
+
+func (s *logStore) append(client string, e logEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.writers[client]
+	if w != nil && (w.seg.Size >= logSegmentMaxBytes || time.Since(w.opened) >= logSegmentSpan) {
+		s.closeWriter(client)
+		w = nil
+	}
+	if w == nil {
+		var err error
+		if w, err = s.openWriter(client, e.Time); err != nil {
+			return err
+		}
+	}
+
+	e.Client = ""
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	n, err := w.f.Write(append(line, '\n'))
+	w.seg.Size += int64(n)
+	if err != nil {
+		return err
+	}
+	if e.Time.Before(w.first) {
+		w.first = e.Time
+	}
+	if e.Time.After(w.last) {
+		w.last = e.Time
+	}
+	cursors := s.loadCursors(client)
+	if e.Time.After(cursors[e.Container]) {
+		cursors[e.Container] = e.Time
+		s.dirty[client] = true
+	}
+	return nil
+}
+
+// openWriter starts a new segment, sealing any left open by a previous run.
+func (s *logStore) openWriter(client string, first time.Time) (*logSegmentWriter, error) {
+	dir := filepath.Join(s.dir, client)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if segs, err := logSegments(s.dir, client); err == nil {
+		for _, seg := range segs {
+			if seg.open() {
+				sealLogSegment(seg, time.Time{}, time.Time{})
+			}
+		}
+	}
+	seg := logSegmentFile{Path: filepath.Join(dir, first.UTC().Format(logSegmentLayout)+".log"), First: first}
+	f, err := os.OpenFile(seg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w := &logSegmentWriter{seg: seg, f: f, opened: time.Now(), first: first, last: first}
+	s.writers[client] = w
+	return w, nil
+}
+
+func (s *logStore) closeWriter(client string) {
+	w := s.writers[client]
+	if w == nil {
+		return
+	}
+	w.f.Close()
+	sealLogSegment(w.seg, w.first, w.last)
+	delete(s.writers, client)
+}
+
+// prune applies a client's retention: segments whose last line is older than
+// the age limit go first, then the oldest ones until the size fits.
+func (s *logStore) prune(client string, p logRetention, now time.Time) (removed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	segs, err := logSegments(s.dir, client)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, seg := range segs {
+		total += seg.Size
+	}
+	cutoff := now.AddDate(0, 0, -p.MaxAgeDays)
+	limit := p.MaxMB << 20
+	for _, seg := range segs {
+		if seg.open() {
+			continue
+		}
+		if !seg.Last.Before(cutoff) && total <= limit {
+			break
+		}
+		if os.Remove(seg.Path) == nil {
+			total -= seg.Size
+			removed++
+		}
+	}
+	return removed
+}
+
+// logQuery selects stored lines. Zero times leave that end open.
+type logQuery struct {
+	Clients []string
+	Since   time.Time
+	Until   time.Time
+	Grep    *regexp.Regexp
+	Level   string
+	Limit   int
+}
+
+// parseLogTime reads a --since/--until value: a duration back from now
+// (90m, 36h, 2d) or a timestamp in RFC 3339 or local "2006-01-02 15:04".
+func parseLogTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use e.g. 90m, 2d, 2026-03-01 12:00 or RFC 3339)", s)
+}
+
+// newLogQuery validates the options shared by 'grengo logs' and QueryLogs.
+func newLogQuery(clients []string, since, until, grep, level string, limit int) (logQuery, error) {
+	now := time.Now()
+	q := logQuery{Clients: clients, Limit: limit}
+	for _, c := range clients {
+		if msg := nameError(c); msg != "" {
+			return q, fmt.Errorf("client %q: %s", c, msg)
+		}
+	}
+	var err error
+	if q.Since, err = parseLogTime(since, now); err != nil {
+		return q, err
+	}
+	if q.Until, err = parseLogTime(until, now); err != nil {
+		return q, err
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return q, fmt.Errorf("until is before since")
+	}
+	if grep != "" {
+		if q.Grep, err = regexp.Compile(grep); err != nil {
+			return q, fmt.Errorf("invalid grep pattern: %v", err)
+		}
+	}
+	if level != "" {
+		if q.Level = normalizeLogLevel(level); q.Level == "" {
+			return q, fmt.Errorf("invalid level %q (debug, info, warn or error)", level)
+		}
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLogQueryLimit
+	}
+	if q.Limit > maxLogQueryLimit {
+		q.Limit = maxLogQueryLimit
+	}
+	return q, nil
+}
+
+func (q logQuery) matches(e logEntry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Time.After(q.Until) {
+		return false
+	}
+	if q.Level != "" && logLevelRank(e.Level) < logLevelRank(q.Level) {
+		return false
+	}
+	return q.Grep == nil || q.Grep.MatchString(e.Msg)
+}
+
+// storedLogClients lists the clients that have stored logs.
+func storedLogClients(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var clients []string
+	for _, e := range entries {
+		if e.IsDir() && nameError(e.Name()) == "" {
+			clients = append(clients, e.Name())
+		}
+	}
+	return clients
+}
+
+// queryLogs returns the newest q.Limit matching lines, oldest first.
+func queryLogs(dir string, q logQuery) ([]logEntry, error) {
+	clients := q.Clients
+	if len(clients) == 0 {
+		clients = storedLogClients(dir)
+	}
+	var out []logEntry
+	for _, client := range clients {
+		segs, err := logSegments(dir, client)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var matched []logEntry
+		for _, seg := range segs {
+			// The open segment is always read: containers writing
+			// concurrently can add lines older than its name.
+			if !seg.open() && ((!q.Until.IsZero() && seg.First.After(q.Until)) || (!q.Since.IsZero() && seg.Last.Before(q.Since))) {
+				continue
+			}
+			err := scanLogSegment(seg.Path, func(e logEntry) {
+				if q.matches(e) {
+					e.Client = client
+					matched = append(matched, e)
+					if len(matched) > 2*q.Limit {
+						matched = append(matched[:0], matched[len(matched)-q.Limit:]...)
+					}
+				}
+			})
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+		out = append(out, matched...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	if len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, nil
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectLogLevel(t *testing.T) {
+	for msg, want := range map[string]string{
+		`{"level":"WARNING","msg":"slow query"}`:   "warn",
+		`{"severity":"fatal"}`:                     "error",
+		"2026/03/01 12:00:00 ERROR db: timeout":    "error",
+		"[debug] cache miss":                       "debug",
+		"level=info msg=started":                   "info",
+		"listening on :8080":                       "info",
+		strings.Repeat("x", 90) + " error at tail": "info",
+	} {
+		if got := detectLogLevel(msg); got != want {
+			t.Errorf("detectLogLevel(%q) = %s, want %s", msg, got, want)
+		}
+	}
+}
+
+func TestParseLogTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"":                     {},
+		"90m":                  now.Add(-90 * time.Minute),
+		"2d":                   now.AddDate(0, 0, -2),
+		"2026-03-01T08:00:00Z": time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+		"2026-03-01 08:30":     time.Date(2026, 3, 1, 8, 30, 0, 0, time.Local),
+	} {
+		got, err := parseLogTime(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseLogTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseLogTime("yesterday", now); err == nil {
+		t.Error("accepted an unknown time")
+	}
+	if _, err := newLogQuery(nil, "1h", "2h", "", "", 0); err == nil {
+		t.Error("accepted until before since")
+	}
+	if _, err := newLogQuery([]string{"../etc"}, "", "", "", "", 0); err == nil {
+		t.Error("accepted an invalid client name")
+	}
+	if _, err := newLogQuery(nil, "", "", "(", "", 0); err == nil {
+		t.Error("accepted an invalid pattern")
+	}
+}
+
+func TestContainerClient(t *testing.T) {
+	clients := []string{"shop", "shop-eu", "blog"}
+	for container, want := range map[string]string{
+		"shop-backend":    "shop",
+		"shop-eu-backend": "shop-eu",
+		"blog-worker":     "blog",
+		"skaia-nginx":     "",
+	} {
+		if got := containerClient(container, clients); got != want {
+			t.Errorf("containerClient(%s) = %q, want %q", container, got, want)
+		}
+	}
+
+	ts, msg := parseDockerLogLine("2026-03-01T12:00:00.123456789Z GET /health 200")
+	if !ts.Equal(time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC)) || msg != "GET /health 200" {
+		t.Errorf("parseDockerLogLine = %v, %q", ts, msg)
+	}
+}
+
+func TestLogStoreQuery(t *testing.T) {
+	dir := t.TempDir()
+	s := newLogStore(dir)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	add := func(client string, minute int, level, msg string) {
+		t.Helper()
+		e := logEntry{Time: base.Add(time.Duration(minute) * time.Minute), Container: client + "-backend", Stream: "stdout", Level: level, Msg: msg}
+		if err := s.append(client, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("shop", 0, "info", "started")
+	add("shop", 10, "error", "payment failed: timeout")
+	add("blog", 5, "warn", "slow render")
+	// Roll shop over so it has a sealed segment and an open one.
+	s.writers["shop"].opened = time.Now().Add(-2 * logSegmentSpan)
+	add("shop", 70, "info", "payment ok")
+	add("shop", 80, "warn", "payment retried")
+	s.saveCursors()
+
+	segs, err := logSegments(dir, "shop")
+	if err != nil || len(segs) != 2 || segs[0].open() || !segs[1].open() {
+		t.Fatalf("segments = %+v, %v", segs, err)
+	}
+	if !segs[0].First.Equal(base) || !segs[0].Last.Equal(base.Add(10*time.Minute)) {
+		t.Errorf("sealed segment range = %v - %v", segs[0].First, segs[0].Last)
+	}
+	if c := newLogStore(dir).cursor("shop", "shop-backend"); !c.Equal(base.Add(80 * time.Minute)) {
+		t.Errorf("stored cursor = %v", c)
+	}
+
+	query := func(q logQuery) []string {
+		t.Helper()
+		if q.Limit == 0 {
+			q.Limit = 100
+		}
+		entries, err := queryLogs(dir, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msgs []string
+		for _, e := range entries {
+			msgs = append(msgs, e.Client+":"+e.Msg)
+		}
+		return msgs
+	}
+	check := func(name string, got []string, want ...string) {
+		t.Helper()
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	check("all", query(logQuery{}), "shop:started", "blog:slow render", "shop:payment failed: timeout", "shop:payment ok", "shop:payment retried")
+	check("since", query(logQuery{Since: base.Add(60 * time.Minute)}), "shop:payment ok", "shop:payment retried")
+	check("until", query(logQuery{Until: base.Add(5 * time.Minute)}), "shop:started", "blog:slow render")
+	check("level", query(logQuery{Level: "warn"}), "blog:slow render", "shop:payment failed: timeout", "shop:payment retried")
+	q, _ := newLogQuery([]string{"shop"}, "", "", "payment (ok|retried)", "", 0)
+	check("grep", query(q), "shop:payment ok", "shop:payment retried")
+	check("limit", query(logQuery{Limit: 2}), "shop:payment ok", "shop:payment retried")
+	check("unknown client", query(logQuery{Clients: []string{"gone"}}))
+
+	// A new store seals the segment the previous run left open.
+	if err := newLogStore(dir).append("shop", logEntry{Time: base.Add(90 * time.Minute), Container: "shop-backend", Msg: "restarted"}); err != nil {
+		t.Fatal(err)
+	}
+	segs, _ = logSegments(dir, "shop")
+	if len(segs) != 3 || segs[1].open() || !segs[1].Last.Equal(base.Add(80*time.Minute)) {
+		t.Fatalf("segments after restart = %+v", segs)
+	}
+}
+
+func TestLogStorePrune(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "shop"), 0755)
+	now := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	write := func(first, last time.Time, size int) {
+		name := first.Format(logSegmentLayout) + "-" + last.Format(logSegmentLayout) + ".log"
+		os.WriteFile(filepath.Join(dir, "shop", name), make([]byte, size), 0644)
+	}
+	write(now.AddDate(0, 0, -20), now.AddDate(0, 0, -19), 10)
+	write(now.AddDate(0, 0, -5), now.AddDate(0, 0, -4), 600<<10)
+	write(now.AddDate(0, 0, -3), now.AddDate(0, 0, -2), 600<<10)
+	write(now.AddDate(0, 0, -1), now, 10)
+
+	s := newLogStore(dir)
+	if n := s.prune("shop", logRetention{MaxAgeDays: 14, MaxMB: 512}, now); n != 1 {
+		t.Fatalf("age prune removed %d segments", n)
+	}
+	if n := s.prune("shop", logRetention{MaxAgeDays: 14, MaxMB: 1}, now); n != 1 {
+		t.Fatalf("size prune removed %d segments", n)
+	}
+	segs, _ := logSegments(dir, "shop")
+	if len(segs) != 2 || !segs[0].First.Equal(now.AddDate(0, 0, -3)) {
+		t.Fatalf("kept segments = %+v", segs)
+	}
+}
@@ -0,0 +1,458 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skaia/grengo/internal/repo"
+	"github.com/skaia/grengo/internal/services"
+	"gopkg.in/yaml.v3"
+)
+
+// nodeManifest is the declarative description of a node, kept in
+// grengo.yaml so hosts can be managed from git. Clients not listed are left
+// alone unless apply runs with --prune, which disables them.
+type nodeManifest struct {
+	Clients map[string]manifestClient `yaml:"clients"`
+}
+
+// manifestClient describes one client. Omitted fields keep whatever the node
+// has; env only converges the keys it lists, and a null value removes one.
+type manifestClient struct {
+	Domains     []string             `yaml:"domains"`
+	Port        int                  `yaml:"port"`
+	Enabled     *bool                `yaml:"enabled"`
+	Armed       *bool                `yaml:"armed"`
+	Env         map[string]*string   `yaml:"env"`
+	FrappeSites []manifestFrappeSite `yaml:"frappe_sites"`
+}
+
+type manifestFrappeSite struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+// manifestManagedKeys are derived from domains and port and cannot be set
+// through env.
+var manifestManagedKeys = map[string]string{
+	"CLIENT_NAME":      "the client key",
+	"PORT":             "port",
+	"DOMAINS":          "domains",
+	"CORS_ORIGINS":     "domains",
+	"PUBLIC_BASE_URL":  "domains",
+	"SITEMAP_BASE_URL": "domains",
+}
+
+// defaultManifestPath is grengo.yaml in the project root.
+func defaultManifestPath() string {
+	return filepath.Join(ProjectRoot(), "grengo.yaml")
+}
+
+// loadManifest reads and validates a node manifest.
+func loadManifest(path string) (nodeManifest, error) {
+	var m nodeManifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return m, fmt.Errorf("parse %s: %v", path, err)
+	}
+	return m, validateManifest(m)
+}
+
+func validateManifest(m nodeManifest) error {
+	ports := map[int]string{}
+	for _, name := range m.clientNames() {
+		c := m.Clients[name]
+		if msg := nameError(name); msg != "" {
+			return fmt.Errorf("client %q: %s", name, msg)
+		}
+		if c.Port < 0 || c.Port > 65535 {
+			return fmt.Errorf("client %s: invalid port %d", name, c.Port)
+		}
+		if other, ok := ports[c.Port]; ok && c.Port != 0 {
+			return fmt.Errorf("clients %s and %s both use port %d", other, name, c.Port)
+		}
+		ports[c.Port] = name
+		if len(c.Domains) > 0 {
+			if _, err := domainEnvValues(c.Domains); err != nil {
+				return fmt.Errorf("client %s: %v", name, err)
+			}
+		}
+		for key := range c.Env {
+			if !isEnvKey(key) {
+				return fmt.Errorf("client %s: invalid env key %q", name, key)
+			}
+			if field, ok := manifestManagedKeys[key]; ok {
+				return fmt.Errorf("client %s: %s is set through %s, not env", name, key, field)
+			}
+		}
+		sites := map[string]bool{}
+		for _, s := range c.FrappeSites {
+			if s.Name == "" {
+				return fmt.Errorf("client %s: frappe site without a name", name)
+			}
+			if sites[s.Name] {
+				return fmt.Errorf("client %s: frappe site %s listed twice", name, s.Name)
+			}
+			sites[s.Name] = true
+			if _, err := frappeVersion(s.Version); err != nil {
+				return fmt.Errorf("client %s: frappe site %s: %v", name, s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m nodeManifest) clientNames() []string {
+	names := make([]string, 0, len(m.Clients))
+	for name := range m.Clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// manifestChange is one step apply takes to converge a client.
+type manifestChange struct {
+	Client string
+	Kind   string // create, set, unset, enable, disable, arm, disarm, frappe
+	Key    string
+	From   string
+	To     string
+
+	// Create and frappe steps carry what they need to run.
+	Domains []string
+	Site    manifestFrappeSite
+}
+
+// planManifest diffs the manifest against the clients on disk and returns
+// the changes apply would make, in the order it makes them.
+func planManifest(m nodeManifest, prune bool) ([]manifestChange, error) {
+	store := repo.New(ProjectRoot())
+	frappe := provisionedFrappeSites()
+
+	ports := &portAllocator{used: map[int]bool{}}
+	owner := map[int]string{}
+	for _, name := range existingClients() {
+		port, _ := strconv.Atoi(envVal(clientEnvFile(name), "PORT"))
+		if c, ok := m.Clients[name]; ok && c.Port != 0 {
+			port = c.Port
+		}
+		if port > 0 {
+			if other, ok := owner[port]; ok {
+				return nil, fmt.Errorf("clients %s and %s would both use port %d", other, name, port)
+			}
+			owner[port] = name
+			ports.take(port)
+		}
+	}
+	for _, name := range m.clientNames() {
+		if port := m.Clients[name].Port; port != 0 && !clientExists(name) {
+			if other, ok := owner[port]; ok {
+				return nil, fmt.Errorf("client %s: port %d is already used by %s", name, port, other)
+			}
+			owner[port] = name
+			ports.take(port)
+		}
+	}
+
+	var changes []manifestChange
+	for _, name := range m.clientNames() {
+		c := m.Clients[name]
+		exists := clientExists(name)
+		current := map[string]string{}
+		if exists {
+			current = loadEnvMap(clientEnvFile(name))
+		}
+
+		if !exists {
+			port := c.Port
+			if port == 0 {
+				p, _ := ports.resolve("", "")
+				port, _ = strconv.Atoi(p)
+			}
+			domains := c.Domains
+			if len(domains) == 0 {
+				domains = []string{"localhost"}
+			}
+			changes = append(changes, manifestChange{Client: name, Kind: "create", To: strconv.Itoa(port), Domains: domains})
+			// The new .env already carries port and domains.
+			c.Port, c.Domains = 0, nil
+		}
+
+		want := map[string]*string{}
+		for k, v := range c.Env {
+			want[k] = v
+		}
+		if c.Port != 0 {
+			p := strconv.Itoa(c.Port)
+			want["PORT"] = &p
+		}
+		if len(c.Domains) > 0 {
+			derived, _ := domainEnvValues(c.Domains)
+			for k, v := range derived {
+				want[k] = &v
+			}
+		}
+		keys := make([]string, 0, len(want))
+		for k := range want {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			cur, has := current[key]
+			switch v := want[key]; {
+			case v == nil && has:
+				changes = append(changes, manifestChange{Client: name, Kind: "unset", Key: key, From: cur})
+			case v != nil && (!has || cur != *v):
+				changes = append(changes, manifestChange{Client: name, Kind: "set", Key: key, From: cur, To: *v})
+			}
+		}
+
+		enabled := !exists || clientEnabled(name)
+		if c.Enabled != nil && *c.Enabled != enabled {
+			kind := "disable"
+			if *c.Enabled {
+				kind = "enable"
+			}
+			changes = append(changes, manifestChange{Client: name, Kind: kind})
+		}
+		armed := exists && store.IsSiteArmed(name)
+		if c.Armed != nil && *c.Armed != armed {
+			kind := "disarm"
+			if *c.Armed {
+				kind = "arm"
+			}
+			changes = append(changes, manifestChange{Client: name, Kind: kind})
+		}
+		for _, s := range c.FrappeSites {
+			if !frappe[s.Name] {
+				changes = append(changes, manifestChange{Client: name, Kind: "frappe", Site: s})
+			}
+		}
+	}
+
+	if prune {
+		for _, name := range existingClients() {
+			if _, ok := m.Clients[name]; !ok && clientEnabled(name) {
+				changes = append(changes, manifestChange{Client: name, Kind: "disable", From: "not in manifest"})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// existingClients lists the client directories that have a .env file.
+func existingClients() []string {
+	var names []string
+	entries, err := os.ReadDir(backendsDir())
+	if err != nil {
+		return names
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(clientEnvFile(e.Name())); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// provisionedFrappeSites returns the sites recorded in the local Frappe
+// clusters' sites.json files.
+func provisionedFrappeSites() map[string]bool {
+	sites := map[string]bool{}
+	matches, _ := filepath.Glob(filepath.Join("/tmp/skaia/frappe", "cluster_*", "sites.json"))
+	for _, path := range matches {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var names []string
+		if json.Unmarshal(data, &names) == nil {
+			for _, n := range names {
+				sites[n] = true
+			}
+		}
+	}
+	return sites
+}
+
+// secretEnvKey reports whether a value should be masked in plan output.
+func secretEnvKey(key string) bool {
+	for _, marker := range []string{"SECRET", "PASSWORD", "TOKEN", "KEY"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c manifestChange) String() string {
+	shown := func(v string) string {
+		if secretEnvKey(c.Key) && v != "" {
+			return "(secret)"
+		}
+		return fmt.Sprintf("%q", v)
+	}
+	switch c.Kind {
+	case "create":
+		return fmt.Sprintf("+ create on port %s (%s)", c.To, strings.Join(c.Domains, " "))
+	case "set":
+		if c.From == "" {
+			return fmt.Sprintf("+ %s = %s", c.Key, shown(c.To))
+		}
+		return fmt.Sprintf("~ %s: %s -> %s", c.Key, shown(c.From), shown(c.To))
+	case "unset":
+		return fmt.Sprintf("- %s (was %s)", c.Key, shown(c.From))
+	case "frappe":
+		return fmt.Sprintf("+ frappe site %s (version %s)", c.Site.Name, orDash(c.Site.Version))
+	case "disable":
+		if c.From != "" {
+			return "~ disable (" + c.From + ")"
+		}
+	}
+	return "~ " + c.Kind
+}
+
+func printManifestPlan(changes []manifestChange) {
+	if len(changes) == 0 {
+		log("Node matches the manifest - nothing to do")
+		return
+	}
+	last := ""
+	for _, c := range changes {
+		if c.Client != last {
+			fmt.Printf("\n%s%s%s\n", colorBold, c.Client, colorReset)
+			last = c.Client
+		}
+		fmt.Printf("  %s\n", c)
+	}
+	fmt.Printf("\n%d change(s)\n", len(changes))
+}
+
+// cmdPlan prints what 'grengo apply' would change.
+func cmdPlan(path string, prune bool) {
+	if path == "" {
+		path = defaultManifestPath()
+	}
+	m, err := loadManifest(path)
+	if err != nil {
+		die("%v", err)
+	}
+	changes, err := planManifest(m, prune)
+	if err != nil {
+		die("%v", err)
+	}
+	printManifestPlan(changes)
+}
+
+// cmdApply converges the node to the manifest. Running clients whose .env
+// changed are recreated so the new values take effect.
+func cmdApply(path string, prune bool) {
+	if path == "" {
+		path = defaultManifestPath()
+	}
+	m, err := loadManifest(path)
+	if err != nil {
+		die("%v", err)
+	}
+	changes, err := planManifest(m, prune)
+	if err != nil {
+		die("%v", err)
+	}
+	printManifestPlan(changes)
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Println()
+
+	store := repo.New(ProjectRoot())
+	runner := services.NewCommandRunner(ProjectRoot())
+	envChanged := map[string]bool{}
+	var created []string
+	for _, c := range changes {
+		switch c.Kind {
+		case "create":
+			args := []string{"new", c.Client, "--port", c.To}
+			for _, d := range c.Domains {
+				args = append(args, "--domain", d)
+			}
+			result, err := runner.RunSelfStream(os.Stdout, args...)
+			if err == nil && result.ExitCode != 0 {
+				err = fmt.Errorf("exit code %d", result.ExitCode)
+			}
+			if err != nil {
+				die("Creating %s failed: %v", c.Client, err)
+			}
+			created = append(created, c.Client)
+		case "set":
+			if err := setEnvVal(clientEnvFile(c.Client), c.Key, c.To); err != nil {
+				die("Cannot update .env for %s: %v", c.Client, err)
+			}
+			envChanged[c.Client] = true
+		case "unset":
+			if _, err := removeEnvVals(clientEnvFile(c.Client), c.Key); err != nil {
+				die("Cannot update .env for %s: %v", c.Client, err)
+			}
+			envChanged[c.Client] = true
+		case "enable":
+			os.Remove(filepath.Join(clientDir(c.Client), ".disabled"))
+		case "disable":
+			if err := os.WriteFile(filepath.Join(clientDir(c.Client), ".disabled"), []byte{}, 0644); err != nil {
+				die("Cannot create .disabled file: %v", err)
+			}
+			if clientRunning(c.Client) {
+				info("Stopping %s…", c.Client)
+				dockerComposeSilent(clientComposeFile(c.Client), "down")
+			}
+		case "arm":
+			if err := store.ArmSite(c.Client, time.Now()); err != nil {
+				die("Cannot arm %s: %v", c.Client, err)
+			}
+		case "disarm":
+			if err := store.DisarmSite(c.Client); err != nil {
+				die("Cannot disarm %s: %v", c.Client, err)
+			}
+		case "frappe":
+			args := []string{"frappe-provision", c.Site.Name}
+			if c.Site.Version != "" {
+				args = append(args, "--version", c.Site.Version)
+			}
+			result, err := runner.RunSelfStream(os.Stdout, args...)
+			if err == nil && result.ExitCode != 0 {
+				err = fmt.Errorf("exit code %d", result.ExitCode)
+			}
+			if err != nil {
+				die("Provisioning Frappe site %s failed: %v", c.Site.Name, err)
+			}
+		}
+	}
+
+	generateNginxConfig()
+	reloadNginxIfRunning()
+	for _, name := range m.clientNames() {
+		if envChanged[name] && clientEnabled(name) && clientRunning(name) {
+			log("Recreating %s with its new environment…", name)
+			if err := dockerCompose(clientComposeFile(name), "up", "-d", "--force-recreate"); err != nil {
+				warn("Failed to recreate %s: %v", name, err)
+			}
+		}
+	}
+	log("Applied %d change(s) from %s", len(changes), path)
+	if len(created) > 0 {
+		info("Start new clients with: grengo start <name> (%s)", strings.Join(created, ", "))
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "grengo.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadManifestValidates(t *testing.T) {
+	tests := []struct {
+		name, yaml, want string
+	}{
+		{"bad name", "clients:\n  Shop: {}\n", "client \"Shop\""},
+		{"duplicate port", "clients:\n  a: {port: 8081}\n  b: {port: 8081}\n", "both use port 8081"},
+		{"managed key", "clients:\n  a:\n    env: {PORT: \"1\"}\n", "PORT is set through port"},
+		{"unknown field", "clients:\n  a: {domain: x}\n", "field domain not found"},
+		{"frappe version", "clients:\n  a:\n    frappe_sites: [{name: erp, version: \"9\"}]\n", "unsupported Frappe version"},
+	}
+	for _, tt := range tests {
+		_, err := loadManifest(writeManifest(t, tt.yaml))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestPlanManifest(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	writeTestClient(t, "shop", "8081", "DOMAINS=shop.example.com\nFEATURES_ENABLED=store\nSMTP_HOST=mail\n")
+	writeTestClient(t, "old", "8082", "")
+	os.WriteFile(filepath.Join(clientDir("shop"), ".disabled"), nil, 0644)
+
+	m, err := loadManifest(writeManifest(t, `
+clients:
+  shop:
+    enabled: true
+    armed: true
+    env:
+      FEATURES_ENABLED: store,cart
+      STRIPE_SECRET_KEY: sk_live
+      SMTP_HOST: null
+  blog:
+    domains: [blog.example.com]
+    enabled: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := planManifest(m, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Client+" "+c.String())
+	}
+	want := []string{
+		"blog + create on port 8083 (blog.example.com)",
+		"blog ~ disable",
+		`shop ~ FEATURES_ENABLED: "store" -> "store,cart"`,
+		"shop - SMTP_HOST (was \"mail\")",
+		"shop + STRIPE_SECRET_KEY = (secret)",
+		"shop ~ enable",
+		"shop ~ arm",
+		"old ~ disable (not in manifest)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("plan:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A port change that collides with an unmanaged client is refused.
+	m.Clients["shop"] = manifestClient{Port: 8082}
+	if _, err := planManifest(m, false); err == nil || !strings.Contains(err.Error(), "port 8082") {
+		t.Fatalf("port collision: err = %v", err)
+	}
+}
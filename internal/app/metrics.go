@@ -0,0 +1,298 @@
+package app
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// metricsPort is the port /metrics is served on, GRENGO_METRICS_PORT or 9103.
+func metricsPort() int {
+	if p, err := strconv.Atoi(envVal(rootEnvFile(), "GRENGO_METRICS_PORT")); err == nil && p > 0 {
+		return p
+	}
+	return DefaultAPIPort + 3
+}
+
+// serveMetrics runs the OpenMetrics listener next to the gRPC API. Like the
+// API it binds to all interfaces so a scraper on the Docker network can
+// reach it; GRENGO_METRICS_TOKEN adds bearer authentication.
+func serveMetrics() *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", apiMetrics)
+	srv := &http.Server{
+		Addr:              net.JoinHostPort("0.0.0.0", strconv.Itoa(metricsPort())),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			warn("Metrics listener on %s failed: %v", srv.Addr, err)
+		}
+	}()
+	return srv
+}
+
+// apiMetrics writes the node's metrics in the OpenMetrics text format.
+func apiMetrics(w http.ResponseWriter, r *http.Request) {
+	if token := envVal(rootEnvFile(), "GRENGO_METRICS_TOKEN"); token != "" {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="grengo"`)
+			apiError(w, http.StatusUnauthorized, "invalid metrics token")
+			return
+		}
+	}
+	var m metricsWriter
+	writeContainerMetrics(&m, gatherStats())
+	writeStorageMetrics(&m, gatherStorage())
+	writeJobMetrics(&m)
+	writeFrappeMetrics(&m)
+	writeNginxMetrics(&m, loadNginxReloadStatus())
+	writeAlertMetrics(&m, alerts)
+	w.Header().Set("Content-Type", openMetricsContentType)
+	io.WriteString(w, m.finish())
+}
+
+// metricsWriter builds an OpenMetrics exposition. Samples must follow the
+// family they belong to.
+type metricsWriter struct {
+	b strings.Builder
+}
+
+func (m *metricsWriter) family(name, typ, unit, help string) {
+	fmt.Fprintf(&m.b, "# TYPE %s %s\n", name, typ)
+	if unit != "" {
+		fmt.Fprintf(&m.b, "# UNIT %s %s\n", name, unit)
+	}
+	fmt.Fprintf(&m.b, "# HELP %s %s\n", name, help)
+}
+
+// sample writes one value; labels alternate between names and values.
+func (m *metricsWriter) sample(name string, value float64, labels ...string) {
+	m.b.WriteString(name)
+	if len(labels) > 0 {
+		m.b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.b.WriteByte(',')
+			}
+			fmt.Fprintf(&m.b, `%s="%s"`, labels[i], escapeLabelValue(labels[i+1]))
+		}
+		m.b.WriteByte('}')
+	}
+	m.b.WriteByte(' ')
+	m.b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	m.b.WriteByte('\n')
+}
+
+func (m *metricsWriter) finish() string {
+	m.b.WriteString("# EOF\n")
+	return m.b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func writeContainerMetrics(m *metricsWriter, stats []containerStats) {
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	families := []struct {
+		name, typ, unit, help string
+		value                 func(containerStats) float64
+	}{
+		{"grengo_container_cpu_percent", "gauge", "", "CPU usage since the previous sample, 100 per core.", func(s containerStats) float64 { return s.CPU }},
+		{"grengo_container_cpu_seconds", "counter", "seconds", "CPU time consumed by the container.", func(s containerStats) float64 { return s.CPUSeconds }},
+		{"grengo_container_memory_bytes", "gauge", "bytes", "Memory in use, excluding page cache.", func(s containerStats) float64 { return float64(s.MemBytes) }},
+		{"grengo_container_memory_limit_bytes", "gauge", "bytes", "Memory limit of the container.", func(s containerStats) float64 { return float64(s.MemMax) }},
+		{"grengo_container_network_receive_bytes", "counter", "bytes", "Bytes received on all interfaces.", func(s containerStats) float64 { return float64(s.NetRx) }},
+		{"grengo_container_network_transmit_bytes", "counter", "bytes", "Bytes sent on all interfaces.", func(s containerStats) float64 { return float64(s.NetTx) }},
+		{"grengo_container_block_read_bytes", "counter", "bytes", "Bytes read from block devices.", func(s containerStats) float64 { return float64(s.BlockRead) }},
+		{"grengo_container_block_write_bytes", "counter", "bytes", "Bytes written to block devices.", func(s containerStats) float64 { return float64(s.BlockWrite) }},
+		{"grengo_container_pids", "gauge", "", "Processes running in the container.", func(s containerStats) float64 { return float64(s.PIDs) }},
+	}
+	for _, f := range families {
+		m.family(f.name, f.typ, f.unit, f.help)
+		name := f.name
+		if f.typ == "counter" {
+			name += "_total"
+		}
+		for _, s := range stats {
+			m.sample(name, f.value(s), "container", s.Name)
+		}
+	}
+}
+
+func writeStorageMetrics(m *metricsWriter, storage *storageInfo) {
+	m.family("grengo_client_storage_used_bytes", "gauge", "bytes", "Upload storage used by the client.")
+	for _, s := range storage.Sites {
+		m.sample("grengo_client_storage_used_bytes", float64(s.Used), "client", s.Name)
+	}
+	m.family("grengo_client_storage_limit_bytes", "gauge", "bytes", "Upload storage limit reported by the client.")
+	for _, s := range storage.Sites {
+		if s.Limit > 0 {
+			m.sample("grengo_client_storage_limit_bytes", float64(s.Limit), "client", s.Name)
+		}
+	}
+}
+
+// writeJobMetrics counts the API's jobs by state. Jobs live in memory, so the
+// counts restart with the API.
+func writeJobMetrics(m *metricsWriter) {
+	counts := map[string]int{"running": 0, "completed": 0, "failed": 0}
+	jobsMu.RLock()
+	for _, j := range jobs {
+		counts[j.Status]++
+	}
+	jobsMu.RUnlock()
+	states := make([]string, 0, len(counts))
+	for state := range counts {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	m.family("grengo_jobs", "gauge", "", "Jobs started since the API came up, by state.")
+	for _, state := range states {
+		m.sample("grengo_jobs", float64(counts[state]), "state", state)
+	}
+}
+
+func writeFrappeMetrics(m *metricsWriter) {
+	clusters, err := newGrengoService().ListFrappeClusters()
+	m.family("grengo_management_db_up", "gauge", "", "Whether the grengo management database answered.")
+	if err != nil {
+		m.sample("grengo_management_db_up", 0)
+		return
+	}
+	m.sample("grengo_management_db_up", 1)
+	for _, f := range []struct {
+		name, help string
+		value      func(frappeCluster) int
+	}{
+		{"grengo_frappe_cluster_capacity", "Sites a Frappe cluster can hold.", func(c frappeCluster) int { return c.Capacity }},
+		{"grengo_frappe_cluster_sites", "Sites allocated to a Frappe cluster.", func(c frappeCluster) int { return c.SiteCount }},
+	} {
+		m.family(f.name, "gauge", "", f.help)
+		for _, c := range clusters {
+			m.sample(f.name, float64(f.value(c)), "version", c.Version, "cluster", strconv.Itoa(c.ClusterIndex), "container", c.ContainerName, "status", c.Status)
+		}
+	}
+}
+
+func writeNginxMetrics(m *metricsWriter, st nginxReloadStatus) {
+	m.family("grengo_nginx_reloads", "counter", "", "nginx reloads run by grengo, by result.")
+	m.sample("grengo_nginx_reloads_total", float64(st.Failures), "result", "failure")
+	m.sample("grengo_nginx_reloads_total", float64(st.Successes), "result", "success")
+	m.family("grengo_nginx_last_reload_timestamp_seconds", "gauge", "seconds", "Time of the last nginx reload, by result.")
+	for _, r := range []struct {
+		result string
+		at     time.Time
+	}{{"failure", st.LastFailure}, {"success", st.LastSuccess}} {
+		if !r.at.IsZero() {
+			m.sample("grengo_nginx_last_reload_timestamp_seconds", float64(r.at.UnixMilli())/1000, "result", r.result)
+		}
+	}
+	if !st.LastSuccess.IsZero() || !st.LastFailure.IsZero() {
+		ok := 0.0
+		if st.LastSuccess.After(st.LastFailure) {
+			ok = 1
+		}
+		m.family("grengo_nginx_last_reload_success", "gauge", "", "Whether the most recent nginx reload succeeded.")
+		m.sample("grengo_nginx_last_reload_success", ok)
+	}
+}
+
+// writeAlertMetrics exports the filesystem grengo lives on and what the
+// alert loop last saw.
+func writeAlertMetrics(m *metricsWriter, e *alertEngine) {
+	if d, err := statDisk(ProjectRoot()); err == nil {
+		m.family("grengo_filesystem_used_bytes", "gauge", "bytes", "Used space on the filesystem holding the project root.")
+		m.sample("grengo_filesystem_used_bytes", float64(d.Used), "path", d.Path)
+		m.family("grengo_filesystem_avail_bytes", "gauge", "bytes", "Space left for grengo on that filesystem.")
+		m.sample("grengo_filesystem_avail_bytes", float64(d.Avail), "path", d.Path)
+	}
+
+	probes := e.lastProbes()
+	targets := make([]string, 0, len(probes))
+	for target := range probes {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	m.family("grengo_site_up", "gauge", "", "Whether a Frappe site or client answered the last alert check.")
+	for _, target := range targets {
+		up := 0.0
+		if probes[target] {
+			up = 1
+		}
+		m.sample("grengo_site_up", up, "site", target)
+	}
+
+	m.family("grengo_alerts_firing", "gauge", "", "Firing alerts by rule and target.")
+	for _, a := range e.firing() {
+		m.sample("grengo_alerts_firing", 1, "rule", a.Rule, "severity", a.Severity, "target", a.Target)
+	}
+}
+
+// nginxReloadStatus tallies reloadNginxIfRunning outcomes. Reloads happen in
+// CLI processes as well as the API, so the tally is kept on disk.
+type nginxReloadStatus struct {
+	Successes   int64     `json:"successes"`
+	Failures    int64     `json:"failures"`
+	LastSuccess time.Time `json:"last_success"`
+	LastFailure time.Time `json:"last_failure"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+func nginxReloadStatusFile() string { return filepath.Join(nginxDir(), "reload-status.json") }
+
+func loadNginxReloadStatus() nginxReloadStatus {
+	var st nginxReloadStatus
+	if data, err := os.ReadFile(nginxReloadStatusFile()); err == nil {
+		json.Unmarshal(data, &st)
+	}
+	return st
+}
+
+// recordNginxReload adds one reload outcome to the tally. It never fails the
+// caller: a lost sample is better than a failed deploy.
+func recordNginxReload(reloadErr error) {
+	os.MkdirAll(nginxDir(), 0755)
+	f, err := os.OpenFile(nginxReloadStatusFile(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return
+	}
+	var st nginxReloadStatus
+	if data, err := io.ReadAll(f); err == nil && len(data) > 0 {
+		json.Unmarshal(data, &st)
+	}
+	if reloadErr != nil {
+		st.Failures++
+		st.LastFailure = time.Now().UTC()
+		st.LastError = reloadErr.Error()
+	} else {
+		st.Successes++
+		st.LastSuccess = time.Now().UTC()
+	}
+	data, _ := json.Marshal(st)
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt(data, 0)
+	}
+}
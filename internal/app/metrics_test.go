@@ -0,0 +1,113 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsWriter(t *testing.T) {
+	var m metricsWriter
+	writeContainerMetrics(&m, []containerStats{
+		{Name: "shop-backend", CPU: 12.5, CPUSeconds: 3.25, MemBytes: 1 << 20, NetRx: 42},
+		{Name: "skaia-nginx", PIDs: 3},
+	})
+	m.family("grengo_test", "gauge", "", "Escaping.")
+	m.sample("grengo_test", 1, "path", `C:\a "b"`+"\n")
+	out := m.finish()
+
+	for _, expected := range []string{
+		"# TYPE grengo_container_cpu_seconds counter\n# UNIT grengo_container_cpu_seconds seconds\n",
+		`grengo_container_cpu_seconds_total{container="shop-backend"} 3.25`,
+		`grengo_container_cpu_percent{container="shop-backend"} 12.5`,
+		`grengo_container_memory_bytes{container="shop-backend"} 1.048576e+06`,
+		`grengo_container_network_receive_bytes_total{container="shop-backend"} 42`,
+		`grengo_container_pids{container="skaia-nginx"} 3`,
+		`grengo_test{path="C:\\a \"b\"\n"} 1`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("output missing %q:\n%s", expected, out)
+		}
+	}
+	if !strings.HasSuffix(out, "\n# EOF\n") {
+		t.Fatal("exposition not terminated with # EOF")
+	}
+}
+
+func TestRecordNginxReload(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	if st := loadNginxReloadStatus(); st.Successes != 0 || st.Failures != 0 {
+		t.Fatalf("fresh status = %+v", st)
+	}
+	recordNginxReload(nil)
+	recordNginxReload(errors.New("exit status 1"))
+	recordNginxReload(nil)
+
+	st := loadNginxReloadStatus()
+	if st.Successes != 2 || st.Failures != 1 || st.LastError != "exit status 1" {
+		t.Fatalf("status = %+v", st)
+	}
+	var m metricsWriter
+	writeNginxMetrics(&m, st)
+	out := m.finish()
+	for _, expected := range []string{
+		`grengo_nginx_reloads_total{result="failure"} 1`,
+		`grengo_nginx_reloads_total{result="success"} 2`,
+		"grengo_nginx_last_reload_success 1",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("output missing %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestAPIMetrics(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GRENGO_ROOT", root)
+	os.WriteFile(filepath.Join(root, ".env"), []byte("GRENGO_METRICS_TOKEN=scrape-me\n"), 0644)
+
+	jobsMu.Lock()
+	saved := jobs
+	jobs = map[string]*jobStatus{
+		"a": {ID: "a", Status: "running", CreatedAt: time.Now()},
+		"b": {ID: "b", Status: "failed", CreatedAt: time.Now()},
+		"c": {ID: "c", Status: "failed", CreatedAt: time.Now()},
+	}
+	jobsMu.Unlock()
+	t.Cleanup(func() {
+		jobsMu.Lock()
+		jobs = saved
+		jobsMu.Unlock()
+	})
+
+	rec := httptest.NewRecorder()
+	apiMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("request without token answered %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-me")
+	rec = httptest.NewRecorder()
+	apiMetrics(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != openMetricsContentType {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	out := rec.Body.String()
+	for _, expected := range []string{
+		`grengo_jobs{state="completed"} 0`,
+		`grengo_jobs{state="failed"} 2`,
+		`grengo_jobs{state="running"} 1`,
+		"# TYPE grengo_client_storage_used_bytes gauge",
+		"# TYPE grengo_nginx_reloads counter",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("output missing %q:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS grengo_backup_schedules (
+  id BIGSERIAL PRIMARY KEY,
+  site_name TEXT NOT NULL,
+  cron_expr TEXT NOT NULL,
+  keep_daily INTEGER NOT NULL DEFAULT 7 CHECK (keep_daily >= 0),
+  keep_weekly INTEGER NOT NULL DEFAULT 4 CHECK (keep_weekly >= 0),
+  keep_monthly INTEGER NOT NULL DEFAULT 6 CHECK (keep_monthly >= 0),
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  last_run_at TIMESTAMPTZ,
+  last_status TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grengo_backup_schedules_site
+  ON grengo_backup_schedules (site_name) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS grengo_backup_runs (
+  id BIGSERIAL PRIMARY KEY,
+  schedule_id BIGINT REFERENCES grengo_backup_schedules(id) ON DELETE RESTRICT,
+  site_name TEXT NOT NULL,
+  job_id TEXT NOT NULL,
+  archive_name TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'running',
+  error TEXT,
+  pruned_at TIMESTAMPTZ,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_grengo_backup_runs_site
+  ON grengo_backup_runs (site_name, started_at DESC);
+
+DO $$
+DECLARE
+  table_name TEXT;
+BEGIN
+  FOREACH table_name IN ARRAY ARRAY['grengo_backup_schedules','grengo_backup_runs']
+  LOOP
+    EXECUTE format('DROP TRIGGER IF EXISTS grengo_reject_hard_delete ON %I', table_name);
+    EXECUTE format(
+      'CREATE TRIGGER grengo_reject_hard_delete BEFORE DELETE ON %I FOR EACH ROW EXECUTE FUNCTION reject_grengo_hard_delete()',
+      table_name
+    );
+  END LOOP;
+END
+$$;
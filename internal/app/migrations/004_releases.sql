@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS grengo_releases (
+  id BIGSERIAL PRIMARY KEY,
+  site_name TEXT NOT NULL,
+  image_id TEXT NOT NULL DEFAULT '',
+  image_ref TEXT NOT NULL DEFAULT '',
+  frontend_hash TEXT NOT NULL DEFAULT '',
+  migration_version TEXT NOT NULL DEFAULT '',
+  db_backup TEXT NOT NULL DEFAULT '',
+  triggered_by TEXT NOT NULL,
+  rolled_back_to BIGINT REFERENCES grengo_releases(id) ON DELETE RESTRICT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_grengo_releases_site
+  ON grengo_releases (site_name, id DESC) WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS grengo_reject_hard_delete ON grengo_releases;
+CREATE TRIGGER grengo_reject_hard_delete BEFORE DELETE ON grengo_releases
+  FOR EACH ROW EXECUTE FUNCTION reject_grengo_hard_delete();
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS grengo_webhook_deliveries (
+  id BIGSERIAL PRIMARY KEY,
+  provider TEXT NOT NULL,
+  delivery_id TEXT NOT NULL DEFAULT '',
+  event TEXT NOT NULL DEFAULT '',
+  site_name TEXT NOT NULL DEFAULT '',
+  ref TEXT NOT NULL DEFAULT '',
+  commit_sha TEXT NOT NULL DEFAULT '',
+  pusher TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  detail TEXT NOT NULL DEFAULT '',
+  targets TEXT NOT NULL DEFAULT '',
+  job_id TEXT NOT NULL DEFAULT '',
+  received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_grengo_webhook_deliveries_received
+  ON grengo_webhook_deliveries (received_at DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_grengo_webhook_deliveries_delivery
+  ON grengo_webhook_deliveries (provider, delivery_id) WHERE delivery_id <> '';
+
+DROP TRIGGER IF EXISTS grengo_reject_hard_delete ON grengo_webhook_deliveries;
+CREATE TRIGGER grengo_reject_hard_delete BEFORE DELETE ON grengo_webhook_deliveries
+  FOR EACH ROW EXECUTE FUNCTION reject_grengo_hard_delete();
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS grengo_site_quotas (
+  id BIGSERIAL PRIMARY KEY,
+  site_name TEXT NOT NULL,
+  cpu_shares INTEGER NOT NULL DEFAULT 0 CHECK (cpu_shares >= 0),
+  memory_mb BIGINT NOT NULL DEFAULT 0 CHECK (memory_mb >= 0),
+  pids_limit INTEGER NOT NULL DEFAULT 0 CHECK (pids_limit >= 0),
+  uploads_mb BIGINT NOT NULL DEFAULT 0 CHECK (uploads_mb >= 0),
+  db_mb BIGINT NOT NULL DEFAULT 0 CHECK (db_mb >= 0),
+  enforce TEXT NOT NULL DEFAULT 'arm' CHECK (enforce IN ('warn', 'arm', 'disable')),
+  state TEXT NOT NULL DEFAULT 'ok',
+  over_since TIMESTAMPTZ,
+  uploads_used BIGINT NOT NULL DEFAULT 0,
+  db_used BIGINT NOT NULL DEFAULT 0,
+  checked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grengo_site_quotas_site
+  ON grengo_site_quotas (site_name) WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS grengo_reject_hard_delete ON grengo_site_quotas;
+CREATE TRIGGER grengo_reject_hard_delete BEFORE DELETE ON grengo_site_quotas
+  FOR EACH ROW EXECUTE FUNCTION reject_grengo_hard_delete();
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS grengo_alerts (
+  id BIGSERIAL PRIMARY KEY,
+  rule TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  target TEXT NOT NULL DEFAULT '',
+  severity TEXT NOT NULL DEFAULT 'warning',
+  summary TEXT NOT NULL DEFAULT '',
+  state TEXT NOT NULL DEFAULT 'firing' CHECK (state IN ('firing', 'resolved')),
+  started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  notified_at TIMESTAMPTZ,
+  resolved_at TIMESTAMPTZ,
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_grengo_alerts_firing
+  ON grengo_alerts (rule, target) WHERE state = 'firing' AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_grengo_alerts_started
+  ON grengo_alerts (started_at DESC) WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS grengo_reject_hard_delete ON grengo_alerts;
+CREATE TRIGGER grengo_reject_hard_delete BEFORE DELETE ON grengo_alerts
+  FOR EACH ROW EXECUTE FUNCTION reject_grengo_hard_delete();
+
+CREATE TABLE IF NOT EXISTS grengo_alert_silences (
+  id BIGSERIAL PRIMARY KEY,
+  rule TEXT NOT NULL DEFAULT '*',
+  target TEXT NOT NULL DEFAULT '*',
+  comment TEXT NOT NULL DEFAULT '',
+  starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  ends_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_grengo_alert_silences_active
+  ON grengo_alert_silences (ends_at) WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS grengo_reject_hard_delete ON grengo_alert_silences;
+CREATE TRIGGER grengo_reject_hard_delete BEFORE DELETE ON grengo_alert_silences
+  FOR EACH ROW EXECUTE FUNCTION reject_grengo_hard_delete();
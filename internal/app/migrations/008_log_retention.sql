@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS grengo_log_retention (
+  id BIGSERIAL PRIMARY KEY,
+  site_name TEXT NOT NULL,
+  max_age_days INTEGER NOT NULL CHECK (max_age_days > 0),
+  max_mb BIGINT NOT NULL CHECK (max_mb > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grengo_log_retention_site
+  ON grengo_log_retention (site_name) WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS grengo_reject_hard_delete ON grengo_log_retention;
+CREATE TRIGGER grengo_reject_hard_delete BEFORE DELETE ON grengo_log_retention
+  FOR EACH ROW EXECUTE FUNCTION reject_grengo_hard_delete();
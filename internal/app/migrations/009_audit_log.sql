@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS grengo_audit_log (
+  id BIGSERIAL PRIMARY KEY,
+  at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  actor TEXT NOT NULL DEFAULT '',
+  api_key_prefix TEXT NOT NULL DEFAULT '',
+  source_ip TEXT NOT NULL DEFAULT '',
+  via TEXT NOT NULL CHECK (via IN ('grpc', 'http', 'ws', 'cli')),
+  command TEXT NOT NULL,
+  target TEXT NOT NULL DEFAULT '',
+  args JSONB NOT NULL DEFAULT '{}',
+  result TEXT NOT NULL CHECK (result IN ('ok', 'error', 'denied')),
+  error TEXT NOT NULL DEFAULT '',
+  duration_ms BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_grengo_audit_log_at ON grengo_audit_log (at);
+CREATE INDEX IF NOT EXISTS idx_grengo_audit_log_target ON grengo_audit_log (target, at);
+
+-- The audit log is append-only: rows are never updated or deleted, not even
+-- by the hard-delete operator role.
+CREATE OR REPLACE FUNCTION reject_grengo_audit_change() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  RAISE EXCEPTION '% on % rejected: the audit log is append-only', TG_OP, TG_TABLE_NAME
+    USING ERRCODE='42501';
+END
+$$;
+
+DROP TRIGGER IF EXISTS grengo_audit_append_only ON grengo_audit_log;
+CREATE TRIGGER grengo_audit_append_only BEFORE UPDATE OR DELETE ON grengo_audit_log
+  FOR EACH ROW EXECUTE FUNCTION reject_grengo_audit_change();
+
+DROP TRIGGER IF EXISTS grengo_audit_no_truncate ON grengo_audit_log;
+CREATE TRIGGER grengo_audit_no_truncate BEFORE TRUNCATE ON grengo_audit_log
+  FOR EACH STATEMENT EXECUTE FUNCTION reject_grengo_audit_change();
@@ -0,0 +1,3 @@
+ALTER TABLE grengo_api_keys
+  ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS rotated_to BIGINT REFERENCES grengo_api_keys(id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS grengo_operators (
+  id BIGSERIAL PRIMARY KEY,
+  username TEXT NOT NULL,
+  password_hash TEXT NOT NULL,
+  role TEXT NOT NULL CHECK (role IN ('viewer', 'deployer', 'admin')),
+  totp_secret TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_login_at TIMESTAMPTZ,
+  disabled_at TIMESTAMPTZ,
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grengo_operators_username
+  ON grengo_operators (username) WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS grengo_reject_hard_delete ON grengo_operators;
+CREATE TRIGGER grengo_reject_hard_delete BEFORE DELETE ON grengo_operators
+  FOR EACH ROW EXECUTE FUNCTION reject_grengo_hard_delete();
@@ -147,23 +147,13 @@ func cmdNew(args []string) {
 	// Auto-generated values
 	jwtSecret := generateSecret(32)
 
-	domainList := strings.Join(domains, " ")
-	publicBaseURL, err := canonicalPublicBase(domains)
+	domainEnv, err := domainEnvValues(domains)
 	if err != nil {
-		die("Invalid primary domain: %v", err)
+		die("%v", err)
 	}
-	var corsParts []string
-	for _, d := range domains {
-		host, domainErr := normalizedDomainHost(d)
-		if domainErr != nil {
-			die("Invalid domain: %v", domainErr)
-		}
-		corsParts = append(corsParts, "https://"+host)
-		if isLocalDomain(host) {
-			corsParts = append(corsParts, "http://"+host)
-		}
-	}
-	corsOrigins := strings.Join(corsParts, ",")
+	domainList := domainEnv["DOMAINS"]
+	corsOrigins := domainEnv["CORS_ORIGINS"]
+	publicBaseURL := domainEnv["PUBLIC_BASE_URL"]
 
 	env := loadSharedEnv()
 
@@ -291,3 +281,29 @@ networks:
 	info("  1. grengo build          # first time only")
 	info("  2. grengo compose up     # starts everything")
 }
+
+// domainEnvValues derives the .env keys that follow from a client's domain
+// list: DOMAINS, CORS_ORIGINS and the canonical public URLs.
+func domainEnvValues(domains []string) (map[string]string, error) {
+	publicBaseURL, err := canonicalPublicBase(domains)
+	if err != nil {
+		return nil, fmt.Errorf("invalid primary domain: %v", err)
+	}
+	var corsParts []string
+	for _, d := range domains {
+		host, err := normalizedDomainHost(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid domain: %v", err)
+		}
+		corsParts = append(corsParts, "https://"+host)
+		if isLocalDomain(host) {
+			corsParts = append(corsParts, "http://"+host)
+		}
+	}
+	return map[string]string{
+		"DOMAINS":          strings.Join(domains, " "),
+		"CORS_ORIGINS":     strings.Join(corsParts, ","),
+		"PUBLIC_BASE_URL":  publicBaseURL,
+		"SITEMAP_BASE_URL": publicBaseURL,
+	}, nil
+}
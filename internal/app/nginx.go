@@ -97,6 +97,11 @@ func nginxDefaultServerBlock() string {
         return 200 "ok\n";
     }
 
+    location ^~ /.well-known/acme-challenge/ {
+        alias /var/www/acme-challenge/;
+        default_type text/plain;
+    }
+
     location / {
         return 444;
     }
@@ -104,6 +109,8 @@ func nginxDefaultServerBlock() string {
 `
 }
 
+// nginxHealthLocation is shared by every tenant block, on both ports. It also
+// answers ACME HTTP-01 challenges from the files grengo writes.
 func nginxHealthLocation() string {
 	return `    location = /healthz {
         access_log off;
@@ -111,6 +118,11 @@ func nginxHealthLocation() string {
         return 200 "ok\n";
     }
 
+    location ^~ /.well-known/acme-challenge/ {
+        alias /var/www/acme-challenge/;
+        default_type text/plain;
+    }
+
 `
 }
 
@@ -136,6 +148,9 @@ func enabledClients() []clientInfo {
 		}
 		name := strings.TrimSpace(envVal(envFile, "CLIENT_NAME"))
 		port := strings.TrimSpace(envVal(envFile, "PORT"))
+		if override := upstreamPort(e.Name()); override != "" {
+			port = override
+		}
 		domainsStr := strings.TrimSpace(envVal(envFile, "DOMAINS"))
 		if name == "" || port == "" {
 			continue
@@ -237,8 +252,10 @@ proxy_cache_path /var/cache/nginx/uploads
 
 `)
 
-	// Collect all server names
-	var serverNames []string
+	// Hosts of clients holding a current certificate are served over HTTPS;
+	// everything else stays on the plain port 80 block.
+	certified := certifiedHosts(clients)
+	var plainHosts, tlsHosts []string
 	seenServerNames := map[string]bool{}
 	for _, c := range clients {
 		for _, domain := range c.Domains {
@@ -247,18 +264,21 @@ proxy_cache_path /var/cache/nginx/uploads
 					continue
 				}
 				seenServerNames[host] = true
-				serverNames = append(serverNames, host)
+				if certified[c.Name][host] {
+					tlsHosts = append(tlsHosts, host)
+				} else {
+					plainHosts = append(plainHosts, host)
+				}
 			}
 		}
 	}
+	if len(tlsHosts) > 0 {
+		b.WriteString("ssl_session_cache   shared:grengo_tls:10m;\nssl_session_timeout 1d;\n\n")
+	}
 
 	// Unknown hosts and the shared healthcheck never enter a tenant backend.
 	b.WriteString(nginxDefaultServerBlock())
 
-	// Tenant server block.
-	fmt.Fprintf(&b, "server {\n    listen 80;\n    server_name %s;\n    client_max_body_size 0;\n    proxy_intercept_errors on;\n\n", strings.Join(serverNames, " "))
-	b.WriteString(nginxHealthLocation())
-
 	// Load frontend shell index.html for fallback
 	indexPath := filepath.Join(ProjectRoot(), "frontend", "dist", "index.html")
 	indexBytes, err := os.ReadFile(indexPath)
@@ -287,12 +307,55 @@ proxy_cache_path /var/cache/nginx/uploads
 		fallbackHTML = "<!DOCTYPE html><html><head><title>Starting...</title><meta http-equiv=\\\"refresh\\\" content=\\\"2\\\"></head><body><h2>System is starting...</h2><p>Please wait a moment while the system boots.</p><!-- padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit padding to bypass cloudflare limit --></body></html>"
 	}
 
+	// Tenant server block.
+	if len(plainHosts) > 0 || len(tlsHosts) == 0 {
+		fmt.Fprintf(&b, "server {\n    listen 80;\n    server_name %s;\n    client_max_body_size 0;\n    proxy_intercept_errors on;\n\n", strings.Join(plainHosts, " "))
+		b.WriteString(nginxHealthLocation())
+		writeNginxTenantLocations(&b, fallbackHTML, "")
+		b.WriteString("}\n")
+	}
+
+	// Certified hosts: port 80 only answers ACME challenges and redirects,
+	// and each certificate gets its own HTTPS block.
+	if len(tlsHosts) > 0 {
+		fmt.Fprintf(&b, "\nserver {\n    listen 80;\n    server_name %s;\n\n", strings.Join(tlsHosts, " "))
+		b.WriteString(nginxHealthLocation())
+		b.WriteString("    location / {\n        return 301 https://$host$request_uri;\n    }\n}\n")
+	}
+	hsts := hstsHeader()
+	for _, c := range clients {
+		var hosts []string
+		for _, host := range tlsHosts {
+			if certified[c.Name][host] {
+				hosts = append(hosts, host)
+			}
+		}
+		if len(hosts) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\nserver {\n    listen 443 ssl;\n    http2 on;\n    server_name %s;\n", strings.Join(hosts, " "))
+		fmt.Fprintf(&b, "    ssl_certificate     %s;\n    ssl_certificate_key %s;\n", nginxTLSPath(c.Name, "fullchain.pem"), nginxTLSPath(c.Name, "privkey.pem"))
+		b.WriteString("    ssl_protocols       TLSv1.2 TLSv1.3;\n    client_max_body_size 0;\n    proxy_intercept_errors on;\n\n")
+		b.WriteString(nginxHealthLocation())
+		writeNginxTenantLocations(&b, fallbackHTML, hsts)
+		b.WriteString("}\n")
+	}
+
+	if err := os.WriteFile(confPath, []byte(b.String()), 0644); err != nil {
+		die("Cannot write nginx config: %v", err)
+	}
+	log("nginx config written => %s", confPath)
+}
+
+// writeNginxTenantLocations writes the locations shared by every tenant
+// server block. hsts, when set, is sent as Strict-Transport-Security.
+func writeNginxTenantLocations(b *strings.Builder, fallbackHTML, hsts string) {
 	// Fallback replacement to prevent 502 from Cloudflare
 	b.WriteString("    error_page 502 503 504 =503 /fallback-503;\n")
 	b.WriteString("    location = /fallback-503 {\n")
 	b.WriteString("        internal;\n")
 	b.WriteString("        default_type text/html;\n")
-	b.WriteString(fmt.Sprintf("        return 503 \"%s\";\n", fallbackHTML))
+	fmt.Fprintf(b, "        return 503 \"%s\";\n", fallbackHTML)
 	b.WriteString("    }\n\n")
 
 	// Security headers
@@ -301,8 +364,11 @@ proxy_cache_path /var/cache/nginx/uploads
     add_header X-Content-Type-Options "nosniff" always;
     add_header Referrer-Policy        "strict-origin-when-cross-origin" always;
     add_header Permissions-Policy     "camera=(self), microphone=(self), geolocation=()" always;
-
 `)
+	if hsts != "" {
+		fmt.Fprintf(b, "    add_header Strict-Transport-Security %s always;\n", strconv.Quote(hsts))
+	}
+	b.WriteString("\n")
 
 	// Uploads location
 	b.WriteString(`    # Cached uploads
@@ -416,17 +482,18 @@ proxy_cache_path /var/cache/nginx/uploads
 	b.WriteString(liveKitNginxLocation())
 
 	// Webhook location
-	fmt.Fprintf(&b, `    # Webhook
-    location = /webhook/github {
+	fmt.Fprintf(b, `    # Webhook
+    location /webhook/ {
         proxy_pass         http://127.0.0.1:%d;
         proxy_http_version 1.1;
         proxy_set_header   Host              $host;
         proxy_set_header   X-Real-IP         $remote_addr;
         proxy_set_header   X-Forwarded-For   $proxy_add_x_forwarded_for;
         proxy_set_header   X-Forwarded-Proto $scheme;
+        client_max_body_size 5m;
     }
 
-`, DefaultAPIPort)
+`, webhookPort())
 
 	// Health location
 	b.WriteString(`    # Health
@@ -453,11 +520,5 @@ proxy_cache_path /var/cache/nginx/uploads
         proxy_buffer_size  16k;
         proxy_buffers      8 16k;
     }
-}
 `)
-
-	if err := os.WriteFile(confPath, []byte(b.String()), 0644); err != nil {
-		die("Cannot write nginx config: %v", err)
-	}
-	log("nginx config written => %s", confPath)
 }
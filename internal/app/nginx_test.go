@@ -148,3 +148,40 @@ func TestGenerateNginxConfigKeepsTenantRoutingIsolated(t *testing.T) {
 		t.Fatalf("unknown host still falls through to a tenant:\n%s", config)
 	}
 }
+
+func TestGenerateNginxConfigFollowsUpstreamOverride(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GRENGO_ROOT", root)
+	dir := filepath.Join(root, "backends", "writer")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("CLIENT_NAME=writer\nPORT=1080\nDOMAINS=thewriterco.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	upstream := func() string {
+		generateNginxConfig()
+		data, err := os.ReadFile(filepath.Join(root, "nginx", "default.conf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		config := string(data)
+		start := strings.Index(config, "upstream writer-backend {")
+		return config[start : start+strings.Index(config[start:], "}")]
+	}
+
+	if got := upstream(); !strings.Contains(got, "server 127.0.0.1:1080;") {
+		t.Fatalf("upstream without override:\n%s", got)
+	}
+	if err := os.WriteFile(upstreamPortFile("writer"), []byte("1093\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := upstream(); !strings.Contains(got, "server 127.0.0.1:1093;") {
+		t.Fatalf("upstream with override:\n%s", got)
+	}
+	os.Remove(upstreamPortFile("writer"))
+	if got := upstream(); !strings.Contains(got, "server 127.0.0.1:1080;") {
+		t.Fatalf("upstream after clearing override:\n%s", got)
+	}
+}
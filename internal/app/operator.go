@@ -0,0 +1,717 @@
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/skaia/grpc/grengo"
+)
+
+const (
+	roleViewer   = "viewer"
+	roleDeployer = "deployer"
+	roleAdmin    = "admin"
+
+	sessionTokenTag   = "gst_"
+	defaultSessionTTL = 15 * time.Minute
+	// maxSessionTTL caps GRENGO_SESSION_TTL so a leaked token stays short
+	// lived whatever the setting.
+	maxSessionTTL = 12 * time.Hour
+	totpPeriod    = 30
+	totpDigits    = 6
+	// loginMaxFailures failed logins within loginFailureWindow lock a
+	// username until the window passes.
+	loginMaxFailures   = 5
+	loginFailureWindow = 15 * time.Minute
+	operatorsCacheTTL  = 30 * time.Second
+	// operatorStateTTL bounds how long sessions of a disabled, removed or
+	// re-roled operator keep their old access.
+	operatorStateTTL = 30 * time.Second
+)
+
+var operatorRoleRank = map[string]int{roleViewer: 1, roleDeployer: 2, roleAdmin: 3}
+
+// roleAllows reports whether role have covers role need.
+func roleAllows(have, need string) bool {
+	return operatorRoleRank[need] > 0 && operatorRoleRank[have] >= operatorRoleRank[need]
+}
+
+// adminMethods read secrets, run arbitrary commands or act on the whole
+// node. Other audited methods need deployer; the rest only viewer.
+var adminMethods = map[string]bool{
+	pb.GrengoService_Exec_FullMethodName:           true,
+	pb.GrengoService_DeleteSite_FullMethodName:     true,
+	pb.GrengoService_GetSiteEnv_FullMethodName:     true,
+	pb.GrengoService_UpdateSiteEnv_FullMethodName:  true,
+	pb.GrengoService_ArmSite_FullMethodName:        true,
+	pb.GrengoService_DisarmSite_FullMethodName:     true,
+	pb.GrengoService_ExportNode_FullMethodName:     true,
+	pb.GrengoService_ImportNode_FullMethodName:     true,
+	pb.GrengoService_DownloadExport_FullMethodName: true,
+	pb.GrengoService_DownloadJob_FullMethodName:    true,
+	pb.GrengoService_DeleteExport_FullMethodName:   true,
+	pb.GrengoService_SetSiteQuota_FullMethodName:   true,
+	pb.GrengoService_MoveSite_FullMethodName:       true,
+}
+
+// actionRole is the role a SendAction or WebSocket action needs.
+func actionRole(action string) string {
+	switch action {
+	case "export-site", "backup-site":
+		return roleDeployer
+	}
+	return roleAdmin
+}
+
+// methodRole is the role a gRPC method needs, given its decoded request.
+func methodRole(method string, decoded any) string {
+	if adminMethods[method] {
+		return roleAdmin
+	}
+	if method == pb.GrengoService_SendAction_FullMethodName {
+		m, _ := decoded.(map[string]any)
+		action, _ := m["action"].(string)
+		return actionRole(action)
+	}
+	if _, ok := auditedMethods[method]; ok {
+		return roleDeployer
+	}
+	return roleViewer
+}
+
+func validOperatorRole(role string) bool { return operatorRoleRank[role] > 0 }
+
+// sessionClaims are carried by a session token.
+type sessionClaims struct {
+	Username string `json:"sub"`
+	Role     string `json:"role"`
+	Expires  int64  `json:"exp"`
+}
+
+func sessionTTL() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(envVal(rootEnvFile(), "GRENGO_SESSION_TTL"))); err == nil && d > 0 {
+		return min(d, maxSessionTTL)
+	}
+	return defaultSessionTTL
+}
+
+func sessionKeyFile() string { return filepath.Join(ProjectRoot(), ".grengo-session-key") }
+
+var sessionKeyCache struct {
+	sync.Mutex
+	key []byte
+}
+
+// sessionKey returns the node's token signing key, creating it on first
+// use. Removing the file ends every session.
+func sessionKey() ([]byte, error) {
+	sessionKeyCache.Lock()
+	defer sessionKeyCache.Unlock()
+	if sessionKeyCache.key != nil {
+		return sessionKeyCache.key, nil
+	}
+	key, err := os.ReadFile(sessionKeyFile())
+	if os.IsNotExist(err) {
+		key = make([]byte, 32)
+		if _, err = rand.Read(key); err != nil {
+			return nil, err
+		}
+		err = writeFileAtomic(sessionKeyFile(), key, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(key) < 32 {
+		return nil, fmt.Errorf("%s is too short", sessionKeyFile())
+	}
+	sessionKeyCache.key = key
+	return key, nil
+}
+
+func signSession(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// issueSessionToken signs claims as gst_<payload>.<mac>. Tokens are not
+// stored; sessionFromToken rechecks the operator behind one on each call.
+func issueSessionToken(key []byte, c sessionClaims) string {
+	data, _ := json.Marshal(c)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return sessionTokenTag + payload + "." + signSession(key, payload)
+}
+
+func parseSessionToken(key []byte, token string, now time.Time) (*sessionClaims, error) {
+	payload, sig, ok := strings.Cut(strings.TrimPrefix(token, sessionTokenTag), ".")
+	if !ok || !strings.HasPrefix(token, sessionTokenTag) || !hmac.Equal([]byte(sig), []byte(signSession(key, payload))) {
+		return nil, errors.New("invalid session token")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errors.New("invalid session token")
+	}
+	var c sessionClaims
+	if err := json.Unmarshal(data, &c); err != nil || c.Username == "" {
+		return nil, errors.New("invalid session token")
+	}
+	if now.Unix() >= c.Expires {
+		return nil, errors.New("session expired")
+	}
+	return &c, nil
+}
+
+func hashOperatorPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+func newTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// totpCode is the RFC 6238 code for a time step.
+func totpCode(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret: %w", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	off := sum[len(sum)-1] & 0x0f
+	n := binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, n%1000000), nil
+}
+
+// verifyTOTP accepts the code for now or one step either side and returns
+// the step it matched.
+func verifyTOTP(secret, code string, now time.Time) (int64, bool) {
+	step := now.Unix() / totpPeriod
+	for _, s := range []int64{step, step - 1, step + 1} {
+		want, err := totpCode(secret, s)
+		if err == nil && hmac.Equal([]byte(want), []byte(strings.TrimSpace(code))) {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+func totpURI(username, secret string) string {
+	return fmt.Sprintf("otpauth://totp/grengo:%s?secret=%s&issuer=grengo&period=%d&digits=%d",
+		url.PathEscape(username), secret, totpPeriod, totpDigits)
+}
+
+var errInvalidLogin = errors.New("invalid username or password")
+
+// loginGuard throttles password guessing per username and refuses replayed
+// TOTP codes.
+type loginGuard struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	lastStep map[string]int64
+}
+
+var logins = &loginGuard{failures: map[string][]time.Time{}, lastStep: map[string]int64{}}
+
+func (g *loginGuard) locked(username string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	recent := g.failures[username][:0]
+	for _, t := range g.failures[username] {
+		if now.Sub(t) < loginFailureWindow {
+			recent = append(recent, t)
+		}
+	}
+	g.failures[username] = recent
+	return len(recent) >= loginMaxFailures
+}
+
+func (g *loginGuard) fail(username string, now time.Time) {
+	g.mu.Lock()
+	g.failures[username] = append(g.failures[username], now)
+	g.mu.Unlock()
+}
+
+// useStep records a TOTP step, refusing one already used.
+func (g *loginGuard) useStep(username string, step int64) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if step <= g.lastStep[username] {
+		return false
+	}
+	g.lastStep[username] = step
+	delete(g.failures, username)
+	return true
+}
+
+// dummyOperatorHash is compared against when a login names no operator.
+var dummyOperatorHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("grengo-no-such-operator"), bcrypt.DefaultCost)
+	return hash
+})
+
+// checkOperatorLogin verifies an operator's password and TOTP code. It
+// reports totpRequired, without an error, when a TOTP code is needed but
+// none was given.
+func checkOperatorLogin(o *operator, username, password, code string, now time.Time) (totpRequired bool, err error) {
+	if logins.locked(username, now) {
+		return false, errors.New("too many failed logins; try again later")
+	}
+	hash := dummyOperatorHash()
+	if o != nil {
+		hash = []byte(o.PasswordHash)
+	}
+	// Unknown usernames cost a bcrypt comparison too, so timing does not
+	// tell them apart from wrong passwords.
+	match := bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+	if o == nil || o.DisabledAt != nil || !match {
+		logins.fail(username, now)
+		return false, errInvalidLogin
+	}
+	if o.TOTPSecret == "" {
+		return false, nil
+	}
+	if strings.TrimSpace(code) == "" {
+		return true, nil
+	}
+	step, ok := verifyTOTP(o.TOTPSecret, code, now)
+	if !ok || !logins.useStep(username, step) {
+		logins.fail(username, now)
+		return false, errors.New("invalid totp code")
+	}
+	return false, nil
+}
+
+// operatorLogin checks credentials and issues a session token.
+func operatorLogin(username, password, code string) (*pb.LoginResponse, error) {
+	svc := newGrengoService()
+	o, err := svc.FindOperator(username)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "cannot check login: %v", err)
+	}
+	now := time.Now()
+	totpRequired, err := checkOperatorLogin(o, username, password, code, now)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if totpRequired {
+		return &pb.LoginResponse{TotpRequired: true}, nil
+	}
+	key, err := sessionKey()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "session key: %v", err)
+	}
+	claims := sessionClaims{Username: o.Username, Role: o.Role, Expires: now.Add(sessionTTL()).Unix()}
+	svc.UpdateOperator(o.Username, operatorUpdate{LoggedIn: true})
+	return &pb.LoginResponse{Token: issueSessionToken(key, claims), Role: o.Role, ExpiresAt: claims.Expires}, nil
+}
+
+var operatorsCache struct {
+	sync.Mutex
+	checked time.Time
+	any     bool
+}
+
+// operatorsConfigured reports whether any operator account exists. While
+// none do and no passcode is set, the API stays open as before. A failed
+// lookup is returned rather than read as "none", so an unreachable
+// management database never opens the API.
+func operatorsConfigured() (bool, error) {
+	operatorsCache.Lock()
+	defer operatorsCache.Unlock()
+	if time.Since(operatorsCache.checked) >= operatorsCacheTTL {
+		ops, err := newGrengoService().ListOperators()
+		if err != nil {
+			return false, err
+		}
+		operatorsCache.any = len(ops) > 0
+		operatorsCache.checked = time.Now()
+	}
+	return operatorsCache.any, nil
+}
+
+type operatorContextKey struct{}
+
+// operatorFromContext returns the operator a call was authorized for. It
+// is nil for calls made with the shared passcode.
+func operatorFromContext(ctx context.Context) *sessionClaims {
+	c, _ := ctx.Value(operatorContextKey{}).(*sessionClaims)
+	return c
+}
+
+var operatorStates struct {
+	sync.Mutex
+	entries map[string]operatorState
+}
+
+type operatorState struct {
+	op     *operator
+	loaded time.Time
+}
+
+// currentOperator returns the stored operator behind a session, or nil when
+// it was removed, reading the management DB at most every operatorStateTTL.
+func currentOperator(username string, now time.Time, lookup func(string) (*operator, error)) (*operator, error) {
+	operatorStates.Lock()
+	e, ok := operatorStates.entries[username]
+	operatorStates.Unlock()
+	if ok && now.Sub(e.loaded) < operatorStateTTL {
+		return e.op, nil
+	}
+	o, err := lookup(username)
+	if err != nil {
+		return nil, err
+	}
+	operatorStates.Lock()
+	if operatorStates.entries == nil {
+		operatorStates.entries = map[string]operatorState{}
+	}
+	operatorStates.entries[username] = operatorState{op: o, loaded: now}
+	operatorStates.Unlock()
+	return o, nil
+}
+
+// sessionFromToken verifies a bearer token with the node's key and checks
+// that its operator is still enabled. The stored role replaces the one the
+// token was issued with.
+func sessionFromToken(token string) (*sessionClaims, error) {
+	if token == "" {
+		return nil, errors.New("missing session token")
+	}
+	key, err := sessionKey()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	claims, err := parseSessionToken(key, token, now)
+	if err != nil {
+		return nil, err
+	}
+	o, err := currentOperator(claims.Username, now, newGrengoService().FindOperator)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check operator: %w", err)
+	}
+	if o == nil || o.DisabledAt != nil {
+		return nil, errors.New("operator disabled or removed")
+	}
+	claims.Role = o.Role
+	return claims, nil
+}
+
+// authorize decides whether a caller holding token or passcode ("p1:p2")
+// may do something needing role. An operator's role is checked; the shared
+// passcode acts as admin.
+func authorize(token, passcode, role string) (*sessionClaims, error) {
+	if token != "" {
+		claims, err := sessionFromToken(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if !roleAllows(claims.Role, role) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s role required", role)
+		}
+		return claims, nil
+	}
+	if passcode != "" {
+		parts := strings.SplitN(passcode, ":", 2)
+		if len(parts) != 2 || !verifyPasscode(parts[0], parts[1]) {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid passcode")
+		}
+		return nil, nil
+	}
+	if !passcodeConfigured() {
+		configured, err := operatorsConfigured()
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "cannot check operator accounts: %v", err)
+		}
+		if !configured {
+			return nil, nil
+		}
+	}
+	return nil, status.Errorf(codes.Unauthenticated, "missing session token or passcode")
+}
+
+func bearerToken(header string) string {
+	if t, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(t)
+	}
+	return ""
+}
+
+func metadataValue(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func authorizeCall(ctx context.Context, method string, decoded any) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	token, passcode := bearerToken(metadataValue(md, "authorization")), metadataValue(md, "x-grengo-passcode")
+	if token == "" && passcode == "" && strings.TrimSpace(metadataValue(md, "x-grengo-api-key")) != "" {
+		return authorizeAPIKeyCall(ctx, method, decoded)
+	}
+	claims, err := authorize(token, passcode, methodRole(method, decoded))
+	if err != nil {
+		return nil, err
+	}
+	if claims != nil {
+		ctx = context.WithValue(ctx, operatorContextKey{}, claims)
+	}
+	return ctx, nil
+}
+
+// authInterceptor admits calls with an operator session whose role covers
+// the method, with the shared passcode, or with an API key alone.
+func authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if passcodeExemptMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	ctx, err := authorizeCall(ctx, info.FullMethod, decodeRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// contextServerStream replaces the context of a server stream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context { return s.ctx }
+
+func authStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if passcodeExemptMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	ctx, err := authorizeCall(ss.Context(), info.FullMethod, nil)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// requireRole guards an HTTP handler like authInterceptor does a gRPC
+// method, reading "Authorization: Bearer" or X-Grengo-Passcode.
+func requireRole(role string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authorize(bearerToken(r.Header.Get("Authorization")), r.Header.Get("X-Grengo-Passcode"), role)
+		if err != nil {
+			code := http.StatusUnauthorized
+			if status.Code(err) == codes.PermissionDenied {
+				code = http.StatusForbidden
+			}
+			apiError(w, code, status.Convert(err).Message())
+			return
+		}
+		if claims != nil {
+			r = r.WithContext(context.WithValue(r.Context(), operatorContextKey{}, claims))
+		}
+		h(w, r)
+	}
+}
+
+// apiLogin is the HTTP form of Login.
+func apiLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	resp, err := operatorLogin(req.Username, req.Password, req.TOTP)
+	switch {
+	case status.Code(err) == codes.Unavailable:
+		apiError(w, http.StatusServiceUnavailable, status.Convert(err).Message())
+	case err != nil:
+		apiError(w, http.StatusUnauthorized, status.Convert(err).Message())
+	case resp.TotpRequired:
+		apiJSON(w, http.StatusUnauthorized, map[string]any{"error": "totp code required", "totp_required": true})
+	default:
+		apiJSON(w, http.StatusOK, map[string]any{"token": resp.Token, "role": resp.Role, "expires_at": time.Unix(resp.ExpiresAt, 0).UTC()})
+	}
+}
+
+// readNewPassword prompts twice for a password of at least 12 characters.
+func readNewPassword(username string) string {
+	pw := prompt(fmt.Sprintf("Password for %s", username), "", true)
+	if len(pw) < 12 {
+		die("Passwords must be at least 12 characters")
+	}
+	if prompt("Repeat password", "", true) != pw {
+		die("Passwords do not match")
+	}
+	return pw
+}
+
+func printTOTPSetup(username, secret string) {
+	fmt.Println()
+	info("Add this TOTP secret to an authenticator app:")
+	fmt.Printf("  %s%s%s\n", colorBold, secret, colorReset)
+	fmt.Printf("  %s\n", totpURI(username, secret))
+	fmt.Println()
+}
+
+// cmdOperatorAdd creates an operator account, prompting for its password.
+func cmdOperatorAdd(username, role string, withTOTP bool) {
+	start := time.Now()
+	if msg := nameError(username); msg != "" {
+		die("Invalid username: %s", msg)
+	}
+	if !validOperatorRole(role) {
+		die("Invalid role %q (viewer, deployer or admin)", role)
+	}
+	svc := newGrengoService()
+	if existing, err := svc.FindOperator(username); err != nil {
+		die("Cannot read operators: %v", err)
+	} else if existing != nil {
+		die("Operator '%s' already exists", username)
+	}
+	hash, err := hashOperatorPassword(readNewPassword(username))
+	if err != nil {
+		die("Cannot hash password: %v", err)
+	}
+	o := operator{Username: username, Role: role, PasswordHash: hash}
+	if withTOTP {
+		if o.TOTPSecret, err = newTOTPSecret(); err != nil {
+			die("Cannot generate TOTP secret: %v", err)
+		}
+	}
+	_, err = svc.CreateOperator(o)
+	auditCLI("operator-add", map[string]any{"name": username, "role": role, "totp": withTOTP}, start, err)
+	if err != nil {
+		die("Cannot create operator: %v", err)
+	}
+	log("Operator '%s' created with role %s", username, role)
+	if withTOTP {
+		printTOTPSetup(username, o.TOTPSecret)
+	}
+	if passcodeConfigured() {
+		info("The shared passcode still grants admin access; clear it with 'grengo passcode clear' once operators log in")
+	}
+}
+
+// cmdOperatorList shows operators with their role, TOTP and last login.
+func cmdOperatorList() {
+	ops, err := newGrengoService().ListOperators()
+	if err != nil {
+		die("Cannot list operators: %v", err)
+	}
+	if len(ops) == 0 {
+		info("No operators; add one with: grengo operator add <username> --role admin")
+		return
+	}
+	fmt.Printf("  %-20s %-9s %-5s %-17s %s\n", "USERNAME", "ROLE", "TOTP", "LAST LOGIN", "STATE")
+	for _, o := range ops {
+		totp := "no"
+		if o.TOTPEnabled {
+			totp = "yes"
+		}
+		state := "active"
+		if o.DisabledAt != nil {
+			state = colorRed + "disabled" + colorReset
+		}
+		fmt.Printf("  %-20s %-9s %-5s %-17s %s\n", o.Username, o.Role, totp, formatKeyTime(o.LastLoginAt, "never"), state)
+	}
+}
+
+// cmdOperatorUpdate applies one change to an operator: a new role, a new
+// password, TOTP on or off, disable, enable or remove.
+func cmdOperatorUpdate(username, change, value string) {
+	start := time.Now()
+	svc := newGrengoService()
+	o, err := svc.FindOperator(username)
+	if err != nil {
+		die("Cannot read operators: %v", err)
+	}
+	if o == nil {
+		die("No operator '%s'", username)
+	}
+	var u operatorUpdate
+	var secret string
+	args := map[string]any{"name": username, "change": change}
+	switch change {
+	case "role":
+		if !validOperatorRole(value) {
+			die("Invalid role %q (viewer, deployer or admin)", value)
+		}
+		u.Role = &value
+		args["role"] = value
+	case "passwd":
+		hash, err := hashOperatorPassword(readNewPassword(username))
+		if err != nil {
+			die("Cannot hash password: %v", err)
+		}
+		u.PasswordHash = &hash
+	case "totp":
+		switch value {
+		case "on":
+			if secret, err = newTOTPSecret(); err != nil {
+				die("Cannot generate TOTP secret: %v", err)
+			}
+		case "off":
+		default:
+			die("Usage: grengo operator totp <username> on|off")
+		}
+		u.TOTPSecret = &secret
+		args["totp"] = value
+	case "disable", "enable":
+		disabled := change == "disable"
+		u.Disabled = &disabled
+	case "remove":
+		err = svc.DeleteOperator(username)
+		auditCLI("operator-remove", args, start, err)
+		if err != nil {
+			die("Cannot remove operator: %v", err)
+		}
+		log("Operator '%s' removed", username)
+		info("Its sessions end within %s", operatorStateTTL)
+		return
+	}
+	err = svc.UpdateOperator(username, u)
+	auditCLI("operator-"+change, args, start, err)
+	if err != nil {
+		die("Cannot update operator: %v", err)
+	}
+	log("Operator '%s' updated", username)
+	if secret != "" {
+		printTOTPSetup(username, secret)
+	}
+	if change == "role" || change == "disable" {
+		info("Sessions already issued follow the change within %s", operatorStateTTL)
+	}
+}
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"context"
+	"encoding/base32"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/skaia/grpc/grengo"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMethodRole(t *testing.T) {
+	for _, tc := range []struct {
+		method string
+		req    any
+		role   string
+	}{
+		{pb.GrengoService_ListSites_FullMethodName, &pb.ListSitesRequest{}, roleViewer},
+		{pb.GrengoService_QueryLogs_FullMethodName, &pb.QueryLogsRequest{}, roleViewer},
+		{pb.GrengoService_StartSite_FullMethodName, &pb.SiteRequest{Name: "shop"}, roleDeployer},
+		{pb.GrengoService_MigrateAll_FullMethodName, &pb.MigrateAllRequest{}, roleDeployer},
+		{pb.GrengoService_Exec_FullMethodName, &pb.ExecRequest{Command: "logs"}, roleAdmin},
+		{pb.GrengoService_GetSiteEnv_FullMethodName, &pb.SiteRequest{Name: "shop"}, roleAdmin},
+		{pb.GrengoService_SendAction_FullMethodName, &pb.SendActionRequest{Action: []byte(`{"action":"backup-site","name":"shop"}`)}, roleDeployer},
+		{pb.GrengoService_SendAction_FullMethodName, &pb.SendActionRequest{Action: []byte(`{"action":"global-cmd","command":"prune"}`)}, roleAdmin},
+	} {
+		if got := methodRole(tc.method, decodeRequest(tc.req)); got != tc.role {
+			t.Errorf("methodRole(%s) = %s, want %s", tc.method, got, tc.role)
+		}
+	}
+	if !roleAllows(roleAdmin, roleDeployer) || roleAllows(roleViewer, roleDeployer) || roleAllows("root", roleViewer) {
+		t.Error("role ranking is wrong")
+	}
+}
+
+func TestSessionToken(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	token := issueSessionToken(key, sessionClaims{Username: "alice", Role: roleDeployer, Expires: now.Add(time.Minute).Unix()})
+
+	c, err := parseSessionToken(key, token, now)
+	if err != nil || c.Username != "alice" || c.Role != roleDeployer {
+		t.Fatalf("parse = %+v, %v", c, err)
+	}
+	if _, err := parseSessionToken(key, token, now.Add(2*time.Minute)); err == nil {
+		t.Error("expired token accepted")
+	}
+	if _, err := parseSessionToken([]byte("another key, just as long as it!"), token, now); err == nil {
+		t.Error("token accepted under another key")
+	}
+	payload, sig, _ := strings.Cut(token, ".")
+	forged := strings.Replace(payload, payload[len(payload)-8:], "AAAAAAAA", 1) + "." + sig
+	if _, err := parseSessionToken(key, forged, now); err == nil {
+		t.Error("altered token accepted")
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 appendix B, SHA1, truncated to six digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"} {
+		if got, err := totpCode(secret, unix/totpPeriod); err != nil || got != want {
+			t.Errorf("totpCode at %d = %s, %v; want %s", unix, got, err, want)
+		}
+	}
+	now := time.Unix(1111111109, 0)
+	if step, ok := verifyTOTP(secret, "081804", now.Add(25*time.Second)); !ok || step != 1111111109/totpPeriod {
+		t.Errorf("code from the previous step rejected")
+	}
+	if _, ok := verifyTOTP(secret, "081804", now.Add(5*time.Minute)); ok {
+		t.Error("stale code accepted")
+	}
+}
+
+func TestCheckOperatorLogin(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	secret, _ := newTOTPSecret()
+	o := &operator{Username: "carol", Role: roleAdmin, PasswordHash: string(hash), TOTPSecret: secret}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	code, _ := totpCode(secret, now.Unix()/totpPeriod)
+
+	if need, err := checkOperatorLogin(o, "carol", "correct horse", "", now); !need || err != nil {
+		t.Errorf("missing code: need=%v err=%v", need, err)
+	}
+	if _, err := checkOperatorLogin(o, "carol", "correct horse", code, now); err != nil {
+		t.Errorf("valid login: %v", err)
+	}
+	if _, err := checkOperatorLogin(o, "carol", "correct horse", code, now); err == nil {
+		t.Error("replayed code accepted")
+	}
+	if _, err := checkOperatorLogin(nil, "nobody", "x", "", now); err != errInvalidLogin {
+		t.Errorf("unknown user: %v", err)
+	}
+	for i := 0; i < loginMaxFailures; i++ {
+		checkOperatorLogin(o, "carol", "wrong", "", now)
+	}
+	if _, err := checkOperatorLogin(o, "carol", "correct horse", code, now); err == nil || err == errInvalidLogin {
+		t.Errorf("locked account: %v", err)
+	}
+	if _, err := checkOperatorLogin(o, "carol", "correct horse", "", now.Add(loginFailureWindow)); err != nil {
+		t.Errorf("lock outlived its window: %v", err)
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	key, err := sessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := issueSessionToken(key, sessionClaims{Username: "dave", Role: roleDeployer, Expires: time.Now().Add(time.Minute).Unix()})
+	seedOperatorState(t, &operator{Username: "dave", Role: roleDeployer})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	call := func(method string, req any) (string, error) {
+		var who string
+		_, err := authInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, _ any) (any, error) {
+			if op := operatorFromContext(ctx); op != nil {
+				who = op.Username
+			}
+			return nil, nil
+		})
+		return who, err
+	}
+
+	if who, err := call(pb.GrengoService_StartSite_FullMethodName, &pb.SiteRequest{Name: "shop"}); err != nil || who != "dave" {
+		t.Errorf("deployer call: who=%q err=%v", who, err)
+	}
+	if _, err := call(pb.GrengoService_Exec_FullMethodName, &pb.ExecRequest{Command: "logs"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("admin call by deployer: %v", err)
+	}
+
+	h := requireRole(roleAdmin, func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	req := httptest.NewRequest(http.MethodGet, "/sites/shop/env", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("HTTP admin route by deployer = %d", rec.Code)
+	}
+	req.Header.Set("Authorization", "Bearer gst_bogus.token")
+	rec = httptest.NewRecorder()
+	h(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("HTTP bogus token = %d", rec.Code)
+	}
+}
+
+// seedOperatorState stands in for the management DB lookup behind
+// sessionFromToken.
+func seedOperatorState(t *testing.T, o *operator) {
+	t.Helper()
+	operatorStates.Lock()
+	if operatorStates.entries == nil {
+		operatorStates.entries = map[string]operatorState{}
+	}
+	operatorStates.entries[o.Username] = operatorState{op: o, loaded: time.Now()}
+	operatorStates.Unlock()
+	t.Cleanup(func() {
+		operatorStates.Lock()
+		delete(operatorStates.entries, o.Username)
+		operatorStates.Unlock()
+	})
+}
+
+func TestSessionFollowsOperatorState(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	key, err := sessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := issueSessionToken(key, sessionClaims{Username: "erin", Role: roleAdmin, Expires: time.Now().Add(time.Minute).Unix()})
+
+	seedOperatorState(t, &operator{Username: "erin", Role: roleViewer})
+	if c, err := sessionFromToken(token); err != nil || c.Role != roleViewer {
+		t.Fatalf("demoted operator session = %+v, %v", c, err)
+	}
+	disabled := time.Now()
+	seedOperatorState(t, &operator{Username: "erin", Role: roleAdmin, DisabledAt: &disabled})
+	if _, err := sessionFromToken(token); err == nil {
+		t.Error("disabled operator's session accepted")
+	}
+	operatorStates.Lock()
+	operatorStates.entries["erin"] = operatorState{loaded: time.Now()}
+	operatorStates.Unlock()
+	if _, err := sessionFromToken(token); err == nil {
+		t.Error("removed operator's session accepted")
+	}
+
+	calls := 0
+	lookup := func(string) (*operator, error) { calls++; return &operator{Username: "frank"}, nil }
+	now := time.Now()
+	currentOperator("frank", now, lookup)
+	currentOperator("frank", now.Add(operatorStateTTL/2), lookup)
+	currentOperator("frank", now.Add(operatorStateTTL), lookup)
+	t.Cleanup(func() {
+		operatorStates.Lock()
+		delete(operatorStates.entries, "frank")
+		operatorStates.Unlock()
+	})
+	if calls != 2 {
+		t.Errorf("operator looked up %d times, want 2", calls)
+	}
+}
+
+func TestSessionTTLIsCapped(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GRENGO_ROOT", root)
+	for value, want := range map[string]time.Duration{
+		"":      defaultSessionTTL,
+		"1h":    time.Hour,
+		"8760h": maxSessionTTL,
+		"-5m":   defaultSessionTTL,
+	} {
+		os.WriteFile(filepath.Join(root, ".env"), []byte("GRENGO_SESSION_TTL="+value+"\n"), 0644)
+		if got := sessionTTL(); got != want {
+			t.Errorf("GRENGO_SESSION_TTL=%q gives %s, want %s", value, got, want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/skaia/grengo/internal/repo"
 )
@@ -40,6 +41,7 @@ func cmdPasscodeSet(args []string) {
 	if p1 == "" || p2 == "" {
 		die("Both passcode parts are required")
 	}
+	start := time.Now()
 
 	// Generate a random 16-byte file-salt so identical passcode pairs
 	// produce different hashes across installations.
@@ -63,6 +65,7 @@ func cmdPasscodeSet(args []string) {
 		_ = os.Remove(pcodePath())
 		log("Passcode set => grengo database")
 	}
+	auditCLI("passcode-set", map[string]any{}, start, nil)
 	info("The grengo API is now accessible with this passcode pair.")
 }
 
@@ -89,6 +92,7 @@ func cmdPasscodeVerify(args []string) {
 
 // cmdPasscodeClear removes the stored passcode, disabling grengo API access.
 func cmdPasscodeClear() {
+	start := time.Now()
 	path := pcodePath()
 	dbErr := newGrengoService().ClearPasscode()
 	fileExists := true
@@ -107,8 +111,10 @@ func cmdPasscodeClear() {
 	if dbErr != nil {
 		warn("Fallback passcode cleared, but grengo DB passcode could not be cleared: %v", dbErr)
 		warn("API access may still be enabled when the grengo DB is available")
+		auditCLI("passcode-clear", map[string]any{}, start, dbErr)
 		return
 	}
+	auditCLI("passcode-clear", map[string]any{}, start, nil)
 	log("Passcode cleared - grengo API access is now disabled")
 }
 
@@ -0,0 +1,477 @@
+package app
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skaia/grengo/internal/repo"
+	"github.com/skaia/grengo/internal/services"
+)
+
+const (
+	quotaCheckInterval = 5 * time.Minute
+	defaultQuotaGrace  = 24 * time.Hour
+	// quotaWarnPercent is the share of a storage quota that triggers a warning.
+	quotaWarnPercent = 90
+
+	quotaEnforceWarn    = "warn"
+	quotaEnforceArm     = "arm"
+	quotaEnforceDisable = "disable"
+
+	quotaStateOK       = "ok"
+	quotaStateWarning  = "warning"
+	quotaStateOver     = "over"
+	quotaStateEnforced = "enforced"
+)
+
+// composeLimitLine matches the limit keys grengo manages in a client's
+// compose.yml.
+var composeLimitLine = regexp.MustCompile(`(?m)^    (cpu_shares|mem_limit|memswap_limit|pids_limit):.*\n`)
+
+// parseSizeMB parses a size such as 512m, 5g or 1.5t into megabytes. A bare
+// number is taken as megabytes; 0 means unlimited.
+func parseSizeMB(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	mult := 1.0
+	for _, unit := range []struct {
+		suffix string
+		mult   float64
+	}{{"tb", 1024 * 1024}, {"gb", 1024}, {"mb", 1}, {"t", 1024 * 1024}, {"g", 1024}, {"m", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, mult = strings.TrimSuffix(s, unit.suffix), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 512m or 5g)", s)
+	}
+	return int64(n * mult), nil
+}
+
+func formatQuotaMB(mb int64) string {
+	if mb == 0 {
+		return "unlimited"
+	}
+	return humanBytes(uint64(mb) * 1024 * 1024)
+}
+
+func validateSiteQuota(q siteQuota) error {
+	switch q.Enforce {
+	case quotaEnforceWarn, quotaEnforceArm, quotaEnforceDisable:
+	default:
+		return fmt.Errorf("enforce must be warn, arm or disable, not %q", q.Enforce)
+	}
+	if q.CPUShares != 0 && q.CPUShares < 2 {
+		return fmt.Errorf("cpu shares must be at least 2")
+	}
+	if q.MemoryMB != 0 && q.MemoryMB < 6 {
+		return fmt.Errorf("memory limit must be at least 6 MB")
+	}
+	if q.CPUShares < 0 || q.MemoryMB < 0 || q.PidsLimit < 0 || q.UploadsMB < 0 || q.DBMB < 0 {
+		return fmt.Errorf("limits cannot be negative")
+	}
+	return nil
+}
+
+// composeWithLimits returns compose.yml content with the backend service's
+// limits replaced by q's. A nil quota removes them.
+func composeWithLimits(content string, q *siteQuota) (string, error) {
+	content = composeLimitLine.ReplaceAllString(content, "")
+	anchor := regexp.MustCompile(`(?m)^    restart:.*\n`)
+	loc := anchor.FindStringIndex(content)
+	if loc == nil {
+		return "", fmt.Errorf("no restart: line in the backend service")
+	}
+	if q == nil {
+		return content, nil
+	}
+	var lines strings.Builder
+	if q.CPUShares > 0 {
+		fmt.Fprintf(&lines, "    cpu_shares: %d\n", q.CPUShares)
+	}
+	if q.MemoryMB > 0 {
+		// Equal swap and memory limits keep the container out of swap.
+		fmt.Fprintf(&lines, "    mem_limit: %dm\n    memswap_limit: %dm\n", q.MemoryMB, q.MemoryMB)
+	}
+	if q.PidsLimit > 0 {
+		fmt.Fprintf(&lines, "    pids_limit: %d\n", q.PidsLimit)
+	}
+	return content[:loc[1]] + lines.String() + content[loc[1]:], nil
+}
+
+// applyComposeLimits writes a client's limits into its compose.yml.
+func applyComposeLimits(name string, q *siteQuota) error {
+	data, err := os.ReadFile(clientComposeFile(name))
+	if err != nil {
+		return err
+	}
+	updated, err := composeWithLimits(string(data), q)
+	if err != nil {
+		return fmt.Errorf("%s: %w", clientComposeFile(name), err)
+	}
+	if updated == string(data) {
+		return nil
+	}
+	return os.WriteFile(clientComposeFile(name), []byte(updated), 0644)
+}
+
+// uploadsUsage sums the size of a client's upload directory.
+func uploadsUsage(name string) int64 {
+	var total int64
+	filepath.WalkDir(filepath.Join(clientDir(name), "uploads"), func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if fi, err := d.Info(); err == nil {
+				total += fi.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// databaseUsage returns the size of a client's database, or an error when
+// PostgreSQL is not reachable.
+func databaseUsage(name string) (int64, error) {
+	dbName := sanitizeDBName(envVal(clientEnvFile(name), "POSTGRES_DB"))
+	if dbName == "" {
+		return 0, fmt.Errorf("POSTGRES_DB not set")
+	}
+	if !pgRunning() {
+		return 0, fmt.Errorf("PostgreSQL is not running")
+	}
+	env := loadSharedEnv()
+	out, err := dockerExecOutput("skaia-postgres", "psql", "-U", env.PostgresUser, "-d", "template1", "-tAc",
+		fmt.Sprintf("SELECT COALESCE((SELECT pg_database_size(datname) FROM pg_database WHERE datname='%s'), 0)", dbName))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+}
+
+// quotaGrace is how long a client may stay over quota before it is armed or
+// disabled.
+func quotaGrace() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(envVal(rootEnvFile(), "GRENGO_QUOTA_GRACE"))); err == nil && d >= 0 {
+		return d
+	}
+	return defaultQuotaGrace
+}
+
+// evaluateQuota moves a quota through ok, warning, over and enforced for the
+// usage recorded in q. The returned action is what the checker must do:
+// "warning" or "over" to notify, "arm" or "disable" to enforce, "release" to
+// disarm a client it armed earlier, or "" for nothing.
+func evaluateQuota(q siteQuota, now time.Time, grace time.Duration) (siteQuota, string) {
+	const mb = 1024 * 1024
+	over, near := false, false
+	for _, u := range []struct{ used, limitMB int64 }{{q.UploadsUsed, q.UploadsMB}, {q.DBUsed, q.DBMB}} {
+		if u.limitMB == 0 {
+			continue
+		}
+		if u.used > u.limitMB*mb {
+			over = true
+		} else if u.used*100 >= u.limitMB*mb*quotaWarnPercent {
+			near = true
+		}
+	}
+
+	prev := q.State
+	next := q
+	action := ""
+	switch {
+	case over:
+		if q.OverSince == nil {
+			next.OverSince = &now
+		}
+		next.State = quotaStateOver
+		if prev == quotaStateEnforced {
+			next.State = quotaStateEnforced
+		} else if q.Enforce != quotaEnforceWarn && now.Sub(*next.OverSince) >= grace {
+			next.State, action = quotaStateEnforced, q.Enforce
+		} else if prev != quotaStateOver {
+			action = "over"
+		}
+	case near:
+		next.OverSince, next.State = nil, quotaStateWarning
+		if prev == quotaStateOK || prev == "" {
+			action = "warning"
+		}
+	default:
+		next.OverSince, next.State = nil, quotaStateOK
+	}
+	if !over && prev == quotaStateEnforced && q.Enforce == quotaEnforceArm {
+		action = "release"
+	}
+	return next, action
+}
+
+// checkQuotas measures every client with a quota and acts on the result.
+// It runs inside the API process and must not exit it.
+func checkQuotas(now time.Time) []siteQuota {
+	svc := newGrengoService()
+	quotas, err := svc.ListSiteQuotas("")
+	if err != nil {
+		return nil
+	}
+	grace := quotaGrace()
+	store := repo.New(ProjectRoot())
+	var checked []siteQuota
+	for _, q := range quotas {
+		if !clientExists(q.SiteName) {
+			continue
+		}
+		prefix := logPrefix("quota", q.SiteName)
+		q.UploadsUsed = uploadsUsage(q.SiteName)
+		if q.DBMB > 0 {
+			used, err := databaseUsage(q.SiteName)
+			if err != nil {
+				// Keep the last measurement rather than report an empty database.
+				BroadcastLog("WARN", prefix, fmt.Sprintf("cannot measure database: %v", err))
+			} else {
+				q.DBUsed = used
+			}
+		}
+		next, action := evaluateQuota(q, now, grace)
+		usage := fmt.Sprintf("uploads %s of %s, database %s of %s",
+			humanBytes(uint64(next.UploadsUsed)), formatQuotaMB(next.UploadsMB), humanBytes(uint64(next.DBUsed)), formatQuotaMB(next.DBMB))
+		switch action {
+		case "warning":
+			BroadcastLog("WARN", prefix, "nearing quota: "+usage)
+		case "over":
+			msg := "over quota: " + usage
+			if q.Enforce != quotaEnforceWarn {
+				msg += fmt.Sprintf(" - will %s in %s", q.Enforce, grace)
+			}
+			BroadcastLog("WARN", prefix, msg)
+		case quotaEnforceArm:
+			if err := store.ArmSite(q.SiteName, now); err != nil {
+				BroadcastLog("ERROR", prefix, fmt.Sprintf("cannot arm: %v", err))
+				continue
+			}
+			BroadcastLog("WARN", prefix, "armed for exceeding its quota: "+usage)
+		case quotaEnforceDisable:
+			if _, err := services.NewCommandRunner(ProjectRoot()).RunSelf("disable", q.SiteName); err != nil {
+				BroadcastLog("ERROR", prefix, fmt.Sprintf("cannot disable: %v", err))
+				continue
+			}
+			BroadcastLog("WARN", prefix, "disabled for exceeding its quota: "+usage)
+		case "release":
+			if err := store.DisarmSite(q.SiteName); err != nil {
+				BroadcastLog("ERROR", prefix, fmt.Sprintf("cannot disarm: %v", err))
+				continue
+			}
+			BroadcastLog("INFO", prefix, "back within quota, disarmed: "+usage)
+		}
+		if err := svc.RecordQuotaCheck(next); err != nil {
+			BroadcastLog("WARN", prefix, fmt.Sprintf("cannot record quota check: %v", err))
+		}
+		checked = append(checked, next)
+	}
+	return checked
+}
+
+func runQuotaCheckerLoop() {
+	for {
+		checkQuotas(time.Now())
+		time.Sleep(quotaCheckInterval)
+	}
+}
+
+// siteQuotaOrDefault returns a client's quota, or an unlimited one.
+func siteQuotaOrDefault(name string) (siteQuota, bool, error) {
+	quotas, err := newGrengoService().ListSiteQuotas(name)
+	if err != nil {
+		return siteQuota{}, false, err
+	}
+	if len(quotas) == 0 {
+		return siteQuota{SiteName: name, Enforce: quotaEnforceArm, State: quotaStateOK}, false, nil
+	}
+	return quotas[0], true, nil
+}
+
+// quotaOptions are the limits given to 'grengo quota set'; empty fields keep
+// the current value.
+type quotaOptions struct {
+	CPUShares string
+	Memory    string
+	Pids      string
+	Uploads   string
+	DB        string
+	Enforce   string
+}
+
+// applyQuotaOptions overlays the options given on the command line.
+func applyQuotaOptions(q siteQuota, opts quotaOptions) (siteQuota, error) {
+	ints := []struct {
+		flag, value string
+		dst         *int
+	}{{"--cpu-shares", opts.CPUShares, &q.CPUShares}, {"--pids", opts.Pids, &q.PidsLimit}}
+	for _, f := range ints {
+		if f.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(f.value)
+		if err != nil {
+			return q, fmt.Errorf("%s expects a number, got %q", f.flag, f.value)
+		}
+		*f.dst = n
+	}
+	sizes := []struct {
+		value string
+		dst   *int64
+	}{{opts.Memory, &q.MemoryMB}, {opts.Uploads, &q.UploadsMB}, {opts.DB, &q.DBMB}}
+	for _, f := range sizes {
+		if f.value == "" {
+			continue
+		}
+		n, err := parseSizeMB(f.value)
+		if err != nil {
+			return q, err
+		}
+		*f.dst = n
+	}
+	if opts.Enforce != "" {
+		q.Enforce = opts.Enforce
+	}
+	return q, validateSiteQuota(q)
+}
+
+// recreateIfRunning restarts a running client so compose picks up new limits.
+func recreateIfRunning(name string) {
+	if !clientRunning(name) {
+		info("Limits apply the next time %s starts", name)
+		return
+	}
+	log("Recreating %s with the new limits…", name)
+	if err := dockerCompose(clientComposeFile(name), "up", "-d"); err != nil {
+		die("Cannot recreate %s: %v", name, err)
+	}
+}
+
+// cmdQuotaSet updates a client's quota and applies its container limits.
+func cmdQuotaSet(name string, opts quotaOptions) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	q, _, err := siteQuotaOrDefault(name)
+	if err != nil {
+		die("Cannot read quota: %v", err)
+	}
+	if q, err = applyQuotaOptions(q, opts); err != nil {
+		die("%v", err)
+	}
+	if err := newGrengoService().UpsertSiteQuota(q); err != nil {
+		die("Cannot save quota: %v", err)
+	}
+	if err := applyComposeLimits(name, &q); err != nil {
+		die("Cannot apply limits: %v", err)
+	}
+	log("Quota for %s saved", bold(name))
+	printSiteQuota(q)
+	recreateIfRunning(name)
+}
+
+// cmdQuotaApply rewrites a client's compose limits from its stored quota.
+func cmdQuotaApply(name string) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	q, ok, err := siteQuotaOrDefault(name)
+	if err != nil {
+		die("Cannot read quota: %v", err)
+	}
+	limits := &q
+	if !ok {
+		limits = nil
+	}
+	if err := applyComposeLimits(name, limits); err != nil {
+		die("Cannot apply limits: %v", err)
+	}
+	recreateIfRunning(name)
+}
+
+// cmdQuotaClear removes a client's quota and its container limits.
+func cmdQuotaClear(name string) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	q, ok, err := siteQuotaOrDefault(name)
+	if err != nil {
+		die("Cannot read quota: %v", err)
+	}
+	if !ok {
+		die("Client '%s' has no quota", name)
+	}
+	if err := newGrengoService().DeleteSiteQuota(name); err != nil {
+		die("Cannot remove quota: %v", err)
+	}
+	if err := applyComposeLimits(name, nil); err != nil {
+		die("Cannot remove limits: %v", err)
+	}
+	if q.State == quotaStateEnforced && q.Enforce == quotaEnforceArm {
+		repo.New(ProjectRoot()).DisarmSite(name)
+		info("Disarmed %s (it was armed by its quota)", name)
+	}
+	log("Quota for %s removed", bold(name))
+	recreateIfRunning(name)
+}
+
+// cmdQuotaShow lists quotas and the last measured usage.
+func cmdQuotaShow(name string) {
+	quotas, err := newGrengoService().ListSiteQuotas(name)
+	if err != nil {
+		die("Cannot read quotas: %v", err)
+	}
+	if len(quotas) == 0 {
+		info("No quotas set")
+		return
+	}
+	for i, q := range quotas {
+		if i > 0 {
+			fmt.Println()
+		}
+		printSiteQuota(q)
+	}
+}
+
+// cmdQuotaCheck runs one checker pass now.
+func cmdQuotaCheck() {
+	if err := newGrengoService().EnsureReady(); err != nil {
+		die("Grengo management DB unavailable: %v", err)
+	}
+	for _, q := range checkQuotas(time.Now()) {
+		printSiteQuota(q)
+		fmt.Println()
+	}
+}
+
+func printSiteQuota(q siteQuota) {
+	state := q.State
+	switch state {
+	case quotaStateWarning, quotaStateOver:
+		state = colorYellow + state + colorReset
+	case quotaStateEnforced:
+		state = colorRed + state + " (" + q.Enforce + ")" + colorReset
+	}
+	cpu, pids := "default", "default"
+	if q.CPUShares > 0 {
+		cpu = strconv.Itoa(q.CPUShares)
+	}
+	if q.PidsLimit > 0 {
+		pids = strconv.Itoa(q.PidsLimit)
+	}
+	fmt.Printf("%s%s%s  %s\n", colorBold, q.SiteName, colorReset, state)
+	fmt.Printf("  cpu shares %s, memory %s, pids %s, enforce %s\n", cpu, formatQuotaMB(q.MemoryMB), pids, q.Enforce)
+	fmt.Printf("  uploads    %s of %s\n", humanBytes(uint64(q.UploadsUsed)), formatQuotaMB(q.UploadsMB))
+	fmt.Printf("  database   %s of %s\n", humanBytes(uint64(q.DBUsed)), formatQuotaMB(q.DBMB))
+	if q.OverSince != nil && q.State == quotaStateOver {
+		fmt.Printf("  over quota since %s\n", q.OverSince.Local().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testClientCompose = `# Auto-generated by grengo
+services:
+  backend:
+    image: skaia-backend:latest
+    container_name: ${CLIENT_NAME}-backend
+    restart: unless-stopped
+    ports:
+      - "${PORT}:${PORT}"
+`
+
+func TestParseSizeMB(t *testing.T) {
+	tests := map[string]int64{"512": 512, "512m": 512, "5g": 5120, "1.5GB": 1536, "2t": 2 * 1024 * 1024, "0": 0}
+	for in, want := range tests {
+		if got, err := parseSizeMB(in); err != nil || got != want {
+			t.Errorf("parseSizeMB(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "lots", "-1g", "5x"} {
+		if _, err := parseSizeMB(in); err == nil {
+			t.Errorf("parseSizeMB(%q) accepted", in)
+		}
+	}
+}
+
+func TestComposeWithLimits(t *testing.T) {
+	q := &siteQuota{CPUShares: 512, MemoryMB: 1024, PidsLimit: 200}
+	got, err := composeWithLimits(testClientCompose, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "    restart: unless-stopped\n    cpu_shares: 512\n    mem_limit: 1024m\n    memswap_limit: 1024m\n    pids_limit: 200\n    ports:\n"
+	if !strings.Contains(got, want) {
+		t.Fatalf("limits not placed in the backend service:\n%s", got)
+	}
+
+	q = &siteQuota{MemoryMB: 256}
+	again, _ := composeWithLimits(got, q)
+	if strings.Contains(again, "cpu_shares") || strings.Count(again, "mem_limit") != 1 || !strings.Contains(again, "mem_limit: 256m") {
+		t.Fatalf("limits not replaced:\n%s", again)
+	}
+	if twice, _ := composeWithLimits(again, q); twice != again {
+		t.Fatal("applying the same limits twice changed the file")
+	}
+
+	cleared, _ := composeWithLimits(again, nil)
+	if cleared != testClientCompose {
+		t.Fatalf("clearing limits did not restore the original:\n%s", cleared)
+	}
+	if _, err := composeWithLimits("services: {}\n", q); err == nil {
+		t.Fatal("compose file without a backend service accepted")
+	}
+}
+
+func TestEvaluateQuota(t *testing.T) {
+	const mb = 1024 * 1024
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	grace := time.Hour
+	q := siteQuota{SiteName: "shop", UploadsMB: 100, DBMB: 50, Enforce: quotaEnforceArm, State: quotaStateOK}
+
+	step := func(uploads, db int64, at time.Time, wantState, wantAction string) {
+		t.Helper()
+		q.UploadsUsed, q.DBUsed = uploads, db
+		var action string
+		q, action = evaluateQuota(q, at, grace)
+		if q.State != wantState || action != wantAction {
+			t.Fatalf("uploads %d MB, db %d MB at %s: state %q action %q, want %q %q",
+				uploads/mb, db/mb, at.Format(time.Kitchen), q.State, action, wantState, wantAction)
+		}
+	}
+
+	step(10*mb, 10*mb, now, quotaStateOK, "")
+	step(95*mb, 10*mb, now, quotaStateWarning, "warning")
+	step(96*mb, 10*mb, now, quotaStateWarning, "")
+	step(10*mb, 60*mb, now, quotaStateOver, "over")
+	step(10*mb, 60*mb, now.Add(30*time.Minute), quotaStateOver, "")
+	step(10*mb, 60*mb, now.Add(time.Hour), quotaStateEnforced, quotaEnforceArm)
+	step(10*mb, 60*mb, now.Add(2*time.Hour), quotaStateEnforced, "")
+	step(10*mb, 10*mb, now.Add(3*time.Hour), quotaStateOK, "release")
+	if q.OverSince != nil {
+		t.Fatal("over_since kept after usage dropped")
+	}
+
+	// warn-only quotas never enforce.
+	q = siteQuota{UploadsMB: 1, Enforce: quotaEnforceWarn, State: quotaStateOK}
+	step(2*mb, 0, now, quotaStateOver, "over")
+	step(2*mb, 0, now.Add(48*time.Hour), quotaStateOver, "")
+
+	// Disabled clients stay disabled when usage drops.
+	q = siteQuota{UploadsMB: 1, Enforce: quotaEnforceDisable, State: quotaStateOK}
+	grace = 0
+	step(2*mb, 0, now, quotaStateEnforced, quotaEnforceDisable)
+	step(0, 0, now, quotaStateOK, "")
+}
+
+func TestApplyQuotaOptions(t *testing.T) {
+	base := siteQuota{SiteName: "shop", Enforce: quotaEnforceArm, UploadsMB: 100}
+	q, err := applyQuotaOptions(base, quotaOptions{Memory: "1g", CPUShares: "512", Enforce: "disable"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.MemoryMB != 1024 || q.CPUShares != 512 || q.Enforce != quotaEnforceDisable || q.UploadsMB != 100 {
+		t.Fatalf("quota = %+v", q)
+	}
+	for _, opts := range []quotaOptions{{Enforce: "delete"}, {CPUShares: "1"}, {Memory: "1m"}, {Pids: "many"}} {
+		if _, err := applyQuotaOptions(base, opts); err == nil {
+			t.Errorf("options %+v accepted", opts)
+		}
+	}
+}
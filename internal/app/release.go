@@ -0,0 +1,444 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Release ledger
+// releasesDir holds the frontend builds referenced by the ledger.
+func releasesDir() string {
+	return filepath.Join(ProjectRoot(), "releases")
+}
+
+func releaseFrontendDir(hash string) string {
+	return filepath.Join(releasesDir(), "frontend", hash)
+}
+
+// releaseActor names whoever ran this grengo process, for triggered_by.
+func releaseActor() string {
+	if name := os.Getenv("SUDO_USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// cliTrigger describes a deploy started from the command line.
+func cliTrigger(command string) string {
+	return command + " by " + releaseActor()
+}
+
+// recordRelease snapshots what a client is running now and appends it to
+// the ledger. Every deploy (start, frontend ship, migration, blue/green
+// update) calls it. The image is pinned with an extra tag and the frontend
+// build is copied into releases/frontend/<hash>, so the release can be
+// restored after later builds replace skaia-backend:latest and
+// frontend/dist.
+//
+// distDir is the frontend build just shipped ("" if unchanged,
+// in which case the previous release's frontend is carried over) and
+// dbBackup the safety dump taken before migrations ran, if any. Failures
+// are reported but never abort the deploy that triggered them.
+func recordRelease(name, trigger, distDir, dbBackup string) {
+	rel := release{
+		SiteName:         name,
+		MigrationVersion: latestMigrationVersion(),
+		DBBackup:         dbBackup,
+		TriggeredBy:      trigger,
+	}
+	if err := fillReleaseImage(&rel, name); err != nil {
+		warn("Release ledger: %v", err)
+	}
+	if distDir != "" {
+		hash, err := archiveFrontendDist(distDir)
+		if err != nil {
+			warn("Release ledger: cannot archive frontend: %v", err)
+		}
+		rel.FrontendHash = hash
+	} else if prev, ok := latestRelease(name); ok {
+		rel.FrontendHash = prev.FrontendHash
+	}
+	id, err := newGrengoService().RecordRelease(rel)
+	if err != nil {
+		warn("Release ledger: cannot record release for '%s': %v", name, err)
+		return
+	}
+	info("Recorded release #%d for %s", id, name)
+}
+
+// fillReleaseImage records the image the client's container runs and pins
+// it with a tag so it survives the next 'grengo build'.
+func fillReleaseImage(rel *release, name string) error {
+	imageID, err := dockerOutput("inspect", "--format", "{{.Image}}", name+"-backend")
+	if err != nil || imageID == "" {
+		if imageID, err = dockerOutput("image", "inspect", "--format", "{{.Id}}", Image()); err != nil {
+			return fmt.Errorf("cannot determine image of %s", name)
+		}
+	}
+	rel.ImageID = imageID
+	rel.ImageRef = releaseImageRef(imageID)
+	if err := dockerRunSilent("tag", imageID, rel.ImageRef); err != nil {
+		return fmt.Errorf("cannot tag %s as %s: %v", imageID, rel.ImageRef, err)
+	}
+	return nil
+}
+
+// releaseImageRef is the pinned tag for an image id.
+func releaseImageRef(imageID string) string {
+	short := strings.TrimPrefix(imageID, "sha256:")
+	if len(short) > 12 {
+		short = short[:12]
+	}
+	return ImageName + ":release-" + short
+}
+
+// latestMigrationVersion returns the newest backend migration (file name
+// without .sql), which is what runMigrations leaves a database at.
+func latestMigrationVersion() string {
+	entries, err := os.ReadDir(filepath.Join(backendSrc(), "internal", "migrations"))
+	if err != nil {
+		return ""
+	}
+	latest := ""
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".sql" && e.Name() > latest {
+			latest = e.Name()
+		}
+	}
+	return strings.TrimSuffix(latest, ".sql")
+}
+
+// hashFrontendDist returns a content hash over every file in dir.
+func hashFrontendDist(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, path := range files {
+		rel, _ := filepath.Rel(dir, path)
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// archiveFrontendDist copies distDir into the release store (once per
+// distinct build) and returns its hash.
+func archiveFrontendDist(distDir string) (string, error) {
+	hash, err := hashFrontendDist(distDir)
+	if err != nil {
+		return "", err
+	}
+	dest := releaseFrontendDir(hash)
+	if _, err := os.Stat(dest); err == nil {
+		return hash, nil
+	}
+	tmp := dest + ".tmp"
+	os.RemoveAll(tmp)
+	if err := copyDir(distDir, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return hash, nil
+}
+
+// copyDir copies the regular files and directories under src to dst.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+func latestRelease(name string) (release, bool) {
+	releases, err := newGrengoService().ListReleases(name)
+	if err != nil || len(releases) == 0 {
+		return release{}, false
+	}
+	return releases[0], true
+}
+
+// rollbackTarget picks the release to restore from releases (newest first).
+// With no explicit id it is the newest ordinary release older than the one
+// currently live, so repeated rollbacks keep stepping back instead of
+// toggling between two releases.
+func rollbackTarget(releases []release, id int64) (release, error) {
+	if len(releases) == 0 {
+		return release{}, fmt.Errorf("no releases recorded")
+	}
+	if id > 0 {
+		for _, r := range releases {
+			if r.ID == id {
+				return r, nil
+			}
+		}
+		return release{}, fmt.Errorf("release #%d not found", id)
+	}
+	live := releases[0].ID
+	if releases[0].RolledBackTo > 0 {
+		live = releases[0].RolledBackTo
+	}
+	for _, r := range releases {
+		if r.ID < live && r.RolledBackTo == 0 {
+			return r, nil
+		}
+	}
+	return release{}, fmt.Errorf("no release older than #%d to roll back to", live)
+}
+
+// rollbackBackup returns the safety dump taken when the client first
+// migrated past target, i.e. the oldest db_backup recorded after it.
+func rollbackBackup(releases []release, target release) string {
+	backup := ""
+	for _, r := range releases {
+		if r.ID > target.ID && r.DBBackup != "" && r.RolledBackTo == 0 {
+			backup = r.DBBackup
+		}
+	}
+	return backup
+}
+
+var composeImageLine = regexp.MustCompile(`(?m)^([ \t]*image:[ \t]*)\S+[ \t]*$`)
+
+// pinComposeImage points the client's compose.yml at image.
+func pinComposeImage(name, image string) error {
+	data, err := os.ReadFile(clientComposeFile(name))
+	if err != nil {
+		return err
+	}
+	if !composeImageLine.Match(data) {
+		return fmt.Errorf("no image line in %s", clientComposeFile(name))
+	}
+	updated := composeImageLine.ReplaceAll(data, []byte("${1}"+image))
+	return os.WriteFile(clientComposeFile(name), updated, 0644)
+}
+
+// unpinComposeImage points a client pinned by 'grengo rollback' back at the
+// image 'grengo build' produces, so the next deploy runs the new build. It
+// returns the pinned reference it replaced, or "" if the client was not
+// pinned.
+func unpinComposeImage(name string) (string, error) {
+	data, err := os.ReadFile(clientComposeFile(name))
+	if err != nil {
+		return "", err
+	}
+	m := composeImageLine.FindSubmatch(data)
+	if m == nil {
+		return "", nil
+	}
+	pinned := strings.TrimSpace(strings.TrimPrefix(string(m[0]), string(m[1])))
+	if !strings.HasPrefix(pinned, ImageName+":release-") {
+		return "", nil
+	}
+	if err := pinComposeImage(name, Image()); err != nil {
+		return "", err
+	}
+	return pinned, nil
+}
+
+// releaseRollbackPin unpins a client before a deploy, dying if compose.yml
+// cannot be rewritten. The returned reference lets a deploy that fails
+// before replacing the live container restore the pin.
+func releaseRollbackPin(name string) string {
+	pinned, err := unpinComposeImage(name)
+	if err != nil {
+		die("Cannot unpin %s: %v", name, err)
+	}
+	if pinned != "" {
+		info("%s was pinned to %s by a rollback; deploying %s", name, pinned, Image())
+	}
+	return pinned
+}
+
+// restoreRollbackPin re-pins a client after a deploy that left the old
+// container running.
+func restoreRollbackPin(name, pinned string) {
+	if pinned == "" {
+		return
+	}
+	if err := pinComposeImage(name, pinned); err != nil {
+		warn("Cannot restore image pin %s: %v", pinned, err)
+	}
+}
+
+// cmdRollback restores a client to an earlier release: its pinned image,
+// its frontend build and, with restoreDB, the database as it was before the
+// migrations that followed it.
+func cmdRollback(name, releaseArg string, restoreDB bool) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	var id int64
+	if releaseArg != "" {
+		n, err := strconv.ParseInt(strings.TrimPrefix(releaseArg, "#"), 10, 64)
+		if err != nil || n <= 0 {
+			die("Invalid release: %s", releaseArg)
+		}
+		id = n
+	}
+	releases, err := newGrengoService().ListReleases(name)
+	if err != nil {
+		die("Cannot read release ledger: %v", err)
+	}
+	target, err := rollbackTarget(releases, id)
+	if err != nil {
+		die("Cannot roll back '%s': %v", name, err)
+	}
+
+	if target.ImageRef == "" || !imageExists(target.ImageRef) {
+		die("Image of release #%d (%s) is no longer available", target.ID, orDash(target.ImageRef))
+	}
+	distDir := ""
+	if target.FrontendHash != "" {
+		distDir = releaseFrontendDir(target.FrontendHash)
+		if _, err := os.Stat(distDir); err != nil {
+			die("Frontend build %s of release #%d is no longer available", target.FrontendHash, target.ID)
+		}
+	}
+	backup := ""
+	if restoreDB {
+		backup = rollbackBackup(releases, target)
+		if backup == "" {
+			die("No pre-migration backup recorded after release #%d", target.ID)
+		}
+		if _, err := os.Stat(backup); err != nil {
+			die("Backup %s no longer exists", backup)
+		}
+	}
+
+	log("Rolling back %s to release #%d (%s, %s)", name, target.ID, target.ImageRef, target.CreatedAt.Local().Format("2006-01-02 15:04"))
+	if err := pinComposeImage(name, target.ImageRef); err != nil {
+		die("Cannot pin image: %v", err)
+	}
+	if backup != "" {
+		restoreDatabaseBackup(name, backup)
+	}
+
+	ensureNetwork()
+	if err := dockerCompose(clientComposeFile(name), "up", "-d", "--force-recreate"); err != nil {
+		die("Failed to start %s: %v", name, err)
+	}
+	waitForHealthy(name+"-backend", blueGreenHealthTimeout)
+	if distDir != "" {
+		shipFrontendDist(name, distDir)
+	}
+
+	id, err = newGrengoService().RecordRelease(release{
+		SiteName:         name,
+		ImageID:          target.ImageID,
+		ImageRef:         target.ImageRef,
+		FrontendHash:     target.FrontendHash,
+		MigrationVersion: target.MigrationVersion,
+		DBBackup:         backup,
+		TriggeredBy:      cliTrigger("rollback"),
+		RolledBackTo:     target.ID,
+	})
+	if err != nil {
+		warn("Release ledger: cannot record rollback: %v", err)
+	}
+	log("%s rolled back to release #%d", name, target.ID)
+	info("The image stays pinned to %s until the next 'grengo start %s' or 'grengo update --blue-green %s'", target.ImageRef, name, name)
+}
+
+// restoreDatabaseBackup stops the client and replaces its database with the
+// dump in backupFile. The current database is dumped first.
+func restoreDatabaseBackup(name, backupFile string) {
+	if !pgRunning() {
+		die("PostgreSQL is not running. Start infra first: grengo compose up")
+	}
+	env := loadSharedEnv()
+	dbName := envVal(clientEnvFile(name), "POSTGRES_DB")
+	if dbName == "" {
+		die("POSTGRES_DB not set in %s", clientEnvFile(name))
+	}
+	dump, err := os.ReadFile(backupFile)
+	if err != nil {
+		die("Cannot read backup: %v", err)
+	}
+	if clientRunning(name) {
+		cmdStop(name)
+	}
+	log("Safety backup => %s", backupDatabase(name, dbName))
+
+	log("Restoring '%s' from %s…", dbName, filepath.Base(backupFile))
+	dropSQL := fmt.Sprintf(`DROP DATABASE "%s";`, dbName)
+	if err := dockerExec("skaia-postgres", "psql", "-U", env.PostgresUser, "-d", "template1", "-c", dropSQL); err != nil {
+		die("Failed to drop database: %v", err)
+	}
+	createSQL := fmt.Sprintf(`CREATE DATABASE "%s";`, dbName)
+	if err := dockerExec("skaia-postgres", "psql", "-U", env.PostgresUser, "-d", "template1", "-c", createSQL); err != nil {
+		die("Failed to recreate database: %v", err)
+	}
+	if err := dockerExecInput("skaia-postgres", dump, "psql", "-v", "ON_ERROR_STOP=1", "-U", env.PostgresUser, "-d", dbName); err != nil {
+		die("Restore failed: %v", err)
+	}
+}
+
+// cmdReleaseList prints a client's release ledger.
+func cmdReleaseList(name string) {
+	releases, err := newGrengoService().ListReleases(name)
+	if err != nil {
+		die("Cannot read release ledger: %v", err)
+	}
+	if len(releases) == 0 {
+		info("No releases recorded for '%s'", name)
+		return
+	}
+	fmt.Printf("%s%-6s %-17s %-28s %-17s %-28s %s%s\n", colorBold, "ID", "DEPLOYED", "IMAGE", "FRONTEND", "MIGRATION", "TRIGGER", colorReset)
+	for _, r := range releases {
+		trigger := r.TriggeredBy
+		if r.RolledBackTo > 0 {
+			trigger = fmt.Sprintf("%s (to #%d)", trigger, r.RolledBackTo)
+		}
+		fmt.Printf("%-6d %-17s %-28s %-17s %-28s %s\n", r.ID, r.CreatedAt.Local().Format("2006-01-02 15:04"),
+			orDash(r.ImageRef), orDash(r.FrontendHash), orDash(r.MigrationVersion), trigger)
+	}
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRollbackTarget(t *testing.T) {
+	// Newest first, as ListReleases returns them.
+	releases := []release{
+		{ID: 5, DBBackup: "b5"},
+		{ID: 4, DBBackup: "b4"},
+		{ID: 3},
+		{ID: 2},
+		{ID: 1},
+	}
+	if got, err := rollbackTarget(releases, 0); err != nil || got.ID != 4 {
+		t.Fatalf("previous release = #%d, %v; want #4", got.ID, err)
+	}
+	if got, err := rollbackTarget(releases, 2); err != nil || got.ID != 2 {
+		t.Fatalf("explicit release = #%d, %v; want #2", got.ID, err)
+	}
+	if _, err := rollbackTarget(releases, 9); err == nil {
+		t.Fatal("unknown release accepted")
+	}
+	if got := rollbackBackup(releases, release{ID: 3}); got != "b4" {
+		t.Fatalf("backup for #3 = %q, want the first dump taken after it (b4)", got)
+	}
+
+	// After rolling back to #4, the next rollback steps back to #3 rather
+	// than returning to #5.
+	releases = append([]release{{ID: 6, RolledBackTo: 4}}, releases...)
+	if got, err := rollbackTarget(releases, 0); err != nil || got.ID != 3 {
+		t.Fatalf("second rollback = #%d, %v; want #3", got.ID, err)
+	}
+	if _, err := rollbackTarget([]release{{ID: 1}}, 0); err == nil {
+		t.Fatal("rollback past the first release accepted")
+	}
+}
+
+func TestArchiveFrontendDist(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	dist := t.TempDir()
+	os.MkdirAll(filepath.Join(dist, "assets"), 0755)
+	os.WriteFile(filepath.Join(dist, "index.html"), []byte("<html>v1</html>"), 0644)
+	os.WriteFile(filepath.Join(dist, "assets", "app.js"), []byte("console.log(1)"), 0644)
+
+	hash, err := archiveFrontendDist(dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(releaseFrontendDir(hash), "assets", "app.js"))
+	if err != nil || string(got) != "console.log(1)" {
+		t.Fatalf("archived app.js = %q, %v", got, err)
+	}
+	if again, _ := archiveFrontendDist(dist); again != hash {
+		t.Fatalf("hash changed for identical build: %s != %s", again, hash)
+	}
+
+	os.WriteFile(filepath.Join(dist, "index.html"), []byte("<html>v2</html>"), 0644)
+	if next, _ := archiveFrontendDist(dist); next == hash {
+		t.Fatal("hash did not change for a different build")
+	}
+	if data, _ := os.ReadFile(filepath.Join(releaseFrontendDir(hash), "index.html")); string(data) != "<html>v1</html>" {
+		t.Fatalf("earlier release was overwritten: %q", data)
+	}
+}
+
+func TestPinComposeImage(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	os.MkdirAll(clientDir("shop"), 0755)
+	compose := "services:\n  backend:\n    image: skaia-backend:latest\n    container_name: ${CLIENT_NAME}-backend\n"
+	os.WriteFile(clientComposeFile("shop"), []byte(compose), 0644)
+
+	if err := pinComposeImage("shop", "skaia-backend:release-0123456789ab"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(clientComposeFile("shop"))
+	want := strings.Replace(compose, "skaia-backend:latest", "skaia-backend:release-0123456789ab", 1)
+	if string(data) != want {
+		t.Fatalf("compose.yml = %q, want %q", data, want)
+	}
+
+	pinned, err := unpinComposeImage("shop")
+	if err != nil || pinned != "skaia-backend:release-0123456789ab" {
+		t.Fatalf("unpin = %q, %v", pinned, err)
+	}
+	if data, _ := os.ReadFile(clientComposeFile("shop")); string(data) != compose {
+		t.Fatalf("compose.yml after unpin = %q, want %q", data, compose)
+	}
+	if pinned, err := unpinComposeImage("shop"); err != nil || pinned != "" {
+		t.Fatalf("unpinned client reported a pin: %q, %v", pinned, err)
+	}
+}
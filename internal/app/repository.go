@@ -1,8 +1,11 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const grengoDatabaseName = "grengo"
@@ -81,6 +84,36 @@ func (r grengoRepository) RecordFrappeAllocation(record frappeAllocation) error
 	return r.execSQL([]byte(sql))
 }
 
+// frappeCluster is one row of frappe_clusters.
+type frappeCluster struct {
+	Version       string `json:"version"`
+	Branch        string `json:"branch"`
+	ClusterIndex  int    `json:"cluster_index"`
+	ContainerName string `json:"container_name"`
+	Capacity      int    `json:"capacity"`
+	SiteCount     int    `json:"site_count"`
+	Status        string `json:"status"`
+}
+
+// ListFrappeClusters returns every live Frappe cluster with its site count.
+func (r grengoRepository) ListFrappeClusters() ([]frappeCluster, error) {
+	out, err := r.queryScalar(`
+SELECT COALESCE(json_agg(row_to_json(c) ORDER BY c.version, c.cluster_index), '[]')
+FROM (
+  SELECT version, branch, cluster_index, container_name, capacity, site_count, status
+  FROM frappe_clusters
+  WHERE deleted_at IS NULL
+) c`)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []frappeCluster
+	if err := json.Unmarshal([]byte(out), &clusters); err != nil {
+		return nil, fmt.Errorf("decode frappe clusters: %w", err)
+	}
+	return clusters, nil
+}
+
 func (r grengoRepository) lockFrappeSiteSQL(record frappeAllocation) string {
 	key := fmt.Sprintf("frappe_site:%s", record.SiteName)
 	return fmt.Sprintf("SELECT pg_advisory_xact_lock(hashtext(%s)::bigint);", sqlLiteral(key))
@@ -182,3 +215,807 @@ func grengoTransactionSQL(statements ...string) string {
 	b.WriteString("COMMIT;\n")
 	return b.String()
 }
+
+type backupSchedule struct {
+	ID          int64      `json:"id"`
+	SiteName    string     `json:"site_name"`
+	CronExpr    string     `json:"cron_expr"`
+	KeepDaily   int        `json:"keep_daily"`
+	KeepWeekly  int        `json:"keep_weekly"`
+	KeepMonthly int        `json:"keep_monthly"`
+	Enabled     bool       `json:"enabled"`
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	LastStatus  string     `json:"last_status,omitempty"`
+}
+
+func (r grengoRepository) UpsertBackupSchedule(s backupSchedule) error {
+	if s.SiteName == "" || s.CronExpr == "" {
+		return fmt.Errorf("invalid backup schedule")
+	}
+	sql := fmt.Sprintf(`
+INSERT INTO grengo_backup_schedules (site_name, cron_expr, keep_daily, keep_weekly, keep_monthly, enabled, updated_at)
+VALUES (%s, %s, %d, %d, %d, %t, NOW())
+ON CONFLICT (site_name) WHERE deleted_at IS NULL DO UPDATE SET
+  cron_expr = EXCLUDED.cron_expr,
+  keep_daily = EXCLUDED.keep_daily,
+  keep_weekly = EXCLUDED.keep_weekly,
+  keep_monthly = EXCLUDED.keep_monthly,
+  enabled = EXCLUDED.enabled,
+  updated_at = NOW();`,
+		sqlLiteral(s.SiteName), sqlLiteral(s.CronExpr), s.KeepDaily, s.KeepWeekly, s.KeepMonthly, s.Enabled)
+	return r.execSQL([]byte(sql))
+}
+
+func (r grengoRepository) DeleteBackupSchedule(siteName string) error {
+	sql := fmt.Sprintf(`UPDATE grengo_backup_schedules SET deleted_at=NOW(), updated_at=NOW() WHERE site_name=%s AND deleted_at IS NULL;`, sqlLiteral(siteName))
+	return r.execSQL([]byte(sql))
+}
+
+func (r grengoRepository) ListBackupSchedules() ([]backupSchedule, error) {
+	out, err := r.queryScalar(`
+SELECT COALESCE(json_agg(row_to_json(s) ORDER BY s.site_name), '[]')
+FROM (
+  SELECT id, site_name, cron_expr, keep_daily, keep_weekly, keep_monthly, enabled, last_run_at, last_status
+  FROM grengo_backup_schedules
+  WHERE deleted_at IS NULL
+) s`)
+	if err != nil {
+		return nil, err
+	}
+	var schedules []backupSchedule
+	if err := json.Unmarshal([]byte(out), &schedules); err != nil {
+		return nil, fmt.Errorf("decode backup schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// StartBackupRun records a running backup and returns its row id.
+func (r grengoRepository) StartBackupRun(scheduleID int64, siteName, jobID, archiveName string) (int64, error) {
+	schedule := "NULL"
+	if scheduleID > 0 {
+		schedule = strconv.FormatInt(scheduleID, 10)
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+INSERT INTO grengo_backup_runs (schedule_id, site_name, job_id, archive_name)
+VALUES (%s, %s, %s, %s)
+RETURNING id`, schedule, sqlLiteral(siteName), sqlLiteral(jobID), sqlLiteral(archiveName)))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(out, 10, 64)
+}
+
+func (r grengoRepository) FinishBackupRun(runID, scheduleID int64, status, errText string) error {
+	statements := []string{fmt.Sprintf(`
+UPDATE grengo_backup_runs SET status=%s, error=NULLIF(%s, ''), finished_at=NOW() WHERE id=%d;`,
+		sqlLiteral(status), sqlLiteral(errText), runID)}
+	if scheduleID > 0 {
+		statements = append(statements, fmt.Sprintf(`
+UPDATE grengo_backup_schedules SET last_run_at=NOW(), last_status=%s, updated_at=NOW() WHERE id=%d;`,
+			sqlLiteral(status), scheduleID))
+	}
+	return r.execSQL([]byte(grengoTransactionSQL(statements...)))
+}
+
+func (r grengoRepository) MarkBackupsPruned(archiveNames []string) error {
+	if len(archiveNames) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(archiveNames))
+	for i, name := range archiveNames {
+		quoted[i] = sqlLiteral(name)
+	}
+	sql := fmt.Sprintf(`UPDATE grengo_backup_runs SET pruned_at=NOW() WHERE pruned_at IS NULL AND archive_name IN (%s);`, strings.Join(quoted, ", "))
+	return r.execSQL([]byte(sql))
+}
+
+// release is one entry of a client's release ledger: what it was running
+// after a deploy, and what triggered the deploy.
+type release struct {
+	ID               int64     `json:"id"`
+	SiteName         string    `json:"site_name"`
+	ImageID          string    `json:"image_id"`
+	ImageRef         string    `json:"image_ref"`
+	FrontendHash     string    `json:"frontend_hash"`
+	MigrationVersion string    `json:"migration_version"`
+	DBBackup         string    `json:"db_backup"`
+	TriggeredBy      string    `json:"triggered_by"`
+	RolledBackTo     int64     `json:"rolled_back_to,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// RecordRelease appends a release to the ledger and returns its id.
+func (r grengoRepository) RecordRelease(rel release) (int64, error) {
+	if rel.SiteName == "" || rel.TriggeredBy == "" {
+		return 0, fmt.Errorf("invalid release")
+	}
+	rolledBackTo := "NULL"
+	if rel.RolledBackTo > 0 {
+		rolledBackTo = strconv.FormatInt(rel.RolledBackTo, 10)
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+INSERT INTO grengo_releases (site_name, image_id, image_ref, frontend_hash, migration_version, db_backup, triggered_by, rolled_back_to)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+RETURNING id`,
+		sqlLiteral(rel.SiteName), sqlLiteral(rel.ImageID), sqlLiteral(rel.ImageRef), sqlLiteral(rel.FrontendHash),
+		sqlLiteral(rel.MigrationVersion), sqlLiteral(rel.DBBackup), sqlLiteral(rel.TriggeredBy), rolledBackTo))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(out, 10, 64)
+}
+
+// ListReleases returns a client's releases, newest first.
+func (r grengoRepository) ListReleases(siteName string) ([]release, error) {
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT COALESCE(json_agg(row_to_json(x) ORDER BY x.id DESC), '[]')
+FROM (
+  SELECT id, site_name, image_id, image_ref, frontend_hash, migration_version, db_backup, triggered_by,
+         COALESCE(rolled_back_to, 0) AS rolled_back_to, created_at
+  FROM grengo_releases
+  WHERE site_name=%s AND deleted_at IS NULL
+) x`, sqlLiteral(siteName)))
+	if err != nil {
+		return nil, err
+	}
+	var releases []release
+	if err := json.Unmarshal([]byte(out), &releases); err != nil {
+		return nil, fmt.Errorf("decode releases: %w", err)
+	}
+	return releases, nil
+}
+
+// webhookDelivery is one received webhook request and what grengo did with it.
+type webhookDelivery struct {
+	ID         int64     `json:"id"`
+	Provider   string    `json:"provider"`
+	DeliveryID string    `json:"delivery_id"`
+	Event      string    `json:"event"`
+	SiteName   string    `json:"site_name"`
+	Ref        string    `json:"ref"`
+	CommitSHA  string    `json:"commit_sha"`
+	Pusher     string    `json:"pusher"`
+	Status     string    `json:"status"`
+	Detail     string    `json:"detail"`
+	Targets    string    `json:"targets"`
+	JobID      string    `json:"job_id"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+func (r grengoRepository) RecordWebhookDelivery(d webhookDelivery) error {
+	sql := fmt.Sprintf(`
+INSERT INTO grengo_webhook_deliveries (provider, delivery_id, event, site_name, ref, commit_sha, pusher, status, detail, targets, job_id)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s);`,
+		sqlLiteral(d.Provider), sqlLiteral(d.DeliveryID), sqlLiteral(d.Event), sqlLiteral(d.SiteName), sqlLiteral(d.Ref),
+		sqlLiteral(d.CommitSHA), sqlLiteral(d.Pusher), sqlLiteral(d.Status), sqlLiteral(d.Detail), sqlLiteral(d.Targets), sqlLiteral(d.JobID))
+	return r.execSQL([]byte(sql))
+}
+
+// WebhookDeliveryAccepted reports whether a delivery with this id already
+// started a deploy, so provider retries are not deployed twice.
+func (r grengoRepository) WebhookDeliveryAccepted(provider, deliveryID string) (bool, error) {
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT COUNT(*) FROM grengo_webhook_deliveries
+WHERE provider=%s AND delivery_id=%s AND status='accepted' AND deleted_at IS NULL`,
+		sqlLiteral(provider), sqlLiteral(deliveryID)))
+	if err != nil {
+		return false, err
+	}
+	return out != "0", nil
+}
+
+// ListWebhookDeliveries returns the newest deliveries first, optionally only
+// those addressed to or deploying siteName.
+func (r grengoRepository) ListWebhookDeliveries(siteName string, limit int) ([]webhookDelivery, error) {
+	where := "deleted_at IS NULL"
+	if siteName != "" {
+		where += fmt.Sprintf(" AND (site_name=%s OR %s = ANY(string_to_array(targets, ',')))", sqlLiteral(siteName), sqlLiteral(siteName))
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT COALESCE(json_agg(row_to_json(x) ORDER BY x.id DESC), '[]')
+FROM (
+  SELECT id, provider, delivery_id, event, site_name, ref, commit_sha, pusher, status, detail, targets, job_id, received_at
+  FROM grengo_webhook_deliveries
+  WHERE %s
+  ORDER BY id DESC
+  LIMIT %d
+) x`, where, limit))
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []webhookDelivery
+	if err := json.Unmarshal([]byte(out), &deliveries); err != nil {
+		return nil, fmt.Errorf("decode webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// siteQuota holds a client's resource limits and the checker's last view of
+// its usage. A zero limit means unlimited.
+type siteQuota struct {
+	SiteName    string     `json:"site_name"`
+	CPUShares   int        `json:"cpu_shares"`
+	MemoryMB    int64      `json:"memory_mb"`
+	PidsLimit   int        `json:"pids_limit"`
+	UploadsMB   int64      `json:"uploads_mb"`
+	DBMB        int64      `json:"db_mb"`
+	Enforce     string     `json:"enforce"`
+	State       string     `json:"state"`
+	OverSince   *time.Time `json:"over_since,omitempty"`
+	UploadsUsed int64      `json:"uploads_used"`
+	DBUsed      int64      `json:"db_used"`
+	CheckedAt   *time.Time `json:"checked_at,omitempty"`
+}
+
+// UpsertSiteQuota stores a client's limits. The checker's state is kept.
+func (r grengoRepository) UpsertSiteQuota(q siteQuota) error {
+	if q.SiteName == "" {
+		return fmt.Errorf("invalid site quota")
+	}
+	sql := fmt.Sprintf(`
+INSERT INTO grengo_site_quotas (site_name, cpu_shares, memory_mb, pids_limit, uploads_mb, db_mb, enforce, updated_at)
+VALUES (%s, %d, %d, %d, %d, %d, %s, NOW())
+ON CONFLICT (site_name) WHERE deleted_at IS NULL DO UPDATE SET
+  cpu_shares = EXCLUDED.cpu_shares,
+  memory_mb = EXCLUDED.memory_mb,
+  pids_limit = EXCLUDED.pids_limit,
+  uploads_mb = EXCLUDED.uploads_mb,
+  db_mb = EXCLUDED.db_mb,
+  enforce = EXCLUDED.enforce,
+  updated_at = NOW();`,
+		sqlLiteral(q.SiteName), q.CPUShares, q.MemoryMB, q.PidsLimit, q.UploadsMB, q.DBMB, sqlLiteral(q.Enforce))
+	return r.execSQL([]byte(sql))
+}
+
+func (r grengoRepository) DeleteSiteQuota(siteName string) error {
+	sql := fmt.Sprintf(`UPDATE grengo_site_quotas SET deleted_at=NOW(), updated_at=NOW() WHERE site_name=%s AND deleted_at IS NULL;`, sqlLiteral(siteName))
+	return r.execSQL([]byte(sql))
+}
+
+// ListSiteQuotas returns every quota, or only siteName's when it is set.
+func (r grengoRepository) ListSiteQuotas(siteName string) ([]siteQuota, error) {
+	where := "deleted_at IS NULL"
+	if siteName != "" {
+		where += " AND site_name=" + sqlLiteral(siteName)
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT COALESCE(json_agg(row_to_json(q) ORDER BY q.site_name), '[]')
+FROM (
+  SELECT site_name, cpu_shares, memory_mb, pids_limit, uploads_mb, db_mb, enforce, state, over_since, uploads_used, db_used, checked_at
+  FROM grengo_site_quotas
+  WHERE %s
+) q`, where))
+	if err != nil {
+		return nil, err
+	}
+	var quotas []siteQuota
+	if err := json.Unmarshal([]byte(out), &quotas); err != nil {
+		return nil, fmt.Errorf("decode site quotas: %w", err)
+	}
+	return quotas, nil
+}
+
+// RecordQuotaCheck stores the outcome of one checker pass for a client.
+func (r grengoRepository) RecordQuotaCheck(q siteQuota) error {
+	overSince := "NULL"
+	if q.OverSince != nil {
+		overSince = sqlLiteral(q.OverSince.UTC().Format(time.RFC3339))
+	}
+	sql := fmt.Sprintf(`
+UPDATE grengo_site_quotas SET state=%s, over_since=%s, uploads_used=%d, db_used=%d, checked_at=NOW()
+WHERE site_name=%s AND deleted_at IS NULL;`,
+		sqlLiteral(q.State), overSince, q.UploadsUsed, q.DBUsed, sqlLiteral(q.SiteName))
+	return r.execSQL([]byte(sql))
+}
+
+// alertRecord is one alert from when its rule started firing until it
+// resolved.
+type alertRecord struct {
+	ID         int64      `json:"id"`
+	Rule       string     `json:"rule"`
+	Kind       string     `json:"kind"`
+	Target     string     `json:"target"`
+	Severity   string     `json:"severity"`
+	Summary    string     `json:"summary"`
+	State      string     `json:"state"`
+	StartedAt  time.Time  `json:"started_at"`
+	NotifiedAt *time.Time `json:"notified_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+func sqlTimestamp(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "NULL"
+	}
+	return sqlLiteral(t.UTC().Format(time.RFC3339Nano))
+}
+
+// OpenAlert stores a newly firing alert and returns its id.
+func (r grengoRepository) OpenAlert(a alertRecord) (int64, error) {
+	out, err := r.queryScalar(fmt.Sprintf(`
+INSERT INTO grengo_alerts (rule, kind, target, severity, summary, state, started_at, notified_at)
+VALUES (%s, %s, %s, %s, %s, 'firing', %s, %s)
+RETURNING id`,
+		sqlLiteral(a.Rule), sqlLiteral(a.Kind), sqlLiteral(a.Target), sqlLiteral(a.Severity), sqlLiteral(a.Summary),
+		sqlTimestamp(&a.StartedAt), sqlTimestamp(a.NotifiedAt)))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(out, 10, 64)
+}
+
+// UpdateAlert stores an alert's latest summary, notification time and state.
+func (r grengoRepository) UpdateAlert(a alertRecord) error {
+	sql := fmt.Sprintf(`
+UPDATE grengo_alerts SET summary=%s, state=%s, notified_at=%s, resolved_at=%s
+WHERE id=%d AND deleted_at IS NULL;`,
+		sqlLiteral(a.Summary), sqlLiteral(a.State), sqlTimestamp(a.NotifiedAt), sqlTimestamp(a.ResolvedAt), a.ID)
+	return r.execSQL([]byte(sql))
+}
+
+// ListAlerts returns the newest alerts first; firingOnly drops resolved ones.
+func (r grengoRepository) ListAlerts(firingOnly bool, limit int) ([]alertRecord, error) {
+	where := "deleted_at IS NULL"
+	if firingOnly {
+		where += " AND state='firing'"
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT COALESCE(json_agg(row_to_json(x) ORDER BY x.id DESC), '[]')
+FROM (
+  SELECT id, rule, kind, target, severity, summary, state, started_at, notified_at, resolved_at
+  FROM grengo_alerts
+  WHERE %s
+  ORDER BY id DESC
+  LIMIT %d
+) x`, where, limit))
+	if err != nil {
+		return nil, err
+	}
+	var alerts []alertRecord
+	if err := json.Unmarshal([]byte(out), &alerts); err != nil {
+		return nil, fmt.Errorf("decode alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// AddAlertSilence stores a silence window and returns its id.
+func (r grengoRepository) AddAlertSilence(s alertSilence) (int64, error) {
+	out, err := r.queryScalar(fmt.Sprintf(`
+INSERT INTO grengo_alert_silences (rule, target, comment, starts_at, ends_at)
+VALUES (%s, %s, %s, %s, %s)
+RETURNING id`,
+		sqlLiteral(s.Rule), sqlLiteral(s.Target), sqlLiteral(s.Comment), sqlTimestamp(&s.From), sqlTimestamp(&s.Until)))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(out, 10, 64)
+}
+
+// DeleteAlertSilence ends a silence early.
+func (r grengoRepository) DeleteAlertSilence(id int64) error {
+	sql := fmt.Sprintf(`UPDATE grengo_alert_silences SET deleted_at=NOW() WHERE id=%d AND deleted_at IS NULL;`, id)
+	return r.execSQL([]byte(sql))
+}
+
+// ListAlertSilences returns the silences that have not ended yet.
+func (r grengoRepository) ListAlertSilences() ([]alertSilence, error) {
+	out, err := r.queryScalar(`
+SELECT COALESCE(json_agg(row_to_json(x) ORDER BY x.ends_at), '[]')
+FROM (
+  SELECT id, rule, target, comment, starts_at, ends_at
+  FROM grengo_alert_silences
+  WHERE deleted_at IS NULL AND ends_at > NOW()
+) x`)
+	if err != nil {
+		return nil, err
+	}
+	var silences []alertSilence
+	if err := json.Unmarshal([]byte(out), &silences); err != nil {
+		return nil, fmt.Errorf("decode alert silences: %w", err)
+	}
+	return silences, nil
+}
+
+// logRetention bounds how much of a client's stored container log is kept.
+type logRetention struct {
+	SiteName   string `json:"site_name"`
+	MaxAgeDays int    `json:"max_age_days"`
+	MaxMB      int64  `json:"max_mb"`
+}
+
+func (r grengoRepository) UpsertLogRetention(p logRetention) error {
+	if p.SiteName == "" || p.MaxAgeDays <= 0 || p.MaxMB <= 0 {
+		return fmt.Errorf("invalid log retention")
+	}
+	sql := fmt.Sprintf(`
+INSERT INTO grengo_log_retention (site_name, max_age_days, max_mb, updated_at)
+VALUES (%s, %d, %d, NOW())
+ON CONFLICT (site_name) WHERE deleted_at IS NULL DO UPDATE SET
+  max_age_days = EXCLUDED.max_age_days,
+  max_mb = EXCLUDED.max_mb,
+  updated_at = NOW();`,
+		sqlLiteral(p.SiteName), p.MaxAgeDays, p.MaxMB)
+	return r.execSQL([]byte(sql))
+}
+
+// DeleteLogRetention returns a client to the default retention.
+func (r grengoRepository) DeleteLogRetention(siteName string) error {
+	sql := fmt.Sprintf(`UPDATE grengo_log_retention SET deleted_at=NOW(), updated_at=NOW() WHERE site_name=%s AND deleted_at IS NULL;`, sqlLiteral(siteName))
+	return r.execSQL([]byte(sql))
+}
+
+func (r grengoRepository) ListLogRetention() ([]logRetention, error) {
+	out, err := r.queryScalar(`
+SELECT COALESCE(json_agg(row_to_json(l) ORDER BY l.site_name), '[]')
+FROM (
+  SELECT site_name, max_age_days, max_mb
+  FROM grengo_log_retention
+  WHERE deleted_at IS NULL
+) l`)
+	if err != nil {
+		return nil, err
+	}
+	var policies []logRetention
+	if err := json.Unmarshal([]byte(out), &policies); err != nil {
+		return nil, fmt.Errorf("decode log retention: %w", err)
+	}
+	return policies, nil
+}
+
+// auditEntry is one control-plane action in the append-only audit log.
+type auditEntry struct {
+	ID           int64           `json:"id,omitempty"`
+	At           time.Time       `json:"at"`
+	Actor        string          `json:"actor"`
+	APIKeyPrefix string          `json:"api_key_prefix,omitempty"`
+	SourceIP     string          `json:"source_ip"`
+	Via          string          `json:"via"`
+	Command      string          `json:"command"`
+	Target       string          `json:"target,omitempty"`
+	Args         json.RawMessage `json:"args"`
+	Result       string          `json:"result"`
+	Error        string          `json:"error,omitempty"`
+	DurationMS   int64           `json:"duration_ms"`
+}
+
+// auditFilter selects audit entries. Newest come first unless Ascending,
+// which together with AfterID pages through the log for export.
+type auditFilter struct {
+	Actor     string
+	Command   string
+	Target    string
+	Result    string
+	Since     time.Time
+	Until     time.Time
+	AfterID   int64
+	Ascending bool
+	Limit     int
+}
+
+func (r grengoRepository) AppendAudit(e auditEntry) error {
+	args := string(e.Args)
+	if args == "" {
+		args = "{}"
+	}
+	sql := fmt.Sprintf(`
+INSERT INTO grengo_audit_log (at, actor, api_key_prefix, source_ip, via, command, target, args, result, error, duration_ms)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s::jsonb, %s, %s, %d);`,
+		sqlTimestamp(&e.At), sqlLiteral(e.Actor), sqlLiteral(e.APIKeyPrefix), sqlLiteral(e.SourceIP), sqlLiteral(e.Via),
+		sqlLiteral(e.Command), sqlLiteral(e.Target), sqlLiteral(args), sqlLiteral(e.Result), sqlLiteral(e.Error), e.DurationMS)
+	return r.execSQL([]byte(sql))
+}
+
+func (r grengoRepository) ListAudit(f auditFilter) ([]auditEntry, error) {
+	conds := []string{"TRUE"}
+	for col, v := range map[string]string{"actor": f.Actor, "command": f.Command, "target": f.Target, "result": f.Result} {
+		if v != "" {
+			conds = append(conds, col+"="+sqlLiteral(v))
+		}
+	}
+	if !f.Since.IsZero() {
+		conds = append(conds, "at >= "+sqlTimestamp(&f.Since))
+	}
+	if !f.Until.IsZero() {
+		conds = append(conds, "at <= "+sqlTimestamp(&f.Until))
+	}
+	if f.AfterID > 0 {
+		conds = append(conds, fmt.Sprintf("id > %d", f.AfterID))
+	}
+	order := "id DESC"
+	if f.Ascending {
+		order = "id"
+	}
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT COALESCE(json_agg(row_to_json(x) ORDER BY x.%s), '[]')
+FROM (
+  SELECT id, at, actor, api_key_prefix, source_ip, via, command, target, args, result, error, duration_ms
+  FROM grengo_audit_log
+  WHERE %s
+  ORDER BY %s
+  LIMIT %d
+) x`, order, strings.Join(conds, " AND "), order, f.Limit))
+	if err != nil {
+		return nil, err
+	}
+	var entries []auditEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		return nil, fmt.Errorf("decode audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// apiKeyScope grants an API key read and/or write access to one module.
+// The "grengo" module covers the control plane itself.
+type apiKeyScope struct {
+	Module string `json:"module"`
+	Read   bool   `json:"can_read"`
+	Write  bool   `json:"can_write"`
+}
+
+// apiKey is a row of grengo_api_keys with its module scopes. The raw key is
+// never stored; only its prefix and SHA-256.
+type apiKey struct {
+	ID            int64         `json:"id"`
+	Prefix        string        `json:"key_prefix"`
+	Name          string        `json:"name"`
+	UserID        int64         `json:"user_id"`
+	SiteScope     []string      `json:"site_scope"`
+	Scopes        []apiKeyScope `json:"scopes"`
+	RatePerMinute int           `json:"threshold_per_minute"`
+	CreatedAt     time.Time     `json:"created_at"`
+	ExpiresAt     *time.Time    `json:"expires_at"`
+	LastUsedAt    *time.Time    `json:"last_used_at"`
+	RevokedAt     *time.Time    `json:"revoked_at"`
+	RotatedTo     *int64        `json:"rotated_to"`
+}
+
+func sqlTextArray(values []string) string {
+	if len(values) == 0 {
+		return "'{}'::text[]"
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = sqlLiteral(v)
+	}
+	return "ARRAY[" + strings.Join(quoted, ",") + "]::text[]"
+}
+
+const apiKeySelectSQL = `
+SELECT k.id, k.key_prefix, k.name, k.user_id, k.site_scope, k.threshold_per_minute,
+  k.created_at, k.expires_at, k.last_used_at, k.revoked_at, k.rotated_to,
+  COALESCE((
+    SELECT json_agg(json_build_object('module', p.module, 'can_read', p.can_read, 'can_write', p.can_write) ORDER BY p.module)
+    FROM grengo_api_key_permissions p
+    WHERE p.api_key_id = k.id AND p.inactive_at IS NULL
+  ), '[]') AS scopes
+FROM grengo_api_keys k`
+
+// CreateAPIKey stores a new key and its scopes and returns its id.
+func (r grengoRepository) CreateAPIKey(k apiKey, hash string) (int64, error) {
+	perms := ""
+	if len(k.Scopes) > 0 {
+		rows := make([]string, len(k.Scopes))
+		for i, s := range k.Scopes {
+			rows[i] = fmt.Sprintf("(%s, %t, %t)", sqlLiteral(s.Module), s.Read, s.Write)
+		}
+		perms = fmt.Sprintf(`,
+perms AS (
+  INSERT INTO grengo_api_key_permissions (api_key_id, module, can_read, can_write)
+  SELECT k.id, v.module, v.can_read, v.can_write FROM k, (VALUES %s) AS v(module, can_read, can_write)
+)`, strings.Join(rows, ", "))
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+WITH k AS (
+  INSERT INTO grengo_api_keys (key_prefix, key_hash, user_id, name, site_scope, threshold_per_minute, expires_at)
+  VALUES (%s, %s, %d, %s, %s, %d, %s)
+  RETURNING id
+)%s
+SELECT id FROM k`,
+		sqlLiteral(k.Prefix), sqlLiteral(hash), k.UserID, sqlLiteral(k.Name), sqlTextArray(k.SiteScope),
+		k.RatePerMinute, sqlTimestamp(k.ExpiresAt), perms))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(out, 10, 64)
+}
+
+// RotateAPIKey replaces an active key with a new secret carrying the same
+// name, user, sites and scopes. The old key is revoked now, or expires after
+// grace so callers can switch over.
+func (r grengoRepository) RotateAPIKey(oldID int64, prefix, hash string, expiresAt *time.Time, grace time.Duration) (int64, error) {
+	retire := "revoked_at = NOW()"
+	if grace > 0 {
+		retire = fmt.Sprintf("expires_at = LEAST(COALESCE(grengo_api_keys.expires_at, 'infinity'), NOW() + INTERVAL '%d seconds')", int64(grace.Seconds()))
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+WITH old AS (
+  SELECT * FROM grengo_api_keys WHERE id = %d AND revoked_at IS NULL AND rotated_to IS NULL FOR UPDATE
+), k AS (
+  INSERT INTO grengo_api_keys (key_prefix, key_hash, user_id, name, site_scope, threshold_per_minute, expires_at)
+  SELECT %s, %s, user_id, name, site_scope, threshold_per_minute, %s FROM old
+  RETURNING id
+), perms AS (
+  INSERT INTO grengo_api_key_permissions (api_key_id, module, can_read, can_write)
+  SELECT k.id, p.module, p.can_read, p.can_write
+  FROM k, grengo_api_key_permissions p
+  WHERE p.api_key_id = %d AND p.inactive_at IS NULL
+), retired AS (
+  UPDATE grengo_api_keys SET rotated_to = k.id, %s FROM k WHERE grengo_api_keys.id = %d
+)
+SELECT id FROM k`,
+		oldID, sqlLiteral(prefix), sqlLiteral(hash), sqlTimestamp(expiresAt), oldID, retire, oldID))
+	if err != nil {
+		return 0, err
+	}
+	if out == "" {
+		return 0, fmt.Errorf("api key %d is revoked or already rotated", oldID)
+	}
+	return strconv.ParseInt(out, 10, 64)
+}
+
+// RevokeAPIKey disables a key immediately.
+func (r grengoRepository) RevokeAPIKey(id int64) error {
+	return r.execSQL([]byte(fmt.Sprintf(`UPDATE grengo_api_keys SET revoked_at = NOW() WHERE id = %d AND revoked_at IS NULL;`, id)))
+}
+
+// ListAPIKeys returns keys newest first; revoked and expired keys only
+// when all is set.
+func (r grengoRepository) ListAPIKeys(all bool) ([]apiKey, error) {
+	where := "TRUE"
+	if !all {
+		where = "k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())"
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT COALESCE(json_agg(row_to_json(x) ORDER BY x.id DESC), '[]')
+FROM (%s WHERE %s) x`, apiKeySelectSQL, where))
+	if err != nil {
+		return nil, err
+	}
+	var keys []apiKey
+	if err := json.Unmarshal([]byte(out), &keys); err != nil {
+		return nil, fmt.Errorf("decode api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// LookupAPIKey finds a key by prefix and hash, revoked or not. It returns
+// nil when no key matches.
+func (r grengoRepository) LookupAPIKey(prefix, hash string) (*apiKey, error) {
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT row_to_json(x) FROM (%s WHERE k.key_prefix = %s AND k.key_hash = %s) x`,
+		apiKeySelectSQL, sqlLiteral(prefix), sqlLiteral(hash)))
+	if err != nil || out == "" {
+		return nil, err
+	}
+	var k apiKey
+	if err := json.Unmarshal([]byte(out), &k); err != nil {
+		return nil, fmt.Errorf("decode api key: %w", err)
+	}
+	return &k, nil
+}
+
+// TouchAPIKey records that a key was just used.
+func (r grengoRepository) TouchAPIKey(id int64) error {
+	return r.execSQL([]byte(fmt.Sprintf(`UPDATE grengo_api_keys SET last_used_at = NOW() WHERE id = %d;`, id)))
+}
+
+// operator is a named account for the grengo API. PasswordHash and
+// TOTPSecret are only filled by FindOperator.
+type operator struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	Role         string     `json:"role"`
+	PasswordHash string     `json:"password_hash,omitempty"`
+	TOTPSecret   string     `json:"totp_secret,omitempty"`
+	TOTPEnabled  bool       `json:"totp_enabled"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastLoginAt  *time.Time `json:"last_login_at"`
+	DisabledAt   *time.Time `json:"disabled_at"`
+}
+
+// CreateOperator stores a new operator and returns its id.
+func (r grengoRepository) CreateOperator(o operator) (int64, error) {
+	totp := "NULL"
+	if o.TOTPSecret != "" {
+		totp = sqlLiteral(o.TOTPSecret)
+	}
+	out, err := r.queryScalar(fmt.Sprintf(`
+INSERT INTO grengo_operators (username, password_hash, role, totp_secret)
+VALUES (%s, %s, %s, %s)
+RETURNING id`, sqlLiteral(o.Username), sqlLiteral(o.PasswordHash), sqlLiteral(o.Role), totp))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(out, 10, 64)
+}
+
+// ListOperators returns operators without their secrets, by name.
+func (r grengoRepository) ListOperators() ([]operator, error) {
+	out, err := r.queryScalar(`
+SELECT COALESCE(json_agg(row_to_json(o) ORDER BY o.username), '[]')
+FROM (
+  SELECT id, username, role, totp_secret IS NOT NULL AS totp_enabled, created_at, last_login_at, disabled_at
+  FROM grengo_operators
+  WHERE deleted_at IS NULL
+) o`)
+	if err != nil {
+		return nil, err
+	}
+	var ops []operator
+	if err := json.Unmarshal([]byte(out), &ops); err != nil {
+		return nil, fmt.Errorf("decode operators: %w", err)
+	}
+	return ops, nil
+}
+
+// FindOperator loads an operator with its password hash and TOTP secret.
+// It returns nil when there is no such operator.
+func (r grengoRepository) FindOperator(username string) (*operator, error) {
+	out, err := r.queryScalar(fmt.Sprintf(`
+SELECT row_to_json(o) FROM (
+  SELECT id, username, role, password_hash, COALESCE(totp_secret, '') AS totp_secret,
+    totp_secret IS NOT NULL AS totp_enabled, created_at, last_login_at, disabled_at
+  FROM grengo_operators
+  WHERE username = %s AND deleted_at IS NULL
+) o`, sqlLiteral(username)))
+	if err != nil || out == "" {
+		return nil, err
+	}
+	var o operator
+	if err := json.Unmarshal([]byte(out), &o); err != nil {
+		return nil, fmt.Errorf("decode operator: %w", err)
+	}
+	return &o, nil
+}
+
+// operatorUpdate lists the fields of an operator to change; nil fields are
+// kept. An empty TOTPSecret turns TOTP off.
+type operatorUpdate struct {
+	Role         *string
+	PasswordHash *string
+	TOTPSecret   *string
+	Disabled     *bool
+	LoggedIn     bool
+}
+
+func (r grengoRepository) UpdateOperator(username string, u operatorUpdate) error {
+	set := []string{"updated_at=NOW()"}
+	if u.Role != nil {
+		set = append(set, "role="+sqlLiteral(*u.Role))
+	}
+	if u.PasswordHash != nil {
+		set = append(set, "password_hash="+sqlLiteral(*u.PasswordHash))
+	}
+	if u.TOTPSecret != nil {
+		if *u.TOTPSecret == "" {
+			set = append(set, "totp_secret=NULL")
+		} else {
+			set = append(set, "totp_secret="+sqlLiteral(*u.TOTPSecret))
+		}
+	}
+	if u.Disabled != nil {
+		if *u.Disabled {
+			set = append(set, "disabled_at=COALESCE(disabled_at, NOW())")
+		} else {
+			set = append(set, "disabled_at=NULL")
+		}
+	}
+	if u.LoggedIn {
+		set = []string{"last_login_at=NOW()"}
+	}
+	sql := fmt.Sprintf(`UPDATE grengo_operators SET %s WHERE username=%s AND deleted_at IS NULL;`,
+		strings.Join(set, ", "), sqlLiteral(username))
+	return r.execSQL([]byte(sql))
+}
+
+// DeleteOperator removes an operator; the row is kept for the audit log.
+func (r grengoRepository) DeleteOperator(username string) error {
+	sql := fmt.Sprintf(`UPDATE grengo_operators SET deleted_at=NOW(), updated_at=NOW() WHERE username=%s AND deleted_at IS NULL;`, sqlLiteral(username))
+	return r.execSQL([]byte(sql))
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io/fs"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 //go:embed migrations/*.sql
@@ -21,7 +23,7 @@ type grengoService struct {
 }
 
 func sqlLiteral(s string) string {
-	return fmt.Sprintf("'%s'", s)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
 
 func newGrengoService() grengoService {
@@ -82,6 +84,272 @@ func (s grengoService) RecordFrappeAllocation(record frappeAllocation) error {
 	return s.repo.RecordFrappeAllocation(record)
 }
 
+func (s grengoService) ListFrappeClusters() ([]frappeCluster, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListFrappeClusters()
+}
+
+func (s grengoService) UpsertBackupSchedule(schedule backupSchedule) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.UpsertBackupSchedule(schedule)
+}
+
+func (s grengoService) DeleteBackupSchedule(siteName string) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.DeleteBackupSchedule(siteName)
+}
+
+func (s grengoService) ListBackupSchedules() ([]backupSchedule, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListBackupSchedules()
+}
+
+func (s grengoService) StartBackupRun(scheduleID int64, siteName, jobID, archiveName string) (int64, error) {
+	if err := s.EnsureReady(); err != nil {
+		return 0, err
+	}
+	return s.repo.StartBackupRun(scheduleID, siteName, jobID, archiveName)
+}
+
+func (s grengoService) FinishBackupRun(runID, scheduleID int64, status, errText string) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.FinishBackupRun(runID, scheduleID, status, errText)
+}
+
+func (s grengoService) MarkBackupsPruned(archiveNames []string) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.MarkBackupsPruned(archiveNames)
+}
+
+func (s grengoService) RecordRelease(rel release) (int64, error) {
+	if err := s.EnsureReady(); err != nil {
+		return 0, err
+	}
+	return s.repo.RecordRelease(rel)
+}
+
+func (s grengoService) ListReleases(siteName string) ([]release, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReleases(siteName)
+}
+
+func (s grengoService) RecordWebhookDelivery(d webhookDelivery) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.RecordWebhookDelivery(d)
+}
+
+func (s grengoService) WebhookDeliveryAccepted(provider, deliveryID string) (bool, error) {
+	if err := s.EnsureReady(); err != nil {
+		return false, err
+	}
+	return s.repo.WebhookDeliveryAccepted(provider, deliveryID)
+}
+
+func (s grengoService) ListWebhookDeliveries(siteName string, limit int) ([]webhookDelivery, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListWebhookDeliveries(siteName, limit)
+}
+
+func (s grengoService) UpsertSiteQuota(q siteQuota) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.UpsertSiteQuota(q)
+}
+
+func (s grengoService) DeleteSiteQuota(siteName string) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.DeleteSiteQuota(siteName)
+}
+
+func (s grengoService) ListSiteQuotas(siteName string) ([]siteQuota, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSiteQuotas(siteName)
+}
+
+func (s grengoService) RecordQuotaCheck(q siteQuota) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.RecordQuotaCheck(q)
+}
+
+func (s grengoService) OpenAlert(a alertRecord) (int64, error) {
+	if err := s.EnsureReady(); err != nil {
+		return 0, err
+	}
+	return s.repo.OpenAlert(a)
+}
+
+func (s grengoService) UpdateAlert(a alertRecord) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.UpdateAlert(a)
+}
+
+func (s grengoService) ListAlerts(firingOnly bool, limit int) ([]alertRecord, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAlerts(firingOnly, limit)
+}
+
+func (s grengoService) AddAlertSilence(silence alertSilence) (int64, error) {
+	if err := s.EnsureReady(); err != nil {
+		return 0, err
+	}
+	return s.repo.AddAlertSilence(silence)
+}
+
+func (s grengoService) DeleteAlertSilence(id int64) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.DeleteAlertSilence(id)
+}
+
+func (s grengoService) ListAlertSilences() ([]alertSilence, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAlertSilences()
+}
+
+func (s grengoService) UpsertLogRetention(p logRetention) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.UpsertLogRetention(p)
+}
+
+func (s grengoService) DeleteLogRetention(siteName string) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.DeleteLogRetention(siteName)
+}
+
+func (s grengoService) ListLogRetention() ([]logRetention, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListLogRetention()
+}
+
+func (s grengoService) AppendAudit(e auditEntry) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.AppendAudit(e)
+}
+
+func (s grengoService) ListAudit(f auditFilter) ([]auditEntry, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAudit(f)
+}
+
+func (s grengoService) CreateAPIKey(k apiKey, hash string) (int64, error) {
+	if err := s.EnsureReady(); err != nil {
+		return 0, err
+	}
+	return s.repo.CreateAPIKey(k, hash)
+}
+
+func (s grengoService) RotateAPIKey(oldID int64, prefix, hash string, expiresAt *time.Time, grace time.Duration) (int64, error) {
+	if err := s.EnsureReady(); err != nil {
+		return 0, err
+	}
+	return s.repo.RotateAPIKey(oldID, prefix, hash, expiresAt, grace)
+}
+
+func (s grengoService) RevokeAPIKey(id int64) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.RevokeAPIKey(id)
+}
+
+func (s grengoService) ListAPIKeys(all bool) ([]apiKey, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAPIKeys(all)
+}
+
+func (s grengoService) LookupAPIKey(prefix, hash string) (*apiKey, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.LookupAPIKey(prefix, hash)
+}
+
+func (s grengoService) TouchAPIKey(id int64) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.TouchAPIKey(id)
+}
+
+func (s grengoService) CreateOperator(o operator) (int64, error) {
+	if err := s.EnsureReady(); err != nil {
+		return 0, err
+	}
+	return s.repo.CreateOperator(o)
+}
+
+func (s grengoService) ListOperators() ([]operator, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.ListOperators()
+}
+
+func (s grengoService) FindOperator(username string) (*operator, error) {
+	if err := s.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return s.repo.FindOperator(username)
+}
+
+func (s grengoService) UpdateOperator(username string, u operatorUpdate) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.UpdateOperator(username, u)
+}
+
+func (s grengoService) DeleteOperator(username string) error {
+	if err := s.EnsureReady(); err != nil {
+		return err
+	}
+	return s.repo.DeleteOperator(username)
+}
+
 func (s grengoService) runMigrations() error {
 	entries, err := fs.ReadDir(grengoMigrationFiles, "migrations")
 	if err != nil {
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func chunkAll(data []byte) [][]byte {
+	var chunks [][]byte
+	for len(data) > 0 {
+		n := cdcCut(data)
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return chunks
+}
+
+func TestCDCBoundariesSurviveInsertions(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]byte, 4<<20)
+	rng.Read(data)
+
+	before := map[string]bool{}
+	for _, c := range chunkAll(data) {
+		if len(c) > chunkMaxSize {
+			t.Fatalf("chunk of %d bytes exceeds max", len(c))
+		}
+		before[string(c)] = true
+	}
+
+	// Insert a few bytes near the start: only the chunks around the edit
+	// should change.
+	edited := append(append(append([]byte{}, data[:1000]...), []byte("inserted")...), data[1000:]...)
+	after := chunkAll(edited)
+	reused := 0
+	for _, c := range after {
+		if before[string(c)] {
+			reused++
+		}
+	}
+	if reused < len(after)-2 {
+		t.Fatalf("only %d of %d chunks reused after a small insertion", reused, len(after))
+	}
+}
+
+func writeTestSnapshot(t *testing.T, store chunkStore, id string, uploads []byte) snapshot {
+	t.Helper()
+	w := newSnapshotWriter(store, snapshot{ID: id, Type: "client", Name: "a", CreatedAt: time.Now().UTC()})
+	writeMeta(w, archiveMeta{Version: archiveVersion, Type: "client", Name: "a"})
+	addBytesToArchive(w, []byte("PORT=1080\n"), "env")
+	// Feed the large entry in odd-sized writes to check boundaries do not
+	// depend on write sizes.
+	w.WriteHeader(&tar.Header{Name: "uploads/big.bin", Mode: 0644, Size: int64(len(uploads))})
+	for i := 0; i < len(uploads); i += 7919 {
+		w.Write(uploads[i:min(i+7919, len(uploads))])
+	}
+	return w.commit()
+}
+
+func TestSnapshotDeduplicatesAndRestores(t *testing.T) {
+	store := chunkStore{dir: t.TempDir()}
+	uploads := make([]byte, 3<<20)
+	rand.New(rand.NewSource(2)).Read(uploads)
+
+	first := writeTestSnapshot(t, store, "client-a-1", uploads)
+	if first.NewBytes != first.Size {
+		t.Fatalf("first snapshot stored %d new of %d bytes", first.NewBytes, first.Size)
+	}
+	second := writeTestSnapshot(t, store, "client-a-2", uploads)
+	if second.NewBytes > 1024 {
+		t.Fatalf("unchanged snapshot stored %d new bytes", second.NewBytes)
+	}
+
+	out := filepath.Join(t.TempDir(), "restored.tar.gz")
+	materializeSnapshot(store, second, out)
+	files, err := loadArchive(out, archiveOptions{})
+	if err != nil {
+		t.Fatalf("loadArchive: %v", err)
+	}
+	if _, err := verifyArchive(out, files, archiveOptions{AllowUnsigned: true}); err != nil {
+		t.Fatalf("verifyArchive: %v", err)
+	}
+	if !bytes.Equal(files["uploads/big.bin"], uploads) || string(files["env"]) != "PORT=1080\n" {
+		t.Fatal("restored archive content differs from the snapshot input")
+	}
+	if parseMeta(files).Name != "a" {
+		t.Fatal("restored meta.json lost the client name")
+	}
+
+	snaps, err := store.snapshots()
+	if err != nil || len(snaps) != 2 {
+		t.Fatalf("snapshots() = %d, %v", len(snaps), err)
+	}
+}
+
+func TestSnapshotAt(t *testing.T) {
+	base := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+	snaps := []snapshot{
+		{ID: "a", CreatedAt: base},
+		{ID: "b", CreatedAt: base.Add(24 * time.Hour)},
+		{ID: "c", CreatedAt: base.Add(48 * time.Hour)},
+	}
+	if s, ok := snapshotAt(snaps, base.Add(30*time.Hour)); !ok || s.ID != "b" {
+		t.Fatalf("snapshotAt = %q, %v; want b", s.ID, ok)
+	}
+	if _, ok := snapshotAt(snaps, base.Add(-time.Minute)); ok {
+		t.Fatal("snapshotAt returned a snapshot newer than the requested time")
+	}
+}
@@ -2,9 +2,14 @@ package app
 
 import (
 	"archive/tar"
+	"bufio"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/fs"
 	"os"
@@ -28,26 +33,31 @@ type archiveMeta struct {
 
 // Client Export
 // cmdExportClient packs a single client (env, compose, uploads, DB dump) into
-// a portable tar.gz archive.
-func cmdExportClient(name, outFile string) {
+// a portable tar.gz archive. opts selects encryption, signing and an optional
+// backup target the finished archive is uploaded to.
+func cmdExportClient(name, outFile string, opts archiveOptions) {
 	if !clientExists(name) {
 		die("Client '%s' not found", name)
 	}
+	if opts.Incremental {
+		snapshotClient(name, opts)
+		return
+	}
+	opts = defaultSigning(opts)
 	if outFile == "" {
 		outFile = fmt.Sprintf("grengo-client-%s-%s.tar.gz", name, time.Now().Format("20060102-150405"))
 	}
 
-	f, err := os.Create(outFile)
-	if err != nil {
-		die("Cannot create archive: %v", err)
+	tw := createArchive(outFile, opts)
+	writeClientArchive(tw, name)
+	finishArchive(tw, outFile, opts)
+	log("Client '%s' exported => %s", name, outFile)
+	if opts.Target != "" {
+		uploadToTarget(opts.Target, outFile)
 	}
-	defer f.Close()
-
-	gw := gzip.NewWriter(f)
-	defer gw.Close()
-	tw := tar.NewWriter(gw)
-	defer tw.Close()
+}
 
+func writeClientArchive(tw archiveSink, name string) {
 	writeMeta(tw, archiveMeta{
 		Version:    archiveVersion,
 		Type:       "client",
@@ -75,15 +85,23 @@ func cmdExportClient(name, outFile string) {
 	} else {
 		warn("PostgreSQL is not running - archive will not include DB data")
 	}
-
-	log("Client '%s' exported => %s", name, outFile)
 }
 
 // Client Import
 // cmdImportClient restores a single-client archive onto this node.
 // newName overrides the archived client name; newPort overrides the port.
-func cmdImportClient(archivePath, newName, newPort string) {
-	files := readArchive(archivePath)
+// When opts.Target is set, archivePath names an archive on that backup target.
+// With opts.DryRun it only prints the import plan.
+func cmdImportClient(archivePath, newName, newPort string, opts archiveOptions) {
+	if opts.DryRun {
+		dryRunImport(archivePath, newName, newPort, opts)
+		return
+	}
+	if opts.Target != "" {
+		archivePath = fetchFromTarget(opts.Target, archivePath)
+		defer removeArchive(archivePath)
+	}
+	files := readArchive(archivePath, opts)
 	meta := parseMeta(files)
 
 	if meta.Type != "client" {
@@ -117,8 +135,9 @@ func cmdImportClient(archivePath, newName, newPort string) {
 }
 
 // Node Export
-// cmdExportNode packs every client on this node into a single tar.gz archive.
-func cmdExportNode(outFile string) {
+// cmdExportNode packs every client on this node into a single tar.gz archive,
+// encrypting, signing and uploading it as opts requests.
+func cmdExportNode(outFile string, opts archiveOptions) {
 	entries, err := os.ReadDir(backendsDir())
 	if err != nil || len(entries) == 0 {
 		die("No clients found to export")
@@ -137,21 +156,25 @@ func cmdExportNode(outFile string) {
 		die("No valid clients to export")
 	}
 
+	if opts.Incremental {
+		snapshotNode(names, opts)
+		return
+	}
+	opts = defaultSigning(opts)
 	if outFile == "" {
 		outFile = fmt.Sprintf("grengo-node-%s.tar.gz", time.Now().Format("20060102-150405"))
 	}
 
-	f, err := os.Create(outFile)
-	if err != nil {
-		die("Cannot create archive: %v", err)
+	tw := createArchive(outFile, opts)
+	writeNodeArchive(tw, names)
+	finishArchive(tw, outFile, opts)
+	log("Node exported => %s  (%d client(s))", outFile, len(names))
+	if opts.Target != "" {
+		uploadToTarget(opts.Target, outFile)
 	}
-	defer f.Close()
-
-	gw := gzip.NewWriter(f)
-	defer gw.Close()
-	tw := tar.NewWriter(gw)
-	defer tw.Close()
+}
 
+func writeNodeArchive(tw archiveSink, names []string) {
 	writeMeta(tw, archiveMeta{
 		Version:    archiveVersion,
 		Type:       "node",
@@ -185,15 +208,23 @@ func cmdExportNode(outFile string) {
 			}
 		}
 	}
-
-	log("Node exported => %s  (%d client(s))", outFile, len(names))
 }
 
 // Node Import
 // cmdImportNode restores all clients from a node archive onto this node.
 // Clients that already exist are skipped; port conflicts are auto-resolved.
-func cmdImportNode(archivePath string) {
-	files := readArchive(archivePath)
+// When opts.Target is set, archivePath names an archive on that backup target.
+// With opts.DryRun it only prints the import plan.
+func cmdImportNode(archivePath string, opts archiveOptions) {
+	if opts.DryRun {
+		dryRunImport(archivePath, "", "", opts)
+		return
+	}
+	if opts.Target != "" {
+		archivePath = fetchFromTarget(opts.Target, archivePath)
+		defer removeArchive(archivePath)
+	}
+	files := readArchive(archivePath, opts)
 	meta := parseMeta(files)
 
 	if meta.Type != "node" {
@@ -329,18 +360,142 @@ func resolvePort(archived, override string) string {
 }
 
 // Archive low-level helpers
-// readArchive opens a .tar.gz file and returns all regular-file contents
-// keyed by their path inside the archive.
-func readArchive(path string) map[string][]byte {
+// archiveSink receives archive entries. It is implemented by archiveWriter
+// (tar.gz files) and snapshotWriter (the incremental chunk store), so the
+// same export code feeds both.
+type archiveSink interface {
+	WriteHeader(hdr *tar.Header) error
+	Write(p []byte) (int, error)
+}
+
+// archiveWriter wraps the tar writer of an export and records the SHA-256
+// of every entry so finishArchive can write manifest.json.
+type archiveWriter struct {
+	tw      *tar.Writer
+	closers []io.Closer
+	sums    map[string]string
+	name    string
+	hash    hash.Hash
+}
+
+func (w *archiveWriter) WriteHeader(hdr *tar.Header) error {
+	w.recordSum()
+	w.name, w.hash = hdr.Name, sha256.New()
+	return w.tw.WriteHeader(hdr)
+}
+
+func (w *archiveWriter) Write(p []byte) (int, error) {
+	n, err := w.tw.Write(p)
+	w.hash.Write(p[:n])
+	return n, err
+}
+
+func (w *archiveWriter) recordSum() {
+	if w.hash != nil {
+		w.sums[w.name] = hex.EncodeToString(w.hash.Sum(nil))
+		w.hash = nil
+	}
+}
+
+// archiveManifest is written as the last entry (manifest.json) of every
+// archive and is what detached signatures cover.
+type archiveManifest struct {
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"` // entry name => hex SHA-256
+}
+
+// createArchive creates outFile as a tar.gz, encrypted when opts carries a
+// passphrase or recipient.
+func createArchive(outFile string, opts archiveOptions) *archiveWriter {
+	f, err := os.Create(outFile)
+	if err != nil {
+		die("Cannot create archive: %v", err)
+	}
+	w := &archiveWriter{sums: map[string]string{}, closers: []io.Closer{f}}
+
+	var dst io.Writer = f
+	if opts.Passphrase != "" || opts.Recipient != "" {
+		enc, err := newArchiveEncryptor(f, opts)
+		if err != nil {
+			f.Close()
+			os.Remove(outFile)
+			die("Cannot encrypt archive: %v", err)
+		}
+		w.closers = append(w.closers, enc)
+		dst = enc
+	}
+	gw := gzip.NewWriter(dst)
+	w.tw = tar.NewWriter(gw)
+	w.closers = append(w.closers, gw, w.tw)
+	return w
+}
+
+// finishArchive appends manifest.json, closes every layer of the archive and
+// writes the detached signature when requested.
+func finishArchive(w *archiveWriter, outFile string, opts archiveOptions) {
+	w.recordSum()
+	manifest, _ := json.MarshalIndent(archiveManifest{Version: archiveVersion, Files: w.sums}, "", "  ")
+	addBytesToArchive(w, manifest, "manifest.json")
+	w.hash = nil
+
+	for i := len(w.closers) - 1; i >= 0; i-- {
+		if err := w.closers[i].Close(); err != nil {
+			die("Cannot finalise archive: %v", err)
+		}
+	}
+	if opts.Passphrase != "" || opts.Recipient != "" {
+		log("Archive encrypted")
+	}
+	if opts.Sign {
+		signManifest(outFile, manifest)
+	}
+}
+
+// removeArchive deletes an archive together with its detached signature.
+func removeArchive(path string) {
+	os.Remove(path)
+	os.Remove(path + ".sig")
+}
+
+// readArchive opens a (possibly encrypted) .tar.gz file, verifies it against
+// its manifest and signature and returns all regular-file contents keyed by
+// their path inside the archive.
+func readArchive(path string, opts archiveOptions) map[string][]byte {
+	files, err := loadArchive(path, opts)
+	if err != nil {
+		die("%v", err)
+	}
+	signer, err := verifyArchive(path, files, opts)
+	if err != nil {
+		die("Refusing to import %s: %v", filepath.Base(path), err)
+	}
+	if signer != "" {
+		log("Signature verified (%s)", signer)
+	}
+	return files
+}
+
+func loadArchive(path string, opts archiveOptions) (map[string][]byte, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		die("Cannot open archive: %v", err)
+		return nil, fmt.Errorf("Cannot open archive: %v", err)
 	}
 	defer f.Close()
 
-	gr, err := gzip.NewReader(f)
+	br := bufio.NewReader(f)
+	var src io.Reader = br
+	if isEncryptedArchive(br) {
+		if src, err = newArchiveDecryptor(br, opts); err != nil {
+			return nil, fmt.Errorf("Cannot decrypt archive: %v", err)
+		}
+	}
+
+	gr, err := gzip.NewReader(src)
 	if err != nil {
-		die("Not a valid gzip archive: %v", err)
+		if errors.Is(err, errArchiveAuth) {
+			return nil, fmt.Errorf("Cannot decrypt archive: %v", err)
+		}
+		return nil, fmt.Errorf("Not a valid gzip archive: %v", err)
 	}
 	defer gr.Close()
 
@@ -351,42 +506,109 @@ func readArchive(path string) map[string][]byte {
 		if err == io.EOF {
 			break
 		}
+		if errors.Is(err, errArchiveAuth) {
+			return nil, fmt.Errorf("Cannot decrypt archive: %v", err)
+		}
 		if err != nil {
-			die("Corrupt archive: %v", err)
+			return nil, fmt.Errorf("Corrupt archive: %v", err)
 		}
 		if hdr.Typeflag != tar.TypeReg {
 			continue
 		}
 		data, err := io.ReadAll(tr)
 		if err != nil {
-			die("Error reading %s from archive: %v", hdr.Name, err)
+			return nil, fmt.Errorf("Error reading %s from archive: %v", hdr.Name, err)
 		}
 		files[hdr.Name] = data
 	}
-	return files
+	// Drain the rest so every encrypted chunk, including the final one, is
+	// authenticated.
+	if _, err := io.Copy(io.Discard, src); err != nil {
+		return nil, fmt.Errorf("Cannot decrypt archive: %v", err)
+	}
+	return files, nil
+}
+
+// verifyArchive checks every entry against manifest.json and the detached
+// <path>.sig signature. Archives without a manifest or a trusted signature
+// are refused unless opts.AllowUnsigned or GRENGO_ALLOW_UNSIGNED_ARCHIVES=1
+// opts out. It returns the signer key.
+func verifyArchive(path string, files map[string][]byte, opts archiveOptions) (string, error) {
+	allowUnsigned := opts.AllowUnsigned || envVal(rootEnvFile(), "GRENGO_ALLOW_UNSIGNED_ARCHIVES") == "1"
+	manifestData, hasManifest := files["manifest.json"]
+	if !hasManifest && !allowUnsigned {
+		return "", fmt.Errorf("archive has no manifest - re-export it or pass --allow-unsigned")
+	}
+	if hasManifest {
+		var m archiveManifest
+		if err := json.Unmarshal(manifestData, &m); err != nil {
+			return "", fmt.Errorf("invalid manifest.json: %v", err)
+		}
+		for name, data := range files {
+			if name == "manifest.json" {
+				continue
+			}
+			want, ok := m.Files[name]
+			if !ok {
+				return "", fmt.Errorf("%s is not listed in the manifest", name)
+			}
+			sum := sha256.Sum256(data)
+			if hex.EncodeToString(sum[:]) != want {
+				return "", fmt.Errorf("checksum mismatch for %s", name)
+			}
+		}
+		for name := range m.Files {
+			if _, ok := files[name]; !ok {
+				return "", fmt.Errorf("%s is listed in the manifest but missing", name)
+			}
+		}
+	}
+
+	sigData, err := os.ReadFile(path + ".sig")
+	if os.IsNotExist(err) {
+		if !allowUnsigned {
+			return "", fmt.Errorf("no signature found at %s.sig - sign exports with 'grengo keys init' or pass --allow-unsigned", path)
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !hasManifest {
+		return "", fmt.Errorf("archive is signed but has no manifest")
+	}
+	return verifyManifestSignature(sigData, manifestData, trustedSigners())
 }
 
 // parseMeta extracts and validates the meta.json entry from the file map.
 func parseMeta(files map[string][]byte) archiveMeta {
+	m, err := decodeMeta(files)
+	if err != nil {
+		die("%v", err)
+	}
+	return m
+}
+
+func decodeMeta(files map[string][]byte) (archiveMeta, error) {
+	var m archiveMeta
 	data, ok := files["meta.json"]
 	if !ok {
-		die("Invalid archive - meta.json not found")
+		return m, errors.New("Invalid archive - meta.json not found")
 	}
-	var m archiveMeta
 	if err := json.Unmarshal(data, &m); err != nil {
-		die("Invalid meta.json: %v", err)
+		return m, fmt.Errorf("Invalid meta.json: %v", err)
 	}
-	return m
+	return m, nil
 }
 
 // writeMeta serialises m as meta.json and writes it as the first tar entry.
-func writeMeta(tw *tar.Writer, m archiveMeta) {
+func writeMeta(tw archiveSink, m archiveMeta) {
 	data, _ := json.MarshalIndent(m, "", "  ")
 	addBytesToArchive(tw, data, "meta.json")
 }
 
 // addBytesToArchive writes raw bytes as a named regular-file entry.
-func addBytesToArchive(tw *tar.Writer, data []byte, name string) {
+func addBytesToArchive(tw archiveSink, data []byte, name string) {
 	hdr := &tar.Header{
 		Name:    name,
 		Mode:    0644,
@@ -424,7 +646,7 @@ func (pr *progressReader) Read(p []byte) (int, error) {
 }
 
 // addFileToArchive opens src from disk and streams it to the archive as archiveName.
-func addFileToArchive(tw *tar.Writer, src, archiveName string, pr *progressReader) {
+func addFileToArchive(tw archiveSink, src, archiveName string, pr *progressReader) {
 	info, err := os.Stat(src)
 	if err != nil {
 		warn("Skipping %s (stat failed): %v", src, err)
@@ -460,7 +682,7 @@ func addFileToArchive(tw *tar.Writer, src, archiveName string, pr *progressReade
 }
 
 // addDirToArchive walks dir recursively and adds each file under archivePrefix/.
-func addDirToArchive(tw *tar.Writer, dir, archivePrefix string) {
+func addDirToArchive(tw archiveSink, dir, archivePrefix string) {
 	archivePrefix = strings.TrimRight(archivePrefix, "/")
 
 	var totalBytes int64
@@ -495,7 +717,7 @@ func addDirToArchive(tw *tar.Writer, dir, archivePrefix string) {
 
 // Postgres helpers
 // addPgDumpToArchive runs pg_dump inside the postgres container and streams the output to the tar archive.
-func addPgDumpToArchive(tw *tar.Writer, dbName, archiveName string) error {
+func addPgDumpToArchive(tw archiveSink, dbName, archiveName string) error {
 	env := loadSharedEnv()
 	cmd := exec.Command(
 		"docker", "exec", "skaia-postgres",
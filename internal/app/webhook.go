@@ -0,0 +1,511 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxWebhookBody caps the payload size accepted from a git host.
+	maxWebhookBody = 5 << 20
+
+	defaultWebhookBranch = "main"
+
+	// webhookRejectInterval is how often the same rejection (provider, site
+	// and reason) reaches the delivery log; repeats in between are counted
+	// and folded into the next entry.
+	webhookRejectInterval = time.Minute
+)
+
+var webhookRejects = struct {
+	sync.Mutex
+	last       map[string]time.Time
+	suppressed map[string]int
+}{last: map[string]time.Time{}, suppressed: map[string]int{}}
+
+// webhookEvent is a push (or other event) normalised from any provider.
+type webhookEvent struct {
+	Event    string // "push", "ping", or the provider's name for anything else
+	Delivery string
+	Ref      string
+	Commit   string
+	Pusher   string
+	Files    []string
+}
+
+// webhookProvider verifies and parses one git host's webhook format.
+type webhookProvider struct {
+	verify func(r *http.Request, body []byte, secret string) bool
+	parse  func(r *http.Request, body []byte) (webhookEvent, error)
+}
+
+var webhookProviders = map[string]webhookProvider{
+	"github":  {verify: verifyGitHubSignature, parse: parseGitHubPush},
+	"gitea":   {verify: verifyGiteaSignature, parse: parseGiteaPush},
+	"gitlab":  {verify: verifyGitLabToken, parse: parseGitLabPush},
+	"generic": {verify: verifyGenericSignature, parse: parseGenericPush},
+}
+
+// validHMAC reports whether sigHex is the hex HMAC-SHA256 of body.
+func validHMAC(body []byte, secret, sigHex string) bool {
+	sig, err := hex.DecodeString(strings.TrimSpace(sigHex))
+	if err != nil || len(sig) != sha256.Size {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+func verifyGitHubSignature(r *http.Request, body []byte, secret string) bool {
+	sig, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+	return ok && validHMAC(body, secret, sig)
+}
+
+func verifyGiteaSignature(r *http.Request, body []byte, secret string) bool {
+	if sig := r.Header.Get("X-Gitea-Signature"); sig != "" {
+		return validHMAC(body, secret, sig)
+	}
+	return verifyGitHubSignature(r, body, secret)
+}
+
+// verifyGitLabToken checks the shared token GitLab sends verbatim.
+func verifyGitLabToken(r *http.Request, _ []byte, secret string) bool {
+	token := r.Header.Get("X-Gitlab-Token")
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+func verifyGenericSignature(r *http.Request, body []byte, secret string) bool {
+	sig, ok := strings.CutPrefix(r.Header.Get("X-Grengo-Signature"), "sha256=")
+	return ok && validHMAC(body, secret, sig)
+}
+
+// gitPushPayload covers the push payloads of GitHub, Gitea and GitLab, which
+// share ref and commits but name the pusher and head commit differently.
+type gitPushPayload struct {
+	Ref          string `json:"ref"`
+	After        string `json:"after"`
+	CheckoutSHA  string `json:"checkout_sha"`
+	UserUsername string `json:"user_username"`
+	Pusher       struct {
+		Name     string `json:"name"`
+		Login    string `json:"login"`
+		Username string `json:"username"`
+	} `json:"pusher"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Removed  []string `json:"removed"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+func (p gitPushPayload) event(kind, delivery string) webhookEvent {
+	ev := webhookEvent{Event: kind, Delivery: delivery, Ref: p.Ref, Commit: p.After}
+	if p.CheckoutSHA != "" {
+		ev.Commit = p.CheckoutSHA
+	}
+	for _, name := range []string{p.Pusher.Login, p.Pusher.Username, p.Pusher.Name, p.UserUsername} {
+		if name != "" {
+			ev.Pusher = name
+			break
+		}
+	}
+	for _, c := range p.Commits {
+		ev.Files = append(ev.Files, c.Added...)
+		ev.Files = append(ev.Files, c.Removed...)
+		ev.Files = append(ev.Files, c.Modified...)
+	}
+	return ev
+}
+
+func decodePush(body []byte, kind, delivery string) (webhookEvent, error) {
+	var p gitPushPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return webhookEvent{}, fmt.Errorf("invalid payload: %v", err)
+	}
+	return p.event(kind, delivery), nil
+}
+
+func parseGitHubPush(r *http.Request, body []byte) (webhookEvent, error) {
+	kind := r.Header.Get("X-GitHub-Event")
+	if kind == "" {
+		kind = "push"
+	}
+	return decodePush(body, kind, r.Header.Get("X-GitHub-Delivery"))
+}
+
+func parseGiteaPush(r *http.Request, body []byte) (webhookEvent, error) {
+	kind := r.Header.Get("X-Gitea-Event")
+	if kind == "" {
+		kind = "push"
+	}
+	return decodePush(body, kind, r.Header.Get("X-Gitea-Delivery"))
+}
+
+func parseGitLabPush(r *http.Request, body []byte) (webhookEvent, error) {
+	kind := r.Header.Get("X-Gitlab-Event")
+	if kind == "Push Hook" {
+		kind = "push"
+	}
+	return decodePush(body, kind, r.Header.Get("X-Gitlab-Event-UUID"))
+}
+
+// parseGenericPush reads the minimal JSON format for CI systems and scripts:
+// ref, commit, pusher and the changed files. Omitting files deploys
+// unconditionally.
+func parseGenericPush(r *http.Request, body []byte) (webhookEvent, error) {
+	var p struct {
+		Ref    string   `json:"ref"`
+		Commit string   `json:"commit"`
+		Pusher string   `json:"pusher"`
+		Files  []string `json:"files"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return webhookEvent{}, fmt.Errorf("invalid payload: %v", err)
+	}
+	kind := r.Header.Get("X-Grengo-Event")
+	if kind == "" {
+		kind = "push"
+	}
+	return webhookEvent{Event: kind, Delivery: r.Header.Get("X-Grengo-Delivery"), Ref: p.Ref, Commit: p.Commit, Pusher: p.Pusher, Files: p.Files}, nil
+}
+
+// webhookSecret returns the secret for a delivery: the client's own
+// WEBHOOK_SECRET for /webhook/<provider>/<client>, falling back to the
+// node-wide GRENGO_WEBHOOK_SECRET.
+func webhookSecret(client string) string {
+	if client != "" {
+		if s := envVal(clientEnvFile(client), "WEBHOOK_SECRET"); s != "" {
+			return s
+		}
+	}
+	return envVal(rootEnvFile(), "GRENGO_WEBHOOK_SECRET")
+}
+
+// trackedBranches returns the branch patterns a client deploys from
+// (WEBHOOK_BRANCHES, comma-separated, default "main"). Patterns use
+// path.Match syntax, e.g. "release/*".
+func trackedBranches(client string) []string {
+	var branches []string
+	for _, b := range strings.Split(envVal(clientEnvFile(client), "WEBHOOK_BRANCHES"), ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			branches = append(branches, b)
+		}
+	}
+	if len(branches) == 0 {
+		return []string{defaultWebhookBranch}
+	}
+	return branches
+}
+
+func tracksBranch(client, branch string) bool {
+	for _, pattern := range trackedBranches(client) {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookTargets returns the enabled clients a push to branch deploys:
+// only client when the URL names one, otherwise every client tracking it.
+func webhookTargets(client, branch string) []string {
+	var targets []string
+	for _, c := range enabledClients() {
+		if client != "" && c.Name != client {
+			continue
+		}
+		if tracksBranch(c.Name, branch) {
+			targets = append(targets, c.Name)
+		}
+	}
+	return targets
+}
+
+// classifyChangedFiles splits changed paths into frontend code and
+// everything else. Docs (.md, .tip) and extensionless files are neutral.
+func classifyChangedFiles(files []string) (frontend, backend bool) {
+	for _, file := range files {
+		ext := filepath.Ext(file)
+		if ext == ".md" || ext == ".tip" || ext == "" {
+			continue
+		}
+		if strings.HasPrefix(file, "frontend/") {
+			frontend = true
+			continue
+		}
+		backend = true
+	}
+	return frontend, backend
+}
+
+// apiWebhook receives POST /webhook/<provider>[/<client>], verifies the
+// provider's signature and ships the frontend to every client tracking the
+// pushed branch. Verified requests are written to the delivery log;
+// rejected ones are throttled so unauthenticated traffic cannot grow it.
+func apiWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apiError(w, http.StatusMethodNotAllowed, "POST only")
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/webhook"), "/"), "/")
+	d := webhookDelivery{Provider: parts[0]}
+	if len(parts) == 2 {
+		d.SiteName = parts[1]
+	}
+	provider, ok := webhookProviders[d.Provider]
+	if !ok || len(parts) > 2 || (d.SiteName != "" && (!nginxClientNamePattern.MatchString(d.SiteName) || !clientExists(d.SiteName))) {
+		apiError(w, http.StatusNotFound, "unknown webhook")
+		return
+	}
+
+	status, msg := handleWebhook(r, provider, &d)
+	if d.Status == "rejected" && !admitRejectedWebhook(&d, time.Now()) {
+		d.Status = ""
+	}
+	if d.Status != "" {
+		recordWebhookDelivery(d)
+	}
+	apiJSON(w, status, map[string]string{"status": msg})
+}
+
+// handleWebhook processes a delivery, filling in d for the delivery log, and
+// returns the HTTP status and message for the provider.
+func handleWebhook(r *http.Request, provider webhookProvider, d *webhookDelivery) (int, string) {
+	reject := func(code int, detail string) (int, string) {
+		d.Status, d.Detail = "rejected", detail
+		return code, detail
+	}
+	ignore := func(detail string) (int, string) {
+		d.Status, d.Detail = "ignored", detail
+		return http.StatusOK, detail
+	}
+
+	secret := webhookSecret(d.SiteName)
+	if secret == "" {
+		return reject(http.StatusServiceUnavailable, "no webhook secret configured")
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxWebhookBody))
+	if err != nil {
+		return reject(http.StatusRequestEntityTooLarge, "payload too large")
+	}
+	if !provider.verify(r, body, secret) {
+		return reject(http.StatusUnauthorized, "invalid signature")
+	}
+	ev, err := provider.parse(r, body)
+	if err != nil {
+		return reject(http.StatusBadRequest, err.Error())
+	}
+	d.Event, d.DeliveryID, d.Ref, d.CommitSHA, d.Pusher = ev.Event, ev.Delivery, ev.Ref, ev.Commit, ev.Pusher
+
+	switch ev.Event {
+	case "ping":
+		d.Status = "ping"
+		return http.StatusOK, "pong"
+	case "push":
+	default:
+		return ignore("unsupported event " + ev.Event)
+	}
+	branch, ok := strings.CutPrefix(ev.Ref, "refs/heads/")
+	if !ok {
+		return ignore("not a branch push")
+	}
+	if d.DeliveryID != "" {
+		if seen, err := newGrengoService().WebhookDeliveryAccepted(d.Provider, d.DeliveryID); err == nil && seen {
+			d.Status = ""
+			return http.StatusOK, "duplicate delivery"
+		}
+	}
+	targets := webhookTargets(d.SiteName, branch)
+	if len(targets) == 0 {
+		return ignore("no client tracks branch " + branch)
+	}
+	d.Targets = strings.Join(targets, ",")
+
+	hasFrontend, hasBackend := classifyChangedFiles(ev.Files)
+	if len(ev.Files) == 0 && d.Provider == "generic" {
+		hasFrontend = true
+	}
+	if hasBackend {
+		log("Push to %s contains backend changes. Skipping automatic frontend deployment.", branch)
+		return ignore("ignored due to backend changes")
+	}
+	if !hasFrontend {
+		return ignore("no deployable changes")
+	}
+	var running []string
+	for _, name := range targets {
+		if clientRunning(name) {
+			running = append(running, name)
+		}
+	}
+	if len(running) == 0 {
+		return ignore("no tracking client is running")
+	}
+	targets = running
+	d.Targets = strings.Join(targets, ",")
+
+	trigger := d.Provider + " push"
+	if len(ev.Commit) >= 12 {
+		trigger += " " + ev.Commit[:12]
+	}
+	trigger += " to " + branch
+	if ev.Pusher != "" {
+		trigger += " by " + ev.Pusher
+	}
+	log("Webhook %s: shipping frontend to %s", trigger, d.Targets)
+	args := append([]string{"frontend"}, targets...)
+	d.JobID = startGlobalCommand("ship", append(args, "--trigger", trigger))
+	d.Status = "accepted"
+	return http.StatusAccepted, "deploying frontend"
+}
+
+// admitRejectedWebhook reports whether a rejected delivery should be logged.
+// Each kind of rejection is logged at most once per webhookRejectInterval;
+// the entry that gets through notes how many were dropped before it.
+func admitRejectedWebhook(d *webhookDelivery, now time.Time) bool {
+	key := d.Provider + "/" + d.SiteName + "/" + d.Detail
+	webhookRejects.Lock()
+	defer webhookRejects.Unlock()
+	for k, t := range webhookRejects.last {
+		if now.Sub(t) >= webhookRejectInterval && webhookRejects.suppressed[k] == 0 {
+			delete(webhookRejects.last, k)
+		}
+	}
+	if t, ok := webhookRejects.last[key]; ok && now.Sub(t) < webhookRejectInterval {
+		webhookRejects.suppressed[key]++
+		return false
+	}
+	if n := webhookRejects.suppressed[key]; n > 0 {
+		d.Detail += fmt.Sprintf(" (+%d more)", n)
+		delete(webhookRejects.suppressed, key)
+	}
+	webhookRejects.last[key] = now
+	return true
+}
+
+func recordWebhookDelivery(d webhookDelivery) {
+	if err := newGrengoService().RecordWebhookDelivery(d); err != nil {
+		warn("Webhook delivery log unavailable: %v", err)
+	}
+}
+
+// webhookPort is where the API serves webhooks over plain HTTP; nginx
+// proxies /webhook/ to it.
+func webhookPort() int {
+	if p, err := strconv.Atoi(envVal(rootEnvFile(), "GRENGO_WEBHOOK_PORT")); err == nil && p > 0 {
+		return p
+	}
+	return DefaultAPIPort + 1
+}
+
+// serveWebhooks runs the webhook HTTP listener next to the gRPC API. It only
+// binds to loopback: git hosts reach it through nginx.
+func serveWebhooks() *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook/", apiWebhook)
+	srv := &http.Server{
+		Addr:              net.JoinHostPort("127.0.0.1", strconv.Itoa(webhookPort())),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			warn("Webhook listener on %s failed: %v", srv.Addr, err)
+		}
+	}()
+	return srv
+}
+
+// cmdWebhookSecret generates a new webhook secret for a client, or the
+// node-wide secret when name is empty, and prints it once.
+func cmdWebhookSecret(name string) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		die("Cannot generate secret: %v", err)
+	}
+	secret := hex.EncodeToString(buf)
+	file, key, url := rootEnvFile(), "GRENGO_WEBHOOK_SECRET", "/webhook/<provider>"
+	if name != "" {
+		if !clientExists(name) {
+			die("Client '%s' not found", name)
+		}
+		file, key, url = clientEnvFile(name), "WEBHOOK_SECRET", "/webhook/<provider>/"+name
+	}
+	if err := setEnvVal(file, key, secret); err != nil {
+		die("Cannot write %s: %v", file, err)
+	}
+	log("New webhook secret stored as %s in %s", key, file)
+	fmt.Printf("\n  %s\n\n", secret)
+	info("Use it for %s (provider: github, gitea, gitlab or generic)", url)
+}
+
+// cmdWebhookTrack sets the branches a client deploys from.
+func cmdWebhookTrack(name string, branches []string) {
+	if !clientExists(name) {
+		die("Client '%s' not found", name)
+	}
+	var clean []string
+	for _, b := range branches {
+		for _, part := range strings.Split(b, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				if _, err := path.Match(part, ""); err != nil {
+					die("Invalid branch pattern %q", part)
+				}
+				clean = append(clean, part)
+			}
+		}
+	}
+	if len(clean) == 0 {
+		fmt.Printf("%s tracks: %s\n", name, strings.Join(trackedBranches(name), ", "))
+		return
+	}
+	if err := setEnvVal(clientEnvFile(name), "WEBHOOK_BRANCHES", strings.Join(clean, ",")); err != nil {
+		die("Cannot update .env for %s: %v", name, err)
+	}
+	log("%s now deploys pushes to: %s", name, strings.Join(clean, ", "))
+}
+
+// cmdWebhookLog prints recent webhook deliveries.
+func cmdWebhookLog(name string, limit int) {
+	if limit <= 0 {
+		limit = 20
+	}
+	deliveries, err := newGrengoService().ListWebhookDeliveries(name, limit)
+	if err != nil {
+		die("Cannot read webhook delivery log: %v", err)
+	}
+	if len(deliveries) == 0 {
+		info("No webhook deliveries recorded")
+		return
+	}
+	fmt.Printf("%s%-17s %-8s %-10s %-20s %-12s %-20s %s%s\n", colorBold, "RECEIVED", "PROVIDER", "STATUS", "REF", "COMMIT", "TARGETS", "DETAIL", colorReset)
+	for _, d := range deliveries {
+		commit := d.CommitSHA
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		detail := d.Detail
+		if d.JobID != "" {
+			detail = d.JobID
+		}
+		fmt.Printf("%-17s %-8s %-10s %-20s %-12s %-20s %s\n", d.ReceivedAt.Local().Format("2006-01-02 15:04"),
+			d.Provider, d.Status, orDash(strings.TrimPrefix(d.Ref, "refs/heads/")), orDash(commit), orDash(d.Targets), detail)
+	}
+}
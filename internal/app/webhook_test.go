@@ -0,0 +1,217 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signBody(body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+const testPushPayload = `{
+  "ref": "refs/heads/main",
+  "after": "0123456789abcdef0123",
+  "checkout_sha": "",
+  "pusher": {"name": "Ada", "login": "ada"},
+  "commits": [
+    {"added": ["frontend/src/new.ts"], "removed": [], "modified": ["README.md"]},
+    {"added": [], "removed": ["frontend/src/old.ts"], "modified": []}
+  ]
+}`
+
+func TestWebhookProviderSignatures(t *testing.T) {
+	const secret = "s3cret"
+	sig := signBody(testPushPayload, secret)
+	tests := []struct {
+		provider string
+		header   string
+		value    string
+	}{
+		{"github", "X-Hub-Signature-256", "sha256=" + sig},
+		{"gitea", "X-Gitea-Signature", sig},
+		{"gitea", "X-Hub-Signature-256", "sha256=" + sig},
+		{"gitlab", "X-Gitlab-Token", secret},
+		{"generic", "X-Grengo-Signature", "sha256=" + sig},
+	}
+	for _, tt := range tests {
+		p := webhookProviders[tt.provider]
+		r := httptest.NewRequest(http.MethodPost, "/webhook/"+tt.provider, nil)
+		r.Header.Set(tt.header, tt.value)
+		if !p.verify(r, []byte(testPushPayload), secret) {
+			t.Errorf("%s with %s: valid signature rejected", tt.provider, tt.header)
+		}
+		if p.verify(r, []byte(testPushPayload+" "), "other") {
+			t.Errorf("%s with %s: signature accepted for wrong secret or body", tt.provider, tt.header)
+		}
+		if p.verify(httptest.NewRequest(http.MethodPost, "/", nil), []byte(testPushPayload), secret) {
+			t.Errorf("%s: unsigned request accepted", tt.provider)
+		}
+	}
+}
+
+func TestWebhookParsePush(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/webhook/github", nil)
+	r.Header.Set("X-GitHub-Event", "push")
+	r.Header.Set("X-GitHub-Delivery", "d-1")
+	ev, err := parseGitHubPush(r, []byte(testPushPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := webhookEvent{
+		Event:    "push",
+		Delivery: "d-1",
+		Ref:      "refs/heads/main",
+		Commit:   "0123456789abcdef0123",
+		Pusher:   "ada",
+		Files:    []string{"frontend/src/new.ts", "README.md", "frontend/src/old.ts"},
+	}
+	if !reflect.DeepEqual(ev, want) {
+		t.Fatalf("github event = %+v, want %+v", ev, want)
+	}
+
+	gitlab := `{"ref": "refs/heads/dev", "after": "aaa", "checkout_sha": "bbb", "user_username": "grace", "commits": []}`
+	r = httptest.NewRequest(http.MethodPost, "/webhook/gitlab", nil)
+	r.Header.Set("X-Gitlab-Event", "Push Hook")
+	r.Header.Set("X-Gitlab-Event-UUID", "uuid-1")
+	ev, err = parseGitLabPush(r, []byte(gitlab))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Event != "push" || ev.Commit != "bbb" || ev.Pusher != "grace" || ev.Delivery != "uuid-1" {
+		t.Fatalf("gitlab event = %+v", ev)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/webhook/generic", nil)
+	ev, err = parseGenericPush(r, []byte(`{"ref": "refs/heads/main", "commit": "ccc", "pusher": "ci"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Event != "push" || ev.Commit != "ccc" || ev.Pusher != "ci" || len(ev.Files) != 0 {
+		t.Fatalf("generic event = %+v", ev)
+	}
+	if _, err := parseGenericPush(r, []byte("not json")); err == nil {
+		t.Fatal("invalid generic payload accepted")
+	}
+}
+
+func TestWebhookTargetsFollowTrackedBranches(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	writeTestClient(t, "alpha", "1", "")
+	writeTestClient(t, "beta", "1", "WEBHOOK_BRANCHES=main, release/*\n")
+	writeTestClient(t, "gamma", "1", "WEBHOOK_BRANCHES=staging\n")
+
+	tests := []struct {
+		client, branch string
+		want           []string
+	}{
+		{"", "main", []string{"alpha", "beta"}},
+		{"", "release/2.1", []string{"beta"}},
+		{"", "staging", []string{"gamma"}},
+		{"", "feature/x", nil},
+		{"alpha", "main", []string{"alpha"}},
+		{"gamma", "main", nil},
+	}
+	for _, tt := range tests {
+		if got := webhookTargets(tt.client, tt.branch); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("webhookTargets(%q, %q) = %v, want %v", tt.client, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyChangedFiles(t *testing.T) {
+	if fe, be := classifyChangedFiles([]string{"frontend/src/a.ts", "docs/x.md"}); !fe || be {
+		t.Fatalf("frontend-only push classified as frontend=%v backend=%v", fe, be)
+	}
+	if _, be := classifyChangedFiles([]string{"frontend/src/a.ts", "backend/main.go"}); !be {
+		t.Fatal("backend change not detected")
+	}
+	if fe, be := classifyChangedFiles([]string{"README.md", "LICENSE"}); fe || be {
+		t.Fatal("documentation-only push classified as deployable")
+	}
+}
+
+func TestAPIWebhookRejectsAndIgnores(t *testing.T) {
+	t.Setenv("GRENGO_ROOT", t.TempDir())
+	writeTestClient(t, "alpha", "1", "WEBHOOK_SECRET=client-secret\n")
+
+	send := func(path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		apiWebhook(w, r)
+		return w
+	}
+
+	if w := send("/webhook/github", testPushPayload, nil); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no secret configured: status %d", w.Code)
+	}
+	if w := send("/webhook/bitbucket", testPushPayload, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown provider: status %d", w.Code)
+	}
+	if w := send("/webhook/github/missing", testPushPayload, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown client: status %d", w.Code)
+	}
+
+	clientSig := map[string]string{"X-Hub-Signature-256": "sha256=" + signBody(testPushPayload, "client-secret")}
+	if w := send("/webhook/github/alpha", testPushPayload, map[string]string{"X-Hub-Signature-256": "sha256=00"}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("bad signature: status %d", w.Code)
+	}
+
+	ping := map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": clientSig["X-Hub-Signature-256"]}
+	if w := send("/webhook/github/alpha", testPushPayload, ping); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "pong") {
+		t.Fatalf("ping: status %d, body %s", w.Code, w.Body)
+	}
+
+	other := strings.Replace(testPushPayload, "refs/heads/main", "refs/heads/feature", 1)
+	sig := map[string]string{"X-Hub-Signature-256": "sha256=" + signBody(other, "client-secret")}
+	if w := send("/webhook/github/alpha", other, sig); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "no client tracks") {
+		t.Fatalf("untracked branch: status %d, body %s", w.Code, w.Body)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/webhook/github/alpha", nil)
+	w := httptest.NewRecorder()
+	apiWebhook(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET: status %d", w.Code)
+	}
+}
+
+func TestAdmitRejectedWebhookThrottles(t *testing.T) {
+	webhookRejects.Lock()
+	webhookRejects.last, webhookRejects.suppressed = map[string]time.Time{}, map[string]int{}
+	webhookRejects.Unlock()
+
+	now := time.Now()
+	reject := func(detail string, at time.Time) (webhookDelivery, bool) {
+		d := webhookDelivery{Provider: "github", SiteName: "alpha", Status: "rejected", Detail: detail}
+		ok := admitRejectedWebhook(&d, at)
+		return d, ok
+	}
+	if _, ok := reject("invalid signature", now); !ok {
+		t.Fatal("first rejection not logged")
+	}
+	for i := 1; i <= 3; i++ {
+		if _, ok := reject("invalid signature", now.Add(time.Duration(i)*time.Second)); ok {
+			t.Fatalf("repeat %d logged inside the interval", i)
+		}
+	}
+	if _, ok := reject("payload too large", now.Add(time.Second)); !ok {
+		t.Fatal("different rejection throttled with the first")
+	}
+	d, ok := reject("invalid signature", now.Add(webhookRejectInterval))
+	if !ok || d.Detail != "invalid signature (+3 more)" {
+		t.Fatalf("after interval: logged=%v detail=%q", ok, d.Detail)
+	}
+}
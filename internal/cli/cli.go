@@ -1,6 +1,9 @@
 package cli
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 type commandEntry struct {
 	names []string
@@ -44,13 +47,30 @@ func commandRegistry() []commandEntry {
 		{names: []string{"migrate"}, run: runMigrate},
 		{names: []string{"logs"}, run: runLogs},
 		{names: []string{"update"}, run: runUpdate},
+		{names: []string{"plan"}, run: runPlan},
+		{names: []string{"apply"}, run: runApply},
+		{names: []string{"releases"}, run: runReleases},
+		{names: []string{"rollback"}, run: runRollback},
 		{names: []string{"export"}, run: runExport},
 		{names: []string{"import"}, run: runImport},
 		{names: []string{"export-node"}, run: runExportNode},
 		{names: []string{"wipe"}, run: runWipe},
 		{names: []string{"import-node"}, run: runImportNode},
+		{names: []string{"verify"}, run: runVerify},
 		{names: []string{"api"}, run: runAPI},
 		{names: []string{"passcode"}, run: runPasscode},
+		{names: []string{"audit"}, run: runAudit},
+		{names: []string{"apikey"}, run: runAPIKey},
+		{names: []string{"operator"}, run: runOperator},
+		{names: []string{"backup"}, run: runBackup},
+		{names: []string{"webhook"}, run: runWebhook},
+		{names: []string{"fleet"}, run: runFleet},
+		{names: []string{"alert"}, run: runAlert},
+		{names: []string{"tls"}, run: runTLS},
+		{names: []string{"quota"}, run: runQuota},
+		{names: []string{"target"}, run: runTarget},
+		{names: []string{"keys"}, run: runKeys},
+		{names: []string{"snapshot"}, run: runSnapshot},
 		{names: []string{"frappe-provision"}, run: runFrappeProvision},
 		{names: []string{"frappe-rebuild"}, run: runFrappeRebuild},
 		{names: []string{"help", "--help", "-h"}, run: runHelp},
@@ -117,9 +137,19 @@ func runBuild(_ []string, c Commands) {
 }
 
 func runShip(rest []string, c Commands) {
-	sub := requireArg(rest, "ship frontend", c)
+	sub := requireArg(rest, "ship frontend [<name>...] [--trigger <text>]", c)
 	if sub == "frontend" {
-		c.ShipFrontend()
+		var names []string
+		trigger := ""
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == "--trigger" && i+1 < len(rest) {
+				i++
+				trigger = rest[i]
+			} else {
+				names = append(names, rest[i])
+			}
+		}
+		c.ShipFrontend(names, trigger)
 	} else {
 		c.Die("Unknown ship subcommand: %s", sub)
 	}
@@ -194,12 +224,243 @@ func runMigrate(rest []string, c Commands) {
 }
 
 func runLogs(rest []string, c Commands) {
-	name := requireArg(rest, "logs <name> [-f]", c)
-	c.Logs(name, rest[1:])
+	if len(rest) > 0 && rest[0] == "retention" {
+		runLogsRetention(rest[1:], c)
+		return
+	}
+	flags := map[string]bool{"--since": true, "--until": true, "--grep": true, "--level": true}
+	query := false
+	for _, arg := range rest {
+		query = query || flags[arg]
+	}
+	if !query {
+		name := requireArg(rest, "logs <name> [-f] | logs [<name>...] --since <t> [--until <t>] [--grep <re>] [--level <l>]", c)
+		c.Logs(name, rest[1:])
+		return
+	}
+
+	var opts LogQueryOptions
+	for i := 0; i < len(rest); i++ {
+		if strings.HasPrefix(rest[i], "-") && i+1 >= len(rest) {
+			c.Die("Missing value for %s", rest[i])
+		}
+		switch rest[i] {
+		case "--since":
+			i++
+			opts.Since = rest[i]
+		case "--until":
+			i++
+			opts.Until = rest[i]
+		case "--grep":
+			i++
+			opts.Grep = rest[i]
+		case "--level":
+			i++
+			opts.Level = rest[i]
+		case "--limit":
+			i++
+			opts.Limit = intFlag(rest[i], "--limit", c)
+		default:
+			if strings.HasPrefix(rest[i], "-") {
+				c.Die("Unknown logs option: %s", rest[i])
+			}
+			opts.Clients = append(opts.Clients, rest[i])
+		}
+	}
+	c.LogsQuery(opts)
+}
+
+func runLogsRetention(rest []string, c Commands) {
+	var name, size string
+	var days int
+	reset := false
+	for i := 0; i < len(rest); i++ {
+		switch {
+		case rest[i] == "--days" && i+1 < len(rest):
+			i++
+			days = intFlag(rest[i], "--days", c)
+		case rest[i] == "--size" && i+1 < len(rest):
+			i++
+			size = rest[i]
+		case rest[i] == "--reset":
+			reset = true
+		case name == "" && !strings.HasPrefix(rest[i], "-"):
+			name = rest[i]
+		default:
+			c.Die("Usage: grengo logs retention [<name>] [--days <n>] [--size <size>] [--reset]")
+		}
+	}
+	if name == "" && (days != 0 || size != "" || reset) {
+		c.Die("Usage: grengo logs retention <name> [--days <n>] [--size <size>] [--reset]")
+	}
+	c.LogsRetention(name, days, size, reset)
+}
+
+func runAudit(rest []string, c Commands) {
+	export := false
+	if len(rest) > 0 && (rest[0] == "list" || rest[0] == "export") {
+		export = rest[0] == "export"
+		rest = rest[1:]
+	}
+	var opts AuditOptions
+	for i := 0; i < len(rest); i++ {
+		if i+1 >= len(rest) {
+			c.Die("Usage: grengo audit [list|export] [--actor <a>] [--command <c>] [--target <name>] [--result <r>] [--since <t>] [--until <t>] [--limit <n>] [-o <file>]")
+		}
+		switch rest[i] {
+		case "--actor":
+			i++
+			opts.Actor = rest[i]
+		case "--command":
+			i++
+			opts.Command = rest[i]
+		case "--target":
+			i++
+			opts.Target = rest[i]
+		case "--result":
+			i++
+			opts.Result = rest[i]
+		case "--since":
+			i++
+			opts.Since = rest[i]
+		case "--until":
+			i++
+			opts.Until = rest[i]
+		case "--limit":
+			i++
+			opts.Limit = intFlag(rest[i], "--limit", c)
+		case "-o", "--output":
+			i++
+			opts.Output = rest[i]
+		default:
+			c.Die("Unknown audit option: %s", rest[i])
+		}
+	}
+	if export {
+		c.AuditExport(opts)
+		return
+	}
+	if opts.Output != "" {
+		c.Die("-o is only valid with 'audit export'")
+	}
+	c.AuditList(opts)
+}
+
+func runAPIKey(rest []string, c Commands) {
+	sub := requireArg(rest, "apikey <create|list|rotate|revoke>", c)
+	switch sub {
+	case "create":
+		usage := "apikey create <name> --scope <module>[:r|w|rw]... [--site <name>]... [--user <id>] [--expires <90d|date|never>] [--rate <n>]"
+		name := requireArg(rest[1:], usage, c)
+		var opts APIKeyOptions
+		for i := 2; i < len(rest); i++ {
+			if i+1 >= len(rest) {
+				c.Die("Usage: grengo %s", usage)
+			}
+			switch rest[i] {
+			case "--scope":
+				i++
+				opts.Scopes = append(opts.Scopes, rest[i])
+			case "--site":
+				i++
+				opts.Sites = append(opts.Sites, rest[i])
+			case "--user":
+				i++
+				opts.User = intFlag(rest[i], "--user", c)
+			case "--expires":
+				i++
+				opts.Expires = rest[i]
+			case "--rate":
+				i++
+				opts.Rate = intFlag(rest[i], "--rate", c)
+			default:
+				c.Die("Unknown apikey create option: %s", rest[i])
+			}
+		}
+		c.APIKeyCreate(name, opts)
+	case "list":
+		all := false
+		for _, arg := range rest[1:] {
+			if arg != "--all" {
+				c.Die("Unknown apikey list option: %s", arg)
+			}
+			all = true
+		}
+		c.APIKeyList(all)
+	case "rotate":
+		usage := "apikey rotate <id|prefix> [--expires <90d|date|never>] [--grace <duration>]"
+		ref := requireArg(rest[1:], usage, c)
+		var expires, grace string
+		for i := 2; i < len(rest); i++ {
+			switch {
+			case rest[i] == "--expires" && i+1 < len(rest):
+				i++
+				expires = rest[i]
+			case rest[i] == "--grace" && i+1 < len(rest):
+				i++
+				grace = rest[i]
+			default:
+				c.Die("Usage: grengo %s", usage)
+			}
+		}
+		c.APIKeyRotate(ref, expires, grace)
+	case "revoke":
+		c.APIKeyRevoke(requireArg(rest[1:], "apikey revoke <id|prefix>", c))
+	default:
+		c.Die("Unknown apikey subcommand: %s", sub)
+	}
+}
+
+func runOperator(rest []string, c Commands) {
+	sub := requireArg(rest, "operator <add|list|role|passwd|totp|disable|enable|remove>", c)
+	switch sub {
+	case "add":
+		usage := "operator add <username> [--role viewer|deployer|admin] [--totp]"
+		username := requireArg(rest[1:], usage, c)
+		role, totp := "viewer", false
+		for i := 2; i < len(rest); i++ {
+			switch {
+			case rest[i] == "--role" && i+1 < len(rest):
+				i++
+				role = rest[i]
+			case rest[i] == "--totp":
+				totp = true
+			default:
+				c.Die("Usage: grengo %s", usage)
+			}
+		}
+		c.OperatorAdd(username, role, totp)
+	case "list":
+		c.OperatorList()
+	case "role":
+		usage := "operator role <username> <viewer|deployer|admin>"
+		username := requireArg(rest[1:], usage, c)
+		c.OperatorUpdate(username, sub, requireArg(rest[2:], usage, c))
+	case "totp":
+		usage := "operator totp <username> <on|off>"
+		username := requireArg(rest[1:], usage, c)
+		c.OperatorUpdate(username, sub, requireArg(rest[2:], usage, c))
+	case "passwd", "disable", "enable", "remove":
+		c.OperatorUpdate(requireArg(rest[1:], "operator "+sub+" <username>", c), sub, "")
+	default:
+		c.Die("Unknown operator subcommand: %s", sub)
+	}
 }
 
 func runUpdate(rest []string, c Commands) {
-	sub := requireArg(rest, "update <name|all>", c)
+	sub := requireArg(rest, "update <name|all> | update --blue-green <name> [--drain <sec>]", c)
+	if sub == "--blue-green" {
+		name := requireArg(rest[1:], "update --blue-green <name> [--drain <sec>]", c)
+		drain := -1
+		for i := 2; i < len(rest); i++ {
+			if rest[i] == "--drain" && i+1 < len(rest) {
+				i++
+				drain = intFlag(rest[i], "--drain", c)
+			}
+		}
+		c.UpdateBlueGreen(name, drain)
+		return
+	}
 	if sub == "all" {
 		c.UpdateAll()
 		return
@@ -207,13 +468,61 @@ func runUpdate(rest []string, c Commands) {
 	c.UpdateClient(sub)
 }
 
+func runPlan(rest []string, c Commands) {
+	path, prune := manifestFlags(rest, c)
+	c.Plan(path, prune)
+}
+
+func runApply(rest []string, c Commands) {
+	path, prune := manifestFlags(rest, c)
+	c.Apply(path, prune)
+}
+
+func manifestFlags(args []string, c Commands) (string, bool) {
+	var path string
+	prune := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-f", "--file":
+			if i+1 >= len(args) {
+				c.Die("Missing value for %s", args[i])
+			}
+			i++
+			path = args[i]
+		case "--prune":
+			prune = true
+		default:
+			c.Die("Unknown flag: %s", args[i])
+		}
+	}
+	return path, prune
+}
+
+func runReleases(rest []string, c Commands) {
+	c.ReleaseList(requireArg(rest, "releases <name>", c))
+}
+
+func runRollback(rest []string, c Commands) {
+	name := requireArg(rest, "rollback <name> [release] [--restore-db]", c)
+	var release string
+	restoreDB := false
+	for _, arg := range rest[1:] {
+		if arg == "--restore-db" {
+			restoreDB = true
+		} else if release == "" {
+			release = arg
+		}
+	}
+	c.Rollback(name, release, restoreDB)
+}
+
 func runExport(rest []string, c Commands) {
-	name := requireArg(rest, "export <name> [-o <file.tar.gz>]", c)
-	c.ExportClient(name, outputFlag(rest[1:]))
+	name := requireArg(rest, "export <name> [-o <file.tar.gz>] [--target <t>] [--passphrase-file <f>|--recipient <key>] [--sign]", c)
+	c.ExportClient(name, outputFlag(rest[1:]), archiveFlags(rest[1:]))
 }
 
 func runImport(rest []string, c Commands) {
-	archivePath := requireArg(rest, "import <file.tar.gz> [--name <n>] [--port <p>]", c)
+	archivePath := requireArg(rest, "import <file.tar.gz> [--name <n>] [--port <p>] [--target <t>] [--passphrase-file <f>|--identity <f>] [--allow-unsigned] [--dry-run]", c)
 	var newName, newPort string
 	for i := 1; i < len(rest); i++ {
 		switch rest[i] {
@@ -229,11 +538,72 @@ func runImport(rest []string, c Commands) {
 			}
 		}
 	}
-	c.ImportClient(archivePath, newName, newPort)
+	c.ImportClient(archivePath, newName, newPort, archiveFlags(rest[1:]))
 }
 
 func runExportNode(rest []string, c Commands) {
-	c.ExportNode(outputFlag(rest))
+	c.ExportNode(outputFlag(rest), archiveFlags(rest))
+}
+
+func runKeys(rest []string, c Commands) {
+	sub := requireArg(rest, "keys <init|show|trust>", c)
+	switch sub {
+	case "init":
+		c.KeysInit()
+	case "show":
+		c.KeysShow()
+	case "trust":
+		pub := requireArg(rest[1:], "keys trust <grengo-ed25519:...> [label]", c)
+		label := ""
+		if len(rest) > 2 {
+			label = strings.Join(rest[2:], " ")
+		}
+		c.KeysTrust(pub, label)
+	default:
+		c.Die("Unknown keys subcommand: %s", sub)
+	}
+}
+
+func runSnapshot(rest []string, c Commands) {
+	sub := requireArg(rest, "snapshot <list|restore>", c)
+	opts := archiveFlags(rest[1:])
+	switch sub {
+	case "list", "ls":
+		c.SnapshotList(opts.Store)
+	case "restore":
+		var id, at string
+		for i := 1; i < len(rest); i++ {
+			switch rest[i] {
+			case "--at":
+				if i+1 < len(rest) {
+					i++
+					at = rest[i]
+				}
+			case "--store", "-o", "--output":
+				i++
+			default:
+				if id == "" && !strings.HasPrefix(rest[i], "-") {
+					id = rest[i]
+				}
+			}
+		}
+		if id == "" && at == "" {
+			c.Die("Usage: grengo snapshot restore <id>|--at <time> [-o <file.tar.gz>] [--store <dir>]")
+		}
+		c.SnapshotRestore(id, at, outputFlag(rest[1:]), opts.Store)
+	default:
+		c.Die("Unknown snapshot subcommand: %s", sub)
+	}
+}
+
+func runTarget(rest []string, c Commands) {
+	sub := requireArg(rest, "target <list> <target>", c)
+	switch sub {
+	case "list", "ls":
+		c.TargetList(requireArg(rest[1:], "target list <target>", c))
+	default:
+		c.Die("Unknown target subcommand: %s", sub)
+	}
 }
 
 func runWipe(rest []string, c Commands) {
@@ -245,7 +615,13 @@ func runWipe(rest []string, c Commands) {
 }
 
 func runImportNode(rest []string, c Commands) {
-	c.ImportNode(requireArg(rest, "import-node <file.tar.gz>", c))
+	archivePath := requireArg(rest, "import-node <file.tar.gz> [--target <t>] [--passphrase-file <f>|--identity <f>] [--allow-unsigned] [--dry-run]", c)
+	c.ImportNode(archivePath, archiveFlags(rest[1:]))
+}
+
+func runVerify(rest []string, c Commands) {
+	archivePath := requireArg(rest, "verify <file.tar.gz> [--target <t>] [--passphrase-file <f>|--identity <f>] [--allow-unsigned]", c)
+	c.Verify(archivePath, archiveFlags(rest[1:]))
 }
 
 func runAPI(rest []string, c Commands) {
@@ -289,6 +665,266 @@ func runPasscode(rest []string, c Commands) {
 	}
 }
 
+func runWebhook(rest []string, c Commands) {
+	sub := requireArg(rest, "webhook <log|secret|track>", c)
+	switch sub {
+	case "log":
+		var name string
+		limit := 20
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == "--limit" && i+1 < len(rest) {
+				i++
+				limit = intFlag(rest[i], "--limit", c)
+			} else if name == "" {
+				name = rest[i]
+			}
+		}
+		c.WebhookLog(name, limit)
+	case "secret":
+		var name string
+		if len(rest) > 1 {
+			name = rest[1]
+		}
+		c.WebhookSecret(name)
+	case "track":
+		name := requireArg(rest[1:], "webhook track <name> [<branch>...]", c)
+		c.WebhookTrack(name, rest[2:])
+	default:
+		c.Die("Unknown webhook subcommand: %s", sub)
+	}
+}
+
+func runQuota(rest []string, c Commands) {
+	sub := requireArg(rest, "quota <show|set|clear|apply|check>", c)
+	switch sub {
+	case "show":
+		var name string
+		if len(rest) > 1 {
+			name = rest[1]
+		}
+		c.QuotaShow(name)
+	case "set":
+		usage := "quota set <name> [--cpu-shares <n>] [--memory <size>] [--pids <n>] [--uploads <size>] [--db <size>] [--enforce warn|arm|disable]"
+		name := requireArg(rest[1:], usage, c)
+		var opts QuotaOptions
+		flags := map[string]*string{
+			"--cpu-shares": &opts.CPUShares,
+			"--memory":     &opts.Memory,
+			"--pids":       &opts.Pids,
+			"--uploads":    &opts.Uploads,
+			"--db":         &opts.DB,
+			"--enforce":    &opts.Enforce,
+		}
+		for i := 2; i < len(rest); i++ {
+			dst, ok := flags[rest[i]]
+			if !ok || i+1 >= len(rest) {
+				c.Die("Usage: grengo %s", usage)
+			}
+			i++
+			*dst = rest[i]
+		}
+		c.QuotaSet(name, opts)
+	case "clear":
+		c.QuotaClear(requireArg(rest[1:], "quota clear <name>", c))
+	case "apply":
+		c.QuotaApply(requireArg(rest[1:], "quota apply <name>", c))
+	case "check":
+		c.QuotaCheck()
+	default:
+		c.Die("Unknown quota subcommand: %s", sub)
+	}
+}
+
+func runAlert(rest []string, c Commands) {
+	sub := requireArg(rest, "alert <rules|list|silence|silences|unsilence|test>", c)
+	switch sub {
+	case "rules":
+		c.AlertRules()
+	case "list":
+		all := false
+		limit := 50
+		for i := 1; i < len(rest); i++ {
+			switch {
+			case rest[i] == "--all":
+				all = true
+			case rest[i] == "--limit" && i+1 < len(rest):
+				i++
+				limit = intFlag(rest[i], "--limit", c)
+			default:
+				c.Die("Unknown alert list option: %s", rest[i])
+			}
+		}
+		c.AlertList(all, limit)
+	case "silence":
+		usage := "alert silence <rule> [<target>] --for <duration> [--comment <text>]"
+		rule := requireArg(rest[1:], usage, c)
+		var target, duration, comment string
+		for i := 2; i < len(rest); i++ {
+			switch {
+			case rest[i] == "--for" && i+1 < len(rest):
+				i++
+				duration = rest[i]
+			case rest[i] == "--comment" && i+1 < len(rest):
+				i++
+				comment = rest[i]
+			case target == "" && !strings.HasPrefix(rest[i], "--"):
+				target = rest[i]
+			default:
+				c.Die("Usage: grengo %s", usage)
+			}
+		}
+		if duration == "" {
+			c.Die("Usage: grengo %s", usage)
+		}
+		c.AlertSilence(rule, target, duration, comment)
+	case "silences":
+		c.AlertSilences()
+	case "unsilence":
+		c.AlertUnsilence(intFlag(requireArg(rest[1:], "alert unsilence <id>", c), "silence id", c))
+	case "test":
+		c.AlertTest(requireArg(rest[1:], "alert test <notifier>", c))
+	default:
+		c.Die("Unknown alert subcommand: %s", sub)
+	}
+}
+
+func runTLS(rest []string, c Commands) {
+	sub := requireArg(rest, "tls <status|issue|renew>", c)
+	switch sub {
+	case "status":
+		c.TLSStatus()
+	case "issue":
+		c.TLSIssue(requireArg(rest[1:], "tls issue <name>", c))
+	case "renew":
+		force := len(rest) > 1 && rest[1] == "--force"
+		c.TLSRenew(force)
+	default:
+		c.Die("Unknown tls subcommand: %s", sub)
+	}
+}
+
+func runFleet(rest []string, c Commands) {
+	sub := requireArg(rest, "fleet <init|issue|join|add|remove|list|sites|stats|storage|move>", c)
+	switch sub {
+	case "init":
+		var node string
+		var hosts []string
+		for i := 1; i < len(rest); i++ {
+			switch {
+			case rest[i] == "--name" && i+1 < len(rest):
+				i++
+				node = rest[i]
+			case rest[i] == "--host" && i+1 < len(rest):
+				i++
+				hosts = append(hosts, rest[i])
+			default:
+				c.Die("Unknown fleet init option: %s", rest[i])
+			}
+		}
+		c.FleetInit(node, hosts)
+	case "issue":
+		node := requireArg(rest[1:], "fleet issue <node> --host <address> [--host <address>]... [-o <file>]", c)
+		var hosts []string
+		outFile := ""
+		for i := 2; i < len(rest); i++ {
+			switch {
+			case rest[i] == "--host" && i+1 < len(rest):
+				i++
+				hosts = append(hosts, rest[i])
+			case (rest[i] == "-o" || rest[i] == "--output") && i+1 < len(rest):
+				i++
+				outFile = rest[i]
+			default:
+				c.Die("Unknown fleet issue option: %s", rest[i])
+			}
+		}
+		c.FleetIssue(node, hosts, outFile)
+	case "join":
+		c.FleetJoin(requireArg(rest[1:], "fleet join <bundle.pem>", c))
+	case "add":
+		if len(rest) < 3 {
+			c.Die("Usage: grengo fleet add <node> <host[:port]>")
+		}
+		c.FleetAdd(rest[1], rest[2])
+	case "remove":
+		c.FleetRemove(requireArg(rest[1:], "fleet remove <node>", c))
+	case "list":
+		c.FleetList()
+	case "sites":
+		c.FleetSites()
+	case "stats":
+		c.FleetStats()
+	case "storage":
+		c.FleetStorage()
+	case "move":
+		var args []string
+		keepSource := false
+		for _, a := range rest[1:] {
+			if a == "--keep-source" {
+				keepSource = true
+			} else {
+				args = append(args, a)
+			}
+		}
+		if len(args) != 3 {
+			c.Die("Usage: grengo fleet move <name> <from-node> <to-node> [--keep-source]")
+		}
+		c.FleetMove(args[0], args[1], args[2], keepSource)
+	default:
+		c.Die("Unknown fleet subcommand: %s", sub)
+	}
+}
+
+func runBackup(rest []string, c Commands) {
+	sub := requireArg(rest, "backup <schedule|unschedule|list|run|prune>", c)
+	switch sub {
+	case "schedule":
+		name := requireArg(rest[1:], "backup schedule <name> [--cron <expr>] [--keep-daily <n>] [--keep-weekly <n>] [--keep-monthly <n>]", c)
+		cronExpr := ""
+		keepDaily, keepWeekly, keepMonthly := 7, 4, 6
+		for i := 2; i < len(rest); i++ {
+			if i+1 >= len(rest) {
+				c.Die("Missing value for %s", rest[i])
+			}
+			switch rest[i] {
+			case "--cron":
+				i++
+				cronExpr = rest[i]
+			case "--keep-daily":
+				i++
+				keepDaily = intFlag(rest[i], "--keep-daily", c)
+			case "--keep-weekly":
+				i++
+				keepWeekly = intFlag(rest[i], "--keep-weekly", c)
+			case "--keep-monthly":
+				i++
+				keepMonthly = intFlag(rest[i], "--keep-monthly", c)
+			default:
+				c.Die("Unknown backup schedule option: %s", rest[i])
+			}
+		}
+		c.BackupSchedule(name, cronExpr, keepDaily, keepWeekly, keepMonthly)
+	case "unschedule":
+		c.BackupUnschedule(requireArg(rest[1:], "backup unschedule <name>", c))
+	case "list", "ls":
+		c.BackupList()
+	case "run":
+		c.BackupRun(requireArg(rest[1:], "backup run <name>", c))
+	case "prune":
+		c.BackupPrune(requireArg(rest[1:], "backup prune <name>", c))
+	default:
+		c.Die("Unknown backup subcommand: %s", sub)
+	}
+}
+
+func intFlag(value, flag string, c Commands) int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		c.Die("Invalid value for %s: %s", flag, value)
+	}
+	return n
+}
+
 func runHelp(_ []string, _ Commands) {
 	Usage()
 }
@@ -311,6 +947,41 @@ func outputFlag(args []string) string {
 	return outFile
 }
 
+func archiveFlags(args []string) ArchiveOptions {
+	var opts ArchiveOptions
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--target", "--passphrase-file", "--recipient", "--identity", "--store":
+			if i+1 >= len(args) {
+				continue
+			}
+			val := args[i+1]
+			switch args[i] {
+			case "--target":
+				opts.Target = val
+			case "--passphrase-file":
+				opts.PassphraseFile = val
+			case "--recipient":
+				opts.Recipient = val
+			case "--identity":
+				opts.Identity = val
+			case "--store":
+				opts.Store = val
+			}
+			i++
+		case "--sign":
+			opts.Sign = true
+		case "--allow-unsigned":
+			opts.AllowUnsigned = true
+		case "--incremental":
+			opts.Incremental = true
+		case "--dry-run":
+			opts.DryRun = true
+		}
+	}
+	return opts
+}
+
 func runFrappeProvision(rest []string, c Commands) {
 	siteName := requireArg(rest, "frappe-provision <site_name>", c)
 	version := "16"
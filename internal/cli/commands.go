@@ -1,44 +1,155 @@
 package cli
 
+// ArchiveOptions carries the flags shared by export and import commands.
+type ArchiveOptions struct {
+	Target         string
+	PassphraseFile string
+	Recipient      string
+	Identity       string
+	Sign           bool
+	AllowUnsigned  bool
+	Incremental    bool
+	Store          string
+	DryRun         bool
+}
+
+// LogQueryOptions holds the filters given to 'logs' when it searches the
+// stored logs instead of tailing docker compose.
+type LogQueryOptions struct {
+	Clients []string
+	Since   string
+	Until   string
+	Grep    string
+	Level   string
+	Limit   int
+}
+
+// AuditOptions holds the filters given to 'audit' and 'audit export'.
+type AuditOptions struct {
+	Actor   string
+	Command string
+	Target  string
+	Result  string
+	Since   string
+	Until   string
+	Limit   int
+	Output  string
+}
+
+// APIKeyOptions holds the flags given to 'apikey create'.
+type APIKeyOptions struct {
+	User    int
+	Expires string
+	Scopes  []string
+	Sites   []string
+	Rate    int
+}
+
+// QuotaOptions holds the limits given to 'quota set'; empty fields are left
+// unchanged.
+type QuotaOptions struct {
+	CPUShares string
+	Memory    string
+	Pids      string
+	Uploads   string
+	DB        string
+	Enforce   string
+}
+
 type Commands struct {
 	DefaultAPIPort int
 	Die            func(format string, args ...any)
 
-	New             func([]string)
-	List            func()
-	Enable          func(string)
-	Disable         func(string)
-	Start           func(string)
-	Stop            func(string)
-	Remove          func(string)
-	Build           func()
-	GlobalStart     func()
-	GlobalStop      func()
-	GlobalRestart   func()
-	ShipFrontend    func()
-	Dev             func()
-	ComposeUp       func(follow bool, build bool, forceRecreate bool)
-	ComposeDown     func()
-	LiveKit         func([]string)
-	NginxReload     func()
-	DBInit          func(string)
-	Migrate         func(name string, rebuild bool)
-	MigrateAll      func(rebuild bool)
-	Logs            func(name string, extra []string)
-	UpdateClient    func(string)
-	UpdateAll       func()
-	ExportClient    func(name, outFile string)
-	ImportClient    func(archivePath, newName, newPort string)
-	ExportNode      func(outFile string)
-	ImportNode      func(archivePath string)
-	WipeAll         func()
-	APIStart        func(port int)
-	APIStop         func()
-	APIStatus       func()
-	PasscodeSet     func([]string)
-	PasscodeVerify  func([]string)
-	PasscodeClear   func()
-	PasscodeStatus  func()
-	FrappeProvision func(siteName, version string)
-	FrappeRebuild   func()
+	New              func([]string)
+	List             func()
+	Enable           func(string)
+	Disable          func(string)
+	Start            func(string)
+	Stop             func(string)
+	Remove           func(string)
+	Build            func()
+	GlobalStart      func()
+	GlobalStop       func()
+	GlobalRestart    func()
+	ShipFrontend     func(names []string, trigger string)
+	Dev              func()
+	ComposeUp        func(follow bool, build bool, forceRecreate bool)
+	ComposeDown      func()
+	LiveKit          func([]string)
+	NginxReload      func()
+	DBInit           func(string)
+	Migrate          func(name string, rebuild bool)
+	MigrateAll       func(rebuild bool)
+	Logs             func(name string, extra []string)
+	LogsQuery        func(opts LogQueryOptions)
+	LogsRetention    func(name string, days int, size string, reset bool)
+	UpdateClient     func(string)
+	UpdateAll        func()
+	UpdateBlueGreen  func(name string, drain int)
+	Rollback         func(name, release string, restoreDB bool)
+	ReleaseList      func(name string)
+	Plan             func(path string, prune bool)
+	Apply            func(path string, prune bool)
+	ExportClient     func(name, outFile string, opts ArchiveOptions)
+	ImportClient     func(archivePath, newName, newPort string, opts ArchiveOptions)
+	ExportNode       func(outFile string, opts ArchiveOptions)
+	ImportNode       func(archivePath string, opts ArchiveOptions)
+	Verify           func(archivePath string, opts ArchiveOptions)
+	TargetList       func(target string)
+	KeysInit         func()
+	KeysShow         func()
+	KeysTrust        func(pub, label string)
+	SnapshotList     func(store string)
+	SnapshotRestore  func(id, at, outFile, store string)
+	WipeAll          func()
+	APIStart         func(port int)
+	APIStop          func()
+	APIStatus        func()
+	PasscodeSet      func([]string)
+	PasscodeVerify   func([]string)
+	PasscodeClear    func()
+	PasscodeStatus   func()
+	AuditList        func(opts AuditOptions)
+	AuditExport      func(opts AuditOptions)
+	APIKeyCreate     func(name string, opts APIKeyOptions)
+	APIKeyList       func(all bool)
+	APIKeyRotate     func(ref, expires, grace string)
+	APIKeyRevoke     func(ref string)
+	OperatorAdd      func(username, role string, totp bool)
+	OperatorList     func()
+	OperatorUpdate   func(username, change, value string)
+	BackupSchedule   func(name, cronExpr string, keepDaily, keepWeekly, keepMonthly int)
+	BackupUnschedule func(name string)
+	BackupList       func()
+	BackupRun        func(name string)
+	BackupPrune      func(name string)
+	WebhookLog       func(name string, limit int)
+	WebhookSecret    func(name string)
+	WebhookTrack     func(name string, branches []string)
+	QuotaShow        func(name string)
+	QuotaSet         func(name string, opts QuotaOptions)
+	QuotaClear       func(name string)
+	QuotaApply       func(name string)
+	QuotaCheck       func()
+	AlertRules       func()
+	AlertList        func(all bool, limit int)
+	AlertSilence     func(rule, target, duration, comment string)
+	AlertSilences    func()
+	AlertUnsilence   func(id int)
+	AlertTest        func(notifier string)
+	TLSStatus        func()
+	TLSIssue         func(name string)
+	TLSRenew         func(force bool)
+	FleetInit        func(node string, hosts []string)
+	FleetIssue       func(node string, hosts []string, outFile string)
+	FleetJoin        func(bundlePath string)
+	FleetAdd         func(node, addr string)
+	FleetRemove      func(node string)
+	FleetList        func()
+	FleetSites       func()
+	FleetStats       func()
+	FleetStorage     func()
+	FleetMove        func(name, from, to string, keepSource bool)
+	FrappeProvision  func(siteName, version string)
+	FrappeRebuild    func()
 }
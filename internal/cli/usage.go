@@ -14,8 +14,18 @@ Commands:
   restart [<name>]                           Restart all node services (or a specific client)
   remove <name>                              Remove a client (with confirmation)
   update <name|all>                          Update FEATURES_ENABLED in client(s) .env with selected features
+  update --blue-green <name> [--drain <sec>] Roll out a new backend with zero downtime (rolls back if unhealthy)
+  releases <name>                            Show a client's release history (image, frontend, migration, trigger)
+  rollback <name> [release] [--restore-db]   Restore the previous (or given) release; --restore-db also restores
+                                             the database backup taken before the migrations that followed it;
+                                             the pin lasts until the next start or update --blue-green
+  plan [-f <grengo.yaml>] [--prune]          Show what apply would change to match the node manifest
+  apply [-f <grengo.yaml>] [--prune]         Converge clients (domains, port, env, enabled/armed, Frappe sites)
+                                             to the manifest; --prune disables clients it does not list
   build                                      Build / rebuild the backend Docker image
-  ship frontend                              Auto-stash, pull, pop, and rebuild frontend
+  ship frontend [<name>...] [--trigger <text>]
+                                             Auto-stash, pull, pop, and rebuild frontend (all running
+                                             clients, or only <name>...); --trigger labels the release
   dev                                        Start dev environment (infra, API, and vite dev server)
   compose up [--build] [--force-recreate] [--follow|--no-detach]
                                              Start everything (infra + all clients + nginx); optionally recreate containers
@@ -25,12 +35,87 @@ Commands:
   db init <name>                             Create database & run migrations
   migrate <name|all> [--rebuild]             Re-run migrations on existing database
   logs <name> [-f]                           View / tail client logs
+  logs [<name>...] --since <t> [--until <t>] [--grep <re>] [--level <l>] [--limit <n>]
+                                             Search stored logs of all or the named clients; <t> is
+                                             a duration back (90m, 2d) or a time; --level is the lowest
+  logs retention [<name>] [--days <n>] [--size <size>] [--reset]
+                                             Show or set how long stored logs are kept (default 14d, 512m)
   wipe all                                   Remove all clients and shared data (postgres/redis)
 
-  export <name> [-o <file.tar.gz>]           Export a single client to a portable archive
-  import <file.tar.gz> [--name <n>] [--port <p>]  Import a client archive onto this node
-  export-node [-o <file.tar.gz>]             Export ALL clients as a single node archive
-  import-node <file.tar.gz>                  Restore a full node archive onto this node
+  export <name> [-o <file.tar.gz>] [--target <t>]
+                                             Export a single client to a portable archive
+  import <file.tar.gz> [--name <n>] [--port <p>] [--target <t>]
+                                             Import a client archive onto this node
+  export-node [-o <file.tar.gz>] [--target <t>]
+                                             Export ALL clients as a single node archive
+  import-node <file.tar.gz> [--target <t>]   Restore a full node archive onto this node
+  verify <file.tar.gz> [--target <t>]        Check checksums, signature, collisions and a test DB restore
+  target list <t>                            List archives stored on a backup target
+                                             (<t> is a directory, s3://bucket/prefix or sftp://user@host/dir)
+
+  Archive protection (export / export-node):
+    --passphrase-file <f>                    Encrypt with AES-256-GCM using the passphrase in <f>
+    --recipient <grengo-x25519:...>          Encrypt to another node's recipient key
+    --sign                                   Write a detached <archive>.sig with this node's key
+                                             (on by default once 'grengo keys init' has run)
+  Archive protection (import / import-node):
+    --passphrase-file <f> | --identity <f>   Decrypt (default identity: this node's key)
+    --allow-unsigned                         Accept archives without a manifest or trusted signature
+                                             (always on with GRENGO_ALLOW_UNSIGNED_ARCHIVES=1)
+    --dry-run                                Print what the import would change, then stop
+  export <name> --incremental [--store <dir>]
+  export-node --incremental [--store <dir>]  Store a deduplicated snapshot instead of a full archive
+  snapshot list [--store <dir>]              List incremental snapshots (default store: exports/store)
+  snapshot restore <id>|--at <time> [-o <file.tar.gz>] [--store <dir>]
+                                             Rebuild a snapshot as an archive (-o) or import it
+  keys init                                  Create this node's signing key and recipient key
+  keys show                                  Print this node's public signing and recipient keys
+  keys trust <grengo-ed25519:...> [label]    Accept archives signed by another node
+
+  backup schedule <name> [--cron <expr>] [--keep-daily <n>] [--keep-weekly <n>] [--keep-monthly <n>]
+                                             Schedule recurring backups (default @daily, keep 7/4/6)
+  backup unschedule <name>                   Remove a backup schedule (archives are kept)
+  backup list                                List backup schedules and their last result
+  backup run <name>                          Back up a client now and prune old archives
+  backup prune <name>                        Apply the retention policy to a client's backups
+
+  webhook secret [<name>]                    Generate the node-wide (or a client's) webhook secret
+  webhook track <name> [<branch>...]         Set (or show) the branches a client deploys on push (default: main)
+  webhook log [<name>] [--limit <n>]         Show recent webhook deliveries and what they triggered
+                                             (receivers: POST /webhook/<github|gitea|gitlab|generic>[/<name>])
+
+  quota show [<name>]                        Show quotas with the last measured usage
+  quota set <name> [--cpu-shares <n>] [--memory <size>] [--pids <n>] [--uploads <size>] [--db <size>]
+            [--enforce warn|arm|disable]     Limit a client (sizes like 512m or 5g, 0 = unlimited);
+                                             over-quota clients are armed by default after GRENGO_QUOTA_GRACE
+  quota clear <name>                         Remove a client's quota and container limits
+  quota apply <name>                         Rewrite compose limits from the stored quota
+  quota check                                Measure usage and enforce quotas now (the API does this every 5m)
+
+  alert rules                                Validate alerts.yaml and show its rules and notifiers
+  alert list [--all] [--limit <n>]           Show firing alerts (--all includes resolved ones)
+  alert silence <rule> [<target>] --for <duration> [--comment <text>]
+                                             Mute matching alerts (globs, e.g. 'site-*' 'shop*')
+  alert silences                             Show silences from alerts.yaml and the command above
+  alert unsilence <id>                       End a silence early
+  alert test <notifier>                      Send a test notice through an smtp or webhook notifier
+
+  tls status                                 Show each client's certificate and renewal state
+  tls issue <name>                           Request a new ACME certificate for a client now
+  tls renew [--force]                        Issue missing or expiring certificates (the API does this every 12h)
+
+  fleet init [--name <node>] [--host <address>]...
+                                             Create the fleet CA and this node's certificate
+  fleet issue <node> --host <address>... [-o <file>]
+                                             Issue a certificate bundle for a peer node
+  fleet join <bundle.pem>                    Install a bundle issued by the fleet CA on this node
+  fleet add <node> <host[:port]>             Register a peer (mutual TLS, default port 9102)
+  fleet remove <node>                        Unregister a peer
+  fleet list                                 Show registered nodes and whether they answer
+  fleet sites|stats|storage                  Clients, container usage or storage across all nodes
+  fleet move <name> <from> <to> [--keep-source]
+                                             Move a client between nodes ("local" is this node),
+                                             run GRENGO_FLEET_CUTOVER_HOOK, disable the source copy
 
   api start [--port <p>]                     Start the internal API server (default: 9100)
   api stop                                   Stop the internal API server
@@ -41,6 +126,31 @@ Commands:
   passcode clear                             Remove the passcode (disables remote management)
   passcode status                            Show whether a passcode is configured
 
+  apikey create <name> --scope <module>[:r|w|rw]... [--site <name>]... [--user <id>]
+               [--expires <90d|date|never>] [--rate <n>]
+                                             Issue an API key (shown once); the grengo module scope
+                                             covers the control plane, --site limits it to clients.
+                                             Scopes narrow calls that carry the key (the Skaia backend
+                                             forwards it); they do not bind passcode or operator callers
+  apikey list [--all]                        Show keys with scopes, expiry and last use (--all: revoked too)
+  apikey rotate <id|prefix> [--expires <t>] [--grace <duration>]
+                                             Replace a key's secret; the old one stops now or after --grace
+  apikey revoke <id|prefix>                  Disable a key
+
+  operator add <username> [--role viewer|deployer|admin] [--totp]
+                                             Create an API login (prompts for the password)
+  operator list                              Show operators with role, TOTP and last login
+  operator role <username> <role>            Change an operator's role
+  operator passwd <username>                 Set a new password
+  operator totp <username> on|off            Require or drop a TOTP code at login
+  operator disable|enable <username>         Block or allow an operator's logins
+  operator remove <username>                 Delete an operator
+
+  audit [list] [--actor <a>] [--command <c>] [--target <name>] [--result ok|error|denied]
+        [--since <t>] [--until <t>] [--limit <n>]
+                                             Show recorded control-plane actions (newest 50 by default)
+  audit export [<filters>] [-o <file.jsonl>] Write matching actions as JSON lines, oldest first
+
 Examples:
   grengo new                           # fully interactive
   grengo new skaiacraft                # name provided, rest prompted
@@ -51,10 +161,20 @@ Examples:
   grengo disable writers
   grengo start
   grengo stop
+  grengo update --blue-green mysite
+  grengo rollback mysite
+  grengo rollback mysite 42 --restore-db
   grengo export mysite
   grengo import grengo-client-mysite-20260319-120000.tar.gz --name mysite-copy
   grengo export-node -o full-backup.tar.gz
-  grengo import-node full-backup.tar.gz`
+  grengo import-node full-backup.tar.gz
+  grengo import-node full-backup.tar.gz --dry-run
+  grengo export mysite --target s3://skaia-backups/node1
+  grengo export-node --incremental
+  grengo snapshot restore --at "2026-03-04 02:00" -o node-0200.tar.gz
+  grengo export mysite --sign --recipient grengo-x25519:3q2-7w... --target s3://skaia-backups/node1
+  grengo import grengo-client-mysite-20260319-120000.tar.gz --target sftp://backup@vault.example.com/srv/grengo
+  grengo backup schedule mysite --cron "30 2 * * *" --keep-daily 14`
 
 func Usage() {
 	fmt.Println(usageText)